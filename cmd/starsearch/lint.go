@@ -0,0 +1,52 @@
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+
+	"starsearch/internal/lint"
+)
+
+// runLint implements `starsearch lint [flags] FILE...`, which checks local
+// gemtext for common mistakes before it is published
+func runLint(args []string) error {
+	flags := flag.NewFlagSet("lint", flag.ContinueOnError)
+	base := flags.String("base", "", "URL the root directory is served at, e.g. gemini://example.org/; absolute links into it are checked too")
+	root := flags.String("root", "", "directory served at the base URL (default: each file's directory)")
+	maxLine := flags.Int("max-line", lint.DefaultMaxLineLength, "longest text line, in characters, before it is reported")
+	flags.Usage = func() {
+		fmt.Fprintf(flags.Output(), "Usage: starsearch lint [flags] FILE...\n\n"+
+			"Reports broken relative links, trailing whitespace, very long lines\n"+
+			"outside preformatted blocks and links without labels.\n\n")
+		flags.PrintDefaults()
+	}
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+	if flags.NArg() == 0 {
+		flags.Usage()
+		return errors.New("lint needs at least one file")
+	}
+
+	issues := 0
+	for _, file := range flags.Args() {
+		diagnostics, err := lint.File(file, lint.Options{Root: *root, Base: *base, MaxLineLength: *maxLine})
+		if err != nil {
+			return err
+		}
+		for _, d := range diagnostics {
+			fmt.Println(d)
+		}
+		issues += len(diagnostics)
+	}
+
+	switch issues {
+	case 0:
+		return nil
+	case 1:
+		return errors.New("1 issue found")
+	default:
+		return fmt.Errorf("%d issues found", issues)
+	}
+}
@@ -1,9 +1,14 @@
 package main
 
 import (
+	"errors"
+	"flag"
 	"fmt"
 	"log"
+	"net/http"
+	_ "net/http/pprof" // Served only when --pprof is given
 	"os"
+	"strings"
 
 	tea "github.com/charmbracelet/bubbletea"
 	"starsearch/internal/app"
@@ -18,10 +23,68 @@ func main() {
 		os.Exit(0)
 	}
 
+	// Development Gemini server: starsearch serve [flags] DIR
+	if len(os.Args) > 1 && os.Args[1] == "serve" {
+		if err := runServe(os.Args[2:]); err != nil {
+			if !errors.Is(err, flag.ErrHelp) {
+				fmt.Fprintf(os.Stderr, "starsearch serve: %v\n", err)
+			}
+			os.Exit(1)
+		}
+		os.Exit(0)
+	}
+
+	// Gemtext linter: starsearch lint [flags] FILE...
+	if len(os.Args) > 1 && os.Args[1] == "lint" {
+		if err := runLint(os.Args[2:]); err != nil {
+			if !errors.Is(err, flag.ErrHelp) {
+				fmt.Fprintf(os.Stderr, "starsearch lint: %v\n", err)
+			}
+			os.Exit(1)
+		}
+		os.Exit(0)
+	}
+
+	// Leading flags:
+	//   --pprof[=addr]   hidden; serves net/http/pprof
+	//   --record=FILE    save every request and response to a cassette
+	//   --replay=FILE    serve responses from a cassette instead of the network
+	args := os.Args[1:]
+	var recordPath, replayPath string
+	for len(args) > 0 && strings.HasPrefix(args[0], "--") {
+		name, value, _ := strings.Cut(args[0], "=")
+		switch name {
+		case "--pprof":
+			addr := value
+			if addr == "" {
+				addr = "localhost:6060"
+			}
+			go func() {
+				if err := http.ListenAndServe(addr, nil); err != nil {
+					log.Printf("pprof server: %v", err)
+				}
+			}()
+		case "--record", "--replay":
+			if value == "" {
+				fmt.Fprintf(os.Stderr, "%s needs a file: %s=FILE\n", name, name)
+				os.Exit(2)
+			}
+			if name == "--record" {
+				recordPath = value
+			} else {
+				replayPath = value
+			}
+		default:
+			fmt.Fprintf(os.Stderr, "unknown flag %s\n", name)
+			os.Exit(2)
+		}
+		args = args[1:]
+	}
+
 	// Get initial URL from command-line arguments if provided
 	var initialURL string
-	if len(os.Args) > 1 {
-		initialURL = os.Args[1]
+	if len(args) > 0 {
+		initialURL = args[0]
 	}
 
 	// Create the application model with version
@@ -29,16 +92,30 @@ func main() {
 	if err != nil {
 		log.Fatal(err)
 	}
+	switch {
+	case recordPath != "" && replayPath != "":
+		log.Fatal("--record and --replay cannot be used together")
+	case recordPath != "":
+		model.RecordSession(recordPath)
+	case replayPath != "":
+		if err := model.ReplaySession(replayPath); err != nil {
+			log.Fatal(err)
+		}
+	}
 
 	// Create the Bubble Tea program with alternate screen buffer
 	p := tea.NewProgram(
 		model,
-		tea.WithAltScreen(),       // Use alternate screen buffer
-		tea.WithMouseCellMotion(), // Enable mouse support
+		tea.WithAltScreen(),      // Use alternate screen buffer
+		tea.WithMouseAllMotion(), // Enable mouse support, with motion for link hover
 	)
 
-	// Run the program
-	if _, err := p.Run(); err != nil {
+	handleSignals(p)
+
+	// Run the program, then save its state once nothing else can change it
+	_, err = p.Run()
+	model.Shutdown()
+	if err != nil {
 		fmt.Printf("Error running program: %v\n", err)
 		os.Exit(1)
 	}
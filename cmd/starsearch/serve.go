@@ -0,0 +1,61 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"os/signal"
+	"path/filepath"
+
+	"starsearch/internal/devserver"
+)
+
+// runServe implements `starsearch serve [flags] DIR`, a local Gemini
+// server for testing rendering, TOFU prompts, redirects and input
+func runServe(args []string) error {
+	flags := flag.NewFlagSet("serve", flag.ContinueOnError)
+	addr := flags.String("addr", "localhost:1965", "address to listen on")
+	newCert := flags.Bool("new-cert", false, "replace the stored certificate, to test certificate change warnings")
+	flags.Usage = func() {
+		fmt.Fprintf(flags.Output(), "Usage: starsearch serve [flags] DIR\n\n"+
+			"Serves the .gmi files in DIR over Gemini with a self-signed certificate.\n"+
+			"Redirects, input prompts and error statuses are read from DIR/%s.\n\n", devserver.ConfigFile)
+		flags.PrintDefaults()
+	}
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+	if flags.NArg() != 1 {
+		flags.Usage()
+		return errors.New("serve needs exactly one directory")
+	}
+
+	// Keep the certificate next to the browser's config so TOFU pins
+	// survive restarts
+	certDir := ""
+	if configDir, err := os.UserConfigDir(); err == nil {
+		certDir = filepath.Join(configDir, "starsearch", "serve-certs")
+	}
+
+	server, err := devserver.New(devserver.Options{
+		Dir:     flags.Arg(0),
+		Addr:    *addr,
+		CertDir: certDir,
+		NewCert: *newCert,
+	})
+	if err != nil {
+		return err
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+
+	log.Printf("Serving %s at gemini://%s/ (Ctrl+C to stop)", flags.Arg(0), *addr)
+	if err := server.ListenAndServe(ctx); err != nil && !errors.Is(err, context.Canceled) {
+		return err
+	}
+	return nil
+}
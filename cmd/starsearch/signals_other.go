@@ -0,0 +1,9 @@
+//go:build !unix
+
+package main
+
+import tea "github.com/charmbracelet/bubbletea"
+
+// handleSignals does nothing where there are no hangup or job control
+// signals; Bubble Tea itself quits on termination
+func handleSignals(p *tea.Program) {}
@@ -0,0 +1,29 @@
+//go:build unix
+
+package main
+
+import (
+	"os"
+	"os/signal"
+	"syscall"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// handleSignals quits p cleanly, so its state is saved, when the terminal
+// hangs up, and has it restore the terminal when continued after being
+// stopped from outside. Bubble Tea itself quits on SIGTERM.
+func handleSignals(p *tea.Program) {
+	sig := make(chan os.Signal, 1)
+	signal.Notify(sig, syscall.SIGHUP, syscall.SIGCONT)
+	go func() {
+		for s := range sig {
+			switch s {
+			case syscall.SIGHUP:
+				p.Quit()
+			case syscall.SIGCONT:
+				p.Send(tea.ResumeMsg{})
+			}
+		}
+	}()
+}
@@ -0,0 +1,68 @@
+package app
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"starsearch/internal/renderer"
+	"starsearch/internal/storage"
+	"starsearch/internal/types"
+	"starsearch/internal/urlutil"
+)
+
+// aboutPage generates an internal about: page from local data. It returns
+// nil for addresses that are not internal pages.
+func (m *Model) aboutPage(urlStr string, fetchID int) tea.Cmd {
+	var body []byte
+	switch urlStr {
+	case "about:stats":
+		body = storage.StatsPage(m.history.GetAll(), m.stats.Counters(), time.Now())
+	case welcomeURL:
+		body = welcomePage(m.tourDone)
+	case discoverURL:
+		body = discoverPage(m.config.Get().General.RandomCapsuleURL, m.config.Get().General.SearchEngines)
+	default:
+		return nil
+	}
+
+	resp := &types.Response{
+		Status: 20,
+		Meta:   "text/gemini; charset=utf-8",
+		Body:   body,
+		URL:    urlStr,
+	}
+	return func() tea.Msg {
+		return fetchCompleteMsg{resp: resp, protocol: "gemini", url: urlStr, fetchID: fetchID}
+	}
+}
+
+// dataPage shows the contents of a data: URL as if it had been fetched.
+// Text is shown as a page and images through the image renderer; other
+// media types cannot be shown.
+func (m *Model) dataPage(urlStr string, fetchID int) tea.Cmd {
+	mediaType, body, err := urlutil.ParseDataURL(urlStr)
+	if err == nil {
+		switch lower := strings.ToLower(mediaType); {
+		case strings.HasPrefix(lower, "text/gemini"):
+			mediaType = "text/gemini; charset=utf-8"
+		case strings.HasPrefix(lower, "text/"):
+			mediaType = "text/plain"
+		case renderer.IsImageMIME(lower):
+			mediaType = lower
+		default:
+			err = fmt.Errorf("cannot show data: links of type %s", mediaType)
+		}
+	}
+	if err != nil {
+		return func() tea.Msg {
+			return fetchCompleteMsg{err: err, protocol: "gemini", url: urlStr, fetchID: fetchID}
+		}
+	}
+
+	resp := &types.Response{Status: 20, Meta: mediaType, Body: body, URL: urlStr}
+	return func() tea.Msg {
+		return fetchCompleteMsg{resp: resp, protocol: "gemini", url: urlStr, fetchID: fetchID}
+	}
+}
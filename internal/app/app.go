@@ -1,66 +1,146 @@
 package app
 
 import (
+	"context"
 	"crypto/x509"
+	"errors"
 	"fmt"
 	"net/url"
 	"os"
 	"os/exec"
 	"path/filepath"
 	"runtime"
+	"slices"
 	"strconv"
 	"strings"
+	"sync"
+	"time"
 
-	"github.com/atotto/clipboard"
 	tea "github.com/charmbracelet/bubbletea"
 	"github.com/charmbracelet/lipgloss"
 
 	"starsearch/internal/cache"
 	"starsearch/internal/gemini"
+	"starsearch/internal/gempub"
 	"starsearch/internal/gopher"
 	"starsearch/internal/renderer"
+	"starsearch/internal/robots"
+	"starsearch/internal/scheduler"
 	"starsearch/internal/storage"
 	"starsearch/internal/types"
 	"starsearch/internal/ui"
+	"starsearch/internal/urlutil"
 )
 
+// fetcher fetches a URL. *gemini.Client and *gopher.Client implement it,
+// and tests drive the model with fakes.
+type fetcher interface {
+	Fetch(urlStr string) (*types.Response, error)
+}
+
 // Model is the main application model
 type Model struct {
-	client         *gemini.Client
-	gopherClient   *gopher.Client
+	client         fetcher // Gemini client
+	gopherClient   fetcher
 	tofuStore      *gemini.TOFUStore
+	identities     *gemini.IdentityStore
 	history        *storage.History
 	bookmarks      *storage.Bookmarks
+	bookmarkIndex  *storage.BookmarkIndex // Text of bookmarked pages, for the bookmarks filter
+	indexing       map[string]bool        // Bookmarks whose pages are being fetched for the index
+	inputHistory   *storage.InputHistory
+	stats          *storage.Stats
+	tokens         *storage.CapsuleTokens // Session tokens capsules were allowed to keep
 	config         *storage.Config
 	sessionManager *storage.SessionManager
 	pageCache      *cache.Cache
 	addressBar     *ui.AddressBar
 	viewport       *ui.ContentViewport
 	statusBar      *ui.StatusBar
+	breadcrumb     *ui.Breadcrumb
 	tabBar         *ui.TabBar
 	helpModal      *ui.HelpModal
+	pageInfoModal  *ui.PageInfoModal
 	inputModal     *ui.InputModal
 	bookmarksModal *ui.BookmarksModal
 	searchModal    *ui.SearchModal
 	historyModal   *ui.HistoryModal
+	identitiesModal *ui.IdentitiesModal
+	cacheModal     *ui.CacheModal
+	archiveModal   *ui.ArchiveModal
+	lastArchive    string // Path of the most recently downloaded archive
+	audio          audioQueue // Sound files playing and waiting to play
+	robots         *robots.Cache // robots.txt policies automated requests honor
+	privacyModal   *ui.PrivacyModal
+	privacyReportModal *ui.PrivacyReportModal
+	certExpiryModal *ui.CertExpiryModal
+	certificateModal *ui.CertificateModal
+	statusLogModal *ui.StatusLogModal
+	titanModal     *ui.TitanModal
+	titanBuffer    []byte // Text written in the editor to upload
+	linkMenu       *ui.LinkMenu
+	linkListModal  *ui.LinkListModal
+	modals         *ui.ModalStack // Open modals, topmost last
+	confirmModal   *ui.ConfirmModal
+	toast          *ui.Toast
+	activeDownloads int // Downloads started and not yet saved
+	downloads      *storage.Downloads // Downloads in progress and finished, listed with Shift+D
+	downloadModal  *ui.DownloadModal
+	downloadCancels map[string]context.CancelFunc // Cancels each download in progress, by ID
+	writes         sync.WaitGroup // Downloads being written to disk, waited for on shutdown
+	cancelRequests func()         // Aborts requests in flight on shutdown
+	viewerDir      string         // Temporary files of pages viewed in other programs, removed on shutdown
 	width          int
 	height         int
 	currentURL     string
 	currentDoc     *types.Document
 	linkNumbers    bool   // Whether we're in link number input mode
 	linkInput      string
-	showHelp       bool   // Whether to show the help modal
-	showInput      bool   // Whether to show the input modal
-	showBookmarks  bool   // Whether to show the bookmarks modal
-	showSearch     bool   // Whether to show the search modal
-	showHistory    bool   // Whether to show the history modal
 	pendingInputURL string // URL that triggered input request
+	inputSession   *inputSession // Chain of consecutive input prompts, if any
+	importingSession bool        // The input prompt asks for a session file to import
+	lastSessionExport string     // File the session was last exported to
 	quitting       bool
 	isNavigating   bool   // Whether currently navigating (to avoid adding to history during back/forward)
 	initialURL     string // Initial URL to navigate to on startup
+	firstRun       bool   // No config existed before this launch; start with the welcome tour
+	tourDone       []bool // Welcome tour steps done, by index into tourSteps
 	forceReload    bool   // Whether to bypass cache for next navigation
+	revalidation   *revalidation // Reload checking whether the current page changed
 	redirectCount  int    // Current redirect count for loop detection
-	redirectLimit  int    // Maximum number of redirects allowed (default: 10)
+	redirectLimit  int    // Maximum number of redirects allowed (default: 5)
+	redirectChain  []string // URLs visited in the current redirect chain, for cycle detection
+	redirectViolations []string // Protocol violations by redirects in the current chain
+	strictMode     bool   // Whether Gemini protocol violations are flagged
+	anonymousNext  bool   // Whether the next Gemini request is sent without a client certificate
+	retryAttempt   int    // Retry number for the next navigation (0 for a fresh request)
+	retryPending   bool   // Whether a retry is waiting on its backoff delay
+	retryID        int    // Incremented to invalidate pending retries
+	resizeID       int    // Incremented on every resize; only the last one re-renders the page
+	watchTicking   bool   // Whether the once-a-second ticker for watched tabs is running
+	pendingFold    bool   // Whether "z" was pressed and a fold command is expected
+	fetchID        int    // Incremented for every fetch started by navigate
+	streamedFetch  int    // ID of the fetch whose page was last shown partially while loading
+	cancelledFetch int    // ID of a fetch the user cancelled; its result is ignored
+	stopFetch      func() // Abandons the fetch stopFetchID midway, if it can be
+	stopFetchID    int
+	scheduler      *scheduler.Scheduler // Limits concurrent requests, per host and in total
+	bgTotal        int    // Background tab fetches in the current batch
+	bgDone         int    // Background tab fetches finished in the current batch
+	bgFailed       int    // Background tab fetches that failed in the current batch
+	frame          string    // Screen last drawn by View
+	frameAt        time.Time // When frame was drawn
+	holdFrame      bool      // Whether View shows frame again rather than redrawing
+	asciiGlyphs    bool      // Whether the screen is drawn with ASCII symbols only
+	hideTabBar     bool      // Bars hidden for a distraction-free view
+	hideAddressBar bool
+	hideStatusBar  bool
+	imageView      renderer.ImageOptions // How images are drawn, changed with +/-, Shift+arrows, x and m on image pages
+	applied        layout    // Layout the page was last fitted to
+	framePending   bool      // Whether a redraw of held back changes is scheduled
+	books          map[string]*openBook // Gempub books unpacked for reading, by ID
+	bookDir        string               // Temporary directory books are unpacked to
+	bookshelf      *storage.Bookshelf   // Reading progress in Gempub books
 }
 
 // NewModel creates a new application model
@@ -75,6 +155,11 @@ func NewModel(initialURL string, version string) (*Model, error) {
 	tofuPath := filepath.Join(starsearchDir, "known_hosts.json")
 	historyPath := filepath.Join(starsearchDir, "history.json")
 	bookmarksPath := filepath.Join(starsearchDir, "bookmarks.json")
+	bookmarkIndexPath := filepath.Join(starsearchDir, "bookmark_index.json")
+	inputHistoryPath := filepath.Join(starsearchDir, "input_history.json")
+	statsPath := filepath.Join(starsearchDir, "stats.json")
+	tokensPath := filepath.Join(starsearchDir, "tokens.json")
+	booksPath := filepath.Join(starsearchDir, "books.json")
 	configPath := filepath.Join(starsearchDir, "config.toml")
 	sessionPath := filepath.Join(starsearchDir, "session.json")
 
@@ -89,11 +174,13 @@ func NewModel(initialURL string, version string) (*Model, error) {
 		return true // Auto-accept new certificates
 	}
 	tofuStore.OnCertChange = func(host string, old, new *x509.Certificate) bool {
-		return true // Auto-accept changed certificates (user will see warning)
+		return false // Changed certificates are only trusted once the user confirms
 	}
 
 	// Create clients
 	client := gemini.NewClient(tofuStore)
+	identities := gemini.NewIdentityStore(filepath.Join(starsearchDir, "identities"))
+	client.SetIdentities(identities)
 	gopherClient := gopher.NewClient()
 
 	// Create config, history, bookmarks, session manager, and cache
@@ -101,11 +188,25 @@ func NewModel(initialURL string, version string) (*Model, error) {
 	history := storage.NewHistory(historyPath, config.Get().General.MaxHistory)
 	bookmarks := storage.NewBookmarks(bookmarksPath)
 	sessionManager := storage.NewSessionManager(sessionPath)
+
+	// Route requests through the SOCKS proxy, if one is configured
+	if err := client.SetProxy(config.Get().Network.SocksProxy); err != nil {
+		return nil, fmt.Errorf("failed to set up proxy: %w", err)
+	}
+	if err := gopherClient.SetProxy(config.Get().Network.SocksProxy); err != nil {
+		return nil, fmt.Errorf("failed to set up proxy: %w", err)
+	}
+	network := config.Get().Network
+	gopherClient.SetLimits(int64(network.GopherMaxSizeMB)<<20, time.Duration(network.GopherIdleTimeout)*time.Second)
 	
 	// Create page cache if enabled
 	var pageCache *cache.Cache
-	if config.Get().Performance.EnableCache {
-		pageCache = cache.NewCache(config.Get().Performance.CacheSizeMB, int64(config.Get().Performance.CacheTTL))
+	if performance := config.Get().Performance; performance.EnableCache {
+		if performance.CacheMemoryOnly {
+			pageCache = cache.NewCache(performance.CacheSizeMB, int64(performance.CacheTTL))
+		} else {
+			pageCache = cache.NewDiskCache(filepath.Join(starsearchDir, "cache"), performance.CacheSizeMB, int64(performance.CacheTTL))
+		}
 	}
 
 	// Create UI components
@@ -115,6 +216,7 @@ func NewModel(initialURL string, version string) (*Model, error) {
 	tabBar := ui.NewTabBar()
 	helpModal := ui.NewHelpModal()
 	inputModal := ui.NewInputModal()
+	inputModal.SetSpelling(config.Get().UI.Spelling)
 	bookmarksModal := ui.NewBookmarksModal()
 	searchModal := ui.NewSearchModal()
 	historyModal := ui.NewHistoryModal()
@@ -125,31 +227,99 @@ func NewModel(initialURL string, version string) (*Model, error) {
 	model := &Model{
 		client:         client,
 		gopherClient:   gopherClient,
+		cancelRequests: func() {
+			client.Close()
+			gopherClient.Close()
+		},
 		tofuStore:      tofuStore,
+		identities:     identities,
 		history:        history,
 		bookmarks:      bookmarks,
+		bookmarkIndex:  storage.NewBookmarkIndex(bookmarkIndexPath),
+		indexing:       make(map[string]bool),
+		inputHistory:   storage.NewInputHistory(inputHistoryPath),
+		stats:          storage.NewStats(statsPath),
+		tokens:         storage.NewCapsuleTokens(tokensPath),
+		books:          make(map[string]*openBook),
+		bookshelf:      storage.NewBookshelf(booksPath),
 		config:         config,
 		sessionManager: sessionManager,
 		pageCache:      pageCache,
 		addressBar:     addressBar,
 		viewport:       viewport,
 		statusBar:      statusBar,
+		breadcrumb:     ui.NewBreadcrumb(),
 		tabBar:         tabBar,
 		helpModal:      helpModal,
+		pageInfoModal:  ui.NewPageInfoModal(),
+		modals:         ui.NewModalStack(),
+		confirmModal:   ui.NewConfirmModal(),
+		toast:          ui.NewToast(),
 		inputModal:     inputModal,
 		bookmarksModal: bookmarksModal,
 		searchModal:    searchModal,
 		historyModal:   historyModal,
+		identitiesModal: ui.NewIdentitiesModal(),
+		cacheModal:     ui.NewCacheModal(),
+		archiveModal:   ui.NewArchiveModal(),
+		privacyModal:   ui.NewPrivacyModal(),
+		privacyReportModal: ui.NewPrivacyReportModal(),
+		certExpiryModal: ui.NewCertExpiryModal(),
+		certificateModal: ui.NewCertificateModal(),
+		downloads:      storage.NewDownloads(filepath.Join(starsearchDir, "downloads.json"), config.Get().Downloads.MaxConcurrent),
+		downloadModal:  ui.NewDownloadModal(),
+		downloadCancels: make(map[string]context.CancelFunc),
+		statusLogModal: ui.NewStatusLogModal(),
+		titanModal:     ui.NewTitanModal(),
+		linkMenu:       ui.NewLinkMenu(),
+		linkListModal:  ui.NewLinkListModal(),
+		scheduler:      scheduler.New(
+			config.Get().Network.MaxRequests,
+			config.Get().Network.MaxRequestsPerHost,
+			config.Get().Network.MaxParallelFetches,
+		),
 		width:          80,
 		height:         24,
 		initialURL:     initialURL,
-		redirectLimit:  10, // Default redirect limit
+		firstRun:       config.FirstRun(),
+		tourDone:       make([]bool, len(tourSteps)),
+		redirectLimit:  5, // Default redirect limit, as the Gemini specification suggests
 		redirectCount:  0,
+		strictMode:     config.Get().Network.GeminiStrict,
+		asciiGlyphs:    ui.UseASCII(config.Get().UI.Glyphs, os.Getenv),
+		hideTabBar:     config.Get().UI.HideTabBar,
+		hideAddressBar: config.Get().UI.HideAddressBar,
+		hideStatusBar:  config.Get().UI.HideStatusBar,
+		imageView:      imageView(config.Get().UI),
 	}
 
 	// Apply theme colors to viewport
 	colors := config.Get().Colors
 	viewport.SetColors(&colors)
+	viewport.SetRenderTables(config.Get().UI.RenderTables)
+	viewport.SetSyntaxHighlight(config.Get().UI.SyntaxHighlight)
+	viewport.SetFootnoteLinks(config.Get().UI.LinkStyle == "footnote")
+	viewport.SetQuoteThreshold(config.Get().UI.QuoteFoldThreshold)
+	viewport.SetScrollSpeed(config.Get().UI.ScrollSpeed)
+	viewport.SetZoom(config.Get().UI.Zoom)
+	viewport.SetTypography(typography(config.Get().UI))
+	viewport.SetBidi(!config.Get().UI.TerminalBidi)
+
+	model.scheduler.LimitBackground(config.Get().Network.BackgroundKBps * 1024)
+	// Downloads cut short by the last exit cannot be resumed
+	for _, download := range model.downloads.GetActive() {
+		model.downloads.SetStatus(download.ID, types.DownloadFailed, "interrupted")
+	}
+	model.robots = robots.NewCache(robotsFetcher{model}, robotsTTL)
+	addressBar.SetHosts(func() []string {
+		return ui.CompletionHosts(model.history.GetAll(), model.bookmarks.GetAll())
+	})
+	viewport.SetSlowLinks(model.slowLink)
+
+	// Count evictions from the full cache in about:stats
+	if pageCache != nil {
+		pageCache.OnEvict(model.stats.RecordEvictions)
+	}
 
 	return model, nil
 }
@@ -201,7 +371,22 @@ func (m *Model) Init() tea.Cmd {
 
 	// If an initial URL was provided and no session was restored, navigate to it
 	if m.initialURL != "" && len(cmds) == 0 {
-		cmds = append(cmds, m.navigate(m.initialURL))
+		if target, err := m.resolveInput(m.initialURL); err == nil {
+			cmds = append(cmds, m.navigate(target))
+		} else {
+			m.statusBar.SetError(err.Error())
+		}
+	}
+
+	// Otherwise start on somewhere to go rather than an empty page: the
+	// welcome tour on the first launch
+	if m.initialURL == "" && len(cmds) == 0 {
+		m.isNavigating = true
+		if m.firstRun {
+			cmds = append(cmds, m.navigate(welcomeURL))
+		} else {
+			cmds = append(cmds, m.navigate(discoverURL))
+		}
 	}
 
 	if len(cmds) > 0 {
@@ -212,60 +397,84 @@ func (m *Model) Init() tea.Cmd {
 
 // Update handles messages and updates the model
 func (m *Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	model, cmd := m.update(msg)
+	m.applyLayout()
+	if frame := m.limitFrames(msg); frame != nil {
+		cmd = tea.Batch(cmd, frame)
+	}
+	return model, cmd
+}
+
+// update handles a message; Update adds frame limiting on top
+func (m *Model) update(msg tea.Msg) (tea.Model, tea.Cmd) {
 	var cmds []tea.Cmd
 
 	switch msg := msg.(type) {
 	case tea.KeyMsg:
-		// If history modal is showing, handle it first
-		if m.showHistory {
-			var cmd tea.Cmd
-			m.historyModal, cmd = m.historyModal.Update(msg)
-			if cmd != nil {
-				cmds = append(cmds, cmd)
-			}
-			// Check if modal was closed
-			if !m.historyModal.IsVisible() {
-				m.showHistory = false
-			}
-			return m, tea.Batch(cmds...)
+		// Check off the welcome tour's steps, except while typing text
+		if !m.addressBar.IsFocused() && !m.modals.TakesText() {
+			m.observeTour(msg.String())
 		}
 
-		// If bookmarks modal is showing, handle it first
-		if m.showBookmarks {
-			var cmd tea.Cmd
-			m.bookmarksModal, cmd = m.bookmarksModal.Update(msg)
-			if cmd != nil {
-				cmds = append(cmds, cmd)
-			}
-			// Check if modal was closed
-			if !m.bookmarksModal.IsVisible() {
-				m.showBookmarks = false
-			}
-			return m, tea.Batch(cmds...)
+		// Ctrl+Z suspends from anywhere, like other terminal programs
+		if msg.Type == tea.KeyCtrlZ {
+			return m, m.suspend()
 		}
 
-		// If search modal is showing, handle it
-		if m.showSearch {
-			var cmd tea.Cmd
-			m.searchModal, cmd = m.searchModal.Update(msg)
-			if cmd != nil {
-				cmds = append(cmds, cmd)
+		// An open modal takes all key input
+		if m.modals.Top() != nil {
+			return m, m.modals.Update(msg)
+		}
+
+		// Structural navigation after "g": gu goes up a level, gU/gr to the
+		// root; gd discovers capsules and g? opens a random one
+		if m.linkNumbers && m.linkInput == "" {
+			switch msg.String() {
+			case "u":
+				return m, m.navigateUp(false)
+			case "U", "r":
+				return m, m.navigateUp(true)
+			case "d":
+				return m, m.discover(false)
+			case "?":
+				return m, m.discover(true)
 			}
-			// Check if modal was closed
-			if !m.searchModal.IsVisible() {
-				m.showSearch = false
+		}
+
+		// Folding after "z": za toggles the current section, zR/zM all sections
+		if m.pendingFold {
+			m.pendingFold = false
+			switch msg.String() {
+			case "a":
+				if heading := m.viewport.ToggleFold(); heading != "" {
+					m.statusBar.SetMessage("Toggled fold: " + heading)
+				} else {
+					m.statusBar.SetMessage("No heading above the current position")
+				}
+				return m, nil
+			case "R":
+				m.viewport.UnfoldAll()
+				m.statusBar.SetMessage("Expanded all sections")
+				return m, nil
+			case "M":
+				m.viewport.FoldAll()
+				m.statusBar.SetMessage("Folded all sections")
+				return m, nil
 			}
-			return m, tea.Batch(cmds...)
+			m.statusBar.SetMessage("Ready")
 		}
 
-		// If input modal is showing, handle it first
-		if m.showInput {
-			var cmd tea.Cmd
-			m.inputModal, cmd = m.inputModal.Update(msg)
-			if cmd != nil {
-				cmds = append(cmds, cmd)
+		// On image pages some keys change how the image is drawn
+		if !m.addressBar.IsFocused() && !m.linkNumbers && m.viewingImage() {
+			if cmd, ok := m.imageKey(msg.String()); ok {
+				return m, cmd
 			}
-			return m, tea.Batch(cmds...)
+		}
+
+		// Text pasted into the terminal (Ctrl+Shift+V in most terminals)
+		// while the page has focus is navigated to straight away
+		if msg.Paste && !m.addressBar.IsFocused() && !m.linkNumbers {
+			return m, m.pasteAndGo(string(msg.Runes))
 		}
 
 		// Global key handlers
@@ -276,7 +485,9 @@ func (m *Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 				m.saveCurrentTabState()
 				m.tabBar.AddTab("", "New Tab")
 				m.loadTabState()
-				return m, nil
+				// New tabs suggest somewhere to go, without a history entry
+				m.isNavigating = true
+				return m, m.navigate(discoverURL)
 			}
 
 		case "ctrl+w":
@@ -288,23 +499,14 @@ func (m *Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 					m.loadTabState()
 				} else {
 					// Last tab - quit application
-					m.saveSession()
-					m.quitting = true
-					return m, tea.Quit
+					return m, m.requestQuit()
 				}
-				return m, nil
+				return m, m.restoreActiveTab()
 			}
 
 		case "ctrl+c", "q":
-			if m.showHelp {
-				m.showHelp = false
-				return m, nil
-			}
 			if !m.addressBar.IsFocused() && !m.linkNumbers {
-				// Save session before quitting
-				m.saveSession()
-				m.quitting = true
-				return m, tea.Quit
+				return m, m.requestQuit()
 			}
 
 		case "ctrl+l":
@@ -323,15 +525,13 @@ func (m *Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 				m.linkNumbers = true
 				m.linkInput = ""
 				m.statusBar.SetMessage("Enter link number: ")
-				// Viewport moves down by 1 line due to help text
-				m.viewport.SetYPosition(5)
 				return m, nil
 			}
 
 		case "esc":
-			// Exit help modal
-			if m.showHelp {
-				m.showHelp = false
+			// Cancel a pending automatic retry
+			if m.cancelRetry() {
+				m.statusBar.SetMessage("Retry cancelled")
 				return m, nil
 			}
 			// Exit link number mode
@@ -339,8 +539,16 @@ func (m *Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 				m.linkNumbers = false
 				m.linkInput = ""
 				m.statusBar.SetMessage("Ready")
-				// Viewport moves back up when help text disappears
-				m.viewport.SetYPosition(4)
+				return m, nil
+			}
+			// Abandon a Gopher fetch still in progress
+			if m.stopFetch != nil && m.stopFetchID == m.fetchID {
+				m.cancelFetch()
+				return m, nil
+			}
+			// Drop the highlights of a word jump
+			if m.viewport.HasSearch() && !m.addressBar.IsFocused() {
+				m.viewport.ClearSearch()
 				return m, nil
 			}
 
@@ -356,9 +564,7 @@ func (m *Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 				num, _ := strconv.Atoi(msg.String())
 				tabIdx := num - 1
 				if tabIdx >= 0 && tabIdx < len(m.tabBar.GetTabs()) {
-					m.saveCurrentTabState()
-					m.tabBar.SwitchTab(tabIdx)
-					m.loadTabState()
+					return m, m.activateTab(tabIdx)
 				}
 				return m, nil
 			}
@@ -379,15 +585,32 @@ func (m *Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 					m.linkNumbers = false
 					m.linkInput = ""
 					m.statusBar.SetMessage("Ready")
-					// Viewport moves back up when help text disappears
-					m.viewport.SetYPosition(4)
 					return m, m.viewport.SelectLinkByNumber(num)
 				}
 				m.linkNumbers = false
 				m.linkInput = ""
 				m.statusBar.SetMessage("Invalid link number")
-				// Viewport moves back up when help text disappears
-				m.viewport.SetYPosition(4)
+				return m, nil
+			}
+			// Expand a collapsed quote run on screen
+			if !m.addressBar.IsFocused() && m.viewport.ExpandVisibleQuote() {
+				return m, nil
+			}
+
+		case "o":
+			// Open the link menu for the typed link number
+			if m.linkNumbers {
+				num, _ := strconv.Atoi(m.linkInput)
+				m.linkNumbers = false
+				m.linkInput = ""
+				link, ok := m.viewport.LinkByNumber(num)
+				if !ok {
+					m.statusBar.SetMessage("Invalid link number")
+					return m, nil
+				}
+				m.statusBar.SetMessage("Ready")
+				m.linkMenu.Show(link.URL, link.Text)
+				ui.OpenModal(m.modals, m.linkMenu)
 				return m, nil
 			}
 
@@ -395,6 +618,9 @@ func (m *Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 			// Reload current page
 			if !m.addressBar.IsFocused() && !m.linkNumbers && m.currentURL != "" {
 				m.isNavigating = true
+				if m.config.Get().Performance.Revalidate {
+					return m, m.revalidate()
+				}
 				return m, m.navigate(m.currentURL)
 			}
 
@@ -412,21 +638,22 @@ func (m *Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 				if m.bookmarks.HasBookmark(m.currentURL) {
 					// Remove bookmark
 					if err := m.bookmarks.Remove(m.currentURL); err == nil {
-						m.statusBar.SetMessage("Bookmark removed")
-					} else {
-						m.statusBar.SetError("Failed to remove bookmark")
+						return m, m.notify("Bookmark removed")
 					}
+					m.statusBar.SetError("Failed to remove bookmark")
 				} else {
 					// Add bookmark
 					title := "Untitled"
 					if m.currentDoc != nil {
-						title = gemini.GetTitle(m.currentDoc)
+						title = pageTitle(m.currentDoc)
 					}
 					if err := m.bookmarks.Add(m.currentURL, title, nil); err == nil {
-						m.statusBar.SetMessage("Bookmark added")
-					} else {
-						m.statusBar.SetError("Failed to add bookmark")
+						if m.currentDoc != nil && m.config.Get().General.IndexBookmarks {
+							m.indexDocument(m.currentURL, m.currentDoc)
+						}
+						return m, m.notify("Bookmark added")
 					}
+					m.statusBar.SetError("Failed to add bookmark")
 				}
 				return m, nil
 			}
@@ -481,20 +708,263 @@ func (m *Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 				m.viewport.PageUp()
 			}
 
+		case "w":
+			// Toggle soft-wrap / truncate for the current page
+			if !m.addressBar.IsFocused() && !m.linkNumbers && m.currentDoc != nil {
+				if m.viewport.ToggleTruncate() {
+					m.statusBar.SetMessage("Wrap off: long lines truncated (< > to scroll)")
+				} else {
+					m.statusBar.SetMessage("Wrap on")
+				}
+				return m, nil
+			}
+
+		case "t":
+			// Toggle between drawn tables and the raw original text
+			if !m.addressBar.IsFocused() && !m.linkNumbers {
+				m.viewport.SetRenderTables(!m.viewport.RenderTables())
+				if m.viewport.RenderTables() {
+					m.statusBar.SetMessage("Tables: drawn")
+				} else {
+					m.statusBar.SetMessage("Tables: raw")
+				}
+				return m, nil
+			}
+
+		case "f":
+			// Toggle inline / footnote link rendering
+			if !m.addressBar.IsFocused() && !m.linkNumbers {
+				m.viewport.SetFootnoteLinks(!m.viewport.FootnoteLinks())
+				if m.viewport.FootnoteLinks() {
+					m.statusBar.SetMessage("Links: footnotes")
+				} else {
+					m.statusBar.SetMessage("Links: inline")
+				}
+				return m, nil
+			}
+
+		case "z":
+			// Start a fold command (za, zR, zM)
+			if !m.addressBar.IsFocused() && !m.linkNumbers && m.currentDoc != nil {
+				m.pendingFold = true
+				m.statusBar.SetMessage("z: a toggle section • R expand all • M fold all")
+				return m, nil
+			}
+
+		case "<":
+			if !m.addressBar.IsFocused() && !m.linkNumbers {
+				m.viewport.ScrollLeft()
+			}
+
+		case ">":
+			if !m.addressBar.IsFocused() && !m.linkNumbers {
+				m.viewport.ScrollRight()
+			}
+
 		case "?":
-			// Toggle help modal
+			// Show help modal
+			if !m.addressBar.IsFocused() && !m.linkNumbers {
+				m.helpModal.Show()
+				ui.OpenModal(m.modals, m.helpModal)
+				return m, nil
+			}
+
+		case "i":
+			// Show page info modal
+			if !m.addressBar.IsFocused() && !m.linkNumbers && m.currentDoc != nil {
+				m.pageInfoModal.Show(m.currentDoc, m.strictMode)
+				ui.OpenModal(m.modals, m.pageInfoModal)
+				return m, nil
+			}
+
+		case "|", "V":
+			// Read the page, or its source, in $PAGER
+			if !m.addressBar.IsFocused() && !m.linkNumbers && m.currentDoc != nil {
+				return m, m.openInPager(msg.String() == "V")
+			}
+
+		case "A":
+			// Cycle the active tab's auto-reload interval
+			if !m.addressBar.IsFocused() && !m.linkNumbers {
+				return m, m.cycleWatch()
+			}
+
+		case "I":
+			// Open the identities manager
+			if !m.addressBar.IsFocused() && !m.linkNumbers {
+				m.identitiesModal.Show(m.identityInfos())
+				ui.OpenModal(m.modals, m.identitiesModal)
+				return m, nil
+			}
+
+		case "s":
+			// Stop the sound playing and clear the queue
+			if !m.addressBar.IsFocused() && !m.linkNumbers && m.audio.playing != nil {
+				m.stopAudio()
+				return m, m.notify("Stopped playback")
+			}
+
+		case "Z":
+			// List the entries of the last downloaded archive
+			if !m.addressBar.IsFocused() && !m.linkNumbers {
+				m.openArchive()
+				return m, nil
+			}
+
+		case "C":
+			// Open the cache browser
+			if !m.addressBar.IsFocused() && !m.linkNumbers {
+				m.openCacheBrowser()
+				return m, nil
+			}
+
+		case "K":
+			// Open the capsule tokens modal
+			if !m.addressBar.IsFocused() && !m.linkNumbers {
+				m.openPrivacy()
+				return m, nil
+			}
+
+		case "ctrl+s":
+			// Show the recent status messages
+			if !m.addressBar.IsFocused() && !m.linkNumbers {
+				m.statusLogModal.Show(m.statusBar.History())
+				ui.OpenModal(m.modals, m.statusLogModal)
+				return m, nil
+			}
+
+		case "E":
+			// Export the open tabs as a gemtext page
 			if !m.addressBar.IsFocused() && !m.linkNumbers {
-				m.showHelp = !m.showHelp
+				m.exportSession()
+				return m, nil
+			}
+
+		case "O":
+			// Open every link of an exported session in tabs
+			if !m.addressBar.IsFocused() && !m.linkNumbers {
+				return m, m.promptSessionImport()
+			}
+
+		case "U":
+			// Upload the page, a file or edited text with Titan
+			if !m.addressBar.IsFocused() && !m.linkNumbers {
+				return m, m.openTitan("")
+			}
+
+		case "ctrl+p":
+			// Open the privacy report
+			if !m.addressBar.IsFocused() && !m.linkNumbers {
+				m.openPrivacyReport()
+				return m, nil
+			}
+
+		case "D":
+			// List downloads in progress and finished
+			if !m.addressBar.IsFocused() && !m.linkNumbers {
+				m.openDownloads()
+				return m, nil
+			}
+
+		case "W":
+			// View the page in the program its type is handled by
+			if !m.addressBar.IsFocused() && !m.linkNumbers {
+				return m, m.viewCurrentExternally()
+			}
+
+		case "c":
+			// Manage the certificates trusted on first use
+			if !m.addressBar.IsFocused() && !m.linkNumbers {
+				m.openCertificates()
+				return m, nil
+			}
+
+		case "T":
+			// List trusted certificates by expiry date
+			if !m.addressBar.IsFocused() && !m.linkNumbers {
+				m.openCertExpiry()
+				return m, nil
+			}
+
+		case "Y":
+			// Rotate to the next identity on the current host
+			if !m.addressBar.IsFocused() && !m.linkNumbers {
+				return m, m.rotateIdentity()
+			}
+
+		case "X":
+			// Send the next request without a client certificate
+			if !m.addressBar.IsFocused() && !m.linkNumbers {
+				m.toggleAnonymous()
+				return m, nil
+			}
+
+		case "S":
+			// Toggle strict mode
+			if !m.addressBar.IsFocused() && !m.linkNumbers {
+				m.toggleStrictMode()
 				return m, nil
 			}
 
 		case "ctrl+f":
 			// Open search modal
 			if !m.addressBar.IsFocused() && !m.linkNumbers && m.currentDoc != nil {
-				m.showSearch = true
-				return m, m.searchModal.Show(m.currentDoc)
+				cmd := m.searchModal.Show(m.currentDoc)
+				ui.OpenModal(m.modals, m.searchModal)
+				return m, cmd
+			}
+
+		case "*", "#":
+			// Jump to the next or previous occurrence of the selected word
+			if !m.addressBar.IsFocused() && !m.linkNumbers && m.currentDoc != nil {
+				word, current, total := m.viewport.JumpToWord(msg.String() == "*")
+				switch {
+				case word == "":
+					m.statusBar.SetMessage("Double-click a word or search for one first")
+				case total == 0:
+					m.statusBar.SetMessage(fmt.Sprintf("%q does not occur as a word", word))
+				default:
+					m.statusBar.SetMessage(fmt.Sprintf("%s: %d of %d", word, current, total))
+				}
+				return m, nil
+			}
+
+		case "P":
+			// Paste and go: navigate to the clipboard contents
+			if !m.addressBar.IsFocused() && !m.linkNumbers {
+				return m, ui.ReadClipboard(true)
+			}
+
+		case "ctrl+o":
+			// Open the link list
+			if !m.addressBar.IsFocused() && !m.linkNumbers && m.currentDoc != nil {
+				m.linkListModal.Show(m.currentDoc)
+				ui.OpenModal(m.modals, m.linkListModal)
+				return m, nil
 			}
 
+		case "+", "=", "-":
+			if !m.addressBar.IsFocused() && !m.linkNumbers {
+				step := 1
+				if msg.String() == "-" {
+					step = -1
+				}
+				return m, m.zoom(step)
+			}
+
+		case "f11":
+			// Hide or show all the bars around the page
+			return m, m.toggleBar("all")
+
+		case "alt+t":
+			return m, m.toggleBar("tab")
+
+		case "alt+a":
+			return m, m.toggleBar("address")
+
+		case "alt+s":
+			return m, m.toggleBar("status")
+
 		case "ctrl+y":
 			// Copy page content to clipboard
 			if !m.addressBar.IsFocused() && !m.linkNumbers && m.currentDoc != nil {
@@ -504,21 +974,16 @@ func (m *Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		case "ctrl+h":
 			// Show history modal
 			if !m.addressBar.IsFocused() && !m.linkNumbers {
-				m.showHelp = false
-				m.showHistory = true
 				m.historyModal.Show(m.history.GetAll())
 				m.historyModal.SetSize(m.width, m.height)
+				ui.OpenModal(m.modals, m.historyModal)
 				return m, nil
 			}
 
 		case "b":
 			// Toggle bookmarks modal
 			if !m.addressBar.IsFocused() && !m.linkNumbers {
-				// Close help modal if open
-				m.showHelp = false
-				m.showBookmarks = true
-				m.bookmarksModal.Show(m.bookmarks.GetAll())
-				return m, nil
+				return m, m.showBookmarks()
 			}
 
 		case "ctrl+tab":
@@ -526,10 +991,8 @@ func (m *Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 			if !m.addressBar.IsFocused() && !m.linkNumbers {
 				tabs := m.tabBar.GetTabs()
 				if len(tabs) > 1 {
-					m.saveCurrentTabState()
 					nextIdx := (m.tabBar.GetActiveIndex() + 1) % len(tabs)
-					m.tabBar.SwitchTab(nextIdx)
-					m.loadTabState()
+					return m, m.activateTab(nextIdx)
 				}
 				return m, nil
 			}
@@ -539,166 +1002,486 @@ func (m *Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 			if !m.addressBar.IsFocused() && !m.linkNumbers {
 				tabs := m.tabBar.GetTabs()
 				if len(tabs) > 1 {
-					m.saveCurrentTabState()
 					prevIdx := m.tabBar.GetActiveIndex() - 1
 					if prevIdx < 0 {
 						prevIdx = len(tabs) - 1
 					}
-					m.tabBar.SwitchTab(prevIdx)
-					m.loadTabState()
+					return m, m.activateTab(prevIdx)
 				}
 				return m, nil
 			}
 		}
 
-	case tea.QuitMsg:
-		// Window was closed - save session before quitting
-		m.saveSession()
-		return m, nil
+	case tea.ResumeMsg:
+		return m, m.resume()
 
 	case tea.WindowSizeMsg:
+		firstSize := m.width == 0
 		m.width = msg.Width
 		m.height = msg.Height
 
 		// Update component sizes (subtract 2 to account for terminal edges)
 		m.addressBar.SetWidth(m.width - 2)
 
-		// Calculate viewport height: total - tab bar (1) - address bar (3) - status bar (1)
-		viewportHeight := m.height - 5
-		if viewportHeight < 1 {
-			viewportHeight = 1
-		}
-		m.viewport.SetSize(m.width, viewportHeight)
-
-		// Set viewport Y position (tab bar (1) + address bar with border (3) = 4 lines)
-		// Or 5 lines if in link number mode (help text is shown above tab bar)
-		if m.linkNumbers {
-			m.viewport.SetYPosition(5)
+		// Re-rendering the page is the expensive part, so wait for the
+		// size to settle unless this is the initial size
+		var cmd tea.Cmd
+		if firstSize {
+			m.resizeViewport()
 		} else {
-			m.viewport.SetYPosition(4)
+			cmd = m.scheduleResize()
 		}
 
+		m.breadcrumb.SetWidth(m.width)
+
 		m.statusBar.SetWidth(m.width)
 		m.tabBar.SetSize(m.width, 1)
 		m.helpModal.SetSize(m.width, m.height)
+		m.pageInfoModal.SetSize(m.width, m.height)
 		m.inputModal.SetSize(m.width, m.height)
 		m.bookmarksModal.SetSize(m.width, m.height)
 		m.searchModal.SetSize(m.width, m.height)
 		m.historyModal.SetSize(m.width, m.height)
-
+		m.identitiesModal.SetSize(m.width, m.height)
+		m.cacheModal.SetSize(m.width, m.height)
+		m.archiveModal.SetSize(m.width, m.height)
+		m.privacyModal.SetSize(m.width, m.height)
+		m.privacyReportModal.SetSize(m.width, m.height)
+		m.certExpiryModal.SetSize(m.width, m.height)
+		m.certificateModal.SetSize(m.width, m.height)
+		m.downloadModal.SetSize(m.width, m.height)
+		m.statusLogModal.SetSize(m.width, m.height)
+		m.titanModal.SetSize(m.width, m.height)
+		m.linkMenu.SetSize(m.width, m.height)
+		m.confirmModal.SetSize(m.width, m.height)
+		m.linkListModal.SetSize(m.width, m.height)
+
+		return m, cmd
+
+	case resizeSettledMsg:
+		// Only the last resize of a burst re-renders the page
+		if msg.id == m.resizeID {
+			m.resizeViewport()
+		}
 		return m, nil
 
 	case ui.InputSubmitMsg:
 		// User submitted input
-		m.showInput = false
+		m.inputModal.Hide()
+		if m.importingSession {
+			m.importingSession = false
+			if path := strings.TrimSpace(msg.Input); path != "" {
+				return m, m.importSession(path)
+			}
+			return m, nil
+		}
 		if m.pendingInputURL != "" && msg.Input != "" {
+			// Remember the answer for next time, unless it was sensitive
+			if !m.inputModal.IsSensitive() {
+				m.inputHistory.Add(m.pendingInputURL, msg.Input)
+			}
 			// Append input as URL-encoded query parameter
 			inputURL := m.pendingInputURL + "?" + url.QueryEscape(msg.Input)
 			m.pendingInputURL = ""
 			return m, m.navigate(inputURL)
 		}
 		m.pendingInputURL = ""
+		m.inputSession = nil
 		return m, nil
 
+	case ui.InputEditMsg:
+		return m, m.editInput(msg.Text)
+
+	case editorClosedMsg:
+		return m, m.handleEditorClosed(msg)
+
 	case ui.InputCancelMsg:
-		// User cancelled input
-		m.showInput = false
+		// User cancelled input; abandon the whole session and stay on the
+		// page that started it
+		m.inputModal.Hide()
 		m.pendingInputURL = ""
-		m.statusBar.SetMessage("Input cancelled")
+		if m.importingSession {
+			m.importingSession = false
+			m.statusBar.SetMessage("Import cancelled")
+			return m, nil
+		}
+		if m.inputSession != nil && m.inputSession.step > 1 {
+			m.statusBar.SetMessage(fmt.Sprintf("Input session cancelled after %d prompts from %s", m.inputSession.step, m.inputSession.host))
+		} else {
+			m.statusBar.SetMessage("Input cancelled")
+		}
+		m.inputSession = nil
 		return m, nil
 
+	case ui.StatusClickMsg:
+		switch msg.Zone {
+		case ui.StatusZoneURL:
+			if m.currentURL != "" {
+				if err := ui.WriteClipboard(gopher.EscapeURL(m.currentURL)); err != nil {
+					m.statusBar.SetError(fmt.Sprintf("Failed to copy URL: %v", err))
+				} else {
+					cmds = append(cmds, m.notify("Copied URL"))
+				}
+			}
+		case ui.StatusZoneScroll:
+			if m.viewport.GetScrollPercent() < 0.5 {
+				m.viewport.GotoBottom()
+			} else {
+				m.viewport.GotoTop()
+			}
+		case ui.StatusZoneLoading:
+			m.cancelFetch()
+		case ui.StatusZoneCache:
+			m.openCacheBrowser()
+		case ui.StatusZoneIdentity:
+			m.promptIdentity(m.currentURL, nil, fmt.Sprintf("Presenting %s on %s", m.presentedIdentity(), urlutil.Host(m.currentURL)))
+		}
+		return m, tea.Batch(cmds...)
+
 	case ui.HistorySelectedMsg:
 		// User selected a history entry to navigate to
-		m.showHistory = false
 		m.statusBar.SetMessage("Navigating to history entry...")
 		return m, m.navigate(msg.URL)
 
 	case ui.BookmarkSelectedMsg:
 		// User selected a bookmark to navigate to
-		m.showBookmarks = false
 		m.statusBar.SetMessage("Navigating to bookmark...")
 		return m, m.navigate(msg.URL)
 
 	case ui.BookmarkDeleteMsg:
-		// User deleted a bookmark
-		if err := m.bookmarks.Remove(msg.URL); err == nil {
-			m.statusBar.SetMessage("Bookmark deleted")
-			// Refresh the bookmarks modal with updated list
-			m.bookmarksModal.Show(m.bookmarks.GetAll())
-		} else {
-			m.statusBar.SetError("Failed to delete bookmark")
+		// User asked to delete a bookmark
+		title := msg.URL
+		if bookmark := m.bookmarks.Get(msg.URL); bookmark != nil && bookmark.Title != "" {
+			title = bookmark.Title
 		}
+		m.confirm("Delete bookmark?", title+"\n"+msg.URL, "Delete", bookmarkRemoveMsg{url: msg.URL})
 		return m, nil
 
-	case ui.SearchSubmitMsg:
-		// User submitted a search
-		m.viewport.SetSearch(msg.Query, m.searchModal.GetResults(), msg.CaseSensitive)
+	case ui.HistoryDeleteMsg:
+		// User asked to delete history entries
+		m.confirm("Delete from history?",
+			fmt.Sprintf("%d visit(s) to %s will be forgotten.", msg.Visits, msg.Label),
+			"Delete", historyRemoveMsg{urls: msg.URLs, label: msg.Label})
 		return m, nil
 
-	case ui.SearchNavigateMsg:
-		// User is navigating search results
-		if msg.Direction == "next" || msg.Direction == "prev" {
-			// Navigation is handled by the search modal
-			result := m.searchModal.GetCurrentResult()
-			if result != nil {
-				m.viewport.GoToSearchResult(result)
-			}
-		} else if msg.Direction == "goto" {
-			// Go to selected result
-			result := m.searchModal.GetCurrentResult()
-			if result != nil {
-				m.viewport.GoToSearchResult(result)
+	case bookmarkIndexMsg:
+		return m, m.handleBookmarkIndex(msg)
+
+	case ui.HistoryTitleMsg:
+		// User renamed a page in history
+		m.history.SetTitle(msg.URL, msg.Title)
+		m.historyModal.Refresh(m.history.GetAll())
+		m.statusBar.SetMessage("History title updated")
+		return m, nil
+
+	case ui.BookmarkEditMsg:
+		// User saved changes to a bookmark
+		if err := m.bookmarks.Update(msg.OldURL, msg.URL, msg.Title, msg.Tags); err == nil {
+			if msg.URL != msg.OldURL {
+				// The new page is indexed when the bookmarks are next opened
+				m.bookmarkIndex.Remove(msg.OldURL)
 			}
+			m.statusBar.SetMessage("Bookmark updated")
+			// The list is sorted by title, so the bookmark may have moved
+			m.bookmarksModal.Refresh(m.bookmarks.GetAll())
+			m.bookmarksModal.SelectURL(msg.URL)
+		} else {
+			m.statusBar.SetError(fmt.Sprintf("Failed to update bookmark: %v", err))
 		}
 		return m, nil
 
-	case ui.SearchCloseMsg:
-		// User closed search modal
-		m.showSearch = false
-		m.viewport.ClearSearch()
+	case ui.IdentityCreateMsg:
+		m.createIdentity(msg)
 		return m, nil
 
-	case ui.NavigateMsg:
-		// Handle navigation
+	case ui.IdentityDeleteMsg:
+		m.deleteIdentity(msg)
+		return m, nil
+
+	case ui.CacheOpenMsg:
+		m.statusBar.SetMessage("Opening cached copy...")
 		return m, m.navigate(msg.URL)
 
-	case fetchCompleteMsg:
-		// Handle fetch completion
-		m.statusBar.SetLoading(false)
+	case ui.CacheInvalidateMsg, ui.CacheClearMsg:
+		m.handleCacheAction(msg)
+		return m, nil
 
-		// Show cache status
-		if msg.fromCache {
-			m.statusBar.SetMessage("Loaded from cache")
+	case ui.ArchiveExtractMsg:
+		return m, m.extractEntry(msg)
+
+	case audioFinishedMsg:
+		if msg.id != m.audio.id {
+			return m, nil
 		}
+		if msg.err != nil && m.audio.playing != nil {
+			m.statusBar.SetError(fmt.Sprintf("Player failed on %s: %v", m.audio.playing.name(), msg.err))
+		}
+		return m, m.playNext()
 
+	case archiveExtractedMsg:
 		if msg.err != nil {
 			m.statusBar.SetError(msg.err.Error())
-			m.redirectCount = 0 // Reset redirect count on error
-			m.saveCurrentTabState()
 			return m, nil
 		}
+		return m, m.notify("Extracted " + filepath.Base(msg.path))
 
-		// Handle Gopher protocol
-		if msg.protocol == "gopher" {
-			// Parse the document using Gopher parser
-			parser := gopher.NewParser(msg.resp.URL)
-			doc, err := parser.Parse(msg.resp)
+	case ui.TokenConsentMsg, ui.TokenRevokeMsg:
+		m.handleTokenAction(msg)
+		return m, nil
+
+	case ui.TitanUploadMsg:
+		return m, m.upload(msg)
+
+	case ui.TitanEditMsg:
+		return m, m.editTitan(msg)
+
+	case titanEditedMsg:
+		m.handleTitanEdited(msg)
+		return m, nil
+
+	case titanDoneMsg:
+		return m, m.handleTitanDone(msg)
+
+	case ui.StatusCopyMsg:
+		if err := ui.WriteClipboard(msg.Text); err != nil {
+			m.statusBar.SetError(fmt.Sprintf("Failed to copy message: %v", err))
+			return m, nil
+		}
+		return m, m.notify("Copied message")
+
+	case ui.PrivacyPurgeMsg:
+		m.confirmPurge(msg.Host)
+		return m, nil
+
+	case ui.CertForgetMsg:
+		m.confirmCertForget(msg.Host)
+		return m, nil
+
+	case ui.CertVerifyMsg:
+		return m, m.verifyCert(msg.Host)
+
+	case ui.CertificateUntrustMsg:
+		m.confirmCertForget(msg.Host)
+		return m, nil
+
+	case ui.CertificateTrustMsg:
+		m.confirmCertChange(msg.Host, "")
+		return m, nil
+
+	case certVerifiedMsg:
+		return m, m.handleCertVerified(msg)
+
+	case quitConfirmedMsg, bookmarkRemoveMsg, historyRemoveMsg, certAcceptMsg, redirectConfirmedMsg, privacyPurgeMsg, certForgetMsg, sessionConfirmedMsg, backgroundRedirectConfirmedMsg:
+		return m, m.handleConfirmed(msg)
+
+	case ui.IdentityScopesMsg:
+		m.saveIdentityScopes(msg)
+		return m, nil
+
+	case ui.IdentityChosenMsg:
+		return m, m.useIdentity(msg)
+
+	case ui.SearchSubmitMsg:
+		// User submitted a search
+		m.viewport.SetSearch(msg.Query, m.searchModal.GetResults(), msg.CaseSensitive)
+		return m, nil
+
+	case ui.SearchNavigateMsg:
+		// User is navigating search results
+		if msg.Direction == "next" || msg.Direction == "prev" {
+			// Navigation is handled by the search modal
+			result := m.searchModal.GetCurrentResult()
+			if result != nil {
+				m.viewport.GoToSearchResult(result)
+			}
+		} else if msg.Direction == "goto" {
+			// Go to selected result
+			result := m.searchModal.GetCurrentResult()
+			if result != nil {
+				m.viewport.GoToSearchResult(result)
+			}
+		}
+		return m, nil
+
+	case ui.SearchCloseMsg:
+		// User closed search modal
+		m.viewport.ClearSearch()
+		return m, nil
+
+	case ui.PasteMsg:
+		if msg.Err != nil {
+			m.statusBar.SetError(fmt.Sprintf("Failed to read clipboard: %v", msg.Err))
+			return m, nil
+		}
+		if msg.Go {
+			return m, m.pasteAndGo(msg.Text)
+		}
+		// Otherwise the focused address bar inserts it below
+
+	case ui.LinkMenuMsg:
+		// Right-click on a link
+		m.linkMenu.Show(msg.URL, msg.Label)
+		ui.OpenModal(m.modals, m.linkMenu)
+		return m, nil
+
+	case ui.LinkActionMsg:
+		return m, m.doLinkAction(msg)
+
+	case ui.CopySelectionMsg:
+		// Double- or triple-clicked text in the content
+		what := "word"
+		if msg.Line {
+			what = "line"
+		}
+		if err := ui.WriteClipboard(msg.Text); err != nil {
+			m.statusBar.SetError(fmt.Sprintf("Failed to copy %s: %v", what, err))
+			return m, nil
+		}
+		return m, m.notify("Copied " + what)
+
+	case ui.ToastExpiredMsg:
+		m.toast.Update(msg)
+		return m, nil
+
+	case ui.LinkListOpenMsg:
+		return m, m.openInBackground(msg.URLs)
+
+	case backgroundFetchMsg:
+		return m, m.handleBackgroundFetch(msg)
+
+	case imageDecodedMsg:
+		m.handleImageDecoded(msg)
+		return m, nil
+
+	case downloadProgressMsg:
+		m.downloads.UpdateProgress(msg.id, msg.written)
+		m.refreshDownloads()
+		return m, waitForStream(msg.events)
+
+	case downloadCompleteMsg:
+		return m, m.handleDownloadComplete(msg)
+
+	case ui.DownloadCancelMsg:
+		m.cancelDownload(msg.ID)
+		return m, nil
+
+	case ui.DownloadRetryMsg:
+		return m, m.retryDownload(msg.ID)
+
+	case ui.NavigateMsg:
+		// Handle navigation
+		if msg.Typed {
+			target, err := m.resolveInput(msg.URL)
+			if err != nil {
+				m.statusBar.SetError(err.Error())
+				return m, nil
+			}
+			return m, m.navigate(target)
+		}
+		return m, m.navigate(msg.URL)
+
+	case retryFetchMsg:
+		// Ignore retries that were cancelled or superseded by a new navigation
+		if !m.retryPending || msg.id != m.retryID {
+			return m, nil
+		}
+		m.retryPending = false
+		m.retryAttempt = msg.attempt
+		cmd := m.navigate(msg.url)
+		m.statusBar.SetMessage(fmt.Sprintf("Retrying %d/%d: %s...", msg.attempt, m.config.Get().Network.RetryAttempts, msg.url))
+		return m, cmd
+
+	case fetchCompleteMsg:
+		if msg.fetchID != 0 && msg.fetchID == m.stopFetchID {
+			m.stopFetch = nil
+		}
+		// Drop results of a fetch the user cancelled
+		if msg.fetchID != 0 && msg.fetchID == m.cancelledFetch {
+			return m, nil
+		}
+
+		// Handle fetch completion
+		m.statusBar.SetLoading(false)
+
+		// Show cache status
+		if msg.fromCache {
+			m.statusBar.SetMessage("Loaded from cache")
+		}
+
+		if msg.err != nil {
+			if cmd := m.scheduleRetry(msg); cmd != nil {
+				return m, cmd
+			}
+			var headerErr *gemini.HeaderError
+			if errors.As(msg.err, &headerErr) {
+				m.showErrorPage(headerErr)
+				return m, nil
+			}
+			if errors.Is(msg.err, gemini.ErrCertificateChanged) {
+				m.redirectCount = 0
+				m.confirmCertChange(urlutil.Host(msg.url), msg.url)
+				return m, nil
+			}
+			var choiceErr *gemini.IdentityChoiceError
+			if errors.As(msg.err, &choiceErr) {
+				m.redirectCount = 0
+				m.promptIdentity(choiceErr.URL, choiceErr.Names, fmt.Sprintf("Several identities are scoped to %s:", choiceErr.URL))
+				return m, nil
+			}
+			m.statusBar.SetError(msg.err.Error())
+			m.inputSession = nil
+			m.redirectCount = 0 // Reset redirect count on error
+			m.saveCurrentTabState()
+			return m, nil
+		}
+
+		// Any final response ends an input session; redirects may continue it
+		if msg.protocol != "gemini" || !(gemini.IsInputStatus(msg.resp.Status) || gemini.IsRedirectStatus(msg.resp.Status)) {
+			m.inputSession = nil
+		}
+
+		// Count the page for about:stats
+		if !strings.HasPrefix(msg.resp.URL, "about:") && !strings.HasPrefix(msg.resp.URL, bookScheme+":") && !strings.HasPrefix(msg.resp.URL, "data:") {
+			m.stats.RecordFetch(msg.protocol, len(msg.resp.Body), msg.fromCache)
+		}
+
+		// Handle Gopher protocol
+		if msg.protocol == "gopher" {
+			// Parse the document using Gopher parser
+			doc, err := m.newGopherParser(msg.resp.URL).Parse(msg.resp)
 			if err != nil {
 				m.statusBar.SetError(fmt.Sprintf("Failed to parse Gopher document: %v", err))
 				return m, nil
 			}
 
+			// Sound is played, and other files are saved to the download
+			// directory instead of being shown as an empty page
+			if doc.ItemType == "s" {
+				m.isNavigating = false
+				return m, m.queueAudio(msg.resp.URL, msg.resp.Body)
+			}
+			if gopher.IsBinaryType(doc.ItemType) {
+				m.isNavigating = false
+				m.statusBar.SetMessage("Saving " + msg.resp.URL + "...")
+				return m, m.saveFetched(msg.resp.URL, msg.resp.Body)
+			}
+
+			unchanged := m.revalidated(msg)
 			m.currentDoc = doc
 			m.currentURL = msg.resp.URL
-			m.viewport.SetDocument(doc)
+			if unchanged {
+				m.viewport.GrowDocument(doc)
+			} else {
+				m.viewport.SetDocument(doc)
+			}
 			m.statusBar.SetURL(m.currentURL)
+			m.breadcrumb.SetURL(m.currentURL)
 			if !m.addressBar.IsFocused() {
 				m.addressBar.SetValue(m.currentURL)
 			}
 
-			// Get title from URL for Gopher
-			title := msg.resp.URL
-			m.statusBar.SetMessage(fmt.Sprintf("Loaded: %s", title))
+			title := gopher.GetTitle(doc)
+			m.statusBar.SetMessage(fmt.Sprintf("%s: %s", loadedStatus(unchanged), title))
 
 				// Reset redirect count on successful response
 				m.redirectCount = 0
@@ -720,45 +1503,49 @@ func (m *Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		if gemini.IsSuccessStatus(msg.resp.Status) {
 			mimeType := gemini.GetMIMEType(msg.resp)
 
-			// Check if this is an image
-			if renderer.IsImageMIME(mimeType) {
-				// Render image
-				imgRenderer := renderer.NewImageRenderer(m.width-4, m.height-8)
-				renderedImage, err := imgRenderer.RenderImage(msg.resp.Body)
-				if err != nil {
-					m.statusBar.SetError(fmt.Sprintf("Failed to render image: %v", err))
-					return m, nil
-				}
+			// Sound plays in the background, leaving the page as it is
+			if strings.HasPrefix(mimeType, "audio/") {
+				m.redirectCount = 0
+				m.isNavigating = false
+				return m, m.queueAudio(msg.resp.URL, msg.resp.Body)
+			}
 
-				// Create a document with the rendered image as preformatted text
-				doc := &types.Document{
-					URL:      msg.resp.URL,
-					RawBody:  msg.resp.Body,
-					MIMEType: mimeType,
-					Lines:    []types.Line{},
-					Links:    []types.Line{},
-				}
+			// Books are unpacked and opened at their cover
+			if gempub.IsGempub(mimeType, msg.resp.URL) {
+				m.redirectCount = 0
+				m.isNavigating = false
+				return m, m.openGempub(msg.resp)
+			}
 
-				// Split rendered image into lines
-				for _, line := range strings.Split(renderedImage, "\n") {
-					doc.Lines = append(doc.Lines, types.Line{
-						Type: types.LineText,
-						Text: line,
-						Raw:  line,
-					})
-				}
+			// Files that are not text are saved to the download directory
+			// rather than shown as garbled text
+			if mimeType != "" && !strings.HasPrefix(mimeType, "text/") && !renderer.IsImageMIME(mimeType) {
+				m.redirectCount = 0
+				m.isNavigating = false
+				m.statusBar.SetMessage("Saving " + msg.resp.URL + "...")
+				return m, m.saveFetched(msg.resp.URL, msg.resp.Body)
+			}
+
+			// Check if this is an image
+			if renderer.IsImageMIME(mimeType) {
+				// Show a placeholder at once; the image is decoded off the
+				// UI thread and swapped in when ready
+				doc := imagePlaceholder(msg.resp, mimeType)
+				m.imageView.PanX, m.imageView.PanY = 0, 0
+				cmd := m.decodeImage(doc, msg.resp, mimeType)
 
 			m.currentDoc = doc
 			m.currentURL = msg.resp.URL
 			m.viewport.SetDocument(doc)
 			m.statusBar.SetURL(m.currentURL)
+			m.breadcrumb.SetURL(m.currentURL)
 			if !m.addressBar.IsFocused() {
 				m.addressBar.SetValue(m.currentURL)
 			}
 
 				// Use filename or URL as title
 				title := msg.resp.URL
-				m.statusBar.SetMessage(fmt.Sprintf("Image loaded: %s", mimeType))
+				m.statusBar.SetMessage(fmt.Sprintf("Decoding image: %s", mimeType))
 
 					// Reset redirect count on successful response
 					m.redirectCount = 0
@@ -771,6 +1558,7 @@ func (m *Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 
 					// Save tab state
 					m.saveCurrentTabState()
+					return m, cmd
 			} else {
 				// Parse text document
 				parser := gemini.NewParser(msg.resp.URL)
@@ -780,17 +1568,24 @@ func (m *Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 					return m, nil
 				}
 
+			unchanged := m.revalidated(msg)
 			m.currentDoc = doc
 			m.currentURL = msg.resp.URL
-			m.viewport.SetDocument(doc)
+			if unchanged || (msg.fetchID != 0 && msg.fetchID == m.streamedFetch) {
+				// Already shown, in part or whole; keep the reader's place
+				m.viewport.GrowDocument(doc)
+			} else {
+				m.viewport.SetDocument(doc)
+			}
 			m.statusBar.SetURL(m.currentURL)
+			m.breadcrumb.SetURL(m.currentURL)
 			if !m.addressBar.IsFocused() {
 				m.addressBar.SetValue(m.currentURL)
 			}
 
 				// Get title for status
-				title := gemini.GetTitle(doc)
-				m.statusBar.SetMessage(fmt.Sprintf("Loaded: %s", title))
+				title := pageTitle(doc)
+				m.statusBar.SetMessage(fmt.Sprintf("%s: %s", loadedStatus(unchanged), title))
 
 					// Reset redirect count on successful response
 					m.redirectCount = 0
@@ -804,6 +1599,7 @@ func (m *Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 					// Save tab state
 					m.saveCurrentTabState()
 				}
+			m.recordViolations(gemini.Violations(msg.resp))
 
 		} else if gemini.IsRedirectStatus(msg.resp.Status) {
 			// Handle redirect with loop protection
@@ -821,9 +1617,40 @@ func (m *Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 				return m, nil
 			}
 
-			m.statusBar.SetMessage(fmt.Sprintf("Redirecting to: %s (%d/%d)", newURL, m.redirectCount, m.redirectLimit))
+			// A chain that comes back to a URL it passed would never end
+			if len(m.redirectChain) == 0 {
+				m.redirectChain = append(m.redirectChain, msg.resp.URL)
+			}
+			target := resolveRedirect(msg.resp.URL, newURL)
+			if slices.Contains(m.redirectChain, target) {
+				m.statusBar.SetError(fmt.Sprintf("Redirect loop: %s redirects back to %s", msg.resp.URL, target))
+				m.redirectCount = 0
+				return m, nil
+			}
+			m.redirectChain = append(m.redirectChain, target)
+
+			// Leaving for another protocol or host is only done with
+			// consent, except from the random capsule endpoint, which is
+			// followed to be sent elsewhere
+			randomCapsule := msg.resp.URL == m.config.Get().General.RandomCapsuleURL
+			if crossing := redirectCrossing(msg.resp.URL, newURL); crossing != "" && !randomCapsule {
+				m.redirectCount = 0
+				m.statusBar.SetMessage("Redirect to " + crossing + ": " + newURL)
+				m.confirm("Follow redirect?",
+					fmt.Sprintf("%s redirects to %s:\n%s", msg.resp.URL, crossing, newURL),
+					"Follow", redirectConfirmedMsg{url: target})
+				return m, nil
+			}
+
+			m.captureToken(msg.resp.URL, newURL)
+			m.redirectViolations = append(m.redirectViolations, gemini.Violations(msg.resp)...)
+			if msg.fromCache {
+				m.statusBar.SetMessage(fmt.Sprintf("Redirecting to: %s (cached permanent redirect)", newURL))
+			} else {
+				m.statusBar.SetMessage(fmt.Sprintf("Redirecting to: %s (%d/%d)", newURL, m.redirectCount, m.redirectLimit))
+			}
 			// Don't reset redirectCount - keep it for the next navigate call
-			return m, m.navigate(newURL)
+			return m, m.navigate(target)
 
 		} else if gemini.IsInputStatus(msg.resp.Status) {
 			// Handle input request (status 10 or 11)
@@ -838,95 +1665,137 @@ func (m *Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 			// Store the URL that triggered input request
 			m.pendingInputURL = msg.resp.URL
 
+			// Consecutive prompts from the same host form one input session
+			host := urlutil.Host(msg.resp.URL)
+			if m.inputSession != nil && m.inputSession.host == host {
+				m.inputSession.step++
+			} else {
+				m.inputSession = &inputSession{host: host, step: 1}
+			}
+			m.inputModal.SetStep(m.inputSession.step, host)
+
 			// Show input modal
-			m.showInput = true
-			return m, m.inputModal.Show(prompt, sensitive)
+			var previous []string
+			if !sensitive {
+				previous = m.inputHistory.Get(m.pendingInputURL)
+			}
+			cmd := m.inputModal.Show(prompt, sensitive, previous)
+			ui.OpenModal(m.modals, m.inputModal)
+			return m, cmd
+
+		} else if msg.resp.Status == 60 && len(m.identities.Match(msg.resp.URL)) == 0 {
+			// Ask which identity to present instead of just failing
+			m.redirectCount = 0
+			reason := fmt.Sprintf("%s asks for a client certificate", urlutil.Host(msg.resp.URL))
+			if msg.resp.Meta != "" {
+				reason += ": " + msg.resp.Meta
+			}
+			m.promptIdentity(msg.resp.URL, nil, reason)
 
 		} else {
 			// Handle error status
 			m.redirectCount = 0 // Reset redirect count on error
 			statusMsg := gemini.GetStatusMessage(msg.resp.Status)
+			if msg.fromCache {
+				statusMsg += " (cached)"
+			}
 			m.statusBar.SetError(fmt.Sprintf("%s: %s", statusMsg, msg.resp.Meta))
 		}
 
 		return m, nil
 
+	case pagerClosedMsg:
+		if msg.err != nil {
+			m.statusBar.SetError(fmt.Sprintf("Pager failed: %v", msg.err))
+		}
+		return m, nil
+
+	case viewerFetchedMsg:
+		return m, m.handleViewerFetched(msg)
+
+	case viewerClosedMsg:
+		if msg.err != nil {
+			m.statusBar.SetError(fmt.Sprintf("Viewer of %s failed: %v", msg.name, msg.err))
+		}
+		return m, nil
+
+	case sessionEndedMsg:
+		if msg.err != nil {
+			m.statusBar.SetError(fmt.Sprintf("%s failed: %v", msg.session.Description(), msg.err))
+		} else {
+			m.statusBar.SetMessage("Session ended: " + msg.session.Description())
+		}
+		return m, nil
+
+	case streamUpdateMsg:
+		// Stale streams are still drained so their fetch can finish
+		if msg.fetchID == m.fetchID && msg.fetchID != m.cancelledFetch {
+			if msg.protocol == "gopher" {
+				m.showProgress(msg.resp)
+			} else {
+				m.showPartialPage(msg.resp)
+			}
+		}
+		return m, waitForStream(msg.events)
+
+	case watchTickMsg:
+		return m, m.handleWatchTick()
+
 	case externalLinkOpenedMsg:
 		// External link was opened successfully
 		m.statusBar.SetMessage(fmt.Sprintf("Opened external link: %s", msg.url))
 		return m, nil
 
 	case tea.MouseMsg:
-		// If history modal is showing, handle mouse events there
-		if m.showHistory {
-			var cmd tea.Cmd
-			m.historyModal, cmd = m.historyModal.Update(msg)
-			if cmd != nil {
-				cmds = append(cmds, cmd)
-			}
-			// Check if modal was closed
-			if !m.historyModal.IsVisible() {
-				m.showHistory = false
-			}
-			return m, tea.Batch(cmds...)
+		// An open modal takes the mouse too, if it handles it at all
+		if m.modals.Top() != nil {
+			return m, m.modals.Update(msg)
 		}
 
-		// If bookmarks modal is showing, handle mouse events there
-		if m.showBookmarks {
-			var cmd tea.Cmd
-			m.bookmarksModal, cmd = m.bookmarksModal.Update(msg)
-			if cmd != nil {
-				cmds = append(cmds, cmd)
-			}
-			// Check if modal was closed
-			if !m.bookmarksModal.IsVisible() {
-				m.showBookmarks = false
+		// Middle-click on the address bar pastes the primary selection over
+		// the current URL, ready to be edited or submitted
+		l := m.layout()
+		if msg.Action == tea.MouseActionPress && msg.Button == tea.MouseButtonMiddle && l.onAddressBar(msg.Y) {
+			if !m.addressBar.IsFocused() {
+				m.linkNumbers = false
+				m.linkInput = ""
+				m.addressBar.SetValue(m.currentURL)
+				cmds = append(cmds, m.addressBar.Focus())
 			}
-			return m, tea.Batch(cmds...)
-		}
-
-		// If search modal is showing, handle mouse events there
-		if m.showSearch {
 			var cmd tea.Cmd
-			m.searchModal, cmd = m.searchModal.Update(msg)
-			if cmd != nil {
-				cmds = append(cmds, cmd)
-			}
-			// Check if modal was closed
-			if !m.searchModal.IsVisible() {
-				m.showSearch = false
-			}
+			m.addressBar, cmd = m.addressBar.Update(msg)
+			cmds = append(cmds, cmd)
 			return m, tea.Batch(cmds...)
 		}
 
 		if msg.Action == tea.MouseActionPress && msg.Button == tea.MouseButtonLeft {
-			// Check if click is on tab bar (line 0)
-			if msg.Y == 0 {
+			// Check if click is on the tab bar
+			if l.tabBar >= 0 && msg.Y == l.tabBar {
+				// Save the current tab first: the tab bar switches tabs itself
+				m.saveCurrentTabState()
+
 				// Pass to tab bar for handling
 				var cmd tea.Cmd
 				m.tabBar, cmd = m.tabBar.Update(msg)
 				if cmd != nil {
 					// Check if this is a tab switch message
-					if switchMsg, ok := cmd().(ui.TabSwitchMsg); ok {
-						// Save current tab state and load new tab state
-						m.saveCurrentTabState()
-						m.tabBar.SwitchTab(switchMsg.Index)
+					if _, ok := cmd().(ui.TabSwitchMsg); ok {
+						// Load new tab state
 						m.loadTabState()
+						return m, m.restoreActiveTab()
 					}
 				}
 				return m, nil
 			}
 
-			// Check if click is on address bar (lines 1-3)
-			if msg.Y >= 1 && msg.Y <= 3 {
+			// Check if click is on the address bar
+			if l.onAddressBar(msg.Y) {
 				if !m.addressBar.IsFocused() {
 					// Focus address bar, same as Ctrl+L
 					if m.linkNumbers {
 						m.linkNumbers = false
 						m.linkInput = ""
 						m.statusBar.SetMessage("Ready")
-						// Viewport moves back up when help text disappears
-						m.viewport.SetYPosition(4)
 					}
 					m.addressBar.SetValue(m.currentURL)
 					focusCmd := m.addressBar.Focus()
@@ -941,6 +1810,23 @@ func (m *Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 				return m, tea.Batch(cmds...)
 			}
 
+			// Check if click is on the breadcrumb bar
+			if l.breadcrumb >= 0 && msg.Y == l.breadcrumb {
+				var cmd tea.Cmd
+				m.breadcrumb, cmd = m.breadcrumb.Update(msg)
+				if m.addressBar.IsFocused() {
+					m.addressBar.Blur()
+				}
+				return m, cmd
+			}
+
+			// Check if click is on the status bar (last line)
+			if l.statusBar && msg.Y == m.height-1 {
+				var cmd tea.Cmd
+				m.statusBar, cmd = m.statusBar.Update(msg)
+				return m, cmd
+			}
+
 			// Click anywhere else - blur address bar if focused
 			if m.addressBar.IsFocused() {
 				m.addressBar.Blur()
@@ -984,41 +1870,58 @@ func (m *Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 
 // View renders the application
 func (m *Model) View() string {
-	if m.quitting {
-		return "Thanks for using starsearch!\n"
+	// Redraws for background activity are limited to a few a second
+	if m.holdFrame {
+		return m.frame
 	}
-
-	// Show history modal if active (highest priority for overlay)
-	if m.showHistory {
-		return m.historyModal.View()
+	m.frame = m.render()
+	if m.asciiGlyphs {
+		m.frame = ui.PlainGlyphs(m.frame)
 	}
+	m.frameAt = time.Now()
+	return m.frame
+}
 
-	// Show bookmarks modal if active (highest priority for overlay)
-	if m.showBookmarks {
-		return m.bookmarksModal.View()
+// render draws the whole screen
+func (m *Model) render() string {
+	if m.quitting {
+		return "Thanks for using starsearch!\n"
 	}
 
-		// Show search modal if active
-	if m.showSearch {
-		return m.searchModal.View()
+	// The topmost modal covers the page, or is drawn over it
+	view := ""
+	if m.modals.Top() != nil {
+		view = m.modals.View(m.pageView)
+	} else {
+		view = m.pageView()
 	}
+	return m.toast.Overlay(view)
+}
 
-	// Show input modal if active
-	if m.showInput {
-		return m.inputModal.View()
+// pageView renders the bars and the page content
+func (m *Model) pageView() string {
+	// Layout components vertically
+	l := m.layout()
+	var components []string
+	if l.tabBar >= 0 {
+		components = append(components, m.tabBar.View())
 	}
-
-	// Show help modal if active
-	if m.showHelp {
-		return m.helpModal.View()
+	if l.addressBar >= 0 {
+		components = append(components, m.addressBar.View())
 	}
-
-	// Layout components vertically
-	components := []string{
-		m.tabBar.View(),
-		m.addressBar.View(),
-		m.viewport.View(),
-		m.statusBar.View(),
+	if l.breadcrumb >= 0 {
+		components = append(components, m.breadcrumb.View())
+	}
+	components = append(components, m.viewport.View())
+	if l.statusBar {
+		if m.pageCache != nil {
+			m.statusBar.SetCacheUsage(m.pageCache.GetSize(), m.pageCache.MaxSize())
+		}
+		m.statusBar.SetHoverURL(m.viewport.HoveredURL())
+		network := m.config.Get().Network
+		m.statusBar.SetOnion(network.SocksProxy != "" && urlutil.IsOnion(m.currentURL), network.TorIsolation)
+		m.statusBar.SetIdentity(m.presentedIdentity())
+		components = append(components, m.statusBar.View())
 	}
 
 	// Add help text if in link mode
@@ -1027,11 +1930,22 @@ func (m *Model) View() string {
 			Foreground(lipgloss.Color("12")).
 			Background(lipgloss.Color("235")).
 			Padding(0, 1)
-		helpText := helpStyle.Render(" Type link number and press Enter (ESC to cancel) ")
+		helpText := helpStyle.Render(" Type link number and press Enter (o: more actions) • u: up a level • U/r: root (ESC to cancel) ")
 		components = append([]string{helpText}, components...)
 	}
 
-	return lipgloss.JoinVertical(lipgloss.Left, components...)
+	view := lipgloss.JoinVertical(lipgloss.Left, components...)
+	if l.addressBar >= 0 {
+		view = m.addressBar.OverlaySuggestions(view, l.addressBar+addressBarHeight)
+	}
+	return view
+}
+
+// newGopherParser creates a Gopher parser following the strictness setting
+func (m *Model) newGopherParser(baseURL string) *gopher.Parser {
+	parser := gopher.NewParser(baseURL)
+	parser.Strict = m.config.Get().Network.GopherStrictness == "strict"
+	return parser
 }
 
 // navigate fetches and displays a URL
@@ -1040,16 +1954,51 @@ func (m *Model) navigate(urlStr string) tea.Cmd {
 	bypassCache := m.forceReload
 	m.forceReload = false // Reset force reload flag
 
+	m.fetchID++
+	fetchID := m.fetchID
+
+	// Violations of redirects only carry over to the page they lead to
+	if m.redirectCount == 0 {
+		m.redirectViolations = nil
+		m.redirectChain = nil
+	}
+
+	// A fresh navigation supersedes any retry still waiting to fire
+	attempt := m.retryAttempt
+	m.retryAttempt = 0
+	if attempt == 0 {
+		m.cancelRetry()
+	}
+
+	// Internal pages are generated, not fetched
+	if cmd := m.aboutPage(urlStr, fetchID); cmd != nil {
+		return cmd
+	}
+	if strings.HasPrefix(urlStr, bookScheme+"://") {
+		return m.bookPage(urlStr, fetchID)
+	}
+	if len(urlStr) > 5 && strings.EqualFold(urlStr[:5], "data:") {
+		return m.dataPage(urlStr, fetchID)
+	}
+
+	// Gopher URLs typed or pasted with bare spaces and the like are escaped
+	urlStr = gopher.EscapeURL(urlStr)
+
 	if !bypassCache && m.pageCache != nil && m.config.Get().Performance.EnableCache {
 		if cachedResp, found := m.pageCache.Get(urlStr); found {
 			// Serve from cache
 			m.statusBar.SetMessage("Loaded from cache: " + urlStr)
 			return func() tea.Msg {
-				return fetchCompleteMsg{resp: cachedResp, err: nil, protocol: "gemini", fromCache: true}
+				return fetchCompleteMsg{resp: cachedResp, err: nil, protocol: "gemini", fromCache: true, fetchID: fetchID}
 			}
 		}
 	}
 
+	// Telnet and phone book links open no page
+	if session, ok := gopher.SessionFor(urlStr); ok {
+		return m.openSession(session)
+	}
+
 	// Parse URL to detect protocol
 	parsedURL, err := url.Parse(urlStr)
 	if err == nil && parsedURL.Scheme != "" {
@@ -1059,14 +2008,28 @@ func (m *Model) navigate(urlStr string) tea.Cmd {
 			m.statusBar.SetLoading(true)
 			m.statusBar.SetMessage("Fetching " + urlStr + "...")
 
+			// Large items report their progress and can be abandoned
+			client := m.gopherFetcher(m.activeTabID())
+			if streamer, ok := client.(contextFetcher); ok {
+				return m.fetchGopher(streamer, urlStr, attempt, fetchID)
+			}
+
 			return func() tea.Msg {
-				resp, err := m.gopherClient.Fetch(urlStr)
-				return fetchCompleteMsg{resp: resp, err: err, protocol: "gopher", fromCache: false}
+				release := m.scheduler.Acquire(urlutil.Host(urlStr), scheduler.Interactive)
+				start := time.Now()
+				resp, err := client.Fetch(urlStr)
+				m.recordLatency(urlStr, time.Since(start), err)
+				release()
+				return fetchCompleteMsg{resp: resp, err: err, protocol: "gopher", fromCache: false, url: urlStr, attempt: attempt, fetchID: fetchID}
 			}
 
 		case "gemini":
 			// Handle Gemini protocol (continue below)
 
+		case "titan":
+			// Titan links are places to upload to, not pages
+			return m.openTitan(urlStr)
+
 		default:
 			// Handle other external protocols (http, https, etc.)
 			return m.openExternalURL(urlStr)
@@ -1078,39 +2041,217 @@ func (m *Model) navigate(urlStr string) tea.Cmd {
 		urlStr = "gemini://" + urlStr
 	}
 
+	// Capsules allowed to keep a token get it back on requests without a
+	// query of their own
+	urlStr = m.tokens.Apply(urlStr)
+
 	m.statusBar.SetLoading(true)
 	m.statusBar.SetMessage("Fetching " + urlStr + "...")
 
+	// An anonymous request presents no identity, whatever is scoped to it
+	if m.anonymousNext {
+		m.anonymousNext = false
+		m.identities.SkipOnce(urlStr)
+		m.statusBar.SetMessage("Fetching " + urlStr + " anonymously...")
+	}
+
+	// Slow pages are shown as they arrive when the client can stream
+	client := m.geminiFetcher(m.activeTabID())
+	if streamer, ok := client.(streamFetcher); ok {
+		return m.fetchStreaming(streamer, urlStr, attempt, fetchID)
+	}
+
 	return func() tea.Msg {
-		resp, err := m.client.Fetch(urlStr)
+		release := m.scheduler.Acquire(urlutil.Host(urlStr), scheduler.Interactive)
+		start := time.Now()
+		resp, err := client.Fetch(urlStr)
+		m.recordLatency(urlStr, time.Since(start), err)
+		release()
 		// Cache successful responses
 		if err == nil && resp != nil && m.pageCache != nil && m.config.Get().Performance.EnableCache {
 			m.pageCache.Set(urlStr, resp, int64(m.config.Get().Performance.CacheTTL))
 		}
-		return fetchCompleteMsg{resp: resp, err: err, protocol: "gemini", fromCache: false}
+		return fetchCompleteMsg{resp: resp, err: err, protocol: "gemini", fromCache: false, url: urlStr, attempt: attempt, fetchID: fetchID}
 	}
 }
 
+// showErrorPage renders a malformed-header failure in the viewport
+func (m *Model) showErrorPage(headerErr *gemini.HeaderError) {
+	resp := &types.Response{
+		Status: 20,
+		Meta:   "text/gemini",
+		Body:   headerErr.Page(),
+		URL:    headerErr.URL,
+	}
+	doc, err := gemini.NewParser(headerErr.URL).Parse(resp)
+	if err != nil {
+		m.statusBar.SetError(headerErr.Error())
+		return
+	}
+
+	m.currentDoc = doc
+	m.currentURL = headerErr.URL
+	m.viewport.SetDocument(doc)
+	m.statusBar.SetURL(m.currentURL)
+	m.breadcrumb.SetURL(m.currentURL)
+	if !m.addressBar.IsFocused() {
+		m.addressBar.SetValue(m.currentURL)
+	}
+	var violations []string
+	if headerErr.IsViolation() {
+		violations = append(violations, headerErr.Title())
+	}
+	m.recordViolations(violations)
+	m.statusBar.SetError(headerErr.Title())
+	m.redirectCount = 0
+	m.isNavigating = false
+	m.saveCurrentTabState()
+}
+
+// breadcrumbHeight returns the number of lines used by the breadcrumb bar
+func (m *Model) breadcrumbHeight() int {
+	if m.config.Get().UI.ShowBreadcrumbs {
+		return 1
+	}
+	return 0
+}
+
+// navigateUp leaves link number mode and navigates to the parent of the
+// current URL, or to the capsule root if toRoot is set
+func (m *Model) navigateUp(toRoot bool) tea.Cmd {
+	m.linkNumbers = false
+	m.linkInput = ""
+
+	if m.currentURL == "" {
+		m.statusBar.SetMessage("No page loaded")
+		return nil
+	}
+
+	var target string
+	var err error
+	if toRoot {
+		target, err = urlutil.RootURL(m.currentURL)
+	} else {
+		target, err = urlutil.ParentURL(m.currentURL)
+	}
+	if err != nil {
+		m.statusBar.SetError(err.Error())
+		return nil
+	}
+
+	if target == m.currentURL {
+		m.statusBar.SetMessage("Already at the root")
+		return nil
+	}
+	return m.navigate(target)
+}
+
+// doLinkAction carries out an entry chosen from the link menu
+func (m *Model) doLinkAction(msg ui.LinkActionMsg) tea.Cmd {
+	switch msg.Action {
+	case ui.LinkOpen:
+		return m.navigate(msg.URL)
+
+	case ui.LinkOpenNewTab:
+		m.saveCurrentTabState()
+		m.tabBar.AddTab(msg.URL, msg.URL)
+		m.loadTabState()
+		return m.navigate(msg.URL)
+
+	case ui.LinkOpenExternal:
+		m.statusBar.SetMessage("Opening externally: " + msg.URL)
+		return m.openExternalURL(msg.URL)
+
+	case ui.LinkViewExternal:
+		return m.viewLinkExternally(msg.URL)
+
+	case ui.LinkDownload:
+		m.statusBar.SetMessage("Downloading " + msg.URL + "...")
+		return m.downloadLink(msg.URL)
+
+	case ui.LinkCopyURL:
+		if err := ui.WriteClipboard(gopher.EscapeURL(msg.URL)); err != nil {
+			m.statusBar.SetError(fmt.Sprintf("Failed to copy URL: %v", err))
+			return nil
+		}
+		return m.notify("Copied link URL")
+
+	case ui.LinkBookmark:
+		if m.bookmarks.HasBookmark(msg.URL) {
+			m.statusBar.SetMessage("Already bookmarked")
+			return nil
+		}
+		title := msg.Label
+		if title == "" {
+			title = msg.URL
+		}
+		if err := m.bookmarks.Add(msg.URL, title, nil); err == nil {
+			return m.notify("Bookmark added: " + title)
+		}
+		m.statusBar.SetError("Failed to add bookmark")
+	}
+	return nil
+}
+
+// pasteAndGo navigates to pasted text, treating it like address bar input
+func (m *Model) pasteAndGo(text string) tea.Cmd {
+	text = urlutil.CleanPasted(text)
+	if text == "" {
+		m.statusBar.SetMessage("Clipboard is empty")
+		return nil
+	}
+
+	target, err := m.resolveInput(text)
+	if err != nil {
+		m.statusBar.SetError(err.Error())
+		return nil
+	}
+	return m.navigate(target)
+}
+
+// resolveInput turns typed address bar input into a URL. "!name query" searches
+// with the named engine; free text containing spaces goes to the default engine.
+func (m *Model) resolveInput(input string) (string, error) {
+	input = strings.TrimSpace(input)
+
+	if strings.HasPrefix(input, "!") {
+		name, query, _ := strings.Cut(input[1:], " ")
+		engine := m.config.GetSearchEngine(name)
+		if name == "" || engine == nil {
+			return "", fmt.Errorf("unknown search engine: !%s", name)
+		}
+		return searchURL(engine.URL, strings.TrimSpace(query)), nil
+	}
+
+	if strings.ContainsAny(input, " \t") && !strings.Contains(input, "://") {
+		if engine := m.config.GetSearchEngine(""); engine != nil {
+			return searchURL(engine.URL, input), nil
+		}
+	}
+
+	return input, nil
+}
+
+// searchURL builds a search request URL, sending the query as status 10 input
+func searchURL(engineURL, query string) string {
+	if query == "" {
+		return engineURL
+	}
+	return engineURL + "?" + url.QueryEscape(query)
+}
+
 // openExternalURL opens a URL in the system's default browser
 func (m *Model) openExternalURL(urlStr string) tea.Cmd {
 	return func() tea.Msg {
-		var cmd *exec.Cmd
-
-		switch runtime.GOOS {
-		case "linux", "freebsd", "openbsd", "netbsd":
-			cmd = exec.Command("xdg-open", urlStr)
-		case "darwin":
-			cmd = exec.Command("open", urlStr)
-		case "windows":
-			cmd = exec.Command("cmd", "/c", "start", urlStr)
-		default:
+		cmd, err := systemOpener(urlStr)
+		if err != nil {
 			return fetchCompleteMsg{
 				resp: nil,
 				err:  fmt.Errorf("unsupported platform for opening external links: %s", runtime.GOOS),
 			}
 		}
 
-		err := cmd.Start()
+		err = cmd.Start()
 		if err != nil {
 			return fetchCompleteMsg{
 				resp: nil,
@@ -1124,14 +2265,59 @@ func (m *Model) openExternalURL(urlStr string) tea.Cmd {
 	}
 }
 
+// systemOpener builds the command that opens target, a URL or a file, with
+// the program the desktop associates with it
+func systemOpener(target string) (*exec.Cmd, error) {
+	switch runtime.GOOS {
+	case "linux", "freebsd", "openbsd", "netbsd":
+		return exec.Command("xdg-open", target), nil
+	case "darwin":
+		return exec.Command("open", target), nil
+	case "windows":
+		// Not cmd /c start, which would take & and ^ in target as its own
+		return exec.Command("rundll32", "url.dll,FileProtocolHandler", target), nil
+	}
+	return nil, fmt.Errorf("unsupported platform: %s", runtime.GOOS)
+}
+
+// cancelFetch abandons the fetch in progress; its result is discarded
+// when it arrives
+func (m *Model) cancelFetch() {
+	m.cancelledFetch = m.fetchID
+	if m.stopFetch != nil && m.stopFetchID == m.fetchID {
+		m.stopFetch()
+		m.stopFetch = nil
+	}
+	m.cancelRetry()
+	m.isNavigating = false
+	m.redirectCount = 0
+	m.statusBar.SetLoading(false)
+	m.statusBar.SetMessage("Fetch cancelled")
+}
+
 // copyPageContent copies the current page content to the clipboard
 func (m *Model) copyPageContent() tea.Cmd {
 	if m.currentDoc == nil {
 		return nil
 	}
 
-	_ = clipboard.WriteAll(string(m.currentDoc.RawBody))
-	return nil
+	if err := ui.WriteClipboard(string(m.currentDoc.RawBody)); err != nil {
+		m.statusBar.SetError(fmt.Sprintf("Failed to copy page: %v", err))
+		return nil
+	}
+	return m.notify("Copied page content")
+}
+
+// notify shows a short-lived notification, in the status bar if toasts are
+// turned off
+func (m *Model) notify(text string) tea.Cmd {
+	duration := m.config.Get().UI.ToastDurationMs
+	if duration < 0 {
+		m.statusBar.SetMessage(text)
+		return nil
+	}
+	m.statusBar.Log(text, false)
+	return m.toast.Show(text, time.Duration(duration)*time.Millisecond)
 }
 
 // externalLinkOpenedMsg is sent when an external link is opened
@@ -1145,6 +2331,24 @@ type fetchCompleteMsg struct {
 	err       error
 	protocol  string // "gemini" or "gopher"
 	fromCache bool   // Whether response came from cache
+	url       string // Requested URL, used to retry failed fetches
+	attempt   int    // Retry number of this fetch (0 for the first try)
+	fetchID   int    // Model.fetchID when the fetch started (0 if not from navigate)
+}
+
+// inputSession tracks a chain of status 1x prompts from one host, such as
+// a capsule's multi-step wizard
+type inputSession struct {
+	host string
+	step int // 1-based number of the prompt currently shown
+}
+
+// pageTitle names doc the way its protocol allows
+func pageTitle(doc *types.Document) string {
+	if doc.Metadata.Protocol == "gopher" {
+		return gopher.GetTitle(doc)
+	}
+	return gemini.GetTitle(doc)
 }
 
 // saveCurrentTabState saves the current browsing state to the active tab
@@ -1155,12 +2359,13 @@ func (m *Model) saveCurrentTabState() {
 		scroll := m.viewport.GetScrollOffset()
 		title := ""
 		if doc != nil {
-			title = gemini.GetTitle(doc)
+			title = pageTitle(doc)
 		} else if url != "" {
 			title = url
 		}
 		idx := m.tabBar.GetActiveIndex()
 		m.tabBar.UpdateTab(idx, url, title, doc, scroll)
+		m.enforceTabBudget()
 	}
 }
 
@@ -1178,6 +2383,7 @@ func (m *Model) loadTabState() {
 			m.viewport.SetDocument(nil)
 		}
 		m.statusBar.SetURL(m.currentURL)
+		m.breadcrumb.SetURL(m.currentURL)
 		m.addressBar.SetValue(m.currentURL)
 	}
 }
@@ -1196,3 +2402,11 @@ func (m *Model) saveSession() {
 
 	_ = m.sessionManager.Save(tabs, activeIndex) // Ignore errors
 }
+
+// saveCache records which cached pages were read last, so the least
+// recently used go first when the cache is next loaded
+func (m *Model) saveCache() {
+	if m.pageCache != nil {
+		_ = m.pageCache.Save() // Ignore errors
+	}
+}
@@ -0,0 +1,2380 @@
+package app
+
+import (
+	"archive/zip"
+	"bytes"
+	"context"
+	"encoding/base64"
+	"encoding/binary"
+	"encoding/json"
+	"fmt"
+	"image"
+	"image/color"
+	"image/jpeg"
+	"image/png"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/charmbracelet/x/ansi"
+	"starsearch/internal/cache"
+	"starsearch/internal/gemini"
+	"starsearch/internal/gopher"
+	"starsearch/internal/renderer"
+	"starsearch/internal/scheduler"
+	"starsearch/internal/storage"
+	"starsearch/internal/types"
+	"starsearch/internal/ui"
+)
+
+// fakeFetcher serves canned responses by URL and records every request
+type fakeFetcher struct {
+	mu        sync.Mutex
+	responses map[string]*types.Response
+	requests  []string
+}
+
+func (f *fakeFetcher) Fetch(urlStr string) (*types.Response, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.requests = append(f.requests, urlStr)
+	resp, ok := f.responses[urlStr]
+	if !ok {
+		return nil, fmt.Errorf("no response for %s", urlStr)
+	}
+	resp.URL = urlStr
+	return resp, nil
+}
+
+// streamingFetcher serves one page in chunks, pausing between them as a
+// slow "live" endpoint would
+type streamingFetcher struct {
+	chunks []string
+	pause  time.Duration
+}
+
+func (f *streamingFetcher) Fetch(urlStr string) (*types.Response, error) {
+	return f.FetchStream(urlStr, nil)
+}
+
+func (f *streamingFetcher) FetchStream(urlStr string, partial func(resp *types.Response)) (*types.Response, error) {
+	var body []byte
+	for i, chunk := range f.chunks {
+		if i > 0 {
+			time.Sleep(f.pause)
+		}
+		body = append(body, chunk...)
+		if partial != nil {
+			partial(&types.Response{Status: 20, Meta: "text/gemini", Body: body, URL: urlStr})
+		}
+	}
+	return &types.Response{Status: 20, Meta: "text/gemini", Body: body, URL: urlStr}, nil
+}
+
+// gemtext builds a successful text/gemini response
+func gemtext(body string) *types.Response {
+	return &types.Response{Status: 20, Meta: "text/gemini", Body: []byte(body)}
+}
+
+// newTestModel creates a model with its configuration in a temporary
+// directory and the given fakes in place of the network clients
+func newTestModel(t *testing.T, geminiFake, gopherFake *fakeFetcher) *Model {
+	t.Helper()
+	dir := t.TempDir()
+	t.Setenv("HOME", dir)
+	t.Setenv("XDG_CONFIG_HOME", dir)
+
+	m, err := NewModel("", "test")
+	if err != nil {
+		t.Fatalf("NewModel: %v", err)
+	}
+	m.client = geminiFake
+	m.gopherClient = gopherFake
+	m.pageCache = nil
+	// Toasts expire at once, so commands that show one settle quickly
+	m.config.Get().UI.ToastDurationMs = 1
+	// Draw the same glyphs whatever the locale the tests run in
+	m.asciiGlyphs = false
+	m.Update(tea.WindowSizeMsg{Width: 80, Height: 24})
+	return m
+}
+
+// run feeds the messages produced by cmd back into the model until no
+// commands are left
+func run(t *testing.T, m *Model, cmd tea.Cmd) {
+	t.Helper()
+	for steps := 0; cmd != nil; steps++ {
+		if steps > 50 {
+			t.Fatal("command chain did not settle")
+		}
+		msg := cmd()
+		if batch, ok := msg.(tea.BatchMsg); ok {
+			for _, c := range batch {
+				run(t, m, c)
+			}
+			return
+		}
+		_, cmd = m.Update(msg)
+	}
+}
+
+func TestNavigateShowsDocument(t *testing.T) {
+	fake := &fakeFetcher{responses: map[string]*types.Response{
+		"gemini://example.org/": gemtext("# Welcome\nHello from the fake\n=> /about.gmi About\n"),
+	}}
+	m := newTestModel(t, fake, &fakeFetcher{})
+
+	run(t, m, m.navigate("gemini://example.org/"))
+
+	if m.currentURL != "gemini://example.org/" {
+		t.Fatalf("currentURL = %q", m.currentURL)
+	}
+	if m.currentDoc == nil || len(m.currentDoc.Links) != 1 {
+		t.Fatalf("document not loaded: %+v", m.currentDoc)
+	}
+	if view := m.View(); !strings.Contains(view, "Hello from the fake") {
+		t.Errorf("page text missing from view:\n%s", view)
+	}
+}
+
+func TestNavigateFollowsRedirect(t *testing.T) {
+	fake := &fakeFetcher{responses: map[string]*types.Response{
+		"gemini://example.org/old": {Status: 31, Meta: "gemini://example.org/new"},
+		"gemini://example.org/new": gemtext("# Moved\n"),
+	}}
+	m := newTestModel(t, fake, &fakeFetcher{})
+
+	run(t, m, m.navigate("gemini://example.org/old"))
+
+	if m.currentURL != "gemini://example.org/new" {
+		t.Fatalf("currentURL = %q, want the redirect target", m.currentURL)
+	}
+	if len(fake.requests) != 2 {
+		t.Errorf("requests = %v, want the original and the target", fake.requests)
+	}
+}
+
+func TestStrictModeReportsRedirectViolations(t *testing.T) {
+	fake := &fakeFetcher{responses: map[string]*types.Response{
+		"gemini://example.org/old":        {Status: 31, Meta: "gemini://user@example.org/new"},
+		"gemini://user@example.org/new":   {Status: 20, Meta: "text/gemini; charset=utf-8", Body: []byte("# Moved\n")},
+		"gemini://example.org/no-charset": {Status: 20, Meta: "text/gemini", Body: []byte("# Plain\n")},
+	}}
+	m := newTestModel(t, fake, &fakeFetcher{})
+	m.strictMode = true
+
+	run(t, m, m.navigate("gemini://example.org/old"))
+	if len(m.currentDoc.Violations) != 1 || !strings.Contains(m.currentDoc.Violations[0], "userinfo") {
+		t.Errorf("violations = %q, want the redirect's userinfo", m.currentDoc.Violations)
+	}
+
+	run(t, m, m.navigate("gemini://example.org/no-charset"))
+	if len(m.currentDoc.Violations) != 1 || !strings.Contains(m.currentDoc.Violations[0], "charset") {
+		t.Errorf("violations = %q, want only the missing charset", m.currentDoc.Violations)
+	}
+}
+
+func TestAboutStats(t *testing.T) {
+	fake := &fakeFetcher{responses: map[string]*types.Response{
+		"gemini://example.org/": gemtext("# Home\n"),
+	}}
+	m := newTestModel(t, fake, &fakeFetcher{})
+
+	run(t, m, m.navigate("gemini://example.org/"))
+	run(t, m, m.navigate("about:stats"))
+
+	if m.currentURL != "about:stats" || len(fake.requests) != 1 {
+		t.Fatalf("currentURL = %q after requests %v, want about:stats served locally", m.currentURL, fake.requests)
+	}
+	if !strings.Contains(string(m.currentDoc.RawBody), "example.org (1 visit)") {
+		t.Errorf("stats page does not count the visit:\n%s", m.currentDoc.RawBody)
+	}
+}
+
+func TestNewTabOpensDiscover(t *testing.T) {
+	fake := &fakeFetcher{responses: map[string]*types.Response{
+		"gemini://random.example/": {Status: 30, Meta: "gemini://far.example/"},
+		"gemini://far.example/":    gemtext("# Somewhere\n"),
+	}}
+	m := newTestModel(t, fake, &fakeFetcher{})
+
+	_, cmd := m.Update(tea.KeyMsg{Type: tea.KeyCtrlT})
+	run(t, m, cmd)
+	if m.currentURL != discoverURL || len(fake.requests) != 0 {
+		t.Fatalf("new tab shows %q after requests %v, want %s served locally", m.currentURL, fake.requests, discoverURL)
+	}
+	page := string(m.currentDoc.RawBody)
+	for _, want := range []string{"=> gemini://warmedal.se/~antenna/ Antenna", "=> gemini://kennedy.gemi.dev/search Search Geminispace with kennedy", "random_capsule_url"} {
+		if !strings.Contains(page, want) {
+			t.Errorf("discover page lacks %q:\n%s", want, page)
+		}
+	}
+	if len(m.history.GetAll()) != 0 {
+		t.Error("opening a new tab added the discover page to the history")
+	}
+
+	// g? follows the configured random capsule endpoint
+	m.config.Get().General.RandomCapsuleURL = "gemini://random.example/"
+	for _, key := range []string{"g", "?"} {
+		_, cmd = m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune(key)})
+		run(t, m, cmd)
+	}
+	if m.currentURL != "gemini://far.example/" {
+		t.Errorf("random capsule led to %q", m.currentURL)
+	}
+}
+
+func TestFirstRunWelcomeTour(t *testing.T) {
+	fake := &fakeFetcher{}
+	m := newTestModel(t, fake, &fakeFetcher{})
+	if !m.firstRun {
+		t.Fatal("a launch without a config file is not the first run")
+	}
+
+	run(t, m, m.Init())
+	if m.currentURL != welcomeURL {
+		t.Fatalf("first launch shows %q, want %s", m.currentURL, welcomeURL)
+	}
+	if strings.Contains(string(m.currentDoc.RawBody), "[x]") {
+		t.Errorf("tour starts with steps done:\n%s", m.currentDoc.RawBody)
+	}
+
+	// Steps are checked off in place as they are done
+	m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("j")})
+	if m.currentURL != welcomeURL || !strings.Contains(string(m.currentDoc.RawBody), "[x] Scroll the page") {
+		t.Errorf("scrolling was not checked off:\n%s", m.currentDoc.RawBody)
+	}
+	_, cmd := m.Update(tea.KeyMsg{Type: tea.KeyCtrlT})
+	run(t, m, cmd)
+	run(t, m, m.navigate(welcomeURL))
+	if page := string(m.currentDoc.RawBody); !strings.Contains(page, "[x] Open a new tab") || !strings.Contains(page, fmt.Sprintf("2 of %d done", len(tourSteps))) {
+		t.Errorf("new tab was not checked off:\n%s", page)
+	}
+
+	// The config written on the first launch ends it
+	again, err := NewModel("", "test")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if again.firstRun {
+		t.Error("the second launch is still the first run")
+	}
+}
+
+func TestHoveringLinkShowsTarget(t *testing.T) {
+	fake := &fakeFetcher{responses: map[string]*types.Response{
+		"gemini://example.org/": gemtext("=> /next Next page\nPlain text\n"),
+	}}
+	m := newTestModel(t, fake, &fakeFetcher{})
+	run(t, m, m.navigate("gemini://example.org/"))
+	hover := func(y int) {
+		m.Update(tea.MouseMsg{X: 6, Y: y, Action: tea.MouseActionMotion, Button: tea.MouseButtonNone})
+	}
+
+	hover(m.layout().viewport)
+	m.View()
+	if !strings.Contains(m.statusBar.View(), "→ gemini://example.org/next") {
+		t.Errorf("status bar does not show the hovered link's target: %q", m.statusBar.View())
+	}
+	if !strings.Contains(m.viewport.View(), "\x1b[1;4m") {
+		t.Error("hovered link is not highlighted")
+	}
+
+	// Moving off the link shows the page's URL again
+	hover(m.layout().viewport + 1)
+	m.View()
+	if strings.Contains(m.statusBar.View(), "→") || strings.Contains(m.viewport.View(), "\x1b[1;4m") {
+		t.Error("hover outlived the pointer leaving the link")
+	}
+}
+
+func TestMultiClickSelectsText(t *testing.T) {
+	fake := &fakeFetcher{responses: map[string]*types.Response{
+		"gemini://example.org/": gemtext("=> /next Next page\nPlain text here\n"),
+	}}
+	m := newTestModel(t, fake, &fakeFetcher{})
+	run(t, m, m.navigate("gemini://example.org/"))
+	click := func(x int) tea.Msg {
+		y := m.layout().viewport + 1
+		m.Update(tea.MouseMsg{X: x, Y: y, Action: tea.MouseActionPress, Button: tea.MouseButtonLeft})
+		_, cmd := m.Update(tea.MouseMsg{X: x, Y: y, Action: tea.MouseActionRelease, Button: tea.MouseButtonLeft})
+		if cmd == nil {
+			return nil
+		}
+		return cmd()
+	}
+
+	if msg := click(7); msg != nil {
+		t.Fatalf("a single click produced %#v", msg)
+	}
+	msg, ok := click(7).(ui.CopySelectionMsg)
+	if !ok || msg.Text != "text" || msg.Line {
+		t.Errorf("double click gave %#v, want the word \"text\"", msg)
+	}
+	if !strings.Contains(m.viewport.View(), "\x1b[7mtext\x1b[0m") {
+		t.Error("selected word is not highlighted")
+	}
+	msg, ok = click(7).(ui.CopySelectionMsg)
+	if !ok || msg.Text != "Plain text here" || !msg.Line {
+		t.Errorf("triple click gave %#v, want the whole line", msg)
+	}
+}
+
+func TestWordJump(t *testing.T) {
+	fake := &fakeFetcher{responses: map[string]*types.Response{
+		"gemini://example.org/": gemtext("Plain text here\nContext is not a match\nMore text, and Text\n"),
+	}}
+	m := newTestModel(t, fake, &fakeFetcher{})
+	run(t, m, m.navigate("gemini://example.org/"))
+	key := func(k string) string {
+		m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune(k)})
+		return ansi.Strip(m.statusBar.View())
+	}
+
+	if status := key("*"); !strings.Contains(status, "Double-click a word") {
+		t.Errorf("* without a selection: %q", status)
+	}
+
+	// Double-click "text", then step through its whole-word occurrences
+	y := m.layout().viewport
+	for range 2 {
+		m.Update(tea.MouseMsg{X: 7, Y: y, Action: tea.MouseActionPress, Button: tea.MouseButtonLeft})
+		m.Update(tea.MouseMsg{X: 7, Y: y, Action: tea.MouseActionRelease, Button: tea.MouseButtonLeft})
+	}
+	for _, step := range []struct{ key, want string }{
+		{"*", "text: 2 of 3"},
+		{"*", "text: 3 of 3"},
+		{"*", "text: 1 of 3"},
+		{"#", "text: 3 of 3"},
+	} {
+		if status := key(step.key); !strings.Contains(status, step.want) {
+			t.Errorf("%s: status %q, want %q", step.key, status, step.want)
+		}
+	}
+	if !m.viewport.HasSearch() {
+		t.Error("occurrences are not highlighted")
+	}
+	m.Update(tea.KeyMsg{Type: tea.KeyEsc})
+	if m.viewport.HasSearch() {
+		t.Error("Esc should clear the highlights")
+	}
+}
+
+func TestModalsShareDismissalKeys(t *testing.T) {
+	m := newTestModel(t, &fakeFetcher{}, &fakeFetcher{})
+	key := func(k string) tea.Cmd {
+		msg := tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune(k)}
+		switch k {
+		case "esc":
+			msg = tea.KeyMsg{Type: tea.KeyEsc}
+		case "ctrl+h":
+			msg = tea.KeyMsg{Type: tea.KeyCtrlH}
+		}
+		_, cmd := m.Update(msg)
+		return cmd
+	}
+
+	// q closes a modal instead of quitting
+	key("?")
+	if m.modals.Top() != m.helpModal {
+		t.Fatal("? should open the help modal")
+	}
+	key("q")
+	if m.modals.Top() != nil || m.quitting {
+		t.Fatal("q should close the help modal and nothing else")
+	}
+
+	// but is typed into a modal taking text, which Esc closes
+	key("ctrl+h")
+	key("q")
+	if m.modals.Top() != m.historyModal {
+		t.Fatal("q should filter the history rather than close it")
+	}
+	key("esc")
+	if m.modals.Top() != nil {
+		t.Error("Esc should close the history modal")
+	}
+}
+
+func TestBookmarkContentSearch(t *testing.T) {
+	fake := &fakeFetcher{responses: map[string]*types.Response{
+		"gemini://example.org/":        gemtext("# Home\nNothing to see\n"),
+		"gemini://example.org/old":     {Status: 31, Meta: "/recipes"},
+		"gemini://example.org/recipes": gemtext("# Recipes\nA tart made with rhubarb and custard\n"),
+	}}
+	m := newTestModel(t, fake, &fakeFetcher{})
+	m.config.Get().General.IndexBookmarks = true
+	for _, url := range []string{"gemini://example.org/", "gemini://example.org/old"} {
+		if err := m.bookmarks.Add(url, "Untitled", nil); err != nil {
+			t.Fatal(err)
+		}
+	}
+	key := func(k string) {
+		_, cmd := m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune(k)})
+		run(t, m, cmd)
+	}
+
+	// Opening the bookmarks indexes their pages, following redirects
+	key("b")
+	if snippet, ok := m.bookmarkIndex.Snippet("gemini://example.org/old", "RHUBARB"); !ok || !strings.Contains(snippet, "rhubarb and custard") {
+		t.Fatalf("redirected page not indexed: %q, %v", snippet, ok)
+	}
+
+	// and the filter finds them by their text
+	key("/")
+	key("rhubarb")
+	view := m.bookmarksModal.View()
+	if !strings.Contains(view, "1 of 2") || !strings.Contains(view, "rhubarb and custard") {
+		t.Errorf("filter did not match the page text:\n%s", view)
+	}
+}
+
+func TestConfirmBeforeDestructiveActions(t *testing.T) {
+	fake := &fakeFetcher{responses: map[string]*types.Response{
+		"gemini://example.org/away": {Status: 30, Meta: "https://example.com/"},
+	}}
+	m := newTestModel(t, fake, &fakeFetcher{})
+	key := func(k string) {
+		msg := tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune(k)}
+		if k == "esc" {
+			msg = tea.KeyMsg{Type: tea.KeyEsc}
+		}
+		_, cmd := m.Update(msg)
+		run(t, m, cmd)
+	}
+
+	// Deleting a bookmark asks over the bookmarks modal
+	if err := m.bookmarks.Add("gemini://example.org/", "Example", nil); err != nil {
+		t.Fatal(err)
+	}
+	key("b")
+	key("d")
+	if m.modals.Top() != m.confirmModal || !m.modals.IsOpen(m.bookmarksModal) {
+		t.Fatal("deleting a bookmark should ask over the bookmarks modal")
+	}
+	key("esc")
+	if !m.bookmarks.HasBookmark("gemini://example.org/") || m.modals.Top() != m.bookmarksModal {
+		t.Fatal("declining should keep the bookmark and return to the bookmarks modal")
+	}
+	key("d")
+	key("y")
+	if m.bookmarks.HasBookmark("gemini://example.org/") {
+		t.Error("confirming should delete the bookmark")
+	}
+	key("esc")
+
+	// A redirect to another protocol waits for consent
+	run(t, m, m.navigate("gemini://example.org/away"))
+	if m.modals.Top() != m.confirmModal {
+		t.Fatal("a cross-protocol redirect should ask first")
+	}
+	if len(fake.requests) != 1 {
+		t.Errorf("%d requests, want only the redirecting one", len(fake.requests))
+	}
+}
+
+func TestShutdownSavesAfterQuit(t *testing.T) {
+	fake := &fakeFetcher{responses: map[string]*types.Response{
+		"gemini://example.org/": gemtext("# Home\n"),
+	}}
+	m := newTestModel(t, fake, &fakeFetcher{})
+	run(t, m, m.navigate("gemini://example.org/"))
+
+	_, cmd := m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("q")})
+	if cmd == nil || !m.quitting {
+		t.Fatal("q should quit")
+	}
+	if session, _ := m.sessionManager.Load(); session != nil && len(session.Tabs) > 0 {
+		t.Fatal("the session should not be saved while the program may still be running")
+	}
+
+	m.Shutdown()
+	session, err := m.sessionManager.Load()
+	if err != nil || session == nil || len(session.Tabs) == 0 {
+		t.Fatalf("Load() = %v, %v; want the saved session", session, err)
+	}
+	if session.Tabs[0].URL != "gemini://example.org/" {
+		t.Errorf("saved tab URL = %q", session.Tabs[0].URL)
+	}
+}
+
+func TestShutdownWaitsForDownloads(t *testing.T) {
+	fake := &fakeFetcher{responses: map[string]*types.Response{
+		"gemini://example.org/done.bin": {Status: 20, Meta: "application/octet-stream", Body: []byte("done")},
+	}}
+	m := newTestModel(t, fake, &fakeFetcher{})
+
+	// A download that finished as the program quit is not waited for,
+	// though nothing reads its result any more
+	m.downloadLink("gemini://example.org/done.bin")
+
+	// A download whose command has not started yet is still waited for
+	cmd := m.saveFetched("gemini://example.org/late.bin", []byte("late"))
+	go func() {
+		time.Sleep(200 * time.Millisecond)
+		cmd()
+	}()
+
+	// Progress is only saved along the way once a download completes
+	download, err := m.downloads.Add("gemini://example.org/big.iso", "big.iso", 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	m.downloads.UpdateProgress(download.ID, 1234)
+
+	start := time.Now()
+	m.Shutdown()
+	if elapsed := time.Since(start); elapsed >= shutdownTimeout {
+		t.Errorf("shutdown took %s, waiting out the timeout", elapsed)
+	}
+	if data, err := os.ReadFile(filepath.Join(m.config.GetDownloadDirectory(), "late.bin")); err != nil || string(data) != "late" {
+		t.Errorf("download started before shutdown = %q, %v", data, err)
+	}
+	saved := storage.NewDownloads(filepath.Join(os.Getenv("XDG_CONFIG_HOME"), "starsearch", "downloads.json"), 1)
+	if err := saved.Load(); err != nil {
+		t.Fatal(err)
+	}
+	if got := saved.Get(download.ID); got == nil || got.Downloaded != 1234 {
+		t.Errorf("saved download = %+v, want its progress kept", got)
+	}
+}
+
+func TestShutdownCancelsStalledDownloads(t *testing.T) {
+	m := newTestModel(t, &fakeFetcher{}, &fakeFetcher{})
+	m.client = &stallingDownloader{}
+	dir := m.config.GetDownloadDirectory()
+
+	// A download still running at the timeout is cancelled and cleans up
+	m.downloadLink("gemini://example.org/huge.iso")
+	m.Shutdown()
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, entry := range entries {
+		t.Errorf("shutdown left %s behind", entry.Name())
+	}
+}
+
+func TestSuspendAndResume(t *testing.T) {
+	fake := &fakeFetcher{responses: map[string]*types.Response{
+		"gemini://example.org/": gemtext("# Home\n"),
+	}}
+	m := newTestModel(t, fake, &fakeFetcher{})
+	run(t, m, m.navigate("gemini://example.org/"))
+
+	// Ctrl+Z suspends even from a dialog, saving the session first
+	m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("?")})
+	_, cmd := m.Update(tea.KeyMsg{Type: tea.KeyCtrlZ})
+	if cmd == nil {
+		t.Fatal("Ctrl+Z should suspend")
+	}
+	if _, ok := cmd().(tea.SuspendMsg); !ok {
+		t.Error("Ctrl+Z should send SuspendMsg")
+	}
+	if session, err := m.sessionManager.Load(); err != nil || session == nil || len(session.Tabs) == 0 {
+		t.Errorf("the session should be saved before suspending: %v, %v", session, err)
+	}
+
+	// Resuming turns mouse reporting back on and checks the size again
+	profile := lipgloss.ColorProfile()
+	t.Cleanup(func() { lipgloss.SetColorProfile(profile) })
+	_, cmd = m.Update(tea.ResumeMsg{})
+	batch, ok := cmd().(tea.BatchMsg)
+	if !ok {
+		t.Fatal("resuming should restore the terminal")
+	}
+	var restored []string
+	for _, c := range batch {
+		restored = append(restored, fmt.Sprintf("%T", c()))
+	}
+	if got := strings.Join(restored, " "); !strings.Contains(got, "enableMouseAllMotionMsg") || !strings.Contains(got, "windowSizeMsg") {
+		t.Errorf("resume sent %s, want mouse reporting enabled and the size checked", got)
+	}
+}
+
+func TestBackgroundRedrawsAreLimited(t *testing.T) {
+	fake := &fakeFetcher{responses: map[string]*types.Response{
+		"gemini://example.org/": gemtext("# Home\n"),
+	}}
+	m := newTestModel(t, fake, &fakeFetcher{})
+	run(t, m, m.navigate("gemini://example.org/"))
+	before := m.View()
+
+	// A background result right after a redraw waits for the next frame
+	m.activeDownloads++
+	_, cmd := m.Update(downloadCompleteMsg{url: "gemini://example.org/a.zip", err: fmt.Errorf("refused")})
+	if cmd == nil {
+		t.Fatal("a held back redraw should be scheduled")
+	}
+	if m.View() != before {
+		t.Error("the background result should not redraw at once")
+	}
+	if _, cmd := m.Update(downloadCompleteMsg{url: "gemini://example.org/b.zip", err: fmt.Errorf("refused")}); cmd != nil {
+		t.Error("only one redraw should be scheduled at a time")
+	}
+
+	m.Update(frameMsg{})
+	if !strings.Contains(m.View(), "Download failed") {
+		t.Error("the scheduled frame should draw the held back change")
+	}
+
+	// Input always redraws straight away
+	m.Update(downloadCompleteMsg{url: "gemini://example.org/c.zip", err: fmt.Errorf("refused")})
+	m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("?")})
+	if !strings.Contains(m.View(), "Show this help") {
+		t.Error("a key press should redraw at once")
+	}
+}
+
+func TestASCIIGlyphs(t *testing.T) {
+	fake := &fakeFetcher{responses: map[string]*types.Response{
+		"gemini://example.org/": gemtext("# Home\n* listed\n"),
+	}}
+	m := newTestModel(t, fake, &fakeFetcher{})
+	run(t, m, m.navigate("gemini://example.org/"))
+	m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("?")})
+	unicode := m.View()
+
+	m.asciiGlyphs = true
+	plain := ansi.Strip(m.View())
+	for _, r := range plain {
+		if r > 0x7f {
+			t.Fatalf("ASCII mode drew %q", r)
+		}
+	}
+	if lipgloss.Width(plain) != lipgloss.Width(ansi.Strip(unicode)) {
+		t.Error("ASCII glyphs should keep the layout")
+	}
+
+	for _, env := range []struct {
+		vars map[string]string
+		want bool
+	}{
+		{map[string]string{"LANG": "en_US.UTF-8"}, false},
+		{map[string]string{"LANG": "en_US.UTF-8", "LC_ALL": "C"}, true},
+		{map[string]string{"LC_CTYPE": "de_DE.utf8", "LANG": "C"}, false},
+		{map[string]string{"TERM": "vt100", "LANG": "en_US.UTF-8"}, true},
+		{map[string]string{}, false},
+	} {
+		if got := ui.UseASCII("auto", func(name string) string { return env.vars[name] }); got != env.want {
+			t.Errorf("UseASCII(auto, %v) = %v", env.vars, got)
+		}
+	}
+}
+
+func TestHiddenBars(t *testing.T) {
+	fake := &fakeFetcher{responses: map[string]*types.Response{
+		"gemini://example.org/": gemtext("# Home\n=> /a A\n"),
+	}}
+	m := newTestModel(t, fake, &fakeFetcher{})
+	run(t, m, m.navigate("gemini://example.org/"))
+	key := func(k tea.KeyMsg) {
+		_, cmd := m.Update(k)
+		run(t, m, cmd)
+	}
+
+	// Distraction-free: the page fills the screen
+	key(tea.KeyMsg{Type: tea.KeyF11})
+	if top := m.layout().viewport; top != 0 {
+		t.Errorf("viewport top = %d with no bars, want 0", top)
+	}
+	if lines := strings.Count(m.View(), "\n") + 1; lines != 24 {
+		t.Errorf("view is %d lines, want the screen's 24", lines)
+	}
+
+	// A hidden address bar comes back while focused
+	m.Update(tea.KeyMsg{Type: tea.KeyCtrlL})
+	if top := m.layout().viewport; top != 3 {
+		t.Errorf("viewport top = %d while the address bar has focus, want 3", top)
+	}
+	m.Update(tea.KeyMsg{Type: tea.KeyEsc})
+	if top := m.layout().viewport; top != 0 {
+		t.Errorf("viewport top = %d after leaving the address bar, want 0", top)
+	}
+
+	// Bars come back one at a time, and clicks find them where they are
+	key(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("s"), Alt: true})
+	key(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("a"), Alt: true})
+	if top := m.layout().viewport; top != 3 {
+		t.Errorf("viewport top = %d with only the address bar, want 3", top)
+	}
+	m.Update(tea.MouseMsg{X: 5, Y: 1, Action: tea.MouseActionPress, Button: tea.MouseButtonLeft})
+	if !m.addressBar.IsFocused() {
+		t.Error("clicking the address bar at the top should focus it")
+	}
+	if lines := strings.Count(m.View(), "\n") + 1; lines != 24 {
+		t.Errorf("view is %d lines, want the screen's 24", lines)
+	}
+}
+
+func TestLayoutKeepsPageInPlace(t *testing.T) {
+	fake := &fakeFetcher{responses: map[string]*types.Response{
+		"gemini://example.org/": gemtext("# Home\n=> /a A\n"),
+	}}
+	m := newTestModel(t, fake, &fakeFetcher{})
+	run(t, m, m.navigate("gemini://example.org/"))
+	top := m.layout().viewport
+
+	// Address suggestions open over the page rather than pushing it down
+	m.Update(tea.KeyMsg{Type: tea.KeyCtrlL})
+	view := m.View()
+	if !strings.Contains(view, "example.org") {
+		t.Fatal("suggestions should be shown")
+	}
+	if lines := strings.Count(view, "\n") + 1; lines != 24 {
+		t.Errorf("view is %d lines with suggestions open, want 24", lines)
+	}
+	if m.layout().viewport != top {
+		t.Error("suggestions should not move the page")
+	}
+	m.Update(tea.KeyMsg{Type: tea.KeyEsc})
+
+	// The link mode help line moves the page down, and clicks follow it
+	m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("g")})
+	if got := m.layout().viewport; got != top+1 {
+		t.Fatalf("viewport top = %d in link mode, want %d", got, top+1)
+	}
+	if lines := strings.Count(m.View(), "\n") + 1; lines != 24 {
+		t.Errorf("view is %d lines in link mode, want 24", lines)
+	}
+	m.Update(tea.MouseMsg{X: 5, Y: m.layout().addressBar + 1, Action: tea.MouseActionPress, Button: tea.MouseButtonLeft})
+	if !m.addressBar.IsFocused() {
+		t.Error("clicking the address bar below the help line should focus it")
+	}
+}
+
+func TestZoomChangesDensity(t *testing.T) {
+	long := strings.Repeat("word ", 40)
+	fake := &fakeFetcher{responses: map[string]*types.Response{
+		"gemini://example.org/": gemtext("# Home\n\n\n\n" + long + "\n## Section\n"),
+	}}
+	m := newTestModel(t, fake, &fakeFetcher{})
+	run(t, m, m.navigate("gemini://example.org/"))
+	key := func(k string) {
+		_, cmd := m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune(k)})
+		run(t, m, cmd)
+	}
+	rows := func() []string { return strings.Split(ansi.Strip(m.viewport.View()), "\n") }
+	sectionRow := func() int {
+		for i, row := range rows() {
+			if strings.Contains(row, "## Section") {
+				return i
+			}
+		}
+		return -1
+	}
+
+	// Zooming out drops heading margins and blank lines
+	normal := sectionRow()
+	key("-")
+	key("-")
+	if got := sectionRow(); got < 0 || got >= normal {
+		t.Errorf("zoomed out, the second heading is on row %d, want above row %d", got, normal)
+	}
+	if row := rows()[1]; strings.TrimSpace(row) == "" {
+		t.Error("zoomed out, the first heading should not be followed by blank lines")
+	}
+	key("-")
+	if m.viewport.Zoom() != ui.MinZoom {
+		t.Errorf("zoom = %d, want it to stop at %d", m.viewport.Zoom(), ui.MinZoom)
+	}
+
+	// Zooming in wraps text to a narrower column
+	for range 4 {
+		key("+")
+	}
+	if m.viewport.Zoom() != ui.MaxZoom {
+		t.Fatalf("zoom = %d, want %d", m.viewport.Zoom(), ui.MaxZoom)
+	}
+	for _, row := range rows() {
+		if w := ansi.StringWidth(strings.TrimRight(row, " ")); w > 72 {
+			t.Fatalf("row %q is %d columns, want at most 72", row, w)
+		}
+	}
+	if got := sectionRow(); got <= normal {
+		t.Errorf("zoomed in, the second heading is on row %d, want below row %d", got, normal)
+	}
+
+	// The level is kept for the next launch
+	if err := m.config.Load(); err != nil {
+		t.Fatal(err)
+	}
+	if m.config.Get().UI.Zoom != ui.MaxZoom {
+		t.Errorf("saved zoom = %d, want %d", m.config.Get().UI.Zoom, ui.MaxZoom)
+	}
+}
+
+func TestTypographySettings(t *testing.T) {
+	fake := &fakeFetcher{responses: map[string]*types.Response{
+		"gemini://example.org/":  gemtext("# Title\n\n\n\nBody text\n=> /a Link\n"),
+		"gemini://example.org/a": gemtext("# A\n"),
+	}}
+	m := newTestModel(t, fake, &fakeFetcher{})
+	run(t, m, m.navigate("gemini://example.org/"))
+
+	settings := m.config.Get().UI
+	settings.H1Margin = []int{0, 0}
+	settings.MaxBlankLines = 1
+	settings.IndentUnderHeadings = 2
+	m.viewport.SetTypography(typography(settings))
+
+	rows := strings.Split(ansi.Strip(m.viewport.View()), "\n")
+	want := []string{"# Title", "", "  Body text", "  [1] Link"}
+	for i, w := range want {
+		if got := strings.TrimRight(rows[i], " "); got != w {
+			t.Errorf("row %d = %q, want %q", i, got, w)
+		}
+	}
+
+	// Links are clicked where they are drawn, indent included
+	top := m.layout().viewport
+	m.Update(tea.MouseMsg{X: 7, Y: top + 3, Action: tea.MouseActionPress, Button: tea.MouseButtonLeft})
+	_, cmd := m.Update(tea.MouseMsg{X: 7, Y: top + 3, Action: tea.MouseActionRelease, Button: tea.MouseButtonLeft})
+	run(t, m, cmd)
+	if m.currentURL != "gemini://example.org/a" {
+		t.Errorf("clicking the indented link went to %q", m.currentURL)
+	}
+}
+
+func TestRightToLeftText(t *testing.T) {
+	fake := &fakeFetcher{responses: map[string]*types.Response{
+		"gemini://example.org/": gemtext("שלום עולם 42\nSay שלום עולם today\n"),
+	}}
+	m := newTestModel(t, fake, &fakeFetcher{})
+	run(t, m, m.navigate("gemini://example.org/"))
+
+	rows := strings.Split(ansi.Strip(m.viewport.View()), "\n")
+	rtl := strings.TrimRight(rows[0], " ")
+	if got := strings.TrimLeft(rtl, " "); got != "42 םלוע םולש" {
+		t.Errorf("RTL line = %q, want it reordered", got)
+	}
+	if lipgloss.Width(rtl) != m.width {
+		t.Errorf("RTL line is %d columns wide, want it right-aligned to %d", lipgloss.Width(rtl), m.width)
+	}
+	if got := strings.TrimRight(rows[1], " "); got != "Say םלוע םולש today" {
+		t.Errorf("mixed line = %q, want the Hebrew phrase reversed in place", got)
+	}
+
+	// Terminals that shape bidi text themselves get it in logical order
+	m.viewport.SetBidi(false)
+	rows = strings.Split(ansi.Strip(m.viewport.View()), "\n")
+	if got := strings.TrimRight(rows[0], " "); got != "שלום עולם 42" {
+		t.Errorf("unshaped line = %q", got)
+	}
+}
+
+func TestInputSpelling(t *testing.T) {
+	fake := &fakeFetcher{responses: map[string]*types.Response{
+		"gemini://example.org/sign": {Status: 10, Meta: "Sign the guestbook"},
+	}}
+	m := newTestModel(t, fake, &fakeFetcher{})
+	run(t, m, m.navigate("gemini://example.org/sign"))
+	m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("Teh best place I recieve mail")})
+
+	if strings.Contains(ansi.Strip(m.View()), "Did you mean") {
+		t.Fatal("spelling is highlighted before it is turned on")
+	}
+	m.Update(tea.KeyMsg{Type: tea.KeyCtrlS})
+	view := ansi.Strip(m.View())
+	if !strings.Contains(view, "Did you mean: Teh → The, recieve → receive") {
+		t.Errorf("misspellings are not flagged:\n%s", view)
+	}
+}
+
+func TestInputInEditor(t *testing.T) {
+	fake := &fakeFetcher{responses: map[string]*types.Response{
+		"gemini://example.org/sign":                        {Status: 10, Meta: "Sign the guestbook"},
+		"gemini://example.org/sign?Hello%0Afrom+an+editor": gemtext("# Thanks\n"),
+	}}
+	m := newTestModel(t, fake, &fakeFetcher{})
+	run(t, m, m.navigate("gemini://example.org/sign"))
+	m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("Hello")})
+
+	_, cmd := m.Update(tea.KeyMsg{Type: tea.KeyCtrlE})
+	if cmd == nil {
+		t.Fatal("Ctrl+E should ask for the editor")
+	}
+	if msg, ok := cmd().(ui.InputEditMsg); !ok || msg.Text != "Hello" {
+		t.Fatalf("Ctrl+E sent %#v, want the answer so far", msg)
+	}
+
+	// The editor's file, less its final newline, is sent
+	path := filepath.Join(t.TempDir(), "answer.txt")
+	if err := os.WriteFile(path, []byte("Hello\nfrom an editor\n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	_, cmd = m.Update(editorClosedMsg{path: path})
+	run(t, m, cmd)
+	if m.currentURL != "gemini://example.org/sign?Hello%0Afrom+an+editor" {
+		t.Errorf("current URL = %q, want the edited answer sent", m.currentURL)
+	}
+	if _, err := os.Stat(path); !os.IsNotExist(err) {
+		t.Error("the answer's file should be removed once sent")
+	}
+
+	// Answers too long to send are kept in their file
+	run(t, m, m.navigate("gemini://example.org/sign"))
+	if err := os.WriteFile(path, []byte(strings.Repeat("x", maxRequestLength)), 0600); err != nil {
+		t.Fatal(err)
+	}
+	_, cmd = m.Update(editorClosedMsg{path: path})
+	if cmd != nil || !m.inputModal.IsVisible() {
+		t.Error("an answer too long to send should leave the prompt open")
+	}
+	if _, err := os.Stat(path); err != nil {
+		t.Errorf("an answer too long to send should be kept: %v", err)
+	}
+}
+
+func TestGempubBook(t *testing.T) {
+	var book bytes.Buffer
+	w := zip.NewWriter(&book)
+	for name, body := range map[string]string{
+		"metadata.txt":   "title: A Book\nauthor: Someone\n",
+		"index.gmi":      "# Contents\n=> chapters/1.gmi Chapter One\n",
+		"chapters/1.gmi": "# Chapter One\nIt begins.\n",
+	} {
+		f, _ := w.Create(name)
+		f.Write([]byte(body))
+	}
+	w.Close()
+
+	fake := &fakeFetcher{responses: map[string]*types.Response{
+		"gemini://example.org/book.gpub": {Status: 20, Meta: "application/gpub+zip", Body: book.Bytes()},
+	}}
+	m := newTestModel(t, fake, &fakeFetcher{})
+	t.Cleanup(m.closeBooks)
+
+	follow := func(text string) {
+		t.Helper()
+		for _, link := range m.currentDoc.Links {
+			if strings.HasPrefix(link.Text, text) {
+				run(t, m, m.navigate(link.URL))
+				return
+			}
+		}
+		t.Fatalf("no %q link on %s", text, m.currentURL)
+	}
+
+	run(t, m, m.navigate("gemini://example.org/book.gpub"))
+	if !strings.HasPrefix(m.currentURL, "gpub://") || !strings.Contains(ansi.Strip(m.viewport.View()), "By Someone") {
+		t.Fatalf("book opened at %q:\n%s", m.currentURL, ansi.Strip(m.viewport.View()))
+	}
+	follow("Contents")
+	follow("Chapter One")
+	if !strings.Contains(ansi.Strip(m.viewport.View()), "It begins.") {
+		t.Fatalf("chapter not shown:\n%s", ansi.Strip(m.viewport.View()))
+	}
+
+	// Opening the book again offers to continue where the reader left off
+	run(t, m, m.navigate("gemini://example.org/book.gpub"))
+	follow("Continue reading")
+	if !strings.HasSuffix(m.currentURL, "/chapters/1.gmi") {
+		t.Errorf("continued at %q", m.currentURL)
+	}
+}
+
+func TestArchiveListing(t *testing.T) {
+	var archive bytes.Buffer
+	w := zip.NewWriter(&archive)
+	f, _ := w.Create("dist/notes.txt")
+	f.Write([]byte("read me"))
+	w.Close()
+
+	fake := &fakeFetcher{responses: map[string]*types.Response{
+		"gemini://example.org/files.zip": {Status: 20, Meta: "application/zip", Body: archive.Bytes()},
+	}}
+	m := newTestModel(t, fake, &fakeFetcher{})
+	run(t, m, m.downloadLink("gemini://example.org/files.zip"))
+
+	m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("Z")})
+	if !strings.Contains(ansi.Strip(m.View()), "dist/notes.txt") {
+		t.Fatalf("archive entries are not listed:\n%s", ansi.Strip(m.View()))
+	}
+	_, cmd := m.Update(tea.KeyMsg{Type: tea.KeyEnter})
+	run(t, m, cmd)
+
+	data, err := os.ReadFile(filepath.Join(m.config.GetDownloadDirectory(), "notes.txt"))
+	if err != nil || string(data) != "read me" {
+		t.Errorf("extracted file = %q, %v", data, err)
+	}
+}
+
+func TestArchiveEntryOutsideDownloads(t *testing.T) {
+	m := newTestModel(t, &fakeFetcher{}, &fakeFetcher{})
+	dir := m.config.GetDownloadDirectory()
+	var archive bytes.Buffer
+	w := zip.NewWriter(&archive)
+	for _, name := range []string{"..", "../", "/", "."} {
+		f, _ := w.Create(name)
+		f.Write([]byte("escaped"))
+	}
+	w.Close()
+	path := filepath.Join(t.TempDir(), "evil.zip")
+	if err := os.WriteFile(path, archive.Bytes(), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		t.Fatal(err)
+	}
+	parent := filepath.Dir(dir)
+	before, _ := os.ReadDir(parent)
+	for _, name := range []string{"..", "../", "/", "."} {
+		run(t, m, m.extractEntry(ui.ArchiveExtractMsg{Path: path, Entry: name}))
+		if bar := ansi.Strip(m.statusBar.View()); !strings.Contains(bar, "cannot extract") {
+			t.Errorf("extracting %q reported %q", name, bar)
+		}
+	}
+	if after, _ := os.ReadDir(parent); len(after) != len(before) {
+		t.Errorf("extraction wrote beside the download directory: %v", after)
+	}
+}
+
+// stallingDownloader writes the start of a download, then waits until it
+// is cancelled
+type stallingDownloader struct {
+	fakeFetcher
+}
+
+func (f *stallingDownloader) FetchTo(ctx context.Context, urlStr string, w io.Writer, progress func(written int64)) (*types.Response, error) {
+	w.Write([]byte("part"))
+	progress(4)
+	<-ctx.Done()
+	return nil, fmt.Errorf("failed to read response body: %w", ctx.Err())
+}
+
+func TestDownloads(t *testing.T) {
+	fake := &fakeFetcher{responses: map[string]*types.Response{
+		"gemini://example.org/data.bin":  {Status: 20, Meta: "application/octet-stream", Body: []byte("binary")},
+		"gemini://example.org/paper.pdf": {Status: 20, Meta: "application/pdf", Body: []byte("%PDF")},
+	}}
+	m := newTestModel(t, fake, &fakeFetcher{})
+	dir := m.config.GetDownloadDirectory()
+
+	// Links are downloaded without being shown, and listed with Shift+D
+	run(t, m, m.downloadLink("gemini://example.org/data.bin"))
+	if data, err := os.ReadFile(filepath.Join(dir, "data.bin")); err != nil || string(data) != "binary" {
+		t.Fatalf("downloaded file = %q, %v", data, err)
+	}
+
+	// Pages that are not text are saved rather than shown
+	run(t, m, m.navigate("gemini://example.org/paper.pdf"))
+	if data, err := os.ReadFile(filepath.Join(dir, "paper.pdf")); err != nil || string(data) != "%PDF" {
+		t.Fatalf("saved page = %q, %v", data, err)
+	}
+
+	m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("D")})
+	view := ansi.Strip(m.View())
+	if !strings.Contains(view, "data.bin") || !strings.Contains(view, "paper.pdf") || !strings.Contains(view, "Completed") {
+		t.Fatalf("downloads are not listed:\n%s", view)
+	}
+	m.Update(tea.KeyMsg{Type: tea.KeyEsc})
+
+	// A cancelled download leaves no file behind
+	m.client = &stallingDownloader{}
+	cmd := m.downloadLink("gemini://example.org/huge.iso")
+	active := m.downloads.GetActive()
+	if len(active) != 1 || m.activeDownloads != 1 {
+		t.Fatalf("active downloads = %v", active)
+	}
+	m.Update(ui.DownloadCancelMsg{ID: active[0].ID})
+	run(t, m, cmd)
+	if got := m.downloads.Get(active[0].ID); got == nil || got.Status != types.DownloadCancelled {
+		t.Errorf("cancelled download = %+v", got)
+	}
+	entries, _ := os.ReadDir(dir)
+	if len(entries) != 2 || m.activeDownloads != 0 {
+		t.Errorf("download directory holds %d files after cancelling, want 2", len(entries))
+	}
+}
+
+func TestAudioQueue(t *testing.T) {
+	fake := &fakeFetcher{responses: map[string]*types.Response{
+		"gemini://example.org/":         gemtext("# Radio\n"),
+		"gemini://example.org/one.ogg":  {Status: 20, Meta: "audio/ogg", Body: []byte("one")},
+		"gemini://example.org/two.ogg":  {Status: 20, Meta: "audio/ogg", Body: []byte("two")},
+		"gemini://example.org/last.ogg": {Status: 20, Meta: "audio/ogg", Body: []byte("last")},
+	}}
+	m := newTestModel(t, fake, &fakeFetcher{})
+	m.config.Get().General.AudioPlayer = "sleep 10"
+	t.Cleanup(m.stopAudio)
+	run(t, m, m.navigate("gemini://example.org/"))
+
+	// The player keeps running, so its exit is not waited for here
+	m.Update(m.navigate("gemini://example.org/one.ogg")())
+	m.Update(m.navigate("gemini://example.org/two.ogg")())
+	if m.currentURL != "gemini://example.org/" {
+		t.Errorf("playing sound left the page for %q", m.currentURL)
+	}
+	if bar := ansi.Strip(m.statusBar.View()); !strings.Contains(bar, "one.ogg (+1)") {
+		t.Errorf("status bar does not show what is playing: %q", bar)
+	}
+
+	// A finished track moves on to the next
+	m.Update(audioFinishedMsg{id: m.audio.id})
+	if m.audio.playing == nil || m.audio.playing.url != "gemini://example.org/two.ogg" {
+		t.Fatalf("after the first track, playing %+v", m.audio.playing)
+	}
+
+	m.Update(m.navigate("gemini://example.org/last.ogg")())
+	m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("s")})
+	if m.audio.playing != nil || len(m.audio.waiting) != 0 {
+		t.Errorf("stop left %+v playing and %d waiting", m.audio.playing, len(m.audio.waiting))
+	}
+	if bar := ansi.Strip(m.statusBar.View()); strings.Contains(bar, "♪") {
+		t.Errorf("status bar still shows playback: %q", bar)
+	}
+}
+
+func TestPlayerCommand(t *testing.T) {
+	track := audioTrack{url: "gemini://example.org/song.ogg", body: []byte("sound")}
+
+	// Without {file} the sound is piped to the player
+	cmd, file, err := playerCommand("mpv --no-terminal -", track)
+	if err != nil || file != "" || strings.Join(cmd.Args, " ") != "mpv --no-terminal -" {
+		t.Fatalf("piped player = %v, %q, %v", cmd, file, err)
+	}
+	if piped, _ := io.ReadAll(cmd.Stdin); string(piped) != "sound" {
+		t.Errorf("player is given %q on stdin", piped)
+	}
+
+	// {file} is replaced by a temporary file holding the sound
+	cmd, file, err = playerCommand("mpv --no-terminal {file}", track)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { os.Remove(file) })
+	if data, _ := os.ReadFile(file); string(data) != "sound" || filepath.Ext(file) != ".ogg" {
+		t.Errorf("sound file %s holds %q", file, data)
+	}
+	if strings.Join(cmd.Args, " ") != "mpv --no-terminal "+file || cmd.Stdin != nil {
+		t.Errorf("file player = %v", cmd.Args)
+	}
+
+	// A blank player is an error, not a crash
+	if _, _, err := playerCommand("  ", track); err == nil {
+		t.Error("blank player accepted")
+	}
+}
+
+func TestDataURLs(t *testing.T) {
+	var picture bytes.Buffer
+	if err := png.Encode(&picture, image.NewRGBA(image.Rect(0, 0, 4, 4))); err != nil {
+		t.Fatal(err)
+	}
+	page := "=> data:text/plain;base64,SGVsbG8gZnJvbSBpbnNpZGUgdGhlIGxpbms Text\n" +
+		"=> data:image/png;base64," + base64.StdEncoding.EncodeToString(picture.Bytes()) + " Image\n" +
+		"=> data:application/pdf;base64,JVBERg== PDF\n"
+	fake := &fakeFetcher{responses: map[string]*types.Response{
+		"gemini://example.org/": gemtext(page),
+	}}
+	m := newTestModel(t, fake, &fakeFetcher{})
+	run(t, m, m.navigate("gemini://example.org/"))
+	links := m.currentDoc.Links
+
+	run(t, m, m.navigate(links[0].URL))
+	if !strings.Contains(ansi.Strip(m.viewport.View()), "Hello from inside the link") {
+		t.Errorf("text data: link not shown:\n%s", ansi.Strip(m.viewport.View()))
+	}
+
+	run(t, m, m.navigate(links[1].URL))
+	if m.currentDoc.MIMEType != "image/png" {
+		t.Errorf("image data: link shown as %q", m.currentDoc.MIMEType)
+	}
+
+	run(t, m, m.navigate(links[2].URL))
+	if !strings.Contains(ansi.Strip(m.statusBar.View()), "cannot show data: links of type application/pdf") {
+		t.Errorf("unsupported data: link: %q", ansi.Strip(m.statusBar.View()))
+	}
+	if len(fake.requests) != 1 {
+		t.Errorf("data: links were fetched: %v", fake.requests)
+	}
+}
+
+func TestToastOutlivesStatusUpdates(t *testing.T) {
+	fake := &fakeFetcher{responses: map[string]*types.Response{
+		"gemini://example.org/": gemtext("# Home\n"),
+	}}
+	m := newTestModel(t, fake, &fakeFetcher{})
+	run(t, m, m.navigate("gemini://example.org/"))
+
+	_, expire := m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("d")})
+	m.statusBar.SetMessage("Scrolled")
+	if !strings.Contains(m.View(), "Bookmark added") {
+		t.Fatal("the toast should be drawn over the page")
+	}
+
+	// A newer toast is not hidden by the older one expiring
+	_, newer := m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("d")})
+	run(t, m, expire)
+	if m.toast.Text() != "Bookmark removed" {
+		t.Errorf("toast = %q, want the newer one", m.toast.Text())
+	}
+	run(t, m, newer)
+	if m.toast.IsVisible() {
+		t.Error("the toast should hide once its time is up")
+	}
+}
+
+func TestNavigateStopsRedirectLoop(t *testing.T) {
+	fake := &fakeFetcher{responses: map[string]*types.Response{
+		"gemini://example.org/a": {Status: 30, Meta: "gemini://example.org/b"},
+		"gemini://example.org/b": {Status: 30, Meta: "/a"},
+	}}
+	m := newTestModel(t, fake, &fakeFetcher{})
+
+	run(t, m, m.navigate("gemini://example.org/a"))
+
+	if m.currentDoc != nil {
+		t.Errorf("a page was shown for a redirect loop")
+	}
+	if len(fake.requests) != 2 {
+		t.Errorf("made %d requests, want the loop caught after 2", len(fake.requests))
+	}
+	if bar := ansi.Strip(m.statusBar.View()); !strings.Contains(bar, "Redirect loop") {
+		t.Errorf("status bar = %q, want the loop reported", bar)
+	}
+}
+
+func TestNavigateStopsLongRedirectChain(t *testing.T) {
+	responses := map[string]*types.Response{}
+	for i := 0; i < 10; i++ {
+		responses[fmt.Sprintf("gemini://example.org/%d", i)] = &types.Response{Status: 30, Meta: fmt.Sprintf("/%d", i+1)}
+	}
+	fake := &fakeFetcher{responses: responses}
+	m := newTestModel(t, fake, &fakeFetcher{})
+
+	run(t, m, m.navigate("gemini://example.org/0"))
+
+	if m.redirectLimit != 5 {
+		t.Errorf("redirect limit = %d, want 5", m.redirectLimit)
+	}
+	if len(fake.requests) != m.redirectLimit+1 {
+		t.Errorf("made %d requests, want %d", len(fake.requests), m.redirectLimit+1)
+	}
+}
+
+func TestRedirectToAnotherHostAsks(t *testing.T) {
+	fake := &fakeFetcher{responses: map[string]*types.Response{
+		"gemini://example.org/moved": {Status: 31, Meta: "gemini://example.net/"},
+		"gemini://example.org/same":  {Status: 31, Meta: "gemini://EXAMPLE.org:1965/"},
+		"gemini://EXAMPLE.org:1965/": gemtext("# Here\n"),
+		"gemini://example.net/":      gemtext("# There\n"),
+	}}
+	m := newTestModel(t, fake, &fakeFetcher{})
+
+	// The same host, however written, is followed without asking
+	run(t, m, m.navigate("gemini://example.org/same"))
+	if m.modals.Top() == m.confirmModal || m.currentDoc == nil {
+		t.Fatalf("a redirect within the host asked first")
+	}
+
+	run(t, m, m.navigate("gemini://example.org/moved"))
+	if m.modals.Top() != m.confirmModal {
+		t.Fatal("a redirect to another host was followed without asking")
+	}
+	if view := m.confirmModal.View(); !strings.Contains(view, "another host") {
+		t.Errorf("confirmation does not say where the redirect leads:\n%s", view)
+	}
+	_, cmd := m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("y")})
+	run(t, m, cmd)
+	if m.currentURL != "gemini://example.net/" {
+		t.Errorf("after confirming, at %q", m.currentURL)
+	}
+}
+
+func TestNavigateGopher(t *testing.T) {
+	gopherFake := &fakeFetcher{responses: map[string]*types.Response{
+		"gopher://example.org/": {Status: 20, Meta: gopher.GetMIMEType("1"), Body: []byte("iWelcome to gopherspace\t\texample.org\t70\r\n1Docs\t/docs\texample.org\t70\r\n.\r\n")},
+	}}
+	m := newTestModel(t, &fakeFetcher{}, gopherFake)
+
+	run(t, m, m.navigate("gopher://example.org/"))
+
+	if m.currentDoc == nil {
+		t.Fatal("gopher menu not loaded")
+	}
+	if view := m.View(); !strings.Contains(view, "Welcome to gopherspace") {
+		t.Errorf("menu text missing from view:\n%s", view)
+	}
+}
+
+func TestHistoryTitles(t *testing.T) {
+	gopherFake := &fakeFetcher{responses: map[string]*types.Response{
+		"gopher://example.org/1/docs/notes/": {Status: 20, Meta: gopher.GetMIMEType("1"), Body: []byte("iNothing here yet\t\texample.org\t70\r\n.\r\n")},
+	}}
+	m := newTestModel(t, &fakeFetcher{}, gopherFake)
+
+	// Gopher pages are named after their selector
+	run(t, m, m.navigate("gopher://example.org/1/docs/notes/"))
+	if current := m.history.Current(); current == nil || current.Title != "notes" {
+		t.Fatalf("history entry = %+v, want the title \"notes\"", current)
+	}
+
+	// and can be renamed from the history modal
+	key := func(msg tea.KeyMsg) {
+		_, cmd := m.Update(msg)
+		run(t, m, cmd)
+	}
+	key(tea.KeyMsg{Type: tea.KeyCtrlH})
+	key(tea.KeyMsg{Type: tea.KeyCtrlE})
+	key(tea.KeyMsg{Type: tea.KeyCtrlU})
+	key(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("Meeting notes")})
+	key(tea.KeyMsg{Type: tea.KeyEnter})
+	if title := m.history.Current().Title; title != "Meeting notes" {
+		t.Errorf("renamed title = %q", title)
+	}
+	if m.modals.Top() != m.historyModal {
+		t.Error("renaming should leave the history modal open")
+	}
+}
+
+func TestReplaySession(t *testing.T) {
+	fake := &fakeFetcher{responses: map[string]*types.Response{
+		"gemini://example.org/": gemtext("# Recorded\nThis page came from a cassette\n"),
+	}}
+	path := filepath.Join(t.TempDir(), "session.json")
+
+	recording := newTestModel(t, fake, &fakeFetcher{})
+	recording.RecordSession(path)
+	run(t, recording, recording.navigate("gemini://example.org/"))
+
+	m := newTestModel(t, &fakeFetcher{}, &fakeFetcher{})
+	if err := m.ReplaySession(path); err != nil {
+		t.Fatalf("ReplaySession: %v", err)
+	}
+	run(t, m, m.navigate("gemini://example.org/"))
+
+	if view := m.View(); !strings.Contains(view, "This page came from a cassette") {
+		t.Errorf("replayed page missing from view:\n%s", view)
+	}
+}
+
+func TestGopherBinaryItemIsSaved(t *testing.T) {
+	gopherFake := &fakeFetcher{responses: map[string]*types.Response{
+		"gopher://example.org:70/9/files/tool.zip": {Status: 20, Meta: gopher.GetMIMEType("9"), Body: []byte("PK\x03\x04")},
+	}}
+	m := newTestModel(t, &fakeFetcher{}, gopherFake)
+
+	run(t, m, m.navigate("gopher://example.org:70/9/files/tool.zip"))
+
+	if m.currentDoc != nil {
+		t.Error("binary item was shown as a page")
+	}
+	data, err := os.ReadFile(filepath.Join(m.config.GetDownloadDirectory(), "tool.zip"))
+	if err != nil || string(data) != "PK\x03\x04" {
+		t.Errorf("download not saved: %q, %v", data, err)
+	}
+}
+
+func TestWatchReload(t *testing.T) {
+	var page strings.Builder
+	for i := 1; i <= 60; i++ {
+		fmt.Fprintf(&page, "Line %d\n", i)
+	}
+	fake := &fakeFetcher{responses: map[string]*types.Response{
+		"gemini://example.org/board": gemtext(page.String()),
+	}}
+	m := newTestModel(t, fake, &fakeFetcher{})
+	run(t, m, m.navigate("gemini://example.org/board"))
+
+	if cmd := m.cycleWatch(); cmd == nil {
+		t.Fatal("watching the first tab should start the ticker")
+	}
+	if got := m.tabBar.GetActiveTab().WatchInterval; got != watchIntervals[0] {
+		t.Fatalf("watch interval %d, want %d", got, watchIntervals[0])
+	}
+	reload := func() {
+		run(t, m, m.fetchIntoTab(m.tabBar.GetActiveTab().ID, "gemini://example.org/board", nil, scheduler.Background, fetchWatch))
+	}
+
+	// Unchanged content keeps the scroll position
+	m.viewport.SetScrollOffset(7)
+	reload()
+	if got := m.viewport.GetScrollOffset(); got != 7 {
+		t.Errorf("scroll offset %d after an unchanged reload, want 7", got)
+	}
+
+	// Changed content jumps to the first change
+	fake.responses["gemini://example.org/board"] = gemtext(strings.Replace(page.String(), "Line 40\n", "Line 40 (edited)\n", 1))
+	reload()
+	if !strings.Contains(string(m.currentDoc.RawBody), "(edited)") {
+		t.Fatal("the changed page was not shown")
+	}
+	if got := m.viewport.GetScrollOffset(); got != 39 {
+		t.Errorf("scroll offset %d after a change on line 40, want 39", got)
+	}
+}
+
+func TestWatchHonorsRobots(t *testing.T) {
+	fake := &fakeFetcher{responses: map[string]*types.Response{
+		"gemini://example.org/board":      gemtext("# Board\n"),
+		"gemini://example.org/robots.txt": {Status: 20, Meta: "text/plain", Body: []byte("User-agent: *\nDisallow: /board\n")},
+	}}
+	m := newTestModel(t, fake, &fakeFetcher{})
+	run(t, m, m.navigate("gemini://example.org/board"))
+	m.cycleWatch()
+
+	run(t, m, m.fetchIntoTab(m.tabBar.GetActiveTab().ID, "gemini://example.org/board", nil, scheduler.Background, fetchWatch))
+	if got := m.tabBar.GetActiveTab().WatchInterval; got != 0 {
+		t.Errorf("still watching every %ds against robots.txt", got)
+	}
+	want := []string{"gemini://example.org/board", "gemini://example.org/robots.txt"}
+	if fmt.Sprint(fake.requests) != fmt.Sprint(want) {
+		t.Errorf("requests = %v, want %v", fake.requests, want)
+	}
+
+	// Pages the user asks for are not bound by robots.txt
+	run(t, m, m.navigate("gemini://example.org/board"))
+	if len(fake.requests) != 3 {
+		t.Errorf("reloading by hand made requests %v", fake.requests)
+	}
+}
+
+func TestReloadRevalidatesUnchangedPage(t *testing.T) {
+	var page strings.Builder
+	for i := 1; i <= 60; i++ {
+		fmt.Fprintf(&page, "Line %d\n", i)
+	}
+	fake := &fakeFetcher{responses: map[string]*types.Response{
+		"gemini://example.org/log": gemtext(page.String()),
+	}}
+	m := newTestModel(t, fake, &fakeFetcher{})
+	run(t, m, m.navigate("gemini://example.org/log"))
+	reload := func() {
+		_, cmd := m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("r")})
+		run(t, m, cmd)
+	}
+
+	// The same body keeps the scroll position
+	m.viewport.SetScrollOffset(12)
+	reload()
+	if len(fake.requests) != 2 {
+		t.Fatalf("%d requests, want the reload to fetch again", len(fake.requests))
+	}
+	if got := m.viewport.GetScrollOffset(); got != 12 {
+		t.Errorf("scroll offset %d after an unchanged reload, want 12", got)
+	}
+	if view := m.statusBar.View(); !strings.Contains(view, "Unchanged (revalidated)") {
+		t.Errorf("status bar %q does not report the page unchanged", view)
+	}
+
+	// A changed body is shown from the top
+	fake.responses["gemini://example.org/log"] = gemtext(page.String() + "Line 61\n")
+	reload()
+	if got := m.viewport.GetScrollOffset(); got != 0 {
+		t.Errorf("scroll offset %d after a changed reload, want 0", got)
+	}
+	if view := m.statusBar.View(); strings.Contains(view, "Unchanged") {
+		t.Errorf("status bar %q reports a changed page unchanged", view)
+	}
+}
+
+func TestNavigateStreamsSlowPage(t *testing.T) {
+	m := newTestModel(t, &fakeFetcher{}, &fakeFetcher{})
+	m.client = &streamingFetcher{
+		chunks: []string{"# Live\n", "First update\n", "Second update\n"},
+		pause:  streamInterval + 20*time.Millisecond,
+	}
+
+	run(t, m, m.navigate("gemini://example.org/live"))
+
+	if m.streamedFetch != m.fetchID {
+		t.Error("the page was not shown while it was loading")
+	}
+	if m.currentDoc == nil || !strings.Contains(string(m.currentDoc.RawBody), "Second update") {
+		t.Fatalf("complete page not shown: %+v", m.currentDoc)
+	}
+}
+
+func TestCertificateRequiredPromptsForIdentity(t *testing.T) {
+	fake := &fakeFetcher{responses: map[string]*types.Response{
+		"gemini://garden.example/app": {Status: 60, Meta: "Log in to water your plant"},
+	}}
+	m := newTestModel(t, fake, &fakeFetcher{})
+	if _, err := m.identities.Generate(gemini.IdentityOptions{Name: "gardener", KeyType: gemini.KeyEd25519, Validity: time.Hour}); err != nil {
+		t.Fatal(err)
+	}
+
+	run(t, m, m.navigate("gemini://garden.example/app"))
+	if m.modals.Top() != m.identitiesModal {
+		t.Fatal("a 60 response without a scoped identity should ask for one")
+	}
+
+	run(t, m, func() tea.Msg {
+		return ui.IdentityChosenMsg{Name: "gardener", URL: "gemini://garden.example/app"}
+	})
+	matches := m.identities.Match("gemini://garden.example/other")
+	if len(matches) != 1 || matches[0].Name != "gardener" {
+		t.Fatalf("the chosen identity was not scoped to the capsule: %v", matches)
+	}
+	if got := len(fake.requests); got != 2 {
+		t.Errorf("%d requests, want the original and one retry", got)
+	}
+}
+
+func TestCacheBrowser(t *testing.T) {
+	fake := &fakeFetcher{responses: map[string]*types.Response{
+		"gemini://example.org/":    gemtext("# Home\n"),
+		"gemini://example.org/old": {Status: 31, Meta: "gemini://example.org/"},
+	}}
+	m := newTestModel(t, fake, &fakeFetcher{})
+	m.pageCache = cache.NewCache(1, 300)
+
+	run(t, m, m.navigate("gemini://example.org/old"))
+	if got := m.pageCache.GetEntryCount(); got != 2 {
+		t.Fatalf("%d cache entries, want the redirect and the page", got)
+	}
+
+	// The cached redirect is followed without a request
+	run(t, m, m.navigate("gemini://example.org/old"))
+	if got := len(fake.requests); got != 2 {
+		t.Errorf("%d requests, want 2: the second visit should be served from cache", got)
+	}
+
+	m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("C")})
+	if m.modals.Top() != m.cacheModal {
+		t.Fatal("C should open the cache browser")
+	}
+	run(t, m, func() tea.Msg { return ui.CacheInvalidateMsg{URL: "gemini://example.org/old"} })
+	if _, ok := m.pageCache.Get("gemini://example.org/old"); ok {
+		t.Error("the invalidated redirect is still cached")
+	}
+	run(t, m, func() tea.Msg { return ui.CacheClearMsg{} })
+	if got := m.pageCache.GetEntryCount(); got != 0 {
+		t.Errorf("%d entries after clearing the cache", got)
+	}
+}
+
+func TestCapsuleTokensNeedConsent(t *testing.T) {
+	fake := &fakeFetcher{responses: map[string]*types.Response{
+		"gemini://example.org/login":           {Status: 30, Meta: "/app?session=abc"},
+		"gemini://example.org/app?session=abc": gemtext("# Signed in\n"),
+		"gemini://example.org/app":             gemtext("# Signed out\n"),
+		"gemini://other.example/":              gemtext("# Other\n"),
+	}}
+	m := newTestModel(t, fake, &fakeFetcher{})
+	lastRequest := func() string {
+		return fake.requests[len(fake.requests)-1]
+	}
+
+	// Without consent nothing is remembered
+	run(t, m, m.navigate("gemini://example.org/login"))
+	run(t, m, m.navigate("gemini://example.org/app"))
+	if got := lastRequest(); got != "gemini://example.org/app" {
+		t.Fatalf("requested %s before the capsule was allowed a token", got)
+	}
+
+	m.Update(ui.TokenConsentMsg{Host: "example.org", Allow: true})
+	run(t, m, m.navigate("gemini://example.org/login"))
+	run(t, m, m.navigate("gemini://example.org/app"))
+	if got := lastRequest(); got != "gemini://example.org/app?session=abc" {
+		t.Errorf("requested %s, want the token re-sent", got)
+	}
+	run(t, m, m.navigate("gemini://other.example/"))
+	if got := lastRequest(); got != "gemini://other.example/" {
+		t.Errorf("token leaked to another host: %s", got)
+	}
+
+	m.Update(ui.TokenRevokeMsg{Host: "example.org"})
+	run(t, m, m.navigate("gemini://example.org/app"))
+	if got := lastRequest(); got != "gemini://example.org/app" {
+		t.Errorf("requested %s after revoking the token", got)
+	}
+}
+
+func TestPrivacyReport(t *testing.T) {
+	m := newTestModel(t, &fakeFetcher{}, &fakeFetcher{})
+	m.inputHistory.Add("gemini://example.org/sign", "hello")
+	m.inputHistory.Add("gemini://example.org/search", "gophers")
+	m.inputHistory.Add("gemini://other.example/sign", "hi")
+	m.tokens.Allow("example.org")
+	m.tokens.Capture("gemini://example.org/app?session=abc")
+	if err := m.identities.AddScope("alice", "gemini://example.org/"); err != nil {
+		t.Fatal(err)
+	}
+
+	report := m.privacyReport()
+	if len(report) != 2 || report[0].Host != "example.org" || report[1].Host != "other.example" {
+		t.Fatalf("report = %+v, want example.org and other.example", report)
+	}
+	if got := report[0]; got.Inputs != 2 || !got.TokenSet || len(got.Identities) != 1 || got.Identities[0] != "alice" {
+		t.Errorf("example.org = %+v, want 2 inputs, a token and alice", got)
+	}
+
+	// Forgetting a host asks first, then leaves the others alone
+	key := func(k string) {
+		msg := tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune(k)}
+		if k == "ctrl+p" {
+			msg = tea.KeyMsg{Type: tea.KeyCtrlP}
+		}
+		_, cmd := m.Update(msg)
+		run(t, m, cmd)
+	}
+	key("ctrl+p")
+	key("d")
+	if m.modals.Top() != m.confirmModal {
+		t.Fatal("forgetting a host should ask first")
+	}
+	key("y")
+	report = m.privacyReport()
+	if len(report) != 1 || report[0].Host != "other.example" {
+		t.Errorf("report after forgetting example.org = %+v", report)
+	}
+	if names := m.identities.HostIdentities()["example.org"]; len(names) != 0 {
+		t.Errorf("identities %v are still scoped to a forgotten host", names)
+	}
+	if m.tokens.Allowed("example.org") {
+		t.Error("the forgotten host should keep no token")
+	}
+}
+
+func TestStatusLog(t *testing.T) {
+	m := newTestModel(t, &fakeFetcher{}, &fakeFetcher{})
+	m.statusBar.SetError("Failed to fetch: connection refused")
+	m.statusBar.SetMessage("Ready")
+	m.statusBar.SetMessage("Ready")
+	m.statusBar.SetProgress("Receiving... 4 KiB")
+	m.statusBar.SetMessage("Done")
+
+	history := m.statusBar.History()
+	var texts []string
+	for _, entry := range history {
+		texts = append(texts, entry.Text)
+	}
+	if got := strings.Join(texts, " | "); got != "Done | Ready | Failed to fetch: connection refused" {
+		t.Errorf("status log = %q, want messages most recent first without repeats or progress", got)
+	}
+	if !history[2].Error {
+		t.Error("errors should be marked in the status log")
+	}
+
+	// Only the most recent messages are kept
+	for i := 0; i < 150; i++ {
+		m.statusBar.SetMessage(fmt.Sprintf("Message %d", i))
+	}
+	if history = m.statusBar.History(); len(history) != 100 || history[0].Text != "Message 149" || history[99].Text != "Message 50" {
+		t.Errorf("status log kept %d messages, from %q to %q", len(history), history[0].Text, history[len(history)-1].Text)
+	}
+
+	m.Update(tea.KeyMsg{Type: tea.KeyCtrlS})
+	if m.modals.Top() != m.statusLogModal {
+		t.Fatal("Ctrl+S should show the status log")
+	}
+	if view := ansi.Strip(m.View()); !strings.Contains(view, "Message 149") {
+		t.Errorf("status log does not show the latest message:\n%s", view)
+	}
+}
+
+func TestAddressBarCompletesHosts(t *testing.T) {
+	m := newTestModel(t, &fakeFetcher{}, &fakeFetcher{})
+	m.history.Add("gemini://example.net/old", "Old")
+	m.history.Add("gopher://example.com/1/phlog", "Phlog")
+	m.history.Add("gemini://example.org/page", "Page")
+	if err := m.bookmarks.Add("gemini://exhibit.example/", "Exhibit", nil); err != nil {
+		t.Fatal(err)
+	}
+	// The address bar's commands only blink the cursor
+	key := func(msg tea.KeyMsg) { m.Update(msg) }
+	typeText := func(text string) {
+		key(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune(text)})
+	}
+
+	key(tea.KeyMsg{Type: tea.KeyCtrlL})
+	typeText("ex")
+	var got []string
+	for i := 0; i < 5; i++ {
+		key(tea.KeyMsg{Type: tea.KeyTab})
+		got = append(got, m.addressBar.Value())
+	}
+	want := "gemini://example.org/ gopher://example.com/ gemini://example.net/ gemini://exhibit.example/ gemini://example.org/"
+	if strings.Join(got, " ") != want {
+		t.Errorf("Tab completed %v, want %s", got, want)
+	}
+	key(tea.KeyMsg{Type: tea.KeyShiftTab})
+	if got := m.addressBar.Value(); got != "gemini://exhibit.example/" {
+		t.Errorf("Shift+Tab completed %q, want the previous match", got)
+	}
+
+	// A typed scheme narrows the matches; typing on starts afresh
+	key(tea.KeyMsg{Type: tea.KeyCtrlC})
+	typeText("gopher://e")
+	key(tea.KeyMsg{Type: tea.KeyTab})
+	if got := m.addressBar.Value(); got != "gopher://example.com/" {
+		t.Errorf("completed %q, want the gopher host only", got)
+	}
+	key(tea.KeyMsg{Type: tea.KeyTab})
+	if got := m.addressBar.Value(); got != "gopher://example.com/" {
+		t.Errorf("cycled to %q with a single match", got)
+	}
+	typeText("1/")
+	key(tea.KeyMsg{Type: tea.KeyTab})
+	if got := m.addressBar.Value(); got != "gopher://example.com/1/" {
+		t.Errorf("Tab past the host changed the URL to %q", got)
+	}
+}
+
+// fakeUploader is a Gemini fetcher that also accepts Titan uploads
+type fakeUploader struct {
+	*fakeFetcher
+	uploads []gemini.TitanUpload
+	reply   *types.Response
+}
+
+func (f *fakeUploader) Upload(upload gemini.TitanUpload) (*types.Response, error) {
+	f.uploads = append(f.uploads, upload)
+	return f.reply, nil
+}
+
+func TestTitanUpload(t *testing.T) {
+	fake := &fakeFetcher{responses: map[string]*types.Response{
+		"gemini://example.org/notes/today.gmi": gemtext("# Today\nOld text\n"),
+		"gemini://example.org/notes/":          gemtext("# Notes\n"),
+	}}
+	m := newTestModel(t, fake, &fakeFetcher{})
+	client := &fakeUploader{fakeFetcher: fake, reply: &types.Response{Status: 30, Meta: "/notes/"}}
+	m.client = client
+	run(t, m, m.navigate("gemini://example.org/notes/today.gmi"))
+
+	// The form starts at the titan:// side of the page
+	m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("U")})
+	if m.modals.Top() != m.titanModal {
+		t.Fatal("U should open the upload form")
+	}
+	if view := ansi.Strip(m.View()); !strings.Contains(view, "titan://example.org/notes/today.gmi") {
+		t.Errorf("upload form does not offer the page's titan:// URL:\n%s", view)
+	}
+
+	// Text written in the editor is uploaded in place of the page
+	path := filepath.Join(t.TempDir(), "edited.gmi")
+	if err := os.WriteFile(path, []byte("# Today\nNew text\n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	m.Update(titanEditedMsg{path: path})
+	_, cmd := m.Update(tea.KeyMsg{Type: tea.KeyEnter})
+	run(t, m, cmd)
+
+	if len(client.uploads) != 1 {
+		t.Fatalf("%d uploads, want 1", len(client.uploads))
+	}
+	if got := client.uploads[0]; got.URL != "titan://example.org/notes/today.gmi" || string(got.Body) != "# Today\nNew text\n" {
+		t.Errorf("uploaded %q to %s, want the edited text to the page", got.Body, got.URL)
+	}
+	if m.titanModal.IsVisible() || m.currentURL != "gemini://example.org/notes/" {
+		t.Errorf("after the upload the form should close and the redirect be followed; at %s", m.currentURL)
+	}
+
+	// A refused upload keeps the form open with the reason
+	client.reply = &types.Response{Status: 60, Meta: "Certificate required"}
+	run(t, m, m.navigate("titan://example.org/notes/new.gmi"))
+	_, cmd = m.Update(tea.KeyMsg{Type: tea.KeyEnter})
+	run(t, m, cmd)
+	if !m.titanModal.IsVisible() {
+		t.Fatal("a refused upload should leave the form open")
+	}
+	if view := ansi.Strip(m.View()); !strings.Contains(view, "Certificate required") {
+		t.Errorf("the form does not say why the upload was refused:\n%s", view)
+	}
+	if got := client.uploads[1]; string(got.Body) != "# Notes\n" || got.MIME != "text/gemini" {
+		t.Errorf("uploaded %q as %q, want the current page as text/gemini", got.Body, got.MIME)
+	}
+}
+
+func TestViewExternally(t *testing.T) {
+	fake := &fakeFetcher{responses: map[string]*types.Response{
+		"gemini://example.org/":         gemtext("# Home\n=> report Report\n"),
+		"gemini://example.org/report":   {Status: 20, Meta: "application/pdf", Body: []byte("%PDF-1.7")},
+		"gemini://example.org/gone.pdf": {Status: 51, Meta: "Not found"},
+	}}
+	m := newTestModel(t, fake, &fakeFetcher{})
+	out := t.TempDir()
+	m.config.Get().General.Openers = map[string]string{
+		"text/gemini":   "cp {file} " + filepath.Join(out, "home.gmi"),
+		"application/*": "cp -t " + out,
+	}
+	run(t, m, m.navigate("gemini://example.org/"))
+
+	// The page goes to the command configured for its type
+	_, cmd := m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("W")})
+	run(t, m, cmd)
+	if data, err := os.ReadFile(filepath.Join(out, "home.gmi")); err != nil || string(data) != "# Home\n=> report Report\n" {
+		t.Errorf("viewer was given %q, %v", data, err)
+	}
+
+	// A link is fetched and named for its type; type/* matches any subtype
+	run(t, m, m.doLinkAction(ui.LinkActionMsg{Action: ui.LinkViewExternal, URL: "gemini://example.org/report"}))
+	copies, _ := filepath.Glob(filepath.Join(out, "report-*.pdf"))
+	if len(copies) != 1 {
+		t.Errorf("viewer copies = %v, want one report PDF", copies)
+	}
+
+	run(t, m, m.viewLinkExternally("gemini://example.org/gone.pdf"))
+	if bar := ansi.Strip(m.statusBar.View()); !strings.Contains(bar, "Cannot view") {
+		t.Errorf("failed fetch reported as %q", bar)
+	}
+
+	// The temporary files go on shutdown
+	dir := m.viewerDir
+	if dir == "" {
+		t.Fatal("no viewer files were written")
+	}
+	m.Shutdown()
+	if _, err := os.Stat(dir); !os.IsNotExist(err) {
+		t.Errorf("viewer files left in %s: %v", dir, err)
+	}
+}
+
+func TestPagerCommand(t *testing.T) {
+	cmd := pagerCommand("", "/tmp/page.txt")
+	if got := strings.Join(cmd.Args, " "); got != "less -R /tmp/page.txt" {
+		t.Errorf("default pager = %q, want less -R", got)
+	}
+	cmd = pagerCommand("most -s", "/tmp/page.txt")
+	if got := strings.Join(cmd.Args, " "); got != "most -s /tmp/page.txt" {
+		t.Errorf("$PAGER with arguments = %q", got)
+	}
+
+	fake := &fakeFetcher{responses: map[string]*types.Response{
+		"gemini://example.org/": gemtext("# Home\nSome text\n"),
+	}}
+	m := newTestModel(t, fake, &fakeFetcher{})
+	run(t, m, m.navigate("gemini://example.org/"))
+	if page := ansi.Strip(m.viewport.Rendered()); !strings.Contains(page, "Some text") {
+		t.Errorf("rendered page = %q, want the page text", page)
+	}
+	if _, cmd := m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("|")}); cmd == nil {
+		t.Error("| should open the pager")
+	}
+}
+
+func TestSessionExportImport(t *testing.T) {
+	fake := &fakeFetcher{responses: map[string]*types.Response{
+		"gemini://example.org/":       gemtext("# Example\n"),
+		"gopher://example.org/1/news": {Body: []byte("iNews\t\terror.host\t1\r\n.\r\n")},
+	}}
+	m := newTestModel(t, fake, fake)
+	run(t, m, m.navigate("gemini://example.org/"))
+	run(t, m, m.openInBackground([]string{"gopher://example.org/1/news"}))
+
+	m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'E'}})
+	if m.lastSessionExport == "" {
+		t.Fatal("Shift+E did not export the session")
+	}
+	data, err := os.ReadFile(m.lastSessionExport)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(string(data), "=> gemini://example.org/ Example\n") || !strings.Contains(string(data), "=> gopher://example.org/1/news") {
+		t.Errorf("exported session is missing tabs:\n%s", data)
+	}
+
+	// Importing opens every link in a background tab
+	other := newTestModel(t, fake, fake)
+	other.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'O'}})
+	if other.modals.Top() != other.inputModal {
+		t.Fatal("Shift+O should ask for the file to import")
+	}
+	_, cmd := other.Update(ui.InputSubmitMsg{Input: m.lastSessionExport})
+	run(t, other, cmd)
+	var urls []string
+	for _, tab := range other.tabBar.GetTabs() {
+		urls = append(urls, tab.URL)
+	}
+	if got := strings.Join(urls, " "); !strings.HasSuffix(got, "gemini://example.org/ gopher://example.org/1/news") {
+		t.Errorf("tabs after import = %q", got)
+	}
+}
+
+func TestSessionLinks(t *testing.T) {
+	page := "# Session\n=> gemini://a.example/ A\n=> /relative\n=> https://web.example/\n```\n=> gemini://pre.example/\n```\n=> gopher://b.example/1/\n=> gemini://a.example/ Again\n"
+	got := strings.Join(sessionLinks([]byte(page)), " ")
+	if want := "gemini://a.example/ gopher://b.example/1/"; got != want {
+		t.Errorf("sessionLinks = %q, want %q", got, want)
+	}
+}
+
+func TestCertExpiry(t *testing.T) {
+	now := time.Now()
+	certs := map[string]gemini.CertificateInfo{
+		"far.example":     {Fingerprint: "aa", NotAfter: now.AddDate(1, 0, 0)},
+		"soon.example":    {Fingerprint: "bb", NotAfter: now.AddDate(0, 0, 10)},
+		"expired.example": {Fingerprint: "cc", NotAfter: now.AddDate(0, 0, -5)},
+	}
+	data, err := json.Marshal(certs)
+	if err != nil {
+		t.Fatal(err)
+	}
+	storePath := filepath.Join(t.TempDir(), "known_hosts.json")
+	if err := os.WriteFile(storePath, data, 0600); err != nil {
+		t.Fatal(err)
+	}
+	fake := &fakeFetcher{responses: map[string]*types.Response{
+		"gemini://soon.example/": gemtext("# Soon\n"),
+	}}
+	m := newTestModel(t, fake, &fakeFetcher{})
+	if m.tofuStore, err = gemini.NewTOFUStore(storePath); err != nil {
+		t.Fatal(err)
+	}
+
+	var hosts []string
+	for _, cert := range m.certExpiries() {
+		hosts = append(hosts, cert.Host)
+	}
+	if got := strings.Join(hosts, " "); got != "expired.example soon.example far.example" {
+		t.Fatalf("certificates listed as %q, want soonest expiry first", got)
+	}
+
+	key := func(k string) {
+		_, cmd := m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune(k)})
+		run(t, m, cmd)
+	}
+	key("T")
+	if m.modals.Top() != m.certExpiryModal {
+		t.Fatal("Shift+T should list the certificates by expiry")
+	}
+	if view := ansi.Strip(m.View()); !strings.Contains(view, "expired 5d ago") {
+		t.Errorf("expired certificate not called out:\n%s", view)
+	}
+
+	// Forgetting asks first
+	key("d")
+	if m.modals.Top() != m.confirmModal {
+		t.Fatal("forgetting a certificate should ask first")
+	}
+	key("y")
+	if _, ok := m.tofuStore.GetCertInfo("expired.example"); ok {
+		t.Error("the expired certificate was not forgotten")
+	}
+
+	// Re-verifying connects to the selected host again
+	key("r")
+	if len(fake.requests) != 1 || fake.requests[0] != "gemini://soon.example/" {
+		t.Errorf("requests = %v, want the selected host", fake.requests)
+	}
+	if got := m.statusBar.History()[0].Text; !strings.Contains(got, "soon.example presents its trusted certificate") {
+		t.Errorf("status after re-verifying = %q", got)
+	}
+}
+
+func TestCertificateManager(t *testing.T) {
+	certs := map[string]gemini.CertificateInfo{
+		"b.example": {Fingerprint: "bb", Subject: "CN=b.example"},
+		"a.example": {Fingerprint: "aa", Subject: "CN=a.example"},
+	}
+	data, err := json.Marshal(certs)
+	if err != nil {
+		t.Fatal(err)
+	}
+	storePath := filepath.Join(t.TempDir(), "known_hosts.json")
+	if err := os.WriteFile(storePath, data, 0600); err != nil {
+		t.Fatal(err)
+	}
+	m := newTestModel(t, &fakeFetcher{}, &fakeFetcher{})
+	if m.tofuStore, err = gemini.NewTOFUStore(storePath); err != nil {
+		t.Fatal(err)
+	}
+
+	key := func(k string) {
+		_, cmd := m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune(k)})
+		run(t, m, cmd)
+	}
+	key("c")
+	if m.modals.Top() != m.certificateModal {
+		t.Fatal("c should open the certificate manager")
+	}
+	if view := ansi.Strip(m.View()); !strings.Contains(view, "a.example") || !strings.Contains(view, "TRUSTED") {
+		t.Errorf("trusted certificates are not listed:\n%s", view)
+	}
+
+	// Untrusting forgets the selected host's certificate after asking
+	key("u")
+	if m.modals.Top() != m.confirmModal {
+		t.Fatal("untrusting a certificate should ask first")
+	}
+	key("y")
+	if _, ok := m.tofuStore.GetCertInfo("a.example"); ok {
+		t.Error("the certificate of a.example was not forgotten")
+	}
+	if got := m.certificates(); len(got) != 1 || got[0].Host != "b.example" {
+		t.Errorf("certificates after untrusting = %+v", got)
+	}
+	if view := ansi.Strip(m.View()); strings.Contains(view, "a.example") {
+		t.Errorf("the certificate manager still lists a.example:\n%s", view)
+	}
+}
+
+func TestSlowHostLinks(t *testing.T) {
+	fake := &fakeFetcher{responses: map[string]*types.Response{
+		"gemini://example.org/": gemtext("# Links\n=> gemini://slow.example/ Slow capsule\n=> gemini://fast.example/ Fast capsule\n"),
+	}}
+	m := newTestModel(t, fake, &fakeFetcher{})
+	for i := 0; i < 3; i++ {
+		m.stats.RecordLatency("slow.example", 5*time.Second, false)
+		m.stats.RecordLatency("fast.example", 100*time.Millisecond, false)
+	}
+	run(t, m, m.navigate("gemini://example.org/"))
+
+	if metrics := m.stats.HostMetrics(); len(metrics) != 3 || metrics[2].Host != "example.org" || metrics[2].Requests != 1 {
+		t.Errorf("host metrics = %+v, want the fetch of example.org timed", metrics)
+	}
+	page := ansi.Strip(m.viewport.Rendered())
+	if !strings.Contains(page, "Slow capsule (slow host)") {
+		t.Errorf("link to a slow host is not marked:\n%s", page)
+	}
+	if strings.Contains(page, "Fast capsule (slow host)") {
+		t.Errorf("link to a fast host is marked slow:\n%s", page)
+	}
+}
+
+func TestHistoryModal(t *testing.T) {
+	fake := &fakeFetcher{responses: map[string]*types.Response{
+		"gemini://example.org/a": gemtext("# Page A\n"),
+		"gemini://example.org/b": gemtext("# Page B\n"),
+	}}
+	m := newTestModel(t, fake, &fakeFetcher{})
+	run(t, m, m.navigate("gemini://example.org/a"))
+	run(t, m, m.navigate("gemini://example.org/b"))
+
+	key := func(msg tea.KeyMsg) {
+		_, cmd := m.Update(msg)
+		run(t, m, cmd)
+	}
+
+	// Ctrl+H lists the visits, most recent first; Enter goes back to one
+	key(tea.KeyMsg{Type: tea.KeyCtrlH})
+	if m.modals.Top() != m.historyModal {
+		t.Fatal("Ctrl+H should open the history")
+	}
+	key(tea.KeyMsg{Type: tea.KeyDown})
+	key(tea.KeyMsg{Type: tea.KeyEnter})
+	if m.currentURL != "gemini://example.org/a" {
+		t.Errorf("current page %q after choosing the older visit, want page A", m.currentURL)
+	}
+
+	// Delete forgets the selected page's visits after asking
+	key(tea.KeyMsg{Type: tea.KeyCtrlH})
+	key(tea.KeyMsg{Type: tea.KeyDown})
+	key(tea.KeyMsg{Type: tea.KeyDelete})
+	if m.modals.Top() != m.confirmModal {
+		t.Fatal("deleting from history should ask first")
+	}
+	key(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("y")})
+	for _, entry := range m.history.GetAll() {
+		if entry.URL == "gemini://example.org/b" {
+			t.Errorf("visit to page B was not forgotten: %+v", m.history.GetAll())
+			break
+		}
+	}
+}
+
+func TestImageDecodesOffUIThread(t *testing.T) {
+	var picture bytes.Buffer
+	if err := png.Encode(&picture, image.NewRGBA(image.Rect(0, 0, 8, 8))); err != nil {
+		t.Fatal(err)
+	}
+	fake := &fakeFetcher{responses: map[string]*types.Response{
+		"gemini://example.org/cat.png": {Status: 20, Meta: "image/png", Body: picture.Bytes()},
+	}}
+	m := newTestModel(t, fake, &fakeFetcher{})
+	decoded := func() tea.Cmd {
+		_, cmd := m.Update(m.navigate("gemini://example.org/cat.png")())
+		if cmd == nil {
+			t.Fatal("the image should be decoded by a command")
+		}
+		return cmd
+	}
+
+	// A placeholder is shown until the image is ready
+	decode := decoded()
+	if text := m.currentDoc.Lines[0].Text; !strings.HasPrefix(text, "Decoding image… ") {
+		t.Errorf("placeholder = %q", text)
+	}
+	run(t, m, decode)
+	if text := m.currentDoc.Lines[0].Text; !strings.HasPrefix(text, "Image: 8x8") {
+		t.Errorf("rendered image starts with %q", text)
+	}
+
+	// An image finished in a tab left meanwhile is kept in that tab
+	decode = decoded()
+	m.Update(tea.KeyMsg{Type: tea.KeyCtrlT})
+	run(t, m, decode)
+	if m.currentDoc != nil && len(m.currentDoc.Lines) > 0 && strings.HasPrefix(m.currentDoc.Lines[0].Text, "Image:") {
+		t.Error("the image replaced the page of another tab")
+	}
+	if doc := m.tabBar.GetTabs()[0].Document; doc == nil || !strings.HasPrefix(doc.Lines[0].Text, "Image: 8x8") {
+		t.Errorf("the image's tab holds %+v", doc)
+	}
+}
+
+func TestImageZoom(t *testing.T) {
+	picture := image.NewRGBA(image.Rect(0, 0, 200, 20))
+	for x := 0; x < 200; x++ {
+		for y := 0; y < 20; y++ {
+			picture.Set(x, y, color.RGBA{uint8(x), uint8(y * 12), 90, 255})
+		}
+	}
+	var encoded bytes.Buffer
+	if err := png.Encode(&encoded, picture); err != nil {
+		t.Fatal(err)
+	}
+	fake := &fakeFetcher{responses: map[string]*types.Response{
+		"gemini://example.org/wide.png": {Status: 20, Meta: "image/png", Body: encoded.Bytes()},
+	}}
+	m := newTestModel(t, fake, &fakeFetcher{})
+	m.imageView = renderer.ImageOptions{}
+	run(t, m, m.navigate("gemini://example.org/wide.png"))
+
+	press := func(msg tea.KeyMsg, want string) {
+		t.Helper()
+		_, cmd := m.Update(msg)
+		run(t, m, cmd)
+		if header := m.currentDoc.Lines[1].Text; !strings.Contains(header, want) {
+			t.Errorf("after %s the image is drawn as %q, want %q", msg, header, want)
+		}
+	}
+	plus := tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("+")}
+	press(plus, "fit width")
+	press(plus, "Displayed as 76x16, fit height, showing from 0,0")
+	press(tea.KeyMsg{Type: tea.KeyShiftRight}, "showing from 19,0")
+	press(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("x")}, "256 colours, dithered")
+	if row := m.currentDoc.Lines[3].Text; !strings.Contains(row, "\x1b[38;5;") {
+		t.Errorf("256-colour row = %q", row)
+	}
+	press(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("m")}, "grayscale")
+	press(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("-")}, "Displayed as 76x4, fit width, 256 colours, dithered, grayscale")
+}
+
+func TestImageMetadata(t *testing.T) {
+	var encoded bytes.Buffer
+	if err := jpeg.Encode(&encoded, image.NewGray(image.Rect(0, 0, 16, 8)), nil); err != nil {
+		t.Fatal(err)
+	}
+
+	// A little-endian TIFF block: IFD0 holds the make, the model and a
+	// pointer to the EXIF IFD, which holds the capture date
+	le := binary.LittleEndian
+	tiff := []byte("II*\x00\x08\x00\x00\x00")
+	entry := func(tag, kind uint16, size, value uint32) {
+		tiff = le.AppendUint16(tiff, tag)
+		tiff = le.AppendUint16(tiff, kind)
+		tiff = le.AppendUint32(tiff, size)
+		tiff = le.AppendUint32(tiff, value)
+	}
+	// IFD0 at 8: three entries, then the next-IFD offset; data follows at 50
+	tiff = le.AppendUint16(tiff, 3)
+	entry(0x010F, 2, 6, 50)  // "Canon"
+	entry(0x0110, 2, 13, 56) // "Canon EOS 5D"
+	entry(0x8769, 4, 1, 70)
+	tiff = le.AppendUint32(tiff, 0)
+	tiff = append(tiff, "Canon\x00Canon EOS 5D\x00\x00"...)
+	// EXIF IFD at 70
+	tiff = le.AppendUint16(tiff, 1)
+	entry(0x9003, 2, 20, 88)
+	tiff = le.AppendUint32(tiff, 0)
+	tiff = append(tiff, "2024:05:17 09:30:12\x00"...)
+
+	segment := append([]byte("Exif\x00\x00"), tiff...)
+	app1 := binary.BigEndian.AppendUint16([]byte{0xFF, 0xE1}, uint16(len(segment)+2))
+	body := append(append(append([]byte{}, encoded.Bytes()[:2]...), append(app1, segment...)...), encoded.Bytes()[2:]...)
+
+	fake := &fakeFetcher{responses: map[string]*types.Response{
+		"gemini://example.org/photo.jpg": {Status: 20, Meta: "image/jpeg", Body: body},
+	}}
+	m := newTestModel(t, fake, &fakeFetcher{})
+	run(t, m, m.navigate("gemini://example.org/photo.jpg"))
+
+	want := []string{
+		"Image: 16x8 JPEG, " + ui.FormatBytes(int64(len(body))),
+		"Taken: 2024-05-17 09:30:12",
+		"Camera: Canon EOS 5D",
+	}
+	for i, line := range want {
+		if got := m.currentDoc.Lines[i].Text; got != line {
+			t.Errorf("line %d = %q, want %q", i, got, line)
+		}
+	}
+	if got := m.currentDoc.Lines[3].Text; !strings.HasPrefix(got, "Displayed as 16x4") {
+		t.Errorf("line 3 = %q", got)
+	}
+}
+
+func TestTelnetSession(t *testing.T) {
+	// Values from the link that a command could take as options are refused
+	for _, session := range []gopher.Session{
+		{Kind: "telnet", Host: "bbs.example.org", Port: "22", User: "-oProxyCommand=touch /tmp/pwned"},
+		{Kind: "telnet", Host: "-n/tmp/trace", Port: "23"},
+		{Kind: "telnet", Host: "bbs.example.org", Port: "-1"},
+		{Kind: "telnet", Host: "bbs.example.org", Port: "23", User: "guest -v"},
+		{Kind: "telnet", Host: "bbs.example.org\x1b[2J", Port: "23"},
+	} {
+		if cmd, err := sessionCommand("ssh {user}@{host} -p {port}", session); err == nil {
+			t.Errorf("%+v was accepted as %q", session, cmd.Args)
+		}
+	}
+	cmd, err := sessionCommand("telnet {host} {port}", gopher.Session{Kind: "telnet", Host: "bbs.example.org", Port: "23"})
+	if err != nil || strings.Join(cmd.Args, " ") != "telnet bbs.example.org 23" {
+		t.Errorf("sessionCommand = %v, %v", cmd, err)
+	}
+
+	// The command only runs once the user agrees
+	m := newTestModel(t, &fakeFetcher{}, &fakeFetcher{})
+	m.config.Get().General.TelnetCommand = "telnet {host} {port}"
+	if start := m.navigate("telnet://bbs.example.org:23"); start != nil {
+		t.Error("the session started without asking")
+	}
+	if m.modals.Top() != m.confirmModal {
+		t.Fatal("opening a session should ask first")
+	}
+	_, confirmed := m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("y")})
+	if confirmed == nil {
+		t.Fatal("confirming should start the session")
+	}
+	if _, start := m.Update(confirmed()); start == nil {
+		t.Error("confirming did not start the session")
+	}
+
+	// A hostile link is refused without asking
+	m.navigate("telnet://-oProxyCommand=x@bbs.example.org:22")
+	if m.modals.Top() == m.confirmModal {
+		t.Error("a session with a hostile login name was offered")
+	}
+	if bar := ansi.Strip(m.statusBar.View()); !strings.Contains(bar, "could be taken as an option") {
+		t.Errorf("status bar = %q", bar)
+	}
+}
+
+func TestBackgroundRedirect(t *testing.T) {
+	fake := &fakeFetcher{responses: map[string]*types.Response{
+		"gemini://example.org/old":   {Status: 31, Meta: "/new"},
+		"gemini://example.org/new":   gemtext("# New home\n"),
+		"gemini://example.org/ping":  {Status: 30, Meta: "pong"},
+		"gemini://example.org/pong":  {Status: 30, Meta: "/ping"},
+		"gemini://example.org/leave": {Status: 30, Meta: "gemini://other.example/"},
+		"gemini://other.example/":    gemtext("# Elsewhere\n"),
+	}}
+	m := newTestModel(t, fake, &fakeFetcher{})
+	tab := func(i int) types.Tab { return m.tabBar.GetTabs()[i] }
+
+	// A relative target is resolved against the redirecting page
+	run(t, m, m.openInBackground([]string{"gemini://example.org/old"}))
+	if got := tab(1); got.Failed || got.URL != "gemini://example.org/new" || got.Document == nil {
+		t.Fatalf("redirected tab = %+v", got)
+	}
+
+	// A loop is stopped without using up the limit
+	fake.requests = nil
+	run(t, m, m.openInBackground([]string{"gemini://example.org/ping"}))
+	if !tab(2).Failed || len(fake.requests) != 2 {
+		t.Errorf("redirect loop: failed %v after %d requests", tab(2).Failed, len(fake.requests))
+	}
+
+	// Leaving for another host waits for consent
+	fake.requests = nil
+	run(t, m, m.openInBackground([]string{"gemini://example.org/leave"}))
+	if m.modals.Top() != m.confirmModal || len(fake.requests) != 1 || tab(3).Loading {
+		t.Fatalf("cross-host redirect was not held back: %v", fake.requests)
+	}
+	_, cmd := m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("y")})
+	run(t, m, cmd)
+	if got := tab(3); got.URL != "gemini://other.example/" || got.Document == nil {
+		t.Errorf("confirmed redirect = %+v", got)
+	}
+}
+
+func TestStatusBarIdentity(t *testing.T) {
+	fake := &fakeFetcher{responses: map[string]*types.Response{
+		"gemini://example.org/": gemtext("# Garden\n"),
+	}}
+	m := newTestModel(t, fake, &fakeFetcher{})
+	if _, err := m.identities.Generate(gemini.IdentityOptions{Name: "gardener", KeyType: gemini.KeyEd25519, Validity: time.Hour}); err != nil {
+		t.Fatal(err)
+	}
+	if err := m.identities.AddScope("gardener", "gemini://example.org/"); err != nil {
+		t.Fatal(err)
+	}
+	run(t, m, m.navigate("gemini://example.org/"))
+
+	// The identity presented on the page is shown, and clicking it opens
+	// the identity picker
+	m.View()
+	bar := ansi.Strip(m.statusBar.View())
+	x := strings.Index(bar, "⚷ gardener")
+	if x < 0 {
+		t.Fatalf("status bar does not show the identity: %q", bar)
+	}
+	x = ansi.StringWidth(bar[:x])
+	_, cmd := m.statusBar.Update(tea.MouseMsg{X: x, Action: tea.MouseActionPress, Button: tea.MouseButtonLeft})
+	if cmd == nil {
+		t.Fatal("clicking the identity did nothing")
+	}
+	if msg := cmd(); msg != (ui.StatusClickMsg{Zone: ui.StatusZoneIdentity}) {
+		t.Fatalf("clicking the identity sent %v", msg)
+	}
+	run(t, m, cmd)
+	if m.modals.Top() != m.identitiesModal {
+		t.Error("clicking the identity should open the identity picker")
+	}
+}
+
+func TestBookmarkEditKeepsSelection(t *testing.T) {
+	m := newTestModel(t, &fakeFetcher{}, &fakeFetcher{})
+	for _, title := range []string{"Alpha", "Beta", "Gamma"} {
+		if err := m.bookmarks.Add("gemini://"+strings.ToLower(title)+".example/", title, nil); err != nil {
+			t.Fatal(err)
+		}
+	}
+	m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("b")})
+
+	// Renaming the first bookmark sorts it last; it stays selected
+	m.Update(ui.BookmarkEditMsg{OldURL: "gemini://alpha.example/", URL: "gemini://omega.example/", Title: "Omega"})
+	_, cmd := m.bookmarksModal.Update(tea.KeyMsg{Type: tea.KeyEnter})
+	if cmd == nil {
+		t.Fatal("no bookmark selected after editing")
+	}
+	if msg := cmd(); msg != (ui.BookmarkSelectedMsg{URL: "gemini://omega.example/"}) {
+		t.Errorf("selected %v after editing, want the edited bookmark", msg)
+	}
+}
+
+func TestViewerFileNames(t *testing.T) {
+	// Types the desktop would run rather than open are refused
+	for _, c := range []struct{ name, mediaType string }{
+		{"setup.exe", "application/x-msdownload"},
+		{"run.bat", "application/x-bat"},
+		{"tool", "application/octet-stream"},
+		{"script.sh", ""},
+	} {
+		if ext, err := viewerExtension(c.name, c.mediaType); err == nil {
+			t.Errorf("%s (%s) would be viewed as %s", c.name, c.mediaType, ext)
+		}
+	}
+	for _, c := range []struct{ name, mediaType, want string }{
+		{"report", "application/pdf", ".pdf"},
+		{"index.gmi", "text/gemini", ".gmi"},
+		{"run.bat", "text/plain", ".txt"},
+		{"photo.JPG", "image/jpeg", ".jpg"},
+	} {
+		if ext, err := viewerExtension(c.name, c.mediaType); err != nil || ext != c.want {
+			t.Errorf("%s (%s) viewed as %q, %v, want %s", c.name, c.mediaType, ext, err, c.want)
+		}
+	}
+
+	// Names keep no shell metacharacters
+	m := newTestModel(t, &fakeFetcher{}, &fakeFetcher{})
+	file, err := m.writeViewerFile("gemini://example.org/a%26calc%5Eb%22.pdf", "application/pdf", []byte("%PDF"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if name := filepath.Base(file); strings.ContainsAny(name, "&^\"") || !strings.HasPrefix(name, "a_calc_b_-") {
+		t.Errorf("viewer file named %q", name)
+	}
+	m.removeViewerFiles()
+}
@@ -0,0 +1,70 @@
+package app
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"starsearch/internal/archive"
+	"starsearch/internal/ui"
+)
+
+// archiveExtractedMsg reports the result of extracting an archive entry
+type archiveExtractedMsg struct {
+	path string
+	err  error
+}
+
+// openArchive lists the entries of the last archive downloaded
+func (m *Model) openArchive() {
+	if m.lastArchive == "" {
+		m.statusBar.SetMessage("No archive downloaded yet")
+		return
+	}
+	entries, err := archive.List(m.lastArchive)
+	if err != nil {
+		m.statusBar.SetError(fmt.Sprintf("Failed to read archive: %v", err))
+		return
+	}
+	m.archiveModal.Show(m.lastArchive, entries)
+	ui.OpenModal(m.modals, m.archiveModal)
+}
+
+// extractEntry writes one entry of an archive to a new file in the
+// download directory, named after the entry without its directories
+func (m *Model) extractEntry(msg ui.ArchiveExtractMsg) tea.Cmd {
+	dir := m.config.GetDownloadDirectory()
+	m.writes.Add(1)
+	return func() tea.Msg {
+		defer m.writes.Done()
+		if err := os.MkdirAll(dir, 0755); err != nil {
+			return archiveExtractedMsg{err: fmt.Errorf("failed to create download directory: %w", err)}
+		}
+
+		// An entry named "..", say, would otherwise be written beside the
+		// download directory rather than in it
+		name := filepath.Base(filepath.FromSlash(strings.ReplaceAll(msg.Entry, "\\", "/")))
+		if name == "" || name == "." || name == ".." || name == string(filepath.Separator) {
+			return archiveExtractedMsg{err: fmt.Errorf("cannot extract %s: it has no file name", msg.Entry)}
+		}
+		filePath := uniquePath(filepath.Join(dir, name))
+		if filepath.Dir(filePath) != filepath.Clean(dir) {
+			return archiveExtractedMsg{err: fmt.Errorf("cannot extract %s outside the download directory", msg.Entry)}
+		}
+		f, err := os.OpenFile(filePath, os.O_CREATE|os.O_EXCL|os.O_WRONLY, 0644)
+		if err != nil {
+			return archiveExtractedMsg{err: fmt.Errorf("failed to extract %s: %w", msg.Entry, err)}
+		}
+		err = archive.Extract(msg.Path, msg.Entry, f)
+		if closeErr := f.Close(); err == nil {
+			err = closeErr
+		}
+		if err != nil {
+			os.Remove(filePath)
+			return archiveExtractedMsg{err: fmt.Errorf("failed to extract %s: %w", msg.Entry, err)}
+		}
+		return archiveExtractedMsg{path: filePath}
+	}
+}
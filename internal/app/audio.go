@@ -0,0 +1,161 @@
+package app
+
+import (
+	"bytes"
+	"fmt"
+	"net/url"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// audioTrack is a sound file waiting to be played
+type audioTrack struct {
+	url  string
+	body []byte
+}
+
+// name is how the track is shown in the status bar
+func (t audioTrack) name() string {
+	if u, err := url.Parse(t.url); err == nil {
+		return downloadFilename(u)
+	}
+	return t.url
+}
+
+// audioQueue plays sound files one after another with the configured
+// player, which runs in the background while browsing goes on
+type audioQueue struct {
+	waiting []audioTrack
+	playing *audioTrack
+	player  *exec.Cmd
+	file    string // Temporary file of the playing track, if it has one
+	id      int    // Counts tracks started, so a stopped track's exit is ignored
+}
+
+// audioFinishedMsg reports that the player exited
+type audioFinishedMsg struct {
+	id  int
+	err error
+}
+
+// queueAudio plays a fetched sound file, or queues it behind the one
+// playing. Without a player configured the file is saved instead.
+func (m *Model) queueAudio(urlStr string, body []byte) tea.Cmd {
+	if strings.TrimSpace(m.config.Get().General.AudioPlayer) == "" {
+		m.statusBar.SetMessage("Saving " + urlStr + " (set general.audio_player to play sound)...")
+		return m.saveFetched(urlStr, body)
+	}
+
+	track := audioTrack{url: urlStr, body: body}
+	m.audio.waiting = append(m.audio.waiting, track)
+	if m.audio.playing == nil {
+		return m.playNext()
+	}
+	m.showNowPlaying()
+	return m.notify(fmt.Sprintf("Queued %s (%d waiting)", track.name(), len(m.audio.waiting)))
+}
+
+// playNext starts the player on the next track in the queue. A player
+// command with {file} is given the path of a temporary copy; any other
+// command has the sound streamed to its standard input.
+func (m *Model) playNext() tea.Cmd {
+	m.endTrack()
+	for len(m.audio.waiting) > 0 {
+		track := m.audio.waiting[0]
+		m.audio.waiting = m.audio.waiting[1:]
+
+		player, file, err := playerCommand(m.config.Get().General.AudioPlayer, track)
+		if err == nil {
+			err = player.Start()
+		}
+		if err != nil {
+			if file != "" {
+				os.Remove(file)
+			}
+			m.statusBar.SetError(fmt.Sprintf("Cannot play %s: %v", track.name(), err))
+			continue
+		}
+
+		m.audio.id++
+		m.audio.playing = &track
+		m.audio.player = player
+		m.audio.file = file
+		m.showNowPlaying()
+
+		id := m.audio.id
+		return func() tea.Msg {
+			return audioFinishedMsg{id: id, err: player.Wait()}
+		}
+	}
+	m.showNowPlaying()
+	return nil
+}
+
+// playerCommand builds the player command for track from a template such
+// as "mpv --no-terminal {file}", writing the temporary file it names
+func playerCommand(template string, track audioTrack) (cmd *exec.Cmd, file string, err error) {
+	args := strings.Fields(template)
+	if len(args) == 0 {
+		return nil, "", fmt.Errorf("general.audio_player is empty")
+	}
+	if strings.Contains(template, "{file}") {
+		f, err := os.CreateTemp("", "starsearch-audio-*"+filepath.Ext(track.name()))
+		if err != nil {
+			return nil, "", fmt.Errorf("failed to write sound file: %w", err)
+		}
+		file = f.Name()
+		_, err = f.Write(track.body)
+		if closeErr := f.Close(); err == nil {
+			err = closeErr
+		}
+		if err != nil {
+			return nil, file, fmt.Errorf("failed to write sound file: %w", err)
+		}
+		for i, arg := range args {
+			args[i] = strings.ReplaceAll(arg, "{file}", file)
+		}
+	}
+
+	cmd = exec.Command(args[0], args[1:]...)
+	if file == "" {
+		cmd.Stdin = bytes.NewReader(track.body)
+	}
+	return cmd, file, nil
+}
+
+// stopAudio stops the playing track and empties the queue
+func (m *Model) stopAudio() {
+	if m.audio.playing == nil {
+		return
+	}
+	if m.audio.player.Process != nil {
+		_ = m.audio.player.Process.Kill()
+	}
+	m.audio.waiting = nil
+	m.audio.id++ // The killed player's exit is not a reason to play on
+	m.endTrack()
+	m.showNowPlaying()
+}
+
+// endTrack forgets the playing track and removes its temporary file
+func (m *Model) endTrack() {
+	if m.audio.file != "" {
+		os.Remove(m.audio.file)
+	}
+	m.audio.playing = nil
+	m.audio.player = nil
+	m.audio.file = ""
+}
+
+// showNowPlaying updates the status bar's now-playing segment
+func (m *Model) showNowPlaying() {
+	if m.audio.playing == nil {
+		m.statusBar.SetNowPlaying("", 0)
+		return
+	}
+	m.statusBar.SetNowPlaying(m.audio.playing.name(), len(m.audio.waiting))
+}
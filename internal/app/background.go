@@ -0,0 +1,262 @@
+package app
+
+import (
+	"errors"
+	"fmt"
+	"net/url"
+	"slices"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"starsearch/internal/gemini"
+	"starsearch/internal/gopher"
+	"starsearch/internal/renderer"
+	"starsearch/internal/scheduler"
+	"starsearch/internal/types"
+	"starsearch/internal/urlutil"
+)
+
+// fetchReason says why a page is fetched into a tab other than through
+// navigate
+type fetchReason int
+
+const (
+	fetchOpen    fetchReason = iota // Opening a new background tab
+	fetchRestore                    // Reloading an evicted tab
+	fetchWatch                      // Automatic reload of a watched tab
+)
+
+// backgroundFetchMsg carries the result of fetching a page into a tab
+// other than through navigate: a background, evicted or watched tab
+type backgroundFetchMsg struct {
+	tabID    int
+	url      string
+	protocol string
+	resp     *types.Response
+	err      error
+	chain    []string           // URLs redirected from so far, for loop detection
+	priority scheduler.Priority // Priority used for this fetch and any redirects
+	reason   fetchReason
+}
+
+// openInBackground opens each URL in a new background tab and fetches them
+// all through the scheduler at background priority
+func (m *Model) openInBackground(urls []string) tea.Cmd {
+	cmds := make([]tea.Cmd, 0, len(urls))
+	for _, u := range urls {
+		id := m.tabBar.AddBackgroundTab(u, u)
+		m.tabBar.SetTabStatus(m.tabBar.IndexOf(id), true, false)
+		cmds = append(cmds, m.fetchIntoTab(id, u, nil, scheduler.Background, fetchOpen))
+	}
+
+	m.bgTotal += len(urls)
+	m.statusBar.SetMessage(fmt.Sprintf("Opening %d links in background tabs...", len(urls)))
+	return tea.Batch(cmds...)
+}
+
+// fetchIntoTab fetches urlStr for the tab with the given ID once the
+// scheduler grants a slot. Waiting happens in the command's goroutine, not
+// the UI. Cached Gemini pages are used without a request, except by the
+// automatic reloads of watched tabs. chain holds the URLs that redirected
+// to urlStr, if any.
+func (m *Model) fetchIntoTab(tabID int, urlStr string, chain []string, priority scheduler.Priority, reason fetchReason) tea.Cmd {
+	return func() tea.Msg {
+		msg := backgroundFetchMsg{tabID: tabID, url: urlStr, chain: chain, priority: priority, reason: reason}
+		u, err := url.Parse(urlStr)
+		if err != nil {
+			msg.err = fmt.Errorf("invalid URL: %w", err)
+			return msg
+		}
+		msg.protocol = u.Scheme
+
+		if u.Scheme == "gemini" && reason != fetchWatch && m.pageCache != nil && m.config.Get().Performance.EnableCache {
+			if cached, found := m.pageCache.Get(urlStr); found {
+				msg.resp = cached
+				return msg
+			}
+		}
+
+		if reason.automated() && !m.robots.Allowed(urlStr) {
+			msg.err = errDisallowed
+			return msg
+		}
+
+		release := m.scheduler.Acquire(urlutil.Host(urlStr), priority)
+		defer release()
+
+		start := time.Now()
+		switch u.Scheme {
+		case "gemini":
+			msg.resp, msg.err = m.geminiFetcher(tabID).Fetch(urlStr)
+		case "gopher":
+			msg.resp, msg.err = m.gopherFetcher(tabID).Fetch(urlStr)
+		default:
+			msg.err = fmt.Errorf("%s links cannot be opened in a background tab", u.Scheme)
+			return msg
+		}
+		m.recordLatency(urlStr, time.Since(start), msg.err)
+		m.chargeTransfer(priority, msg.resp)
+		return msg
+	}
+}
+
+// chargeTransfer counts a response received at priority against the
+// bandwidth budget of background requests
+func (m *Model) chargeTransfer(priority scheduler.Priority, resp *types.Response) {
+	if resp != nil {
+		m.scheduler.Transferred(priority, len(resp.Body))
+	}
+}
+
+// handleBackgroundFetch stores a background fetch result in its tab
+func (m *Model) handleBackgroundFetch(msg backgroundFetchMsg) tea.Cmd {
+	idx := m.tabBar.IndexOf(msg.tabID)
+	if idx < 0 {
+		// The tab was closed while loading
+		if msg.reason == fetchOpen {
+			m.finishBackgroundFetch(false)
+		}
+		return nil
+	}
+
+	doc, err := m.backgroundDocument(msg)
+	if doc == nil && err == nil {
+		// Redirect: fetch the target into the same tab
+		var cmd tea.Cmd
+		if cmd, err = m.followBackgroundRedirect(idx, msg); err == nil {
+			return cmd
+		}
+	}
+
+	// Capsules that ask not to be fetched automatically are not watched
+	if errors.Is(err, errDisallowed) {
+		m.tabBar.SetTabStatus(idx, false, false)
+		m.tabBar.SetWatch(idx, 0)
+		m.statusBar.SetMessage("Stopped watching " + msg.url + ": its robots.txt asks automated clients to stay away")
+		return nil
+	}
+
+	if err != nil {
+		m.tabBar.SetTabStatus(idx, false, true)
+		m.statusBar.SetError(fmt.Sprintf("Failed to load %s: %v", msg.url, err))
+		if msg.reason == fetchOpen {
+			m.finishBackgroundFetch(true)
+		}
+		return nil
+	}
+
+	if msg.reason == fetchWatch {
+		m.applyWatchReload(idx, doc)
+		return nil
+	}
+
+	// Keep the scroll position of a reloaded tab
+	scroll := m.tabBar.GetTabs()[idx].Scroll
+	m.tabBar.UpdateTab(idx, doc.URL, pageTitle(doc), doc, scroll)
+	m.tabBar.SetTabStatus(idx, false, false)
+	if idx == m.tabBar.GetActiveIndex() {
+		// The user is looking at the tab
+		m.loadTabState()
+	}
+	if msg.reason == fetchRestore {
+		m.statusBar.SetMessage("Reloaded " + doc.URL)
+	} else {
+		m.finishBackgroundFetch(false)
+	}
+	m.enforceTabBudget()
+	return nil
+}
+
+// followBackgroundRedirect fetches the target of a redirect into the tab
+// at idx, checking it as navigate does: the redirect limit, loops, and
+// consent before leaving for another protocol or host. A tab waiting for
+// consent stops loading until it is given.
+func (m *Model) followBackgroundRedirect(idx int, msg backgroundFetchMsg) (tea.Cmd, error) {
+	if len(msg.chain) >= m.redirectLimit {
+		return nil, fmt.Errorf("too many redirects (limit: %d)", m.redirectLimit)
+	}
+	chain := append(slices.Clone(msg.chain), msg.url)
+	target := resolveRedirect(msg.url, msg.resp.Meta)
+	if slices.Contains(chain, target) {
+		return nil, fmt.Errorf("redirect loop: %s redirects back to %s", msg.url, target)
+	}
+
+	if crossing := redirectCrossing(msg.url, msg.resp.Meta); crossing != "" {
+		m.tabBar.SetTabStatus(idx, false, false)
+		if msg.reason == fetchOpen {
+			m.finishBackgroundFetch(false)
+		}
+		m.statusBar.SetMessage("Redirect to " + crossing + ": " + msg.resp.Meta)
+		m.confirm("Follow redirect?",
+			fmt.Sprintf("%s, in a background tab, redirects to %s:\n%s", msg.url, crossing, msg.resp.Meta),
+			"Follow", backgroundRedirectConfirmedMsg{tabID: msg.tabID, url: target, chain: chain, priority: msg.priority})
+		return nil, nil
+	}
+	return m.fetchIntoTab(msg.tabID, target, chain, msg.priority, msg.reason), nil
+}
+
+// followConfirmedRedirect fetches the target of a redirect the user
+// agreed to follow into its tab, if still open. It loads as a reload, the
+// tab having been counted when the redirect was held back.
+func (m *Model) followConfirmedRedirect(msg backgroundRedirectConfirmedMsg) tea.Cmd {
+	idx := m.tabBar.IndexOf(msg.tabID)
+	if idx < 0 {
+		return nil
+	}
+	m.tabBar.SetTabStatus(idx, true, false)
+	return m.fetchIntoTab(msg.tabID, msg.url, msg.chain, msg.priority, fetchRestore)
+}
+
+// backgroundDocument parses a background fetch result. It returns a nil
+// document and nil error for a redirect that should be followed.
+func (m *Model) backgroundDocument(msg backgroundFetchMsg) (*types.Document, error) {
+	if msg.err != nil {
+		return nil, msg.err
+	}
+
+	if msg.protocol == "gopher" {
+		doc, err := m.newGopherParser(msg.resp.URL).Parse(msg.resp)
+		if err == nil && gopher.IsBinaryType(doc.ItemType) {
+			return nil, fmt.Errorf("%s is a file; open it in a tab to download it", msg.url)
+		}
+		return doc, err
+	}
+
+	switch {
+	case gemini.IsRedirectStatus(msg.resp.Status):
+		if msg.resp.Meta == "" {
+			return nil, fmt.Errorf("redirect URL is empty")
+		}
+		return nil, nil
+	case !gemini.IsSuccessStatus(msg.resp.Status):
+		return nil, fmt.Errorf("server responded %d %s", msg.resp.Status, msg.resp.Meta)
+	}
+
+	if mimeType := gemini.GetMIMEType(msg.resp); renderer.IsImageMIME(mimeType) {
+		return nil, fmt.Errorf("%s is not preloaded; press R in the tab to load it", mimeType)
+	}
+	return gemini.NewParser(msg.resp.URL).Parse(msg.resp)
+}
+
+// finishBackgroundFetch counts a finished background fetch and reports
+// progress in the status bar
+func (m *Model) finishBackgroundFetch(failed bool) {
+	m.bgDone++
+	if failed {
+		m.bgFailed++
+	}
+
+	if m.bgDone < m.bgTotal {
+		if !failed {
+			m.statusBar.SetMessage(fmt.Sprintf("Background tabs: %d/%d loaded", m.bgDone, m.bgTotal))
+		}
+		return
+	}
+
+	if m.bgFailed == 0 {
+		m.statusBar.SetMessage(fmt.Sprintf("Loaded %d background tabs", m.bgTotal))
+	} else if !failed {
+		m.statusBar.SetMessage(fmt.Sprintf("Loaded %d background tabs, %d failed", m.bgTotal-m.bgFailed, m.bgFailed))
+	}
+	m.bgTotal, m.bgDone, m.bgFailed = 0, 0, 0
+}
@@ -0,0 +1,138 @@
+package app
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"mime"
+	"net/url"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"starsearch/internal/gempub"
+	"starsearch/internal/types"
+)
+
+// bookScheme addresses the pages of open Gempub books, as
+// gpub://<book>/<path>. The book's own address shows its cover.
+const bookScheme = "gpub"
+
+// openBook is a Gempub book unpacked for reading
+type openBook struct {
+	*gempub.Book
+	source string // URL the book was downloaded from
+}
+
+// bookID names the book from source in gpub:// addresses
+func bookID(source string) string {
+	sum := sha256.Sum256([]byte(source))
+	return hex.EncodeToString(sum[:6])
+}
+
+// openGempub unpacks a downloaded book to a temporary directory and shows
+// its cover
+func (m *Model) openGempub(resp *types.Response) tea.Cmd {
+	if m.bookDir == "" {
+		dir, err := os.MkdirTemp("", "starsearch-gpub-")
+		if err != nil {
+			m.statusBar.SetError(fmt.Sprintf("Failed to open book: %v", err))
+			return nil
+		}
+		m.bookDir = dir
+	}
+
+	id := bookID(resp.URL)
+	dir := filepath.Join(m.bookDir, id)
+	_ = os.RemoveAll(dir) // A book opened again may have changed
+	book, err := gempub.Open(resp.Body, dir)
+	if err != nil {
+		m.statusBar.SetError(fmt.Sprintf("Failed to open book: %v", err))
+		return nil
+	}
+	m.books[id] = &openBook{Book: book, source: resp.URL}
+	return m.navigate(bookScheme + "://" + id + "/")
+}
+
+// bookPage serves a page of an open book. Opening a chapter records it as
+// the reader's place in the book.
+func (m *Model) bookPage(urlStr string, fetchID int) tea.Cmd {
+	fail := func(err error) tea.Cmd {
+		return func() tea.Msg {
+			return fetchCompleteMsg{err: err, protocol: "gemini", url: urlStr, fetchID: fetchID}
+		}
+	}
+
+	u, err := url.Parse(urlStr)
+	if err != nil {
+		return fail(fmt.Errorf("invalid book address: %w", err))
+	}
+	book, ok := m.books[u.Host]
+	if !ok {
+		return fail(fmt.Errorf("this book is no longer open; open the .gpub file again"))
+	}
+
+	name := strings.TrimPrefix(path.Clean("/"+u.Path), "/")
+	resp := &types.Response{Status: 20, Meta: "text/gemini; charset=utf-8", URL: urlStr}
+	if name == "" {
+		resp.Body = m.bookCover(book)
+	} else {
+		body, err := book.Read(name)
+		if err != nil {
+			return fail(fmt.Errorf("%s is not in this book", name))
+		}
+		resp.Body = body
+		if mimeType := mime.TypeByExtension(path.Ext(name)); mimeType != "" && !strings.HasSuffix(name, ".gmi") {
+			resp.Meta = mimeType
+		} else if name != book.Index() {
+			m.bookshelf.SetChapter(book.source, book.Title(), name)
+		}
+	}
+
+	return func() tea.Msg {
+		return fetchCompleteMsg{resp: resp, protocol: "gemini", url: urlStr, fetchID: fetchID}
+	}
+}
+
+// bookCover renders the first page of a book: its details from the
+// metadata, the table of contents and where the reader left off
+func (m *Model) bookCover(book *openBook) []byte {
+	var b strings.Builder
+	title := book.Title()
+	if title == "" {
+		title = path.Base(book.source)
+	}
+	fmt.Fprintf(&b, "# %s\n\n", title)
+
+	for _, field := range []struct{ key, label string }{
+		{"author", "By"},
+		{"published", "Published"},
+		{"publishdate", "Published"},
+		{"language", "Language"},
+		{"license", "License"},
+	} {
+		if value := book.Metadata[field.key]; value != "" {
+			fmt.Fprintf(&b, "%s %s\n", field.label, value)
+		}
+	}
+	if description := book.Metadata["description"]; description != "" {
+		fmt.Fprintf(&b, "\n> %s\n", description)
+	}
+
+	b.WriteString("\n")
+	if progress, ok := m.bookshelf.Progress(book.source); ok && progress.Chapter != "" {
+		fmt.Fprintf(&b, "=> %s Continue reading (%s)\n", (&url.URL{Path: progress.Chapter}).String(), progress.Chapter)
+	}
+	fmt.Fprintf(&b, "=> %s Contents\n", (&url.URL{Path: book.Index()}).String())
+	fmt.Fprintf(&b, "=> %s Downloaded from %s\n", book.source, book.source)
+	return []byte(b.String())
+}
+
+// closeBooks removes the unpacked books
+func (m *Model) closeBooks() {
+	if m.bookDir != "" {
+		_ = os.RemoveAll(m.bookDir)
+	}
+}
@@ -0,0 +1,137 @@
+package app
+
+import (
+	"fmt"
+	"net/url"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"starsearch/internal/gemini"
+	"starsearch/internal/scheduler"
+	"starsearch/internal/types"
+	"starsearch/internal/ui"
+	"starsearch/internal/urlutil"
+)
+
+// bookmarkIndexMsg carries a fetched bookmarked page to be indexed
+type bookmarkIndexMsg struct {
+	bookmark  string // URL of the bookmark the page is indexed under
+	url       string // URL fetched, after any redirects
+	resp      *types.Response
+	err       error
+	redirects int
+}
+
+// showBookmarks opens the bookmarks modal. With index_bookmarks on, its
+// filter matches the text of the pages too, and pages not indexed yet are
+// fetched.
+func (m *Model) showBookmarks() tea.Cmd {
+	indexed := m.config.Get().General.IndexBookmarks
+	if indexed {
+		m.bookmarksModal.SetContent(m.bookmarkIndex)
+	} else {
+		m.bookmarksModal.SetContent(nil)
+	}
+	m.bookmarksModal.Show(m.bookmarks.GetAll())
+	ui.OpenModal(m.modals, m.bookmarksModal)
+
+	if !indexed {
+		return nil
+	}
+	return m.indexBookmarks()
+}
+
+// indexBookmarks fetches the pages of bookmarks not indexed yet. Pages are
+// fetched at background priority and only where robots.txt allows
+// automated clients.
+func (m *Model) indexBookmarks() tea.Cmd {
+	var cmds []tea.Cmd
+	for _, bookmark := range m.bookmarks.GetAll() {
+		if m.bookmarkIndex.Has(bookmark.URL) || m.indexing[bookmark.URL] {
+			continue
+		}
+		m.indexing[bookmark.URL] = true
+		cmds = append(cmds, m.fetchForIndex(bookmark.URL, bookmark.URL, 0))
+	}
+	return tea.Batch(cmds...)
+}
+
+// fetchForIndex fetches urlStr, the page of bookmark or a page it
+// redirected to, once the scheduler grants a slot
+func (m *Model) fetchForIndex(bookmark, urlStr string, redirects int) tea.Cmd {
+	return func() tea.Msg {
+		msg := bookmarkIndexMsg{bookmark: bookmark, url: urlStr, redirects: redirects}
+		u, err := url.Parse(urlStr)
+		if err != nil {
+			msg.err = fmt.Errorf("invalid URL: %w", err)
+			return msg
+		}
+		if u.Scheme != "gemini" && u.Scheme != "gopher" {
+			msg.err = fmt.Errorf("%s pages are not indexed", u.Scheme)
+			return msg
+		}
+		if !m.robots.Allowed(urlStr) {
+			msg.err = errDisallowed
+			return msg
+		}
+
+		release := m.scheduler.Acquire(urlutil.Host(urlStr), scheduler.Background)
+		defer release()
+		if u.Scheme == "gopher" {
+			msg.resp, msg.err = m.gopherClient.Fetch(urlStr)
+		} else {
+			msg.resp, msg.err = m.client.Fetch(urlStr)
+		}
+		m.chargeTransfer(scheduler.Background, msg.resp)
+		return msg
+	}
+}
+
+// handleBookmarkIndex records the text of a fetched bookmarked page
+func (m *Model) handleBookmarkIndex(msg bookmarkIndexMsg) tea.Cmd {
+	if msg.err == nil && gemini.IsRedirectStatus(msg.resp.Status) && msg.redirects < m.redirectLimit {
+		base, err := url.Parse(msg.url)
+		target, targetErr := url.Parse(msg.resp.Meta)
+		if err == nil && targetErr == nil {
+			return m.fetchForIndex(msg.bookmark, base.ResolveReference(target).String(), msg.redirects+1)
+		}
+	}
+	delete(m.indexing, msg.bookmark)
+
+	if msg.err != nil {
+		// Pages that cannot be fetched are indexed as empty rather than
+		// tried again every time the bookmarks are opened
+		m.bookmarkIndex.Set(msg.bookmark, "")
+		return nil
+	}
+
+	var doc *types.Document
+	var err error
+	if strings.HasPrefix(msg.url, "gopher://") {
+		doc, err = m.newGopherParser(msg.url).Parse(msg.resp)
+	} else {
+		doc, err = gemini.NewParser(msg.url).Parse(msg.resp)
+	}
+	if err != nil {
+		m.bookmarkIndex.Set(msg.bookmark, "")
+		return nil
+	}
+	m.indexDocument(msg.bookmark, doc)
+	return nil
+}
+
+// indexDocument records the text of doc as the page of bookmark and
+// updates the bookmarks filter if it is open
+func (m *Model) indexDocument(bookmark string, doc *types.Document) {
+	lines := make([]string, 0, len(doc.Lines))
+	for _, line := range doc.Lines {
+		if line.Text != "" {
+			lines = append(lines, line.Text)
+		}
+	}
+	m.bookmarkIndex.Set(bookmark, strings.Join(lines, "\n"))
+
+	if m.bookmarksModal.IsVisible() {
+		m.bookmarksModal.Refresh(m.bookmarks.GetAll())
+	}
+}
@@ -0,0 +1,35 @@
+package app
+
+import (
+	"fmt"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"starsearch/internal/ui"
+)
+
+// openCacheBrowser shows the page cache's entries
+func (m *Model) openCacheBrowser() {
+	if m.pageCache == nil {
+		m.statusBar.SetMessage("The page cache is disabled (enable_cache in config.toml)")
+		return
+	}
+	m.cacheModal.Show(m.pageCache.Entries(), m.pageCache.GetSize(), m.pageCache.MaxSize())
+	ui.OpenModal(m.modals, m.cacheModal)
+}
+
+// handleCacheAction carries out an action chosen in the cache browser
+func (m *Model) handleCacheAction(msg tea.Msg) {
+	if m.pageCache == nil {
+		return
+	}
+	switch msg := msg.(type) {
+	case ui.CacheInvalidateMsg:
+		m.pageCache.Invalidate(msg.URL)
+		m.statusBar.SetMessage("Removed from cache: " + msg.URL)
+	case ui.CacheClearMsg:
+		count := m.pageCache.GetEntryCount()
+		m.pageCache.Clear()
+		m.statusBar.SetMessage(fmt.Sprintf("Cache cleared (%d entries)", count))
+	}
+	m.cacheModal.Refresh(m.pageCache.Entries(), m.pageCache.GetSize(), m.pageCache.MaxSize())
+}
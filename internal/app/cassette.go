@@ -0,0 +1,28 @@
+package app
+
+import (
+	"starsearch/internal/cassette"
+)
+
+// RecordSession saves every Gemini and Gopher request and response to a
+// cassette file at path. The page cache is turned off so that every page
+// shown is recorded.
+func (m *Model) RecordSession(path string) {
+	recorder := cassette.NewRecorder(path)
+	m.client = recorder.Wrap(m.client)
+	m.gopherClient = recorder.Wrap(m.gopherClient)
+	m.pageCache = nil
+}
+
+// ReplaySession serves every Gemini and Gopher request from the cassette
+// file at path instead of the network
+func (m *Model) ReplaySession(path string) error {
+	player, err := cassette.Load(path)
+	if err != nil {
+		return err
+	}
+	m.client = player
+	m.gopherClient = player
+	m.pageCache = nil
+	return nil
+}
@@ -0,0 +1,122 @@
+package app
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"starsearch/internal/gemini"
+	"starsearch/internal/scheduler"
+	"starsearch/internal/types"
+	"starsearch/internal/ui"
+)
+
+// certForgetMsg forgets the certificate trusted for a host, once confirmed
+type certForgetMsg struct{ host string }
+
+// certVerifiedMsg reports how a host's certificate compared to the trusted
+// one when it was checked again
+type certVerifiedMsg struct {
+	host string
+	err  error
+}
+
+// certExpiries lists the trusted certificates, soonest expiry first.
+// Certificates without a known expiry come last.
+func (m *Model) certExpiries() []types.CertificateInfo {
+	hosts := m.tofuStore.ListHosts()
+	certs := make([]types.CertificateInfo, 0, len(hosts))
+	for _, host := range hosts {
+		info, ok := m.tofuStore.GetCertInfo(host)
+		if !ok {
+			continue
+		}
+		certs = append(certs, certificateInfo(host, info, true))
+	}
+	sort.Slice(certs, func(i, j int) bool {
+		a, b := certs[i].NotAfter, certs[j].NotAfter
+		if a.IsZero() != b.IsZero() {
+			return b.IsZero()
+		}
+		if !a.Equal(b) {
+			return a.Before(b)
+		}
+		return certs[i].Host < certs[j].Host
+	})
+	return certs
+}
+
+// certificateInfo describes a certificate the TOFU store holds for host
+func certificateInfo(host string, info *gemini.CertificateInfo, trusted bool) types.CertificateInfo {
+	return types.CertificateInfo{
+		Host:        host,
+		Fingerprint: info.Fingerprint,
+		Subject:     info.Subject,
+		NotBefore:   info.NotBefore,
+		NotAfter:    info.NotAfter,
+		Trusted:     trusted,
+		FirstSeen:   info.FirstSeen,
+		LastSeen:    info.LastSeen,
+	}
+}
+
+// openCertExpiry shows the trusted certificates by expiry date
+func (m *Model) openCertExpiry() {
+	m.certExpiryModal.Show(m.certExpiries())
+	ui.OpenModal(m.modals, m.certExpiryModal)
+}
+
+// confirmCertForget asks before forgetting the certificate trusted for host
+func (m *Model) confirmCertForget(host string) {
+	m.confirm("Forget the certificate of "+host+"?",
+		"The next certificate "+host+" presents is trusted on first use, without comparing it to this one.",
+		"Forget", certForgetMsg{host: host})
+}
+
+// forgetCert removes the certificate trusted for host
+func (m *Model) forgetCert(host string) tea.Cmd {
+	if err := m.tofuStore.RemoveCert(host); err != nil {
+		m.statusBar.SetError(fmt.Sprintf("Failed to forget the certificate of %s: %v", host, err))
+		return nil
+	}
+	m.refreshCertificates()
+	return m.notify("Forgot the certificate of " + host)
+}
+
+// verifyCert connects to host again so the certificate it presents now is
+// checked against the trusted one
+func (m *Model) verifyCert(host string) tea.Cmd {
+	m.statusBar.SetMessage("Checking the certificate of " + host + "...")
+	return func() tea.Msg {
+		release := m.scheduler.Acquire(host, scheduler.Interactive)
+		defer release()
+		_, err := m.client.Fetch("gemini://" + host + "/")
+		return certVerifiedMsg{host: host, err: err}
+	}
+}
+
+// handleCertVerified reports the check of a host's certificate. A changed
+// certificate can be trusted from here, as when browsing.
+func (m *Model) handleCertVerified(msg certVerifiedMsg) tea.Cmd {
+	var headerErr *gemini.HeaderError
+	switch {
+	case errors.Is(msg.err, gemini.ErrCertificateChanged):
+		m.confirmCertChange(msg.host, "")
+		return nil
+	case errors.Is(msg.err, gemini.ErrCertificateExpired):
+		m.statusBar.SetError(msg.host + " still presents an expired certificate")
+		return nil
+	case msg.err != nil && !errors.As(msg.err, &headerErr):
+		// A malformed reply still came over a verified connection
+		m.statusBar.SetError(fmt.Sprintf("Could not check %s: %v", msg.host, msg.err))
+		return nil
+	}
+
+	m.refreshCertificates()
+	info, ok := m.tofuStore.GetCertInfo(msg.host)
+	if !ok {
+		return m.notify(msg.host + " presented a new certificate; it is now trusted")
+	}
+	return m.notify(fmt.Sprintf("%s presents its trusted certificate, valid until %s", msg.host, info.NotAfter.Format("2006-01-02")))
+}
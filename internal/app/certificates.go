@@ -0,0 +1,39 @@
+package app
+
+import (
+	"sort"
+
+	"starsearch/internal/types"
+	"starsearch/internal/ui"
+)
+
+// certificates lists the certificates of the TOFU store by host for the
+// certificate manager. A host whose new certificate was rejected is listed
+// again with that certificate, untrusted, so it can be trusted from there.
+func (m *Model) certificates() []types.CertificateInfo {
+	hosts := m.tofuStore.ListHosts()
+	sort.Strings(hosts)
+	certs := make([]types.CertificateInfo, 0, len(hosts))
+	for _, host := range hosts {
+		if info, ok := m.tofuStore.GetCertInfo(host); ok {
+			certs = append(certs, certificateInfo(host, info, true))
+		}
+		if changed, ok := m.tofuStore.ChangedCert(host); ok {
+			certs = append(certs, certificateInfo(host, changed, false))
+		}
+	}
+	return certs
+}
+
+// openCertificates shows the certificate manager
+func (m *Model) openCertificates() {
+	m.certificateModal.Show(m.certificates())
+	ui.OpenModal(m.modals, m.certificateModal)
+}
+
+// refreshCertificates brings the certificate modals up to date after the
+// TOFU store changed
+func (m *Model) refreshCertificates() {
+	m.certExpiryModal.Refresh(m.certExpiries())
+	m.certificateModal.Refresh(m.certificates())
+}
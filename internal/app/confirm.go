@@ -0,0 +1,157 @@
+package app
+
+import (
+	"fmt"
+	"net/url"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"starsearch/internal/gemini"
+	"starsearch/internal/scheduler"
+	"starsearch/internal/ui"
+)
+
+// Actions held back until the user confirms them in the confirmation dialog
+type (
+	quitConfirmedMsg  struct{}
+	bookmarkRemoveMsg struct{ url string }
+	historyRemoveMsg  struct {
+		urls  []string
+		label string
+	}
+	certAcceptMsg struct {
+		host string
+		url  string // Page to load again once the certificate is trusted, if any
+	}
+	redirectConfirmedMsg           struct{ url string }
+	backgroundRedirectConfirmedMsg struct {
+		tabID    int
+		url      string
+		chain    []string
+		priority scheduler.Priority
+	}
+)
+
+// confirm asks the user before doing something destructive or unusual;
+// msg is handled if they agree
+func (m *Model) confirm(title, detail, yes string, msg tea.Msg) {
+	m.confirmModal.Show(title, detail, yes, msg)
+	ui.OpenModal(m.modals, m.confirmModal)
+}
+
+// quit ends the program. The browsing state is saved by Shutdown once
+// the program has stopped, so no save can race the exit.
+func (m *Model) quit() tea.Cmd {
+	m.quitting = true
+	return tea.Quit
+}
+
+// requestQuit quits, first asking when downloads would be cut short
+func (m *Model) requestQuit() tea.Cmd {
+	if m.activeDownloads == 0 {
+		return m.quit()
+	}
+	m.confirm("Quit starsearch?",
+		fmt.Sprintf("%d download(s) still in progress will be lost.", m.activeDownloads),
+		"Quit", quitConfirmedMsg{})
+	return nil
+}
+
+// confirmCertChange asks whether to trust the certificate host presented
+// in place of the one trusted on first use, then load urlStr again
+func (m *Model) confirmCertChange(host, urlStr string) {
+	detail := fmt.Sprintf("%s presented a different certificate from the one trusted on first use. This happens when a capsule renews its certificate, but can also mean someone is intercepting the connection.", host)
+	if trusted, ok := m.tofuStore.GetCertInfo(host); ok {
+		detail += "\n\nTrusted: " + gemini.FormatFingerprint(trusted.Fingerprint)
+	}
+	if changed, ok := m.tofuStore.ChangedCert(host); ok {
+		detail += "\nNew:     " + gemini.FormatFingerprint(changed.Fingerprint)
+	}
+	m.statusBar.SetError("Certificate changed for " + host)
+	m.confirm("Trust the new certificate?", detail, "Trust", certAcceptMsg{host: host, url: urlStr})
+}
+
+// redirectCrossing says what a redirect from one URL to another leaves
+// for, "another protocol" or "another host", or "" if neither. Relative
+// targets keep both.
+func redirectCrossing(from, to string) string {
+	fromURL, err := url.Parse(from)
+	if err != nil {
+		return ""
+	}
+	toURL, err := url.Parse(to)
+	if err != nil {
+		return ""
+	}
+	switch {
+	case toURL.Scheme != "" && toURL.Scheme != fromURL.Scheme:
+		return "another protocol"
+	case toURL.Host != "" && !strings.EqualFold(toURL.Hostname(), fromURL.Hostname()):
+		return "another host"
+	}
+	return ""
+}
+
+// resolveRedirect returns the absolute URL a redirect from one URL to
+// another leads to
+func resolveRedirect(from, to string) string {
+	fromURL, err := url.Parse(from)
+	if err != nil {
+		return to
+	}
+	toURL, err := url.Parse(to)
+	if err != nil {
+		return to
+	}
+	return fromURL.ResolveReference(toURL).String()
+}
+
+// handleConfirmed carries out an action the user confirmed
+func (m *Model) handleConfirmed(msg tea.Msg) tea.Cmd {
+	switch msg := msg.(type) {
+	case quitConfirmedMsg:
+		return m.quit()
+
+	case bookmarkRemoveMsg:
+		if err := m.bookmarks.Remove(msg.url); err != nil {
+			m.statusBar.SetError("Failed to delete bookmark")
+			return nil
+		}
+		m.bookmarkIndex.Remove(msg.url)
+		m.bookmarksModal.Refresh(m.bookmarks.GetAll())
+		return m.notify("Bookmark deleted")
+
+	case historyRemoveMsg:
+		removed := m.history.Remove(msg.urls...)
+		m.historyModal.Refresh(m.history.GetAll())
+		return m.notify(fmt.Sprintf("Deleted %d visit(s) to %s from history", removed, msg.label))
+
+	case certAcceptMsg:
+		if err := m.tofuStore.AcceptChange(msg.host); err != nil {
+			m.statusBar.SetError(fmt.Sprintf("Failed to trust certificate: %v", err))
+			return nil
+		}
+		m.statusBar.SetMessage("Trusted the new certificate for " + msg.host)
+		if msg.url == "" {
+			m.refreshCertificates()
+			return nil
+		}
+		return m.navigate(msg.url)
+
+	case redirectConfirmedMsg:
+		return m.navigate(msg.url)
+
+	case backgroundRedirectConfirmedMsg:
+		return m.followConfirmedRedirect(msg)
+
+	case sessionConfirmedMsg:
+		return m.startSession(msg.session)
+
+	case privacyPurgeMsg:
+		return m.purgeHost(msg.host)
+
+	case certForgetMsg:
+		return m.forgetCert(msg.host)
+	}
+	return nil
+}
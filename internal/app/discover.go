@@ -0,0 +1,67 @@
+package app
+
+import (
+	"fmt"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"starsearch/internal/types"
+)
+
+// discoverURL is the internal page new tabs open on
+const discoverURL = "about:discover"
+
+// aggregators are the feed aggregators listed on about:discover
+var aggregators = []struct {
+	name, url, about string
+}{
+	{"CAPCOM", "gemini://gemini.circumlunar.space/capcom/", "a random selection of posts from Gemini feeds"},
+	{"Antenna", "gemini://warmedal.se/~antenna/", "the latest posts submitted by gemlog authors"},
+}
+
+// discoverPage renders about:discover: the random capsule endpoint if one
+// is configured, the aggregators and the search engines
+func discoverPage(randomURL string, engines []types.SearchEngine) []byte {
+	var b strings.Builder
+	b.WriteString("# Discover Geminispace\n\n")
+	b.WriteString("Not sure where to start? Try one of these.\n\n")
+
+	b.WriteString("## Random capsule\n\n")
+	if randomURL != "" {
+		fmt.Fprintf(&b, "=> %s Take me somewhere random\n\n", randomURL)
+	} else {
+		b.WriteString("Set random_capsule_url under [general] in config.toml to an endpoint that redirects to a random capsule, and it will be linked here and on g ?.\n\n")
+	}
+
+	b.WriteString("## Aggregators\n\n")
+	for _, aggregator := range aggregators {
+		fmt.Fprintf(&b, "=> %s %s: %s\n", aggregator.url, aggregator.name, aggregator.about)
+	}
+	b.WriteString("\n")
+
+	b.WriteString("## Search\n\n")
+	for _, engine := range engines {
+		fmt.Fprintf(&b, "=> %s Search Geminispace with %s\n", engine.URL, engine.Name)
+	}
+	b.WriteString("\nYou can also type a search straight into the address bar.\n")
+
+	return []byte(b.String())
+}
+
+// discover opens about:discover, or the random capsule endpoint if random
+// is set, leaving link number mode
+func (m *Model) discover(random bool) tea.Cmd {
+	m.linkNumbers = false
+	m.linkInput = ""
+
+	if !random {
+		return m.navigate(discoverURL)
+	}
+	randomURL := m.config.Get().General.RandomCapsuleURL
+	if randomURL == "" {
+		m.statusBar.SetMessage("No random capsule endpoint: set random_capsule_url in config.toml")
+		return nil
+	}
+	m.statusBar.SetMessage("Finding a random capsule...")
+	return m.navigate(randomURL)
+}
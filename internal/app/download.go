@@ -0,0 +1,307 @@
+package app
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/url"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"starsearch/internal/archive"
+	"starsearch/internal/gemini"
+	"starsearch/internal/gopher"
+	"starsearch/internal/scheduler"
+	"starsearch/internal/types"
+	"starsearch/internal/ui"
+)
+
+// downloadCompleteMsg reports the result of saving a link to disk
+type downloadCompleteMsg struct {
+	id   string // Entry in the downloads list, if listed
+	url  string
+	path string
+	size int64
+	err  error
+}
+
+// downloadProgressMsg reports how much of a download has been written
+type downloadProgressMsg struct {
+	id      string
+	written int64
+	events  <-chan tea.Msg // Further progress, then the downloadCompleteMsg
+}
+
+// downloadFetcher is a fetcher that writes a response body out as it
+// arrives instead of holding it in memory, like the Gemini client
+type downloadFetcher interface {
+	FetchTo(ctx context.Context, urlStr string, w io.Writer, progress func(written int64)) (*types.Response, error)
+}
+
+// downloadLink streams urlStr to a file in the download directory without
+// displaying it. The download is listed in the downloads modal, which
+// shows its progress and can cancel it.
+func (m *Model) downloadLink(urlStr string) tea.Cmd {
+	u, err := url.Parse(urlStr)
+	if err != nil {
+		m.statusBar.SetError(fmt.Sprintf("Download failed: invalid URL: %v", err))
+		return nil
+	}
+	download, err := m.downloads.Add(urlStr, downloadFilename(u), 0)
+	if err != nil {
+		m.statusBar.SetError(fmt.Sprintf("Download failed: %v", err))
+		return nil
+	}
+	m.downloads.SetStatus(download.ID, types.Downloading, "")
+	ctx, cancel := context.WithCancel(context.Background())
+	m.downloadCancels[download.ID] = cancel
+	m.activeDownloads++
+	m.refreshDownloads()
+
+	id, dir := download.ID, m.config.GetDownloadDirectory()
+	events := make(chan tea.Msg)
+	// Counted before the goroutine starts, so Shutdown cannot miss it, and
+	// done before the result is sent, which nothing reads after quitting
+	m.writes.Add(1)
+	go func() {
+		msg := m.streamDownload(ctx, id, dir, u, events)
+		cancel()
+		m.writes.Done()
+		events <- msg
+	}()
+	return waitForStream(events)
+}
+
+// streamDownload fetches u into a partial file in dir, offering progress
+// on events, and gives the file its name once complete. A failed or
+// cancelled download leaves nothing behind.
+func (m *Model) streamDownload(ctx context.Context, id, dir string, u *url.URL, events chan tea.Msg) downloadCompleteMsg {
+	urlStr := u.String()
+	fail := func(err error) downloadCompleteMsg {
+		return downloadCompleteMsg{id: id, url: urlStr, err: err}
+	}
+
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return fail(fmt.Errorf("failed to create download directory: %w", err))
+	}
+	name := downloadFilename(u)
+	part, err := os.CreateTemp(dir, "."+name+".*.part")
+	if err != nil {
+		return fail(fmt.Errorf("failed to save download: %w", err))
+	}
+	defer os.Remove(part.Name()) // Gone by then if the download completed
+	defer part.Close()
+
+	release := m.scheduler.Acquire(u.Hostname(), scheduler.Background)
+	var written int64
+	last := time.Now()
+	resp, err := m.fetchDownload(ctx, u, part, func(n int64) {
+		m.scheduler.Transferred(scheduler.Background, int(n-written))
+		written = n
+		if time.Since(last) < streamInterval {
+			return
+		}
+		last = time.Now()
+		// Progress the interface is too busy to take is dropped rather
+		// than holding up the transfer
+		select {
+		case events <- downloadProgressMsg{id: id, written: n, events: events}:
+		default:
+		}
+	})
+	release()
+	if err == nil && !gemini.IsSuccessStatus(resp.Status) {
+		err = fmt.Errorf("server responded %d %s", resp.Status, resp.Meta)
+	}
+	if err != nil {
+		return fail(err)
+	}
+
+	if err := part.Close(); err != nil {
+		return fail(fmt.Errorf("failed to save download: %w", err))
+	}
+	_ = os.Chmod(part.Name(), 0644)
+	filePath := uniquePath(filepath.Join(dir, name))
+	if err := os.Rename(part.Name(), filePath); err != nil {
+		return fail(fmt.Errorf("failed to save download: %w", err))
+	}
+	return downloadCompleteMsg{id: id, url: urlStr, path: filePath, size: written}
+}
+
+// fetchDownload fetches u, writing the body to w and calling progress with
+// the bytes written so far. Fetchers that hold the whole body, like the
+// Gopher client, have it written once it has arrived.
+func (m *Model) fetchDownload(ctx context.Context, u *url.URL, w io.Writer, progress func(written int64)) (*types.Response, error) {
+	var client fetcher
+	switch u.Scheme {
+	case "gemini":
+		client = m.client
+	case "gopher":
+		client = m.gopherClient
+	default:
+		return nil, fmt.Errorf("cannot download %s links", u.Scheme)
+	}
+	if streamer, ok := client.(downloadFetcher); ok {
+		return streamer.FetchTo(ctx, u.String(), w, progress)
+	}
+
+	var resp *types.Response
+	var err error
+	if cf, ok := client.(contextFetcher); ok {
+		resp, err = cf.FetchContext(ctx, u.String(), func(partial *types.Response) {
+			progress(int64(len(partial.Body)))
+		})
+	} else {
+		resp, err = client.Fetch(u.String())
+	}
+	if err == nil && ctx.Err() != nil {
+		err = ctx.Err()
+	}
+	if err != nil || !gemini.IsSuccessStatus(resp.Status) {
+		return resp, err
+	}
+	if _, err := w.Write(resp.Body); err != nil {
+		return nil, fmt.Errorf("failed to save download: %w", err)
+	}
+	progress(int64(len(resp.Body)))
+	return resp, nil
+}
+
+// saveFetched saves a body that was already fetched, such as a Gopher
+// binary item the user opened, in the download directory
+func (m *Model) saveFetched(urlStr string, body []byte) tea.Cmd {
+	u, err := url.Parse(urlStr)
+	if err != nil {
+		m.statusBar.SetError(fmt.Sprintf("Download failed: invalid URL: %v", err))
+		return nil
+	}
+	// The body is already here, so the save is listed if it can be but
+	// never waits for other downloads
+	var id string
+	if download, err := m.downloads.Add(urlStr, downloadFilename(u), int64(len(body))); err == nil {
+		id = download.ID
+	}
+	m.activeDownloads++
+	m.refreshDownloads()
+
+	dir := m.config.GetDownloadDirectory()
+	m.writes.Add(1)
+	return func() tea.Msg {
+		defer m.writes.Done()
+		msg := m.writeDownload(dir, u, body)
+		msg.id = id
+		return msg
+	}
+}
+
+// writeDownload writes body to a new file in dir named after u
+func (m *Model) writeDownload(dir string, u *url.URL, body []byte) downloadCompleteMsg {
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return downloadCompleteMsg{url: u.String(), err: fmt.Errorf("failed to create download directory: %w", err)}
+	}
+	filePath := uniquePath(filepath.Join(dir, downloadFilename(u)))
+	if err := os.WriteFile(filePath, body, 0644); err != nil {
+		return downloadCompleteMsg{url: u.String(), err: fmt.Errorf("failed to save download: %w", err)}
+	}
+	return downloadCompleteMsg{url: u.String(), path: filePath, size: int64(len(body))}
+}
+
+// handleDownloadComplete records how a download ended in the downloads
+// list and reports it
+func (m *Model) handleDownloadComplete(msg downloadCompleteMsg) tea.Cmd {
+	m.activeDownloads--
+	cancelled := errors.Is(msg.err, context.Canceled)
+	if msg.id != "" {
+		delete(m.downloadCancels, msg.id)
+		switch {
+		case cancelled:
+			m.downloads.SetStatus(msg.id, types.DownloadCancelled, "")
+		case msg.err != nil:
+			m.downloads.SetStatus(msg.id, types.DownloadFailed, msg.err.Error())
+		default:
+			m.downloads.Complete(msg.id, filepath.Base(msg.path), msg.size)
+		}
+		m.refreshDownloads()
+	}
+
+	switch {
+	case cancelled:
+		return m.notify("Download cancelled")
+	case msg.err != nil:
+		m.statusBar.SetError(fmt.Sprintf("Download failed: %v", msg.err))
+		return nil
+	case archive.IsArchive(msg.path):
+		m.lastArchive = msg.path
+		return m.notify("Saved " + filepath.Base(msg.path) + " · Z lists its contents")
+	}
+	return m.notify("Saved " + filepath.Base(msg.path))
+}
+
+// cancelDownload abandons a download in progress. Its partial file is
+// removed once the transfer has stopped.
+func (m *Model) cancelDownload(id string) {
+	if cancel, ok := m.downloadCancels[id]; ok {
+		cancel()
+		m.statusBar.SetMessage("Cancelling download...")
+	}
+}
+
+// retryDownload starts a failed or cancelled download again in place of
+// its entry in the list
+func (m *Model) retryDownload(id string) tea.Cmd {
+	download := m.downloads.Get(id)
+	if download == nil {
+		return nil
+	}
+	urlStr := download.URL
+	m.downloads.Remove(id)
+	m.statusBar.SetMessage("Downloading " + urlStr + "...")
+	return m.downloadLink(urlStr)
+}
+
+// openDownloads shows the downloads in progress and finished
+func (m *Model) openDownloads() {
+	m.downloadModal.Show(m.downloads.GetAll())
+	ui.OpenModal(m.modals, m.downloadModal)
+}
+
+// refreshDownloads brings the downloads modal up to date
+func (m *Model) refreshDownloads() {
+	m.downloadModal.Refresh(m.downloads.GetAll())
+}
+
+// downloadFilename picks a local file name for u from the last path
+// segment, or for Gopher from the selector without the item type
+func downloadFilename(u *url.URL) string {
+	name := path.Base(u.Path)
+	if u.Scheme == "gopher" {
+		if item, err := gopher.ParseURL(u.String()); err == nil {
+			name = path.Base(item.Selector)
+		}
+	}
+	if name == "." || name == "/" || name == "" {
+		name = u.Hostname() + ".gmi"
+	}
+	return strings.ReplaceAll(name, string(filepath.Separator), "_")
+}
+
+// uniquePath returns p, or p with a numeric suffix if a file already exists
+func uniquePath(p string) string {
+	if _, err := os.Stat(p); os.IsNotExist(err) {
+		return p
+	}
+
+	ext := filepath.Ext(p)
+	base := strings.TrimSuffix(p, ext)
+	for i := 1; ; i++ {
+		candidate := fmt.Sprintf("%s (%d)%s", base, i, ext)
+		if _, err := os.Stat(candidate); os.IsNotExist(err) {
+			return candidate
+		}
+	}
+}
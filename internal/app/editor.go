@@ -0,0 +1,110 @@
+package app
+
+import (
+	"fmt"
+	"net/url"
+	"os"
+	"os/exec"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"starsearch/internal/ui"
+)
+
+// defaultEditor is run when neither $VISUAL nor $EDITOR is set
+const defaultEditor = "vi"
+
+// maxRequestLength is the longest request a Gemini server must accept, the
+// URL including its query
+const maxRequestLength = 1024
+
+// editorClosedMsg reports that the editor writing an answer exited
+type editorClosedMsg struct {
+	path string // File the answer was written in
+	err  error
+}
+
+// editInput opens text, the answer typed so far, in $VISUAL or $EDITOR
+// while the browser waits. The saved file is submitted when the editor
+// exits.
+func (m *Model) editInput(text string) tea.Cmd {
+	return m.openEditor(text, func(path string, err error) tea.Msg {
+		return editorClosedMsg{path: path, err: err}
+	})
+}
+
+// openEditor writes text to a temporary file and opens it in $VISUAL or
+// $EDITOR while the browser waits. done builds the message reporting the
+// file once the editor exits; removing the file is left to its handler.
+func (m *Model) openEditor(text string, done func(path string, err error) tea.Msg) tea.Cmd {
+	file, err := os.CreateTemp("", "starsearch-*.txt")
+	if err != nil {
+		m.statusBar.SetError(fmt.Sprintf("Failed to open editor: %v", err))
+		return nil
+	}
+	_, err = file.WriteString(text)
+	if closeErr := file.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		os.Remove(file.Name())
+		m.statusBar.SetError(fmt.Sprintf("Failed to open editor: %v", err))
+		return nil
+	}
+
+	editor := os.Getenv("VISUAL")
+	if editor == "" {
+		editor = os.Getenv("EDITOR")
+	}
+	cmd := editorCommand(editor, file.Name())
+	return tea.ExecProcess(cmd, func(err error) tea.Msg {
+		return done(file.Name(), err)
+	})
+}
+
+// editorCommand builds the command editing path with editor, a command
+// line such as "emacs -nw"
+func editorCommand(editor, path string) *exec.Cmd {
+	args := strings.Fields(editor)
+	if len(args) == 0 {
+		args = []string{defaultEditor}
+	}
+	args = append(args, path)
+	return exec.Command(args[0], args[1:]...)
+}
+
+// handleEditorClosed submits the answer written in the editor. The input
+// prompt stays open when nothing was written or the editor failed, and
+// answers too long to send are left in their file rather than lost.
+func (m *Model) handleEditorClosed(msg editorClosedMsg) tea.Cmd {
+	if msg.err != nil {
+		os.Remove(msg.path)
+		m.statusBar.SetError(fmt.Sprintf("Editor failed: %v", msg.err))
+		return nil
+	}
+	data, err := os.ReadFile(msg.path)
+	if err != nil {
+		os.Remove(msg.path)
+		m.statusBar.SetError(fmt.Sprintf("Failed to read answer: %v", err))
+		return nil
+	}
+
+	// Editors end the file with a newline that isn't part of the answer
+	text := strings.TrimRight(string(data), "\r\n")
+	if text == "" {
+		os.Remove(msg.path)
+		m.statusBar.SetMessage("Nothing written; input not sent")
+		return nil
+	}
+	if !m.inputModal.IsVisible() || m.pendingInputURL == "" {
+		os.Remove(msg.path)
+		return nil
+	}
+	if over := len(m.pendingInputURL+"?"+url.QueryEscape(text)) - maxRequestLength; over > 0 {
+		m.statusBar.SetError(fmt.Sprintf("Answer is %d bytes too long to send; kept in %s", over, msg.path))
+		return nil
+	}
+
+	os.Remove(msg.path)
+	return func() tea.Msg { return ui.InputSubmitMsg{Input: text} }
+}
@@ -0,0 +1,231 @@
+package app
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"mime"
+	"net/url"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"slices"
+	"strings"
+	"unicode"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"starsearch/internal/gemini"
+	"starsearch/internal/scheduler"
+)
+
+// viewerFetchedMsg carries a link fetched to be viewed in another program
+type viewerFetchedMsg struct {
+	url      string
+	mimeType string
+	body     []byte
+	err      error
+}
+
+// viewerClosedMsg reports that the program viewing a page exited
+type viewerClosedMsg struct {
+	name string
+	err  error
+}
+
+// viewCurrentExternally opens the current page in the program its MIME
+// type is handled by
+func (m *Model) viewCurrentExternally() tea.Cmd {
+	if m.currentDoc == nil || len(m.currentDoc.RawBody) == 0 {
+		m.statusBar.SetMessage("Nothing to view externally")
+		return nil
+	}
+	return m.viewExternally(m.currentDoc.URL, m.currentDoc.MIMEType, m.currentDoc.RawBody)
+}
+
+// viewLinkExternally fetches urlStr without displaying it and opens it in
+// the program its MIME type is handled by
+func (m *Model) viewLinkExternally(urlStr string) tea.Cmd {
+	u, err := url.Parse(urlStr)
+	if err != nil {
+		m.statusBar.SetError(fmt.Sprintf("Cannot view externally: invalid URL: %v", err))
+		return nil
+	}
+	m.statusBar.SetMessage("Fetching " + urlStr + " to view externally...")
+	return func() tea.Msg {
+		release := m.scheduler.Acquire(u.Hostname(), scheduler.Interactive)
+		defer release()
+
+		var body bytes.Buffer
+		resp, err := m.fetchDownload(context.Background(), u, &body, func(int64) {})
+		if err == nil && !gemini.IsSuccessStatus(resp.Status) {
+			err = fmt.Errorf("status %d %s", resp.Status, resp.Meta)
+		}
+		if err != nil {
+			return viewerFetchedMsg{url: urlStr, err: err}
+		}
+		return viewerFetchedMsg{url: urlStr, mimeType: resp.Meta, body: body.Bytes()}
+	}
+}
+
+// handleViewerFetched opens a link fetched by viewLinkExternally
+func (m *Model) handleViewerFetched(msg viewerFetchedMsg) tea.Cmd {
+	if msg.err != nil {
+		m.statusBar.SetError(fmt.Sprintf("Cannot view %s externally: %v", msg.url, msg.err))
+		return nil
+	}
+	return m.viewExternally(msg.url, msg.mimeType, msg.body)
+}
+
+// viewExternally writes body to a temporary file named for urlStr, with
+// an extension matching mimeType, and starts the program configured for
+// that type in general.openers on it, or else the desktop's. The files
+// are removed on shutdown, as the program may still have them open until
+// then.
+func (m *Model) viewExternally(urlStr, mimeType string, body []byte) tea.Cmd {
+	mediaType, _, _ := mime.ParseMediaType(mimeType)
+	file, err := m.writeViewerFile(urlStr, mediaType, body)
+	if err != nil {
+		m.statusBar.SetError(fmt.Sprintf("Cannot view externally: %v", err))
+		return nil
+	}
+
+	cmd, err := openerCommand(m.config.Get().General.Openers, mediaType, file)
+	if err == nil {
+		err = cmd.Start()
+	}
+	if err != nil {
+		m.statusBar.SetError(fmt.Sprintf("Cannot view externally: %v", err))
+		return nil
+	}
+
+	name := filepath.Base(file)
+	return tea.Batch(m.notify("Opened "+name+" with "+filepath.Base(cmd.Path)), func() tea.Msg {
+		return viewerClosedMsg{name: name, err: cmd.Wait()}
+	})
+}
+
+// writeViewerFile writes body to a new file in the viewer directory,
+// which is created the first time
+func (m *Model) writeViewerFile(urlStr, mediaType string, body []byte) (string, error) {
+	if m.viewerDir == "" {
+		dir, err := os.MkdirTemp("", "starsearch-view-")
+		if err != nil {
+			return "", fmt.Errorf("failed to create temporary directory: %w", err)
+		}
+		m.viewerDir = dir
+	}
+
+	name := "page"
+	if u, err := url.Parse(urlStr); err == nil {
+		name = downloadFilename(u)
+	}
+	ext, err := viewerExtension(name, mediaType)
+	if err != nil {
+		return "", err
+	}
+	stem := viewerStem(strings.TrimSuffix(name, filepath.Ext(name)))
+
+	f, err := os.CreateTemp(m.viewerDir, stem+"-*"+ext)
+	if err != nil {
+		return "", fmt.Errorf("failed to write temporary file: %w", err)
+	}
+	_, err = f.Write(body)
+	if closeErr := f.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		os.Remove(f.Name())
+		return "", fmt.Errorf("failed to write temporary file: %w", err)
+	}
+	return f.Name(), nil
+}
+
+// removeViewerFiles removes the files written for viewers
+func (m *Model) removeViewerFiles() {
+	if m.viewerDir != "" {
+		os.RemoveAll(m.viewerDir)
+		m.viewerDir = ""
+	}
+}
+
+// viewerExtensions are the extensions of files that can be viewed in
+// another program: documents, images and media, which the desktop opens
+// rather than runs
+var viewerExtensions = map[string]bool{
+	".gmi": true, ".txt": true, ".md": true, ".csv": true, ".json": true, ".xml": true,
+	".html": true, ".htm": true, ".pdf": true, ".epub": true, ".djvu": true, ".ps": true,
+	".odt": true, ".ods": true, ".odp": true, ".rtf": true,
+	".png": true, ".jpg": true, ".jpeg": true, ".gif": true, ".webp": true, ".bmp": true,
+	".tif": true, ".tiff": true, ".svg": true,
+	".mp3": true, ".ogg": true, ".oga": true, ".opus": true, ".flac": true, ".wav": true,
+	".m4a": true, ".mp4": true, ".m4v": true, ".webm": true, ".mkv": true, ".ogv": true,
+}
+
+// viewerExtension picks the extension a viewer expects for a file called
+// name holding mediaType: name's own if it suits the type, else the one
+// registered for the type. Programs picked by the desktop go by it, so
+// only those of viewerExtensions are used: other text is viewed as .txt
+// and anything else, such as a program or script, is refused.
+func viewerExtension(name, mediaType string) (string, error) {
+	ext := strings.ToLower(filepath.Ext(name))
+	switch {
+	case mediaType == "":
+	case mediaType == "text/gemini":
+		ext = ".gmi"
+	default:
+		exts, _ := mime.ExtensionsByType(mediaType)
+		if !slices.Contains(exts, ext) && len(exts) > 0 {
+			ext = exts[0]
+		}
+	}
+
+	switch {
+	case viewerExtensions[ext]:
+		return ext, nil
+	case strings.HasPrefix(mediaType, "text/"):
+		return ".txt", nil
+	case mediaType == "":
+		return "", fmt.Errorf("%s cannot be viewed in another program", name)
+	}
+	return "", fmt.Errorf("%s cannot be viewed in another program", mediaType)
+}
+
+// viewerStem makes the stem of a file name from the server safe to hand
+// to the desktop's opener: anything but letters, digits, '-', '_' and '.'
+// is replaced, so no shell metacharacter such as & or ^ reaches it
+func viewerStem(stem string) string {
+	stem = strings.Map(func(r rune) rune {
+		if unicode.IsLetter(r) || unicode.IsDigit(r) || strings.ContainsRune("-_.", r) {
+			return r
+		}
+		return '_'
+	}, stem)
+	if stem == "" {
+		return "page"
+	}
+	return stem
+}
+
+// openerCommand builds the command opening file, which holds mediaType,
+// from openers: a command for the type, or for "type/*", such as
+// "zathura {file}". The file is appended to a command without {file}.
+// Types without one are opened with the desktop's program.
+func openerCommand(openers map[string]string, mediaType, file string) (*exec.Cmd, error) {
+	template, ok := openers[mediaType]
+	if !ok {
+		major, _, _ := strings.Cut(mediaType, "/")
+		template, ok = openers[major+"/*"]
+	}
+	args := strings.Fields(template)
+	if !ok || len(args) == 0 {
+		return systemOpener(file)
+	}
+
+	if !strings.Contains(template, "{file}") {
+		args = append(args, file)
+	}
+	for i, arg := range args {
+		args[i] = strings.ReplaceAll(arg, "{file}", file)
+	}
+	return exec.Command(args[0], args[1:]...), nil
+}
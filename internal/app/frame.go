@@ -0,0 +1,47 @@
+package app
+
+import (
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"starsearch/internal/ui"
+)
+
+// backgroundFrameInterval is the least time between redraws caused only by
+// background activity, such as tabs loading or downloads finishing. Keys,
+// the mouse and page loads always redraw straight away, so a burst of
+// background results cannot make typing lag on a slow terminal or SSH link.
+const backgroundFrameInterval = 100 * time.Millisecond
+
+// frameMsg draws changes that frame limiting held back
+type frameMsg struct{}
+
+// isBackground reports whether msg comes from background activity rather
+// than from the user
+func isBackground(msg tea.Msg) bool {
+	switch msg.(type) {
+	case backgroundFetchMsg, downloadProgressMsg, downloadCompleteMsg, audioFinishedMsg, streamUpdateMsg, watchTickMsg, ui.ToastExpiredMsg:
+		return true
+	}
+	return false
+}
+
+// limitFrames decides whether the screen is redrawn after msg. A background
+// message arriving within backgroundFrameInterval of the last redraw leaves
+// the screen as it is; the command returned draws everything held back
+// once the interval is up.
+func (m *Model) limitFrames(msg tea.Msg) tea.Cmd {
+	if _, ok := msg.(frameMsg); ok {
+		m.framePending = false
+		m.holdFrame = false
+		return nil
+	}
+
+	wait := backgroundFrameInterval - time.Since(m.frameAt)
+	m.holdFrame = isBackground(msg) && m.frame != "" && wait > 0
+	if !m.holdFrame || m.framePending {
+		return nil
+	}
+	m.framePending = true
+	return tea.Tick(wait, func(time.Time) tea.Msg { return frameMsg{} })
+}
@@ -0,0 +1,172 @@
+package app
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"starsearch/internal/gemini"
+	"starsearch/internal/types"
+	"starsearch/internal/ui"
+	"starsearch/internal/urlutil"
+)
+
+// identityInfos lists the stored identities for the identities modal
+func (m *Model) identityInfos() []types.IdentityInfo {
+	identities, err := m.identities.List()
+	if err != nil {
+		m.statusBar.SetError(err.Error())
+	}
+	infos := make([]types.IdentityInfo, 0, len(identities))
+	for _, identity := range identities {
+		infos = append(infos, identity.Info())
+	}
+	return infos
+}
+
+// createIdentity generates the client certificate asked for in the
+// identities modal
+func (m *Model) createIdentity(msg ui.IdentityCreateMsg) {
+	identity, err := m.identities.Generate(gemini.IdentityOptions{
+		Name:       msg.Name,
+		CommonName: msg.CommonName,
+		KeyType:    msg.KeyType,
+		Validity:   time.Duration(msg.ValidityDays) * 24 * time.Hour,
+	})
+	if err != nil {
+		if errors.Is(err, gemini.ErrIdentityExists) {
+			err = fmt.Errorf("identity %s already exists", msg.Name)
+		}
+		m.statusBar.SetError(fmt.Sprintf("Failed to create identity: %v", err))
+		return
+	}
+	m.identitiesModal.Refresh(m.identityInfos())
+	m.statusBar.SetMessage(fmt.Sprintf("Created identity %s (%s, valid until %s)", identity.Name, msg.KeyType, identity.Info().NotAfter.Format("2006-01-02")))
+}
+
+// deleteIdentity removes an identity chosen in the identities modal
+func (m *Model) deleteIdentity(msg ui.IdentityDeleteMsg) {
+	if err := m.identities.Delete(msg.Name); err != nil {
+		m.statusBar.SetError(err.Error())
+		return
+	}
+	m.identitiesModal.Refresh(m.identityInfos())
+	m.statusBar.SetMessage("Deleted identity " + msg.Name)
+}
+
+// promptIdentity asks which identity to present on urlStr, offering the
+// named identities, or all of them if names is empty
+func (m *Model) promptIdentity(urlStr string, names []string, reason string) {
+	infos := m.identityInfos()
+	if len(names) > 0 {
+		offered := make(map[string]bool, len(names))
+		for _, name := range names {
+			offered[name] = true
+		}
+		var candidates []types.IdentityInfo
+		for _, info := range infos {
+			if offered[info.Name] {
+				candidates = append(candidates, info)
+			}
+		}
+		infos = candidates
+	}
+	m.identitiesModal.ShowChooser(infos, urlStr, reason)
+	ui.OpenModal(m.modals, m.identitiesModal)
+}
+
+// useIdentity retries a request with the identity picked for it. An
+// identity not yet scoped to the URL is scoped to the whole capsule.
+func (m *Model) useIdentity(msg ui.IdentityChosenMsg) tea.Cmd {
+	scoped := false
+	for _, identity := range m.identities.Match(msg.URL) {
+		scoped = scoped || identity.Name == msg.Name
+	}
+	if !scoped {
+		root, err := urlutil.RootURL(msg.URL)
+		if err == nil {
+			err = m.identities.AddScope(msg.Name, root)
+		}
+		if err != nil {
+			m.statusBar.SetError(fmt.Sprintf("Failed to scope identity: %v", err))
+			return nil
+		}
+	}
+	m.identities.Choose(msg.URL, msg.Name)
+	m.isNavigating = true
+	cmd := m.navigate(msg.URL)
+	m.statusBar.SetMessage(fmt.Sprintf("Using identity %s for %s...", msg.Name, urlutil.Host(msg.URL)))
+	return cmd
+}
+
+// saveIdentityScopes stores the scopes edited in the identities modal
+func (m *Model) saveIdentityScopes(msg ui.IdentityScopesMsg) {
+	if err := m.identities.SetScopes(msg.Name, msg.Scopes); err != nil {
+		m.statusBar.SetError(err.Error())
+		return
+	}
+	m.identitiesModal.Refresh(m.identityInfos())
+	m.statusBar.SetMessage("Saved scopes of " + msg.Name)
+}
+
+// presentedIdentity returns the name of the identity presented on the
+// current page, or "" if there is none
+func (m *Model) presentedIdentity() string {
+	if !strings.HasPrefix(m.currentURL, "gemini://") {
+		return ""
+	}
+	if names := m.identities.MatchNames(m.currentURL); len(names) == 1 {
+		return names[0]
+	}
+	return ""
+}
+
+// rotateIdentity presents the next identity, in name order, on the current
+// host and reloads the page with it
+func (m *Model) rotateIdentity() tea.Cmd {
+	if !strings.HasPrefix(m.currentURL, "gemini://") {
+		m.statusBar.SetMessage("Identities are only used on Gemini pages")
+		return nil
+	}
+	identities, err := m.identities.List()
+	if err != nil {
+		m.statusBar.SetError(err.Error())
+		return nil
+	}
+	if len(identities) == 0 {
+		m.statusBar.SetMessage("No identities yet; press Shift+I to create one")
+		return nil
+	}
+
+	current := ""
+	if matches := m.identities.Match(m.currentURL); len(matches) == 1 {
+		current = matches[0].Name
+	}
+	next := 0
+	for i, identity := range identities {
+		if identity.Name == current {
+			next = (i + 1) % len(identities)
+			break
+		}
+	}
+
+	m.identities.Override(m.currentURL, identities[next].Name)
+	m.forceReload = true
+	m.isNavigating = true
+	cmd := m.navigate(m.currentURL)
+	m.statusBar.SetMessage(fmt.Sprintf("Identity for %s: %s (%d/%d)", urlutil.Host(m.currentURL), identities[next].Name, next+1, len(identities)))
+	return cmd
+}
+
+// toggleAnonymous arms or disarms sending the next request without a
+// client certificate
+func (m *Model) toggleAnonymous() {
+	m.anonymousNext = !m.anonymousNext
+	if m.anonymousNext {
+		m.statusBar.SetMessage("The next request is sent anonymously, without a client certificate")
+	} else {
+		m.statusBar.SetMessage("Anonymous request cancelled")
+	}
+}
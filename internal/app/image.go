@@ -0,0 +1,202 @@
+package app
+
+import (
+	"fmt"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/muesli/termenv"
+	"starsearch/internal/renderer"
+	"starsearch/internal/types"
+	"starsearch/internal/ui"
+)
+
+// imageDecodedMsg carries an image rendered off the UI thread, to be shown
+// in place of its placeholder page
+type imageDecodedMsg struct {
+	placeholder *types.Document
+	resp        *types.Response
+	mimeType    string
+	rendered    string
+	view        renderer.ImageOptions // As drawn, with the pan offsets used
+	err         error
+}
+
+// imageDocument wraps lines, an image rendered as text or a note in its
+// place, in a document for resp
+func imageDocument(resp *types.Response, mimeType string, lines []string) *types.Document {
+	doc := &types.Document{
+		URL:      resp.URL,
+		RawBody:  resp.Body,
+		MIMEType: mimeType,
+		Lines:    []types.Line{},
+		Links:    []types.Line{},
+		Metadata: resp.Metadata(),
+	}
+	for _, line := range lines {
+		doc.Lines = append(doc.Lines, types.Line{
+			Type: types.LineText,
+			Text: line,
+			Raw:  line,
+		})
+	}
+	return doc
+}
+
+// imagePlaceholder is the page shown while an image is decoded
+func imagePlaceholder(resp *types.Response, mimeType string) *types.Document {
+	note := fmt.Sprintf("Decoding image… %s", ui.FormatBytes(int64(len(resp.Body))))
+	return imageDocument(resp, mimeType, []string{note})
+}
+
+// imageSummary describes an image in the first lines of its page, as
+// drawn in the terminal it is hard to tell what it is: its dimensions,
+// format and size, and when and with which camera it was taken if recorded
+func imageSummary(data []byte) []string {
+	info, err := renderer.ReadImageInfo(data)
+	if err != nil {
+		return nil
+	}
+	lines := []string{fmt.Sprintf("Image: %dx%d %s, %s", info.Width, info.Height, strings.ToUpper(info.Format), ui.FormatBytes(int64(len(data))))}
+	if !info.Taken.IsZero() {
+		lines = append(lines, "Taken: "+info.Taken.Format("2006-01-02 15:04:05"))
+	}
+	if info.Camera != "" {
+		lines = append(lines, "Camera: "+info.Camera)
+	}
+	return lines
+}
+
+// decodeImage decodes and renders an image in a goroutine of its own, so
+// large images leave the interface responsive. The result replaces
+// placeholder wherever it is still shown.
+func (m *Model) decodeImage(placeholder *types.Document, resp *types.Response, mimeType string) tea.Cmd {
+	imgRenderer := renderer.NewImageRenderer(m.width-4, m.height-8)
+	view := m.imageView
+	return func() tea.Msg {
+		rendered, view, err := imgRenderer.Render(resp.Body, view)
+		if err == nil {
+			rendered = strings.Join(imageSummary(resp.Body), "\n") + "\n" + rendered
+		}
+		return imageDecodedMsg{placeholder: placeholder, resp: resp, mimeType: mimeType, rendered: rendered, view: view, err: err}
+	}
+}
+
+// handleImageDecoded swaps a rendered image in for its placeholder, in
+// the current page or the tab it was left in
+func (m *Model) handleImageDecoded(msg imageDecodedMsg) {
+	lines := strings.Split(msg.rendered, "\n")
+	if msg.err != nil {
+		lines = []string{fmt.Sprintf("Failed to render image: %v", msg.err)}
+	}
+	doc := imageDocument(msg.resp, msg.mimeType, lines)
+
+	if m.currentDoc == msg.placeholder {
+		m.imageView.PanX, m.imageView.PanY = msg.view.PanX, msg.view.PanY
+		m.currentDoc = doc
+		m.viewport.SetDocument(doc)
+		if msg.err != nil {
+			m.statusBar.SetError(lines[0])
+		} else {
+			m.statusBar.SetMessage(fmt.Sprintf("Image loaded: %s", msg.mimeType))
+		}
+		m.saveCurrentTabState()
+		return
+	}
+	for i, tab := range m.tabBar.GetTabs() {
+		if tab.Document == msg.placeholder {
+			m.tabBar.UpdateTab(i, tab.URL, tab.Title, doc, tab.Scroll)
+		}
+	}
+}
+
+// imageView returns how images are drawn until changed on an image page:
+// in 256 colours on terminals without true colour, unless configured
+func imageView(config types.UIConfig) renderer.ImageOptions {
+	colors256 := config.ImageColors == "256"
+	if config.ImageColors == "" || config.ImageColors == "auto" {
+		colors256 = lipgloss.ColorProfile() != termenv.TrueColor
+	}
+	return renderer.ImageOptions{Colors256: colors256, Dither: config.ImageDither, Grayscale: config.ImageGrayscale}
+}
+
+// viewingImage reports whether the current page is an image
+func (m *Model) viewingImage() bool {
+	return m.currentDoc != nil && len(m.currentDoc.RawBody) > 0 && renderer.IsImageMIME(m.currentDoc.MIMEType)
+}
+
+// imageKey handles the keys that change how the image on the current page
+// is drawn: +/- step through the fits, Shift+arrows pan a cropped image, x
+// cycles the colours and m toggles grayscale. It reports whether key was
+// one of them.
+func (m *Model) imageKey(key string) (tea.Cmd, bool) {
+	view := m.imageView
+	// Pan by a quarter of the window; each row of cells is two pixels
+	stepX, stepY := max(1, (m.width-4)/4), max(1, (m.height-8)/2)
+	cropped := view.Fit == renderer.FitHeight || view.Fit == renderer.ActualSize
+
+	switch key {
+	case "+", "=":
+		if view.Fit == renderer.ActualSize {
+			m.statusBar.SetMessage("The image is already shown at 1:1")
+			return nil, true
+		}
+		view.Fit++
+		view.PanX, view.PanY = 0, 0
+	case "-":
+		if view.Fit == renderer.FitWindow {
+			m.statusBar.SetMessage("The image already fits the window")
+			return nil, true
+		}
+		view.Fit--
+		view.PanX, view.PanY = 0, 0
+	case "shift+left", "shift+right", "shift+up", "shift+down":
+		if !cropped {
+			m.statusBar.SetMessage("Zoom in to fit height or 1:1 with + to pan the image")
+			return nil, true
+		}
+		switch key {
+		case "shift+left":
+			view.PanX -= stepX
+		case "shift+right":
+			view.PanX += stepX
+		case "shift+up":
+			view.PanY -= stepY
+		case "shift+down":
+			view.PanY += stepY
+		}
+	case "x":
+		// True colour, then 256 colours dithered and not
+		switch {
+		case !view.Colors256:
+			view.Colors256, view.Dither = true, true
+		case view.Dither:
+			view.Dither = false
+		default:
+			view.Colors256 = false
+		}
+	case "m":
+		view.Grayscale = !view.Grayscale
+	default:
+		return nil, false
+	}
+
+	m.imageView = view
+	return m.redrawImage(), true
+}
+
+// redrawImage draws the image on the current page again as m.imageView
+// says, off the UI thread like the first time
+func (m *Model) redrawImage() tea.Cmd {
+	doc := m.currentDoc
+	resp := &types.Response{
+		Status:   doc.Metadata.Status,
+		Meta:     doc.Metadata.Meta,
+		Body:     doc.RawBody,
+		URL:      doc.URL,
+		Protocol: doc.Metadata.Protocol,
+		Fetched:  doc.Metadata.Fetched,
+	}
+	return m.decodeImage(doc, resp, doc.MIMEType)
+}
@@ -0,0 +1,25 @@
+package app
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"starsearch/internal/urlutil"
+)
+
+// recordLatency adds a request to urlStr to its host's response times:
+// how long it took to answer and whether it failed. Requests the user
+// cancelled say nothing about the host and are left out.
+func (m *Model) recordLatency(urlStr string, took time.Duration, err error) {
+	if errors.Is(err, context.Canceled) {
+		return
+	}
+	m.stats.RecordLatency(urlutil.Host(urlStr), took, err != nil)
+}
+
+// slowLink reports whether urlStr points to a host whose recent requests
+// were slow or often failed
+func (m *Model) slowLink(urlStr string) bool {
+	return m.stats.IsSlow(urlutil.Host(urlStr))
+}
@@ -0,0 +1,101 @@
+package app
+
+import (
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// addressBarHeight is the height of the address bar with its border
+const addressBarHeight = 3
+
+// layout is where the bars around the page are drawn. Rows are counted
+// from the top of the screen, with -1 for bars that are hidden.
+type layout struct {
+	help       int // Link number mode help text
+	tabBar     int
+	addressBar int // First of its rows
+	breadcrumb int
+	viewport   int  // First row of the page
+	statusBar  bool // Whether the status bar takes the last row
+}
+
+// layout works out which bars are shown and where. A hidden address bar
+// is shown again while it has focus.
+func (m *Model) layout() layout {
+	l := layout{help: -1, tabBar: -1, addressBar: -1, breadcrumb: -1}
+	row := 0
+	if m.linkNumbers {
+		l.help = row
+		row++
+	}
+	if !m.hideTabBar {
+		l.tabBar = row
+		row++
+	}
+	if !m.hideAddressBar || m.addressBar.IsFocused() {
+		l.addressBar = row
+		row += addressBarHeight
+	}
+	if m.breadcrumbHeight() > 0 {
+		l.breadcrumb = row
+		row++
+	}
+	l.viewport = row
+	l.statusBar = !m.hideStatusBar
+	return l
+}
+
+// viewportHeight is the number of rows left for the page on a screen of
+// the given height
+func (l layout) viewportHeight(screenHeight int) int {
+	height := screenHeight - l.viewport
+	if l.statusBar {
+		height--
+	}
+	return max(height, 1)
+}
+
+// onAddressBar reports whether screen row y is part of the address bar
+func (l layout) onAddressBar(y int) bool {
+	return l.addressBar >= 0 && y >= l.addressBar && y < l.addressBar+addressBarHeight
+}
+
+// applyLayout moves and resizes the page when bars were shown or hidden
+// since the last call
+func (m *Model) applyLayout() {
+	l := m.layout()
+	if l == m.applied {
+		return
+	}
+	m.applied = l
+	m.resizeViewport()
+}
+
+// toggleBar shows or hides one of the bars, or with bar "all" switches
+// between the distraction-free view without any bars and all of them
+func (m *Model) toggleBar(bar string) tea.Cmd {
+	var shown bool
+	switch bar {
+	case "tab":
+		m.hideTabBar = !m.hideTabBar
+		shown = !m.hideTabBar
+	case "address":
+		m.hideAddressBar = !m.hideAddressBar
+		shown = !m.hideAddressBar
+	case "status":
+		m.hideStatusBar = !m.hideStatusBar
+		shown = !m.hideStatusBar
+	default:
+		hide := !(m.hideTabBar && m.hideAddressBar && m.hideStatusBar)
+		m.hideTabBar, m.hideAddressBar, m.hideStatusBar = hide, hide, hide
+		if hide {
+			return m.notify("Distraction-free view: F11 brings the bars back")
+		}
+		return m.notify("Bars shown")
+	}
+
+	label := map[string]string{"tab": "Tab bar", "address": "Address bar", "status": "Status bar"}[bar]
+	if shown {
+		return m.notify(label + " shown")
+	}
+	return m.notify(label + " hidden")
+}
@@ -0,0 +1,85 @@
+package app
+
+import (
+	tea "github.com/charmbracelet/bubbletea"
+	"starsearch/internal/scheduler"
+	"starsearch/internal/types"
+)
+
+// lineOverhead approximates the per-line cost of a parsed document beyond
+// its text: the Line struct and string headers
+const lineOverhead = 96
+
+// documentSize estimates the memory held by a parsed document
+func documentSize(doc *types.Document) int64 {
+	if doc == nil {
+		return 0
+	}
+
+	size := int64(len(doc.RawBody))
+	for _, line := range doc.Lines {
+		size += int64(len(line.Raw)+len(line.Text)+len(line.URL)) + lineOverhead
+	}
+	for _, link := range doc.Links {
+		size += int64(len(link.Raw)+len(link.Text)+len(link.URL)) + lineOverhead
+	}
+	return size
+}
+
+// enforceTabBudget evicts the documents of the least recently used
+// background tabs until all open tabs fit in Performance.TabMemoryMB. The
+// active tab and tabs still loading are never evicted.
+func (m *Model) enforceTabBudget() {
+	budgetMB := m.config.Get().Performance.TabMemoryMB
+	if budgetMB <= 0 {
+		return
+	}
+	budget := int64(budgetMB) * 1024 * 1024
+
+	tabs := m.tabBar.GetTabs()
+	active := m.tabBar.GetActiveIndex()
+	var total int64
+	for _, tab := range tabs {
+		total += documentSize(tab.Document)
+	}
+
+	for total > budget {
+		victim := -1
+		for i, tab := range tabs {
+			if i == active || tab.Document == nil || tab.Loading {
+				continue
+			}
+			if victim < 0 || tab.LastUsed < tabs[victim].LastUsed {
+				victim = i
+			}
+		}
+		if victim < 0 {
+			return
+		}
+
+		total -= documentSize(tabs[victim].Document)
+		m.tabBar.EvictDocument(victim)
+	}
+}
+
+// activateTab switches to the tab at index, reloading its document if it
+// was evicted
+func (m *Model) activateTab(index int) tea.Cmd {
+	m.saveCurrentTabState()
+	m.tabBar.SwitchTab(index)
+	m.loadTabState()
+	return m.restoreActiveTab()
+}
+
+// restoreActiveTab reloads the active tab's document if it was evicted,
+// from the page cache when possible, keeping its scroll position
+func (m *Model) restoreActiveTab() tea.Cmd {
+	tab := m.tabBar.GetActiveTab()
+	if tab == nil || !tab.Evicted || tab.Loading || tab.URL == "" {
+		return nil
+	}
+
+	m.tabBar.SetTabStatus(m.tabBar.GetActiveIndex(), true, false)
+	m.statusBar.SetMessage("Reloading unloaded tab: " + tab.URL + "...")
+	return m.fetchIntoTab(tab.ID, tab.URL, nil, scheduler.Interactive, fetchRestore)
+}
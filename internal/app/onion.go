@@ -0,0 +1,56 @@
+package app
+
+import (
+	"fmt"
+
+	"starsearch/internal/gemini"
+	"starsearch/internal/gopher"
+)
+
+// isolatingFetcher is a fetcher that can put requests on Tor circuits of
+// their own, like the Gemini client behind a SOCKS proxy
+type isolatingFetcher interface {
+	Isolated(key string) *gemini.Client
+}
+
+// isolatingGopherFetcher is isolatingFetcher for the Gopher client
+type isolatingGopherFetcher interface {
+	Isolated(key string) *gopher.Client
+}
+
+// geminiFetcher returns the client for Gemini requests made for the tab
+// with ID tabID. With tor_isolation on, each tab presents its own SOCKS
+// credentials, so Tor keeps its requests on separate circuits.
+func (m *Model) geminiFetcher(tabID int) fetcher {
+	if !m.config.Get().Network.TorIsolation {
+		return m.client
+	}
+	if isolating, ok := m.client.(isolatingFetcher); ok {
+		return isolating.Isolated(isolationKey(tabID))
+	}
+	return m.client
+}
+
+// gopherFetcher is geminiFetcher for Gopher requests
+func (m *Model) gopherFetcher(tabID int) fetcher {
+	if !m.config.Get().Network.TorIsolation {
+		return m.gopherClient
+	}
+	if isolating, ok := m.gopherClient.(isolatingGopherFetcher); ok {
+		return isolating.Isolated(isolationKey(tabID))
+	}
+	return m.gopherClient
+}
+
+// isolationKey returns the SOCKS credentials of the tab with ID tabID
+func isolationKey(tabID int) string {
+	return fmt.Sprintf("starsearch-tab-%d", tabID)
+}
+
+// activeTabID returns the ID of the active tab, or 0 if there is none
+func (m *Model) activeTabID() int {
+	if tab := m.tabBar.GetActiveTab(); tab != nil {
+		return tab.ID
+	}
+	return 0
+}
@@ -0,0 +1,72 @@
+package app
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"starsearch/internal/textutil"
+)
+
+// defaultPager is run when $PAGER is not set. -R passes the page's colors
+// through.
+const defaultPager = "less -R"
+
+// pagerClosedMsg reports that the pager showing the page exited
+type pagerClosedMsg struct {
+	err error
+}
+
+// openInPager hands the current page to $PAGER, as rendered or as the raw
+// source, while the browser waits. The browser takes the terminal back
+// when the pager exits.
+func (m *Model) openInPager(source bool) tea.Cmd {
+	if m.currentDoc == nil {
+		return nil
+	}
+
+	content := m.viewport.Rendered()
+	if source {
+		// Control characters would act on the terminal, not be shown.
+		// Unless asked to show them, line endings are made LF like the
+		// page's; a bare CR would otherwise join lines.
+		body, visible := m.currentDoc.RawBody, m.config.Get().UI.ShowControlChars
+		if !visible {
+			body = textutil.NormalizeLineEndings(body)
+		}
+		content = textutil.SanitizeText(string(body), visible)
+	}
+	file, err := os.CreateTemp("", "starsearch-*.txt")
+	if err != nil {
+		m.statusBar.SetError(fmt.Sprintf("Failed to open pager: %v", err))
+		return nil
+	}
+	_, err = file.WriteString(content)
+	if closeErr := file.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		os.Remove(file.Name())
+		m.statusBar.SetError(fmt.Sprintf("Failed to open pager: %v", err))
+		return nil
+	}
+
+	cmd := pagerCommand(os.Getenv("PAGER"), file.Name())
+	return tea.ExecProcess(cmd, func(err error) tea.Msg {
+		os.Remove(file.Name())
+		return pagerClosedMsg{err: err}
+	})
+}
+
+// pagerCommand builds the command showing path with pager, a command line
+// such as "less -R"
+func pagerCommand(pager, path string) *exec.Cmd {
+	args := strings.Fields(pager)
+	if len(args) == 0 {
+		args = strings.Fields(defaultPager)
+	}
+	args = append(args, path)
+	return exec.Command(args[0], args[1:]...)
+}
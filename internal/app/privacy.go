@@ -0,0 +1,94 @@
+package app
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"starsearch/internal/types"
+	"starsearch/internal/ui"
+)
+
+// privacyPurgeMsg forgets everything kept about a host, once confirmed
+type privacyPurgeMsg struct{ host string }
+
+// privacyReport gathers, host by host, the client certificates presented,
+// capsule tokens kept, input answers remembered and certificates trusted
+func (m *Model) privacyReport() []types.HostPrivacy {
+	report := make(map[string]*types.HostPrivacy)
+	entry := func(host string) *types.HostPrivacy {
+		if report[host] == nil {
+			report[host] = &types.HostPrivacy{Host: host}
+		}
+		return report[host]
+	}
+
+	for host, names := range m.identities.HostIdentities() {
+		entry(host).Identities = names
+	}
+	for _, token := range m.tokens.List() {
+		e := entry(token.Host)
+		e.Token = true
+		e.TokenSet = token.Token != ""
+	}
+	for host, count := range m.inputHistory.Hosts() {
+		if host != "" {
+			entry(host).Inputs = count
+		}
+	}
+	for _, host := range m.tofuStore.ListHosts() {
+		if info, ok := m.tofuStore.GetCertInfo(host); ok {
+			e := entry(host)
+			e.FirstSeen = info.FirstSeen
+			e.LastSeen = info.LastSeen
+		}
+	}
+
+	hosts := make([]types.HostPrivacy, 0, len(report))
+	for _, e := range report {
+		hosts = append(hosts, *e)
+	}
+	sort.Slice(hosts, func(i, j int) bool {
+		return hosts[i].Host < hosts[j].Host
+	})
+	return hosts
+}
+
+// openPrivacyReport shows what is sent to or kept about each host
+func (m *Model) openPrivacyReport() {
+	m.privacyReportModal.Show(m.privacyReport())
+	ui.OpenModal(m.modals, m.privacyReportModal)
+}
+
+// confirmPurge asks before forgetting everything kept about a host
+func (m *Model) confirmPurge(host string) {
+	m.confirm("Forget "+host+"?",
+		"Its client certificate scopes and usage, capsule token, remembered input answers and trusted certificate are removed. Identities are kept; the certificate is trusted again on the next visit.",
+		"Forget", privacyPurgeMsg{host: host})
+}
+
+// purgeHost forgets everything kept about a host
+func (m *Model) purgeHost(host string) tea.Cmd {
+	var failed []string
+	if err := m.identities.ForgetHost(host); err != nil {
+		failed = append(failed, fmt.Sprintf("identities: %v", err))
+	}
+	m.tokens.Revoke(host)
+	m.inputHistory.ForgetHost(host)
+	if _, ok := m.tofuStore.GetCertInfo(host); ok {
+		if err := m.tofuStore.RemoveCert(host); err != nil {
+			failed = append(failed, fmt.Sprintf("certificate: %v", err))
+		}
+	}
+
+	m.privacyReportModal.Refresh(m.privacyReport())
+	if m.privacyModal.IsVisible() {
+		m.privacyModal.Refresh(m.tokens.List())
+	}
+	if len(failed) > 0 {
+		m.statusBar.SetError(fmt.Sprintf("Failed to forget %s: %s", host, strings.Join(failed, "; ")))
+		return nil
+	}
+	return m.notify("Forgot " + host)
+}
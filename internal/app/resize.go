@@ -0,0 +1,35 @@
+package app
+
+import (
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// resizeDebounce is how long the terminal size must stay unchanged before
+// the page is re-rendered at the new size
+const resizeDebounce = 75 * time.Millisecond
+
+// resizeSettledMsg fires when no further resize arrived within resizeDebounce
+type resizeSettledMsg struct {
+	id int // Matches Model.resizeID unless another resize followed
+}
+
+// scheduleResize re-renders the page once the terminal stops resizing, so a
+// burst of size changes (e.g. dragging a tmux pane) costs one render
+func (m *Model) scheduleResize() tea.Cmd {
+	m.resizeID++
+	id := m.resizeID
+	return tea.Tick(resizeDebounce, func(time.Time) tea.Msg {
+		return resizeSettledMsg{id: id}
+	})
+}
+
+// resizeViewport fits the page viewport between the bars shown on the
+// current terminal
+func (m *Model) resizeViewport() {
+	l := m.layout()
+	m.viewport.SetYPosition(l.viewport)
+	m.viewport.SetSize(m.width, l.viewportHeight(m.height))
+	m.toast.SetSize(m.width, l.viewport+l.viewportHeight(m.height))
+}
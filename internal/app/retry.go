@@ -0,0 +1,77 @@
+package app
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"syscall"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// retryFetchMsg fires when the backoff delay for a retry has elapsed
+type retryFetchMsg struct {
+	url     string
+	attempt int // 1-based retry number
+	id      int // Matches Model.retryID unless the retry was cancelled
+}
+
+// isTransientError reports whether err is a network failure worth retrying
+// (connection refused/reset, unreachable host, or a timeout)
+func isTransientError(err error) bool {
+	if err == nil {
+		return false
+	}
+
+	var netErr net.Error
+	if errors.As(err, &netErr) && netErr.Timeout() {
+		return true
+	}
+
+	return errors.Is(err, context.DeadlineExceeded) ||
+		errors.Is(err, syscall.ECONNREFUSED) ||
+		errors.Is(err, syscall.ECONNRESET) ||
+		errors.Is(err, syscall.ECONNABORTED) ||
+		errors.Is(err, syscall.EHOSTUNREACH) ||
+		errors.Is(err, syscall.ENETUNREACH)
+}
+
+// retryBackoff returns the delay before the given 1-based retry attempt
+func retryBackoff(initialMs, attempt int) time.Duration {
+	return time.Duration(initialMs) * time.Millisecond << (attempt - 1)
+}
+
+// scheduleRetry queues another fetch of msg.url if the error is transient
+// and attempts remain. It returns nil when no retry was scheduled.
+func (m *Model) scheduleRetry(msg fetchCompleteMsg) tea.Cmd {
+	netCfg := m.config.Get().Network
+	if msg.url == "" || !isTransientError(msg.err) || msg.attempt >= netCfg.RetryAttempts {
+		return nil
+	}
+
+	attempt := msg.attempt + 1
+	delay := retryBackoff(netCfg.RetryBackoffMs, attempt)
+	m.retryID++
+	m.retryPending = true
+	id := m.retryID
+	url := msg.url
+
+	m.statusBar.SetError(fmt.Sprintf("%v (retrying %d/%d in %s, Esc to cancel)",
+		msg.err, attempt, netCfg.RetryAttempts, delay))
+
+	return tea.Tick(delay, func(time.Time) tea.Msg {
+		return retryFetchMsg{url: url, attempt: attempt, id: id}
+	})
+}
+
+// cancelRetry drops any pending retry so its tick is ignored when it fires
+func (m *Model) cancelRetry() bool {
+	if !m.retryPending {
+		return false
+	}
+	m.retryPending = false
+	m.retryID++
+	return true
+}
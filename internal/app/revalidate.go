@@ -0,0 +1,50 @@
+package app
+
+import (
+	"crypto/sha256"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// revalidation is a reload in progress that checks whether the page shown
+// changed. Gemini has no conditional requests, so the page is fetched again
+// and the bodies' hashes compared.
+type revalidation struct {
+	fetchID int
+	url     string
+	hash    [sha256.Size]byte
+}
+
+// revalidate reloads the current page past the cache, remembering the body
+// shown so an unchanged page keeps the reader's place
+func (m *Model) revalidate() tea.Cmd {
+	m.forceReload = true
+	cmd := m.navigate(m.currentURL)
+	if m.currentDoc != nil {
+		m.revalidation = &revalidation{
+			fetchID: m.fetchID,
+			url:     m.currentURL,
+			hash:    sha256.Sum256(m.currentDoc.RawBody),
+		}
+	}
+	return cmd
+}
+
+// revalidated reports whether msg completes a revalidation and brought back
+// the body that was already shown
+func (m *Model) revalidated(msg fetchCompleteMsg) bool {
+	rv := m.revalidation
+	if rv == nil || msg.fetchID == 0 || msg.fetchID != rv.fetchID {
+		return false
+	}
+	m.revalidation = nil
+	return msg.resp.URL == rv.url && sha256.Sum256(msg.resp.Body) == rv.hash
+}
+
+// loadedStatus is how the status bar announces a loaded page
+func loadedStatus(unchanged bool) string {
+	if unchanged {
+		return "Unchanged (revalidated)"
+	}
+	return "Loaded"
+}
@@ -0,0 +1,37 @@
+package app
+
+import (
+	"errors"
+	"time"
+
+	"starsearch/internal/scheduler"
+	"starsearch/internal/types"
+	"starsearch/internal/urlutil"
+)
+
+// robotsTTL is how long a capsule's robots.txt is trusted before it is
+// fetched again
+const robotsTTL = time.Hour
+
+// errDisallowed is the error of automated requests robots.txt turns away
+var errDisallowed = errors.New("robots.txt asks automated clients not to fetch this page")
+
+// automated reports whether fetches for this reason happen without the
+// user asking for the page. Those honor robots.txt, and are the ones to
+// mark as automated wherever requests are logged.
+func (r fetchReason) automated() bool {
+	return r == fetchWatch
+}
+
+// robotsFetcher fetches robots.txt files as background requests
+type robotsFetcher struct {
+	m *Model
+}
+
+func (f robotsFetcher) Fetch(urlStr string) (*types.Response, error) {
+	release := f.m.scheduler.Acquire(urlutil.Host(urlStr), scheduler.Background)
+	defer release()
+	resp, err := f.m.client.Fetch(urlStr)
+	f.m.chargeTransfer(scheduler.Background, resp)
+	return resp, err
+}
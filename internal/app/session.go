@@ -0,0 +1,122 @@
+package app
+
+import (
+	"fmt"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"starsearch/internal/gemini"
+	"starsearch/internal/types"
+	"starsearch/internal/ui"
+)
+
+// exportSession writes every open tab as a link in a gemtext page in the
+// download directory. Any Gemini client can open the file, and importing
+// it on another machine opens the same tabs.
+func (m *Model) exportSession() {
+	m.saveCurrentTabState()
+	tabs := m.tabBar.GetTabs()
+	now := time.Now()
+
+	var b strings.Builder
+	b.WriteString("# starsearch session\n\n")
+	fmt.Fprintf(&b, "Exported %s. Import this page with Shift+O to open every link as a tab.\n\n", now.Format("2006-01-02 15:04"))
+	count := 0
+	for _, tab := range tabs {
+		if tab.URL == "" {
+			continue
+		}
+		title := strings.TrimSpace(tab.Title)
+		if title == "" || title == tab.URL {
+			fmt.Fprintf(&b, "=> %s\n", tab.URL)
+		} else {
+			fmt.Fprintf(&b, "=> %s %s\n", tab.URL, title)
+		}
+		count++
+	}
+	if count == 0 {
+		m.statusBar.SetMessage("No open pages to export")
+		return
+	}
+
+	dir := m.config.GetDownloadDirectory()
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		m.statusBar.SetError(fmt.Sprintf("Failed to create download directory: %v", err))
+		return
+	}
+	filePath := uniquePath(filepath.Join(dir, "session-"+now.Format("2006-01-02")+".gmi"))
+	if err := os.WriteFile(filePath, []byte(b.String()), 0644); err != nil {
+		m.statusBar.SetError(fmt.Sprintf("Failed to export session: %v", err))
+		return
+	}
+	m.lastSessionExport = filePath
+	m.notify(fmt.Sprintf("Exported %d tabs to %s", count, filePath))
+}
+
+// promptSessionImport asks for the gemtext file to import, offering the
+// last export as a previous answer
+func (m *Model) promptSessionImport() tea.Cmd {
+	var previous []string
+	if m.lastSessionExport != "" {
+		previous = []string{m.lastSessionExport}
+	}
+	m.importingSession = true
+	m.inputModal.SetStep(0, "")
+	cmd := m.inputModal.Show("Import session from a gemtext file", false, previous)
+	ui.OpenModal(m.modals, m.inputModal)
+	return cmd
+}
+
+// importSession opens every Gemini and Gopher link in the gemtext file at
+// filePath in a background tab
+func (m *Model) importSession(filePath string) tea.Cmd {
+	data, err := os.ReadFile(expandHome(filePath))
+	if err != nil {
+		m.statusBar.SetError(fmt.Sprintf("Failed to import session: %v", err))
+		return nil
+	}
+	urls := sessionLinks(data)
+	if len(urls) == 0 {
+		m.statusBar.SetError(fmt.Sprintf("No Gemini or Gopher links in %s", filePath))
+		return nil
+	}
+	return m.openInBackground(urls)
+}
+
+// sessionLinks returns the absolute gemini:// and gopher:// links of a
+// gemtext page, in order and without duplicates
+func sessionLinks(data []byte) []string {
+	resp := &types.Response{Status: 20, Meta: "text/gemini", Body: data}
+	doc, err := gemini.NewParser("").Parse(resp)
+	if err != nil {
+		return nil
+	}
+
+	var urls []string
+	seen := make(map[string]bool)
+	for _, link := range doc.Links {
+		u, err := url.Parse(link.URL)
+		if err != nil || (u.Scheme != "gemini" && u.Scheme != "gopher") || u.Host == "" {
+			continue
+		}
+		if !seen[link.URL] {
+			seen[link.URL] = true
+			urls = append(urls, link.URL)
+		}
+	}
+	return urls
+}
+
+// expandHome replaces a leading ~/ in a local path with the home directory
+func expandHome(p string) string {
+	if strings.HasPrefix(p, "~/") {
+		if home, err := os.UserHomeDir(); err == nil {
+			return filepath.Join(home, p[2:])
+		}
+	}
+	return p
+}
@@ -0,0 +1,50 @@
+package app
+
+import "time"
+
+// shutdownTimeout is how long Shutdown waits for downloads being written
+// to disk before cancelling them, and then for them to clean up
+const shutdownTimeout = 3 * time.Second
+
+// Shutdown finishes up once the program has stopped: it aborts requests
+// still in flight, lets downloads already being written finish, cancelling
+// any that take too long, and then saves the session, cache index, history
+// and downloads list and removes unpacked books and files opened in other
+// programs. It must not be called while the program is running.
+func (m *Model) Shutdown() {
+	if m.cancelRequests != nil {
+		m.cancelRequests()
+	}
+	m.stopAudio()
+
+	// Cancelled downloads remove their partial files before they are done
+	if !m.waitForWrites(shutdownTimeout) {
+		for _, cancel := range m.downloadCancels {
+			cancel()
+		}
+		m.waitForWrites(shutdownTimeout)
+	}
+
+	m.saveSession()
+	m.saveCache()
+	_ = m.history.Save() // Ignore errors
+	_ = m.downloads.Save()
+	m.closeBooks()
+	m.removeViewerFiles()
+}
+
+// waitForWrites waits up to timeout for downloads being written to disk,
+// reporting whether they all finished
+func (m *Model) waitForWrites(timeout time.Duration) bool {
+	written := make(chan struct{})
+	go func() {
+		m.writes.Wait()
+		close(written)
+	}()
+	select {
+	case <-written:
+		return true
+	case <-time.After(timeout):
+		return false
+	}
+}
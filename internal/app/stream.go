@@ -0,0 +1,127 @@
+package app
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"starsearch/internal/gemini"
+	"starsearch/internal/scheduler"
+	"starsearch/internal/types"
+	"starsearch/internal/urlutil"
+)
+
+// streamInterval is the least time between renders of a page still
+// arriving. Pages that load faster are never rendered partially.
+const streamInterval = 100 * time.Millisecond
+
+// streamFetcher is a fetcher that can report a response body as it
+// arrives, like the Gemini client
+type streamFetcher interface {
+	FetchStream(urlStr string, partial func(resp *types.Response)) (*types.Response, error)
+}
+
+// contextFetcher is a streamFetcher whose requests can be abandoned
+// midway, like the Gopher client
+type contextFetcher interface {
+	FetchContext(ctx context.Context, urlStr string, partial func(resp *types.Response)) (*types.Response, error)
+}
+
+// streamUpdateMsg carries a page received so far while it is still loading
+type streamUpdateMsg struct {
+	fetchID  int
+	resp     *types.Response
+	protocol string         // "gopher" updates only report progress
+	events   <-chan tea.Msg // Further updates, then the fetchCompleteMsg
+}
+
+// waitForStream delivers the next message of a streaming fetch
+func waitForStream(events <-chan tea.Msg) tea.Cmd {
+	return func() tea.Msg {
+		return <-events
+	}
+}
+
+// fetchStreaming fetches a Gemini page like navigate does, sending the
+// body received so far every streamInterval until it completes
+func (m *Model) fetchStreaming(client streamFetcher, urlStr string, attempt, fetchID int) tea.Cmd {
+	events := make(chan tea.Msg)
+	go func() {
+		release := m.scheduler.Acquire(urlutil.Host(urlStr), scheduler.Interactive)
+		// Pages may stream for as long as they like; the host has answered
+		// once the first part arrives
+		start := time.Now()
+		var answered time.Duration
+		last := start
+		resp, err := client.FetchStream(urlStr, func(partial *types.Response) {
+			if answered == 0 {
+				answered = time.Since(start)
+			}
+			if time.Since(last) < streamInterval {
+				return
+			}
+			last = time.Now()
+			events <- streamUpdateMsg{fetchID: fetchID, resp: partial, events: events}
+		})
+		if answered == 0 {
+			answered = time.Since(start)
+		}
+		m.recordLatency(urlStr, answered, err)
+		release()
+		// Cache successful responses
+		if err == nil && resp != nil && m.pageCache != nil && m.config.Get().Performance.EnableCache {
+			m.pageCache.Set(urlStr, resp, int64(m.config.Get().Performance.CacheTTL))
+		}
+		events <- fetchCompleteMsg{resp: resp, err: err, protocol: "gemini", fromCache: false, url: urlStr, attempt: attempt, fetchID: fetchID}
+	}()
+	return waitForStream(events)
+}
+
+// showPartialPage renders the part of a page received so far
+func (m *Model) showPartialPage(resp *types.Response) {
+	doc, err := gemini.NewParser(resp.URL).Parse(resp)
+	if err != nil {
+		return
+	}
+	m.viewport.GrowDocument(doc)
+	m.streamedFetch = m.fetchID
+	m.statusBar.SetProgress(fmt.Sprintf("Receiving %s... %d lines", resp.URL, len(doc.Lines)))
+}
+
+// fetchGopher fetches a Gopher item like navigate does, reporting how much
+// has arrived every streamInterval. Esc abandons it through cancelFetch.
+func (m *Model) fetchGopher(client contextFetcher, urlStr string, attempt, fetchID int) tea.Cmd {
+	ctx, cancel := context.WithCancel(context.Background())
+	m.stopFetch, m.stopFetchID = cancel, fetchID
+	events := make(chan tea.Msg)
+	go func() {
+		defer cancel()
+		release := m.scheduler.Acquire(urlutil.Host(urlStr), scheduler.Interactive)
+		start := time.Now()
+		var answered time.Duration
+		last := start
+		resp, err := client.FetchContext(ctx, urlStr, func(partial *types.Response) {
+			if answered == 0 {
+				answered = time.Since(start)
+			}
+			if time.Since(last) < streamInterval {
+				return
+			}
+			last = time.Now()
+			events <- streamUpdateMsg{fetchID: fetchID, resp: partial, protocol: "gopher", events: events}
+		})
+		if answered == 0 {
+			answered = time.Since(start)
+		}
+		m.recordLatency(urlStr, answered, err)
+		release()
+		events <- fetchCompleteMsg{resp: resp, err: err, protocol: "gopher", fromCache: false, url: urlStr, attempt: attempt, fetchID: fetchID}
+	}()
+	return waitForStream(events)
+}
+
+// showProgress reports how much of an item has arrived so far
+func (m *Model) showProgress(resp *types.Response) {
+	m.statusBar.SetProgress(fmt.Sprintf("Receiving %s... %d KiB (Esc cancels)", resp.URL, len(resp.Body)>>10))
+}
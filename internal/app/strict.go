@@ -0,0 +1,37 @@
+package app
+
+import "fmt"
+
+// toggleStrictMode turns reporting of Gemini protocol violations on or off
+func (m *Model) toggleStrictMode() {
+	m.strictMode = !m.strictMode
+	if !m.strictMode {
+		m.statusBar.SetMessage("Strict mode off")
+		return
+	}
+	if m.currentDoc == nil || len(m.currentDoc.Violations) == 0 {
+		m.statusBar.SetMessage("Strict mode on: no protocol violations on this page")
+		return
+	}
+	m.flagViolations()
+}
+
+// recordViolations stores the protocol violations found while loading the
+// current page, including those of any redirects that led to it
+func (m *Model) recordViolations(found []string) {
+	m.currentDoc.Violations = append(m.redirectViolations, found...)
+	m.redirectViolations = nil
+	if m.strictMode && len(m.currentDoc.Violations) > 0 {
+		m.flagViolations()
+	}
+}
+
+// flagViolations points out the current page's violations in the status bar
+func (m *Model) flagViolations() {
+	count := len(m.currentDoc.Violations)
+	noun := "violations"
+	if count == 1 {
+		noun = "violation"
+	}
+	m.statusBar.SetError(fmt.Sprintf("Strict mode: %d protocol %s on this page (I for details)", count, noun))
+}
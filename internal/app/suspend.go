@@ -0,0 +1,29 @@
+package app
+
+import (
+	"os"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+)
+
+// suspend hands the terminal back to the shell. The session is saved first
+// in case the browser is never brought back to the foreground.
+func (m *Model) suspend() tea.Cmd {
+	m.saveSession()
+	m.saveCache()
+	return tea.Suspend
+}
+
+// resume restores what the terminal lost while the browser was stopped or
+// its SSH connection dropped: mouse reporting is re-enabled, the color
+// support of the terminal now attached is detected again and the page is
+// re-rendered with it, and the screen size is checked. The background
+// color is not asked for again, as the answer would be read as input.
+func (m *Model) resume() tea.Cmd {
+	detected := lipgloss.NewRenderer(os.Stdout)
+	lipgloss.SetColorProfile(detected.ColorProfile())
+	m.resizeViewport()
+
+	return tea.Batch(tea.EnableMouseAllMotion, tea.WindowSize(), tea.ClearScreen)
+}
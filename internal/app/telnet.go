@@ -0,0 +1,93 @@
+package app
+
+import (
+	"fmt"
+	"os/exec"
+	"strings"
+	"unicode"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"starsearch/internal/gopher"
+	"starsearch/internal/ui"
+)
+
+// sessionEndedMsg reports that a telnet command started for a link exited
+type sessionEndedMsg struct {
+	session gopher.Session
+	err     error
+}
+
+// sessionConfirmedMsg starts a terminal session the user agreed to
+type sessionConfirmedMsg struct{ session gopher.Session }
+
+// openSession handles a link to a telnet, TN3270 or CSO server, which
+// cannot be fetched. Terminal sessions run the configured telnet command
+// once the user agrees; without one, or for CSO, the address is copied
+// for use elsewhere.
+func (m *Model) openSession(session gopher.Session) tea.Cmd {
+	command := m.config.Get().General.TelnetCommand
+	if session.Kind != "cso" && command != "" {
+		cmd, err := sessionCommand(command, session)
+		if err != nil {
+			m.statusBar.SetError(fmt.Sprintf("Cannot open %s: %v", session.Description(), err))
+			return nil
+		}
+		m.confirm("Start a terminal session?",
+			fmt.Sprintf("The link opens a %s by running:\n\n%s", session.Description(), strings.Join(cmd.Args, " ")),
+			"Start", sessionConfirmedMsg{session: session})
+		return nil
+	}
+
+	if err := ui.WriteClipboard(session.Address()); err != nil {
+		m.statusBar.SetError(fmt.Sprintf("Cannot open %s: %v", session.Description(), err))
+		return nil
+	}
+	if session.Kind == "cso" {
+		m.statusBar.SetMessage("CSO phone books are not supported; copied " + session.Address())
+	} else {
+		m.statusBar.SetMessage("Copied " + session.Address() + " (set general.telnet_command to connect from here)")
+	}
+	return nil
+}
+
+// startSession runs the telnet command for a session the user confirmed
+func (m *Model) startSession(session gopher.Session) tea.Cmd {
+	cmd, err := sessionCommand(m.config.Get().General.TelnetCommand, session)
+	if err != nil {
+		m.statusBar.SetError(fmt.Sprintf("Cannot open %s: %v", session.Description(), err))
+		return nil
+	}
+	m.statusBar.SetMessage("Starting " + session.Description() + "...")
+	return tea.ExecProcess(cmd, func(err error) tea.Msg {
+		return sessionEndedMsg{session: session, err: err}
+	})
+}
+
+// sessionCommand builds the command for a session from a template such as
+// "telnet {host} {port}". {user} is the suggested login name, often empty.
+// The values come from the link, so ones a command could take as options,
+// such as a login name of -oProxyCommand=... for ssh, are refused.
+func sessionCommand(template string, session gopher.Session) (*exec.Cmd, error) {
+	for _, field := range []struct{ name, value string }{
+		{"host", session.Host}, {"port", session.Port}, {"login name", session.User},
+	} {
+		if strings.HasPrefix(field.value, "-") {
+			return nil, fmt.Errorf("its %s %q could be taken as an option", field.name, field.value)
+		}
+		if strings.ContainsFunc(field.value, func(r rune) bool { return unicode.IsSpace(r) || unicode.IsControl(r) }) {
+			return nil, fmt.Errorf("its %s contains spaces or control characters", field.name)
+		}
+	}
+
+	replacer := strings.NewReplacer("{host}", session.Host, "{port}", session.Port, "{user}", session.User)
+	var args []string
+	for _, field := range strings.Fields(template) {
+		if arg := replacer.Replace(field); arg != "" {
+			args = append(args, arg)
+		}
+	}
+	if len(args) == 0 {
+		return nil, fmt.Errorf("general.telnet_command is empty")
+	}
+	return exec.Command(args[0], args[1:]...), nil
+}
@@ -0,0 +1,193 @@
+package app
+
+import (
+	"errors"
+	"fmt"
+	"mime"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"starsearch/internal/gemini"
+	"starsearch/internal/scheduler"
+	"starsearch/internal/types"
+	"starsearch/internal/ui"
+	"starsearch/internal/urlutil"
+)
+
+// uploader is implemented by clients that can publish with Titan
+type uploader interface {
+	Upload(upload gemini.TitanUpload) (*types.Response, error)
+}
+
+// titanEditedMsg reports that the editor writing content to upload exited
+type titanEditedMsg struct {
+	path string
+	err  error
+}
+
+// titanDoneMsg carries the capsule's reply to an upload
+type titanDoneMsg struct {
+	url  string
+	size int
+	resp *types.Response
+	err  error
+}
+
+// openTitan opens the upload form for target, or for the titan:// side of
+// the current page when target is empty
+func (m *Model) openTitan(target string) tea.Cmd {
+	if target == "" {
+		if u, err := url.Parse(m.currentURL); err == nil && u.Scheme == "gemini" {
+			u.Scheme = "titan"
+			u.RawQuery = ""
+			u.Fragment = ""
+			target = u.String()
+		}
+	}
+	m.titanBuffer = nil
+	cmd := m.titanModal.Show(target, "")
+	ui.OpenModal(m.modals, m.titanModal)
+	return cmd
+}
+
+// editTitan opens what would be uploaded, the file or the current page, in
+// the editor; the text saved there is uploaded in its place
+func (m *Model) editTitan(msg ui.TitanEditMsg) tea.Cmd {
+	text := string(m.titanBuffer)
+	if !msg.Edited {
+		body, err := m.titanSource(msg.File)
+		if err != nil && !errors.Is(err, os.ErrNotExist) {
+			m.titanModal.SetError(err.Error())
+			return nil
+		}
+		text = string(body)
+	}
+	return m.openEditor(text, func(path string, err error) tea.Msg {
+		return titanEditedMsg{path: path, err: err}
+	})
+}
+
+// handleTitanEdited keeps the text written in the editor for the upload
+func (m *Model) handleTitanEdited(msg titanEditedMsg) {
+	defer os.Remove(msg.path)
+	if msg.err != nil {
+		m.titanModal.SetError(fmt.Sprintf("Editor failed: %v", msg.err))
+		return
+	}
+	data, err := os.ReadFile(msg.path)
+	if err != nil {
+		m.titanModal.SetError(fmt.Sprintf("Failed to read the edited text: %v", err))
+		return
+	}
+	m.titanBuffer = data
+	m.titanModal.SetEdited(len(data))
+}
+
+// titanSource reads the content to upload: file, or the current page's
+// source when file is empty
+func (m *Model) titanSource(file string) ([]byte, error) {
+	if file == "" {
+		if m.currentDoc == nil {
+			return nil, fmt.Errorf("no page to upload; choose a file")
+		}
+		return m.currentDoc.RawBody, nil
+	}
+	return os.ReadFile(expandHome(file))
+}
+
+// titanMIME picks the media type of an upload left without one: the
+// file's, guessed from its extension, or the current page's
+func (m *Model) titanMIME(file string) string {
+	if file == "" {
+		if m.currentDoc != nil {
+			if mediaType, _, err := mime.ParseMediaType(m.currentDoc.Metadata.Meta); err == nil {
+				return mediaType
+			}
+		}
+		return "text/gemini"
+	}
+	switch ext := strings.ToLower(filepath.Ext(file)); ext {
+	case ".gmi", ".gemini":
+		return "text/gemini"
+	default:
+		if mediaType := mime.TypeByExtension(ext); mediaType != "" {
+			return mediaType
+		}
+	}
+	return "application/octet-stream"
+}
+
+// upload sends the content chosen in the upload form
+func (m *Model) upload(msg ui.TitanUploadMsg) tea.Cmd {
+	client, ok := m.client.(uploader)
+	if !ok {
+		m.titanModal.SetError("Uploads are not supported by this client")
+		return nil
+	}
+
+	body := m.titanBuffer
+	if !msg.Edited {
+		var err error
+		if body, err = m.titanSource(msg.File); err != nil {
+			m.titanModal.SetError(fmt.Sprintf("Failed to read %s: %v", msg.File, err))
+			return nil
+		}
+	}
+	mediaType := msg.MIME
+	if mediaType == "" && !msg.Edited {
+		mediaType = m.titanMIME(msg.File)
+	}
+
+	upload := gemini.TitanUpload{URL: msg.URL, Body: body, MIME: mediaType, Token: msg.Token}
+	m.statusBar.SetMessage(fmt.Sprintf("Uploading %d bytes to %s...", len(body), msg.URL))
+	return func() tea.Msg {
+		release := m.scheduler.Acquire(urlutil.Host(upload.URL), scheduler.Interactive)
+		defer release()
+		resp, err := client.Upload(upload)
+		return titanDoneMsg{url: upload.URL, size: len(body), resp: resp, err: err}
+	}
+}
+
+// handleTitanDone reports how an upload went. The page written is shown
+// once the capsule accepts it; on failure the form stays open.
+func (m *Model) handleTitanDone(msg titanDoneMsg) tea.Cmd {
+	if msg.err != nil {
+		m.titanModal.SetError(fmt.Sprintf("Upload failed: %v", msg.err))
+		m.statusBar.SetError(fmt.Sprintf("Upload to %s failed: %v", msg.url, msg.err))
+		return nil
+	}
+
+	resp := msg.resp
+	page := gemini.GeminiURL(msg.url)
+	switch {
+	case gemini.IsRedirectStatus(resp.Status):
+		base, err := url.Parse(page)
+		target, targetErr := url.Parse(resp.Meta)
+		if err == nil && targetErr == nil {
+			page = base.ResolveReference(target).String()
+		}
+	case gemini.IsSuccessStatus(resp.Status):
+	default:
+		reason := fmt.Sprintf("%d %s", resp.Status, gemini.GetStatusMessage(resp.Status))
+		if resp.Meta != "" {
+			reason += ": " + resp.Meta
+		}
+		if gemini.IsCertificateRequired(resp.Status) {
+			reason += " (scope an identity to the capsule with Shift+I)"
+		}
+		m.titanModal.SetError("Upload refused: " + reason)
+		m.statusBar.SetError(fmt.Sprintf("Upload to %s refused: %s", msg.url, reason))
+		return nil
+	}
+
+	m.titanBuffer = nil
+	m.titanModal.Hide()
+	if m.pageCache != nil {
+		m.pageCache.Invalidate(page)
+	}
+	m.statusBar.SetMessage(fmt.Sprintf("Uploaded %d bytes to %s", msg.size, msg.url))
+	return m.navigate(page)
+}
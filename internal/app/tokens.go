@@ -0,0 +1,56 @@
+package app
+
+import (
+	"net/url"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"starsearch/internal/ui"
+	"starsearch/internal/urlutil"
+)
+
+// openPrivacy shows the capsule tokens modal for the current page's host
+func (m *Model) openPrivacy() {
+	host := ""
+	if u, err := url.Parse(m.currentURL); err == nil && u.Scheme == "gemini" {
+		host = u.Hostname()
+	}
+	m.privacyModal.Show(m.tokens.List(), host)
+	ui.OpenModal(m.modals, m.privacyModal)
+}
+
+// handleTokenAction carries out an action chosen in the capsule tokens modal
+func (m *Model) handleTokenAction(msg tea.Msg) {
+	switch msg := msg.(type) {
+	case ui.TokenConsentMsg:
+		if msg.Allow {
+			m.tokens.Allow(msg.Host)
+			m.statusBar.SetMessage(msg.Host + " may keep a token; it is captured from its next redirect with a query")
+		} else {
+			m.tokens.Revoke(msg.Host)
+			m.statusBar.SetMessage(msg.Host + " no longer keeps a token")
+		}
+	case ui.TokenRevokeMsg:
+		m.tokens.Revoke(msg.Host)
+		m.statusBar.SetMessage("Revoked the token of " + msg.Host)
+	}
+	m.privacyModal.Refresh(m.tokens.List())
+}
+
+// captureToken remembers the query of a redirect target as its host's
+// token, for hosts allowed to keep one
+func (m *Model) captureToken(fromURL, target string) {
+	base, err := url.Parse(fromURL)
+	if err != nil {
+		return
+	}
+	ref, err := url.Parse(target)
+	if err != nil {
+		return
+	}
+	resolved := base.ResolveReference(ref)
+	// A token only goes back to the capsule that issued it
+	if resolved.Scheme != "gemini" || resolved.Hostname() != urlutil.Host(fromURL) {
+		return
+	}
+	m.tokens.Capture(resolved.String())
+}
@@ -0,0 +1,121 @@
+package app
+
+import (
+	"bytes"
+	"fmt"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"starsearch/internal/scheduler"
+	"starsearch/internal/types"
+)
+
+// watchIntervals are the auto-reload intervals, in seconds, that cycling
+// the watch on a tab steps through before turning it off again
+var watchIntervals = []int{30, 60, 300}
+
+// watchTickMsg fires every second while any tab is watched
+type watchTickMsg struct{}
+
+// watchTick schedules the next countdown step
+func watchTick() tea.Cmd {
+	return tea.Tick(time.Second, func(time.Time) tea.Msg {
+		return watchTickMsg{}
+	})
+}
+
+// cycleWatch steps the active tab's auto-reload interval through
+// watchIntervals and then off
+func (m *Model) cycleWatch() tea.Cmd {
+	tab := m.tabBar.GetActiveTab()
+	if tab == nil || m.currentURL == "" {
+		return nil
+	}
+
+	next := 0
+	for i, interval := range watchIntervals {
+		if tab.WatchInterval == interval && i+1 < len(watchIntervals) {
+			next = watchIntervals[i+1]
+		}
+	}
+	if tab.WatchInterval == 0 {
+		next = watchIntervals[0]
+	}
+
+	m.tabBar.SetWatch(m.tabBar.GetActiveIndex(), next)
+	if next == 0 {
+		m.statusBar.SetMessage("Stopped watching this page")
+		return nil
+	}
+	m.statusBar.SetMessage(fmt.Sprintf("Watching this page: reloading every %s", time.Duration(next)*time.Second))
+
+	// One ticker serves every watched tab
+	if m.watchTicking {
+		return nil
+	}
+	m.watchTicking = true
+	return watchTick()
+}
+
+// handleWatchTick counts down the watched tabs and reloads those due
+func (m *Model) handleWatchTick() tea.Cmd {
+	due := m.tabBar.CountDownWatches()
+	if !m.tabBar.HasWatches() {
+		m.watchTicking = false
+		return nil
+	}
+
+	cmds := []tea.Cmd{watchTick()}
+	tabs := m.tabBar.GetTabs()
+	for _, id := range due {
+		idx := m.tabBar.IndexOf(id)
+		// A reload still in flight is not stacked with another
+		if tabs[idx].URL == "" || tabs[idx].Loading {
+			continue
+		}
+		m.tabBar.SetTabStatus(idx, true, false)
+		cmds = append(cmds, m.fetchIntoTab(id, tabs[idx].URL, nil, scheduler.Background, fetchWatch))
+	}
+	return tea.Batch(cmds...)
+}
+
+// applyWatchReload shows the reloaded page of a watched tab. Unchanged
+// content is left alone so the scroll position holds; changed content
+// replaces it and, in the active tab, the view jumps to the first change.
+func (m *Model) applyWatchReload(idx int, doc *types.Document) {
+	m.tabBar.SetTabStatus(idx, false, false)
+	tab := m.tabBar.GetTabs()[idx]
+	if tab.URL != doc.URL {
+		// The tab moved on to another page while the reload was running
+		return
+	}
+	if tab.Document != nil && bytes.Equal(tab.Document.RawBody, doc.RawBody) {
+		return
+	}
+
+	first := firstChangedLine(tab.Document, doc)
+	m.tabBar.UpdateTab(idx, doc.URL, pageTitle(doc), doc, tab.Scroll)
+	if idx != m.tabBar.GetActiveIndex() {
+		m.statusBar.SetMessage(fmt.Sprintf("Tab %d changed: %s", idx+1, pageTitle(doc)))
+		return
+	}
+
+	m.loadTabState()
+	m.viewport.ScrollToLine(first)
+	m.statusBar.SetMessage(fmt.Sprintf("Page changed at line %d", first+1))
+}
+
+// firstChangedLine returns the index of the first line of doc that differs
+// from old
+func firstChangedLine(old, doc *types.Document) int {
+	if old == nil {
+		return 0
+	}
+	for i, line := range doc.Lines {
+		if i >= len(old.Lines) || old.Lines[i].Raw != line.Raw {
+			return i
+		}
+	}
+	// Lines were only removed from the end
+	return max(len(doc.Lines)-1, 0)
+}
@@ -0,0 +1,88 @@
+package app
+
+import (
+	"fmt"
+	"strings"
+
+	"starsearch/internal/gemini"
+	"starsearch/internal/types"
+)
+
+// welcomeURL is the tour opened on the first launch
+const welcomeURL = "about:welcome"
+
+// tourStep is one thing the welcome tour asks the user to try. It is
+// checked off when any of its keys is pressed.
+type tourStep struct {
+	task string
+	how  string
+	keys []string
+}
+
+// tourSteps are the welcome tour's steps, in the order they are listed
+var tourSteps = []tourStep{
+	{"Scroll the page", "j or ↓ scrolls down, k or ↑ up, Space a page at a time", []string{"j", "down", "pgdown", " "}},
+	{"Enter link mode", "g then a link's number follows it; clicking works too", []string{"g"}},
+	{"Go back", "h, ← or Alt+← returns to the previous page (and to this tour)", []string{"h", "left", "alt+left"}},
+	{"Open the address bar", "Ctrl+L; type a URL, or words to search", []string{"ctrl+l"}},
+	{"Open a new tab", "Ctrl+T; Ctrl+W closes it, 1-9 switch between tabs", []string{"ctrl+t"}},
+	{"Bookmark a page", "d adds the page to your bookmarks, b lists them", []string{"d"}},
+	{"See every key", "? shows the help", []string{"?"}},
+}
+
+// welcomePage renders the tour with the steps done so far checked off
+func welcomePage(done []bool) []byte {
+	var b strings.Builder
+	b.WriteString("# Welcome to starsearch\n\n")
+	b.WriteString("Starsearch is a terminal browser for Gemini and Gopher. Try each of these; they are checked off as you do.\n\n")
+
+	count := 0
+	for i, step := range tourSteps {
+		mark := "[ ]"
+		if done[i] {
+			mark = "[x]"
+			count++
+		}
+		fmt.Fprintf(&b, "* %s %s: %s\n", mark, step.task, step.how)
+	}
+	b.WriteString("\n")
+	if count == len(tourSteps) {
+		b.WriteString("All done. Enjoy Geminispace!\n\n")
+	} else {
+		fmt.Fprintf(&b, "%d of %d done. This tour stays at %s.\n\n", count, len(tourSteps), welcomeURL)
+	}
+
+	b.WriteString("## Where to next\n\n")
+	fmt.Fprintf(&b, "=> %s Discover Geminispace\n", discoverURL)
+	b.WriteString("=> about:stats Your reading statistics\n")
+
+	return []byte(b.String())
+}
+
+// observeTour checks off the tour steps that key completes. The tour is
+// re-rendered in place if it is being shown.
+func (m *Model) observeTour(key string) {
+	changed := false
+	for i, step := range tourSteps {
+		if m.tourDone[i] {
+			continue
+		}
+		for _, k := range step.keys {
+			if k == key {
+				m.tourDone[i] = true
+				changed = true
+			}
+		}
+	}
+	if !changed || m.currentURL != welcomeURL {
+		return
+	}
+
+	resp := &types.Response{Status: 20, Meta: "text/gemini; charset=utf-8", Body: welcomePage(m.tourDone), URL: welcomeURL}
+	doc, err := gemini.NewParser(welcomeURL).Parse(resp)
+	if err != nil {
+		return
+	}
+	m.currentDoc = doc
+	m.viewport.GrowDocument(doc)
+}
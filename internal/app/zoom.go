@@ -0,0 +1,49 @@
+package app
+
+import (
+	"fmt"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"starsearch/internal/types"
+	"starsearch/internal/ui"
+)
+
+// zoomNames describe each zoom level
+var zoomNames = map[int]string{
+	-2: "compact, no blank lines",
+	-1: "compact",
+	0:  "normal",
+	1:  "spacious, 100 columns",
+	2:  "spacious, 72 columns",
+}
+
+// zoom changes the content density by step levels and saves it to the
+// config, so it is kept for the next launch
+func (m *Model) zoom(step int) tea.Cmd {
+	level := m.viewport.Zoom() + step
+	if level < ui.MinZoom || level > ui.MaxZoom {
+		return m.notify(fmt.Sprintf("Zoom %+d is the limit", m.viewport.Zoom()))
+	}
+
+	m.viewport.SetZoom(level)
+	m.config.Get().UI.Zoom = level
+	if err := m.config.Save(); err != nil {
+		m.statusBar.SetError(fmt.Sprintf("Failed to save zoom level: %v", err))
+	}
+	return m.notify(fmt.Sprintf("Zoom %+d: %s", level, zoomNames[level]))
+}
+
+// typography reads the page spacing settings. Margins must be pairs of
+// blank lines above and below, neither negative; others are left at the
+// defaults.
+func typography(settings types.UIConfig) ui.Typography {
+	typography := ui.DefaultTypography()
+	for level, margin := range [][]int{settings.H1Margin, settings.H2Margin, settings.H3Margin} {
+		if len(margin) == 2 && margin[0] >= 0 && margin[1] >= 0 {
+			typography.HeadingMargins[level] = [2]int{margin[0], margin[1]}
+		}
+	}
+	typography.MaxBlankLines = settings.MaxBlankLines
+	typography.BodyIndent = max(settings.IndentUnderHeadings, 0)
+	return typography
+}
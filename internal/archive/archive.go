@@ -0,0 +1,184 @@
+// Package archive lists and extracts the entries of downloaded zip and
+// tar archives, plain or gzip-compressed.
+package archive
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"bytes"
+	"compress/gzip"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+)
+
+// ErrNotArchive is returned for files that are not a supported archive
+var ErrNotArchive = errors.New("not a zip or tar archive")
+
+// ErrNoEntry is returned when extracting a name the archive lacks
+var ErrNoEntry = errors.New("no such entry in the archive")
+
+// Entry is a file or directory stored in an archive
+type Entry struct {
+	Name string // Path within the archive
+	Size int64  // Uncompressed size in bytes
+	Dir  bool
+}
+
+// format is the kind of an archive file
+type format int
+
+const (
+	unknown format = iota
+	zipFormat
+	tarFormat
+	tarGzipFormat
+)
+
+// detect reads the start of the file at path to tell its format
+func detect(path string) (format, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return unknown, err
+	}
+	defer f.Close()
+
+	head := readHead(f)
+	switch {
+	case bytes.HasPrefix(head, []byte("PK\x03\x04")), bytes.HasPrefix(head, []byte("PK\x05\x06")):
+		return zipFormat, nil
+	case isTar(head):
+		return tarFormat, nil
+	case bytes.HasPrefix(head, []byte{0x1f, 0x8b}):
+		// Only a compressed tar, not any gzip file
+		if _, err := f.Seek(0, io.SeekStart); err != nil {
+			return unknown, err
+		}
+		gz, err := gzip.NewReader(f)
+		if err != nil {
+			return unknown, nil
+		}
+		defer gz.Close()
+		if isTar(readHead(gz)) {
+			return tarGzipFormat, nil
+		}
+	}
+	return unknown, nil
+}
+
+// readHead returns up to the first tar block of r
+func readHead(r io.Reader) []byte {
+	head := make([]byte, 512)
+	n, _ := io.ReadFull(r, head)
+	return head[:n]
+}
+
+// isTar reports whether head starts with a tar header
+func isTar(head []byte) bool {
+	return len(head) >= 262 && string(head[257:262]) == "ustar"
+}
+
+// IsArchive reports whether the file at path is a supported archive
+func IsArchive(path string) bool {
+	f, err := detect(path)
+	return err == nil && f != unknown
+}
+
+// List returns the entries of the archive at path, in stored order
+func List(path string) ([]Entry, error) {
+	var entries []Entry
+	err := walk(path, func(entry Entry, _ io.Reader) (bool, error) {
+		entries = append(entries, entry)
+		return false, nil
+	})
+	return entries, err
+}
+
+// Extract copies the entry called name out of the archive at path to w
+func Extract(path, name string, w io.Writer) error {
+	found := false
+	err := walk(path, func(entry Entry, contents io.Reader) (bool, error) {
+		if entry.Name != name || entry.Dir {
+			return false, nil
+		}
+		found = true
+		_, err := io.Copy(w, contents)
+		return true, err
+	})
+	if err == nil && !found {
+		err = fmt.Errorf("%s: %w", name, ErrNoEntry)
+	}
+	return err
+}
+
+// walk calls visit with each entry of the archive at path and a reader of
+// its contents, until visit returns true or an error
+func walk(path string, visit func(Entry, io.Reader) (bool, error)) error {
+	kind, err := detect(path)
+	if err != nil {
+		return err
+	}
+
+	switch kind {
+	case zipFormat:
+		archive, err := zip.OpenReader(path)
+		if err != nil {
+			return fmt.Errorf("failed to read zip archive: %w", err)
+		}
+		defer archive.Close()
+		for _, file := range archive.File {
+			entry := Entry{Name: file.Name, Size: int64(file.UncompressedSize64), Dir: file.FileInfo().IsDir()}
+			var contents io.ReadCloser = io.NopCloser(bytes.NewReader(nil))
+			if !entry.Dir {
+				if contents, err = file.Open(); err != nil {
+					return fmt.Errorf("failed to read %s: %w", file.Name, err)
+				}
+			}
+			done, err := visit(entry, contents)
+			contents.Close()
+			if done || err != nil {
+				return err
+			}
+		}
+		return nil
+
+	case tarFormat, tarGzipFormat:
+		f, err := os.Open(path)
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+
+		var r io.Reader = f
+		if kind == tarGzipFormat {
+			gz, err := gzip.NewReader(f)
+			if err != nil {
+				return fmt.Errorf("failed to decompress archive: %w", err)
+			}
+			defer gz.Close()
+			r = gz
+		}
+
+		archive := tar.NewReader(r)
+		for {
+			header, err := archive.Next()
+			if err == io.EOF {
+				return nil
+			}
+			if err != nil {
+				return fmt.Errorf("failed to read tar archive: %w", err)
+			}
+			switch header.Typeflag {
+			case tar.TypeReg, tar.TypeDir:
+			default:
+				continue // Links and devices have no contents to extract
+			}
+			entry := Entry{Name: header.Name, Size: header.Size, Dir: header.Typeflag == tar.TypeDir}
+			if done, err := visit(entry, archive); done || err != nil {
+				return err
+			}
+		}
+	}
+	return ErrNotArchive
+}
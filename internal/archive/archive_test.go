@@ -0,0 +1,91 @@
+package archive
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"bytes"
+	"compress/gzip"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func writeZip(t *testing.T, path string) {
+	t.Helper()
+	var buf bytes.Buffer
+	w := zip.NewWriter(&buf)
+	w.Create("docs/")
+	f, _ := w.Create("docs/readme.txt")
+	f.Write([]byte("hello"))
+	if err := w.Close(); err != nil {
+		t.Fatal(err)
+	}
+	os.WriteFile(path, buf.Bytes(), 0644)
+}
+
+func writeTarGz(t *testing.T, path string) {
+	t.Helper()
+	var buf bytes.Buffer
+	gz := gzip.NewWriter(&buf)
+	w := tar.NewWriter(gz)
+	w.WriteHeader(&tar.Header{Name: "src/", Typeflag: tar.TypeDir, Mode: 0755})
+	w.WriteHeader(&tar.Header{Name: "src/main.go", Typeflag: tar.TypeReg, Mode: 0644, Size: 12})
+	w.Write([]byte("package main"))
+	w.Close()
+	gz.Close()
+	os.WriteFile(path, buf.Bytes(), 0644)
+}
+
+func TestListAndExtract(t *testing.T) {
+	dir := t.TempDir()
+	zipPath := filepath.Join(dir, "a.zip")
+	tgzPath := filepath.Join(dir, "b.tar.gz")
+	writeZip(t, zipPath)
+	writeTarGz(t, tgzPath)
+
+	for _, tt := range []struct {
+		path, file, contents string
+		size                 int64
+	}{
+		{zipPath, "docs/readme.txt", "hello", 5},
+		{tgzPath, "src/main.go", "package main", 12},
+	} {
+		entries, err := List(tt.path)
+		if err != nil {
+			t.Fatalf("List(%s): %v", tt.path, err)
+		}
+		if len(entries) != 2 || !entries[0].Dir || entries[1].Name != tt.file || entries[1].Size != tt.size {
+			t.Errorf("List(%s) = %+v", tt.path, entries)
+		}
+
+		var out bytes.Buffer
+		if err := Extract(tt.path, tt.file, &out); err != nil || out.String() != tt.contents {
+			t.Errorf("Extract(%s) = %q, %v", tt.file, out.String(), err)
+		}
+		if err := Extract(tt.path, "missing", &out); !errors.Is(err, ErrNoEntry) {
+			t.Errorf("extracting a missing entry: %v", err)
+		}
+	}
+}
+
+func TestIsArchive(t *testing.T) {
+	dir := t.TempDir()
+	zipPath := filepath.Join(dir, "a.zip")
+	writeZip(t, zipPath)
+
+	// A compressed file that is not a tar is not an archive
+	var buf bytes.Buffer
+	gz := gzip.NewWriter(&buf)
+	gz.Write([]byte("just some text"))
+	gz.Close()
+	gzPath := filepath.Join(dir, "notes.txt.gz")
+	os.WriteFile(gzPath, buf.Bytes(), 0644)
+
+	textPath := filepath.Join(dir, "notes.txt")
+	os.WriteFile(textPath, []byte("plain"), 0644)
+
+	if !IsArchive(zipPath) || IsArchive(gzPath) || IsArchive(textPath) {
+		t.Errorf("IsArchive: zip %v, gzip %v, text %v", IsArchive(zipPath), IsArchive(gzPath), IsArchive(textPath))
+	}
+}
@@ -1,35 +1,60 @@
 package cache
 
 import (
+	"container/list"
 	"crypto/sha256"
 	"encoding/hex"
+	"os"
+	"path/filepath"
+	"sort"
 	"sync"
 	"time"
 
 	"starsearch/internal/types"
 )
 
+// TTLs of cached responses other than pages, in seconds
+const (
+	RedirectTTL = 24 * 60 * 60 // 31 permanent redirects are followed without a round trip
+	NotFoundTTL = 60           // 51 Not Found is remembered briefly for repeat visits
+)
+
+// entryOverhead approximates the bytes an entry costs beyond its URL, meta
+// and body: the entry itself, its key and its place in the map and list
+const entryOverhead = 256
+
 // CacheEntry represents a cached page
 type CacheEntry struct {
 	URL       string
 	Response  *types.Response
 	Timestamp int64
 	TTL       int64 // Time to live in seconds
+
+	size     int64         // Bytes counted against the cache size
+	element  *list.Element // Position in the recency list
+	bodyHash string        // Names the body's file, for a cache on disk
 }
 
-// Cache manages page caching
+// Cache manages page caching. When full it evicts the least recently used
+// entries to make room. A cache created with NewDiskCache also keeps its
+// entries on disk.
 type Cache struct {
 	entries    map[string]*CacheEntry
+	recency    *list.List // Keys, most recently used first
 	mutex      sync.RWMutex
 	maxSize    int64 // Maximum cache size in bytes
 	currentSize int64 // Current cache size in bytes
 	defaultTTL int64 // Default TTL in seconds
+	evictions  int64 // Entries evicted to make room
+	onEvict    func(count int)
+	dir        string // Where entries are persisted; empty keeps them in memory only
 }
 
 // NewCache creates a new cache
 func NewCache(maxSizeMB int, defaultTTLSeconds int64) *Cache {
 	return &Cache{
 		entries:    make(map[string]*CacheEntry),
+		recency:    list.New(),
 		maxSize:    int64(maxSizeMB) * 1024 * 1024,
 		currentSize: 0,
 		defaultTTL: defaultTTLSeconds,
@@ -38,8 +63,8 @@ func NewCache(maxSizeMB int, defaultTTLSeconds int64) *Cache {
 
 // Get retrieves a cached entry if it exists and is still valid
 func (c *Cache) Get(url string) (*types.Response, bool) {
-	c.mutex.RLock()
-	defer c.mutex.RUnlock()
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
 
 	key := c.key(url)
 	entry, exists := c.entries[key]
@@ -54,42 +79,61 @@ func (c *Cache) Get(url string) (*types.Response, bool) {
 		return nil, false
 	}
 
+	c.recency.MoveToFront(entry.element)
 	return entry.Response, true
 }
 
+// OnEvict sets a function called with the number of entries evicted
+// whenever storing a response pushes others out
+func (c *Cache) OnEvict(fn func(count int)) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	c.onEvict = fn
+}
+
+// Evictions returns how many entries have been evicted to make room
+func (c *Cache) Evictions() int64 {
+	c.mutex.RLock()
+	defer c.mutex.RUnlock()
+	return c.evictions
+}
+
 // Set stores a response in the cache
 func (c *Cache) Set(url string, resp *types.Response, ttl int64) {
 	if resp == nil {
 		return
 	}
 
-	// Only cache text/gemini and text/plain responses
-	if resp.Meta != "text/gemini" && resp.Meta != "text/plain" {
+	// Only cache text/gemini and text/plain pages, permanent redirects and
+	// Not Found
+	switch {
+	case resp.Status == 31:
+		ttl = RedirectTTL
+	case resp.Status == 51:
+		ttl = NotFoundTTL
+	case resp.Meta != "text/gemini" && resp.Meta != "text/plain":
 		return
 	}
 
 	c.mutex.Lock()
-	defer c.mutex.Unlock()
 
 	key := c.key(url)
-	entrySize := int64(len(resp.Body))
+	size := entrySize(url, resp)
 
 	// Remove old entry if exists
-	if oldEntry, exists := c.entries[key]; exists {
-		c.currentSize -= int64(len(oldEntry.Response.Body))
-		delete(c.entries, key)
+	if _, exists := c.entries[key]; exists {
+		c.remove(key)
 	}
 
-	// Check if we need to evict entries to make room
-	if c.currentSize+entrySize > c.maxSize {
-		c.evictOldest()
-	}
-
-	// If still too large, don't cache
-	if c.currentSize+entrySize > c.maxSize {
+	// An entry bigger than the whole cache is never stored
+	if size > c.maxSize {
+		c.mutex.Unlock()
 		return
 	}
 
+	evicted := c.evictLRU(size)
+	onEvict := c.onEvict
+
 	if ttl <= 0 {
 		ttl = c.defaultTTL
 	}
@@ -99,10 +143,29 @@ func (c *Cache) Set(url string, resp *types.Response, ttl int64) {
 		Response:  resp,
 		Timestamp: time.Now().Unix(),
 		TTL:       ttl,
+		size:      size,
+		element:   c.recency.PushFront(key),
 	}
 
 	c.entries[key] = entry
-	c.currentSize += entrySize
+	c.currentSize += size
+	if c.dir != "" {
+		entry.bodyHash = bodyHash(resp.Body)
+		_ = c.writeBody(entry) // Ignore errors, the entry still serves from memory
+		_ = c.saveIndex()
+	}
+	c.mutex.Unlock()
+
+	// Report outside the lock, the hook may save to disk
+	if evicted > 0 && onEvict != nil {
+		onEvict(evicted)
+	}
+}
+
+// entrySize is what a response costs the cache: its body and meta, the URL
+// it is stored under and the fixed per-entry overhead
+func entrySize(url string, resp *types.Response) int64 {
+	return int64(len(resp.Body)+len(resp.Meta)+len(url)) + entryOverhead
 }
 
 // Clear removes all cached entries
@@ -111,7 +174,12 @@ func (c *Cache) Clear() {
 	defer c.mutex.Unlock()
 
 	c.entries = make(map[string]*CacheEntry)
+	c.recency.Init()
 	c.currentSize = 0
+	if c.dir != "" {
+		_ = os.RemoveAll(filepath.Join(c.dir, bodiesDir))
+		_ = c.saveIndex()
+	}
 }
 
 // Invalidate removes a specific URL from cache
@@ -119,23 +187,38 @@ func (c *Cache) Invalidate(url string) {
 	c.mutex.Lock()
 	defer c.mutex.Unlock()
 
-	key := c.key(url)
-	if entry, exists := c.entries[key]; exists {
-		c.currentSize -= int64(len(entry.Response.Body))
-		delete(c.entries, key)
+	c.remove(c.key(url))
+	_ = c.saveIndex()
+}
+
+// remove drops the entry under key, if any. The caller holds the lock.
+func (c *Cache) remove(key string) {
+	entry, exists := c.entries[key]
+	if !exists {
+		return
+	}
+	c.recency.Remove(entry.element)
+	c.currentSize -= entry.size
+	delete(c.entries, key)
+	if c.dir != "" {
+		c.removeBody(entry)
 	}
 }
 
-// evictOldest removes the oldest entries until we have enough space
-func (c *Cache) evictOldest() {
-	// Simple eviction: remove entries older than half the TTL
-	now := time.Now().Unix()
-	for key, entry := range c.entries {
-		if entry.Timestamp+entry.TTL/2 < now {
-			c.currentSize -= int64(len(entry.Response.Body))
-			delete(c.entries, key)
+// evictLRU removes the least recently used entries until size more bytes
+// fit, returning how many went. The caller holds the lock.
+func (c *Cache) evictLRU(size int64) int {
+	evicted := 0
+	for c.currentSize+size > c.maxSize {
+		oldest := c.recency.Back()
+		if oldest == nil {
+			break
 		}
+		c.remove(oldest.Value.(string))
+		evicted++
 	}
+	c.evictions += int64(evicted)
+	return evicted
 }
 
 // key generates a cache key from URL
@@ -151,6 +234,37 @@ func (c *Cache) GetSize() int64 {
 	return c.currentSize
 }
 
+// MaxSize returns the configured cache size cap in bytes
+func (c *Cache) MaxSize() int64 {
+	return c.maxSize
+}
+
+// Entries describes every cached response, expired ones included, most
+// recently stored first
+func (c *Cache) Entries() []types.CacheEntryInfo {
+	c.mutex.RLock()
+	defer c.mutex.RUnlock()
+
+	entries := make([]types.CacheEntryInfo, 0, len(c.entries))
+	for _, entry := range c.entries {
+		entries = append(entries, types.CacheEntryInfo{
+			URL:    entry.URL,
+			Status: entry.Response.Status,
+			Meta:   entry.Response.Meta,
+			Size:   entry.size,
+			Stored: time.Unix(entry.Timestamp, 0),
+			TTL:    time.Duration(entry.TTL) * time.Second,
+		})
+	}
+	sort.Slice(entries, func(i, j int) bool {
+		if !entries[i].Stored.Equal(entries[j].Stored) {
+			return entries[i].Stored.After(entries[j].Stored)
+		}
+		return entries[i].URL < entries[j].URL
+	})
+	return entries
+}
+
 // GetEntryCount returns the number of cached entries
 func (c *Cache) GetEntryCount() int {
 	c.mutex.RLock()
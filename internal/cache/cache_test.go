@@ -0,0 +1,182 @@
+package cache
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"starsearch/internal/types"
+)
+
+func TestCacheRedirectsAndNotFound(t *testing.T) {
+	c := NewCache(1, 300)
+
+	c.Set("gemini://example.org/old", &types.Response{Status: 31, Meta: "gemini://example.org/new"}, 300)
+	c.Set("gemini://example.org/temp", &types.Response{Status: 30, Meta: "gemini://example.org/new"}, 300)
+	c.Set("gemini://example.org/missing", &types.Response{Status: 51, Meta: "Not found"}, 300)
+	c.Set("gemini://example.org/broken", &types.Response{Status: 50, Meta: "Oops"}, 300)
+
+	if resp, ok := c.Get("gemini://example.org/old"); !ok || resp.Meta != "gemini://example.org/new" {
+		t.Error("permanent redirect was not cached")
+	}
+	if _, ok := c.Get("gemini://example.org/temp"); ok {
+		t.Error("temporary redirect was cached")
+	}
+	if _, ok := c.Get("gemini://example.org/broken"); ok {
+		t.Error("permanent failure was cached")
+	}
+	if _, ok := c.Get("gemini://example.org/missing"); !ok {
+		t.Fatal("Not Found was not cached")
+	}
+
+	// Not Found expires after NotFoundTTL, whatever TTL was asked for
+	c.mutex.Lock()
+	entry := c.entries[c.key("gemini://example.org/missing")]
+	if entry.TTL != NotFoundTTL {
+		t.Errorf("Not Found TTL %d, want %d", entry.TTL, NotFoundTTL)
+	}
+	entry.Timestamp = time.Now().Unix() - NotFoundTTL - 1
+	c.mutex.Unlock()
+	if _, ok := c.Get("gemini://example.org/missing"); ok {
+		t.Error("Not Found outlived its TTL")
+	}
+
+	c.Clear()
+	if _, ok := c.Get("gemini://example.org/old"); ok {
+		t.Error("Clear kept the cached redirect")
+	}
+}
+
+func TestCacheLRUEviction(t *testing.T) {
+	page := func(n int) *types.Response {
+		return &types.Response{Status: 20, Meta: "text/gemini", Body: make([]byte, n)}
+	}
+	urls := []string{"gemini://example.org/a", "gemini://example.org/b", "gemini://example.org/c"}
+
+	// Room for exactly two of the pages
+	size := entrySize(urls[0], page(100*1024))
+	c := NewCache(1, 300)
+	c.maxSize = 2 * size
+
+	evicted := 0
+	c.OnEvict(func(count int) { evicted += count })
+
+	c.Set(urls[0], page(100*1024), 300)
+	c.Set(urls[1], page(100*1024), 300)
+	if c.GetSize() != 2*size {
+		t.Errorf("size %d, want %d counting URL, meta and overhead", c.GetSize(), 2*size)
+	}
+
+	// Reading a makes b the least recently used
+	if _, ok := c.Get(urls[0]); !ok {
+		t.Fatal("a was not cached")
+	}
+	c.Set(urls[2], page(100*1024), 300)
+	if _, ok := c.Get(urls[1]); ok {
+		t.Error("least recently used entry was kept")
+	}
+	for _, url := range []string{urls[0], urls[2]} {
+		if _, ok := c.Get(url); !ok {
+			t.Errorf("%s was evicted", url)
+		}
+	}
+	if c.Evictions() != 1 || evicted != 1 {
+		t.Errorf("evictions %d, hook saw %d, want 1", c.Evictions(), evicted)
+	}
+
+	// Entries bigger than the cache are refused without evicting anything
+	c.Set("gemini://example.org/huge", page(int(c.maxSize)), 300)
+	if c.GetEntryCount() != 2 || c.Evictions() != 1 {
+		t.Errorf("oversized entry changed the cache: %d entries, %d evictions", c.GetEntryCount(), c.Evictions())
+	}
+
+	c.Invalidate(urls[0])
+	if c.GetSize() != size {
+		t.Errorf("size after invalidate %d, want %d", c.GetSize(), size)
+	}
+	c.Clear()
+	if c.GetSize() != 0 || c.GetEntryCount() != 0 {
+		t.Error("Clear left entries behind")
+	}
+}
+
+func TestDiskCachePersists(t *testing.T) {
+	dir := t.TempDir()
+	page := func(body string) *types.Response {
+		return &types.Response{Status: 20, Meta: "text/gemini", Body: []byte(body)}
+	}
+
+	c := NewDiskCache(dir, 1, 300)
+	c.Set("gemini://example.org/a", page("# Same"), 300)
+	c.Set("gemini://example.org/b", page("# Same"), 300)
+	c.Set("gemini://example.org/c", page("# Other"), 300)
+	c.Set("gemini://example.org/old", &types.Response{Status: 31, Meta: "gemini://example.org/new"}, 300)
+	c.Get("gemini://example.org/a")
+	if err := c.Save(); err != nil {
+		t.Fatal(err)
+	}
+
+	// Identical bodies share a file
+	bodies, err := os.ReadDir(filepath.Join(dir, bodiesDir))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(bodies) != 3 {
+		t.Errorf("%d body files, want 3", len(bodies))
+	}
+
+	reloaded := NewDiskCache(dir, 1, 300)
+	if resp, ok := reloaded.Get("gemini://example.org/b"); !ok || string(resp.Body) != "# Same" {
+		t.Error("page was not reloaded from disk")
+	}
+	if resp, ok := reloaded.Get("gemini://example.org/old"); !ok || resp.Meta != "gemini://example.org/new" {
+		t.Error("redirect was not reloaded from disk")
+	}
+	if reloaded.GetSize() != c.GetSize() {
+		t.Errorf("reloaded size %d, want %d", reloaded.GetSize(), c.GetSize())
+	}
+
+	fetched := time.Unix(1700000000, 0)
+	c.Set("gemini://example.org/meta", &types.Response{Status: 20, Meta: "text/gemini", Protocol: "gemini", Fetched: fetched}, 300)
+	if resp, ok := NewDiskCache(dir, 1, 300).Get("gemini://example.org/meta"); !ok || resp.Protocol != "gemini" || !resp.Fetched.Equal(fetched) {
+		t.Errorf("fetch metadata was not reloaded from disk: %+v", resp)
+	}
+
+	// Removing one of two entries sharing a body keeps the file
+	reloaded.Invalidate("gemini://example.org/a")
+	if _, ok := NewDiskCache(dir, 1, 300).Get("gemini://example.org/b"); !ok {
+		t.Error("invalidating a lost the body it shares with b")
+	}
+
+	reloaded.Clear()
+	if NewDiskCache(dir, 1, 300).GetEntryCount() != 0 {
+		t.Error("Clear left entries on disk")
+	}
+}
+
+func TestDiskCacheEnforcesCapOnLoad(t *testing.T) {
+	dir := t.TempDir()
+	page := &types.Response{Status: 20, Meta: "text/gemini", Body: make([]byte, 400*1024)}
+
+	c := NewDiskCache(dir, 2, 300)
+	c.Set("gemini://example.org/a", page, 300)
+	c.Set("gemini://example.org/b", page, 300)
+	c.Set("gemini://example.org/c", &types.Response{Status: 20, Meta: "text/plain", Body: make([]byte, 400*1024)}, 300)
+	c.Get("gemini://example.org/a")
+	if err := c.Save(); err != nil {
+		t.Fatal(err)
+	}
+
+	// A smaller cap keeps the most recently used entries
+	reloaded := NewDiskCache(dir, 1, 300)
+	if _, ok := reloaded.Get("gemini://example.org/a"); !ok {
+		t.Error("most recently used entry was dropped")
+	}
+	if reloaded.GetEntryCount() != 2 {
+		t.Errorf("%d entries after load, want 2", reloaded.GetEntryCount())
+	}
+	if _, ok := reloaded.Get("gemini://example.org/b"); ok {
+		t.Error("least recently used entry survived the smaller cap")
+	}
+}
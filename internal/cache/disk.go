@@ -0,0 +1,215 @@
+package cache
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"starsearch/internal/types"
+)
+
+// On-disk layout: the index lists entries most recently used first, bodies
+// are stored once each under the SHA-256 of their content
+const (
+	indexFile = "index.json"
+	bodiesDir = "bodies"
+)
+
+// diskEntry is an entry as recorded in the index
+type diskEntry struct {
+	URL        string `json:"url"`
+	Status     int    `json:"status"`
+	Meta       string `json:"meta"`
+	RemoteAddr string `json:"remote_addr,omitempty"`
+	Protocol   string `json:"protocol,omitempty"`
+	Fetched    int64  `json:"fetched,omitempty"` // When the response arrived, Unix seconds
+	Body       string `json:"body"`              // SHA-256 of the body, naming its file
+	Timestamp  int64  `json:"timestamp"`
+	TTL        int64  `json:"ttl"`
+}
+
+// NewDiskCache creates a cache that persists its entries in dir, loading
+// what an earlier run left there. Expired entries and entries beyond the
+// size cap, least recently used first, are dropped from disk on load.
+func NewDiskCache(dir string, maxSizeMB int, defaultTTLSeconds int64) *Cache {
+	c := NewCache(maxSizeMB, defaultTTLSeconds)
+	c.dir = dir
+
+	_ = c.load() // Ignore errors, a damaged cache starts empty
+	return c
+}
+
+// Save records the entries and their recency on disk. Stores and removals
+// are written as they happen; Save catches up on reads.
+func (c *Cache) Save() error {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	return c.saveIndex()
+}
+
+// load reads the index and bodies from disk, then rewrites the index and
+// removes body files nothing refers to any more
+func (c *Cache) load() error {
+	data, err := os.ReadFile(filepath.Join(c.dir, indexFile))
+	if err != nil && !os.IsNotExist(err) {
+		return err
+	}
+
+	var index []diskEntry
+	if len(data) > 0 {
+		if err := json.Unmarshal(data, &index); err != nil {
+			return fmt.Errorf("failed to parse cache index: %w", err)
+		}
+	}
+
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	now := time.Now().Unix()
+	for _, stored := range index {
+		if stored.Timestamp+stored.TTL < now {
+			continue
+		}
+		key := c.key(stored.URL)
+		if _, exists := c.entries[key]; exists {
+			continue
+		}
+
+		// Bodies that are missing or don't match their name are dropped
+		body, err := os.ReadFile(c.bodyPath(stored.Body))
+		if err != nil || bodyHash(body) != stored.Body {
+			continue
+		}
+
+		resp := &types.Response{
+			Status:     stored.Status,
+			Meta:       stored.Meta,
+			Body:       body,
+			RemoteAddr: stored.RemoteAddr,
+			URL:        stored.URL,
+			Protocol:   stored.Protocol,
+		}
+		if stored.Fetched != 0 {
+			resp.Fetched = time.Unix(stored.Fetched, 0)
+		}
+		size := entrySize(stored.URL, resp)
+		if c.currentSize+size > c.maxSize {
+			continue
+		}
+
+		c.entries[key] = &CacheEntry{
+			URL:       stored.URL,
+			Response:  resp,
+			Timestamp: stored.Timestamp,
+			TTL:       stored.TTL,
+			size:      size,
+			element:   c.recency.PushBack(key),
+			bodyHash:  stored.Body,
+		}
+		c.currentSize += size
+	}
+
+	if err := c.saveIndex(); err != nil {
+		return err
+	}
+	return c.removeOrphans()
+}
+
+// saveIndex writes the index. The caller holds the lock.
+func (c *Cache) saveIndex() error {
+	if c.dir == "" {
+		return nil
+	}
+
+	index := make([]diskEntry, 0, len(c.entries))
+	for e := c.recency.Front(); e != nil; e = e.Next() {
+		entry := c.entries[e.Value.(string)]
+		var fetched int64
+		if !entry.Response.Fetched.IsZero() {
+			fetched = entry.Response.Fetched.Unix()
+		}
+		index = append(index, diskEntry{
+			URL:        entry.URL,
+			Status:     entry.Response.Status,
+			Meta:       entry.Response.Meta,
+			RemoteAddr: entry.Response.RemoteAddr,
+			Protocol:   entry.Response.Protocol,
+			Fetched:    fetched,
+			Body:       entry.bodyHash,
+			Timestamp:  entry.Timestamp,
+			TTL:        entry.TTL,
+		})
+	}
+
+	data, err := json.MarshalIndent(index, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(c.dir, 0700); err != nil {
+		return err
+	}
+	return os.WriteFile(filepath.Join(c.dir, indexFile), data, 0600)
+}
+
+// writeBody stores an entry's body under its hash, unless an identical body
+// is already there. The caller holds the lock.
+func (c *Cache) writeBody(entry *CacheEntry) error {
+	path := c.bodyPath(entry.bodyHash)
+	if existing, err := os.ReadFile(path); err == nil && bytes.Equal(existing, entry.Response.Body) {
+		return nil
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0700); err != nil {
+		return err
+	}
+	return os.WriteFile(path, entry.Response.Body, 0600)
+}
+
+// removeBody deletes a removed entry's body file unless another entry shares
+// it. The caller holds the lock.
+func (c *Cache) removeBody(entry *CacheEntry) {
+	for _, other := range c.entries {
+		if other.bodyHash == entry.bodyHash {
+			return
+		}
+	}
+	_ = os.Remove(c.bodyPath(entry.bodyHash))
+}
+
+// removeOrphans deletes body files no entry refers to. The caller holds the
+// lock.
+func (c *Cache) removeOrphans() error {
+	files, err := os.ReadDir(filepath.Join(c.dir, bodiesDir))
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil
+		}
+		return err
+	}
+
+	used := make(map[string]bool, len(c.entries))
+	for _, entry := range c.entries {
+		used[entry.bodyHash] = true
+	}
+	for _, file := range files {
+		if !used[file.Name()] {
+			_ = os.Remove(filepath.Join(c.dir, bodiesDir, file.Name()))
+		}
+	}
+	return nil
+}
+
+// bodyPath is where the body with the given hash is stored
+func (c *Cache) bodyPath(hash string) string {
+	return filepath.Join(c.dir, bodiesDir, hash)
+}
+
+// bodyHash names a body by its content
+func bodyHash(body []byte) string {
+	hash := sha256.Sum256(body)
+	return hex.EncodeToString(hash[:])
+}
@@ -0,0 +1,152 @@
+// Package cassette records network sessions to a file and replays them, for
+// deterministic UI tests and offline demos of rendering bugs.
+package cassette
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+
+	"starsearch/internal/types"
+)
+
+// formatVersion is written to every cassette file
+const formatVersion = 1
+
+// Fetcher fetches a URL, like the Gemini and Gopher clients
+type Fetcher interface {
+	Fetch(urlStr string) (*types.Response, error)
+}
+
+// Interaction is one recorded request and its response or error
+type Interaction struct {
+	URL    string `json:"url"`
+	Status int    `json:"status,omitempty"`
+	Meta   string `json:"meta,omitempty"`
+	Body   []byte `json:"body,omitempty"`
+	Error  string `json:"error,omitempty"`
+}
+
+// file is the on-disk cassette format
+type file struct {
+	Version      int           `json:"version"`
+	Interactions []Interaction `json:"interactions"`
+}
+
+// Recorder saves every request made through the fetchers it wraps. The
+// cassette is rewritten after each request so a crash loses nothing.
+type Recorder struct {
+	mu           sync.Mutex
+	path         string
+	interactions []Interaction
+}
+
+// NewRecorder creates a recorder writing to path
+func NewRecorder(path string) *Recorder {
+	return &Recorder{path: path}
+}
+
+// Wrap returns a fetcher that records every request made through next
+func (r *Recorder) Wrap(next Fetcher) Fetcher {
+	return recordingFetcher{recorder: r, next: next}
+}
+
+// recordingFetcher records the requests of one client
+type recordingFetcher struct {
+	recorder *Recorder
+	next     Fetcher
+}
+
+func (f recordingFetcher) Fetch(urlStr string) (*types.Response, error) {
+	resp, err := f.next.Fetch(urlStr)
+
+	entry := Interaction{URL: urlStr}
+	if err != nil {
+		entry.Error = err.Error()
+	} else {
+		entry.Status, entry.Meta, entry.Body = resp.Status, resp.Meta, resp.Body
+	}
+	if saveErr := f.recorder.add(entry); saveErr != nil && err == nil {
+		err = saveErr
+	}
+	return resp, err
+}
+
+// add appends an interaction and saves the cassette
+func (r *Recorder) add(entry Interaction) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.interactions = append(r.interactions, entry)
+
+	data, err := json.MarshalIndent(file{Version: formatVersion, Interactions: r.interactions}, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode cassette: %w", err)
+	}
+	if err := os.MkdirAll(filepath.Dir(r.path), 0700); err != nil {
+		return fmt.Errorf("failed to save cassette: %w", err)
+	}
+	if err := os.WriteFile(r.path, data, 0600); err != nil {
+		return fmt.Errorf("failed to save cassette: %w", err)
+	}
+	return nil
+}
+
+// Player serves responses from a cassette instead of the network. A URL
+// requested several times gets its recorded responses in order, then the
+// last one again.
+type Player struct {
+	mu     sync.Mutex
+	byURL  map[string][]Interaction
+	served map[string]int
+}
+
+// Load reads a cassette for replay
+func Load(path string) (*Player, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read cassette: %w", err)
+	}
+	var f file
+	if err := json.Unmarshal(data, &f); err != nil {
+		return nil, fmt.Errorf("failed to parse cassette: %w", err)
+	}
+	if f.Version != formatVersion {
+		return nil, fmt.Errorf("unsupported cassette version %d", f.Version)
+	}
+
+	p := &Player{
+		byURL:  make(map[string][]Interaction),
+		served: make(map[string]int),
+	}
+	for _, entry := range f.Interactions {
+		p.byURL[entry.URL] = append(p.byURL[entry.URL], entry)
+	}
+	return p, nil
+}
+
+// Fetch returns the next recorded response for urlStr
+func (p *Player) Fetch(urlStr string) (*types.Response, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	entries := p.byURL[urlStr]
+	if len(entries) == 0 {
+		return nil, fmt.Errorf("%s is not in the cassette", urlStr)
+	}
+	i := min(p.served[urlStr], len(entries)-1)
+	p.served[urlStr]++
+
+	entry := entries[i]
+	if entry.Error != "" {
+		return nil, errors.New(entry.Error)
+	}
+	return &types.Response{
+		Status: entry.Status,
+		Meta:   entry.Meta,
+		Body:   entry.Body,
+		URL:    urlStr,
+	}, nil
+}
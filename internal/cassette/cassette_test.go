@@ -0,0 +1,62 @@
+package cassette
+
+import (
+	"errors"
+	"path/filepath"
+	"testing"
+
+	"starsearch/internal/types"
+)
+
+// sequenceFetcher returns its responses in order, one per request
+type sequenceFetcher struct {
+	responses []*types.Response
+	errs      []error
+}
+
+func (f *sequenceFetcher) Fetch(urlStr string) (*types.Response, error) {
+	resp, err := f.responses[0], f.errs[0]
+	f.responses, f.errs = f.responses[1:], f.errs[1:]
+	return resp, err
+}
+
+func TestRecordAndReplay(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "session.json")
+	live := &sequenceFetcher{
+		responses: []*types.Response{
+			{Status: 20, Meta: "text/gemini", Body: []byte("# First\n")},
+			{Status: 20, Meta: "text/gemini", Body: []byte("# Second\n")},
+			nil,
+		},
+		errs: []error{nil, nil, errors.New("connection refused")},
+	}
+
+	recorder := NewRecorder(path)
+	client := recorder.Wrap(live)
+	client.Fetch("gemini://example.org/")
+	client.Fetch("gemini://example.org/")
+	client.Fetch("gemini://down.example/")
+
+	player, err := Load(path)
+	if err != nil {
+		t.Fatalf("Load: %v", err)
+	}
+
+	// Repeated requests get the recorded responses in order, then the last
+	for _, want := range []string{"# First\n", "# Second\n", "# Second\n"} {
+		resp, err := player.Fetch("gemini://example.org/")
+		if err != nil {
+			t.Fatalf("Fetch: %v", err)
+		}
+		if string(resp.Body) != want || resp.URL != "gemini://example.org/" {
+			t.Errorf("got %q from %s, want %q", resp.Body, resp.URL, want)
+		}
+	}
+
+	if _, err := player.Fetch("gemini://down.example/"); err == nil || err.Error() != "connection refused" {
+		t.Errorf("recorded error not replayed: %v", err)
+	}
+	if _, err := player.Fetch("gemini://unknown.example/"); err == nil {
+		t.Error("expected an error for a URL missing from the cassette")
+	}
+}
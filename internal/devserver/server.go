@@ -0,0 +1,185 @@
+// Package devserver is a tiny local Gemini server for testing starsearch
+// and capsules: it serves a directory of .gmi files over TLS with a
+// self-signed certificate, plus redirects, input prompts and error statuses
+// declared in a config file.
+package devserver
+
+import (
+	"context"
+	"crypto/tls"
+	"fmt"
+	"log"
+	"net"
+	"net/url"
+	"os"
+	"path"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"git.sr.ht/~adnano/go-gemini"
+	"git.sr.ht/~adnano/go-gemini/certificate"
+	"github.com/BurntSushi/toml"
+)
+
+// ConfigFile is the name of the optional rules file in the served directory
+const ConfigFile = "serve.toml"
+
+// Config declares responses other than plain files, keyed by request path
+type Config struct {
+	Redirects          map[string]string `toml:"redirects"`           // Path -> target, status 31
+	TemporaryRedirects map[string]string `toml:"temporary_redirects"` // Path -> target, status 30
+	Input              map[string]string `toml:"input"`               // Path -> prompt, status 10
+	SensitiveInput     map[string]string `toml:"sensitive_input"`     // Path -> prompt, status 11
+	Status             map[string]string `toml:"status"`              // Path -> "<code> <meta>", e.g. "51 Gone"
+}
+
+// LoadConfig reads ConfigFile from dir. A missing file is an empty config.
+func LoadConfig(dir string) (*Config, error) {
+	cfg := &Config{}
+	configPath := filepath.Join(dir, ConfigFile)
+	if _, err := toml.DecodeFile(configPath, cfg); err != nil {
+		if os.IsNotExist(err) {
+			return cfg, nil
+		}
+		return nil, fmt.Errorf("failed to read %s: %w", configPath, err)
+	}
+
+	for p, rule := range cfg.Status {
+		if _, _, err := parseStatus(rule); err != nil {
+			return nil, fmt.Errorf("%s: status for %s: %w", ConfigFile, p, err)
+		}
+	}
+	return cfg, nil
+}
+
+// parseStatus splits a status rule such as "51 Gone" into code and meta
+func parseStatus(rule string) (gemini.Status, string, error) {
+	codeStr, meta, _ := strings.Cut(strings.TrimSpace(rule), " ")
+	code, err := strconv.Atoi(codeStr)
+	if err != nil || code < 10 || code > 69 {
+		return 0, "", fmt.Errorf("invalid status %q", codeStr)
+	}
+	return gemini.Status(code), strings.TrimSpace(meta), nil
+}
+
+// Options configures a Server
+type Options struct {
+	Dir     string // Directory of files to serve
+	Addr    string // Listen address, e.g. localhost:1965
+	CertDir string // Where the self-signed certificate is kept; empty keeps it in memory
+	NewCert bool   // Replace the stored certificate, to test certificate change warnings
+}
+
+// Server serves a directory over Gemini
+type Server struct {
+	config *Config
+	files  gemini.Handler
+	certs  *certificate.Store
+	server *gemini.Server
+}
+
+// New creates a server for opts.Dir, loading its config file and
+// certificate
+func New(opts Options) (*Server, error) {
+	info, err := os.Stat(opts.Dir)
+	if err != nil {
+		return nil, fmt.Errorf("cannot serve %s: %w", opts.Dir, err)
+	}
+	if !info.IsDir() {
+		return nil, fmt.Errorf("cannot serve %s: not a directory", opts.Dir)
+	}
+
+	cfg, err := LoadConfig(opts.Dir)
+	if err != nil {
+		return nil, err
+	}
+
+	certs := &certificate.Store{}
+	certs.Register("*")
+	if opts.CertDir != "" {
+		if opts.NewCert {
+			// Certificates created from now on are written over the old ones
+			certs.SetPath(opts.CertDir)
+		} else if err := certs.Load(opts.CertDir); err != nil {
+			return nil, fmt.Errorf("failed to load certificates: %w", err)
+		}
+	}
+
+	s := &Server{
+		config: cfg,
+		files:  gemini.FileServer(os.DirFS(opts.Dir)),
+		certs:  certs,
+	}
+	s.server = &gemini.Server{
+		Addr:           opts.Addr,
+		Handler:        s,
+		GetCertificate: certs.Get,
+	}
+	return s, nil
+}
+
+// ListenAndServe serves on opts.Addr until ctx is cancelled
+func (s *Server) ListenAndServe(ctx context.Context) error {
+	return s.server.ListenAndServe(ctx)
+}
+
+// Serve serves TLS connections accepted from l until ctx is cancelled
+func (s *Server) Serve(ctx context.Context, l net.Listener) error {
+	return s.server.Serve(ctx, tls.NewListener(l, &tls.Config{
+		ClientAuth: tls.RequestClientCert,
+		MinVersion: tls.VersionTLS12,
+		GetCertificate: func(hello *tls.ClientHelloInfo) (*tls.Certificate, error) {
+			return s.certs.Get(hello.ServerName)
+		},
+	}))
+}
+
+// ServeGemini answers a request from the config rules, falling back to the
+// files in the served directory
+func (s *Server) ServeGemini(ctx context.Context, w gemini.ResponseWriter, r *gemini.Request) {
+	p := path.Clean("/" + r.URL.Path)
+	log.Printf("%s", r.URL)
+
+	if rule, ok := s.config.Status[p]; ok {
+		code, meta, _ := parseStatus(rule)
+		w.WriteHeader(code, meta)
+		return
+	}
+	if target, ok := s.config.Redirects[p]; ok {
+		w.WriteHeader(gemini.StatusPermanentRedirect, target)
+		return
+	}
+	if target, ok := s.config.TemporaryRedirects[p]; ok {
+		w.WriteHeader(gemini.StatusRedirect, target)
+		return
+	}
+	if prompt, ok := s.config.Input[p]; ok {
+		s.serveInput(w, r, gemini.StatusInput, prompt)
+		return
+	}
+	if prompt, ok := s.config.SensitiveInput[p]; ok {
+		s.serveInput(w, r, gemini.StatusSensitiveInput, prompt)
+		return
+	}
+
+	s.files.ServeGemini(ctx, w, r)
+}
+
+// serveInput prompts for input, then echoes the answer back in a page
+func (s *Server) serveInput(w gemini.ResponseWriter, r *gemini.Request, status gemini.Status, prompt string) {
+	if r.URL.RawQuery == "" {
+		w.WriteHeader(status, prompt)
+		return
+	}
+
+	answer, err := url.QueryUnescape(r.URL.RawQuery)
+	if err != nil {
+		w.WriteHeader(gemini.StatusBadRequest, "Malformed query")
+		return
+	}
+	if status == gemini.StatusSensitiveInput {
+		answer = strings.Repeat("*", len([]rune(answer)))
+	}
+	fmt.Fprintf(w, "# %s\n\nReceived: %s\n\n=> %s Ask again\n", prompt, answer, r.URL.Path)
+}
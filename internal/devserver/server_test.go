@@ -0,0 +1,103 @@
+package devserver
+
+import (
+	"context"
+	"net"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"starsearch/internal/gemini"
+)
+
+// startServer serves a directory holding files and returns its base URL
+// and a client that trusts it
+func startServer(t *testing.T, files map[string]string) (string, *gemini.Client) {
+	t.Helper()
+	dir := t.TempDir()
+	for name, content := range files {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	server, err := New(Options{Dir: dir})
+	if err != nil {
+		t.Fatalf("New: %v", err)
+	}
+	l, err := net.Listen("tcp", "localhost:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	t.Cleanup(cancel)
+	go server.Serve(ctx, l)
+
+	tofu, err := gemini.NewTOFUStore(filepath.Join(t.TempDir(), "known_hosts.json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, port, _ := net.SplitHostPort(l.Addr().String())
+	return "gemini://localhost:" + port, gemini.NewClient(tofu)
+}
+
+func TestServeFilesAndRules(t *testing.T) {
+	base, client := startServer(t, map[string]string{
+		"index.gmi": "# Home\n",
+		ConfigFile: `
+[redirects]
+"/old" = "/index.gmi"
+
+[input]
+"/search" = "Search terms"
+
+[sensitive_input]
+"/login" = "Password"
+
+[status]
+"/gone" = "52 Removed for good"
+`,
+	})
+
+	tests := []struct {
+		path   string
+		status int
+		meta   string
+		body   string
+	}{
+		{path: "/", status: 20, meta: "text/gemini", body: "# Home"},
+		{path: "/old", status: 31, meta: "/index.gmi"},
+		{path: "/search", status: 10, meta: "Search terms"},
+		{path: "/search?two%20words", status: 20, body: "Received: two words"},
+		{path: "/login?hunter2", status: 20, body: "Received: *******"},
+		{path: "/gone", status: 52, meta: "Removed for good"},
+		{path: "/missing.gmi", status: 51},
+	}
+	for _, tt := range tests {
+		resp, err := client.Fetch(base + tt.path)
+		if err != nil {
+			t.Errorf("%s: %v", tt.path, err)
+			continue
+		}
+		if resp.Status != tt.status {
+			t.Errorf("%s: status %d, want %d", tt.path, resp.Status, tt.status)
+		}
+		if tt.meta != "" && !strings.HasPrefix(resp.Meta, tt.meta) {
+			t.Errorf("%s: meta %q, want %q", tt.path, resp.Meta, tt.meta)
+		}
+		if !strings.Contains(string(resp.Body), tt.body) {
+			t.Errorf("%s: body %q does not contain %q", tt.path, resp.Body, tt.body)
+		}
+	}
+}
+
+func TestLoadConfigRejectsBadStatus(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, ConfigFile), []byte("[status]\n\"/x\" = \"99 Nope\"\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := LoadConfig(dir); err == nil {
+		t.Error("expected an error for status 99")
+	}
+}
@@ -2,12 +2,17 @@ package gemini
 
 import (
 	"context"
+	"crypto/tls"
 	"fmt"
 	"io"
+	"net"
 	"net/url"
+	"strings"
 	"time"
 
 	"git.sr.ht/~adnano/go-gemini"
+	"golang.org/x/net/idna"
+	"golang.org/x/net/proxy"
 	"starsearch/internal/types"
 )
 
@@ -15,26 +20,222 @@ import (
 type Client struct {
 	client     *gemini.Client
 	tofuStore  *TOFUStore
+	identities *IdentityStore // Client certificates presented by scope, if set
+	proxy      string         // SOCKS5 proxy address; empty connects directly
 	userAgent  string
 	timeout    time.Duration
+	ctx        context.Context // Cancelled by Close, aborting requests in flight
+	cancel     context.CancelFunc
 }
 
 // NewClient creates a new Gemini client with TOFU support
 func NewClient(tofuStore *TOFUStore) *Client {
+	ctx, cancel := context.WithCancel(context.Background())
 	return &Client{
 		client:    &gemini.Client{},
 		tofuStore: tofuStore,
 		userAgent: "starsearch/1.0",
 		timeout:   30 * time.Second,
+		ctx:       ctx,
+		cancel:    cancel,
 	}
 }
 
+// Close aborts requests in flight, including those of isolated clients made
+// from c, and makes any further requests fail
+func (c *Client) Close() {
+	c.cancel()
+}
+
+// SetIdentities makes the client present the identity scoped to each
+// request's URL
+func (c *Client) SetIdentities(identities *IdentityStore) {
+	c.identities = identities
+}
+
+// SetProxy sends requests through the SOCKS5 proxy at addr, such as Tor's
+// 127.0.0.1:9050. Host names are resolved by the proxy, so .onion
+// capsules can be reached. An empty addr connects directly.
+func (c *Client) SetProxy(addr string) error {
+	c.proxy = addr
+	c.client.DialContext = nil
+	if addr == "" {
+		return nil
+	}
+
+	dial, err := socksDialer(addr, "")
+	if err != nil {
+		return err
+	}
+	c.client.DialContext = dial
+	return nil
+}
+
+// Isolated returns a client sharing c's settings whose connections present
+// key as their SOCKS credentials. Tor puts streams with different
+// credentials on different circuits. Without a proxy it returns c.
+func (c *Client) Isolated(key string) *Client {
+	if c.proxy == "" || key == "" {
+		return c
+	}
+	dial, err := socksDialer(c.proxy, key)
+	if err != nil {
+		return c
+	}
+
+	inner := *c.client
+	inner.DialContext = dial
+	isolated := *c
+	isolated.client = &inner
+	return &isolated
+}
+
+// socksDialer dials through the SOCKS5 proxy at addr, authenticating as
+// user if given
+func socksDialer(addr, user string) (func(ctx context.Context, network, addr string) (net.Conn, error), error) {
+	var auth *proxy.Auth
+	if user != "" {
+		auth = &proxy.Auth{User: user, Password: user}
+	}
+	dialer, err := proxy.SOCKS5("tcp", addr, auth, proxy.Direct)
+	if err != nil {
+		return nil, fmt.Errorf("invalid SOCKS proxy %s: %w", addr, err)
+	}
+	return dialer.(proxy.ContextDialer).DialContext, nil
+}
+
 // Fetch retrieves a Gemini URL and returns a parsed response
 func (c *Client) Fetch(urlStr string) (*types.Response, error) {
+	return c.FetchStream(urlStr, nil)
+}
+
+// FetchStream is Fetch for slow, "live" pages: while the body of a
+// successful text response arrives, partial is called with the response
+// as received so far. Its body is never modified afterwards.
+func (c *Client) FetchStream(urlStr string, partial func(resp *types.Response)) (*types.Response, error) {
+	// Create context with timeout
+	ctx, cancel := context.WithTimeout(c.ctx, c.timeout)
+	defer cancel()
+
+	resp, urlStr, err := c.request(ctx, urlStr)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	fetched := time.Now()
+
+	// Read response body
+	if resp.Status.Class() != gemini.StatusSuccess || !strings.HasPrefix(resp.Meta, "text/") {
+		partial = nil
+	}
+	var body []byte
+	buf := make([]byte, 32*1024)
+	for {
+		n, err := resp.Body.Read(buf)
+		if n > 0 {
+			body = append(body, buf[:n]...)
+			if partial != nil {
+				partial(&types.Response{Status: int(resp.Status), Meta: resp.Meta, Body: body, URL: urlStr, Protocol: "gemini", Fetched: fetched})
+			}
+		}
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to read response body: %w", err)
+		}
+	}
+
+	// Create response
+	response := &types.Response{
+		Status:   int(resp.Status),
+		Meta:     resp.Meta,
+		Body:     body,
+		URL:      urlStr,
+		Protocol: "gemini",
+		Fetched:  fetched,
+	}
+
+	return response, nil
+}
+
+// FetchTo is Fetch for downloads: the body of a successful response is
+// written to w as it arrives instead of being kept, and progress is called
+// with the number of bytes written so far. The client's timeout only
+// bounds the wait for the header, so a large file takes as long as it
+// needs; cancelling ctx abandons the request midway.
+func (c *Client) FetchTo(ctx context.Context, urlStr string, w io.Writer, progress func(written int64)) (*types.Response, error) {
+	// The request ends with ctx or when the client is closed
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	stopClose := context.AfterFunc(c.ctx, cancel)
+	defer stopClose()
+
+	timeout := time.AfterFunc(c.timeout, cancel)
+	resp, urlStr, err := c.request(ctx, urlStr)
+	if !timeout.Stop() {
+		if err == nil {
+			resp.Body.Close()
+		}
+		return nil, fmt.Errorf("failed to fetch: no response within %s", c.timeout)
+	}
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	fetched := time.Now()
+
+	// Drop the connection if the download is abandoned midway
+	stop := context.AfterFunc(ctx, func() { resp.Body.Close() })
+	defer stop()
+
+	response := &types.Response{
+		Status:   int(resp.Status),
+		Meta:     resp.Meta,
+		URL:      urlStr,
+		Protocol: "gemini",
+		Fetched:  fetched,
+	}
+	if resp.Status.Class() != gemini.StatusSuccess {
+		return response, nil
+	}
+
+	var written int64
+	buf := make([]byte, 32*1024)
+	for {
+		n, err := resp.Body.Read(buf)
+		if n > 0 {
+			if _, werr := w.Write(buf[:n]); werr != nil {
+				return nil, werr
+			}
+			written += int64(n)
+			if progress != nil {
+				progress(written)
+			}
+		}
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			if ctx.Err() != nil {
+				return nil, fmt.Errorf("failed to read response body: %w", context.Canceled)
+			}
+			return nil, fmt.Errorf("failed to read response body: %w", err)
+		}
+	}
+	return response, nil
+}
+
+// request sends a request for urlStr, through the proxy if one is set and
+// presenting the identity scoped to it, once the certificate the host
+// presents matches the trusted one. It returns the response, whose body
+// the caller closes, and the URL requested. The connection is closed when
+// ctx ends at the latest.
+func (c *Client) request(ctx context.Context, urlStr string) (*gemini.Response, string, error) {
 	// Parse and validate URL
 	parsedURL, err := url.Parse(urlStr)
 	if err != nil {
-		return nil, fmt.Errorf("invalid URL: %w", err)
+		return nil, "", fmt.Errorf("invalid URL: %w", err)
 	}
 
 	// Ensure scheme is gemini
@@ -42,48 +243,67 @@ func (c *Client) Fetch(urlStr string) (*types.Response, error) {
 		parsedURL.Scheme = "gemini"
 		urlStr = parsedURL.String()
 	} else if parsedURL.Scheme != "gemini" {
-		return nil, fmt.Errorf("unsupported scheme: %s (only gemini:// is supported)", parsedURL.Scheme)
+		return nil, "", fmt.Errorf("unsupported scheme: %s (only gemini:// is supported)", parsedURL.Scheme)
 	}
 
-	// Create context with timeout
-	ctx, cancel := context.WithTimeout(context.Background(), c.timeout)
-	defer cancel()
-
-	// Fetch the URL
-	resp, err := c.client.Do(ctx, &gemini.Request{
-		URL: parsedURL,
-	})
-	if err != nil {
-		return nil, fmt.Errorf("failed to fetch: %w", err)
+	// Pick the client certificate to present
+	var cert *tls.Certificate
+	if c.identities != nil {
+		if cert, err = c.identities.Certificate(urlStr); err != nil {
+			return nil, "", err
+		}
 	}
-	defer resp.Body.Close()
-
-	// Verify certificate using TOFU
-	tlsState := resp.TLS()
-	if tlsState != nil && len(tlsState.PeerCertificates) > 0 {
-		cert := tlsState.PeerCertificates[0]
-		host := parsedURL.Hostname()
 
-		if err := c.tofuStore.Verify(host, cert); err != nil {
-			return nil, fmt.Errorf("certificate verification failed: %w", err)
+	// Hosts with non-ASCII names are requested by their punycode form
+	requestURL := *parsedURL
+	if host := parsedURL.Hostname(); net.ParseIP(host) == nil {
+		ascii, err := idna.Lookup.ToASCII(host)
+		if err != nil {
+			return nil, "", fmt.Errorf("invalid URL: %w", err)
 		}
+		if port := parsedURL.Port(); port != "" {
+			ascii = net.JoinHostPort(ascii, port)
+		}
+		requestURL.Host = ascii
 	}
 
-	// Read response body
-	body, err := io.ReadAll(resp.Body)
+	// Connect, through the proxy if one is set
+	conn, err := c.dialTLS(ctx, &requestURL, cert)
 	if err != nil {
-		return nil, fmt.Errorf("failed to read response body: %w", err)
+		if headerErr := diagnoseFetchError(parsedURL, nil, err); headerErr != nil {
+			return nil, "", headerErr
+		}
+		return nil, "", fmt.Errorf("failed to fetch: %w", err)
 	}
+	// The connection ends with ctx, which callers cancel once done with it
+	context.AfterFunc(ctx, func() { conn.Close() })
 
-	// Create response
-	response := &types.Response{
-		Status: int(resp.Status),
-		Meta:   resp.Meta,
-		Body:   body,
-		URL:    urlStr,
+	// Verify certificate using TOFU, before anything is sent
+	if peers := conn.ConnectionState().PeerCertificates; len(peers) > 0 {
+		if err := c.tofuStore.Verify(parsedURL.Hostname(), peers[0]); err != nil {
+			conn.Close()
+			return nil, "", fmt.Errorf("certificate verification failed: %w", err)
+		}
 	}
 
-	return response, nil
+	// Fetch the URL, keeping the header as received in case it is broken
+	if _, err := (&gemini.Request{URL: &requestURL}).WriteTo(conn); err != nil {
+		conn.Close()
+		return nil, "", fmt.Errorf("failed to fetch: %w", err)
+	}
+	recorder := &headerRecorder{ReadCloser: conn}
+	resp, err := gemini.ReadResponse(recorder)
+	if err != nil {
+		conn.Close()
+		if ctx.Err() != nil {
+			return nil, "", fmt.Errorf("failed to fetch: %w", ctx.Err())
+		}
+		if headerErr := diagnoseFetchError(parsedURL, recorder.header, err); headerErr != nil {
+			return nil, "", headerErr
+		}
+		return nil, "", fmt.Errorf("failed to fetch: %w", err)
+	}
+	return resp, urlStr, nil
 }
 
 // IsSuccessStatus checks if a status code indicates success
@@ -0,0 +1,214 @@
+package gemini
+
+import (
+	"bytes"
+	"context"
+	"crypto/tls"
+	"errors"
+	"io"
+	"net"
+	"path/filepath"
+	"testing"
+	"time"
+	"unicode/utf8"
+
+	"git.sr.ht/~adnano/go-gemini/certificate"
+)
+
+// socksRequest is what a fake SOCKS5 proxy saw of one connection
+type socksRequest struct {
+	user string
+	host string
+}
+
+// fakeSOCKS accepts SOCKS5 connections, records the credentials and the
+// requested host, then refuses the connection
+func fakeSOCKS(t *testing.T) (string, <-chan socksRequest) {
+	t.Helper()
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { ln.Close() })
+
+	requests := make(chan socksRequest, 4)
+	go func() {
+		for {
+			conn, err := ln.Accept()
+			if err != nil {
+				return
+			}
+			go func() {
+				defer conn.Close()
+				var req socksRequest
+
+				// Greeting: version, method count, methods
+				buf := make([]byte, 2)
+				if _, err := io.ReadFull(conn, buf); err != nil {
+					return
+				}
+				methods := make([]byte, buf[1])
+				io.ReadFull(conn, methods)
+				method := byte(0x00)
+				for _, m := range methods {
+					if m == 0x02 {
+						method = 0x02
+					}
+				}
+				conn.Write([]byte{0x05, method})
+
+				// Username/password: version, user, password
+				if method == 0x02 {
+					io.ReadFull(conn, buf)
+					user := make([]byte, buf[1])
+					io.ReadFull(conn, user)
+					io.ReadFull(conn, buf[:1])
+					io.ReadFull(conn, make([]byte, buf[0]))
+					req.user = string(user)
+					conn.Write([]byte{0x01, 0x00})
+				}
+
+				// Connect request with a domain name address
+				head := make([]byte, 5)
+				if _, err := io.ReadFull(conn, head); err != nil || head[3] != 0x03 {
+					return
+				}
+				host := make([]byte, head[4])
+				io.ReadFull(conn, host)
+				req.host = string(host)
+				requests <- req
+
+				// Host unreachable
+				conn.Write([]byte{0x05, 0x04, 0x00, 0x01, 0, 0, 0, 0, 0, 0})
+			}()
+		}
+	}()
+	return ln.Addr().String(), requests
+}
+
+func TestClientProxyIsolation(t *testing.T) {
+	addr, requests := fakeSOCKS(t)
+	client := NewClient(nil)
+	if err := client.SetProxy(addr); err != nil {
+		t.Fatal(err)
+	}
+
+	// The proxy resolves the host, so onion services can be reached
+	if _, err := client.Fetch("gemini://example.onion/"); err == nil {
+		t.Fatal("fetch through a refusing proxy succeeded")
+	}
+	if req := <-requests; req.host != "example.onion" || req.user != "" {
+		t.Errorf("proxy saw %+v, want example.onion without credentials", req)
+	}
+
+	// Isolated clients authenticate with their own key
+	for _, key := range []string{"starsearch-tab-1", "starsearch-tab-2"} {
+		client.Isolated(key).Fetch("gemini://example.onion/")
+		if req := <-requests; req.user != key {
+			t.Errorf("proxy saw user %q, want %q", req.user, key)
+		}
+	}
+
+	// Without a proxy there is nothing to isolate
+	if err := client.SetProxy(""); err != nil {
+		t.Fatal(err)
+	}
+	if client.Isolated("starsearch-tab-1") != client {
+		t.Error("Isolated without a proxy returned a new client")
+	}
+}
+
+// fakeCapsule sends reply to every request, keeping the connection open
+// until the test ends if hold is set, and returns the URL to request
+func fakeCapsule(t *testing.T, reply []byte, hold bool) string {
+	t.Helper()
+	cert, err := certificate.Create(certificate.CreateOptions{DNSNames: []string{"localhost"}, Duration: time.Hour})
+	if err != nil {
+		t.Fatal(err)
+	}
+	ln, err := tls.Listen("tcp", "127.0.0.1:0", &tls.Config{Certificates: []tls.Certificate{cert}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	done := make(chan struct{})
+	t.Cleanup(func() {
+		close(done)
+		ln.Close()
+	})
+
+	go func() {
+		for {
+			conn, err := ln.Accept()
+			if err != nil {
+				return
+			}
+			go func() {
+				defer conn.Close()
+				buf := make([]byte, 1026)
+				conn.Read(buf)
+				conn.Write(reply)
+				if hold {
+					<-done
+				}
+			}()
+		}
+	}()
+	_, port, _ := net.SplitHostPort(ln.Addr().String())
+	return "gemini://localhost:" + port + "/file.bin"
+}
+
+func TestFetchTo(t *testing.T) {
+	tofu, err := NewTOFUStore(filepath.Join(t.TempDir(), "known_hosts.json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	client := NewClient(tofu)
+	body := bytes.Repeat([]byte("x"), 100*1024)
+
+	target := fakeCapsule(t, append([]byte("20 application/octet-stream\r\n"), body...), false)
+	var out bytes.Buffer
+	var progress int64
+	resp, err := client.FetchTo(context.Background(), target, &out, func(written int64) { progress = written })
+	if err != nil {
+		t.Fatalf("FetchTo: %v", err)
+	}
+	if resp.Status != 20 || resp.Meta != "application/octet-stream" || resp.Body != nil {
+		t.Errorf("response = %d %q with %d bytes kept", resp.Status, resp.Meta, len(resp.Body))
+	}
+	if !bytes.Equal(out.Bytes(), body) || progress != int64(len(body)) {
+		t.Errorf("wrote %d bytes, reported %d, want %d", out.Len(), progress, len(body))
+	}
+
+	// A download that stalls midway is abandoned when cancelled
+	stalled := fakeCapsule(t, []byte("20 application/zip\r\npart"), true)
+	ctx, cancel := context.WithCancel(context.Background())
+	_, err = client.FetchTo(ctx, stalled, io.Discard, func(written int64) { cancel() })
+	if !errors.Is(err, context.Canceled) {
+		t.Errorf("cancelled download returned %v", err)
+	}
+}
+
+func TestFetchBrokenHeader(t *testing.T) {
+	tofu, err := NewTOFUStore(filepath.Join(t.TempDir(), "known_hosts.json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	client := NewClient(tofu)
+
+	// The header is diagnosed from the bytes of the request made
+	target := fakeCapsule(t, []byte("20 text/gemini\nbody"), false)
+	_, err = client.Fetch(target)
+	var headerErr *HeaderError
+	if !errors.As(err, &headerErr) {
+		t.Fatalf("Fetch returned %v, want a *HeaderError", err)
+	}
+	if headerErr.Kind != HeaderBadTerminator || string(headerErr.Raw) != "20 text/gemini\n" {
+		t.Errorf("header error = %v with %q", headerErr.Kind, headerErr.Raw)
+	}
+
+	// A long header is cut on a rune boundary
+	long := &HeaderError{Kind: HeaderMalformedStatus, URL: target, Raw: bytes.Repeat([]byte("é"), 300)}
+	if page := long.Page(); !utf8.Valid(page) {
+		t.Error("page of a long header is not valid UTF-8")
+	}
+}
@@ -0,0 +1,204 @@
+package gemini
+
+import (
+	"bytes"
+	"crypto/tls"
+	"errors"
+	"fmt"
+	"io"
+	"net/url"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+
+	"git.sr.ht/~adnano/go-gemini"
+)
+
+// maxMetaLength is the longest meta field allowed by the Gemini specification
+const maxMetaLength = 1024
+
+// maxHeaderBytes is the longest header allowed: a status, a space, the
+// meta field and CRLF
+const maxHeaderBytes = len("20 ") + maxMetaLength + len("\r\n")
+
+// HeaderErrorKind classifies why a response header could not be used
+type HeaderErrorKind int
+
+const (
+	HeaderNotTLS          HeaderErrorKind = iota // Server did not speak TLS
+	HeaderMissing                                // Connection closed before any header bytes
+	HeaderTruncated                              // Connection closed mid-header
+	HeaderMalformedStatus                        // Status is not two digits followed by a space
+	HeaderMissingMeta                            // Status present but meta is empty
+	HeaderMetaTooLong                            // Meta exceeds 1024 bytes
+	HeaderBadTerminator                          // Header not terminated by CRLF
+)
+
+// HeaderError describes a server response whose header was absent or malformed
+type HeaderError struct {
+	Kind HeaderErrorKind
+	URL  string
+	Raw  []byte // Header bytes received, if any
+	Err  error  // Underlying error reported by the client
+}
+
+// Error implements the error interface
+func (e *HeaderError) Error() string {
+	return fmt.Sprintf("%s: %s", e.Title(), e.URL)
+}
+
+// Unwrap returns the underlying client error
+func (e *HeaderError) Unwrap() error {
+	return e.Err
+}
+
+// Title returns a short summary of the failure
+func (e *HeaderError) Title() string {
+	switch e.Kind {
+	case HeaderNotTLS:
+		return "Server is not speaking Gemini"
+	case HeaderMissing:
+		return "Server closed the connection without responding"
+	case HeaderTruncated:
+		return "Server closed the connection mid-header"
+	case HeaderMalformedStatus:
+		return "Malformed status code"
+	case HeaderMissingMeta:
+		return "Response header has no meta field"
+	case HeaderMetaTooLong:
+		return "Response header is too long"
+	case HeaderBadTerminator:
+		return "Response header is not terminated by CRLF"
+	default:
+		return "Invalid response header"
+	}
+}
+
+// Explanation returns a longer description of the failure
+func (e *HeaderError) Explanation() string {
+	switch e.Kind {
+	case HeaderNotTLS:
+		return "The TLS handshake failed because the server answered with something other than TLS. The port may belong to a different service, such as a web server."
+	case HeaderMissing:
+		return "The connection was accepted, but the server closed it before sending a response header. The capsule may be misconfigured, overloaded, or not a Gemini server at all."
+	case HeaderTruncated:
+		return "The server started a response header but closed the connection before finishing it."
+	case HeaderMalformedStatus:
+		return "A Gemini header must start with a two-digit status code followed by a space. This server sent something else."
+	case HeaderMissingMeta:
+		return "The server sent a status code but no meta field (MIME type, prompt, redirect target or error message)."
+	case HeaderMetaTooLong:
+		return fmt.Sprintf("The meta field of a Gemini header may be at most %d bytes. This server sent a longer one.", maxMetaLength)
+	case HeaderBadTerminator:
+		return "Gemini headers must end with a carriage return and line feed (CRLF). This server ended the header with a bare line feed."
+	default:
+		return "The server's response header could not be understood."
+	}
+}
+
+// Page renders the error as a text/gemini document
+func (e *HeaderError) Page() []byte {
+	var b strings.Builder
+
+	b.WriteString("# " + e.Title() + "\n\n")
+	b.WriteString(e.Explanation() + "\n\n")
+
+	if len(e.Raw) > 0 {
+		raw := strconv.Quote(string(e.Raw))
+		if len(raw) > 200 {
+			// Cut on a rune boundary, as the quote keeps printable
+			// multi-byte runes as they are
+			cut := 200
+			for cut > 0 && !utf8.RuneStart(raw[cut]) {
+				cut--
+			}
+			raw = raw[:cut] + "…"
+		}
+		b.WriteString("## Header received\n\n")
+		b.WriteString("```\n" + raw + "\n```\n\n")
+	}
+
+	b.WriteString("## What you can try\n\n")
+	b.WriteString("* Check that the address and port are correct\n")
+	b.WriteString("* Wait a moment and try again; the server may be restarting\n")
+	b.WriteString("* If the problem persists, let the capsule's author know\n\n")
+	b.WriteString("=> " + e.URL + " Try again\n")
+
+	return []byte(b.String())
+}
+
+// diagnoseFetchError turns opaque header failures into a *HeaderError,
+// using header, the bytes the server sent before the client gave up on
+// them. It returns nil when err is not a header problem.
+func diagnoseFetchError(u *url.URL, header []byte, err error) *HeaderError {
+	var recordErr tls.RecordHeaderError
+	switch {
+	case errors.As(err, &recordErr):
+		return &HeaderError{Kind: HeaderNotTLS, URL: u.String(), Err: err}
+	case errors.Is(err, io.EOF), errors.Is(err, io.ErrUnexpectedEOF):
+		return &HeaderError{Kind: HeaderMissing, URL: u.String(), Err: err}
+	case errors.Is(err, gemini.ErrInvalidResponse):
+		if i := bytes.IndexByte(header, '\n'); i >= 0 {
+			header = header[:i+1]
+		}
+		if headerErr := classifyHeader(header); headerErr != nil {
+			headerErr.URL = u.String()
+			headerErr.Err = err
+			return headerErr
+		}
+	}
+	return nil
+}
+
+// headerRecorder keeps the first bytes read through it, so that a header
+// the client rejects can be diagnosed from what the server actually sent
+type headerRecorder struct {
+	io.ReadCloser
+	header []byte
+}
+
+// Read implements io.Reader
+func (r *headerRecorder) Read(p []byte) (int, error) {
+	n, err := r.ReadCloser.Read(p)
+	if room := maxHeaderBytes - len(r.header); room > 0 {
+		r.header = append(r.header, p[:min(n, room)]...)
+	}
+	return n, err
+}
+
+// classifyHeader reports what is wrong with a raw header line, or nil if
+// it is well formed
+func classifyHeader(raw []byte) *HeaderError {
+	if len(raw) == 0 {
+		return &HeaderError{Kind: HeaderMissing}
+	}
+
+	line, ok := bytes.CutSuffix(raw, []byte("\n"))
+	if !ok {
+		if len(raw) > len("20 ")+maxMetaLength {
+			return &HeaderError{Kind: HeaderMetaTooLong, Raw: raw}
+		}
+		return &HeaderError{Kind: HeaderTruncated, Raw: raw}
+	}
+
+	line, ok = bytes.CutSuffix(line, []byte("\r"))
+	if len(line) < 2 || line[0] < '0' || line[0] > '9' || line[1] < '0' || line[1] > '9' {
+		return &HeaderError{Kind: HeaderMalformedStatus, Raw: raw}
+	}
+	if len(line) == 2 {
+		return &HeaderError{Kind: HeaderMissingMeta, Raw: raw}
+	}
+	if line[2] != ' ' {
+		return &HeaderError{Kind: HeaderMalformedStatus, Raw: raw}
+	}
+	if len(line) == 3 {
+		return &HeaderError{Kind: HeaderMissingMeta, Raw: raw}
+	}
+	if len(line)-3 > maxMetaLength {
+		return &HeaderError{Kind: HeaderMetaTooLong, Raw: raw}
+	}
+	if !ok {
+		return &HeaderError{Kind: HeaderBadTerminator, Raw: raw}
+	}
+	return nil
+}
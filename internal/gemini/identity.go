@@ -0,0 +1,614 @@
+package gemini
+
+import (
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/url"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"git.sr.ht/~adnano/go-gemini/certificate"
+	"starsearch/internal/types"
+)
+
+// maxUsedURLs is how many URLs are remembered per identity
+const maxUsedURLs = 100
+
+// Key types for generated identities
+const (
+	KeyEd25519 = "ed25519"
+	KeyECDSA   = "ecdsa" // ECDSA with the P-256 curve
+)
+
+var ErrIdentityExists = errors.New("an identity with that name already exists")
+
+// IdentityOptions are the parameters of a new client certificate
+type IdentityOptions struct {
+	Name       string        // File name of the identity, also used as the common name if that is empty
+	CommonName string        // Subject common name shown to servers
+	KeyType    string        // KeyEd25519 or KeyECDSA
+	Validity   time.Duration // How long the certificate is valid for
+}
+
+// Identity is a client certificate with its private key
+type Identity struct {
+	Name        string
+	Certificate tls.Certificate
+	Scopes      []string // URL prefixes the identity is presented on
+	UsedOn      []string // URLs the identity was presented on, most recent first
+}
+
+// IdentityChoiceError is returned for a request that more than one
+// identity is scoped to, until one is chosen with Choose
+type IdentityChoiceError struct {
+	URL   string
+	Names []string
+}
+
+func (e *IdentityChoiceError) Error() string {
+	return fmt.Sprintf("%d identities match %s: %s", len(e.Names), e.URL, strings.Join(e.Names, ", "))
+}
+
+// Info describes the identity for display
+func (i *Identity) Info() types.IdentityInfo {
+	leaf := i.Certificate.Leaf
+	return types.IdentityInfo{
+		Name:        i.Name,
+		CommonName:  leaf.Subject.CommonName,
+		KeyType:     keyType(leaf),
+		Fingerprint: calculateFingerprint(leaf),
+		NotBefore:   leaf.NotBefore,
+		NotAfter:    leaf.NotAfter,
+		Scopes:      i.Scopes,
+		UsedOn:      i.UsedOn,
+	}
+}
+
+// keyType names the kind of public key in a certificate
+func keyType(cert *x509.Certificate) string {
+	switch cert.PublicKey.(type) {
+	case ed25519.PublicKey:
+		return KeyEd25519
+	case *ecdsa.PublicKey:
+		return KeyECDSA
+	default:
+		return strings.ToLower(cert.PublicKeyAlgorithm.String())
+	}
+}
+
+// IdentityStore keeps client certificates as <name>.crt and <name>.key
+// PEM files in a directory only the user can read, the URL prefixes each
+// is scoped to in scopes.json and the URLs each was used on in usage.json
+type IdentityStore struct {
+	mu        sync.Mutex
+	dir       string
+	scopes    map[string][]string // identity name -> URL prefixes
+	used      map[string][]string // identity name -> URLs it was presented on
+	chosen    map[string]string   // host -> identity picked where several match, for this session
+	overrides map[string]string   // host -> identity presented regardless of scopes, for this session
+	skip      map[string]bool     // URLs whose next request is sent without a certificate
+}
+
+// NewIdentityStore creates a store for the identities in dir
+func NewIdentityStore(dir string) *IdentityStore {
+	s := &IdentityStore{
+		dir:       dir,
+		scopes:    make(map[string][]string),
+		used:      make(map[string][]string),
+		chosen:    make(map[string]string),
+		overrides: make(map[string]string),
+		skip:      make(map[string]bool),
+	}
+	// Missing or unreadable files leave every identity unscoped and unused
+	if data, err := os.ReadFile(s.scopesPath()); err == nil {
+		_ = json.Unmarshal(data, &s.scopes)
+	}
+	if data, err := os.ReadFile(s.usagePath()); err == nil {
+		_ = json.Unmarshal(data, &s.used)
+	}
+	return s
+}
+
+// NormalizeScope turns a URL prefix into the canonical form scopes are
+// stored in: a gemini:// URL with a lower-case host and at least "/" as path
+func NormalizeScope(prefix string) (string, error) {
+	if !strings.Contains(prefix, "://") {
+		prefix = "gemini://" + prefix
+	}
+	u, err := url.Parse(prefix)
+	if err != nil {
+		return "", fmt.Errorf("invalid scope %q: %w", prefix, err)
+	}
+	if u.Scheme != "gemini" || u.Host == "" {
+		return "", fmt.Errorf("invalid scope %q: must be a gemini:// host and path prefix", prefix)
+	}
+	u.Host = strings.ToLower(u.Host)
+	if u.Port() == "1965" {
+		u.Host = u.Hostname()
+	}
+	if u.Path == "" {
+		u.Path = "/"
+	}
+	u.RawQuery = ""
+	u.Fragment = ""
+	return u.String(), nil
+}
+
+// scopeMatches reports whether a normalized scope covers urlStr
+func scopeMatches(scope, urlStr string) bool {
+	target, err := NormalizeScope(urlStr)
+	if err != nil {
+		return false
+	}
+	return strings.HasPrefix(target, scope)
+}
+
+// Match returns the identities to present for urlStr: those whose most
+// specific (longest) scope covering the URL is the longest of any
+// identity. An identity picked with Choose wins over the others, and one
+// set with Override is the only match on its host.
+func (s *IdentityStore) Match(urlStr string) []*Identity {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var identities []*Identity
+	for _, name := range s.matchNames(urlStr) {
+		if identity, err := s.load(name); err == nil {
+			identities = append(identities, identity)
+		}
+	}
+	return identities
+}
+
+// MatchNames is Match without loading the identities, for showing which
+// would be presented
+func (s *IdentityStore) MatchNames(urlStr string) []string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.matchNames(urlStr)
+}
+
+// matchNames returns the names of the identities Match returns (must be
+// called with lock held)
+func (s *IdentityStore) matchNames(urlStr string) []string {
+	if name, ok := s.overrides[hostOf(urlStr)]; ok {
+		certPath, _ := s.paths(name)
+		if _, err := os.Stat(certPath); err == nil {
+			return []string{name}
+		}
+	}
+
+	best := 0
+	var names []string
+	for name, scopes := range s.scopes {
+		longest := 0
+		for _, scope := range scopes {
+			if len(scope) > longest && scopeMatches(scope, urlStr) {
+				longest = len(scope)
+			}
+		}
+		switch {
+		case longest == 0 || longest < best:
+		case longest > best:
+			best = longest
+			names = []string{name}
+		default:
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+
+	if chosen, ok := s.chosen[hostOf(urlStr)]; ok && len(names) > 1 {
+		for _, name := range names {
+			if name == chosen {
+				return []string{name}
+			}
+		}
+	}
+	return names
+}
+
+// Certificate returns the client certificate to present for urlStr, nil
+// if no identity is scoped to it, or an *IdentityChoiceError if several are.
+// Presenting an identity records the URL in its usage.
+func (s *IdentityStore) Certificate(urlStr string) (*tls.Certificate, error) {
+	s.mu.Lock()
+	skip := s.skip[urlStr]
+	delete(s.skip, urlStr)
+	s.mu.Unlock()
+	if skip {
+		return nil, nil
+	}
+
+	identities := s.Match(urlStr)
+	switch len(identities) {
+	case 0:
+		return nil, nil
+	case 1:
+		s.recordUse(identities[0].Name, urlStr)
+		return &identities[0].Certificate, nil
+	}
+	names := make([]string, len(identities))
+	for i, identity := range identities {
+		names[i] = identity.Name
+	}
+	return nil, &IdentityChoiceError{URL: urlStr, Names: names}
+}
+
+// Choose settles which identity to present on urlStr's host where several
+// match, until the browser is closed
+func (s *IdentityStore) Choose(urlStr, name string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.chosen[hostOf(urlStr)] = name
+}
+
+// Override presents the named identity on every URL of urlStr's host,
+// whatever it is scoped to, until the browser is closed
+func (s *IdentityStore) Override(urlStr, name string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.overrides[hostOf(urlStr)] = name
+}
+
+// SkipOnce sends the next request for urlStr without a client certificate
+func (s *IdentityStore) SkipOnce(urlStr string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.skip[urlStr] = true
+}
+
+// recordUse remembers that an identity was presented on urlStr. The query
+// is left out, as it may hold input the user typed.
+func (s *IdentityStore) recordUse(name, urlStr string) {
+	if u, err := url.Parse(urlStr); err == nil {
+		u.RawQuery = ""
+		u.Fragment = ""
+		urlStr = u.String()
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	used := s.used[name]
+	if len(used) > 0 && used[0] == urlStr {
+		return
+	}
+	recent := []string{urlStr}
+	for _, previous := range used {
+		if previous != urlStr && len(recent) < maxUsedURLs {
+			recent = append(recent, previous)
+		}
+	}
+	s.used[name] = recent
+	_ = s.saveUsage() // Usage is informational; losing it is harmless
+}
+
+// saveUsage writes usage.json (must be called with lock held)
+func (s *IdentityStore) saveUsage() error {
+	if err := os.MkdirAll(s.dir, 0700); err != nil {
+		return fmt.Errorf("failed to create identity directory: %w", err)
+	}
+	data, err := json.MarshalIndent(s.used, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal usage: %w", err)
+	}
+	if err := os.WriteFile(s.usagePath(), data, 0600); err != nil {
+		return fmt.Errorf("failed to write usage: %w", err)
+	}
+	return nil
+}
+
+// usagePath returns the path of the usage file
+func (s *IdentityStore) usagePath() string {
+	return filepath.Join(s.dir, "usage.json")
+}
+
+// hostOf returns the lower-case host of a URL, or "" if it has none
+func hostOf(urlStr string) string {
+	u, err := url.Parse(urlStr)
+	if err != nil {
+		return ""
+	}
+	return strings.ToLower(u.Hostname())
+}
+
+// HostIdentities returns, for each host, the names of the identities
+// presented there or scoped to it
+func (s *IdentityStore) HostIdentities() map[string][]string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	seen := make(map[string]map[string]bool)
+	add := func(host, name string) {
+		if host == "" {
+			return
+		}
+		if seen[host] == nil {
+			seen[host] = make(map[string]bool)
+		}
+		seen[host][name] = true
+	}
+	for name, urls := range s.used {
+		for _, u := range urls {
+			add(hostOf(u), name)
+		}
+	}
+	for name, scopes := range s.scopes {
+		for _, scope := range scopes {
+			add(hostOf(scope), name)
+		}
+	}
+
+	hosts := make(map[string][]string, len(seen))
+	for host, names := range seen {
+		for name := range names {
+			hosts[host] = append(hosts[host], name)
+		}
+		sort.Strings(hosts[host])
+	}
+	return hosts
+}
+
+// ForgetHost stops presenting identities on host: their scopes there and
+// the record of where they were presented are removed, along with any
+// choice made for it this session. The identities themselves are kept.
+func (s *IdentityStore) ForgetHost(host string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	delete(s.chosen, host)
+	delete(s.overrides, host)
+	for name, urls := range s.used {
+		kept := urls[:0]
+		for _, u := range urls {
+			if hostOf(u) != host {
+				kept = append(kept, u)
+			}
+		}
+		if len(kept) == 0 {
+			delete(s.used, name)
+		} else {
+			s.used[name] = kept
+		}
+	}
+	for name, scopes := range s.scopes {
+		kept := scopes[:0]
+		for _, scope := range scopes {
+			if hostOf(scope) != host {
+				kept = append(kept, scope)
+			}
+		}
+		if len(kept) == 0 {
+			delete(s.scopes, name)
+		} else {
+			s.scopes[name] = kept
+		}
+	}
+
+	if err := s.saveUsage(); err != nil {
+		return err
+	}
+	return s.saveScopes()
+}
+
+// AddScope scopes an identity to one more URL prefix
+func (s *IdentityStore) AddScope(name, prefix string) error {
+	scope, err := NormalizeScope(prefix)
+	if err != nil {
+		return err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for _, existing := range s.scopes[name] {
+		if existing == scope {
+			return nil
+		}
+	}
+	s.scopes[name] = append(s.scopes[name], scope)
+	return s.saveScopes()
+}
+
+// SetScopes replaces the URL prefixes an identity is scoped to
+func (s *IdentityStore) SetScopes(name string, prefixes []string) error {
+	var scopes []string
+	for _, prefix := range prefixes {
+		scope, err := NormalizeScope(prefix)
+		if err != nil {
+			return err
+		}
+		scopes = append(scopes, scope)
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if len(scopes) == 0 {
+		delete(s.scopes, name)
+	} else {
+		s.scopes[name] = scopes
+	}
+	return s.saveScopes()
+}
+
+// saveScopes writes scopes.json (must be called with lock held)
+func (s *IdentityStore) saveScopes() error {
+	if err := os.MkdirAll(s.dir, 0700); err != nil {
+		return fmt.Errorf("failed to create identity directory: %w", err)
+	}
+	data, err := json.MarshalIndent(s.scopes, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal scopes: %w", err)
+	}
+	if err := os.WriteFile(s.scopesPath(), data, 0600); err != nil {
+		return fmt.Errorf("failed to write scopes: %w", err)
+	}
+	return nil
+}
+
+// scopesPath returns the path of the scope file
+func (s *IdentityStore) scopesPath() string {
+	return filepath.Join(s.dir, "scopes.json")
+}
+
+// validIdentityName reports whether name is usable as a file name
+func validIdentityName(name string) bool {
+	if name == "" || name == "." || name == ".." {
+		return false
+	}
+	for _, r := range name {
+		switch {
+		case r >= 'a' && r <= 'z', r >= 'A' && r <= 'Z', r >= '0' && r <= '9', r == '-', r == '_', r == '.':
+		default:
+			return false
+		}
+	}
+	return true
+}
+
+// Generate creates a new identity and saves it
+func (s *IdentityStore) Generate(opts IdentityOptions) (*Identity, error) {
+	if !validIdentityName(opts.Name) {
+		return nil, fmt.Errorf("invalid identity name %q: use letters, digits, '-', '_' and '.'", opts.Name)
+	}
+	if opts.KeyType != KeyEd25519 && opts.KeyType != KeyECDSA {
+		return nil, fmt.Errorf("unsupported key type %q (use %s or %s)", opts.KeyType, KeyEd25519, KeyECDSA)
+	}
+	if opts.Validity <= 0 {
+		return nil, errors.New("validity must be positive")
+	}
+	commonName := opts.CommonName
+	if commonName == "" {
+		commonName = opts.Name
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	certPath, keyPath := s.paths(opts.Name)
+	if _, err := os.Stat(certPath); err == nil {
+		return nil, ErrIdentityExists
+	}
+	if err := os.MkdirAll(s.dir, 0700); err != nil {
+		return nil, fmt.Errorf("failed to create identity directory: %w", err)
+	}
+
+	cert, err := certificate.Create(certificate.CreateOptions{
+		Subject:  pkix.Name{CommonName: commonName},
+		Duration: opts.Validity,
+		Ed25519:  opts.KeyType == KeyEd25519,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to create certificate: %w", err)
+	}
+	// Both files are written with 0600 permissions
+	if err := certificate.Write(cert, certPath, keyPath); err != nil {
+		os.Remove(certPath)
+		os.Remove(keyPath)
+		return nil, fmt.Errorf("failed to save identity: %w", err)
+	}
+
+	return &Identity{Name: opts.Name, Certificate: cert}, nil
+}
+
+// List returns every stored identity, sorted by name. Files that fail to
+// load are skipped.
+func (s *IdentityStore) List() ([]*Identity, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	entries, err := os.ReadDir(s.dir)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to read identities: %w", err)
+	}
+
+	var identities []*Identity
+	for _, entry := range entries {
+		name, ok := strings.CutSuffix(entry.Name(), ".crt")
+		if !ok || entry.IsDir() {
+			continue
+		}
+		identity, err := s.load(name)
+		if err != nil {
+			continue
+		}
+		identities = append(identities, identity)
+	}
+	sort.Slice(identities, func(i, j int) bool {
+		return identities[i].Name < identities[j].Name
+	})
+	return identities, nil
+}
+
+// Get loads the identity with the given name
+func (s *IdentityStore) Get(name string) (*Identity, error) {
+	if !validIdentityName(name) {
+		return nil, fmt.Errorf("invalid identity name %q", name)
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.load(name)
+}
+
+// Delete removes an identity's certificate and key
+func (s *IdentityStore) Delete(name string) error {
+	if !validIdentityName(name) {
+		return fmt.Errorf("invalid identity name %q", name)
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	certPath, keyPath := s.paths(name)
+	if err := os.Remove(certPath); err != nil {
+		return fmt.Errorf("failed to delete identity: %w", err)
+	}
+	if err := os.Remove(keyPath); err != nil && !os.IsNotExist(err) {
+		return fmt.Errorf("failed to delete identity key: %w", err)
+	}
+	if _, ok := s.used[name]; ok {
+		delete(s.used, name)
+		_ = s.saveUsage()
+	}
+	if _, ok := s.scopes[name]; ok {
+		delete(s.scopes, name)
+		return s.saveScopes()
+	}
+	return nil
+}
+
+// load reads an identity from disk (must be called with lock held)
+func (s *IdentityStore) load(name string) (*Identity, error) {
+	certPath, keyPath := s.paths(name)
+	cert, err := tls.LoadX509KeyPair(certPath, keyPath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load identity %s: %w", name, err)
+	}
+	return &Identity{Name: name, Certificate: cert, Scopes: s.scopes[name], UsedOn: s.used[name]}, nil
+}
+
+// paths returns the certificate and key file paths for an identity
+func (s *IdentityStore) paths(name string) (string, string) {
+	return filepath.Join(s.dir, name+".crt"), filepath.Join(s.dir, name+".key")
+}
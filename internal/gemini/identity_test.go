@@ -0,0 +1,188 @@
+package gemini
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestIdentityStore(t *testing.T) {
+	dir := filepath.Join(t.TempDir(), "identities")
+	store := NewIdentityStore(dir)
+
+	for _, opts := range []IdentityOptions{
+		{Name: "plant", KeyType: KeyEd25519, Validity: 24 * time.Hour},
+		{Name: "station", CommonName: "Ground Control", KeyType: KeyECDSA, Validity: 365 * 24 * time.Hour},
+	} {
+		if _, err := store.Generate(opts); err != nil {
+			t.Fatalf("Generate(%s): %v", opts.Name, err)
+		}
+	}
+
+	for _, file := range []string{"plant.crt", "plant.key", "station.crt", "station.key"} {
+		info, err := os.Stat(filepath.Join(dir, file))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if perm := info.Mode().Perm(); perm != 0600 {
+			t.Errorf("%s has permissions %o, want 600", file, perm)
+		}
+	}
+
+	identities, err := store.List()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(identities) != 2 {
+		t.Fatalf("listed %d identities, want 2", len(identities))
+	}
+	plant, station := identities[0].Info(), identities[1].Info()
+	if plant.CommonName != "plant" || plant.KeyType != KeyEd25519 {
+		t.Errorf("plant: common name %q, key type %q", plant.CommonName, plant.KeyType)
+	}
+	if station.CommonName != "Ground Control" || station.KeyType != KeyECDSA {
+		t.Errorf("station: common name %q, key type %q", station.CommonName, station.KeyType)
+	}
+	if d := time.Until(plant.NotAfter); d > 24*time.Hour || d < 23*time.Hour {
+		t.Errorf("plant expires in %v, want about a day", d)
+	}
+
+	if _, err := store.Generate(IdentityOptions{Name: "plant", KeyType: KeyEd25519, Validity: time.Hour}); !errors.Is(err, ErrIdentityExists) {
+		t.Errorf("duplicate name: got %v, want ErrIdentityExists", err)
+	}
+	if _, err := store.Generate(IdentityOptions{Name: "../escape", KeyType: KeyEd25519, Validity: time.Hour}); err == nil {
+		t.Error("a name with a path separator was accepted")
+	}
+	if _, err := store.Generate(IdentityOptions{Name: "rsa", KeyType: "rsa", Validity: time.Hour}); err == nil {
+		t.Error("an unsupported key type was accepted")
+	}
+
+	if err := store.Delete("plant"); err != nil {
+		t.Fatal(err)
+	}
+	if identities, _ := store.List(); len(identities) != 1 || identities[0].Name != "station" {
+		t.Errorf("after delete: %d identities", len(identities))
+	}
+}
+
+func TestIdentityScopes(t *testing.T) {
+	dir := t.TempDir()
+	store := NewIdentityStore(dir)
+	for _, name := range []string{"garden", "plant", "station"} {
+		if _, err := store.Generate(IdentityOptions{Name: name, KeyType: KeyEd25519, Validity: time.Hour}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := store.AddScope("garden", "astrobotany.example"); err != nil {
+		t.Fatal(err)
+	}
+	if err := store.AddScope("plant", "gemini://Astrobotany.example:1965/app/plant"); err != nil {
+		t.Fatal(err)
+	}
+	if err := store.AddScope("station", "gemini://astrobotany.example/app/plant"); err != nil {
+		t.Fatal(err)
+	}
+
+	names := func(urlStr string) []string {
+		var names []string
+		for _, identity := range store.Match(urlStr) {
+			names = append(names, identity.Name)
+		}
+		return names
+	}
+	tests := []struct {
+		url  string
+		want string
+	}{
+		{"gemini://astrobotany.example/", "garden"},
+		{"gemini://astrobotany.example/app/settings", "garden"},
+		{"gemini://astrobotany.example/app/plant/water", "plant station"},
+		{"gemini://astrobotany.example.evil/", ""},
+		{"gemini://other.example/", ""},
+	}
+	for _, tt := range tests {
+		if got := strings.Join(names(tt.url), " "); got != tt.want {
+			t.Errorf("Match(%s) = %q, want %q", tt.url, got, tt.want)
+		}
+	}
+
+	// Two equally specific scopes need a choice
+	var choiceErr *IdentityChoiceError
+	if _, err := store.Certificate("gemini://astrobotany.example/app/plant"); !errors.As(err, &choiceErr) {
+		t.Fatalf("ambiguous match: got %v, want an IdentityChoiceError", err)
+	}
+	store.Choose("gemini://astrobotany.example/app/plant", "station")
+	if got := names("gemini://astrobotany.example/app/plant"); len(got) != 1 || got[0] != "station" {
+		t.Errorf("after choosing: %v", got)
+	}
+
+	// Scopes survive a restart and are dropped with their identity
+	reloaded := NewIdentityStore(dir)
+	if got := len(reloaded.Match("gemini://astrobotany.example/app/plant")); got != 2 {
+		t.Errorf("reloaded store matched %d identities, want 2", got)
+	}
+	if err := reloaded.Delete("plant"); err != nil {
+		t.Fatal(err)
+	}
+	if got := NewIdentityStore(dir).Match("gemini://astrobotany.example/app/plant"); len(got) != 1 {
+		t.Errorf("scopes of the deleted identity still match: %d identities", len(got))
+	}
+}
+
+func TestIdentityQuickActions(t *testing.T) {
+	dir := t.TempDir()
+	store := NewIdentityStore(dir)
+	for _, name := range []string{"alice", "bob"} {
+		if _, err := store.Generate(IdentityOptions{Name: name, KeyType: KeyEd25519, Validity: time.Hour}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := store.AddScope("alice", "station.example"); err != nil {
+		t.Fatal(err)
+	}
+
+	presented := func(urlStr string) string {
+		cert, err := store.Certificate(urlStr)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if cert == nil {
+			return ""
+		}
+		return cert.Leaf.Subject.CommonName
+	}
+
+	if got := presented("gemini://station.example/inbox?secret"); got != "alice" {
+		t.Fatalf("presented %q, want alice", got)
+	}
+
+	// Skipping covers exactly one request
+	store.SkipOnce("gemini://station.example/inbox")
+	if got := presented("gemini://station.example/inbox"); got != "" {
+		t.Errorf("presented %q on a skipped request", got)
+	}
+	if got := presented("gemini://station.example/inbox"); got != "alice" {
+		t.Errorf("presented %q after the skipped request, want alice", got)
+	}
+
+	// An override wins over scopes on the whole host
+	store.Override("gemini://station.example/", "bob")
+	if got := presented("gemini://station.example/outbox"); got != "bob" {
+		t.Errorf("presented %q after the override, want bob", got)
+	}
+
+	// Usage is kept without queries, most recent first, across restarts
+	identities, err := NewIdentityStore(dir).List()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := strings.Join(identities[0].UsedOn, " "); got != "gemini://station.example/inbox" {
+		t.Errorf("alice used on %q", got)
+	}
+	if got := strings.Join(identities[1].UsedOn, " "); got != "gemini://station.example/outbox" {
+		t.Errorf("bob used on %q", got)
+	}
+}
@@ -6,6 +6,7 @@ import (
 	"net/url"
 	"strings"
 
+	"starsearch/internal/textutil"
 	"starsearch/internal/types"
 )
 
@@ -36,6 +37,7 @@ func (p *Parser) Parse(resp *types.Response) (*types.Document, error) {
 		RawBody:  resp.Body,
 		Lines:    make([]types.Line, 0),
 		Links:    make([]types.Line, 0),
+		Metadata: resp.Metadata(),
 		MIMEType: GetMIMEType(resp),
 	}
 
@@ -45,14 +47,21 @@ func (p *Parser) Parse(resp *types.Response) (*types.Document, error) {
 		return doc, nil
 	}
 
-	// Parse line by line
-	scanner := bufio.NewScanner(bytes.NewReader(resp.Body))
+	// Parse line by line, whichever line endings the body uses
+	doc.Metadata.LineEndings = textutil.LineEndings(resp.Body)
+	scanner := bufio.NewScanner(bytes.NewReader(textutil.NormalizeLineEndings(resp.Body)))
 	inPreformat := false
 	linkNum := 1
 
+	controls := 0
+
 	for scanner.Scan() {
 		rawLine := scanner.Text()
 		line := p.parseLine(rawLine, &inPreformat, &linkNum)
+		if textutil.HasControl(line.Text) {
+			controls++
+		}
+		line.Text = textutil.Sanitize(line.Text)
 		doc.Lines = append(doc.Lines, line)
 
 		// Track links separately for easy access
@@ -61,6 +70,9 @@ func (p *Parser) Parse(resp *types.Response) (*types.Document, error) {
 		}
 	}
 
+	if controls > 0 {
+		doc.AddWarning("control characters were removed from lines", controls)
+	}
 	return doc, scanner.Err()
 }
 
@@ -160,13 +172,18 @@ func (p *Parser) parseLine(rawLine string, inPreformat *bool, linkNum *int) type
 	return line
 }
 
-// GetTitle attempts to extract a title from the document (first heading)
+// titleLines is how far into a document a heading may be and still name it.
+// Headings further down title sections, not the page.
+const titleLines = 20
+
+// GetTitle extracts a title from the document: its first level 1 heading,
+// or failing that its first level 2 heading, within the first titleLines
+// lines. Without one the host names the page.
 func GetTitle(doc *types.Document) string {
-	for _, line := range doc.Lines {
-		if line.Type == types.LineHeading1 ||
-		   line.Type == types.LineHeading2 ||
-		   line.Type == types.LineHeading3 {
-			if line.Text != "" {
+	head := doc.Lines[:min(len(doc.Lines), titleLines)]
+	for _, level := range []types.LineType{types.LineHeading1, types.LineHeading2} {
+		for _, line := range head {
+			if line.Type == level && line.Text != "" {
 				return line.Text
 			}
 		}
@@ -174,7 +191,7 @@ func GetTitle(doc *types.Document) string {
 
 	// No heading found, try to use URL
 	parsed, err := url.Parse(doc.URL)
-	if err == nil {
+	if err == nil && parsed.Host != "" {
 		return parsed.Host
 	}
 
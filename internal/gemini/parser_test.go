@@ -0,0 +1,95 @@
+package gemini
+
+import (
+	"strings"
+	"testing"
+
+	"starsearch/internal/types"
+)
+
+func TestGetTitle(t *testing.T) {
+	tests := []struct {
+		name string
+		body string
+		want string
+	}{
+		{"first level 1 heading", "## Section\n# Page\n# Other\n", "Page"},
+		{"level 2 without level 1", "### Small\nText\n## Section\n", "Section"},
+		{"level 3 only", "### Small\n", "example.org"},
+		{"heading far down", strings.Repeat("Text\n", titleLines) + "# Late\n", "example.org"},
+		{"no heading", "Just text\n", "example.org"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			doc, err := NewParser("gemini://example.org/").Parse(&types.Response{
+				Status: 20, Meta: "text/gemini", Body: []byte(tt.body), URL: "gemini://example.org/page.gmi",
+			})
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got := GetTitle(doc); got != tt.want {
+				t.Errorf("GetTitle = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseRemovesControlCharacters(t *testing.T) {
+	body := "# Ding\a Dong\n=> /next Next\x1b[2J page   \nplain\b text \u009b\n```\nart\tkept\n```\n"
+	doc, err := NewParser("gemini://example.org/").Parse(&types.Response{
+		Status: 20, Meta: "text/gemini", Body: []byte(body), URL: "gemini://example.org/",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := []string{"Ding Dong", "Next[2J page", "plain text", "", "art\tkept", ""}
+	for i, text := range want {
+		if got := doc.Lines[i].Text; got != text {
+			t.Errorf("line %d = %q, want %q", i, got, text)
+		}
+	}
+	if doc.Links[0].Text != "Next[2J page" || doc.Links[0].URL != "gemini://example.org/next" {
+		t.Errorf("link = %+v", doc.Links[0])
+	}
+	if doc.Lines[1].Raw != "=> /next Next\x1b[2J page   " {
+		t.Errorf("raw line changed to %q", doc.Lines[1].Raw)
+	}
+	if len(doc.Warnings) != 1 || doc.Warnings[0].Count != 3 {
+		t.Errorf("warnings = %+v, want 3 lines with control characters", doc.Warnings)
+	}
+}
+
+func TestParseNormalizesLineEndings(t *testing.T) {
+	tests := []struct {
+		name string
+		body string
+		want string
+	}{
+		{"LF", "# Title\nText\n", "LF"},
+		{"CRLF", "# Title\r\nText\r\n", "CRLF"},
+		{"bare CR", "# Title\rText\r", "CR"},
+		{"mixed", "# Title\r\nText\rMore\n", "mixed"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			doc, err := NewParser("gemini://example.org/").Parse(&types.Response{
+				Status: 20, Meta: "text/gemini", Body: []byte(tt.body), URL: "gemini://example.org/",
+			})
+			if err != nil {
+				t.Fatal(err)
+			}
+			if doc.Lines[0].Text != "Title" || doc.Lines[1].Text != "Text" {
+				t.Errorf("lines = %+v", doc.Lines)
+			}
+			for _, line := range doc.Lines {
+				if strings.Contains(line.Raw, "\r") {
+					t.Errorf("CR left in line %q", line.Raw)
+				}
+			}
+			if doc.Metadata.LineEndings != tt.want {
+				t.Errorf("line endings recorded as %q, want %q", doc.Metadata.LineEndings, tt.want)
+			}
+		})
+	}
+}
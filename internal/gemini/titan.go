@@ -0,0 +1,185 @@
+package gemini
+
+import (
+	"bufio"
+	"context"
+	"crypto/tls"
+	"fmt"
+	"io"
+	"net"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"starsearch/internal/types"
+)
+
+// TitanUpload is content to write to a Titan-enabled capsule
+type TitanUpload struct {
+	URL   string // titan:// URL of the resource to write
+	Body  []byte
+	MIME  string // Media type of Body; servers assume text/gemini if empty
+	Token string // For capsules that only accept uploads with a token
+}
+
+// RequestURL returns the URL sent for the upload: its URL with the size,
+// media type and token appended as path parameters. Parameters already on
+// the URL are replaced.
+func (u TitanUpload) RequestURL() (string, error) {
+	parsed, err := url.Parse(u.URL)
+	if err != nil {
+		return "", fmt.Errorf("invalid URL: %w", err)
+	}
+	if parsed.Scheme != "titan" || parsed.Host == "" {
+		return "", fmt.Errorf("not a titan:// URL: %s", u.URL)
+	}
+
+	path := parsed.EscapedPath()
+	if i := strings.Index(path, ";"); i >= 0 {
+		path = path[:i]
+	}
+	if path == "" {
+		path = "/"
+	}
+	params := fmt.Sprintf(";size=%d", len(u.Body))
+	if u.MIME != "" {
+		params += ";mime=" + url.PathEscape(u.MIME)
+	}
+	if u.Token != "" {
+		params += ";token=" + url.PathEscape(u.Token)
+	}
+
+	requestURL := "titan://" + parsed.Host + path + params
+	if len(requestURL) > maxMetaLength {
+		return "", fmt.Errorf("upload URL is longer than %d bytes", maxMetaLength)
+	}
+	return requestURL, nil
+}
+
+// GeminiURL returns the gemini:// URL a titan:// URL writes to, without
+// its parameters
+func GeminiURL(titanURL string) string {
+	parsed, err := url.Parse(titanURL)
+	if err != nil || parsed.Scheme != "titan" {
+		return titanURL
+	}
+	path := parsed.EscapedPath()
+	if i := strings.Index(path, ";"); i >= 0 {
+		path = path[:i]
+	}
+	if path == "" {
+		path = "/"
+	}
+	return "gemini://" + parsed.Host + path
+}
+
+// Upload writes content to a capsule with Titan. The connection is checked
+// against the capsule's trusted certificate and presents the identity
+// scoped to its gemini:// side, as uploads usually need one. The reply is
+// a Gemini response, often a redirect to the page written.
+func (c *Client) Upload(upload TitanUpload) (*types.Response, error) {
+	requestURL, err := upload.RequestURL()
+	if err != nil {
+		return nil, err
+	}
+	parsed, err := url.Parse(upload.URL)
+	if err != nil {
+		return nil, fmt.Errorf("invalid URL: %w", err)
+	}
+
+	// Pick the client certificate to present
+	var cert *tls.Certificate
+	if c.identities != nil {
+		if cert, err = c.identities.Certificate(GeminiURL(upload.URL)); err != nil {
+			return nil, err
+		}
+	}
+
+	ctx, cancel := context.WithTimeout(c.ctx, c.timeout)
+	defer cancel()
+
+	conn, err := c.dialTLS(ctx, parsed, cert)
+	if err != nil {
+		return nil, fmt.Errorf("failed to connect: %w", err)
+	}
+	defer conn.Close()
+	if deadline, ok := ctx.Deadline(); ok {
+		conn.SetDeadline(deadline)
+	}
+
+	// Verify certificate using TOFU
+	if peers := conn.ConnectionState().PeerCertificates; len(peers) > 0 {
+		if err := c.tofuStore.Verify(parsed.Hostname(), peers[0]); err != nil {
+			return nil, fmt.Errorf("certificate verification failed: %w", err)
+		}
+	}
+
+	if _, err := conn.Write([]byte(requestURL + "\r\n")); err != nil {
+		return nil, fmt.Errorf("failed to send upload: %w", err)
+	}
+	if _, err := conn.Write(upload.Body); err != nil {
+		return nil, fmt.Errorf("failed to send upload: %w", err)
+	}
+
+	reader := bufio.NewReader(conn)
+	raw, err := reader.ReadBytes('\n')
+	if err != nil && err != io.EOF {
+		return nil, fmt.Errorf("failed to read response: %w", err)
+	}
+	if headerErr := classifyHeader(raw); headerErr != nil {
+		headerErr.URL = upload.URL
+		return nil, headerErr
+	}
+	header := strings.TrimRight(string(raw), "\r\n")
+	status, _ := strconv.Atoi(header[:2])
+
+	var body []byte
+	if IsSuccessStatus(status) {
+		if body, err = io.ReadAll(reader); err != nil {
+			return nil, fmt.Errorf("failed to read response body: %w", err)
+		}
+	}
+
+	return &types.Response{
+		Status:   status,
+		Meta:     header[3:],
+		Body:     body,
+		URL:      upload.URL,
+		Protocol: "titan",
+		Fetched:  time.Now(),
+	}, nil
+}
+
+// dialTLS opens a TLS connection to u's host, through the proxy if one is
+// set. The server certificate is checked by TOFU afterwards, not here.
+func (c *Client) dialTLS(ctx context.Context, u *url.URL, cert *tls.Certificate) (*tls.Conn, error) {
+	host, port := u.Hostname(), u.Port()
+	if port == "" {
+		port = "1965"
+	}
+
+	dial := c.client.DialContext
+	if dial == nil {
+		dial = (&net.Dialer{}).DialContext
+	}
+	raw, err := dial(ctx, "tcp", net.JoinHostPort(host, port))
+	if err != nil {
+		return nil, err
+	}
+
+	config := &tls.Config{
+		InsecureSkipVerify: true,
+		MinVersion:         tls.VersionTLS12,
+		ServerName:         host,
+	}
+	if cert != nil {
+		config.Certificates = []tls.Certificate{*cert}
+	}
+	conn := tls.Client(raw, config)
+	if err := conn.HandshakeContext(ctx); err != nil {
+		raw.Close()
+		return nil, err
+	}
+	return conn, nil
+}
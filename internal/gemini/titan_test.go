@@ -0,0 +1,87 @@
+package gemini
+
+import (
+	"bufio"
+	"crypto/tls"
+	"io"
+	"net"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"git.sr.ht/~adnano/go-gemini/certificate"
+)
+
+func TestTitanRequestURL(t *testing.T) {
+	tests := []struct {
+		upload TitanUpload
+		want   string
+	}{
+		{TitanUpload{URL: "titan://example.org/notes/today.gmi", Body: []byte("# Hi\n")}, "titan://example.org/notes/today.gmi;size=5"},
+		{TitanUpload{URL: "titan://example.org:1966/a.txt;size=99;mime=text/plain", Body: []byte("hello"), MIME: "text/plain", Token: "s3cret word"}, "titan://example.org:1966/a.txt;size=5;mime=text%2Fplain;token=s3cret%20word"},
+		{TitanUpload{URL: "titan://example.org"}, "titan://example.org/;size=0"},
+	}
+	for _, tt := range tests {
+		got, err := tt.upload.RequestURL()
+		if err != nil || got != tt.want {
+			t.Errorf("RequestURL(%q) = %q, %v; want %q", tt.upload.URL, got, err, tt.want)
+		}
+	}
+
+	if _, err := (TitanUpload{URL: "gemini://example.org/"}).RequestURL(); err == nil {
+		t.Error("a gemini:// URL should not be uploaded to")
+	}
+	if got := GeminiURL("titan://example.org/a.gmi;size=3"); got != "gemini://example.org/a.gmi" {
+		t.Errorf("GeminiURL = %q", got)
+	}
+}
+
+func TestUpload(t *testing.T) {
+	cert, err := certificate.Create(certificate.CreateOptions{DNSNames: []string{"localhost"}, Duration: time.Hour})
+	if err != nil {
+		t.Fatal(err)
+	}
+	ln, err := tls.Listen("tcp", "127.0.0.1:0", &tls.Config{Certificates: []tls.Certificate{cert}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { ln.Close() })
+
+	// The server reads the request line and the body it announces, then
+	// redirects to the page written
+	received := make(chan string, 1)
+	go func() {
+		conn, err := ln.Accept()
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+		reader := bufio.NewReader(conn)
+		line, _ := reader.ReadString('\n')
+		body := make([]byte, 5)
+		io.ReadFull(reader, body)
+		received <- line + string(body)
+		conn.Write([]byte("30 gemini://localhost/notes/today.gmi\r\n"))
+	}()
+
+	tofu, err := NewTOFUStore(filepath.Join(t.TempDir(), "known_hosts.json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, port, _ := net.SplitHostPort(ln.Addr().String())
+	target := "titan://localhost:" + port + "/notes/today.gmi"
+	resp, err := NewClient(tofu).Upload(TitanUpload{URL: target, Body: []byte("# Hi\n"), MIME: "text/gemini"})
+	if err != nil {
+		t.Fatalf("Upload: %v", err)
+	}
+	if resp.Status != 30 || resp.Meta != "gemini://localhost/notes/today.gmi" {
+		t.Errorf("response = %d %q, want the redirect", resp.Status, resp.Meta)
+	}
+	if got := <-received; got != target+";size=5;mime=text%2Fgemini\r\n# Hi\n" {
+		t.Errorf("server received %q", got)
+	}
+	if _, ok := tofu.GetCertInfo("localhost"); !ok || !strings.HasPrefix(resp.URL, "titan://") {
+		t.Error("the capsule's certificate should be trusted on first use")
+	}
+}
@@ -32,6 +32,7 @@ type CertificateInfo struct {
 type TOFUStore struct {
 	mu          sync.RWMutex
 	certs       map[string]*CertificateInfo // hostname -> cert info
+	changed     map[string]*CertificateInfo // hostname -> rejected new cert info
 	storePath   string
 	OnNewCert   func(host string, cert *x509.Certificate) bool // Callback for new certs
 	OnCertChange func(host string, old, new *x509.Certificate) bool // Callback for changed certs
@@ -41,6 +42,7 @@ type TOFUStore struct {
 func NewTOFUStore(storePath string) (*TOFUStore, error) {
 	store := &TOFUStore{
 		certs:     make(map[string]*CertificateInfo),
+		changed:   make(map[string]*CertificateInfo),
 		storePath: storePath,
 	}
 
@@ -102,12 +104,7 @@ func (t *TOFUStore) Verify(host string, cert *x509.Certificate) error {
 		// Note: We pass nil for the old certificate because we only store
 		// certificate metadata (fingerprint, dates), not the full certificate.
 		// Callers can access stored.Fingerprint, stored.Subject, etc. for old cert info.
-		if t.OnCertChange != nil && !t.OnCertChange(host, nil, cert) {
-			return ErrCertificateChanged
-		}
-
-		// User accepted the change, update the certificate
-		t.certs[host] = &CertificateInfo{
+		info := &CertificateInfo{
 			Fingerprint: fingerprint,
 			FirstSeen:   stored.FirstSeen, // Keep original first seen date
 			LastSeen:    now,
@@ -115,6 +112,15 @@ func (t *TOFUStore) Verify(host string, cert *x509.Certificate) error {
 			NotBefore:   cert.NotBefore,
 			NotAfter:    cert.NotAfter,
 		}
+		if t.OnCertChange != nil && !t.OnCertChange(host, nil, cert) {
+			// Remember the new certificate so it can be accepted later
+			t.changed[host] = info
+			return ErrCertificateChanged
+		}
+
+		// User accepted the change, update the certificate
+		t.certs[host] = info
+		delete(t.changed, host)
 
 		_ = t.save() // Ignore save errors for now
 
@@ -137,6 +143,31 @@ func (t *TOFUStore) GetCertInfo(host string) (*CertificateInfo, bool) {
 	return info, exists
 }
 
+// ChangedCert returns information on the certificate a host presented in
+// place of its trusted one, if the change was rejected
+func (t *TOFUStore) ChangedCert(host string) (*CertificateInfo, bool) {
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+
+	info, exists := t.changed[host]
+	return info, exists
+}
+
+// AcceptChange trusts the certificate a host presented in place of its
+// trusted one, after the change was rejected
+func (t *TOFUStore) AcceptChange(host string) error {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	info, exists := t.changed[host]
+	if !exists {
+		return fmt.Errorf("no changed certificate for %s", host)
+	}
+	t.certs[host] = info
+	delete(t.changed, host)
+	return t.save()
+}
+
 // RemoveCert removes a certificate from the store
 func (t *TOFUStore) RemoveCert(host string) error {
 	t.mu.Lock()
@@ -0,0 +1,47 @@
+package gemini
+
+import (
+	"fmt"
+	"mime"
+	"net/url"
+	"strings"
+
+	"starsearch/internal/types"
+)
+
+// Violations returns the ways a response breaks the Gemini specification
+// that the client puts up with. Capsule authors see them in strict mode.
+func Violations(resp *types.Response) []string {
+	var violations []string
+
+	if len(resp.Meta) > maxMetaLength {
+		violations = append(violations, fmt.Sprintf("meta is %d bytes, over the %d byte limit", len(resp.Meta), maxMetaLength))
+	}
+
+	switch {
+	case IsRedirectStatus(resp.Status):
+		if target, err := url.Parse(resp.Meta); err == nil && target.User != nil {
+			violations = append(violations, fmt.Sprintf("redirect target %s contains userinfo, which Gemini URIs must not have", resp.Meta))
+		}
+
+	case IsSuccessStatus(resp.Status):
+		mediaType, params, err := mime.ParseMediaType(resp.Meta)
+		if err != nil {
+			violations = append(violations, fmt.Sprintf("MIME type %q cannot be parsed", resp.Meta))
+		} else if strings.HasPrefix(mediaType, "text/") && params["charset"] == "" {
+			violations = append(violations, fmt.Sprintf("%s response declares no charset; UTF-8 is assumed", mediaType))
+		}
+	}
+
+	return violations
+}
+
+// IsViolation reports whether the error is a header the server got wrong,
+// as opposed to a connection that failed
+func (e *HeaderError) IsViolation() bool {
+	switch e.Kind {
+	case HeaderMalformedStatus, HeaderMissingMeta, HeaderMetaTooLong, HeaderBadTerminator:
+		return true
+	}
+	return false
+}
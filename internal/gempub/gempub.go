@@ -0,0 +1,150 @@
+// Package gempub opens Gempub books: zip archives of a small capsule with
+// a metadata.txt file describing the book and an index page of chapters.
+package gempub
+
+import (
+	"archive/zip"
+	"bufio"
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+)
+
+// MIMEType is the media type Gempub books are served with
+const MIMEType = "application/gpub+zip"
+
+// maxUnpackedSize limits how much a book may unpack to, so a malformed or
+// hostile archive cannot fill the disk
+const maxUnpackedSize = 256 << 20
+
+// ErrNotInBook is returned for paths that lead outside the book
+var ErrNotInBook = errors.New("path is outside the book")
+
+// Book is a Gempub archive unpacked to a directory
+type Book struct {
+	Dir      string            // Where the archive was unpacked
+	Metadata map[string]string // Fields of metadata.txt, by lower-case key
+}
+
+// IsGempub reports whether a response is a Gempub book, from its media
+// type or, for servers that send a generic one, the .gpub extension
+func IsGempub(mimeType, urlStr string) bool {
+	if strings.HasPrefix(mimeType, MIMEType) {
+		return true
+	}
+	if i := strings.IndexAny(urlStr, "?#"); i >= 0 {
+		urlStr = urlStr[:i]
+	}
+	return strings.HasSuffix(strings.ToLower(urlStr), ".gpub")
+}
+
+// Open unpacks the archive in data to dir and reads its metadata
+func Open(data []byte, dir string) (*Book, error) {
+	archive, err := zip.NewReader(bytes.NewReader(data), int64(len(data)))
+	if err != nil {
+		return nil, fmt.Errorf("not a Gempub archive: %w", err)
+	}
+	if err := os.MkdirAll(dir, 0700); err != nil {
+		return nil, fmt.Errorf("failed to create book directory: %w", err)
+	}
+
+	var unpacked int64
+	for _, file := range archive.File {
+		if file.FileInfo().IsDir() {
+			continue
+		}
+		target, err := within(dir, file.Name)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", file.Name, err)
+		}
+		n, err := unpack(file, target, maxUnpackedSize-unpacked)
+		if err != nil {
+			return nil, fmt.Errorf("failed to unpack %s: %w", file.Name, err)
+		}
+		unpacked += n
+	}
+
+	book := &Book{Dir: dir, Metadata: map[string]string{}}
+	if meta, err := os.ReadFile(filepath.Join(dir, "metadata.txt")); err == nil {
+		book.Metadata = parseMetadata(meta)
+	}
+	return book, nil
+}
+
+// unpack copies file to target, failing if it is larger than limit
+func unpack(file *zip.File, target string, limit int64) (int64, error) {
+	if err := os.MkdirAll(filepath.Dir(target), 0700); err != nil {
+		return 0, err
+	}
+	in, err := file.Open()
+	if err != nil {
+		return 0, err
+	}
+	defer in.Close()
+
+	out, err := os.OpenFile(target, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, 0600)
+	if err != nil {
+		return 0, err
+	}
+	n, err := io.Copy(out, io.LimitReader(in, limit+1))
+	if closeErr := out.Close(); err == nil {
+		err = closeErr
+	}
+	if err == nil && n > limit {
+		err = errors.New("book is too large")
+	}
+	return n, err
+}
+
+// within returns where the archive path name lives under dir
+func within(dir, name string) (string, error) {
+	clean := path.Clean("/" + strings.ReplaceAll(name, "\\", "/"))
+	if clean == "/" {
+		return "", ErrNotInBook
+	}
+	return filepath.Join(dir, filepath.FromSlash(clean)), nil
+}
+
+// parseMetadata reads the "key: value" lines of metadata.txt
+func parseMetadata(data []byte) map[string]string {
+	fields := map[string]string{}
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	for scanner.Scan() {
+		key, value, ok := strings.Cut(scanner.Text(), ":")
+		if !ok {
+			continue
+		}
+		key = strings.ToLower(strings.TrimSpace(key))
+		if key != "" {
+			fields[key] = strings.TrimSpace(value)
+		}
+	}
+	return fields
+}
+
+// Title returns the book's title, or "" if its metadata has none
+func (b *Book) Title() string {
+	return b.Metadata["title"]
+}
+
+// Index returns the path of the book's table of contents
+func (b *Book) Index() string {
+	if index := b.Metadata["index"]; index != "" {
+		return strings.TrimPrefix(path.Clean("/"+index), "/")
+	}
+	return "index.gmi"
+}
+
+// Read returns the file at name, a slash-separated path within the book
+func (b *Book) Read(name string) ([]byte, error) {
+	target, err := within(b.Dir, name)
+	if err != nil {
+		return nil, err
+	}
+	return os.ReadFile(target)
+}
@@ -0,0 +1,73 @@
+package gempub
+
+import (
+	"archive/zip"
+	"bytes"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// archive zips files, by name, into a Gempub book
+func archive(t *testing.T, files map[string]string) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	w := zip.NewWriter(&buf)
+	for name, body := range files {
+		f, err := w.Create(name)
+		if err != nil {
+			t.Fatal(err)
+		}
+		f.Write([]byte(body))
+	}
+	if err := w.Close(); err != nil {
+		t.Fatal(err)
+	}
+	return buf.Bytes()
+}
+
+func TestOpen(t *testing.T) {
+	dir := t.TempDir()
+	data := archive(t, map[string]string{
+		"metadata.txt":       "title: A Book\nauthor: Someone\ngpubVersion: 1.0.0\nindex: toc.gmi\n",
+		"toc.gmi":            "=> chapters/1.gmi One\n",
+		"chapters/1.gmi":     "# One\n",
+		"../../outside.gmi":  "escaped",
+		"/absolute/path.gmi": "rooted",
+	})
+
+	book, err := Open(data, filepath.Join(dir, "book"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if book.Title() != "A Book" || book.Metadata["author"] != "Someone" || book.Index() != "toc.gmi" {
+		t.Errorf("metadata = %v, index %q", book.Metadata, book.Index())
+	}
+	if body, err := book.Read("chapters/1.gmi"); err != nil || string(body) != "# One\n" {
+		t.Errorf("Read = %q, %v", body, err)
+	}
+
+	// Paths leading out of the archive stay inside the book
+	if _, err := os.Stat(filepath.Join(dir, "outside.gmi")); err == nil {
+		t.Error("an entry was unpacked outside the book")
+	}
+	if body, err := book.Read("../outside.gmi"); err != nil || string(body) != "escaped" {
+		t.Errorf("escaping entry read as %q, %v", body, err)
+	}
+}
+
+func TestIsGempub(t *testing.T) {
+	for _, tt := range []struct {
+		mime, url string
+		want      bool
+	}{
+		{MIMEType, "gemini://example.org/book", true},
+		{"application/octet-stream", "gemini://example.org/book.GPUB", true},
+		{"application/zip", "gemini://example.org/book.gpub?download", true},
+		{"application/zip", "gemini://example.org/book.zip", false},
+	} {
+		if got := IsGempub(tt.mime, tt.url); got != tt.want {
+			t.Errorf("IsGempub(%q, %q) = %v", tt.mime, tt.url, got)
+		}
+	}
+}
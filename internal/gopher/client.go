@@ -1,104 +1,245 @@
 package gopher
 
 import (
+	"context"
+	"errors"
 	"fmt"
 	"io"
 	"net"
-	"net/url"
 	"strings"
+	"sync"
 	"time"
 
+	"golang.org/x/net/proxy"
 	"starsearch/internal/types"
 )
 
-// Client handles Gopher protocol requests
+// ErrTooLarge is returned for responses bigger than the client's size limit
+var ErrTooLarge = errors.New("response too large")
+
+// Default limits of a new client
+const (
+	DefaultMaxSize     = 64 << 20
+	DefaultIdleTimeout = 30 * time.Second
+)
+
+// Client handles Gopher protocol requests. It is safe for concurrent use.
 type Client struct {
-	timeout time.Duration
+	mu          sync.Mutex      // Guards dialer and the limits
+	timeout     time.Duration   // For connecting and sending the request
+	idleTimeout time.Duration   // Longest wait for more of the response
+	maxSize     int64           // Largest response accepted, in bytes
+	proxy       string          // SOCKS5 proxy address; empty connects directly
+	dialer      proxy.Dialer    // Connects through the proxy, if set
+	ctx         context.Context // Cancelled by Close, aborting requests in flight
+	cancel      context.CancelFunc
 }
 
 // NewClient creates a new Gopher client
 func NewClient() *Client {
+	ctx, cancel := context.WithCancel(context.Background())
 	return &Client{
-		timeout: 30 * time.Second,
+		timeout:     30 * time.Second,
+		idleTimeout: DefaultIdleTimeout,
+		maxSize:     DefaultMaxSize,
+		ctx:         ctx,
+		cancel:      cancel,
+	}
+}
+
+// SetLimits bounds responses to maxSize bytes and aborts requests whose
+// server sends nothing for idleTimeout. Zero keeps a limit as it is.
+func (c *Client) SetLimits(maxSize int64, idleTimeout time.Duration) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if maxSize > 0 {
+		c.maxSize = maxSize
+	}
+	if idleTimeout > 0 {
+		c.idleTimeout = idleTimeout
 	}
 }
 
+// Close aborts requests in flight, including those of isolated clients made
+// from c, and makes any further requests fail
+func (c *Client) Close() {
+	c.cancel()
+}
+
+// SetProxy sends requests through the SOCKS5 proxy at addr; an empty addr
+// connects directly
+func (c *Client) SetProxy(addr string) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.proxy = addr
+	c.dialer = nil
+	if addr == "" {
+		return nil
+	}
+
+	dialer, err := c.socksDialer("")
+	if err != nil {
+		return err
+	}
+	c.dialer = dialer
+	return nil
+}
+
+// Isolated returns a client sharing c's settings whose connections present
+// key as their SOCKS credentials. Tor puts streams with different
+// credentials on different circuits. Without a proxy it returns c.
+func (c *Client) Isolated(key string) *Client {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.proxy == "" || key == "" {
+		return c
+	}
+	dialer, err := c.socksDialer(key)
+	if err != nil {
+		return c
+	}
+	return &Client{
+		timeout:     c.timeout,
+		idleTimeout: c.idleTimeout,
+		maxSize:     c.maxSize,
+		proxy:       c.proxy,
+		dialer:      dialer,
+		ctx:         c.ctx,
+		cancel:      c.cancel,
+	}
+}
+
+// socksDialer dials through the proxy, authenticating as user if given
+func (c *Client) socksDialer(user string) (proxy.Dialer, error) {
+	var auth *proxy.Auth
+	if user != "" {
+		auth = &proxy.Auth{User: user, Password: user}
+	}
+	dialer, err := proxy.SOCKS5("tcp", c.proxy, auth, &net.Dialer{Timeout: c.timeout})
+	if err != nil {
+		return nil, fmt.Errorf("invalid SOCKS proxy %s: %w", c.proxy, err)
+	}
+	return dialer, nil
+}
+
+// dial connects to address directly or through the proxy
+func (c *Client) dial(ctx context.Context, address string) (net.Conn, error) {
+	c.mu.Lock()
+	proxyDialer := c.dialer
+	c.mu.Unlock()
+	if proxyDialer != nil {
+		if dialer, ok := proxyDialer.(proxy.ContextDialer); ok {
+			return dialer.DialContext(ctx, "tcp", address)
+		}
+		return proxyDialer.Dial("tcp", address)
+	}
+	dialer := &net.Dialer{Timeout: c.timeout}
+	return dialer.DialContext(ctx, "tcp", address)
+}
+
 // Fetch retrieves a Gopher URL and returns a response
 func (c *Client) Fetch(urlStr string) (*types.Response, error) {
-	// Parse URL
-	parsedURL, err := url.Parse(urlStr)
+	return c.FetchContext(context.Background(), urlStr, nil)
+}
+
+// FetchStream is Fetch reporting progress: partial is called with the
+// response as received so far after each chunk arrives. Its body is never
+// modified afterwards.
+func (c *Client) FetchStream(urlStr string, partial func(resp *types.Response)) (*types.Response, error) {
+	return c.FetchContext(context.Background(), urlStr, partial)
+}
+
+// FetchContext is FetchStream that gives up when ctx is cancelled
+func (c *Client) FetchContext(ctx context.Context, urlStr string, partial func(resp *types.Response)) (*types.Response, error) {
+	// Decode host, item type and selector from the URL
+	item, err := ParseURL(urlStr)
 	if err != nil {
-		return nil, fmt.Errorf("invalid URL: %w", err)
-	}
-
-	// Ensure scheme is gopher
-	if parsedURL.Scheme == "" {
-		parsedURL.Scheme = "gopher"
-		urlStr = parsedURL.String()
-	} else if parsedURL.Scheme != "gopher" {
-		return nil, fmt.Errorf("unsupported scheme: %s (only gopher:// is supported)", parsedURL.Scheme)
-	}
-
-	// Get host and port
-	host := parsedURL.Hostname()
-	port := parsedURL.Port()
-	if port == "" {
-		port = "70" // Default gopher port
-	}
-
-	// Get selector (path)
-	// Gopher URL format: gopher://host:port/[type][selector]
-	// where type is a single character (0, 1, 7, etc.)
-	path := parsedURL.Path
-	itemType := "1" // Default to directory
-	selector := ""
-
-	if path == "" || path == "/" {
-		// Root directory
-		itemType = "1"
-		selector = ""
-	} else if len(path) > 1 {
-		// Extract item type (character after first /)
-		itemType = string(path[1])
-		if len(path) > 2 {
-			// Selector is everything after the type character
-			selector = path[2:]
-		}
+		return nil, err
+	}
+	if !strings.HasPrefix(urlStr, "gopher://") {
+		urlStr = item.URL()
 	}
 
+	// The request ends with ctx or when the client is closed
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	stopClose := context.AfterFunc(c.ctx, cancel)
+	defer stopClose()
+
+	c.mu.Lock()
+	maxSize, idleTimeout := c.maxSize, c.idleTimeout
+	c.mu.Unlock()
+
 	// Connect to server
-	address := net.JoinHostPort(host, port)
-	conn, err := net.DialTimeout("tcp", address, c.timeout)
+	address := net.JoinHostPort(item.Host, item.Port)
+	conn, err := c.dial(ctx, address)
 	if err != nil {
+		if ctx.Err() != nil {
+			return nil, fmt.Errorf("failed to connect: %w", context.Canceled)
+		}
 		return nil, fmt.Errorf("failed to connect: %w", err)
 	}
 	defer conn.Close()
 
-	// Set read deadline
-	conn.SetDeadline(time.Now().Add(c.timeout))
+	// Drop the connection if the request is abandoned midway
+	stop := context.AfterFunc(ctx, func() { conn.Close() })
+	defer stop()
 
-	// Send selector followed by CRLF
-	_, err = conn.Write([]byte(selector + "\r\n"))
+	// Send selector (and search query) followed by CRLF
+	conn.SetWriteDeadline(time.Now().Add(c.timeout))
+	_, err = conn.Write([]byte(item.request()))
 	if err != nil {
+		if ctx.Err() != nil {
+			return nil, fmt.Errorf("failed to send request: %w", context.Canceled)
+		}
 		return nil, fmt.Errorf("failed to send request: %w", err)
 	}
 
-	// Read response until connection closes
-	body, err := io.ReadAll(conn)
-	if err != nil {
-		return nil, fmt.Errorf("failed to read response: %w", err)
-	}
-
 	// Determine MIME type based on item type
-	mimeType := GetMIMEType(itemType)
+	mimeType := GetMIMEType(item.Type)
+	fetched := time.Now()
+
+	// Read response until connection closes. Gopher gives no length, so a
+	// server may go quiet or send without end: each chunk must arrive
+	// within the idle timeout, and the whole within the size limit.
+	var body []byte
+	buf := make([]byte, 32*1024)
+	for {
+		conn.SetReadDeadline(time.Now().Add(idleTimeout))
+		n, err := conn.Read(buf)
+		if n > 0 {
+			if int64(len(body)+n) > maxSize {
+				return nil, fmt.Errorf("%w: over %d MiB", ErrTooLarge, maxSize>>20)
+			}
+			body = append(body, buf[:n]...)
+			if partial != nil {
+				partial(&types.Response{Status: 20, Meta: mimeType, Body: body, URL: urlStr, Protocol: "gopher", Fetched: fetched})
+			}
+		}
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			if ctx.Err() != nil {
+				return nil, fmt.Errorf("failed to read response: %w", context.Canceled)
+			}
+			var netErr net.Error
+			if errors.As(err, &netErr) && netErr.Timeout() {
+				return nil, fmt.Errorf("failed to read response: server sent nothing for %s: %w", idleTimeout, err)
+			}
+			return nil, fmt.Errorf("failed to read response: %w", err)
+		}
+	}
 
 	// Create response
 	// Gopher doesn't have status codes, so we use 20 (success) for Gemini compatibility
 	response := &types.Response{
-		Status: 20, // Success
-		Meta:   mimeType,
-		Body:   body,
-		URL:    urlStr,
+		Status:   20, // Success
+		Meta:     mimeType,
+		Body:     body,
+		URL:      urlStr,
+		Protocol: "gopher",
+		Fetched:  fetched,
 	}
 
 	return response, nil
@@ -0,0 +1,254 @@
+package gopher
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"net"
+	"strings"
+	"testing"
+	"time"
+
+	"starsearch/internal/types"
+)
+
+func TestCloseAbortsFetch(t *testing.T) {
+	// A server that accepts connections and never answers
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { ln.Close() })
+	go func() {
+		for {
+			conn, err := ln.Accept()
+			if err != nil {
+				return
+			}
+			t.Cleanup(func() { conn.Close() })
+		}
+	}()
+
+	c := NewClient()
+	done := make(chan error, 1)
+	go func() {
+		_, err := c.Fetch("gopher://" + ln.Addr().String() + "/1/")
+		done <- err
+	}()
+
+	time.Sleep(50 * time.Millisecond)
+	c.Close()
+	select {
+	case err := <-done:
+		if err == nil {
+			t.Error("Fetch succeeded after Close")
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("Close did not abort the fetch")
+	}
+
+	if _, err := c.Fetch("gopher://" + ln.Addr().String() + "/1/"); err == nil {
+		t.Error("Fetch after Close should fail")
+	}
+}
+
+// serve starts a server answering every request by calling respond with
+// the connection, and returns its address
+func serve(t *testing.T, respond func(conn net.Conn)) string {
+	t.Helper()
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { ln.Close() })
+	go func() {
+		for {
+			conn, err := ln.Accept()
+			if err != nil {
+				return
+			}
+			go func() {
+				defer conn.Close()
+				buf := make([]byte, 1024)
+				conn.Read(buf) // The selector
+				respond(conn)
+			}()
+		}
+	}()
+	return ln.Addr().String()
+}
+
+func TestFetchLimits(t *testing.T) {
+	chunk := []byte(strings.Repeat("x", 1024))
+	endless := serve(t, func(conn net.Conn) {
+		for {
+			if _, err := conn.Write(chunk); err != nil {
+				return
+			}
+		}
+	})
+	quiet := serve(t, func(conn net.Conn) {
+		conn.Write(chunk)
+		time.Sleep(5 * time.Second)
+	})
+
+	c := NewClient()
+	defer c.Close()
+	c.SetLimits(64<<10, 200*time.Millisecond)
+
+	if _, err := c.Fetch("gopher://" + endless + "/9/big.bin"); !errors.Is(err, ErrTooLarge) {
+		t.Errorf("endless response: got %v, want ErrTooLarge", err)
+	}
+
+	start := time.Now()
+	if _, err := c.Fetch("gopher://" + quiet + "/9/slow.bin"); err == nil {
+		t.Error("a server going quiet should fail the fetch")
+	}
+	if elapsed := time.Since(start); elapsed > 2*time.Second {
+		t.Errorf("idle timeout took %s", elapsed)
+	}
+}
+
+func TestFetchContext(t *testing.T) {
+	chunk := []byte(strings.Repeat("x", 1024))
+	slow := serve(t, func(conn net.Conn) {
+		for {
+			if _, err := conn.Write(chunk); err != nil {
+				return
+			}
+			time.Sleep(10 * time.Millisecond)
+		}
+	})
+
+	c := NewClient()
+	defer c.Close()
+
+	// Progress is reported chunk by chunk, and cancelling gives up
+	ctx, cancel := context.WithCancel(context.Background())
+	var received int
+	done := make(chan error, 1)
+	go func() {
+		_, err := c.FetchContext(ctx, "gopher://"+slow+"/9/file.bin", func(partial *types.Response) {
+			received = len(partial.Body)
+			if received >= 4096 {
+				cancel()
+			}
+		})
+		done <- err
+	}()
+
+	select {
+	case err := <-done:
+		if !errors.Is(err, context.Canceled) {
+			t.Errorf("got %v, want context.Canceled", err)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("cancelling did not abort the fetch")
+	}
+	if received < 4096 {
+		t.Errorf("received %d bytes before cancelling, want progress reports", received)
+	}
+
+	// The client still works for other requests
+	short := serve(t, func(conn net.Conn) { conn.Write([]byte("hello")) })
+	resp, err := c.Fetch("gopher://" + short + "/0/hello.txt")
+	if err != nil || string(resp.Body) != "hello" {
+		t.Errorf("Fetch after a cancelled request = %v, %v", resp, err)
+	}
+}
+
+func TestFetchMetadata(t *testing.T) {
+	addr := serve(t, func(conn net.Conn) { conn.Write([]byte("hello\r\n.\r\n")) })
+
+	c := NewClient()
+	defer c.Close()
+	before := time.Now()
+	resp, err := c.Fetch("gopher://" + addr + "/0/hello.txt")
+	if err != nil {
+		t.Fatal(err)
+	}
+	doc, err := NewParser(resp.URL).Parse(resp)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	meta := doc.Metadata
+	if meta.Protocol != "gopher" || meta.Status != 20 || meta.Meta != "text/plain" || meta.Size != 10 {
+		t.Errorf("metadata = %+v", meta)
+	}
+	if meta.Fetched.Before(before) || meta.Fetched.After(time.Now()) {
+		t.Errorf("fetched at %s, want during the request", meta.Fetched)
+	}
+}
+
+// fakeSOCKS accepts SOCKS5 connections, sends the user each one
+// authenticates as, empty if none, then drops the connection
+func fakeSOCKS(t *testing.T) (string, <-chan string) {
+	t.Helper()
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { ln.Close() })
+
+	users := make(chan string, 4)
+	go func() {
+		for {
+			conn, err := ln.Accept()
+			if err != nil {
+				return
+			}
+			go func() {
+				defer conn.Close()
+				buf := make([]byte, 2)
+				if _, err := io.ReadFull(conn, buf); err != nil {
+					return
+				}
+				methods := make([]byte, buf[1])
+				io.ReadFull(conn, methods)
+				if !bytes.Contains(methods, []byte{0x02}) {
+					users <- ""
+					return
+				}
+				conn.Write([]byte{0x05, 0x02})
+				io.ReadFull(conn, buf)
+				user := make([]byte, buf[1])
+				io.ReadFull(conn, user)
+				users <- string(user)
+			}()
+		}
+	}()
+	return ln.Addr().String(), users
+}
+
+func TestClientProxyIsolation(t *testing.T) {
+	addr, users := fakeSOCKS(t)
+	client := NewClient()
+	if err := client.SetProxy(addr); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := client.Fetch("gopher://example.onion:70/"); err == nil {
+		t.Fatal("fetch through a refusing proxy succeeded")
+	}
+	if user := <-users; user != "" {
+		t.Errorf("proxy saw user %q, want none", user)
+	}
+
+	// Isolated clients authenticate with their own key
+	for _, key := range []string{"starsearch-tab-1", "starsearch-tab-2"} {
+		client.Isolated(key).Fetch("gopher://example.onion:70/")
+		if user := <-users; user != key {
+			t.Errorf("proxy saw user %q, want %q", user, key)
+		}
+	}
+
+	// Without a proxy there is nothing to isolate
+	if err := client.SetProxy(""); err != nil {
+		t.Fatal(err)
+	}
+	if client.Isolated("starsearch-tab-1") != client {
+		t.Error("Isolated without a proxy returned a new client")
+	}
+}
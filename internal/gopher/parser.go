@@ -3,16 +3,22 @@ package gopher
 import (
 	"bufio"
 	"bytes"
-	"fmt"
+	"html"
 	"net/url"
 	"strings"
 
+	"starsearch/internal/textutil"
 	"starsearch/internal/types"
 )
 
 // Parser parses Gopher menu format documents
 type Parser struct {
 	baseURL *url.URL
+
+	// Strict shows out-of-spec input as the server sent it instead of
+	// working around it. Either way, it is counted in the document's
+	// warnings.
+	Strict bool
 }
 
 // NewParser creates a new Gopher document parser
@@ -35,35 +41,85 @@ func (p *Parser) Parse(resp *types.Response) (*types.Document, error) {
 		RawBody:  resp.Body,
 		Lines:    make([]types.Line, 0),
 		Links:    make([]types.Line, 0),
+		Metadata: resp.Metadata(),
 		MIMEType: resp.Meta,
 	}
+	if item, err := ParseURL(resp.URL); err == nil {
+		doc.ItemType = item.Type
+	}
 
 	// Only parse gopher menu format
 	if !IsGopherMenu(doc.MIMEType) {
 		// For non-menu content (text files), treat as plain text
 		if strings.HasPrefix(doc.MIMEType, "text/plain") {
-			scanner := bufio.NewScanner(bytes.NewReader(resp.Body))
+			doc.Metadata.LineEndings = textutil.LineEndings(resp.Body)
+			scanner := bufio.NewScanner(bytes.NewReader(textutil.NormalizeLineEndings(resp.Body)))
+			controls := 0
 			for scanner.Scan() {
+				if textutil.HasControl(scanner.Text()) {
+					controls++
+				}
 				line := types.Line{
 					Type: types.LineText,
 					Raw:  scanner.Text(),
-					Text: scanner.Text(),
+					Text: textutil.Sanitize(scanner.Text()),
 				}
 				doc.Lines = append(doc.Lines, line)
 			}
+			if controls > 0 {
+				doc.AddWarning("control characters were removed from lines", controls)
+			}
+			// Text files end with a lone "." like menus
+			if n := len(doc.Lines); n > 0 && doc.Lines[n-1].Text == "." {
+				doc.Lines = doc.Lines[:n-1]
+			}
 			return doc, scanner.Err()
 		}
 		// For binary content, just store the body
 		return doc, nil
 	}
 
+	if bareLF := bytes.Count(resp.Body, []byte("\n")) - bytes.Count(resp.Body, []byte("\r\n")); bareLF > 0 {
+		doc.AddWarning("lines end in a bare LF instead of CRLF", bareLF)
+	}
+
+	// Some servers answer a bad selector with an HTML error page
+	if isHTML(resp.Body) {
+		doc.AddWarning("server sent an HTML page instead of a menu", 1)
+		if !p.Strict {
+			parseHTMLError(doc, resp.Body)
+			return doc, nil
+		}
+	}
+
 	// Parse Gopher menu line by line
-	scanner := bufio.NewScanner(bytes.NewReader(resp.Body))
+	doc.Metadata.LineEndings = textutil.LineEndings(resp.Body)
+	scanner := bufio.NewScanner(bytes.NewReader(textutil.NormalizeLineEndings(resp.Body)))
 	linkNum := 1
+	terminated := false
+	ignored := 0
+	controls := 0
 
 	for scanner.Scan() {
 		rawLine := scanner.Text()
-		line := p.parseGopherLine(rawLine, &linkNum)
+
+		// Nothing after the end-of-menu marker belongs to the menu
+		if terminated {
+			if strings.TrimSpace(rawLine) != "" {
+				ignored++
+			}
+			continue
+		}
+		if rawLine == "." {
+			terminated = true
+			continue
+		}
+
+		line := p.parseGopherLine(rawLine, &linkNum, doc)
+		if textutil.HasControl(line.Text) {
+			controls++
+		}
+		line.Text = textutil.Sanitize(line.Text)
 		doc.Lines = append(doc.Lines, line)
 
 		// Track links separately for easy access
@@ -72,12 +128,49 @@ func (p *Parser) Parse(resp *types.Response) (*types.Document, error) {
 		}
 	}
 
+	if !terminated {
+		doc.AddWarning(`menu does not end with a "." line`, 1)
+	}
+	if ignored > 0 {
+		doc.AddWarning(`lines after the closing "." were ignored`, ignored)
+	}
+	if controls > 0 {
+		doc.AddWarning("control characters were removed from lines", controls)
+	}
 	return doc, scanner.Err()
 }
 
+// isHTML reports whether a body is an HTML document
+func isHTML(body []byte) bool {
+	start := strings.ToLower(string(bytes.TrimSpace(body[:min(len(body), 64)])))
+	return strings.HasPrefix(start, "<!doctype html") || strings.HasPrefix(start, "<html")
+}
+
+// parseHTMLError shows the text of an HTML page as error lines
+func parseHTMLError(doc *types.Document, body []byte) {
+	var text strings.Builder
+	inTag := false
+	for _, r := range string(body) {
+		switch {
+		case r == '<':
+			inTag = true
+		case r == '>':
+			inTag = false
+		case !inTag:
+			text.WriteRune(r)
+		}
+	}
+
+	for _, rawLine := range strings.Split(html.UnescapeString(text.String()), "\n") {
+		if rawLine = strings.TrimSpace(rawLine); rawLine != "" {
+			doc.Lines = append(doc.Lines, types.Line{Type: types.LineText, Raw: rawLine, Text: textutil.Sanitize(rawLine), ItemType: "3"})
+		}
+	}
+}
+
 // parseGopherLine parses a single line of a Gopher menu
 // Gopher format: TypeDisplayString\tSelector\tHost\tPort\r\n
-func (p *Parser) parseGopherLine(rawLine string, linkNum *int) types.Line {
+func (p *Parser) parseGopherLine(rawLine string, linkNum *int, doc *types.Document) types.Line {
 	line := types.Line{
 		Raw: rawLine,
 	}
@@ -140,6 +233,7 @@ func (p *Parser) parseGopherLine(rawLine string, linkNum *int) types.Line {
 			// Informational text or error - not a link
 			line.Type = types.LineText
 			line.Text = displayString
+			line.ItemType = itemType
 			return line
 
 		case "h":
@@ -147,38 +241,19 @@ func (p *Parser) parseGopherLine(rawLine string, linkNum *int) types.Line {
 			if strings.HasPrefix(selector, "URL:") {
 				gopherURL = strings.TrimPrefix(selector, "URL:")
 			} else {
-				gopherURL = fmt.Sprintf("gopher://%s:%s/h%s", host, port, selector)
+				gopherURL = Item{Host: host, Port: port, Type: itemType, Selector: selector}.URL()
 			}
 			isLink = true
 
-		case "0":
-			// Text file
-			gopherURL = fmt.Sprintf("gopher://%s:%s/0%s", host, port, selector)
-			isLink = true
-
-		case "1":
-			// Directory/Menu
-			gopherURL = fmt.Sprintf("gopher://%s:%s/1%s", host, port, selector)
-			isLink = true
-
-		case "7":
-			// Search
-			gopherURL = fmt.Sprintf("gopher://%s:%s/7%s", host, port, selector)
-			isLink = true
-
-		case "9":
-			// Binary file
-			gopherURL = fmt.Sprintf("gopher://%s:%s/9%s", host, port, selector)
-			isLink = true
-
-		case "g", "I":
-			// Image
-			gopherURL = fmt.Sprintf("gopher://%s:%s/%s%s", host, port, itemType, selector)
+		case "2", "8", "T":
+			// Phone book and terminal sessions, opened outside Gopher
+			gopherURL = sessionURL(itemType, selector, host, port)
 			isLink = true
 
 		default:
-			// Unknown type - treat as link
-			gopherURL = fmt.Sprintf("gopher://%s:%s/%s%s", host, port, itemType, selector)
+			// Text files, menus, searches, binaries, images and unknown
+			// types all link to the item; the type is kept in the URL
+			gopherURL = Item{Host: host, Port: port, Type: itemType, Selector: selector}.URL()
 			isLink = true
 		}
 
@@ -186,14 +261,28 @@ func (p *Parser) parseGopherLine(rawLine string, linkNum *int) types.Line {
 			line.Type = types.LineLink
 			line.Text = displayString
 			line.URL = gopherURL
+			line.ItemType = itemType
 			line.LinkNum = *linkNum
 			*linkNum++
 			return line
 		}
 	} else {
-		// No selector/host/port - treat as informational text
+		// No selector/host/port. Servers often write info lines with
+		// spaces instead of tabs, or leave the fields out altogether.
+		if itemType == "i" {
+			doc.AddWarning("info lines are missing their tab-separated fields", 1)
+		} else {
+			doc.AddWarning("menu lines are missing their tab-separated fields", 1)
+		}
 		line.Type = types.LineText
-		line.Text = displayString
+		if p.Strict {
+			line.Text = rawLine
+			line.ItemType = "3"
+			return line
+		}
+		// Treat as informational text
+		line.Text = strings.TrimRight(displayString, " ")
+		line.ItemType = "i"
 		return line
 	}
 
@@ -203,6 +292,42 @@ func (p *Parser) parseGopherLine(rawLine string, linkNum *int) types.Line {
 	return line
 }
 
+// ItemTypeTagWidth is the width of the longest tag returned by ItemTypeTag
+const ItemTypeTagWidth = 4
+
+// ItemTypeTag returns a short tag for a Gopher item type, shown beside menu
+// items so users can tell what a link opens. Info lines have no tag.
+func ItemTypeTag(itemType string) string {
+	switch itemType {
+	case "i", "":
+		return ""
+	case "0":
+		return "TXT"
+	case "1":
+		return "DIR"
+	case "2":
+		return "CSO"
+	case "3":
+		return "ERR"
+	case "4", "5", "6", "9":
+		return "BIN"
+	case "7":
+		return "SRCH"
+	case "8", "T":
+		return "TEL"
+	case "+":
+		return "MIRR"
+	case "g", "I":
+		return "IMG"
+	case "h":
+		return "HTML"
+	case "s":
+		return "SND"
+	default:
+		return "?"
+	}
+}
+
 // GetItemTypeDescription returns a human-readable description of a Gopher item type
 func GetItemTypeDescription(itemType string) string {
 	switch itemType {
@@ -244,3 +369,18 @@ func GetItemTypeDescription(itemType string) string {
 		return "Unknown"
 	}
 }
+
+// GetTitle names a Gopher page by the last segment of its selector, since
+// menus and text files carry no title of their own. The root menu is
+// named by its host.
+func GetTitle(doc *types.Document) string {
+	item, err := ParseURL(doc.URL)
+	if err != nil {
+		return doc.URL
+	}
+	segments := strings.FieldsFunc(item.Selector, func(r rune) bool { return r == '/' })
+	if len(segments) == 0 {
+		return item.Host
+	}
+	return segments[len(segments)-1]
+}
@@ -0,0 +1,113 @@
+package gopher
+
+import (
+	"testing"
+
+	"starsearch/internal/types"
+)
+
+// sloppyMenu has bare LF line endings, an info line written with spaces
+// instead of tabs and no closing "." line
+const sloppyMenu = "iWelcome    fake    (NULL)    0\n" +
+	"1Phlog\t/phlog\texample.org\t70\n"
+
+func parseMenu(t *testing.T, body string, strict bool) *types.Document {
+	t.Helper()
+	parser := NewParser("gopher://example.org/")
+	parser.Strict = strict
+	doc, err := parser.Parse(&types.Response{Meta: GetMIMEType("1"), Body: []byte(body), URL: "gopher://example.org/"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return doc
+}
+
+func warningCounts(doc *types.Document) map[string]int {
+	counts := make(map[string]int)
+	for _, w := range doc.Warnings {
+		counts[w.Message] = w.Count
+	}
+	return counts
+}
+
+func TestLenientMenu(t *testing.T) {
+	doc := parseMenu(t, sloppyMenu, false)
+
+	if len(doc.Lines) != 2 || len(doc.Links) != 1 {
+		t.Fatalf("got %d lines and %d links, want 2 and 1", len(doc.Lines), len(doc.Links))
+	}
+	if got := doc.Lines[0]; got.ItemType != "i" || got.Text != "Welcome    fake    (NULL)    0" {
+		t.Errorf("info line parsed as %+v", got)
+	}
+
+	want := map[string]int{
+		"lines end in a bare LF instead of CRLF":            2,
+		"info lines are missing their tab-separated fields": 1,
+		`menu does not end with a "." line`:                 1,
+	}
+	got := warningCounts(doc)
+	for message, count := range want {
+		if got[message] != count {
+			t.Errorf("warning %q counted %d times, want %d", message, got[message], count)
+		}
+	}
+	if len(got) != len(want) {
+		t.Errorf("warnings %v, want %v", got, want)
+	}
+}
+
+func TestStrictMenuShowsMalformedLines(t *testing.T) {
+	doc := parseMenu(t, sloppyMenu, true)
+	if got := doc.Lines[0]; got.ItemType != "3" || got.Text != "iWelcome    fake    (NULL)    0" {
+		t.Errorf("malformed line parsed as %+v, want it shown raw as an error", got)
+	}
+	if len(doc.Warnings) != 3 {
+		t.Errorf("got warnings %v, want the same 3 as lenient mode", doc.Warnings)
+	}
+}
+
+func TestMenuStopsAtTerminator(t *testing.T) {
+	doc := parseMenu(t, "iHello\t\texample.org\t70\r\n.\r\ntrailing junk\r\n", false)
+	if len(doc.Lines) != 1 {
+		t.Errorf("got %d lines, want only the line before the terminator", len(doc.Lines))
+	}
+	if got := warningCounts(doc)[`lines after the closing "." were ignored`]; got != 1 {
+		t.Errorf("ignored line warning counted %d times, want 1", got)
+	}
+}
+
+func TestHTMLErrorPage(t *testing.T) {
+	page := "<!DOCTYPE html>\n<html><head><title>404 Not Found</title></head>\n" +
+		"<body><h1>Not Found</h1>\n<p>No such selector &amp; no luck</p></body></html>\n"
+
+	doc := parseMenu(t, page, false)
+	var texts []string
+	for _, line := range doc.Lines {
+		if line.ItemType != "3" {
+			t.Errorf("line %q has item type %q, want 3", line.Text, line.ItemType)
+		}
+		texts = append(texts, line.Text)
+	}
+	want := []string{"404 Not Found", "Not Found", "No such selector & no luck"}
+	if len(texts) != len(want) {
+		t.Fatalf("got lines %q, want %q", texts, want)
+	}
+	for i := range want {
+		if texts[i] != want[i] {
+			t.Errorf("line %d: %q, want %q", i, texts[i], want[i])
+		}
+	}
+	if got := warningCounts(doc)["server sent an HTML page instead of a menu"]; got != 1 {
+		t.Errorf("HTML warning counted %d times, want 1", got)
+	}
+}
+
+func TestMenuWithBareCRLineEndings(t *testing.T) {
+	doc := parseMenu(t, "iHello\t\terror.host\t1\r1Phlog\t/phlog\texample.org\t70\r.\r", false)
+	if len(doc.Lines) != 2 || doc.Lines[0].Text != "Hello" || len(doc.Links) != 1 || doc.Links[0].URL != "gopher://example.org:70/1/phlog" {
+		t.Errorf("menu parsed as %+v", doc.Lines)
+	}
+	if doc.Metadata.LineEndings != "CR" {
+		t.Errorf("line endings recorded as %q, want CR", doc.Metadata.LineEndings)
+	}
+}
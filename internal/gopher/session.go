@@ -0,0 +1,84 @@
+package gopher
+
+import (
+	"net"
+	"net/url"
+	"strings"
+)
+
+// Session is a menu item that is not a document: a telnet (8) or TN3270
+// (T) terminal session, or a CSO phone book (2) query server. These cannot
+// be fetched over Gopher.
+type Session struct {
+	Kind string // "telnet", "tn3270" or "cso"
+	Host string
+	Port string
+	User string // Login name suggested by the selector, if any
+}
+
+// sessionURL builds the URL for a session item: telnet:// and tn3270://
+// as in RFC 4266, or a gopher:// URL for CSO servers, which have no scheme
+func sessionURL(itemType, selector, host, port string) string {
+	var scheme string
+	switch itemType {
+	case "8":
+		scheme = "telnet"
+	case "T":
+		scheme = "tn3270"
+	default:
+		return Item{Host: host, Port: port, Type: itemType, Selector: selector}.URL()
+	}
+
+	u := url.URL{Scheme: scheme, Host: net.JoinHostPort(host, port)}
+	if user := strings.TrimPrefix(selector, "/"); user != "" {
+		u.User = url.User(user)
+	}
+	return u.String()
+}
+
+// SessionFor returns the session a link URL opens, or false for URLs that
+// can be fetched
+func SessionFor(urlStr string) (Session, bool) {
+	u, err := url.Parse(urlStr)
+	if err != nil {
+		return Session{}, false
+	}
+
+	switch u.Scheme {
+	case "telnet", "tn3270":
+		s := Session{Kind: u.Scheme, Host: u.Hostname(), Port: u.Port(), User: u.User.Username()}
+		if s.Port == "" {
+			s.Port = "23"
+		}
+		return s, true
+	case "gopher":
+		item, err := ParseURL(urlStr)
+		if err != nil || item.Type != "2" {
+			return Session{}, false
+		}
+		return Session{Kind: "cso", Host: item.Host, Port: item.Port}, true
+	}
+	return Session{}, false
+}
+
+// Address returns host:port, with the login name for terminal sessions
+func (s Session) Address() string {
+	addr := net.JoinHostPort(s.Host, s.Port)
+	if s.User != "" {
+		addr = s.User + "@" + addr
+	}
+	return addr
+}
+
+// Description explains where the session leads, e.g. "telnet to
+// bbs.example.org:23"
+func (s Session) Description() string {
+	switch s.Kind {
+	case "telnet":
+		return "telnet to " + s.Address()
+	case "tn3270":
+		return "TN3270 session to " + s.Address()
+	default:
+		return "CSO phone book at " + s.Address()
+	}
+}
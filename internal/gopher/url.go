@@ -0,0 +1,149 @@
+package gopher
+
+import (
+	"fmt"
+	"net"
+	"net/url"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// Item identifies a Gopher resource: what a menu line points at and what a
+// gopher:// URL encodes
+type Item struct {
+	Host     string
+	Port     string
+	Type     string // Item type character, e.g. "0" text or "1" menu
+	Selector string
+	Search   string // Query for a search (type 7) item, sent after a tab
+}
+
+// ParseURL decodes a gopher:// URL (RFC 4266). A URL without a path is the
+// server's root menu. A search query may be given either %09-escaped in the
+// path or as the URL query.
+func ParseURL(urlStr string) (Item, error) {
+	u, err := url.Parse(urlStr)
+	if err != nil {
+		return Item{}, fmt.Errorf("invalid URL: %w", err)
+	}
+	if u.Scheme != "" && u.Scheme != "gopher" {
+		return Item{}, fmt.Errorf("unsupported scheme: %s (only gopher:// is supported)", u.Scheme)
+	}
+
+	item := Item{Host: u.Hostname(), Port: u.Port(), Type: "1"}
+	if item.Port == "" {
+		item.Port = "70" // Default gopher port
+	}
+
+	// The path is /<type><selector>, already unescaped by url.Parse
+	if len(u.Path) > 1 {
+		item.Type = u.Path[1:2]
+		item.Selector = u.Path[2:]
+	}
+	if item.Type == "7" && u.RawQuery != "" {
+		if query, err := url.QueryUnescape(u.RawQuery); err == nil {
+			item.Search = query
+		}
+	}
+	return item, nil
+}
+
+// URL encodes the item as a gopher:// URL, escaping characters in the
+// selector that would otherwise be read as a query or fragment
+func (i Item) URL() string {
+	path := "/" + i.Type + i.Selector
+	if i.Search != "" {
+		path += "\t" + i.Search
+	}
+	u := url.URL{Scheme: "gopher", Host: net.JoinHostPort(i.Host, i.Port), Path: path}
+	return u.String()
+}
+
+// EscapeURL rewrites a gopher:// URL written with bare spaces, question
+// marks and the like in its selector, as people type and paste them, into
+// the escaped form of RFC 4266. Only searches (type 7) take a query, so
+// elsewhere a "?" or "#" is taken to be part of the selector. Other URLs
+// come back unchanged.
+func EscapeURL(urlStr string) string {
+	u, err := url.Parse(urlStr)
+	if err != nil || u.Scheme != "gopher" {
+		return urlStr
+	}
+
+	search := len(u.Path) > 1 && u.Path[1] == '7'
+	if !search && (u.RawQuery != "" || u.ForceQuery) {
+		query, err := url.PathUnescape(u.RawQuery)
+		if err != nil {
+			return urlStr
+		}
+		u.Path += "?" + query
+		u.RawQuery, u.ForceQuery = "", false
+	}
+	if !search && (u.Fragment != "" || strings.HasSuffix(urlStr, "#")) {
+		u.Path += "#" + u.Fragment
+		u.Fragment, u.RawFragment = "", ""
+	}
+	u.RawQuery = strings.ReplaceAll(u.RawQuery, " ", "%20")
+	return u.String()
+}
+
+// DisplayURL undoes the escaping of a gopher:// URL's selector for showing
+// it, as far as EscapeURL can redo it: escapes of "%", "?", "#" and
+// control characters are kept, and so is everything if the selector is
+// not UTF-8 text. Other URLs come back unchanged.
+func DisplayURL(urlStr string) string {
+	rest, ok := strings.CutPrefix(urlStr, "gopher://")
+	if !ok {
+		return urlStr
+	}
+	slash := strings.IndexByte(rest, '/')
+	if slash < 0 || !strings.Contains(rest[slash:], "%") {
+		return urlStr
+	}
+	host, path := rest[:slash], rest[slash:]
+
+	var b strings.Builder
+	for i := 0; i < len(path); i++ {
+		if path[i] == '%' && i+2 < len(path) {
+			if c, err := url.PathUnescape(path[i : i+3]); err == nil && keepsMeaning(c[0]) {
+				b.WriteString(c)
+				i += 2
+				continue
+			}
+		}
+		b.WriteByte(path[i])
+	}
+	if !utf8.ValidString(b.String()) {
+		return urlStr
+	}
+	return "gopher://" + host + b.String()
+}
+
+// keepsMeaning reports whether an escaped byte can be shown as is without
+// changing what the URL points at
+func keepsMeaning(c byte) bool {
+	switch c {
+	case '%', '?', '#':
+		return false
+	}
+	return c >= 0x80 || !unicode.IsControl(rune(c))
+}
+
+// request returns the line sent to the server to fetch the item
+func (i Item) request() string {
+	if i.Search != "" {
+		return i.Selector + "\t" + i.Search + "\r\n"
+	}
+	return i.Selector + "\r\n"
+}
+
+// IsBinaryType reports whether an item type is a file to be saved rather
+// than displayed
+func IsBinaryType(itemType string) bool {
+	switch itemType {
+	case "4", "5", "6", "9", "s":
+		return true
+	}
+	return false
+}
@@ -0,0 +1,163 @@
+package gopher
+
+import (
+	"testing"
+
+	"starsearch/internal/types"
+)
+
+func TestParseURL(t *testing.T) {
+	tests := []struct {
+		url  string
+		want Item
+	}{
+		{"gopher://example.org", Item{Host: "example.org", Port: "70", Type: "1"}},
+		{"gopher://example.org/", Item{Host: "example.org", Port: "70", Type: "1"}},
+		{"gopher://example.org:7070/0/about.txt", Item{Host: "example.org", Port: "7070", Type: "0", Selector: "/about.txt"}},
+		{"gopher://example.org/1/my%20files", Item{Host: "example.org", Port: "70", Type: "1", Selector: "/my files"}},
+		{"gopher://example.org/7/search?two%20words", Item{Host: "example.org", Port: "70", Type: "7", Selector: "/search", Search: "two words"}},
+		{"gopher://example.org/7/search%09terms", Item{Host: "example.org", Port: "70", Type: "7", Selector: "/search\tterms"}},
+	}
+	for _, tt := range tests {
+		got, err := ParseURL(tt.url)
+		if err != nil {
+			t.Errorf("ParseURL(%q): %v", tt.url, err)
+			continue
+		}
+		if got != tt.want {
+			t.Errorf("ParseURL(%q) = %+v, want %+v", tt.url, got, tt.want)
+		}
+	}
+}
+
+func TestItemURLRoundTrip(t *testing.T) {
+	// Selectors with characters that mean something in a URL
+	for _, selector := range []string{"/plain", "/a file", "/what?", "/section#2", "/100%", "caps/NoSlash", ""} {
+		item := Item{Host: "example.org", Port: "70", Type: "0", Selector: selector}
+		got, err := ParseURL(item.URL())
+		if err != nil {
+			t.Errorf("%q: %v", item.URL(), err)
+			continue
+		}
+		if got != item {
+			t.Errorf("selector %q: round trip through %s gave %+v", selector, item.URL(), got)
+		}
+	}
+}
+
+func TestEscapeURL(t *testing.T) {
+	tests := []struct{ url, want string }{
+		{"gopher://example.org/", "gopher://example.org/"},
+		{"gopher://example.org:70/0/a%20file.txt", "gopher://example.org:70/0/a%20file.txt"},
+		{"gopher://example.org/0/a file.txt", "gopher://example.org/0/a%20file.txt"},
+		{"gopher://example.org/1/what?", "gopher://example.org/1/what%3F"},
+		{"gopher://example.org/0/faq?.txt", "gopher://example.org/0/faq%3F.txt"},
+		{"gopher://example.org/0/notes#2", "gopher://example.org/0/notes%232"},
+		{"gopher://example.org/7/search?two words", "gopher://example.org/7/search?two%20words"},
+		{"gemini://example.org/a b", "gemini://example.org/a b"},
+	}
+	for _, tt := range tests {
+		if got := EscapeURL(tt.url); got != tt.want {
+			t.Errorf("EscapeURL(%q) = %q, want %q", tt.url, got, tt.want)
+		}
+	}
+}
+
+func TestDisplayURL(t *testing.T) {
+	tests := []struct{ url, want string }{
+		{"gopher://example.org/0/a%20file.txt", "gopher://example.org/0/a file.txt"},
+		{"gopher://example.org/0/%C3%BCber", "gopher://example.org/0/über"},
+		{"gopher://example.org/1/what%3F", "gopher://example.org/1/what%3F"},
+		{"gopher://example.org/0/100%25%20sure", "gopher://example.org/0/100%25 sure"},
+		{"gopher://example.org/7/search%09terms", "gopher://example.org/7/search%09terms"},
+		{"gopher://example.org/9/%FF%FE", "gopher://example.org/9/%FF%FE"},
+		{"gemini://example.org/a%20b", "gemini://example.org/a%20b"},
+	}
+	for _, tt := range tests {
+		if got := DisplayURL(tt.url); got != tt.want {
+			t.Errorf("DisplayURL(%q) = %q, want %q", tt.url, got, tt.want)
+		}
+	}
+
+	// What is shown leads back to the same item
+	for _, selector := range []string{"/a file", "/what?", "/section#2", "/100% sure", "/naïve"} {
+		item := Item{Host: "example.org", Port: "70", Type: "0", Selector: selector}
+		got, err := ParseURL(EscapeURL(DisplayURL(item.URL())))
+		if err != nil || got != item {
+			t.Errorf("selector %q: shown as %q, read back as %+v (%v)", selector, DisplayURL(item.URL()), got, err)
+		}
+	}
+}
+
+func TestParserKeepsItemTypes(t *testing.T) {
+	menu := "9Archive\t/files/a b.zip\texample.org\t70\r\n" +
+		"1Odd menu\t?weird#selector\texample.org\t70\r\n" +
+		"iJust text\t\texample.org\t70\r\n"
+	doc, err := NewParser("gopher://example.org/1/").Parse(&types.Response{Meta: GetMIMEType("1"), Body: []byte(menu), URL: "gopher://example.org/1/"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if doc.ItemType != "1" {
+		t.Errorf("document item type %q, want 1", doc.ItemType)
+	}
+
+	want := []struct{ itemType, selector string }{{"9", "/files/a b.zip"}, {"1", "?weird#selector"}}
+	for i, link := range doc.Links {
+		if link.ItemType != want[i].itemType {
+			t.Errorf("link %d: item type %q, want %q", i, link.ItemType, want[i].itemType)
+		}
+		item, err := ParseURL(link.URL)
+		if err != nil || item.Type != want[i].itemType || item.Selector != want[i].selector {
+			t.Errorf("link %d: %s decodes to %+v (%v)", i, link.URL, item, err)
+		}
+	}
+	if doc.Lines[2].ItemType != "i" {
+		t.Errorf("info line item type %q", doc.Lines[2].ItemType)
+	}
+}
+
+func TestSessionItems(t *testing.T) {
+	menu := "8Library catalog\tguest\tlibrary.example.org\t23\r\n" +
+		"TMainframe\t\tmvs.example.org\t3270\r\n" +
+		"2Phone book\t\tcso.example.org\t105\r\n" +
+		".\r\n"
+	doc, err := NewParser("gopher://example.org/").Parse(&types.Response{
+		Status: 20,
+		Meta:   GetMIMEType("1"),
+		Body:   []byte(menu),
+		URL:    "gopher://example.org/",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := []struct {
+		url         string
+		description string
+	}{
+		{"telnet://guest@library.example.org:23", "telnet to guest@library.example.org:23"},
+		{"tn3270://mvs.example.org:3270", "TN3270 session to mvs.example.org:3270"},
+		{"gopher://cso.example.org:105/2", "CSO phone book at cso.example.org:105"},
+	}
+	for i, w := range want {
+		link := doc.Links[i]
+		if link.URL != w.url {
+			t.Errorf("link %d: URL %q, want %q", i, link.URL, w.url)
+		}
+		session, ok := SessionFor(link.URL)
+		if !ok {
+			t.Errorf("link %d: %s is not a session", i, link.URL)
+			continue
+		}
+		if got := session.Description(); got != w.description {
+			t.Errorf("link %d: description %q, want %q", i, got, w.description)
+		}
+	}
+
+	if _, ok := SessionFor("gopher://example.org/1/menu"); ok {
+		t.Error("a menu link should be fetched, not opened as a session")
+	}
+	if s, _ := SessionFor("telnet://bbs.example.org"); s.Port != "23" {
+		t.Errorf("telnet default port %q, want 23", s.Port)
+	}
+}
@@ -0,0 +1,153 @@
+// Package lint checks local gemtext files for common mistakes before they
+// are published: broken relative links, trailing whitespace, overlong lines
+// and links without labels.
+package lint
+
+import (
+	"fmt"
+	"net/url"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+	"unicode/utf8"
+
+	"starsearch/internal/gemini"
+	"starsearch/internal/types"
+)
+
+// DefaultMaxLineLength is the longest text line, in characters, that is not
+// reported. Preformatted lines are never reported.
+const DefaultMaxLineLength = 200
+
+// placeholderBase stands in for the capsule URL when none is given, so
+// relative links still resolve to paths under the root directory
+const placeholderBase = "gemini://localhost/"
+
+// Options configures a lint run
+type Options struct {
+	// Root is the directory served at Base. Defaults to the linted file's
+	// directory.
+	Root string
+
+	// Base is the URL Root is served at, e.g. gemini://example.org/. With
+	// it, absolute links into the capsule are checked as well as relative
+	// ones.
+	Base string
+
+	// MaxLineLength overrides DefaultMaxLineLength when positive
+	MaxLineLength int
+}
+
+// Diagnostic is one issue found in a file
+type Diagnostic struct {
+	File    string
+	Line    int
+	Message string
+}
+
+// String formats the diagnostic as file:line: message
+func (d Diagnostic) String() string {
+	return fmt.Sprintf("%s:%d: %s", d.File, d.Line, d.Message)
+}
+
+// File lints the gemtext file at filePath
+func File(filePath string, opts Options) ([]Diagnostic, error) {
+	body, err := os.ReadFile(filePath)
+	if err != nil {
+		return nil, err
+	}
+
+	root := opts.Root
+	if root == "" {
+		root = filepath.Dir(filePath)
+	}
+	rel, err := filepath.Rel(root, filePath)
+	if err != nil || strings.HasPrefix(rel, "..") {
+		return nil, fmt.Errorf("%s is not inside %s", filePath, root)
+	}
+
+	baseStr := opts.Base
+	if baseStr == "" {
+		baseStr = placeholderBase
+	}
+	base, err := url.Parse(baseStr)
+	if err != nil {
+		return nil, fmt.Errorf("invalid base URL: %w", err)
+	}
+	if !strings.HasSuffix(base.Path, "/") {
+		base.Path += "/"
+	}
+	fileURL := base.ResolveReference(&url.URL{Path: filepath.ToSlash(rel)})
+
+	maxLength := opts.MaxLineLength
+	if maxLength <= 0 {
+		maxLength = DefaultMaxLineLength
+	}
+
+	doc, err := gemini.NewParser(fileURL.String()).Parse(&types.Response{
+		Status: 20,
+		Meta:   "text/gemini",
+		Body:   body,
+		URL:    fileURL.String(),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse %s: %w", filePath, err)
+	}
+
+	var diagnostics []Diagnostic
+	report := func(lineNum int, format string, args ...any) {
+		diagnostics = append(diagnostics, Diagnostic{File: filePath, Line: lineNum, Message: fmt.Sprintf(format, args...)})
+	}
+
+	for i, line := range doc.Lines {
+		lineNum := i + 1
+
+		if trimmed := strings.TrimRight(line.Raw, " \t"); trimmed != line.Raw {
+			report(lineNum, "trailing whitespace")
+		}
+
+		switch line.Type {
+		case types.LinePreformatText, types.LinePreformatStart, types.LinePreformatEnd:
+			continue
+
+		case types.LineLink:
+			fields := strings.Fields(strings.TrimPrefix(line.Raw, "=>"))
+			if len(fields) == 0 {
+				report(lineNum, "link has no URL")
+				continue
+			}
+			if len(fields) == 1 {
+				report(lineNum, "link %s has no label", fields[0])
+			}
+			if msg := checkLink(line.URL, base, root); msg != "" {
+				report(lineNum, "%s", msg)
+			}
+		}
+
+		if length := utf8.RuneCountInString(line.Raw); length > maxLength {
+			report(lineNum, "line is %d characters long (over %d); consider splitting it", length, maxLength)
+		}
+	}
+
+	return diagnostics, nil
+}
+
+// checkLink reports a link into the capsule whose target does not exist
+// under root. Links elsewhere cannot be checked offline and are skipped.
+func checkLink(target string, base *url.URL, root string) string {
+	u, err := url.Parse(target)
+	if err != nil {
+		return fmt.Sprintf("invalid link URL %s", target)
+	}
+	if u.Scheme != base.Scheme || u.Host != base.Host || !strings.HasPrefix(u.Path, base.Path) {
+		return ""
+	}
+
+	rel := strings.TrimPrefix(path.Clean(u.Path), strings.TrimSuffix(base.Path, "/"))
+	local := filepath.Join(root, filepath.FromSlash(rel))
+	if _, err := os.Stat(local); err != nil {
+		return fmt.Sprintf("broken link: %s does not exist", local)
+	}
+	return ""
+}
@@ -0,0 +1,65 @@
+package lint
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestFile(t *testing.T) {
+	dir := t.TempDir()
+	files := map[string]string{
+		"exists.gmi": "# Here\n",
+		"index.gmi": "# Index \n" +
+			"=> exists.gmi Present\n" +
+			"=> missing.gmi Absent\n" +
+			"=> gemini://example.org/gone.gmi Absolute but local\n" +
+			"=> gemini://elsewhere.example/ Another capsule\n" +
+			"=> exists.gmi\n" +
+			strings.Repeat("word ", 50) + "end\n" +
+			"```\n" + strings.Repeat("-", 300) + "\n```\n",
+	}
+	for name, content := range files {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	diagnostics, err := File(filepath.Join(dir, "index.gmi"), Options{Base: "gemini://example.org/"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := map[int]string{
+		1: "trailing whitespace",
+		3: "missing.gmi does not exist",
+		4: "gone.gmi does not exist",
+		6: "has no label",
+		7: "characters long",
+	}
+	if len(diagnostics) != len(want) {
+		t.Errorf("got %d diagnostics, want %d: %v", len(diagnostics), len(want), diagnostics)
+	}
+	for _, d := range diagnostics {
+		if !strings.Contains(d.Message, want[d.Line]) || want[d.Line] == "" {
+			t.Errorf("unexpected diagnostic %s", d)
+		}
+	}
+}
+
+func TestFileWithoutBaseChecksRelativeLinksOnly(t *testing.T) {
+	dir := t.TempDir()
+	page := filepath.Join(dir, "page.gmi")
+	if err := os.WriteFile(page, []byte("=> gemini://example.org/anything Elsewhere\n=> nope.gmi Missing\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	diagnostics, err := File(page, Options{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(diagnostics) != 1 || diagnostics[0].Line != 2 {
+		t.Errorf("got %v, want only the missing relative link", diagnostics)
+	}
+}
@@ -0,0 +1,167 @@
+package renderer
+
+import (
+	"bytes"
+	"encoding/binary"
+	"image"
+	"strings"
+	"time"
+)
+
+// EXIF tags read from images
+const (
+	tagMake             = 0x010F
+	tagModel            = 0x0110
+	tagDateTime         = 0x0132
+	tagExifIFD          = 0x8769
+	tagDateTimeOriginal = 0x9003
+)
+
+// ImageInfo describes an image without decoding its pixels
+type ImageInfo struct {
+	Width, Height int
+	Format        string    // As registered with the image package, e.g. "jpeg"
+	Taken         time.Time // From EXIF; zero if not recorded
+	Camera        string    // Make and model from EXIF, if recorded
+}
+
+// ReadImageInfo reads the dimensions and format of an image, and when
+// and with what camera it was taken if it carries EXIF data. JPEG, PNG
+// and WebP images can carry EXIF data.
+func ReadImageInfo(data []byte) (ImageInfo, error) {
+	config, format, err := image.DecodeConfig(bytes.NewReader(data))
+	if err != nil {
+		return ImageInfo{}, err
+	}
+	info := ImageInfo{Width: config.Width, Height: config.Height, Format: format}
+
+	tiff := exifData(data)
+	if tiff == nil {
+		return info, nil
+	}
+	tags := readExif(tiff)
+	info.Camera = camera(tags[tagMake], tags[tagModel])
+	taken := tags[tagDateTimeOriginal]
+	if taken == "" {
+		taken = tags[tagDateTime]
+	}
+	if t, err := time.Parse("2006:01:02 15:04:05", taken); err == nil {
+		info.Taken = t
+	}
+	return info, nil
+}
+
+// exifData finds the TIFF-structured EXIF block of a JPEG, PNG or WebP
+// image, or returns nil
+func exifData(data []byte) []byte {
+	switch {
+	case bytes.HasPrefix(data, []byte{0xFF, 0xD8}):
+		// JPEG markers up to the image data; APP1 holds EXIF
+		for i := 2; i+4 <= len(data) && data[i] == 0xFF; {
+			marker := data[i+1]
+			length := int(binary.BigEndian.Uint16(data[i+2:]))
+			if marker == 0xDA || length < 2 || i+2+length > len(data) {
+				return nil
+			}
+			segment := data[i+4 : i+2+length]
+			if marker == 0xE1 && bytes.HasPrefix(segment, []byte("Exif\x00\x00")) {
+				return segment[6:]
+			}
+			i += 2 + length
+		}
+
+	case bytes.HasPrefix(data, []byte("\x89PNG\r\n\x1a\n")):
+		// Chunks of length, type, data and CRC; eXIf holds EXIF
+		for i := 8; i+8 <= len(data); {
+			length := int(binary.BigEndian.Uint32(data[i:]))
+			if length < 0 || i+12+length > len(data) {
+				return nil
+			}
+			if string(data[i+4:i+8]) == "eXIf" {
+				return data[i+8 : i+8+length]
+			}
+			i += 12 + length
+		}
+
+	case len(data) >= 12 && string(data[:4]) == "RIFF" && string(data[8:12]) == "WEBP":
+		// Chunks of type and little-endian length, padded to even sizes
+		for i := 12; i+8 <= len(data); {
+			length := int(binary.LittleEndian.Uint32(data[i+4:]))
+			if length < 0 || i+8+length > len(data) {
+				return nil
+			}
+			if string(data[i:i+4]) == "EXIF" {
+				return bytes.TrimPrefix(data[i+8:i+8+length], []byte("Exif\x00\x00"))
+			}
+			i += 8 + length + length%2
+		}
+	}
+	return nil
+}
+
+// readExif reads the text tags of IFD0 and the EXIF IFD it points to,
+// skipping anything malformed
+func readExif(tiff []byte) map[uint16]string {
+	tags := make(map[uint16]string)
+	if len(tiff) < 8 {
+		return tags
+	}
+	var order binary.ByteOrder
+	switch string(tiff[:2]) {
+	case "II":
+		order = binary.LittleEndian
+	case "MM":
+		order = binary.BigEndian
+	default:
+		return tags
+	}
+
+	var readIFD func(offset uint32, depth int)
+	readIFD = func(offset uint32, depth int) {
+		if depth > 1 || int64(offset)+2 > int64(len(tiff)) {
+			return
+		}
+		count := int(order.Uint16(tiff[offset:]))
+		for n := 0; n < count; n++ {
+			entry := int(offset) + 2 + 12*n
+			if entry+12 > len(tiff) {
+				return
+			}
+			tag := order.Uint16(tiff[entry:])
+			kind := order.Uint16(tiff[entry+2:])
+			size := order.Uint32(tiff[entry+4:])
+			value := tiff[entry+8 : entry+12]
+
+			switch {
+			case tag == tagExifIFD && kind == 4:
+				readIFD(order.Uint32(value), depth+1)
+			case kind == 2:
+				// ASCII, stored in the entry itself if it fits
+				if size > 4 {
+					start := order.Uint32(value)
+					if int64(start)+int64(size) > int64(len(tiff)) {
+						continue
+					}
+					value = tiff[start : start+size]
+				} else {
+					value = value[:size]
+				}
+				tags[tag] = strings.TrimSpace(strings.TrimRight(string(value), "\x00"))
+			}
+		}
+	}
+	readIFD(order.Uint32(tiff[4:]), 0)
+	return tags
+}
+
+// camera names a camera by its maker and model. Models usually start with
+// the maker's name already.
+func camera(maker, model string) string {
+	switch {
+	case model == "":
+		return maker
+	case maker == "" || strings.HasPrefix(strings.ToLower(model), strings.ToLower(maker)):
+		return model
+	}
+	return maker + " " + model
+}
@@ -20,6 +20,39 @@ type ImageRenderer struct {
 	maxHeight int
 }
 
+// ImageFit is how an image is sized to the window
+type ImageFit int
+
+const (
+	FitWindow  ImageFit = iota // The whole image on screen, never enlarged
+	FitWidth                   // The window's width; the rest scrolls
+	FitHeight                  // The window's height, cropped at the sides
+	ActualSize                 // One pixel per half cell, cropped to the window
+)
+
+// String names the fit for the status bar
+func (f ImageFit) String() string {
+	switch f {
+	case FitWidth:
+		return "fit width"
+	case FitHeight:
+		return "fit height"
+	case ActualSize:
+		return "1:1"
+	}
+	return "fit window"
+}
+
+// ImageOptions control how an image is drawn
+type ImageOptions struct {
+	Fit       ImageFit
+	Colors256 bool // Draw with xterm's 256 colours instead of true colour
+	Dither    bool // Floyd–Steinberg dither to the 256 colours
+	Grayscale bool
+	PanX      int // Pixels cropped off the left, when cropping
+	PanY      int // Pixels cropped off the top
+}
+
 // NewImageRenderer creates a new image renderer
 func NewImageRenderer(maxWidth, maxHeight int) *ImageRenderer {
 	return &ImageRenderer{
@@ -28,62 +61,133 @@ func NewImageRenderer(maxWidth, maxHeight int) *ImageRenderer {
 	}
 }
 
-// RenderImage renders an image as Unicode blocks
+// RenderImage renders an image as Unicode blocks, fitted to the window in
+// true colour
 func (r *ImageRenderer) RenderImage(imageData []byte) (string, error) {
+	rendered, _, err := r.Render(imageData, ImageOptions{})
+	return rendered, err
+}
+
+// Render renders an image as Unicode blocks as opts say. Pan offsets past
+// the edge of the image are pulled back; the options returned hold the
+// offsets used, for panning further from there.
+func (r *ImageRenderer) Render(imageData []byte, opts ImageOptions) (string, ImageOptions, error) {
 	// Decode image
 	img, _, err := image.Decode(bytes.NewReader(imageData))
 	if err != nil {
-		return "", fmt.Errorf("failed to decode image: %w", err)
+		return "", opts, fmt.Errorf("failed to decode image: %w", err)
+	}
+	if opts.Grayscale {
+		img = imaging.Grayscale(img)
 	}
 
 	// Calculate dimensions (each character represents 2 vertical pixels using half-blocks)
 	bounds := img.Bounds()
 	imgWidth := bounds.Dx()
 	imgHeight := bounds.Dy()
+	targetWidth, targetHeight := r.fit(imgWidth, imgHeight, opts.Fit)
 
-	// Calculate target dimensions to fit within terminal
+	// Resize image
+	var resized image.Image = img
+	if targetWidth != imgWidth || targetHeight != imgHeight {
+		resized = imaging.Resize(img, targetWidth, targetHeight, imaging.Lanczos)
+	}
+
+	// Crop what does not fit the window when the fit allows it to overflow
+	viewWidth, viewHeight := targetWidth, targetHeight
+	if opts.Fit == FitHeight || opts.Fit == ActualSize {
+		viewWidth = min(targetWidth, r.maxWidth)
+	}
+	if opts.Fit == ActualSize {
+		viewHeight = min(targetHeight, r.maxHeight*2)
+	}
+	opts.PanX = max(0, min(opts.PanX, targetWidth-viewWidth))
+	opts.PanY = max(0, min(opts.PanY, targetHeight-viewHeight))
+	if viewWidth != targetWidth || viewHeight != targetHeight {
+		resized = imaging.Crop(resized, image.Rect(opts.PanX, opts.PanY, opts.PanX+viewWidth, opts.PanY+viewHeight))
+	}
+
+	var out strings.Builder
+
+	// Say how the image is shown; ReadImageInfo describes the image itself
+	out.WriteString(fmt.Sprintf("Displayed as %dx%d, %s\n\n", viewWidth, (viewHeight+1)/2, describeView(opts, targetWidth, targetHeight, viewWidth, viewHeight)))
+
+	if opts.Colors256 {
+		writeHalfBlocks256(&out, imaging.Clone(resized), opts.Dither)
+	} else {
+		writeHalfBlocks(&out, resized)
+	}
+	return out.String(), opts, nil
+}
+
+// fit returns the size an image of width by height pixels is scaled to
+func (r *ImageRenderer) fit(width, height int, fit ImageFit) (int, int) {
 	// Each character cell is roughly 2 pixels tall when using half-blocks
-	targetWidth := r.maxWidth
-	targetHeight := r.maxHeight * 2
-
-	// Maintain aspect ratio
-	if imgWidth > targetWidth || imgHeight > targetHeight {
-		ratio := float64(imgWidth) / float64(imgHeight)
-		if imgWidth > targetWidth {
-			targetWidth = r.maxWidth
+	maxWidth, maxHeight := max(r.maxWidth, 1), max(r.maxHeight*2, 2)
+	ratio := float64(width) / float64(height)
+
+	var targetWidth, targetHeight int
+	switch fit {
+	case ActualSize:
+		return width, height
+	case FitWidth:
+		targetWidth = maxWidth
+		targetHeight = int(float64(targetWidth) / ratio)
+	case FitHeight:
+		targetHeight = maxHeight
+		targetWidth = int(float64(targetHeight) * ratio)
+	default:
+		// Maintain aspect ratio, only ever shrinking
+		targetWidth, targetHeight = width, height
+		if targetWidth > maxWidth {
+			targetWidth = maxWidth
 			targetHeight = int(float64(targetWidth) / ratio)
 		}
-		if targetHeight > r.maxHeight*2 {
-			targetHeight = r.maxHeight * 2
+		if targetHeight > maxHeight {
+			targetHeight = maxHeight
 			targetWidth = int(float64(targetHeight) * ratio)
 		}
-	} else {
-		targetWidth = imgWidth
-		targetHeight = imgHeight
 	}
 
 	// Ensure even height for half-block rendering
 	if targetHeight%2 != 0 {
 		targetHeight++
 	}
+	return max(targetWidth, 1), max(targetHeight, 2)
+}
 
-	// Resize image
-	resized := imaging.Resize(img, targetWidth, targetHeight, imaging.Lanczos)
-
-	// Render using half-blocks (▀ for upper half)
-	var out strings.Builder
+// describeView sums up the options an image is drawn with, and which part
+// of it shows when cropped
+func describeView(opts ImageOptions, width, height, viewWidth, viewHeight int) string {
+	parts := []string{opts.Fit.String()}
+	if viewWidth < width || viewHeight < height {
+		parts = append(parts, fmt.Sprintf("showing from %d,%d", opts.PanX, opts.PanY))
+	}
+	switch {
+	case opts.Colors256 && opts.Dither:
+		parts = append(parts, "256 colours, dithered")
+	case opts.Colors256:
+		parts = append(parts, "256 colours")
+	}
+	if opts.Grayscale {
+		parts = append(parts, "grayscale")
+	}
+	return strings.Join(parts, ", ")
+}
 
-	// Add image info
-	out.WriteString(fmt.Sprintf("Image: %dx%d (displayed as %dx%d)\n\n", imgWidth, imgHeight, targetWidth, targetHeight/2))
+// writeHalfBlocks draws img in true colour, two pixels a cell
+func writeHalfBlocks(out *strings.Builder, img image.Image) {
+	bounds := img.Bounds()
+	targetWidth, targetHeight := bounds.Dx(), bounds.Dy()
 
 	// Process pairs of rows
 	for y := 0; y < targetHeight; y += 2 {
 		for x := 0; x < targetWidth; x++ {
 			// Get colors for upper and lower pixels
-			upperColor := resized.At(x, y)
+			upperColor := img.At(bounds.Min.X+x, bounds.Min.Y+y)
 			var lowerColor color.Color
 			if y+1 < targetHeight {
-				lowerColor = resized.At(x, y+1)
+				lowerColor = img.At(bounds.Min.X+x, bounds.Min.Y+y+1)
 			} else {
 				lowerColor = color.RGBA{0, 0, 0, 0}
 			}
@@ -115,8 +219,6 @@ func (r *ImageRenderer) RenderImage(imageData []byte) (string, error) {
 		}
 		out.WriteString("\n")
 	}
-
-	return out.String(), nil
 }
 
 // IsImageMIME checks if a MIME type is an image
@@ -0,0 +1,123 @@
+package renderer
+
+import (
+	"fmt"
+	"image"
+	"strings"
+)
+
+// cubeLevels are the channel values of xterm's 6×6×6 colour cube
+var cubeLevels = [6]int{0, 95, 135, 175, 215, 255}
+
+// nearest256 returns the xterm colour closest to r, g, b and the colour it
+// stands for. The first 16 colours are left out, as terminals theme them.
+func nearest256(r, g, b int) (index int, pr, pg, pb int) {
+	// Closest in the colour cube, channel by channel
+	level := func(v int) int {
+		best := 0
+		for i, l := range cubeLevels {
+			if abs(v-l) < abs(v-cubeLevels[best]) {
+				best = i
+			}
+		}
+		return best
+	}
+	ri, gi, bi := level(r), level(g), level(b)
+	index = 16 + 36*ri + 6*gi + bi
+	pr, pg, pb = cubeLevels[ri], cubeLevels[gi], cubeLevels[bi]
+
+	// The grey ramp runs from 8 to 238 in steps of 10
+	grey := (r + g + b) / 3
+	step := max(0, min(23, (grey-3)/10))
+	gv := 8 + 10*step
+	if distance(r, g, b, gv, gv, gv) < distance(r, g, b, pr, pg, pb) {
+		return 232 + step, gv, gv, gv
+	}
+	return index, pr, pg, pb
+}
+
+func distance(r1, g1, b1, r2, g2, b2 int) int {
+	dr, dg, db := r1-r2, g1-g2, b1-b2
+	return dr*dr + dg*dg + db*db
+}
+
+func abs(v int) int {
+	if v < 0 {
+		return -v
+	}
+	return v
+}
+
+// quantize256 maps every pixel of img to xterm's 256 colours, spreading
+// the error of each over its neighbours (Floyd–Steinberg) if dither is
+// set. Transparent pixels are -1, and take no part in the dithering.
+func quantize256(img *image.NRGBA, dither bool) [][]int {
+	width, height := img.Rect.Dx(), img.Rect.Dy()
+	indices := make([][]int, height)
+
+	// Error carried into the current and the next row, per channel
+	current := make([][3]int, width+2)
+	next := make([][3]int, width+2)
+	for y := 0; y < height; y++ {
+		indices[y] = make([]int, width)
+		for x := 0; x < width; x++ {
+			offset := y*img.Stride + x*4
+			pixel := img.Pix[offset : offset+4]
+			if pixel[3] < 128 {
+				indices[y][x] = -1
+				continue
+			}
+
+			carried := current[x+1]
+			r := clamp(int(pixel[0]) + carried[0]/16)
+			g := clamp(int(pixel[1]) + carried[1]/16)
+			b := clamp(int(pixel[2]) + carried[2]/16)
+			index, pr, pg, pb := nearest256(r, g, b)
+			indices[y][x] = index
+			if !dither {
+				continue
+			}
+
+			// 7/16 right, 3/16 below left, 5/16 below, 1/16 below right
+			errs := [3]int{r - pr, g - pg, b - pb}
+			for c, e := range errs {
+				current[x+2][c] += e * 7
+				next[x][c] += e * 3
+				next[x+1][c] += e * 5
+				next[x+2][c] += e
+			}
+		}
+		current, next = next, current
+		clear(next)
+	}
+	return indices
+}
+
+func clamp(v int) int {
+	return max(0, min(255, v))
+}
+
+// writeHalfBlocks256 draws img in xterm's 256 colours, two pixels a cell
+func writeHalfBlocks256(out *strings.Builder, img *image.NRGBA, dither bool) {
+	indices := quantize256(img, dither)
+	for y := 0; y < len(indices); y += 2 {
+		for x, upper := range indices[y] {
+			lower := -1
+			if y+1 < len(indices) {
+				lower = indices[y+1][x]
+			}
+
+			switch {
+			case upper < 0 && lower < 0:
+				out.WriteString(" ")
+			case upper < 0:
+				fmt.Fprintf(out, "\x1b[38;5;%dm█\x1b[0m", lower)
+			case lower < 0:
+				fmt.Fprintf(out, "\x1b[38;5;%dm▀\x1b[0m", upper)
+			default:
+				fmt.Fprintf(out, "\x1b[38;5;%d;48;5;%dm▀\x1b[0m", upper, lower)
+			}
+		}
+		out.WriteString("\n")
+	}
+}
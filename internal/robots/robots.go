@@ -0,0 +1,161 @@
+// Package robots honors robots.txt for starsearch's automated requests,
+// following the Gemini convention: capsules publish the file at
+// gemini://host/robots.txt, and only requests a person did not ask for
+// directly, such as periodic reloads, are bound by it.
+package robots
+
+import (
+	"bufio"
+	"bytes"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"starsearch/internal/types"
+)
+
+// UserAgent is the name starsearch's automated requests answer to in
+// robots.txt, besides the "*" group
+const UserAgent = "starsearch"
+
+// rule allows or disallows paths starting with prefix
+type rule struct {
+	prefix string
+	allow  bool
+}
+
+// Policy is a parsed robots.txt: the rules of each user agent's group
+type Policy struct {
+	groups map[string][]rule // By lower-case user agent
+}
+
+// Parse reads a robots.txt file. Lines other than User-agent, Allow and
+// Disallow are ignored, as are comments.
+func Parse(body []byte) *Policy {
+	p := &Policy{groups: map[string][]rule{}}
+	var agents []string
+	inRules := false // Whether the current group has had rules yet
+
+	scanner := bufio.NewScanner(bytes.NewReader(body))
+	for scanner.Scan() {
+		line, _, _ := strings.Cut(scanner.Text(), "#")
+		field, value, ok := strings.Cut(line, ":")
+		if !ok {
+			continue
+		}
+		field = strings.ToLower(strings.TrimSpace(field))
+		value = strings.TrimSpace(value)
+
+		switch field {
+		case "user-agent":
+			// Consecutive User-agent lines share the rules that follow
+			if inRules {
+				agents = nil
+				inRules = false
+			}
+			agent := strings.ToLower(value)
+			agents = append(agents, agent)
+			if _, ok := p.groups[agent]; !ok {
+				p.groups[agent] = nil
+			}
+		case "allow", "disallow":
+			inRules = true
+			if value == "" {
+				continue // An empty Disallow allows everything
+			}
+			for _, agent := range agents {
+				p.groups[agent] = append(p.groups[agent], rule{prefix: value, allow: field == "allow"})
+			}
+		}
+	}
+	return p
+}
+
+// Allowed reports whether an automated request for path is permitted.
+// The starsearch group applies if there is one, otherwise the "*" group.
+// The longest matching rule wins, so an Allow can open up part of a
+// disallowed tree.
+func (p *Policy) Allowed(path string) bool {
+	rules, ok := p.groups[UserAgent]
+	if !ok {
+		rules = p.groups["*"]
+	}
+	if path == "" {
+		path = "/"
+	}
+
+	allowed, longest := true, -1
+	for _, r := range rules {
+		if strings.HasPrefix(path, r.prefix) && len(r.prefix) > longest {
+			allowed, longest = r.allow, len(r.prefix)
+		}
+	}
+	return allowed
+}
+
+// Fetcher fetches a URL, like the Gemini client
+type Fetcher interface {
+	Fetch(urlStr string) (*types.Response, error)
+}
+
+// entry is a host's policy and when it was fetched
+type entry struct {
+	policy  *Policy
+	fetched time.Time
+}
+
+// Cache fetches each capsule's robots.txt when first needed and keeps the
+// policy for a while. A capsule without one, or that cannot be reached,
+// allows everything.
+type Cache struct {
+	mu       sync.Mutex
+	fetcher  Fetcher
+	ttl      time.Duration
+	policies map[string]entry // By host and port
+}
+
+// NewCache creates a cache fetching robots.txt with fetcher and keeping
+// each policy for ttl
+func NewCache(fetcher Fetcher, ttl time.Duration) *Cache {
+	return &Cache{
+		fetcher:  fetcher,
+		ttl:      ttl,
+		policies: make(map[string]entry),
+	}
+}
+
+// Allowed reports whether an automated request for rawURL is permitted,
+// fetching the capsule's robots.txt if it is not known yet. It blocks
+// while fetching, so call it from a command's goroutine. Only Gemini
+// addresses are checked.
+func (c *Cache) Allowed(rawURL string) bool {
+	u, err := url.Parse(rawURL)
+	if err != nil || u.Scheme != "gemini" || u.Host == "" {
+		return true
+	}
+	// robots.txt itself is always fetched
+	if u.Path == "/robots.txt" {
+		return true
+	}
+
+	c.mu.Lock()
+	cached, ok := c.policies[u.Host]
+	c.mu.Unlock()
+	if !ok || time.Since(cached.fetched) > c.ttl {
+		cached = entry{policy: c.fetch(u.Host), fetched: time.Now()}
+		c.mu.Lock()
+		c.policies[u.Host] = cached
+		c.mu.Unlock()
+	}
+	return cached.policy.Allowed(u.EscapedPath())
+}
+
+// fetch gets the policy of host, or an empty one allowing everything
+func (c *Cache) fetch(host string) *Policy {
+	resp, err := c.fetcher.Fetch("gemini://" + host + "/robots.txt")
+	if err != nil || resp.Status < 20 || resp.Status > 29 {
+		return Parse(nil)
+	}
+	return Parse(resp.Body)
+}
@@ -0,0 +1,70 @@
+package robots
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"starsearch/internal/types"
+)
+
+func TestPolicy(t *testing.T) {
+	policy := Parse([]byte(`# Keep crawlers off the guestbook
+User-agent: archiver
+User-agent: indexer
+Disallow: /
+
+User-agent: *
+Disallow: /guestbook
+Allow: /guestbook/rules.gmi
+Disallow:
+`))
+
+	for path, want := range map[string]bool{
+		"/":                    true,
+		"/gemlog/":             true,
+		"/guestbook":           false,
+		"/guestbook/new":       false,
+		"/guestbook/rules.gmi": true,
+		"":                     true,
+	} {
+		if got := policy.Allowed(path); got != want {
+			t.Errorf("Allowed(%q) = %v, want %v", path, got, want)
+		}
+	}
+
+	// A starsearch group takes the place of the "*" one
+	own := Parse([]byte("User-agent: *\nDisallow: /\n\nUser-agent: starsearch\nDisallow: /private\n"))
+	if !own.Allowed("/public") || own.Allowed("/private/x") {
+		t.Error("the starsearch group was not used")
+	}
+}
+
+// fetcherFunc adapts a function to Fetcher
+type fetcherFunc func(string) (*types.Response, error)
+
+func (f fetcherFunc) Fetch(urlStr string) (*types.Response, error) { return f(urlStr) }
+
+func TestCache(t *testing.T) {
+	var requests []string
+	cache := NewCache(fetcherFunc(func(urlStr string) (*types.Response, error) {
+		requests = append(requests, urlStr)
+		if urlStr == "gemini://down.example/robots.txt" {
+			return nil, fmt.Errorf("connection refused")
+		}
+		return &types.Response{Status: 20, Body: []byte("User-agent: *\nDisallow: /private\n")}, nil
+	}), time.Hour)
+
+	if cache.Allowed("gemini://example.org/private/a") || !cache.Allowed("gemini://example.org/public") {
+		t.Error("the policy of example.org was not applied")
+	}
+	if !cache.Allowed("gemini://down.example/private") {
+		t.Error("an unreachable robots.txt should allow everything")
+	}
+	if !cache.Allowed("gopher://example.org/1/private") {
+		t.Error("Gopher addresses are not checked")
+	}
+	if len(requests) != 2 {
+		t.Errorf("robots.txt fetched %d times, want once per host: %v", len(requests), requests)
+	}
+}
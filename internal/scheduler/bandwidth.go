@@ -0,0 +1,72 @@
+package scheduler
+
+import (
+	"sync"
+	"time"
+)
+
+// Bandwidth paces transfers so that on average they use no more than a
+// set number of bytes per second. A transfer is not slowed down while it
+// runs; it is paid for once done, and the next one waits until the budget
+// has recovered. Up to one second's worth may be spent at once.
+type Bandwidth struct {
+	mu     sync.Mutex
+	rate   float64   // Bytes per second; 0 or less is unlimited
+	tokens float64   // Bytes that may still be spent; negative when overspent
+	last   time.Time // When tokens was last refilled
+	now    func() time.Time
+	sleep  func(time.Duration)
+}
+
+// NewBandwidth creates a budget of bytesPerSecond; 0 or less is unlimited
+func NewBandwidth(bytesPerSecond int) *Bandwidth {
+	return &Bandwidth{
+		rate:   float64(bytesPerSecond),
+		tokens: float64(bytesPerSecond),
+		last:   time.Now(),
+		now:    time.Now,
+		sleep:  time.Sleep,
+	}
+}
+
+// refill adds what the budget recovered since it was last refilled. It
+// must be called with mu held.
+func (b *Bandwidth) refill() {
+	now := b.now()
+	b.tokens += now.Sub(b.last).Seconds() * b.rate
+	if b.tokens > b.rate {
+		b.tokens = b.rate
+	}
+	b.last = now
+}
+
+// Wait blocks until earlier transfers have been paid for
+func (b *Bandwidth) Wait() {
+	for {
+		b.mu.Lock()
+		if b.rate <= 0 {
+			b.mu.Unlock()
+			return
+		}
+		b.refill()
+		if b.tokens >= 0 {
+			b.mu.Unlock()
+			return
+		}
+		wait := time.Duration(-b.tokens / b.rate * float64(time.Second))
+		b.mu.Unlock()
+		b.sleep(wait)
+	}
+}
+
+// Spend records that n bytes were transferred
+func (b *Bandwidth) Spend(n int) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if b.rate <= 0 {
+		return
+	}
+	b.refill()
+	b.tokens -= float64(n)
+}
@@ -0,0 +1,63 @@
+package scheduler
+
+import (
+	"testing"
+	"time"
+)
+
+func TestBandwidthPacesTransfers(t *testing.T) {
+	clock := time.Unix(0, 0)
+	var slept time.Duration
+	b := NewBandwidth(1000)
+	b.now = func() time.Time { return clock }
+	b.last = clock
+	b.sleep = func(d time.Duration) {
+		slept += d
+		clock = clock.Add(d)
+	}
+
+	// One second's worth may be spent at once
+	b.Spend(1000)
+	b.Wait()
+	if slept != 0 {
+		t.Errorf("waited %v within the burst", slept)
+	}
+
+	// Overspending is paid back before the next transfer
+	b.Spend(2500)
+	b.Wait()
+	if slept != 2500*time.Millisecond {
+		t.Errorf("waited %v after overspending by 2500 bytes at 1000 B/s, want 2.5s", slept)
+	}
+
+	// Idle time recovers the budget, but no more than the burst
+	clock = clock.Add(time.Minute)
+	b.Spend(1500)
+	b.Wait()
+	if slept != 3*time.Second {
+		t.Errorf("waited %v in total, want another 0.5s", slept)
+	}
+}
+
+func TestSchedulerLimitsOnlyBackground(t *testing.T) {
+	s := New(4, 2, 2)
+	s.LimitBackground(1000)
+	var slept time.Duration
+	s.bandwidth.sleep = func(d time.Duration) { slept += d; s.bandwidth.last = s.bandwidth.last.Add(-d) }
+
+	s.Transferred(Interactive, 5000)
+	s.Acquire("a.example", Background)()
+	if slept != 0 {
+		t.Errorf("interactive transfers should not count against the budget, waited %v", slept)
+	}
+
+	s.Transferred(Background, 3000)
+	s.Acquire("a.example", Interactive)()
+	if slept != 0 {
+		t.Errorf("interactive requests should not wait, waited %v", slept)
+	}
+	s.Acquire("a.example", Background)()
+	if slept < time.Second {
+		t.Errorf("background request waited %v after overspending, want about 2s", slept)
+	}
+}
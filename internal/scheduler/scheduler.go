@@ -0,0 +1,156 @@
+package scheduler
+
+import (
+	"sync"
+)
+
+// Priority orders waiting requests. Interactive requests are always granted
+// before background ones.
+type Priority int
+
+const (
+	Interactive Priority = iota // Navigation the user is waiting on
+	Background                  // Background tabs, downloads and other work
+)
+
+// waiter is a request queued for a slot
+type waiter struct {
+	host     string
+	priority Priority
+}
+
+// Scheduler limits how many requests run at once, in total and per host.
+// Background requests never take the last free slot, so interactive
+// navigation is not starved by background work, and can be held to a
+// bandwidth budget.
+type Scheduler struct {
+	mu            sync.Mutex
+	cond          *sync.Cond
+	maxGlobal     int
+	maxPerHost    int
+	maxBackground int
+	active        int
+	background    int
+	perHost       map[string]int
+	waiting       []*waiter  // In arrival order
+	bandwidth     *Bandwidth // Budget for background transfers; nil is unlimited
+}
+
+// New creates a scheduler. maxBackground is capped so that at least one
+// slot is always left for interactive requests.
+func New(maxGlobal, maxPerHost, maxBackground int) *Scheduler {
+	if maxGlobal < 2 {
+		maxGlobal = 2
+	}
+	if maxPerHost < 1 {
+		maxPerHost = 1
+	}
+	if maxBackground < 1 || maxBackground > maxGlobal-1 {
+		maxBackground = maxGlobal - 1
+	}
+
+	s := &Scheduler{
+		maxGlobal:     maxGlobal,
+		maxPerHost:    maxPerHost,
+		maxBackground: maxBackground,
+		perHost:       make(map[string]int),
+	}
+	s.cond = sync.NewCond(&s.mu)
+	return s
+}
+
+// LimitBackground holds background transfers to bytesPerSecond on
+// average; 0 or less lifts the limit. It must be called before any
+// request is made.
+func (s *Scheduler) LimitBackground(bytesPerSecond int) {
+	s.bandwidth = nil
+	if bytesPerSecond > 0 {
+		s.bandwidth = NewBandwidth(bytesPerSecond)
+	}
+}
+
+// Transferred records how many bytes a request of the given priority
+// received, so later background requests keep to the bandwidth budget
+func (s *Scheduler) Transferred(priority Priority, bytes int) {
+	if priority == Background && s.bandwidth != nil {
+		s.bandwidth.Spend(bytes)
+	}
+}
+
+// Acquire blocks until a request to host may start and returns a function
+// that must be called when the request has finished. Background requests
+// first wait for the bandwidth budget to cover earlier transfers.
+func (s *Scheduler) Acquire(host string, priority Priority) (release func()) {
+	if priority == Background && s.bandwidth != nil {
+		s.bandwidth.Wait()
+	}
+
+	s.mu.Lock()
+	w := &waiter{host: host, priority: priority}
+	s.waiting = append(s.waiting, w)
+	for s.next() != w {
+		s.cond.Wait()
+	}
+
+	s.remove(w)
+	s.active++
+	s.perHost[host]++
+	if priority == Background {
+		s.background++
+	}
+	// Another waiter may be able to start too, e.g. for a different host
+	s.cond.Broadcast()
+	s.mu.Unlock()
+
+	var once sync.Once
+	return func() {
+		once.Do(func() { s.release(host, priority) })
+	}
+}
+
+// release frees the slot held by a finished request
+func (s *Scheduler) release(host string, priority Priority) {
+	s.mu.Lock()
+	s.active--
+	s.perHost[host]--
+	if s.perHost[host] <= 0 {
+		delete(s.perHost, host)
+	}
+	if priority == Background {
+		s.background--
+	}
+	s.cond.Broadcast()
+	s.mu.Unlock()
+}
+
+// next returns the waiter that should be granted the next free slot: the
+// earliest interactive waiter that fits, else the earliest background one.
+// It returns nil if no waiter can start yet.
+func (s *Scheduler) next() *waiter {
+	for _, priority := range []Priority{Interactive, Background} {
+		for _, w := range s.waiting {
+			if w.priority == priority && s.fits(w) {
+				return w
+			}
+		}
+	}
+	return nil
+}
+
+// fits reports whether w could start without exceeding any limit
+func (s *Scheduler) fits(w *waiter) bool {
+	if s.active >= s.maxGlobal || s.perHost[w.host] >= s.maxPerHost {
+		return false
+	}
+	return w.priority == Interactive || s.background < s.maxBackground
+}
+
+// remove drops w from the waiting list
+func (s *Scheduler) remove(w *waiter) {
+	for i, other := range s.waiting {
+		if other == w {
+			s.waiting = append(s.waiting[:i], s.waiting[i+1:]...)
+			return
+		}
+	}
+}
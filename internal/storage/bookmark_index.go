@@ -0,0 +1,179 @@
+package storage
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// maxIndexedText is how much of a page's text is kept for searching
+const maxIndexedText = 64 << 10
+
+// snippetContext is how many characters around a match a snippet shows
+const snippetContext = 30
+
+// indexedPage is the text of a bookmarked page as recorded on disk
+type indexedPage struct {
+	URL     string `json:"url"`
+	Text    string `json:"text"`
+	Indexed int64  `json:"indexed"` // When the page was fetched, Unix seconds
+}
+
+// BookmarkIndex keeps the text of bookmarked pages so bookmarks can be
+// found by what the page says, not only by their title
+type BookmarkIndex struct {
+	mu        sync.RWMutex
+	pages     map[string]*indexedPage
+	storePath string
+}
+
+// NewBookmarkIndex creates a new bookmark content index
+func NewBookmarkIndex(storePath string) *BookmarkIndex {
+	i := &BookmarkIndex{
+		pages:     make(map[string]*indexedPage),
+		storePath: storePath,
+	}
+
+	// Try to load the existing index
+	_ = i.Load() // Ignore errors
+
+	return i
+}
+
+// Has reports whether the page at url has been indexed
+func (i *BookmarkIndex) Has(url string) bool {
+	i.mu.RLock()
+	defer i.mu.RUnlock()
+	_, ok := i.pages[url]
+	return ok
+}
+
+// Set records the text of the page at url, replacing what was indexed
+// before. Very long pages are cut short.
+func (i *BookmarkIndex) Set(url, text string) {
+	if len(text) > maxIndexedText {
+		text = strings.ToValidUTF8(text[:maxIndexedText], "")
+	}
+
+	i.mu.Lock()
+	i.pages[url] = &indexedPage{URL: url, Text: text, Indexed: time.Now().Unix()}
+	i.mu.Unlock()
+
+	_ = i.Save()
+}
+
+// Remove forgets the text of the page at url
+func (i *BookmarkIndex) Remove(url string) {
+	i.mu.Lock()
+	_, ok := i.pages[url]
+	delete(i.pages, url)
+	i.mu.Unlock()
+
+	if ok {
+		_ = i.Save()
+	}
+}
+
+// Snippet finds query in the text of the page at url, ignoring case, and
+// returns the match with some of the text around it
+func (i *BookmarkIndex) Snippet(url, query string) (string, bool) {
+	if query == "" {
+		return "", false
+	}
+
+	i.mu.RLock()
+	page, ok := i.pages[url]
+	i.mu.RUnlock()
+	if !ok {
+		return "", false
+	}
+
+	text := []rune(page.Text)
+	lower := []rune(strings.ToLower(page.Text))
+	needle := []rune(strings.ToLower(query))
+	if len(lower) != len(text) {
+		// Lowercasing changed the length; fall back to matching the text as is
+		lower = text
+	}
+	at := indexRunes(lower, needle)
+	if at < 0 {
+		return "", false
+	}
+
+	start := max(at-snippetContext, 0)
+	end := min(at+len(needle)+snippetContext, len(text))
+	snippet := strings.Join(strings.Fields(string(text[start:end])), " ")
+	if start > 0 {
+		snippet = "…" + snippet
+	}
+	if end < len(text) {
+		snippet += "…"
+	}
+	return snippet, true
+}
+
+// indexRunes returns the index of the first needle in haystack, or -1
+func indexRunes(haystack, needle []rune) int {
+	for i := 0; i+len(needle) <= len(haystack); i++ {
+		match := true
+		for j := range needle {
+			if haystack[i+j] != needle[j] {
+				match = false
+				break
+			}
+		}
+		if match {
+			return i
+		}
+	}
+	return -1
+}
+
+// Load loads the index from disk
+func (i *BookmarkIndex) Load() error {
+	data, err := os.ReadFile(i.storePath)
+	if err != nil {
+		return err
+	}
+
+	var pages []indexedPage
+	if err := json.Unmarshal(data, &pages); err != nil {
+		return err
+	}
+
+	i.mu.Lock()
+	i.pages = make(map[string]*indexedPage, len(pages))
+	for n := range pages {
+		i.pages[pages[n].URL] = &pages[n]
+	}
+	i.mu.Unlock()
+	return nil
+}
+
+// Save saves the index to disk
+func (i *BookmarkIndex) Save() error {
+	i.mu.RLock()
+	pages := make([]indexedPage, 0, len(i.pages))
+	for _, page := range i.pages {
+		pages = append(pages, *page)
+	}
+	i.mu.RUnlock()
+	sort.Slice(pages, func(a, b int) bool {
+		return pages[a].URL < pages[b].URL
+	})
+
+	data, err := json.MarshalIndent(pages, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	// Ensure directory exists
+	if err := os.MkdirAll(filepath.Dir(i.storePath), 0700); err != nil {
+		return err
+	}
+	return writeFile(i.storePath, data, 0600)
+}
@@ -2,6 +2,7 @@ package storage
 
 import (
 	"encoding/json"
+	"fmt"
 	"os"
 	"path/filepath"
 	"sort"
@@ -80,6 +81,38 @@ func (b *Bookmarks) Remove(url string) error {
 	return nil // URL not found, nothing to remove
 }
 
+// Update replaces the bookmark stored under oldURL with the given values
+func (b *Bookmarks) Update(oldURL, url, title string, tags []string) error {
+	b.mu.Lock()
+
+	idx := -1
+	for i, bm := range b.bookmarks {
+		if bm.URL == oldURL {
+			idx = i
+		} else if bm.URL == url {
+			b.mu.Unlock()
+			return fmt.Errorf("bookmark for %s already exists", url)
+		}
+	}
+
+	if idx < 0 {
+		b.mu.Unlock()
+		return fmt.Errorf("bookmark for %s not found", oldURL)
+	}
+
+	b.bookmarks[idx].URL = url
+	b.bookmarks[idx].Title = title
+	b.bookmarks[idx].Tags = tags
+
+	// Keep bookmarks sorted by title
+	sort.Slice(b.bookmarks, func(i, j int) bool {
+		return b.bookmarks[i].Title < b.bookmarks[j].Title
+	})
+
+	b.mu.Unlock()
+	return b.Save()
+}
+
 // Get gets a bookmark by URL
 func (b *Bookmarks) Get(url string) *types.Bookmark {
 	b.mu.RLock()
@@ -180,5 +213,5 @@ func (b *Bookmarks) Save() error {
 		return err
 	}
 
-	return os.WriteFile(b.storePath, data, 0600)
+	return writeFile(b.storePath, data, 0600)
 }
@@ -0,0 +1,42 @@
+package storage
+
+import (
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestBookmarksUpdate(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "bookmarks.json")
+	bookmarks := NewBookmarks(path)
+	if err := bookmarks.Add("gemini://a.example/", "Apple", nil); err != nil {
+		t.Fatal(err)
+	}
+	if err := bookmarks.Add("gemini://b.example/", "Banana", nil); err != nil {
+		t.Fatal(err)
+	}
+
+	// Editing changes the URL, title and tags in place and is saved
+	if err := bookmarks.Update("gemini://a.example/", "gemini://c.example/", "Zebra", []string{"moved"}); err != nil {
+		t.Fatalf("Update: %v", err)
+	}
+	all := NewBookmarks(path).GetAll()
+	if len(all) != 2 || all[1].URL != "gemini://c.example/" || all[1].Title != "Zebra" || len(all[1].Tags) != 1 {
+		t.Errorf("saved bookmarks = %+v, want the edited one last by title", all)
+	}
+
+	// A URL another bookmark has is refused
+	err := bookmarks.Update("gemini://c.example/", "gemini://b.example/", "Zebra", nil)
+	if err == nil || !strings.Contains(err.Error(), "already exists") {
+		t.Errorf("duplicate URL: %v", err)
+	}
+	if !bookmarks.HasBookmark("gemini://c.example/") {
+		t.Error("the refused edit changed the bookmark")
+	}
+
+	// as is one for a bookmark that is gone
+	err = bookmarks.Update("gemini://gone.example/", "gemini://d.example/", "Gone", nil)
+	if err == nil || !strings.Contains(err.Error(), "not found") {
+		t.Errorf("missing bookmark: %v", err)
+	}
+}
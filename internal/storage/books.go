@@ -0,0 +1,92 @@
+package storage
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+)
+
+// BookProgress is how far the reader got in a Gempub book
+type BookProgress struct {
+	Title   string    `json:"title"`
+	Chapter string    `json:"chapter"` // Path of the last chapter opened, within the book
+	Read    time.Time `json:"read"`
+}
+
+// Bookshelf remembers reading progress in Gempub books, keyed by the URL
+// each book was opened from
+type Bookshelf struct {
+	mu        sync.RWMutex
+	books     map[string]BookProgress
+	storePath string
+}
+
+// NewBookshelf creates a new bookshelf
+func NewBookshelf(storePath string) *Bookshelf {
+	s := &Bookshelf{
+		books:     make(map[string]BookProgress),
+		storePath: storePath,
+	}
+
+	// Try to load existing progress
+	_ = s.Load() // Ignore errors
+
+	return s
+}
+
+// SetChapter records chapter as the last one read in the book from url
+func (s *Bookshelf) SetChapter(url, title, chapter string) {
+	s.mu.Lock()
+	s.books[url] = BookProgress{Title: title, Chapter: chapter, Read: time.Now()}
+	s.mu.Unlock()
+
+	// Auto-save (release lock before saving to avoid deadlock)
+	_ = s.Save()
+}
+
+// Progress returns how far the book from url was read
+func (s *Bookshelf) Progress(url string) (BookProgress, bool) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	progress, ok := s.books[url]
+	return progress, ok
+}
+
+// Load loads reading progress from disk
+func (s *Bookshelf) Load() error {
+	data, err := os.ReadFile(s.storePath)
+	if err != nil {
+		return err
+	}
+
+	books := make(map[string]BookProgress)
+	if err := json.Unmarshal(data, &books); err != nil {
+		return err
+	}
+
+	s.mu.Lock()
+	s.books = books
+	s.mu.Unlock()
+
+	return nil
+}
+
+// Save saves reading progress to disk
+func (s *Bookshelf) Save() error {
+	s.mu.RLock()
+	data, err := json.MarshalIndent(s.books, "", "  ")
+	s.mu.RUnlock()
+	if err != nil {
+		return err
+	}
+
+	// Ensure directory exists
+	dir := filepath.Dir(s.storePath)
+	if err := os.MkdirAll(dir, 0700); err != nil {
+		return err
+	}
+
+	return writeFile(s.storePath, data, 0600)
+}
@@ -3,6 +3,7 @@ package storage
 import (
 	"os"
 	"path/filepath"
+	"strings"
 
 	"github.com/BurntSushi/toml"
 	"starsearch/internal/themes"
@@ -13,6 +14,7 @@ import (
 type Config struct {
 	config     *types.Config
 	configPath string
+	firstRun   bool // No config file existed before this run
 }
 
 // NewConfig creates a new configuration manager
@@ -32,20 +34,39 @@ func NewConfig(configPath string) *Config {
 func getDefaultConfig() *types.Config {
 	return &types.Config{
 		General: types.GeneralConfig{
-			HomeURL:         "gemini://gemini.circumlunar.space/",
-			SearchEngine:    "gemini://gus.guru/",
-			MaxHistory:      1000,
-			AutoSaveHistory: true,
-			RestoreSession:  true,
+			HomeURL:             "gemini://gemini.circumlunar.space/",
+			DefaultSearchEngine: "gus",
+			MaxHistory:          1000,
+			AutoSaveHistory:     true,
+			RestoreSession:      true,
+			SearchEngines: []types.SearchEngine{
+				{Name: "gus", URL: "gemini://gus.guru/search"},
+				{Name: "kennedy", URL: "gemini://kennedy.gemi.dev/search"},
+				{Name: "geminispace", URL: "gemini://geminispace.info/search"},
+				{Name: "tlgs", URL: "gemini://tlgs.one/search"},
+			},
 		},
 		UI: types.UIConfig{
 			ShowLineNumbers: false,
 			ShowLinkNumbers: true,
 			EnableMouse:     true,
 			ScrollSpeed:     3,
+			ShowBreadcrumbs: false,
+			RenderTables:    false,
+			SyntaxHighlight: true,
+			LinkStyle:       "inline",
+			QuoteFoldThreshold: 8,
+			ToastDurationMs: 2500,
+			Glyphs:          "auto",
+			H1Margin:        []int{1, 1},
+			H2Margin:        []int{1, 0},
+			H3Margin:        []int{0, 0},
+			MaxBlankLines:   -1,
+			ImageColors:     "auto",
+			ImageDither:     true,
 		},
 		Colors: types.ColorConfig{
-			Theme:             "default",
+			Theme:             themes.Auto,
 			LinkColor:         "12",  // Blue
 			VisitedLinkColor:  "13",  // Magenta
 			Heading1Color:     "11",  // Yellow
@@ -66,13 +87,31 @@ func getDefaultConfig() *types.Config {
 			EnableCache:        true,
 			CacheTTL:           3600,
 			CacheSizeMB:        50,
+			Revalidate:         true,
 			EnablePrefetch:     false,
 			PrefetchIdleDelay:  2,
 			ConnectionPoolSize: 2,
+			TabMemoryMB:        64,
+		},
+		Network: types.NetworkConfig{
+			RetryAttempts:      3,
+			RetryBackoffMs:     1000,
+			MaxParallelFetches: 4,
+			MaxRequests:        6,
+			MaxRequestsPerHost: 2,
+			GopherStrictness:   "lenient",
+			GopherMaxSizeMB:    64,
+			GopherIdleTimeout:  30,
 		},
 	}
 }
 
+// FirstRun reports whether there was no config file yet, as on the first
+// launch
+func (c *Config) FirstRun() bool {
+	return c.firstRun
+}
+
 // Get returns the current configuration
 func (c *Config) Get() *types.Config {
 	return c.config
@@ -89,7 +128,13 @@ func (c *Config) Load() error {
 	if err != nil {
 		if os.IsNotExist(err) {
 			// Config file doesn't exist, create it with defaults
-			return c.Save()
+			c.firstRun = true
+			if err := c.Save(); err != nil {
+				return err
+			}
+			// Pick the colors of the automatic theme
+			c.config = c.mergeWithDefaults(c.config)
+			return nil
 		}
 		return err
 	}
@@ -118,7 +163,7 @@ func (c *Config) Save() error {
 		return err
 	}
 
-	return os.WriteFile(c.configPath, data, 0600)
+	return writeFile(c.configPath, data, 0600)
 }
 
 // mergeWithDefaults merges loaded config with defaults
@@ -129,19 +174,74 @@ func (c *Config) mergeWithDefaults(loaded *types.Config) *types.Config {
 	if loaded.General.HomeURL != "" {
 		defaults.General.HomeURL = loaded.General.HomeURL
 	}
-	if loaded.General.SearchEngine != "" {
-		defaults.General.SearchEngine = loaded.General.SearchEngine
+	if len(loaded.General.SearchEngines) > 0 {
+		defaults.General.SearchEngines = loaded.General.SearchEngines
+	}
+	if loaded.General.DefaultSearchEngine != "" {
+		defaults.General.DefaultSearchEngine = loaded.General.DefaultSearchEngine
+	}
+	if loaded.General.SearchEngine != "" && len(loaded.General.SearchEngines) == 0 {
+		// Migrate the old single search_engine setting into a named engine
+		defaults.General.SearchEngines = append([]types.SearchEngine{
+			{Name: "custom", URL: loaded.General.SearchEngine},
+		}, defaults.General.SearchEngines...)
+		defaults.General.DefaultSearchEngine = "custom"
 	}
 	if loaded.General.MaxHistory > 0 {
 		defaults.General.MaxHistory = loaded.General.MaxHistory
 	}
 	defaults.General.AutoSaveHistory = loaded.General.AutoSaveHistory
 	defaults.General.RestoreSession = loaded.General.RestoreSession
+	defaults.General.TelnetCommand = loaded.General.TelnetCommand
+	defaults.General.RandomCapsuleURL = loaded.General.RandomCapsuleURL
+	defaults.General.AudioPlayer = loaded.General.AudioPlayer
+	defaults.General.IndexBookmarks = loaded.General.IndexBookmarks
+	defaults.General.Openers = loaded.General.Openers
 
 	// UI settings
 	defaults.UI.ShowLineNumbers = loaded.UI.ShowLineNumbers
 	defaults.UI.ShowLinkNumbers = loaded.UI.ShowLinkNumbers
 	defaults.UI.EnableMouse = loaded.UI.EnableMouse
+	defaults.UI.ShowBreadcrumbs = loaded.UI.ShowBreadcrumbs
+	defaults.UI.RenderTables = loaded.UI.RenderTables
+	defaults.UI.SyntaxHighlight = loaded.UI.SyntaxHighlight
+	defaults.UI.HideTabBar = loaded.UI.HideTabBar
+	defaults.UI.HideAddressBar = loaded.UI.HideAddressBar
+	defaults.UI.HideStatusBar = loaded.UI.HideStatusBar
+	defaults.UI.Zoom = loaded.UI.Zoom
+	if len(loaded.UI.H1Margin) == 2 {
+		defaults.UI.H1Margin = loaded.UI.H1Margin
+	}
+	if len(loaded.UI.H2Margin) == 2 {
+		defaults.UI.H2Margin = loaded.UI.H2Margin
+	}
+	if len(loaded.UI.H3Margin) == 2 {
+		defaults.UI.H3Margin = loaded.UI.H3Margin
+	}
+	if loaded.UI.MaxBlankLines != 0 {
+		defaults.UI.MaxBlankLines = loaded.UI.MaxBlankLines
+	}
+	defaults.UI.IndentUnderHeadings = loaded.UI.IndentUnderHeadings
+	defaults.UI.TerminalBidi = loaded.UI.TerminalBidi
+	defaults.UI.Spelling = loaded.UI.Spelling
+	defaults.UI.ImageDither = loaded.UI.ImageDither
+	defaults.UI.ImageGrayscale = loaded.UI.ImageGrayscale
+	defaults.UI.ShowControlChars = loaded.UI.ShowControlChars
+	if loaded.UI.ImageColors != "" {
+		defaults.UI.ImageColors = loaded.UI.ImageColors
+	}
+	if loaded.UI.LinkStyle != "" {
+		defaults.UI.LinkStyle = loaded.UI.LinkStyle
+	}
+	if loaded.UI.QuoteFoldThreshold != 0 {
+		defaults.UI.QuoteFoldThreshold = loaded.UI.QuoteFoldThreshold
+	}
+	if loaded.UI.ToastDurationMs != 0 {
+		defaults.UI.ToastDurationMs = loaded.UI.ToastDurationMs
+	}
+	if loaded.UI.Glyphs != "" {
+		defaults.UI.Glyphs = loaded.UI.Glyphs
+	}
 	if loaded.UI.ScrollSpeed > 0 {
 		defaults.UI.ScrollSpeed = loaded.UI.ScrollSpeed
 	}
@@ -152,32 +252,34 @@ func (c *Config) mergeWithDefaults(loaded *types.Config) *types.Config {
 		themes.ApplyTheme(&defaults.Colors, loaded.Colors.Theme)
 	}
 	
-	// Then apply any custom color overrides
-	if loaded.Colors.LinkColor != "" {
+	// Then apply any custom color overrides. Colors still at the default
+	// palette, as written to every new config file, leave the theme's.
+	palette := themes.GetTheme("default")
+	if loaded.Colors.LinkColor != "" && loaded.Colors.LinkColor != palette.LinkColor {
 		defaults.Colors.LinkColor = loaded.Colors.LinkColor
 	}
-	if loaded.Colors.VisitedLinkColor != "" {
+	if loaded.Colors.VisitedLinkColor != "" && loaded.Colors.VisitedLinkColor != palette.VisitedLinkColor {
 		defaults.Colors.VisitedLinkColor = loaded.Colors.VisitedLinkColor
 	}
-	if loaded.Colors.Heading1Color != "" {
+	if loaded.Colors.Heading1Color != "" && loaded.Colors.Heading1Color != palette.Heading1Color {
 		defaults.Colors.Heading1Color = loaded.Colors.Heading1Color
 	}
-	if loaded.Colors.Heading2Color != "" {
+	if loaded.Colors.Heading2Color != "" && loaded.Colors.Heading2Color != palette.Heading2Color {
 		defaults.Colors.Heading2Color = loaded.Colors.Heading2Color
 	}
-	if loaded.Colors.Heading3Color != "" {
+	if loaded.Colors.Heading3Color != "" && loaded.Colors.Heading3Color != palette.Heading3Color {
 		defaults.Colors.Heading3Color = loaded.Colors.Heading3Color
 	}
-	if loaded.Colors.TextColor != "" {
+	if loaded.Colors.TextColor != "" && loaded.Colors.TextColor != palette.TextColor {
 		defaults.Colors.TextColor = loaded.Colors.TextColor
 	}
-	if loaded.Colors.QuoteColor != "" {
+	if loaded.Colors.QuoteColor != "" && loaded.Colors.QuoteColor != palette.QuoteColor {
 		defaults.Colors.QuoteColor = loaded.Colors.QuoteColor
 	}
-	if loaded.Colors.PreformatColor != "" {
+	if loaded.Colors.PreformatColor != "" && loaded.Colors.PreformatColor != palette.PreformatColor {
 		defaults.Colors.PreformatColor = loaded.Colors.PreformatColor
 	}
-	if loaded.Colors.BackgroundColor != "" {
+	if loaded.Colors.BackgroundColor != "" && loaded.Colors.BackgroundColor != palette.BackgroundColor {
 		defaults.Colors.BackgroundColor = loaded.Colors.BackgroundColor
 	}
 
@@ -193,9 +295,68 @@ func (c *Config) mergeWithDefaults(loaded *types.Config) *types.Config {
 		defaults.Downloads.Timeout = loaded.Downloads.Timeout
 	}
 
+	// Performance settings
+	if loaded.Performance.TabMemoryMB != 0 {
+		defaults.Performance.TabMemoryMB = loaded.Performance.TabMemoryMB
+	}
+	defaults.Performance.CacheMemoryOnly = loaded.Performance.CacheMemoryOnly
+	defaults.Performance.Revalidate = loaded.Performance.Revalidate
+
+	// Network settings
+	if loaded.Network.RetryAttempts != 0 {
+		defaults.Network.RetryAttempts = loaded.Network.RetryAttempts
+	}
+	if loaded.Network.RetryBackoffMs > 0 {
+		defaults.Network.RetryBackoffMs = loaded.Network.RetryBackoffMs
+	}
+	if loaded.Network.MaxParallelFetches > 0 {
+		defaults.Network.MaxParallelFetches = loaded.Network.MaxParallelFetches
+	}
+	if loaded.Network.MaxRequests > 0 {
+		defaults.Network.MaxRequests = loaded.Network.MaxRequests
+	}
+	if loaded.Network.MaxRequestsPerHost > 0 {
+		defaults.Network.MaxRequestsPerHost = loaded.Network.MaxRequestsPerHost
+	}
+	if loaded.Network.GopherMaxSizeMB > 0 {
+		defaults.Network.GopherMaxSizeMB = loaded.Network.GopherMaxSizeMB
+	}
+	if loaded.Network.GopherIdleTimeout > 0 {
+		defaults.Network.GopherIdleTimeout = loaded.Network.GopherIdleTimeout
+	}
+	if loaded.Network.BackgroundKBps > 0 {
+		defaults.Network.BackgroundKBps = loaded.Network.BackgroundKBps
+	}
+	if loaded.Network.GopherStrictness != "" {
+		defaults.Network.GopherStrictness = loaded.Network.GopherStrictness
+	}
+	defaults.Network.GeminiStrict = loaded.Network.GeminiStrict
+	defaults.Network.SocksProxy = loaded.Network.SocksProxy
+	defaults.Network.TorIsolation = loaded.Network.TorIsolation
+
 	return defaults
 }
 
+// GetSearchEngine returns the search engine with the given name (case-insensitive),
+// or the default engine when name is empty
+func (c *Config) GetSearchEngine(name string) *types.SearchEngine {
+	if name == "" {
+		name = c.config.General.DefaultSearchEngine
+	}
+
+	for i, engine := range c.config.General.SearchEngines {
+		if strings.EqualFold(engine.Name, name) {
+			return &c.config.General.SearchEngines[i]
+		}
+	}
+
+	// Fall back to the first engine if the default is misconfigured
+	if name == c.config.General.DefaultSearchEngine && len(c.config.General.SearchEngines) > 0 {
+		return &c.config.General.SearchEngines[0]
+	}
+	return nil
+}
+
 // GetDownloadDirectory returns the expanded download directory path
 func (c *Config) GetDownloadDirectory() string {
 	dir := c.config.Downloads.Directory
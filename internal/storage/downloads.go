@@ -7,6 +7,7 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
+	"sort"
 	"sync"
 	"time"
 
@@ -92,7 +93,7 @@ func (d *Downloads) Get(id string) *types.Download {
 	return nil
 }
 
-// GetAll returns all downloads
+// GetAll returns all downloads, newest first
 func (d *Downloads) GetAll() []types.Download {
 	d.mutex.RLock()
 	defer d.mutex.RUnlock()
@@ -101,6 +102,12 @@ func (d *Downloads) GetAll() []types.Download {
 	for _, download := range d.downloads {
 		downloads = append(downloads, *download)
 	}
+	sort.Slice(downloads, func(i, j int) bool {
+		if downloads[i].StartTime != downloads[j].StartTime {
+			return downloads[i].StartTime > downloads[j].StartTime
+		}
+		return downloads[i].ID < downloads[j].ID
+	})
 	return downloads
 }
 
@@ -118,18 +125,20 @@ func (d *Downloads) GetActive() []types.Download {
 	return active
 }
 
-// UpdateProgress updates download progress
+// UpdateProgress updates download progress. Downloads of unknown size
+// stay in progress until their status is set.
 func (d *Downloads) UpdateProgress(id string, downloaded int64) {
 	d.mutex.Lock()
 	defer d.mutex.Unlock()
 
 	if download, ok := d.downloads[id]; ok {
 		download.Downloaded = downloaded
-		if download.Downloaded >= download.Size {
+		// Progress is only saved along with a change of status
+		if download.Size > 0 && download.Downloaded >= download.Size {
 			download.Status = types.DownloadCompleted
 			download.FinishTime = time.Now().Unix()
+			_ = d.Save()
 		}
-		_ = d.Save()
 	}
 }
 
@@ -150,6 +159,22 @@ func (d *Downloads) SetStatus(id string, status types.DownloadStatus, errorMsg s
 	}
 }
 
+// Complete marks a download finished, saved as filename with the size
+// written
+func (d *Downloads) Complete(id, filename string, size int64) {
+	d.mutex.Lock()
+	defer d.mutex.Unlock()
+
+	if download, ok := d.downloads[id]; ok {
+		download.Filename = filename
+		download.Size = size
+		download.Downloaded = size
+		download.Status = types.DownloadCompleted
+		download.FinishTime = time.Now().Unix()
+		_ = d.Save()
+	}
+}
+
 // Remove removes a download
 func (d *Downloads) Remove(id string) {
 	d.mutex.Lock()
@@ -208,7 +233,7 @@ func (d *Downloads) Save() error {
 		return err
 	}
 
-	return os.WriteFile(d.storePath, data, 0600)
+	return writeFile(d.storePath, data, 0600)
 }
 
 // generateID generates a unique download ID
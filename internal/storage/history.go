@@ -136,6 +136,58 @@ func (h *History) GetAll() []types.HistoryEntry {
 	return entries
 }
 
+// Remove deletes every visit to the given URLs, returning how many were
+// deleted. The current position stays on the same entry where it survives.
+func (h *History) Remove(urls ...string) int {
+	remove := make(map[string]bool, len(urls))
+	for _, url := range urls {
+		remove[url] = true
+	}
+
+	h.mu.Lock()
+	kept := h.entries[:0]
+	current := h.currentIndex
+	for i, entry := range h.entries {
+		if remove[entry.URL] {
+			if i <= h.currentIndex {
+				current--
+			}
+			continue
+		}
+		kept = append(kept, entry)
+	}
+	removed := len(h.entries) - len(kept)
+	h.entries = kept
+	h.currentIndex = current
+	if h.currentIndex < 0 && len(h.entries) > 0 {
+		h.currentIndex = 0
+	}
+	h.mu.Unlock()
+
+	if removed > 0 {
+		_ = h.Save()
+	}
+	return removed
+}
+
+// SetTitle renames every visit to url, returning how many were renamed
+func (h *History) SetTitle(url, title string) int {
+	h.mu.Lock()
+	renamed := 0
+	for i := range h.entries {
+		if h.entries[i].URL == url {
+			h.entries[i].Title = title
+			renamed++
+		}
+	}
+	h.mu.Unlock()
+
+	if renamed > 0 {
+		_ = h.Save()
+	}
+	return renamed
+}
+
 // Clear clears all history
 func (h *History) Clear() error {
 	h.mu.Lock()
@@ -188,5 +240,5 @@ func (h *History) Save() error {
 		return err
 	}
 
-	return os.WriteFile(h.storePath, data, 0600)
+	return writeFile(h.storePath, data, 0600)
 }
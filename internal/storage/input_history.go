@@ -0,0 +1,144 @@
+package storage
+
+import (
+	"encoding/json"
+	"net/url"
+	"os"
+	"path/filepath"
+	"sync"
+)
+
+// maxInputsPerURL caps how many answers are remembered for one prompt
+const maxInputsPerURL = 20
+
+// InputHistory remembers previous answers to input prompts, keyed by the
+// URL that requested the input
+type InputHistory struct {
+	mu        sync.RWMutex
+	entries   map[string][]string // Most recent answer first
+	storePath string
+}
+
+// NewInputHistory creates a new input history store
+func NewInputHistory(storePath string) *InputHistory {
+	h := &InputHistory{
+		entries:   make(map[string][]string),
+		storePath: storePath,
+	}
+
+	// Try to load existing input history
+	_ = h.Load() // Ignore errors
+
+	return h
+}
+
+// Add records an answer for the prompt at url, moving it to the front if
+// it was already known
+func (h *InputHistory) Add(url, input string) {
+	if url == "" || input == "" {
+		return
+	}
+
+	h.mu.Lock()
+	inputs := []string{input}
+	for _, existing := range h.entries[url] {
+		if existing != input {
+			inputs = append(inputs, existing)
+		}
+	}
+	if len(inputs) > maxInputsPerURL {
+		inputs = inputs[:maxInputsPerURL]
+	}
+	h.entries[url] = inputs
+	h.mu.Unlock()
+
+	// Auto-save (release lock before saving to avoid deadlock)
+	_ = h.Save()
+}
+
+// Get returns previous answers for the prompt at url, most recent first
+func (h *InputHistory) Get(url string) []string {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+
+	inputs := make([]string, len(h.entries[url]))
+	copy(inputs, h.entries[url])
+	return inputs
+}
+
+// Hosts counts the answers remembered for each host's prompts
+func (h *InputHistory) Hosts() map[string]int {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+
+	counts := make(map[string]int)
+	for prompt, inputs := range h.entries {
+		counts[promptHost(prompt)] += len(inputs)
+	}
+	return counts
+}
+
+// ForgetHost removes the answers to every prompt on host, returning how
+// many were forgotten
+func (h *InputHistory) ForgetHost(host string) int {
+	h.mu.Lock()
+	forgotten := 0
+	for prompt, inputs := range h.entries {
+		if promptHost(prompt) == host {
+			forgotten += len(inputs)
+			delete(h.entries, prompt)
+		}
+	}
+	h.mu.Unlock()
+
+	if forgotten > 0 {
+		_ = h.Save()
+	}
+	return forgotten
+}
+
+// promptHost returns the host of a prompt's URL
+func promptHost(prompt string) string {
+	u, err := url.Parse(prompt)
+	if err != nil {
+		return ""
+	}
+	return u.Hostname()
+}
+
+// Load loads input history from disk
+func (h *InputHistory) Load() error {
+	data, err := os.ReadFile(h.storePath)
+	if err != nil {
+		return err
+	}
+
+	entries := make(map[string][]string)
+	if err := json.Unmarshal(data, &entries); err != nil {
+		return err
+	}
+
+	h.mu.Lock()
+	h.entries = entries
+	h.mu.Unlock()
+
+	return nil
+}
+
+// Save saves input history to disk
+func (h *InputHistory) Save() error {
+	h.mu.RLock()
+	data, err := json.MarshalIndent(h.entries, "", "  ")
+	h.mu.RUnlock()
+	if err != nil {
+		return err
+	}
+
+	// Ensure directory exists
+	dir := filepath.Dir(h.storePath)
+	if err := os.MkdirAll(dir, 0700); err != nil {
+		return err
+	}
+
+	return writeFile(h.storePath, data, 0600)
+}
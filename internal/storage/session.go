@@ -49,7 +49,7 @@ func (s *SessionManager) Save(tabs []types.Tab, activeIndex int) error {
 		return err
 	}
 
-	return os.WriteFile(s.sessionPath, data, 0600)
+	return writeFile(s.sessionPath, data, 0600)
 }
 
 // Load loads a saved session
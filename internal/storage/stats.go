@@ -0,0 +1,411 @@
+package storage
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"starsearch/internal/types"
+)
+
+// topHostCount is how many hosts the stats page ranks
+const topHostCount = 10
+
+// Per-host response times are kept for the last hostWindow requests. A
+// host is slow once minHostSamples of them took slowHostLatency on average
+// or failed at slowHostFailures or more.
+const (
+	hostWindow       = 20
+	minHostSamples   = 3
+	slowHostLatency  = 2 * time.Second
+	slowHostFailures = 0.5
+)
+
+// StatsCounters are the running totals kept by Stats
+type StatsCounters struct {
+	Since          int64            `json:"since"`           // When counting started, Unix seconds
+	Fetches        map[string]int   `json:"fetches"`         // Pages fetched over the network, by protocol
+	Bytes          map[string]int64 `json:"bytes"`           // Bytes fetched over the network, by protocol
+	CacheHits      int              `json:"cache_hits"`      // Pages served from the page cache instead
+	CacheEvictions int              `json:"cache_evictions"` // Cached pages evicted to make room for others
+
+	Latency map[string][]HostSample `json:"latency,omitempty"` // Recent requests by host, oldest first
+}
+
+// HostSample is one request to a host: how long it took and whether it
+// failed
+type HostSample struct {
+	Millis int64 `json:"ms"`
+	Failed bool  `json:"failed,omitempty"`
+}
+
+// HostMetrics sums up the recent requests to a host
+type HostMetrics struct {
+	Host        string
+	Requests    int
+	Latency     time.Duration // Average time taken by the requests that succeeded
+	FailureRate float64       // Share of the requests that failed, 0 to 1
+	Slow        bool
+}
+
+// Stats counts fetched pages and bytes across sessions for the about:stats
+// page. Visits per day and per host come from the history instead.
+type Stats struct {
+	mu        sync.Mutex
+	counters  StatsCounters
+	storePath string
+}
+
+// NewStats creates a new stats store
+func NewStats(storePath string) *Stats {
+	s := &Stats{storePath: storePath}
+
+	// Try to load existing counters
+	_ = s.Load() // Ignore errors
+
+	if s.counters.Since == 0 {
+		s.counters.Since = time.Now().Unix()
+	}
+	if s.counters.Fetches == nil {
+		s.counters.Fetches = make(map[string]int)
+	}
+	if s.counters.Bytes == nil {
+		s.counters.Bytes = make(map[string]int64)
+	}
+	if s.counters.Latency == nil {
+		s.counters.Latency = make(map[string][]HostSample)
+	}
+	return s
+}
+
+// RecordFetch counts a loaded page of size bytes
+func (s *Stats) RecordFetch(protocol string, size int, fromCache bool) {
+	s.mu.Lock()
+	if fromCache {
+		s.counters.CacheHits++
+	} else {
+		s.counters.Fetches[protocol]++
+		s.counters.Bytes[protocol] += int64(size)
+	}
+	s.mu.Unlock()
+
+	// Auto-save (release lock before saving to avoid deadlock)
+	_ = s.Save()
+}
+
+// RecordEvictions counts pages evicted from the full page cache
+func (s *Stats) RecordEvictions(count int) {
+	s.mu.Lock()
+	s.counters.CacheEvictions += count
+	s.mu.Unlock()
+
+	_ = s.Save()
+}
+
+// RecordLatency records how long a request to host took and whether it
+// failed, keeping the last hostWindow requests
+func (s *Stats) RecordLatency(host string, took time.Duration, failed bool) {
+	if host == "" {
+		return
+	}
+	s.mu.Lock()
+	samples := append(s.counters.Latency[host], HostSample{Millis: took.Milliseconds(), Failed: failed})
+	if len(samples) > hostWindow {
+		samples = samples[len(samples)-hostWindow:]
+	}
+	s.counters.Latency[host] = samples
+	s.mu.Unlock()
+
+	_ = s.Save()
+}
+
+// IsSlow reports whether recent requests to host were slow or often failed
+func (s *Stats) IsSlow(host string) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return hostMetrics(host, s.counters.Latency[host]).Slow
+}
+
+// HostMetrics sums up the recent requests to each host, slowest first
+func (s *Stats) HostMetrics() []HostMetrics {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return rankHosts(s.counters.Latency)
+}
+
+// hostMetrics sums up samples of requests to host
+func hostMetrics(host string, samples []HostSample) HostMetrics {
+	metrics := HostMetrics{Host: host, Requests: len(samples)}
+	if len(samples) == 0 {
+		return metrics
+	}
+	var total int64
+	failed := 0
+	for _, sample := range samples {
+		if sample.Failed {
+			failed++
+		} else {
+			total += sample.Millis
+		}
+	}
+	if succeeded := len(samples) - failed; succeeded > 0 {
+		metrics.Latency = time.Duration(total/int64(succeeded)) * time.Millisecond
+	}
+	metrics.FailureRate = float64(failed) / float64(len(samples))
+	metrics.Slow = len(samples) >= minHostSamples &&
+		(metrics.Latency >= slowHostLatency || metrics.FailureRate >= slowHostFailures)
+	return metrics
+}
+
+// rankHosts sums up the requests to each host: slow hosts first, then by
+// average time taken and failure rate
+func rankHosts(latency map[string][]HostSample) []HostMetrics {
+	ranked := make([]HostMetrics, 0, len(latency))
+	for host, samples := range latency {
+		ranked = append(ranked, hostMetrics(host, samples))
+	}
+	sort.Slice(ranked, func(i, j int) bool {
+		a, b := ranked[i], ranked[j]
+		switch {
+		case a.Slow != b.Slow:
+			return a.Slow
+		case a.Latency != b.Latency:
+			return a.Latency > b.Latency
+		case a.FailureRate != b.FailureRate:
+			return a.FailureRate > b.FailureRate
+		}
+		return a.Host < b.Host
+	})
+	return ranked
+}
+
+// Counters returns a copy of the current totals
+func (s *Stats) Counters() StatsCounters {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	counters := s.counters
+	counters.Fetches = make(map[string]int, len(s.counters.Fetches))
+	for k, v := range s.counters.Fetches {
+		counters.Fetches[k] = v
+	}
+	counters.Bytes = make(map[string]int64, len(s.counters.Bytes))
+	for k, v := range s.counters.Bytes {
+		counters.Bytes[k] = v
+	}
+	counters.Latency = make(map[string][]HostSample, len(s.counters.Latency))
+	for k, v := range s.counters.Latency {
+		counters.Latency[k] = append([]HostSample(nil), v...)
+	}
+	return counters
+}
+
+// Load loads the counters from disk
+func (s *Stats) Load() error {
+	data, err := os.ReadFile(s.storePath)
+	if err != nil {
+		return err
+	}
+
+	var counters StatsCounters
+	if err := json.Unmarshal(data, &counters); err != nil {
+		return err
+	}
+
+	if counters.Latency == nil {
+		counters.Latency = make(map[string][]HostSample)
+	}
+
+	s.mu.Lock()
+	s.counters = counters
+	s.mu.Unlock()
+	return nil
+}
+
+// Save saves the counters to disk
+func (s *Stats) Save() error {
+	s.mu.Lock()
+	data, err := json.MarshalIndent(s.counters, "", "  ")
+	s.mu.Unlock()
+	if err != nil {
+		return err
+	}
+
+	// Ensure directory exists
+	if err := os.MkdirAll(filepath.Dir(s.storePath), 0700); err != nil {
+		return err
+	}
+	return writeFile(s.storePath, data, 0600)
+}
+
+// StatsPage renders usage statistics as gemtext: visits per day and week
+// and top hosts from the history, protocol breakdown, cache hit rate,
+// cache evictions, bytes fetched and host response times from the counters
+func StatsPage(history []types.HistoryEntry, counters StatsCounters, now time.Time) []byte {
+	var b strings.Builder
+	b.WriteString("# Reading statistics\n\n")
+
+	// Internal pages are not reading
+	var visits []types.HistoryEntry
+	for _, entry := range history {
+		if !strings.HasPrefix(entry.URL, "about:") {
+			visits = append(visits, entry)
+		}
+	}
+
+	b.WriteString("## Pages visited\n\n")
+	if len(visits) == 0 {
+		b.WriteString("No pages in the history yet.\n\n")
+	} else {
+		today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, now.Location())
+		var days [7]int
+		var weeks [4]int
+		for _, entry := range visits {
+			visited := time.Unix(entry.Timestamp, 0).In(now.Location())
+			daysAgo := int(today.Sub(time.Date(visited.Year(), visited.Month(), visited.Day(), 0, 0, 0, 0, now.Location())).Hours() / 24)
+			if daysAgo >= 0 && daysAgo < len(days) {
+				days[daysAgo]++
+			}
+			if daysAgo >= 0 && daysAgo/7 < len(weeks) {
+				weeks[daysAgo/7]++
+			}
+		}
+
+		b.WriteString("```Pages visited per day and per week\n")
+		for i, count := range days {
+			day := today.AddDate(0, 0, -i)
+			fmt.Fprintf(&b, "%-10s %4d %s\n", day.Format("Mon Jan 2"), count, bar(count, days[:]))
+		}
+		b.WriteString("\n")
+		for i, count := range weeks {
+			label := "This week"
+			if i > 0 {
+				label = fmt.Sprintf("%d weeks ago", i)
+				if i == 1 {
+					label = "Last week"
+				}
+			}
+			fmt.Fprintf(&b, "%-11s %4d %s\n", label, count, bar(count, weeks[:]))
+		}
+		b.WriteString("```\n\n")
+		fmt.Fprintf(&b, "%d visits in the history since %s.\n\n", len(visits), time.Unix(visits[0].Timestamp, 0).Format("2 January 2006"))
+	}
+
+	b.WriteString("## Top hosts\n\n")
+	hosts := make(map[string]int)
+	for _, entry := range visits {
+		if u, err := url.Parse(entry.URL); err == nil && u.Host != "" {
+			hosts[u.Scheme+"://"+u.Host]++
+		}
+	}
+	if len(hosts) == 0 {
+		b.WriteString("None yet.\n\n")
+	} else {
+		ranked := make([]string, 0, len(hosts))
+		for host := range hosts {
+			ranked = append(ranked, host)
+		}
+		sort.Slice(ranked, func(i, j int) bool {
+			if hosts[ranked[i]] != hosts[ranked[j]] {
+				return hosts[ranked[i]] > hosts[ranked[j]]
+			}
+			return ranked[i] < ranked[j]
+		})
+		for _, host := range ranked[:min(len(ranked), topHostCount)] {
+			fmt.Fprintf(&b, "=> %s/ %s (%s)\n", host, strings.SplitN(host, "://", 2)[1], visitCount(hosts[host]))
+		}
+		b.WriteString("\n")
+	}
+
+	b.WriteString("## Protocols\n\n")
+	protocols := make(map[string]int)
+	for _, entry := range visits {
+		if u, err := url.Parse(entry.URL); err == nil {
+			protocols[u.Scheme]++
+		}
+	}
+	for _, protocol := range []string{"gemini", "gopher"} {
+		fmt.Fprintf(&b, "* %s: %s, %d fetched (%s)\n", protocol, visitCount(protocols[protocol]), counters.Fetches[protocol], formatBytes(counters.Bytes[protocol]))
+	}
+	b.WriteString("\n")
+
+	b.WriteString("## Network\n\n")
+	fetches := 0
+	var total int64
+	for protocol, n := range counters.Fetches {
+		fetches += n
+		total += counters.Bytes[protocol]
+	}
+	fmt.Fprintf(&b, "* Bytes fetched: %s\n", formatBytes(total))
+	if loads := fetches + counters.CacheHits; loads > 0 {
+		fmt.Fprintf(&b, "* Cache hit rate: %.0f%% (%d of %d pages)\n", 100*float64(counters.CacheHits)/float64(loads), counters.CacheHits, loads)
+	} else {
+		b.WriteString("* Cache hit rate: no pages loaded yet\n")
+	}
+	fmt.Fprintf(&b, "* Cache evictions: %d\n", counters.CacheEvictions)
+	fmt.Fprintf(&b, "* Counting since %s\n", time.Unix(counters.Since, 0).Format("2 January 2006"))
+
+	b.WriteString("\n## Response times\n\n")
+	ranked := rankHosts(counters.Latency)
+	if len(ranked) == 0 {
+		b.WriteString("No requests timed yet.\n")
+	} else {
+		fmt.Fprintf(&b, "Over the last %d requests to each host, slowest first.\n\n", hostWindow)
+		for _, host := range ranked {
+			latency := "-"
+			if host.Latency > 0 {
+				latency = fmt.Sprintf("%.1fs", host.Latency.Seconds())
+			}
+			line := fmt.Sprintf("* %s: %s on average, %.0f%% failed (%d requests)", host.Host, latency, 100*host.FailureRate, host.Requests)
+			if host.Slow {
+				line += " - slow"
+			}
+			b.WriteString(line + "\n")
+		}
+	}
+
+	return []byte(b.String())
+}
+
+// visitCount formats a number of visits, e.g. "1 visit" or "3 visits"
+func visitCount(n int) string {
+	if n == 1 {
+		return "1 visit"
+	}
+	return fmt.Sprintf("%d visits", n)
+}
+
+// bar draws count as a bar scaled against the largest of counts
+func bar(count int, counts []int) string {
+	const width = 30
+	largest := 0
+	for _, c := range counts {
+		largest = max(largest, c)
+	}
+	if largest == 0 {
+		return ""
+	}
+	return strings.Repeat("█", (count*width+largest-1)/largest)
+}
+
+// formatBytes formats a byte count with a binary unit, e.g. "1.5 MB"
+func formatBytes(bytes int64) string {
+	const unit = 1024
+	if bytes < unit {
+		return fmt.Sprintf("%d B", bytes)
+	}
+	div, exp := int64(unit), 0
+	for n := bytes / unit; n >= unit; n /= unit {
+		div *= unit
+		exp++
+	}
+	return fmt.Sprintf("%.1f %cB", float64(bytes)/float64(div), "KMGTPE"[exp])
+}
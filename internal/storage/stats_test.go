@@ -0,0 +1,104 @@
+package storage
+
+import (
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"starsearch/internal/types"
+)
+
+func TestStatsPersist(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "stats.json")
+	stats := NewStats(path)
+	stats.RecordFetch("gemini", 1000, false)
+	stats.RecordFetch("gemini", 500, false)
+	stats.RecordFetch("gopher", 200, false)
+	stats.RecordFetch("gemini", 1000, true)
+
+	counters := NewStats(path).Counters()
+	if counters.Fetches["gemini"] != 2 || counters.Bytes["gemini"] != 1500 || counters.Fetches["gopher"] != 1 || counters.CacheHits != 1 {
+		t.Errorf("reloaded counters %+v", counters)
+	}
+}
+
+func TestStatsPage(t *testing.T) {
+	now := time.Date(2026, 3, 12, 15, 0, 0, 0, time.UTC)
+	visit := func(url string, daysAgo int) types.HistoryEntry {
+		return types.HistoryEntry{URL: url, Timestamp: now.AddDate(0, 0, -daysAgo).Unix()}
+	}
+	history := []types.HistoryEntry{
+		visit("gemini://a.example/old", 20),
+		visit("gemini://a.example/", 1),
+		visit("gopher://b.example/1/", 0),
+		visit("gemini://a.example/page", 0),
+		visit("about:stats", 0),
+	}
+	counters := StatsCounters{
+		Since:          now.AddDate(0, -1, 0).Unix(),
+		Fetches:        map[string]int{"gemini": 3, "gopher": 1},
+		Bytes:          map[string]int64{"gemini": 3 * 1024, "gopher": 512},
+		CacheHits:      1,
+		CacheEvictions: 4,
+	}
+
+	page := string(StatsPage(history, counters, now))
+	for _, want := range []string{
+		"Thu Mar 12    2 ",
+		"Wed Mar 11    1 ",
+		"This week      3 ",
+		"2 weeks ago    1 ",
+		"=> gemini://a.example/ a.example (3 visits)",
+		"* gemini: 3 visits, 3 fetched (3.0 KB)",
+		"* gopher: 1 visit, 1 fetched (512 B)",
+		"* Bytes fetched: 3.5 KB",
+		"* Cache hit rate: 20% (1 of 5 pages)",
+		"* Cache evictions: 4",
+	} {
+		if !strings.Contains(page, want) {
+			t.Errorf("page lacks %q:\n%s", want, page)
+		}
+	}
+	if strings.Contains(page, "about:stats") {
+		t.Error("internal pages should not be counted as visits")
+	}
+}
+
+func TestHostMetrics(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "stats.json")
+	stats := NewStats(path)
+	for i := 0; i < 25; i++ {
+		stats.RecordLatency("slow.example", 3*time.Second, false)
+		stats.RecordLatency("fast.example", 200*time.Millisecond, i%10 == 0)
+	}
+	stats.RecordLatency("flaky.example", time.Second, true)
+	stats.RecordLatency("flaky.example", time.Second, true)
+	stats.RecordLatency("flaky.example", 100*time.Millisecond, false)
+	stats.RecordLatency("new.example", 5*time.Second, false)
+
+	reloaded := NewStats(path)
+	if !reloaded.IsSlow("slow.example") || !reloaded.IsSlow("flaky.example") {
+		t.Error("slow and failing hosts should be slow")
+	}
+	if reloaded.IsSlow("fast.example") || reloaded.IsSlow("new.example") {
+		t.Error("fast hosts and hosts with too few requests should not be slow")
+	}
+
+	ranked := reloaded.HostMetrics()
+	var hosts []string
+	for _, host := range ranked {
+		hosts = append(hosts, host.Host)
+	}
+	if got := strings.Join(hosts, " "); got != "slow.example flaky.example new.example fast.example" {
+		t.Errorf("hosts ranked %q, want slow hosts first", got)
+	}
+	if fast := ranked[3]; fast.Requests != 20 || fast.Latency != 200*time.Millisecond || fast.FailureRate != 0.1 {
+		t.Errorf("fast.example = %+v, want the last 20 requests", fast)
+	}
+
+	page := string(StatsPage(nil, reloaded.Counters(), time.Now()))
+	if !strings.Contains(page, "* slow.example: 3.0s on average, 0% failed (20 requests) - slow") {
+		t.Errorf("page lacks the slow host:\n%s", page)
+	}
+}
@@ -0,0 +1,157 @@
+package storage
+
+import (
+	"encoding/json"
+	"net/url"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+	"time"
+
+	"starsearch/internal/types"
+)
+
+// CapsuleTokens remembers session tokens that capsules pass in query
+// strings, for the hosts the user allowed to keep one. A token is captured
+// from a redirect to a URL with a query and appended again to requests to
+// the same host that carry no query of their own.
+type CapsuleTokens struct {
+	mu        sync.RWMutex
+	hosts     map[string]*types.CapsuleToken
+	storePath string
+}
+
+// NewCapsuleTokens creates a new capsule token store
+func NewCapsuleTokens(storePath string) *CapsuleTokens {
+	t := &CapsuleTokens{
+		hosts:     make(map[string]*types.CapsuleToken),
+		storePath: storePath,
+	}
+
+	// Try to load existing tokens
+	_ = t.Load() // Ignore errors
+
+	return t
+}
+
+// Allow lets host keep a token
+func (t *CapsuleTokens) Allow(host string) {
+	if host == "" {
+		return
+	}
+
+	t.mu.Lock()
+	if _, ok := t.hosts[host]; !ok {
+		t.hosts[host] = &types.CapsuleToken{Host: host}
+	}
+	t.mu.Unlock()
+
+	_ = t.Save()
+}
+
+// Revoke forgets host's token and the permission to keep one
+func (t *CapsuleTokens) Revoke(host string) {
+	t.mu.Lock()
+	delete(t.hosts, host)
+	t.mu.Unlock()
+
+	_ = t.Save()
+}
+
+// Allowed reports whether host may keep a token
+func (t *CapsuleTokens) Allowed(host string) bool {
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+	_, ok := t.hosts[host]
+	return ok
+}
+
+// Capture remembers the query of a URL a capsule redirected to as its
+// host's token, if the host is allowed to keep one
+func (t *CapsuleTokens) Capture(rawURL string) {
+	u, err := url.Parse(rawURL)
+	if err != nil || u.RawQuery == "" {
+		return
+	}
+
+	t.mu.Lock()
+	token, ok := t.hosts[u.Hostname()]
+	if !ok || token.Token == u.RawQuery {
+		t.mu.Unlock()
+		return
+	}
+	token.Token = u.RawQuery
+	token.Stored = time.Now().Unix()
+	t.mu.Unlock()
+
+	_ = t.Save()
+}
+
+// Apply appends the stored token for rawURL's host, unless the URL already
+// has a query
+func (t *CapsuleTokens) Apply(rawURL string) string {
+	u, err := url.Parse(rawURL)
+	if err != nil || u.RawQuery != "" || u.ForceQuery {
+		return rawURL
+	}
+
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+	token, ok := t.hosts[u.Hostname()]
+	if !ok || token.Token == "" {
+		return rawURL
+	}
+	u.RawQuery = token.Token
+	return u.String()
+}
+
+// List returns the allowed hosts and their tokens, sorted by host
+func (t *CapsuleTokens) List() []types.CapsuleToken {
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+
+	tokens := make([]types.CapsuleToken, 0, len(t.hosts))
+	for _, token := range t.hosts {
+		tokens = append(tokens, *token)
+	}
+	sort.Slice(tokens, func(i, j int) bool {
+		return tokens[i].Host < tokens[j].Host
+	})
+	return tokens
+}
+
+// Load loads the tokens from disk
+func (t *CapsuleTokens) Load() error {
+	data, err := os.ReadFile(t.storePath)
+	if err != nil {
+		return err
+	}
+
+	var tokens []types.CapsuleToken
+	if err := json.Unmarshal(data, &tokens); err != nil {
+		return err
+	}
+
+	t.mu.Lock()
+	t.hosts = make(map[string]*types.CapsuleToken, len(tokens))
+	for i := range tokens {
+		t.hosts[tokens[i].Host] = &tokens[i]
+	}
+	t.mu.Unlock()
+	return nil
+}
+
+// Save saves the tokens to disk
+func (t *CapsuleTokens) Save() error {
+	data, err := json.MarshalIndent(t.List(), "", "  ")
+	if err != nil {
+		return err
+	}
+
+	// Ensure directory exists
+	if err := os.MkdirAll(filepath.Dir(t.storePath), 0700); err != nil {
+		return err
+	}
+	return writeFile(t.storePath, data, 0600)
+}
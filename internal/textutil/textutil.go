@@ -0,0 +1,102 @@
+// Package textutil makes text from capsules and servers safe to draw in
+// the terminal
+package textutil
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+	"unicode"
+)
+
+// Line ending conventions, as LineEndings names them
+const (
+	LF    = "LF"
+	CRLF  = "CRLF"
+	CR    = "CR"
+	Mixed = "mixed"
+)
+
+// isControl reports whether r would act on the terminal rather than be
+// drawn: C0 and C1 control characters and DEL, other than tab
+func isControl(r rune) bool {
+	return r != '\t' && unicode.IsControl(r)
+}
+
+// HasControl reports whether s holds control characters Sanitize removes
+func HasControl(s string) bool {
+	return strings.IndexFunc(s, isControl) >= 0
+}
+
+// Sanitize makes a line of untrusted text safe to draw: control
+// characters, which could ring the bell, move the cursor or start an
+// escape sequence, are removed along with trailing whitespace
+func Sanitize(line string) string {
+	if HasControl(line) {
+		line = strings.Map(func(r rune) rune {
+			if isControl(r) {
+				return -1
+			}
+			return r
+		}, line)
+	}
+	return strings.TrimRight(line, " \t")
+}
+
+// SanitizeText removes control characters from text of several lines,
+// such as a page's source, keeping its line breaks, LF or CRLF, and its
+// whitespace as it is. With visible set, control characters are shown as
+// escapes instead: ^G for BEL, ^H for backspace, ^M for a CR outside a
+// line break, ^[ for ESC, ^? for DEL and <U+009B> for the C1 controls.
+func SanitizeText(text string, visible bool) string {
+	var b strings.Builder
+	for i, r := range text {
+		switch {
+		case r == '\n' || !isControl(r):
+			b.WriteRune(r)
+		case r == '\r' && strings.HasPrefix(text[i+1:], "\n"):
+			b.WriteRune(r)
+		case !visible:
+		case r < 0x20:
+			b.WriteString("^" + string(rune(r+'@')))
+		case r == 0x7F:
+			b.WriteString("^?")
+		default:
+			fmt.Fprintf(&b, "<U+%04X>", r)
+		}
+	}
+	return b.String()
+}
+
+// LineEndings names the line ending convention of text: LF, CRLF, CR, or
+// Mixed if it uses more than one. Text without line breaks has none, "".
+func LineEndings(text []byte) string {
+	crlf := bytes.Count(text, []byte("\r\n"))
+	lf := bytes.Count(text, []byte("\n")) - crlf
+	cr := bytes.Count(text, []byte("\r")) - crlf
+
+	convention := ""
+	for _, ending := range []struct {
+		name  string
+		count int
+	}{{LF, lf}, {CRLF, crlf}, {CR, cr}} {
+		switch {
+		case ending.count == 0:
+		case convention != "":
+			return Mixed
+		default:
+			convention = ending.name
+		}
+	}
+	return convention
+}
+
+// NormalizeLineEndings turns CRLF and bare CR line endings into LF, so
+// no CR is left to be drawn. Text without CRs is returned as it is.
+func NormalizeLineEndings(text []byte) []byte {
+	if bytes.IndexByte(text, '\r') < 0 {
+		return text
+	}
+	text = bytes.ReplaceAll(text, []byte("\r\n"), []byte("\n"))
+	return bytes.ReplaceAll(text, []byte("\r"), []byte("\n"))
+}
@@ -0,0 +1,13 @@
+package textutil
+
+import "testing"
+
+func TestSanitizeText(t *testing.T) {
+	source := "bell\a\r\nover\rwrite\tand\x1b[31m\x7f\u009b\n"
+	if got, want := SanitizeText(source, false), "bell\r\noverwrite\tand[31m\n"; got != want {
+		t.Errorf("SanitizeText = %q, want %q", got, want)
+	}
+	if got, want := SanitizeText(source, true), "bell^G\r\nover^Mwrite\tand^[[31m^?<U+009B>\n"; got != want {
+		t.Errorf("SanitizeText visibly = %q, want %q", got, want)
+	}
+}
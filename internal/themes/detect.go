@@ -0,0 +1,37 @@
+package themes
+
+import (
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/charmbracelet/lipgloss"
+)
+
+// Auto is the theme that picks the light or dark theme to suit the
+// terminal's background
+const Auto = "auto"
+
+// DarkBackground reports whether the terminal's background is dark. The
+// COLORFGBG variable set by rxvt, Konsole and others is trusted if present;
+// otherwise the terminal is asked for its background color (OSC 11), and a
+// dark background is assumed if it does not answer. Call it before the
+// program starts reading input.
+var DarkBackground = func() bool {
+	if dark, ok := colorFGBGDark(os.Getenv("COLORFGBG")); ok {
+		return dark
+	}
+	return lipgloss.HasDarkBackground()
+}
+
+// colorFGBGDark reads a COLORFGBG value such as "15;0", whose last field
+// is the ANSI color of the background, and reports whether it is dark
+func colorFGBGDark(value string) (dark, ok bool) {
+	fields := strings.Split(value, ";")
+	bg, err := strconv.Atoi(fields[len(fields)-1])
+	if value == "" || err != nil || bg < 0 || bg > 15 {
+		return false, false
+	}
+	// White (7) and the bright colors other than gray (8) are light
+	return bg != 7 && (bg < 9), true
+}
@@ -0,0 +1,38 @@
+package themes
+
+import "testing"
+
+func TestColorFGBG(t *testing.T) {
+	tests := []struct {
+		value    string
+		dark, ok bool
+	}{
+		{"15;0", true, true},
+		{"0;15", false, true},
+		{"0;7", false, true},
+		{"7;8", true, true},
+		{"12;default;0", true, true},
+		{"default;default", false, false},
+		{"", false, false},
+	}
+	for _, tt := range tests {
+		dark, ok := colorFGBGDark(tt.value)
+		if dark != tt.dark || ok != tt.ok {
+			t.Errorf("colorFGBGDark(%q) = %v, %v; want %v, %v", tt.value, dark, ok, tt.dark, tt.ok)
+		}
+	}
+}
+
+func TestAutoTheme(t *testing.T) {
+	saved := DarkBackground
+	t.Cleanup(func() { DarkBackground = saved })
+
+	DarkBackground = func() bool { return false }
+	if theme := GetTheme(Auto); theme.Theme != "light" {
+		t.Errorf("on a light background GetTheme(auto) = %s", theme.Theme)
+	}
+	DarkBackground = func() bool { return true }
+	if theme := GetTheme(Auto); theme.Theme != "dark" {
+		t.Errorf("on a dark background GetTheme(auto) = %s", theme.Theme)
+	}
+}
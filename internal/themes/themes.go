@@ -5,6 +5,11 @@ import "starsearch/internal/types"
 // GetTheme returns a color configuration for the given theme name
 func GetTheme(themeName string) *types.ColorConfig {
 	switch themeName {
+	case Auto:
+		if DarkBackground() {
+			return GetTheme("dark")
+		}
+		return GetTheme("light")
 	case "dark":
 		return &types.ColorConfig{
 			Theme:             "dark",
@@ -149,6 +154,7 @@ func getDefaultConfig() *types.ColorConfig {
 // GetAvailableThemes returns a list of available theme names
 func GetAvailableThemes() []string {
 	return []string{
+		Auto,
 		"default",
 		"dark",
 		"light",
@@ -1,6 +1,9 @@
 package types
 
-import "time"
+import (
+	"net/url"
+	"time"
+)
 
 // LineType represents the type of a line in a Gemini document
 type LineType int
@@ -20,20 +23,56 @@ const (
 
 // Line represents a single line in a Gemini document
 type Line struct {
-	Type    LineType
-	Raw     string // Raw line content
-	Text    string // Display text
-	URL     string // For links only
-	LinkNum int    // Link number for keyboard selection
+	Type     LineType
+	Raw      string // Raw line content
+	Text     string // Display text
+	URL      string // For links only
+	LinkNum  int    // Link number for keyboard selection
+	ItemType string // Gopher item type of a menu line, e.g. "1" or "i"; empty outside Gopher menus
 }
 
 // Document represents a parsed Gemini document
 type Document struct {
-	URL      string
-	RawBody  []byte
-	Lines    []Line
-	Links    []Line // All links for easy access
-	MIMEType string
+	URL        string
+	RawBody    []byte
+	Lines      []Line
+	Links      []Line // All links for easy access
+	MIMEType   string
+	ItemType   string         // Gopher item type of the document itself; empty for Gemini
+	Warnings   []ParseWarning // Out-of-spec input the parser worked around
+	Violations []string       // Gemini protocol violations by the server, reported in strict mode
+	Metadata   Metadata       // How and when the document was fetched
+}
+
+// Metadata describes the fetch a document came from, the same way for
+// every protocol
+type Metadata struct {
+	Protocol string    // URL scheme the document was fetched over, e.g. "gemini" or "gopher"
+	Status   int       // Gemini status; Gopher responses are always 20
+	Meta     string    // Header meta: the MIME type, or the prompt or target of other statuses
+	Size     int64     // Length of the body in bytes
+	Fetched  time.Time // When the response arrived; zero for pages made by the browser itself
+
+	// Line ending convention of a text body as sent, before the parser
+	// normalized it: "LF", "CRLF", "CR" or "mixed"
+	LineEndings string
+}
+
+// ParseWarning counts the occurrences of one kind of out-of-spec input
+type ParseWarning struct {
+	Message string
+	Count   int
+}
+
+// AddWarning records count more occurrences of a parse warning
+func (d *Document) AddWarning(message string, count int) {
+	for i := range d.Warnings {
+		if d.Warnings[i].Message == message {
+			d.Warnings[i].Count += count
+			return
+		}
+	}
+	d.Warnings = append(d.Warnings, ParseWarning{Message: message, Count: count})
 }
 
 // Response represents a Gemini protocol response
@@ -43,15 +82,42 @@ type Response struct {
 	Body       []byte
 	RemoteAddr string
 	URL        string
+	Protocol   string    // Set by the client that fetched the response
+	Fetched    time.Time // When the response arrived; zero if it did not come over the network
+}
+
+// Metadata returns the facts about r that its document keeps. Responses
+// made without a client take their protocol from the URL scheme.
+func (r *Response) Metadata() Metadata {
+	protocol := r.Protocol
+	if protocol == "" {
+		if u, err := url.Parse(r.URL); err == nil {
+			protocol = u.Scheme
+		}
+	}
+	return Metadata{
+		Protocol: protocol,
+		Status:   r.Status,
+		Meta:     r.Meta,
+		Size:     int64(len(r.Body)),
+		Fetched:  r.Fetched,
+	}
 }
 
 // Tab represents a browser tab
 type Tab struct {
-	ID       int
+	ID       int // Unique for the life of the tab bar
 	Title    string
 	URL      string
 	Document *Document
-	Scroll   int // Scroll position
+	Scroll   int  // Scroll position
+	Loading  bool // A background fetch for this tab is in progress
+	Failed   bool // The last background fetch for this tab failed
+	Evicted  bool // Document was dropped to save memory; reload on activation
+	LastUsed int  // When the tab was last shown, as a sequence number
+
+	WatchInterval  int // Seconds between automatic reloads; 0 when the tab is not watched
+	WatchRemaining int // Seconds until the next automatic reload
 }
 
 // Bookmark represents a saved bookmark
@@ -75,15 +141,29 @@ type Config struct {
 	Colors      ColorConfig       `toml:"colors"`
 	Downloads   DownloadConfig    `toml:"downloads"`
 	Performance PerformanceConfig `toml:"performance"`
+	Network     NetworkConfig     `toml:"network"`
 }
 
 // GeneralConfig contains general application settings
 type GeneralConfig struct {
-	HomeURL         string `toml:"home_url"`
-	SearchEngine    string `toml:"search_engine"`
-	MaxHistory      int    `toml:"max_history"`
-	AutoSaveHistory bool   `toml:"auto_save_history"`
-	RestoreSession  bool   `toml:"restore_session"`
+	HomeURL             string         `toml:"home_url"`
+	SearchEngine        string         `toml:"search_engine,omitempty"` // Deprecated: migrated into SearchEngines
+	DefaultSearchEngine string         `toml:"default_search_engine"`
+	MaxHistory          int            `toml:"max_history"`
+	AutoSaveHistory     bool           `toml:"auto_save_history"`
+	RestoreSession      bool           `toml:"restore_session"`
+	SearchEngines       []SearchEngine `toml:"search_engines"`
+	TelnetCommand       string         `toml:"telnet_command"` // e.g. "telnet {host} {port}"; empty copies the address
+	RandomCapsuleURL    string         `toml:"random_capsule_url"` // Endpoint that redirects to a random capsule, for about:discover
+	AudioPlayer         string         `toml:"audio_player"` // e.g. "mpv --no-terminal {file}"; without {file} sound is piped to stdin. Empty saves sound files
+	IndexBookmarks      bool           `toml:"index_bookmarks"` // Fetch bookmarked pages so the bookmarks filter matches their text
+	Openers             map[string]string `toml:"openers"` // Command per MIME type or "type/*" for viewing in another program, e.g. "zathura {file}"; others use the desktop's
+}
+
+// SearchEngine is a named search provider whose URL accepts status 10 input
+type SearchEngine struct {
+	Name string `toml:"name"`
+	URL  string `toml:"url"`
 }
 
 // UIConfig contains user interface settings
@@ -92,6 +172,28 @@ type UIConfig struct {
 	ShowLinkNumbers bool `toml:"show_link_numbers"`
 	EnableMouse     bool `toml:"enable_mouse"`
 	ScrollSpeed     int  `toml:"scroll_speed"`
+	ShowBreadcrumbs bool `toml:"show_breadcrumbs"`
+	RenderTables    bool `toml:"render_tables"`
+	SyntaxHighlight bool `toml:"syntax_highlight"`
+	LinkStyle       string `toml:"link_style"` // "inline" or "footnote"
+	QuoteFoldThreshold int `toml:"quote_fold_threshold"` // Collapse longer quote runs; -1 disables
+	ToastDurationMs int `toml:"toast_duration_ms"` // How long notifications stay up; -1 shows them in the status bar instead
+	Glyphs          string `toml:"glyphs"` // "auto", "unicode" or "ascii"
+	HideTabBar      bool `toml:"hide_tab_bar"`
+	HideAddressBar  bool `toml:"hide_address_bar"` // Still shown while it has focus
+	HideStatusBar   bool `toml:"hide_status_bar"`
+	Zoom            int  `toml:"zoom"` // Content density from -2 (most content) to 2 (most spacious)
+	H1Margin        []int `toml:"h1_margin"` // Blank lines above and below level 1 headings
+	H2Margin        []int `toml:"h2_margin"`
+	H3Margin        []int `toml:"h3_margin"`
+	MaxBlankLines   int  `toml:"max_blank_lines"` // Longest run of blank lines shown; -1 shows all
+	IndentUnderHeadings int `toml:"indent_under_headings"` // Columns to indent text under headings by
+	TerminalBidi    bool `toml:"terminal_bidi"` // The terminal reorders right-to-left text itself
+	Spelling        bool `toml:"spelling"` // Highlight likely misspellings in input prompts
+	ImageColors     string `toml:"image_colors"` // "auto", "truecolor" or "256"
+	ImageDither     bool `toml:"image_dither"` // Dither images drawn in 256 colours
+	ImageGrayscale  bool `toml:"image_grayscale"`
+	ShowControlChars bool `toml:"show_control_chars"` // Show control characters in page sources as ^G-style escapes instead of removing them
 }
 
 // ColorConfig contains color theme settings
@@ -124,6 +226,25 @@ type PerformanceConfig struct {
 	EnablePrefetch   bool `toml:"enable_prefetch"`
 	PrefetchIdleDelay int `toml:"prefetch_idle_delay"`
 	ConnectionPoolSize int `toml:"connection_pool_size"`
+	TabMemoryMB      int  `toml:"tab_memory_mb"` // Memory for documents in open tabs before background tabs are evicted; -1 disables
+	CacheMemoryOnly  bool `toml:"cache_memory_only"` // Keep the page cache in memory instead of on disk across restarts
+	Revalidate       bool `toml:"revalidate"` // Reloading fetches the page again and keeps the scroll position if it is unchanged
+}
+
+// NetworkConfig contains network settings
+type NetworkConfig struct {
+	RetryAttempts      int    `toml:"retry_attempts"`        // Retries after a transient failure; -1 disables
+	RetryBackoffMs     int    `toml:"retry_backoff_ms"`      // Delay before the first retry, doubled each attempt
+	MaxParallelFetches int    `toml:"max_parallel_fetches"`  // Background requests allowed at once
+	MaxRequests        int    `toml:"max_requests"`          // Requests allowed at once in total
+	MaxRequestsPerHost int    `toml:"max_requests_per_host"` // Requests allowed at once to a single host
+	GopherStrictness   string `toml:"gopher_strictness"`     // "lenient" works around common server bugs; "strict" shows them
+	GeminiStrict       bool   `toml:"gemini_strict"`         // Start with strict mode on, flagging Gemini protocol violations
+	SocksProxy         string `toml:"socks_proxy"`           // SOCKS5 proxy for all requests, e.g. Tor's "127.0.0.1:9050"; empty connects directly
+	TorIsolation       bool   `toml:"tor_isolation"`         // Give each tab its own SOCKS credentials, and so its own Tor circuits
+	GopherMaxSizeMB    int    `toml:"gopher_max_size_mb"`    // Largest Gopher response accepted
+	GopherIdleTimeout  int    `toml:"gopher_idle_timeout"`   // Seconds a Gopher server may send nothing before the request fails
+	BackgroundKBps     int    `toml:"background_kbps"`       // KiB per second background requests may use on average; 0 is unlimited
 }
 
 // DownloadStatus represents the status of a download
@@ -172,6 +293,53 @@ type CertificateInfo struct {
 	LastSeen     time.Time `json:"last_seen"`
 }
 
+// CacheEntryInfo represents a cached response for display
+type CacheEntryInfo struct {
+	URL    string
+	Status int
+	Meta   string
+	Size   int64
+	Stored time.Time
+	TTL    time.Duration
+}
+
+// Expired reports whether the entry is past its TTL at now
+func (e CacheEntryInfo) Expired(now time.Time) bool {
+	return now.After(e.Stored.Add(e.TTL))
+}
+
+// CapsuleToken is the session token remembered for a host the user allowed
+// to keep one. The token is the query string the capsule last redirected to.
+type CapsuleToken struct {
+	Host   string `json:"host"`
+	Token  string `json:"token,omitempty"`
+	Stored int64  `json:"stored,omitempty"` // When the token was captured, Unix seconds
+}
+
+// HostPrivacy sums up what is sent to one host, or kept about it, for the
+// privacy report
+type HostPrivacy struct {
+	Host       string
+	Identities []string  // Client certificates presented on it or scoped to it
+	Token      bool      // Allowed to keep a capsule token
+	TokenSet   bool      // A token has been captured
+	Inputs     int       // Answers to its input prompts remembered
+	FirstSeen  time.Time // When its certificate was first trusted, zero if never
+	LastSeen   time.Time // When its trusted certificate was last seen
+}
+
+// IdentityInfo represents a client certificate for display
+type IdentityInfo struct {
+	Name        string
+	CommonName  string
+	KeyType     string
+	Fingerprint string
+	NotBefore   time.Time
+	NotAfter    time.Time
+	Scopes      []string // URL prefixes the identity is presented on
+	UsedOn      []string // URLs the identity was presented on, most recent first
+}
+
 // SessionTab represents a tab in a saved session
 type SessionTab struct {
 	URL    string `json:"url"`
@@ -4,6 +4,8 @@ import (
 	"github.com/charmbracelet/bubbles/textinput"
 	tea "github.com/charmbracelet/bubbletea"
 	"github.com/charmbracelet/lipgloss"
+	"starsearch/internal/gopher"
+	"starsearch/internal/urlutil"
 )
 
 // AddressBar represents the URL input bar
@@ -12,6 +14,9 @@ type AddressBar struct {
 	focused     bool
 	width       int
 	suggestions *Suggestions
+	selected    bool            // Whether the whole URL is selected, so typing replaces it
+	hosts       func() []string // Origins offered by host completion
+	completion  *hostCompletion // Tab presses completing the host, if any
 }
 
 // NewAddressBar creates a new address bar
@@ -39,8 +44,45 @@ func (a *AddressBar) Update(msg tea.Msg) (*AddressBar, tea.Cmd) {
 	var cmd tea.Cmd
 
 	switch msg := msg.(type) {
+	case PasteMsg:
+		if a.focused && msg.Err == nil {
+			a.Paste(msg.Text)
+		}
+		return a, nil
+
 	case tea.KeyMsg:
 		if a.focused {
+			// Pasted text is cleaned up before it is inserted; Ctrl+V reads
+			// the clipboard itself so it gets the same treatment
+			if msg.Paste {
+				a.Paste(string(msg.Runes))
+				return a, nil
+			}
+			if msg.String() == "ctrl+v" {
+				return a, ReadClipboard(false)
+			}
+
+			// With the URL selected, typing replaces it and deleting clears
+			// it; any other key just drops the selection
+			if a.selected {
+				a.selected = false
+				switch {
+				case msg.Type == tea.KeyRunes, msg.Type == tea.KeySpace:
+					a.input.SetValue("")
+				case msg.Type == tea.KeyBackspace, msg.Type == tea.KeyDelete:
+					a.input.SetValue("")
+					return a, nil
+				}
+			}
+
+			// Tab completes the host, cycling through the hosts that
+			// match; past the host it moves through the suggestions
+			if msg.String() == "tab" || msg.String() == "shift+tab" {
+				if a.completeHost(msg.String() == "tab") {
+					return a, nil
+				}
+			}
+
 			// Handle suggestion navigation first
 			if a.suggestions.IsVisible() {
 				var suggestionCmd tea.Cmd
@@ -70,7 +112,7 @@ func (a *AddressBar) Update(msg tea.Msg) (*AddressBar, tea.Cmd) {
 				a.input.Blur()
 				a.suggestions.Hide()
 				if url != "" {
-					return a, func() tea.Msg { return NavigateMsg{URL: url} }
+					return a, func() tea.Msg { return NavigateMsg{URL: url, Typed: true} }
 				}
 				return a, nil
 			case "esc":
@@ -88,7 +130,14 @@ func (a *AddressBar) Update(msg tea.Msg) (*AddressBar, tea.Cmd) {
 		}
 
 	case tea.MouseMsg:
+		if a.focused && msg.Action == tea.MouseActionPress && msg.Button == tea.MouseButtonMiddle {
+			// Middle-click pastes the primary selection, as in X11 apps
+			return a, ReadPrimarySelection()
+		}
 		if a.focused && msg.Action == tea.MouseActionPress && msg.Button == tea.MouseButtonLeft {
+			// Clicking places the cursor instead of keeping the selection
+			a.selected = false
+
 			// Calculate position within the text input
 			// Border takes 1 char on left, padding takes 1 char = 2 chars offset
 			// The address bar is rendered with border and padding, so adjust coordinates
@@ -148,35 +197,79 @@ func (a *AddressBar) View() string {
 	// Adjust input width to available space
 	a.input.Width = a.width - 4 // Account for border and padding
 
-	addressBarView := style.Width(a.width).Render(a.input.View())
-	
-	// Add suggestions if visible
-	if a.suggestions.IsVisible() {
-		a.suggestions.SetWidth(a.width)
-		suggestionsView := a.suggestions.View()
-		if suggestionsView != "" {
-			return addressBarView + "\n" + suggestionsView
+	inputView := a.input.View()
+	if a.selected {
+		// Show the selected URL highlighted, trimmed to the visible width
+		value := []rune(a.input.Value())
+		if len(value) > a.input.Width {
+			value = value[:a.input.Width]
 		}
+		inputView = a.input.PromptStyle.Render(a.input.Prompt) +
+			lipgloss.NewStyle().Reverse(true).Render(string(value))
+	}
+
+	return style.Width(a.width).Render(inputView)
+}
+
+// OverlaySuggestions draws the suggestions dropdown, if open, over
+// background from row top down, so it covers the page instead of pushing
+// it down
+func (a *AddressBar) OverlaySuggestions(background string, top int) string {
+	if !a.suggestions.IsVisible() {
+		return background
+	}
+	a.suggestions.SetWidth(a.width)
+	suggestionsView := a.suggestions.View()
+	if suggestionsView == "" {
+		return background
 	}
-	
-	return addressBarView
+	return overlayAt(background, suggestionsView, 0, top)
 }
 
-// Focus sets focus on the address bar
+// Focus sets focus on the address bar and selects the whole URL
 func (a *AddressBar) Focus() tea.Cmd {
 	a.focused = true
+	a.selected = a.input.Value() != ""
+	a.input.CursorEnd()
 	return a.input.Focus()
 }
 
 // Blur removes focus from the address bar
 func (a *AddressBar) Blur() {
 	a.focused = false
+	a.selected = false
 	a.input.Blur()
 }
 
-// SetValue sets the address bar value
+// Paste inserts pasted text at the cursor, replacing the URL if it is
+// selected. Whitespace and surrounding angle brackets are stripped.
+func (a *AddressBar) Paste(text string) {
+	text = urlutil.CleanPasted(text)
+	if text == "" {
+		return
+	}
+
+	if a.selected {
+		a.selected = false
+		a.input.SetValue(text)
+		a.input.CursorEnd()
+		return
+	}
+
+	value := []rune(a.input.Value())
+	pos := a.input.Position()
+	inserted := make([]rune, 0, len(value)+len(text))
+	inserted = append(inserted, value[:pos]...)
+	inserted = append(inserted, []rune(text)...)
+	inserted = append(inserted, value[pos:]...)
+	a.input.SetValue(string(inserted))
+	a.input.SetCursor(pos + len([]rune(text)))
+}
+
+// SetValue sets the address bar value. Gopher selectors are shown
+// unescaped where that is unambiguous.
 func (a *AddressBar) SetValue(url string) {
-	a.input.SetValue(url)
+	a.input.SetValue(gopher.DisplayURL(url))
 }
 
 // Value returns the current address bar value
@@ -210,5 +303,6 @@ func (a *AddressBar) GetSuggestions() *Suggestions {
 
 // NavigateMsg is sent when the user wants to navigate to a URL
 type NavigateMsg struct {
-	URL string
+	URL   string
+	Typed bool // Entered by hand in the address bar; may be a search query
 }
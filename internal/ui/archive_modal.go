@@ -0,0 +1,238 @@
+package ui
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+
+	"github.com/charmbracelet/bubbles/key"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"starsearch/internal/archive"
+)
+
+// ArchiveModal lists the entries of a downloaded archive so single files
+// can be extracted from it
+type ArchiveModal struct {
+	visible      bool
+	path         string
+	entries      []archive.Entry
+	selectedIdx  int
+	width        int
+	height       int
+	scrollOffset int
+}
+
+// ArchiveExtractMsg is sent to extract one entry of the archive
+type ArchiveExtractMsg struct {
+	Path  string // Archive file
+	Entry string // Name of the entry within it
+}
+
+func NewArchiveModal() *ArchiveModal {
+	return &ArchiveModal{}
+}
+
+// Show opens the modal on the entries of the archive at path
+func (m *ArchiveModal) Show(path string, entries []archive.Entry) {
+	m.visible = true
+	m.path = path
+	m.entries = entries
+	m.selectedIdx = 0
+	m.scrollOffset = 0
+}
+
+func (m *ArchiveModal) Hide() {
+	m.visible = false
+}
+
+func (m *ArchiveModal) IsVisible() bool {
+	return m.visible
+}
+
+func (m *ArchiveModal) SetSize(width, height int) {
+	m.width = width
+	m.height = height
+}
+
+func (m *ArchiveModal) Update(msg tea.Msg) (*ArchiveModal, tea.Cmd) {
+	keyMsg, ok := msg.(tea.KeyMsg)
+	if !m.visible || !ok {
+		return m, nil
+	}
+
+	switch {
+	case key.Matches(keyMsg, key.NewBinding(key.WithKeys("esc", "Z"))):
+		m.Hide()
+
+	case key.Matches(keyMsg, key.NewBinding(key.WithKeys("j", "down"))):
+		if m.selectedIdx < len(m.entries)-1 {
+			m.selectedIdx++
+			m.adjustScroll()
+		}
+
+	case key.Matches(keyMsg, key.NewBinding(key.WithKeys("k", "up"))):
+		if m.selectedIdx > 0 {
+			m.selectedIdx--
+			m.adjustScroll()
+		}
+
+	case key.Matches(keyMsg, key.NewBinding(key.WithKeys("enter", "x"))):
+		if m.selectedIdx < len(m.entries) && !m.entries[m.selectedIdx].Dir {
+			path, name := m.path, m.entries[m.selectedIdx].Name
+			return m, func() tea.Msg { return ArchiveExtractMsg{Path: path, Entry: name} }
+		}
+	}
+
+	return m, nil
+}
+
+func (m *ArchiveModal) adjustScroll() {
+	visibleHeight := m.height - 10
+	if visibleHeight < 1 {
+		visibleHeight = 1
+	}
+
+	if m.selectedIdx >= m.scrollOffset+visibleHeight {
+		m.scrollOffset = m.selectedIdx - visibleHeight + 1
+	}
+	if m.selectedIdx < m.scrollOffset {
+		m.scrollOffset = m.selectedIdx
+	}
+}
+
+func (m *ArchiveModal) View() string {
+	if !m.visible {
+		return ""
+	}
+
+	modalWidth := m.width - 4
+	if modalWidth < 50 {
+		modalWidth = 50
+	}
+	if modalWidth > 110 {
+		modalWidth = 110
+	}
+
+	titleStyle := lipgloss.NewStyle().
+		Bold(true).
+		Foreground(lipgloss.Color("12")).
+		Width(modalWidth).
+		Align(lipgloss.Center).
+		MarginBottom(1)
+
+	headerStyle := lipgloss.NewStyle().
+		Foreground(lipgloss.Color("8")).
+		Bold(true)
+
+	selectedStyle := lipgloss.NewStyle().
+		Background(lipgloss.Color("12")).
+		Foreground(lipgloss.Color("0")).
+		Bold(true).
+		Width(modalWidth - 4)
+
+	normalStyle := lipgloss.NewStyle().
+		Foreground(lipgloss.Color("15")).
+		Width(modalWidth - 4)
+
+	dirStyle := lipgloss.NewStyle().
+		Foreground(lipgloss.Color("8")).
+		Width(modalWidth - 4)
+
+	emptyStyle := lipgloss.NewStyle().
+		Foreground(lipgloss.Color("8")).
+		Italic(true).
+		Width(modalWidth).
+		Align(lipgloss.Center).
+		MarginTop(1).
+		MarginBottom(1)
+
+	helpStyle := lipgloss.NewStyle().
+		Foreground(lipgloss.Color("7")).
+		Width(modalWidth).
+		Align(lipgloss.Center).
+		MarginTop(1)
+
+	borderStyle := lipgloss.NewStyle().
+		Border(lipgloss.RoundedBorder()).
+		BorderForeground(lipgloss.Color("12")).
+		Padding(1, 2).
+		Width(modalWidth)
+
+	var total int64
+	for _, entry := range m.entries {
+		total += entry.Size
+	}
+
+	var b strings.Builder
+	b.WriteString(titleStyle.Render(fmt.Sprintf("%s (%d entries, %s)", filepath.Base(m.path), len(m.entries), FormatBytes(total))))
+	b.WriteString("\n")
+
+	// Name column takes what the size column leaves
+	nameWidth := modalWidth - 4 - 10
+	if len(m.entries) == 0 {
+		b.WriteString(emptyStyle.Render("The archive is empty"))
+		b.WriteString("\n")
+	} else {
+		b.WriteString(headerStyle.Render(fmt.Sprintf("%-*s%10s", nameWidth, "Name", "Size")))
+		b.WriteString("\n")
+
+		visibleHeight := m.height - 10
+		if visibleHeight < 1 {
+			visibleHeight = 1
+		}
+		endIdx := m.scrollOffset + visibleHeight
+		if endIdx > len(m.entries) {
+			endIdx = len(m.entries)
+		}
+
+		for i := m.scrollOffset; i < endIdx; i++ {
+			entry := m.entries[i]
+
+			name := entry.Name
+			if len(name) > nameWidth-1 {
+				name = "..." + name[len(name)-nameWidth+4:]
+			}
+			size := FormatBytes(entry.Size)
+			if entry.Dir {
+				size = "-"
+			}
+			line := fmt.Sprintf("%-*s%10s", nameWidth, name, size)
+
+			switch {
+			case i == m.selectedIdx:
+				b.WriteString(selectedStyle.Render(line))
+			case entry.Dir:
+				b.WriteString(dirStyle.Render(line))
+			default:
+				b.WriteString(normalStyle.Render(line))
+			}
+			b.WriteString("\n")
+		}
+	}
+
+	b.WriteString(helpStyle.Render("j/k: move • enter/x: extract to downloads • esc/q: close"))
+
+	content := borderStyle.Render(b.String())
+
+	// Center the modal
+	contentHeight := strings.Count(content, "\n") + 1
+	contentWidth := modalWidth + 6 // Account for border and padding
+
+	topPadding := (m.height - contentHeight) / 2
+	if topPadding < 0 {
+		topPadding = 0
+	}
+
+	leftPadding := (m.width - contentWidth) / 2
+	if leftPadding < 0 {
+		leftPadding = 0
+	}
+
+	result := strings.Repeat("\n", topPadding)
+	for _, line := range strings.Split(content, "\n") {
+		result += strings.Repeat(" ", leftPadding) + line + "\n"
+	}
+
+	return result
+}
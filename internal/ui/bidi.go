@@ -0,0 +1,185 @@
+package ui
+
+import (
+	"strings"
+
+	"github.com/charmbracelet/lipgloss"
+	"golang.org/x/text/unicode/bidi"
+)
+
+// SetBidi sets whether right-to-left text is reordered for display and
+// re-renders the page. Terminals that apply the bidi algorithm themselves
+// need it off, or the text is reversed twice.
+func (c *ContentViewport) SetBidi(enabled bool) {
+	c.shapeBidi = enabled
+	if c.document != nil {
+		c.rerender()
+	}
+}
+
+// strongClass returns the bidi class of the first strong character in
+// text, or bidi.ON if it has none
+func strongClass(text string) bidi.Class {
+	for _, r := range text {
+		props, _ := bidi.LookupRune(r)
+		switch class := props.Class(); class {
+		case bidi.L, bidi.R, bidi.AL:
+			return class
+		}
+	}
+	return bidi.ON
+}
+
+// rightToLeft reports whether text is a right-to-left paragraph, which is
+// when its first strong character is Hebrew, Arabic or another RTL script
+func rightToLeft(text string) bool {
+	class := strongClass(text)
+	return class == bidi.R || class == bidi.AL
+}
+
+// hasRTL reports whether text contains any right-to-left characters
+func hasRTL(text string) bool {
+	for _, r := range text {
+		props, _ := bidi.LookupRune(r)
+		if class := props.Class(); class == bidi.R || class == bidi.AL {
+			return true
+		}
+	}
+	return false
+}
+
+// visual returns a row of text in display order: right-to-left runs are
+// reversed, and so is the order of runs in a right-to-left paragraph.
+// Each character keeps its style. Only the common two embedding levels
+// are reordered, which covers numbers and Latin words inside RTL text and
+// RTL phrases inside Latin text.
+func (t styledText) visual(rtl bool) styledText {
+	text := t.String()
+	if !hasRTL(text) {
+		return t
+	}
+
+	var p bidi.Paragraph
+	direction := bidi.LeftToRight
+	if rtl {
+		direction = bidi.RightToLeft
+	}
+	if _, err := p.SetString(text, bidi.DefaultDirection(direction)); err != nil {
+		return t
+	}
+	order, err := p.Order()
+	if err != nil {
+		return t
+	}
+
+	// Byte offsets of the runs, which the ordering gives in runes
+	runeOffsets := make([]int, 0, len(text)+1)
+	for i := range text {
+		runeOffsets = append(runeOffsets, i)
+	}
+	runeOffsets = append(runeOffsets, len(text))
+
+	type run struct {
+		text styledText
+		rtl  bool
+	}
+	runs := make([]run, order.NumRuns())
+	for i := range runs {
+		r := order.Run(i)
+		start, end := r.Pos()
+		runs[i] = run{
+			text: t.slice(runeOffsets[start], runeOffsets[end+1]),
+			rtl:  r.Direction() == bidi.RightToLeft,
+		}
+	}
+
+	// In a left-to-right paragraph, right-to-left runs and the numbers
+	// between them form one group read from the right
+	var groups [][]run
+	if rtl {
+		groups = [][]run{runs}
+	} else {
+		for i := 0; i < len(runs); i++ {
+			if !runs[i].rtl {
+				groups = append(groups, runs[i:i+1])
+				continue
+			}
+			end := i
+			for j := i + 1; j < len(runs); j++ {
+				if runs[j].rtl {
+					end = j
+				} else if strongClass(runs[j].text.String()) == bidi.L {
+					break
+				}
+			}
+			groups = append(groups, runs[i:end+1])
+			i = end
+		}
+	}
+
+	var out styledText
+	for _, group := range groups {
+		if len(group) == 1 && !group[0].rtl {
+			out = out.addAll(group[0].text)
+			continue
+		}
+		for i := len(group) - 1; i >= 0; i-- {
+			if group[i].rtl {
+				out = out.addAll(group[i].text.reversed())
+			} else {
+				out = out.addAll(group[i].text)
+			}
+		}
+	}
+	return out
+}
+
+// addAll appends every span of other
+func (t styledText) addAll(other styledText) styledText {
+	for _, span := range other {
+		t = t.add(span.text, span.style)
+	}
+	return t
+}
+
+// reversed returns the text with its characters in reverse order,
+// mirroring brackets, and each character keeping its style
+func (t styledText) reversed() styledText {
+	out := make(styledText, 0, len(t))
+	for i := len(t) - 1; i >= 0; i-- {
+		out = out.add(bidi.ReverseString(t[i].text), t[i].style)
+	}
+	return out
+}
+
+// shapeBidiText reorders each line of plain text for display and
+// right-aligns right-to-left paragraphs within width
+func (c *ContentViewport) shapeBidiText(text string, rtl bool, width int) string {
+	if !c.shapeBidi || !hasRTL(text) {
+		return text
+	}
+	lines := strings.Split(text, "\n")
+	for i, line := range lines {
+		lines[i] = newStyledText(line, nil).visual(rtl).String()
+		if rtl {
+			lines[i] = alignRight(lines[i], width)
+		}
+	}
+	return strings.Join(lines, "\n")
+}
+
+// alignRight pads line on the left so it ends at column width
+func alignRight(line string, width int) string {
+	if pad := width - lipgloss.Width(line); pad > 0 {
+		return strings.Repeat(" ", pad) + line
+	}
+	return line
+}
+
+// bidiRow returns a wrapped row in display order when bidi shaping is on
+func (c *ContentViewport) bidiRow(row styledText, rtl bool) styledText {
+	if !c.shapeBidi {
+		return row
+	}
+	return row.visual(rtl)
+}
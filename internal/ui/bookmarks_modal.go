@@ -5,6 +5,7 @@ import (
 	"strings"
 
 	"github.com/charmbracelet/bubbles/key"
+	"github.com/charmbracelet/bubbles/textinput"
 	tea "github.com/charmbracelet/bubbletea"
 	"github.com/charmbracelet/lipgloss"
 	"starsearch/internal/types"
@@ -13,13 +14,39 @@ import (
 // BookmarksModal displays a list of bookmarks for viewing and management
 type BookmarksModal struct {
 	visible      bool
-	bookmarks    []types.Bookmark
+	all          []types.Bookmark
+	bookmarks    []types.Bookmark // Those of all matching the filter
 	selectedIdx  int
 	width        int
 	height       int
 	scrollOffset int
+
+	// Inline edit form state
+	editing     bool
+	editInputs  []textinput.Model // title, URL, tags
+	editFocus   int
+	editOrigURL string
+
+	// Filter typed after "/", matched against titles, URLs, tags and,
+	// through content, the text of the pages
+	filtering bool
+	query     string
+	content   BookmarkContent
+	snippets  map[string]string // Page text matching the filter, by URL
+}
+
+// BookmarkContent finds text in bookmarked pages
+type BookmarkContent interface {
+	Snippet(url, query string) (string, bool)
 }
 
+// Edit form field indices
+const (
+	editFieldTitle = iota
+	editFieldURL
+	editFieldTags
+)
+
 // BookmarkSelectedMsg is sent when a bookmark is selected to navigate to
 type BookmarkSelectedMsg struct {
 	URL string
@@ -30,24 +57,232 @@ type BookmarkDeleteMsg struct {
 	URL string
 }
 
+// BookmarkEditMsg is sent when the user saves changes to a bookmark
+type BookmarkEditMsg struct {
+	OldURL string
+	URL    string
+	Title  string
+	Tags   []string
+}
+
 func NewBookmarksModal() *BookmarksModal {
+	placeholders := []string{"Title", "gemini://...", "tag1, tag2"}
+	inputs := make([]textinput.Model, len(placeholders))
+	for i, placeholder := range placeholders {
+		ti := textinput.New()
+		ti.Placeholder = placeholder
+		ti.CharLimit = 1024
+		ti.Width = 50
+		inputs[i] = ti
+	}
+
 	return &BookmarksModal{
 		visible:      false,
 		bookmarks:    []types.Bookmark{},
 		selectedIdx:  0,
 		scrollOffset: 0,
+		editInputs:   inputs,
 	}
 }
 
 func (m *BookmarksModal) Show(bookmarks []types.Bookmark) {
 	m.visible = true
-	m.bookmarks = bookmarks
+	m.all = bookmarks
+	m.filtering = false
+	m.query = ""
+	m.filter()
 	m.selectedIdx = 0
 	m.scrollOffset = 0
+	m.editing = false
+}
+
+// SetContent lets the filter match the text of bookmarked pages. A nil
+// content matches bookmarks by their details only.
+func (m *BookmarksModal) SetContent(content BookmarkContent) {
+	m.content = content
+}
+
+// Refresh replaces the bookmark list while keeping the filter and the
+// selection near its previous position
+func (m *BookmarksModal) Refresh(bookmarks []types.Bookmark) {
+	m.all = bookmarks
+	m.filter()
+	if m.selectedIdx >= len(m.bookmarks) {
+		m.selectedIdx = len(m.bookmarks) - 1
+	}
+	if m.selectedIdx < 0 {
+		m.selectedIdx = 0
+	}
+	m.adjustScroll()
+}
+
+// SelectURL selects the bookmark for url, if it is listed
+func (m *BookmarksModal) SelectURL(url string) {
+	for i, bookmark := range m.bookmarks {
+		if bookmark.URL == url {
+			m.selectedIdx = i
+			m.adjustScroll()
+			return
+		}
+	}
 }
 
 func (m *BookmarksModal) Hide() {
 	m.visible = false
+	m.filtering = false
+	m.query = ""
+	m.stopEditing()
+}
+
+// filter keeps the bookmarks matching the query: in their title, URL or
+// tags, or else in the text of the page
+func (m *BookmarksModal) filter() {
+	m.snippets = make(map[string]string)
+	if m.query == "" {
+		m.bookmarks = m.all
+		return
+	}
+
+	query := strings.ToLower(m.query)
+	m.bookmarks = nil
+	for _, bookmark := range m.all {
+		details := strings.ToLower(bookmark.Title + "\n" + bookmark.URL + "\n" + strings.Join(bookmark.Tags, "\n"))
+		if strings.Contains(details, query) {
+			m.bookmarks = append(m.bookmarks, bookmark)
+			continue
+		}
+		if m.content == nil {
+			continue
+		}
+		if snippet, ok := m.content.Snippet(bookmark.URL, m.query); ok {
+			m.snippets[bookmark.URL] = snippet
+			m.bookmarks = append(m.bookmarks, bookmark)
+		}
+	}
+}
+
+// setQuery filters by query and selects the first match
+func (m *BookmarksModal) setQuery(query string) {
+	m.query = query
+	m.filter()
+	m.selectedIdx = 0
+	m.scrollOffset = 0
+}
+
+// updateFiltering handles key input while the filter is being typed
+func (m *BookmarksModal) updateFiltering(msg tea.KeyMsg) (*BookmarksModal, tea.Cmd) {
+	switch msg.Type {
+	case tea.KeyEsc:
+		m.filtering = false
+		m.setQuery("")
+	case tea.KeyEnter, tea.KeyDown, tea.KeyUp:
+		m.filtering = false
+	case tea.KeyBackspace:
+		if runes := []rune(m.query); len(runes) > 0 {
+			m.setQuery(string(runes[:len(runes)-1]))
+		}
+	case tea.KeyRunes, tea.KeySpace:
+		m.setQuery(m.query + string(msg.Runes))
+	}
+	return m, nil
+}
+
+// IsEditing returns whether the inline edit form is open
+func (m *BookmarksModal) IsEditing() bool {
+	return m.editing
+}
+
+// TakesText reports whether the edit form or the filter is taking typed
+// text
+func (m *BookmarksModal) TakesText() bool {
+	return m.editing || m.filtering
+}
+
+// HandlesMouse reports that the bookmarks modal handles the mouse
+func (m *BookmarksModal) HandlesMouse() bool {
+	return true
+}
+
+// startEditing opens the inline edit form for the selected bookmark
+func (m *BookmarksModal) startEditing() tea.Cmd {
+	if m.selectedIdx >= len(m.bookmarks) {
+		return nil
+	}
+
+	bookmark := m.bookmarks[m.selectedIdx]
+	m.editing = true
+	m.editOrigURL = bookmark.URL
+	m.editInputs[editFieldTitle].SetValue(bookmark.Title)
+	m.editInputs[editFieldURL].SetValue(bookmark.URL)
+	m.editInputs[editFieldTags].SetValue(strings.Join(bookmark.Tags, ", "))
+	for i := range m.editInputs {
+		m.editInputs[i].CursorEnd()
+	}
+	return m.focusEditField(editFieldTitle)
+}
+
+// stopEditing closes the inline edit form without saving
+func (m *BookmarksModal) stopEditing() {
+	m.editing = false
+	m.editOrigURL = ""
+	for i := range m.editInputs {
+		m.editInputs[i].Blur()
+	}
+}
+
+// focusEditField moves focus to the given edit form field
+func (m *BookmarksModal) focusEditField(field int) tea.Cmd {
+	m.editFocus = field
+	for i := range m.editInputs {
+		m.editInputs[i].Blur()
+	}
+	return m.editInputs[field].Focus()
+}
+
+// updateEditing handles key input while the edit form is open
+func (m *BookmarksModal) updateEditing(msg tea.KeyMsg) (*BookmarksModal, tea.Cmd) {
+	switch msg.String() {
+	case "esc":
+		m.stopEditing()
+		return m, nil
+
+	case "tab", "down":
+		return m, m.focusEditField((m.editFocus + 1) % len(m.editInputs))
+
+	case "shift+tab", "up":
+		return m, m.focusEditField((m.editFocus + len(m.editInputs) - 1) % len(m.editInputs))
+
+	case "enter":
+		url := strings.TrimSpace(m.editInputs[editFieldURL].Value())
+		if url == "" {
+			// A bookmark without a URL is meaningless; keep the form open
+			return m, m.focusEditField(editFieldURL)
+		}
+		editMsg := BookmarkEditMsg{
+			OldURL: m.editOrigURL,
+			URL:    url,
+			Title:  strings.TrimSpace(m.editInputs[editFieldTitle].Value()),
+			Tags:   parseTags(m.editInputs[editFieldTags].Value()),
+		}
+		m.stopEditing()
+		return m, func() tea.Msg { return editMsg }
+	}
+
+	var cmd tea.Cmd
+	m.editInputs[m.editFocus], cmd = m.editInputs[m.editFocus].Update(msg)
+	return m, cmd
+}
+
+// parseTags splits a comma-separated tag list, dropping empty entries
+func parseTags(value string) []string {
+	var tags []string
+	for _, tag := range strings.Split(value, ",") {
+		tag = strings.TrimSpace(tag)
+		if tag != "" {
+			tags = append(tags, tag)
+		}
+	}
+	return tags
 }
 
 func (m *BookmarksModal) IsVisible() bool {
@@ -66,8 +301,20 @@ func (m *BookmarksModal) Update(msg tea.Msg) (*BookmarksModal, tea.Cmd) {
 
 	switch msg := msg.(type) {
 	case tea.KeyMsg:
+		if m.editing {
+			return m.updateEditing(msg)
+		}
+		if m.filtering {
+			return m.updateFiltering(msg)
+		}
+
 		switch {
-		case key.Matches(msg, key.NewBinding(key.WithKeys("esc", "q", "b"))):
+		case key.Matches(msg, key.NewBinding(key.WithKeys("esc", "b"))):
+			if m.query != "" && msg.Type == tea.KeyEsc {
+				// Back out of the filter before closing
+				m.setQuery("")
+				return m, nil
+			}
 			m.Hide()
 			return m, nil
 
@@ -107,6 +354,13 @@ func (m *BookmarksModal) Update(msg tea.Msg) (*BookmarksModal, tea.Cmd) {
 			}
 			return m, nil
 
+		case key.Matches(msg, key.NewBinding(key.WithKeys("e"))):
+			return m, m.startEditing()
+
+		case key.Matches(msg, key.NewBinding(key.WithKeys("/"))):
+			m.filtering = true
+			return m, nil
+
 		case key.Matches(msg, key.NewBinding(key.WithKeys("d", "delete"))):
 			if m.selectedIdx < len(m.bookmarks) {
 				url := m.bookmarks[m.selectedIdx].URL
@@ -118,7 +372,7 @@ func (m *BookmarksModal) Update(msg tea.Msg) (*BookmarksModal, tea.Cmd) {
 		}
 
 	case tea.MouseMsg:
-		if msg.Type == tea.MouseLeft && len(m.bookmarks) > 0 {
+		if msg.Type == tea.MouseLeft && len(m.bookmarks) > 0 && !m.editing {
 			// Calculate modal position and dimensions
 			modalWidth := m.width - 4
 			if modalWidth < 40 {
@@ -265,10 +519,20 @@ func (m *BookmarksModal) View() string {
 		Width(modalWidth)
 
 	// Build content
-	b.WriteString(titleStyle.Render(fmt.Sprintf("Bookmarks (%d)", len(m.bookmarks))))
+	title := fmt.Sprintf("Bookmarks (%d)", len(m.bookmarks))
+	if m.filtering || m.query != "" {
+		title = fmt.Sprintf("Bookmarks (%d of %d) /%s", len(m.bookmarks), len(m.all), m.query)
+		if m.filtering {
+			title += "_"
+		}
+	}
+	b.WriteString(titleStyle.Render(title))
 	b.WriteString("\n")
 
-	if len(m.bookmarks) == 0 {
+	if len(m.bookmarks) == 0 && m.query != "" {
+		b.WriteString(emptyStyle.Render("No bookmarks match"))
+		b.WriteString("\n")
+	} else if len(m.bookmarks) == 0 {
 		b.WriteString(emptyStyle.Render("No bookmarks yet"))
 		b.WriteString("\n")
 		b.WriteString(emptyStyle.Render("Press 'd' on any page to add a bookmark"))
@@ -309,12 +573,13 @@ func (m *BookmarksModal) View() string {
 				title = title[:maxTitleLen-3] + "..."
 			}
 
-			// Truncate URL if too long
+			// Truncate URL if too long. Bookmarks found by the text of
+			// their page show the matching text instead.
 			url := bookmark.URL
-			maxURLLen := modalWidth - 10
-			if len(url) > maxURLLen {
-				url = url[:maxURLLen-3] + "..."
+			if snippet, ok := m.snippets[bookmark.URL]; ok {
+				url = "“" + snippet + "”"
 			}
+			url = truncateRunes(url, modalWidth-10)
 
 			line := fmt.Sprintf("%s\n  %s", title, url)
 
@@ -338,11 +603,20 @@ func (m *BookmarksModal) View() string {
 	}
 
 	// Help text
-	helpText := "j/k: move • enter: open • d: delete • esc/q/b: close"
+	helpText := "j/k: move • enter: open • /: filter • e: edit • d: delete • esc/q/b: close"
+	if m.filtering {
+		helpText = "type to filter • enter: done • esc: clear"
+	}
 	b.WriteString(helpStyle.Render(helpText))
 
+	// Swap in the edit form when editing a bookmark
+	body := b.String()
+	if m.editing {
+		body = m.editFormView(modalWidth, titleStyle, helpStyle)
+	}
+
 	// Wrap in border
-	content := borderStyle.Render(b.String())
+	content := borderStyle.Render(body)
 
 	// Center the modal
 	contentHeight := strings.Count(content, "\n") + 1
@@ -366,3 +640,34 @@ func (m *BookmarksModal) View() string {
 
 	return result
 }
+
+// editFormView renders the inline bookmark edit form
+func (m *BookmarksModal) editFormView(modalWidth int, titleStyle, helpStyle lipgloss.Style) string {
+	labelStyle := lipgloss.NewStyle().
+		Foreground(lipgloss.Color("8")).
+		Bold(true)
+
+	activeLabelStyle := lipgloss.NewStyle().
+		Foreground(lipgloss.Color("12")).
+		Bold(true)
+
+	var b strings.Builder
+	b.WriteString(titleStyle.Render("Edit Bookmark"))
+	b.WriteString("\n")
+
+	labels := []string{"Title", "URL", "Tags (comma-separated)"}
+	for i, label := range labels {
+		m.editInputs[i].Width = modalWidth - 12
+		if i == m.editFocus {
+			b.WriteString(activeLabelStyle.Render(label))
+		} else {
+			b.WriteString(labelStyle.Render(label))
+		}
+		b.WriteString("\n")
+		b.WriteString(m.editInputs[i].View())
+		b.WriteString("\n\n")
+	}
+
+	b.WriteString(helpStyle.Render("tab: next field • enter: save • esc: cancel"))
+	return b.String()
+}
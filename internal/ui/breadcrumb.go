@@ -0,0 +1,134 @@
+package ui
+
+import (
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"starsearch/internal/urlutil"
+)
+
+// Breadcrumb displays the current URL path as clickable segments
+type Breadcrumb struct {
+	url    string
+	width  int
+	crumbs []urlutil.Crumb
+	bounds []crumbBound // Clickable regions from the last render
+}
+
+// crumbBound represents the clickable region of a rendered crumb
+type crumbBound struct {
+	startX int
+	endX   int
+	url    string
+}
+
+// NewBreadcrumb creates a new breadcrumb bar
+func NewBreadcrumb() *Breadcrumb {
+	return &Breadcrumb{
+		width: 80,
+	}
+}
+
+// SetURL sets the URL to split into crumbs
+func (b *Breadcrumb) SetURL(url string) {
+	if url == b.url {
+		return
+	}
+	b.url = url
+	b.crumbs, _ = urlutil.Crumbs(url) // Unparseable URLs render as an empty bar
+}
+
+// SetWidth sets the breadcrumb bar width
+func (b *Breadcrumb) SetWidth(width int) {
+	b.width = width
+}
+
+// Update handles clicks on crumbs
+func (b *Breadcrumb) Update(msg tea.Msg) (*Breadcrumb, tea.Cmd) {
+	switch msg := msg.(type) {
+	case tea.MouseMsg:
+		if msg.Action == tea.MouseActionPress && msg.Button == tea.MouseButtonLeft {
+			for _, bound := range b.bounds {
+				if msg.X >= bound.startX && msg.X < bound.endX {
+					url := bound.url
+					return b, func() tea.Msg { return NavigateMsg{URL: url} }
+				}
+			}
+		}
+	}
+
+	return b, nil
+}
+
+// View renders the breadcrumb bar
+func (b *Breadcrumb) View() string {
+	crumbStyle := lipgloss.NewStyle().
+		Foreground(lipgloss.Color("12"))
+
+	currentStyle := lipgloss.NewStyle().
+		Foreground(lipgloss.Color("15")).
+		Bold(true)
+
+	separatorStyle := lipgloss.NewStyle().
+		Foreground(lipgloss.Color("8"))
+
+	barStyle := lipgloss.NewStyle().
+		Width(b.width).
+		MaxWidth(b.width)
+
+	b.bounds = b.bounds[:0]
+	if len(b.crumbs) == 0 {
+		return barStyle.Render("")
+	}
+
+	// Elide middle segments until the bar fits, always keeping host and current page
+	const separator = " / "
+	const ellipsis = "…"
+	visible := append([]urlutil.Crumb(nil), b.crumbs...)
+	elided := false
+	for len(visible) > 2 && crumbsWidth(visible, elided, len(separator)) > b.width-1 {
+		visible = append(visible[:1], visible[2:]...)
+		elided = true
+	}
+
+	var out strings.Builder
+	x := 1
+	out.WriteString(" ")
+	for i, crumb := range visible {
+		if i > 0 {
+			out.WriteString(separatorStyle.Render(separator))
+			x += len(separator)
+		}
+		if i == 1 && elided {
+			out.WriteString(separatorStyle.Render(ellipsis + separator))
+			x += lipgloss.Width(ellipsis) + len(separator)
+		}
+
+		width := lipgloss.Width(crumb.Label)
+		if i == len(visible)-1 {
+			out.WriteString(currentStyle.Render(crumb.Label))
+		} else {
+			out.WriteString(crumbStyle.Render(crumb.Label))
+		}
+		b.bounds = append(b.bounds, crumbBound{startX: x, endX: x + width, url: crumb.URL})
+		x += width
+	}
+
+	return barStyle.Render(out.String())
+}
+
+// crumbsWidth returns the rendered width of a crumb list
+func crumbsWidth(crumbs []urlutil.Crumb, elided bool, separatorWidth int) int {
+	width := 1
+	for i, crumb := range crumbs {
+		if i > 0 {
+			width += separatorWidth
+		}
+		width += lipgloss.Width(crumb.Label)
+	}
+	if elided {
+		width += 1 + separatorWidth
+	}
+	return width
+}
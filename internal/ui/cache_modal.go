@@ -0,0 +1,308 @@
+package ui
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/charmbracelet/bubbles/key"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"starsearch/internal/types"
+)
+
+// CacheModal lists the page cache's entries for inspection and cleanup
+type CacheModal struct {
+	visible      bool
+	entries      []types.CacheEntryInfo
+	used         int64
+	max          int64
+	selectedIdx  int
+	width        int
+	height       int
+	scrollOffset int
+}
+
+// CacheOpenMsg is sent to open the cached copy of a page
+type CacheOpenMsg struct {
+	URL string
+}
+
+// CacheInvalidateMsg is sent to drop one entry from the cache
+type CacheInvalidateMsg struct {
+	URL string
+}
+
+// CacheClearMsg is sent to empty the cache
+type CacheClearMsg struct{}
+
+func NewCacheModal() *CacheModal {
+	return &CacheModal{}
+}
+
+// Show opens the modal with the cache's entries and its size and cap
+func (m *CacheModal) Show(entries []types.CacheEntryInfo, used, max int64) {
+	m.visible = true
+	m.selectedIdx = 0
+	m.scrollOffset = 0
+	m.Refresh(entries, used, max)
+}
+
+// Refresh replaces the entries while keeping the selection near its
+// previous position
+func (m *CacheModal) Refresh(entries []types.CacheEntryInfo, used, max int64) {
+	m.entries = entries
+	m.used = used
+	m.max = max
+	if m.selectedIdx >= len(m.entries) {
+		m.selectedIdx = len(m.entries) - 1
+	}
+	if m.selectedIdx < 0 {
+		m.selectedIdx = 0
+	}
+	m.adjustScroll()
+}
+
+func (m *CacheModal) Hide() {
+	m.visible = false
+}
+
+func (m *CacheModal) IsVisible() bool {
+	return m.visible
+}
+
+func (m *CacheModal) SetSize(width, height int) {
+	m.width = width
+	m.height = height
+}
+
+func (m *CacheModal) Update(msg tea.Msg) (*CacheModal, tea.Cmd) {
+	keyMsg, ok := msg.(tea.KeyMsg)
+	if !m.visible || !ok {
+		return m, nil
+	}
+
+	switch {
+	case key.Matches(keyMsg, key.NewBinding(key.WithKeys("esc", "C"))):
+		m.Hide()
+
+	case key.Matches(keyMsg, key.NewBinding(key.WithKeys("j", "down"))):
+		if m.selectedIdx < len(m.entries)-1 {
+			m.selectedIdx++
+			m.adjustScroll()
+		}
+
+	case key.Matches(keyMsg, key.NewBinding(key.WithKeys("k", "up"))):
+		if m.selectedIdx > 0 {
+			m.selectedIdx--
+			m.adjustScroll()
+		}
+
+	case key.Matches(keyMsg, key.NewBinding(key.WithKeys("enter"))):
+		if m.selectedIdx < len(m.entries) {
+			url := m.entries[m.selectedIdx].URL
+			m.Hide()
+			return m, func() tea.Msg { return CacheOpenMsg{URL: url} }
+		}
+
+	case key.Matches(keyMsg, key.NewBinding(key.WithKeys("d", "delete"))):
+		if m.selectedIdx < len(m.entries) {
+			url := m.entries[m.selectedIdx].URL
+			return m, func() tea.Msg { return CacheInvalidateMsg{URL: url} }
+		}
+
+	case key.Matches(keyMsg, key.NewBinding(key.WithKeys("X"))):
+		return m, func() tea.Msg { return CacheClearMsg{} }
+	}
+
+	return m, nil
+}
+
+func (m *CacheModal) adjustScroll() {
+	visibleHeight := m.height - 10
+	if visibleHeight < 1 {
+		visibleHeight = 1
+	}
+
+	if m.selectedIdx >= m.scrollOffset+visibleHeight {
+		m.scrollOffset = m.selectedIdx - visibleHeight + 1
+	}
+	if m.selectedIdx < m.scrollOffset {
+		m.scrollOffset = m.selectedIdx
+	}
+}
+
+// cacheEntryKind describes what a cached response is, when not a page
+func cacheEntryKind(entry types.CacheEntryInfo) string {
+	switch entry.Status {
+	case 31:
+		return "→ " + entry.Meta
+	case 51:
+		return "not found"
+	default:
+		return ""
+	}
+}
+
+// formatAge renders a duration in its largest whole unit
+func formatAge(d time.Duration) string {
+	switch {
+	case d < time.Minute:
+		return fmt.Sprintf("%ds", int(d.Seconds()))
+	case d < time.Hour:
+		return fmt.Sprintf("%dm", int(d.Minutes()))
+	case d < 24*time.Hour:
+		return fmt.Sprintf("%dh", int(d.Hours()))
+	default:
+		return fmt.Sprintf("%dd", int(d.Hours()/24))
+	}
+}
+
+// FormatBytes renders a byte count with a binary unit, e.g. "1.4 MB"
+func FormatBytes(bytes int64) string {
+	const unit = 1024
+	if bytes < unit {
+		return fmt.Sprintf("%d B", bytes)
+	}
+	div, exp := int64(unit), 0
+	for n := bytes / unit; n >= unit; n /= unit {
+		div *= unit
+		exp++
+	}
+	return fmt.Sprintf("%.1f %cB", float64(bytes)/float64(div), "KMGTPE"[exp])
+}
+
+func (m *CacheModal) View() string {
+	if !m.visible {
+		return ""
+	}
+
+	modalWidth := m.width - 4
+	if modalWidth < 50 {
+		modalWidth = 50
+	}
+	if modalWidth > 110 {
+		modalWidth = 110
+	}
+
+	titleStyle := lipgloss.NewStyle().
+		Bold(true).
+		Foreground(lipgloss.Color("12")).
+		Width(modalWidth).
+		Align(lipgloss.Center).
+		MarginBottom(1)
+
+	headerStyle := lipgloss.NewStyle().
+		Foreground(lipgloss.Color("8")).
+		Bold(true)
+
+	selectedStyle := lipgloss.NewStyle().
+		Background(lipgloss.Color("12")).
+		Foreground(lipgloss.Color("0")).
+		Bold(true).
+		Width(modalWidth - 4)
+
+	normalStyle := lipgloss.NewStyle().
+		Foreground(lipgloss.Color("15")).
+		Width(modalWidth - 4)
+
+	expiredStyle := lipgloss.NewStyle().
+		Foreground(lipgloss.Color("8")).
+		Width(modalWidth - 4)
+
+	emptyStyle := lipgloss.NewStyle().
+		Foreground(lipgloss.Color("8")).
+		Italic(true).
+		Width(modalWidth).
+		Align(lipgloss.Center).
+		MarginTop(1).
+		MarginBottom(1)
+
+	helpStyle := lipgloss.NewStyle().
+		Foreground(lipgloss.Color("7")).
+		Width(modalWidth).
+		Align(lipgloss.Center).
+		MarginTop(1)
+
+	borderStyle := lipgloss.NewStyle().
+		Border(lipgloss.RoundedBorder()).
+		BorderForeground(lipgloss.Color("12")).
+		Padding(1, 2).
+		Width(modalWidth)
+
+	var b strings.Builder
+	b.WriteString(titleStyle.Render(fmt.Sprintf("Cache (%d entries, %s of %s)", len(m.entries), FormatBytes(m.used), FormatBytes(m.max))))
+	b.WriteString("\n")
+
+	// URL column takes what the size, age and TTL columns leave
+	urlWidth := modalWidth - 4 - 3*10
+	if len(m.entries) == 0 {
+		b.WriteString(emptyStyle.Render("The cache is empty"))
+		b.WriteString("\n")
+	} else {
+		b.WriteString(headerStyle.Render(fmt.Sprintf("%-*s%10s%10s%10s", urlWidth, "URL", "Size", "Age", "TTL left")))
+		b.WriteString("\n")
+
+		visibleHeight := m.height - 10
+		if visibleHeight < 1 {
+			visibleHeight = 1
+		}
+		endIdx := m.scrollOffset + visibleHeight
+		if endIdx > len(m.entries) {
+			endIdx = len(m.entries)
+		}
+
+		now := time.Now()
+		for i := m.scrollOffset; i < endIdx; i++ {
+			entry := m.entries[i]
+
+			url := entry.URL
+			if kind := cacheEntryKind(entry); kind != "" {
+				url += " (" + kind + ")"
+			}
+			if len(url) > urlWidth-1 {
+				url = url[:urlWidth-4] + "..."
+			}
+			ttlLeft := "expired"
+			if !entry.Expired(now) {
+				ttlLeft = formatAge(entry.Stored.Add(entry.TTL).Sub(now))
+			}
+			line := fmt.Sprintf("%-*s%10s%10s%10s", urlWidth, url, FormatBytes(entry.Size), formatAge(now.Sub(entry.Stored)), ttlLeft)
+
+			switch {
+			case i == m.selectedIdx:
+				b.WriteString(selectedStyle.Render(line))
+			case entry.Expired(now):
+				b.WriteString(expiredStyle.Render(line))
+			default:
+				b.WriteString(normalStyle.Render(line))
+			}
+			b.WriteString("\n")
+		}
+	}
+
+	b.WriteString(helpStyle.Render("j/k: move • enter: open cached copy • d: invalidate • X: clear all • esc/q: close"))
+
+	content := borderStyle.Render(b.String())
+
+	// Center the modal
+	contentHeight := strings.Count(content, "\n") + 1
+	contentWidth := modalWidth + 6 // Account for border and padding
+
+	topPadding := (m.height - contentHeight) / 2
+	if topPadding < 0 {
+		topPadding = 0
+	}
+
+	leftPadding := (m.width - contentWidth) / 2
+	if leftPadding < 0 {
+		leftPadding = 0
+	}
+
+	result := strings.Repeat("\n", topPadding)
+	for _, line := range strings.Split(content, "\n") {
+		result += strings.Repeat(" ", leftPadding) + line + "\n"
+	}
+
+	return result
+}
@@ -0,0 +1,309 @@
+package ui
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/charmbracelet/bubbles/key"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"starsearch/internal/types"
+)
+
+// certExpiryWarning is how close to its expiry a trusted certificate is flagged
+const certExpiryWarning = 30 * 24 * time.Hour
+
+// CertExpiryModal lists the certificates trusted on first use by expiry
+// date, soonest first, so capsules about to rotate their certificate are
+// known before the change is flagged
+type CertExpiryModal struct {
+	visible      bool
+	certs        []types.CertificateInfo
+	selectedIdx  int
+	width        int
+	height       int
+	scrollOffset int
+}
+
+// CertForgetMsg is sent to forget the certificate trusted for a host
+type CertForgetMsg struct {
+	Host string
+}
+
+// CertVerifyMsg is sent to connect to a host again and check the
+// certificate it presents against the trusted one
+type CertVerifyMsg struct {
+	Host string
+}
+
+func NewCertExpiryModal() *CertExpiryModal {
+	return &CertExpiryModal{}
+}
+
+// Show opens the modal with certificates sorted soonest expiry first
+func (m *CertExpiryModal) Show(certs []types.CertificateInfo) {
+	m.visible = true
+	m.selectedIdx = 0
+	m.scrollOffset = 0
+	m.Refresh(certs)
+}
+
+// Refresh replaces the list while keeping the selection on the same host
+// if it is still listed
+func (m *CertExpiryModal) Refresh(certs []types.CertificateInfo) {
+	var selected string
+	if m.selectedIdx < len(m.certs) {
+		selected = m.certs[m.selectedIdx].Host
+	}
+	m.certs = certs
+	for i, cert := range certs {
+		if cert.Host == selected {
+			m.selectedIdx = i
+		}
+	}
+	if m.selectedIdx >= len(m.certs) {
+		m.selectedIdx = len(m.certs) - 1
+	}
+	if m.selectedIdx < 0 {
+		m.selectedIdx = 0
+	}
+	m.adjustScroll()
+}
+
+func (m *CertExpiryModal) Hide() {
+	m.visible = false
+}
+
+func (m *CertExpiryModal) IsVisible() bool {
+	return m.visible
+}
+
+func (m *CertExpiryModal) SetSize(width, height int) {
+	m.width = width
+	m.height = height
+}
+
+func (m *CertExpiryModal) Update(msg tea.Msg) (*CertExpiryModal, tea.Cmd) {
+	keyMsg, ok := msg.(tea.KeyMsg)
+	if !m.visible || !ok {
+		return m, nil
+	}
+
+	switch {
+	case key.Matches(keyMsg, key.NewBinding(key.WithKeys("esc", "q", "T"))):
+		m.Hide()
+
+	case key.Matches(keyMsg, key.NewBinding(key.WithKeys("j", "down"))):
+		if m.selectedIdx < len(m.certs)-1 {
+			m.selectedIdx++
+			m.adjustScroll()
+		}
+
+	case key.Matches(keyMsg, key.NewBinding(key.WithKeys("k", "up"))):
+		if m.selectedIdx > 0 {
+			m.selectedIdx--
+			m.adjustScroll()
+		}
+
+	case key.Matches(keyMsg, key.NewBinding(key.WithKeys("d", "delete"))):
+		if m.selectedIdx < len(m.certs) {
+			host := m.certs[m.selectedIdx].Host
+			return m, func() tea.Msg { return CertForgetMsg{Host: host} }
+		}
+
+	case key.Matches(keyMsg, key.NewBinding(key.WithKeys("r", "enter"))):
+		if m.selectedIdx < len(m.certs) {
+			host := m.certs[m.selectedIdx].Host
+			return m, func() tea.Msg { return CertVerifyMsg{Host: host} }
+		}
+	}
+
+	return m, nil
+}
+
+func (m *CertExpiryModal) adjustScroll() {
+	visibleHeight := m.height - 12
+	if visibleHeight < 1 {
+		visibleHeight = 1
+	}
+
+	if m.selectedIdx >= m.scrollOffset+visibleHeight {
+		m.scrollOffset = m.selectedIdx - visibleHeight + 1
+	}
+	if m.selectedIdx < m.scrollOffset {
+		m.scrollOffset = m.selectedIdx
+	}
+}
+
+// timeToExpiry says how long a certificate has left, or since when it has
+// been expired
+func timeToExpiry(notAfter, now time.Time) string {
+	switch {
+	case notAfter.IsZero():
+		return "unknown"
+	case now.After(notAfter):
+		return "expired " + formatAge(now.Sub(notAfter)) + " ago"
+	default:
+		return "in " + formatAge(notAfter.Sub(now))
+	}
+}
+
+func (m *CertExpiryModal) View() string {
+	if !m.visible {
+		return ""
+	}
+
+	modalWidth := m.width - 4
+	if modalWidth < 60 {
+		modalWidth = 60
+	}
+	if modalWidth > 100 {
+		modalWidth = 100
+	}
+
+	titleStyle := lipgloss.NewStyle().
+		Bold(true).
+		Foreground(lipgloss.Color("12")).
+		Width(modalWidth).
+		Align(lipgloss.Center).
+		MarginBottom(1)
+
+	infoStyle := lipgloss.NewStyle().
+		Foreground(lipgloss.Color("7")).
+		Width(modalWidth - 4).
+		MarginBottom(1)
+
+	headerStyle := lipgloss.NewStyle().
+		Foreground(lipgloss.Color("8")).
+		Bold(true)
+
+	selectedStyle := lipgloss.NewStyle().
+		Background(lipgloss.Color("12")).
+		Foreground(lipgloss.Color("0")).
+		Bold(true).
+		Width(modalWidth - 4)
+
+	normalStyle := lipgloss.NewStyle().
+		Foreground(lipgloss.Color("15")).
+		Width(modalWidth - 4)
+
+	expiredStyle := normalStyle.
+		Foreground(lipgloss.Color("9")).
+		Bold(true)
+
+	expiringStyle := normalStyle.
+		Foreground(lipgloss.Color("11"))
+
+	emptyStyle := lipgloss.NewStyle().
+		Foreground(lipgloss.Color("8")).
+		Italic(true).
+		Width(modalWidth).
+		Align(lipgloss.Center).
+		MarginTop(1).
+		MarginBottom(1)
+
+	helpStyle := lipgloss.NewStyle().
+		Foreground(lipgloss.Color("7")).
+		Width(modalWidth).
+		Align(lipgloss.Center).
+		MarginTop(1)
+
+	borderStyle := lipgloss.NewStyle().
+		Border(lipgloss.RoundedBorder()).
+		BorderForeground(lipgloss.Color("12")).
+		Padding(1, 2).
+		Width(modalWidth)
+
+	now := time.Now()
+	expired, expiring := 0, 0
+	for _, cert := range m.certs {
+		switch {
+		case cert.NotAfter.IsZero():
+		case now.After(cert.NotAfter):
+			expired++
+		case cert.NotAfter.Sub(now) < certExpiryWarning:
+			expiring++
+		}
+	}
+
+	var b strings.Builder
+	b.WriteString(titleStyle.Render(fmt.Sprintf("Certificate expiry (%d hosts)", len(m.certs))))
+	b.WriteString("\n")
+	b.WriteString(infoStyle.Render(fmt.Sprintf("%d expired, %d expiring within 30 days. Capsules usually renew with a new self-signed certificate, which is then flagged as changed; re-verify to check what a host presents now.", expired, expiring)))
+	b.WriteString("\n")
+
+	// Host column takes what the fixed columns leave
+	dateWidth, leftWidth, seenWidth := 12, 16, 8
+	hostWidth := modalWidth - 4 - dateWidth - leftWidth - seenWidth
+	if len(m.certs) == 0 {
+		b.WriteString(emptyStyle.Render("No certificates trusted yet"))
+		b.WriteString("\n")
+	} else {
+		b.WriteString(headerStyle.Render(fmt.Sprintf("%-*s%-*s%-*s%*s",
+			hostWidth, "Host", dateWidth, "Expires", leftWidth, "", seenWidth, "Seen")))
+		b.WriteString("\n")
+
+		visibleHeight := m.height - 12
+		if visibleHeight < 1 {
+			visibleHeight = 1
+		}
+		endIdx := m.scrollOffset + visibleHeight
+		if endIdx > len(m.certs) {
+			endIdx = len(m.certs)
+		}
+
+		for i := m.scrollOffset; i < endIdx; i++ {
+			cert := m.certs[i]
+
+			date, seen := "-", "-"
+			if !cert.NotAfter.IsZero() {
+				date = cert.NotAfter.Format("2006-01-02")
+			}
+			if !cert.LastSeen.IsZero() {
+				seen = formatAge(now.Sub(cert.LastSeen))
+			}
+			line := fmt.Sprintf("%-*s%-*s%-*s%*s",
+				hostWidth, truncateRunes(cert.Host, hostWidth-1),
+				dateWidth, date, leftWidth, timeToExpiry(cert.NotAfter, now), seenWidth, seen)
+
+			style := normalStyle
+			switch {
+			case i == m.selectedIdx:
+				style = selectedStyle
+			case cert.NotAfter.IsZero():
+			case now.After(cert.NotAfter):
+				style = expiredStyle
+			case cert.NotAfter.Sub(now) < certExpiryWarning:
+				style = expiringStyle
+			}
+			b.WriteString(style.Render(line))
+			b.WriteString("\n")
+		}
+	}
+
+	b.WriteString(helpStyle.Render("j/k: move • r/enter: re-verify • d: forget • esc/q: close"))
+
+	content := borderStyle.Render(b.String())
+
+	// Center the modal
+	contentHeight := strings.Count(content, "\n") + 1
+	contentWidth := modalWidth + 6 // Account for border and padding
+
+	topPadding := (m.height - contentHeight) / 2
+	if topPadding < 0 {
+		topPadding = 0
+	}
+
+	leftPadding := (m.width - contentWidth) / 2
+	if leftPadding < 0 {
+		leftPadding = 0
+	}
+
+	result := strings.Repeat("\n", topPadding)
+	for _, line := range strings.Split(content, "\n") {
+		result += strings.Repeat(" ", leftPadding) + line + "\n"
+	}
+
+	return result
+}
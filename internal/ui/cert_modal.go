@@ -11,6 +11,9 @@ import (
 	"starsearch/internal/types"
 )
 
+// certificateLines is how many lines one certificate takes in the list
+const certificateLines = 8
+
 // CertificateModal displays certificate information and management
 type CertificateModal struct {
 	visible      bool
@@ -51,6 +54,28 @@ func (m *CertificateModal) Show(certificates []types.CertificateInfo) tea.Cmd {
 	return nil
 }
 
+// Refresh replaces the list while keeping the selection on the same
+// certificate if it is still listed
+func (m *CertificateModal) Refresh(certificates []types.CertificateInfo) {
+	var selected types.CertificateInfo
+	if m.selectedIdx < len(m.certificates) {
+		selected = m.certificates[m.selectedIdx]
+	}
+	m.certificates = certificates
+	for i, cert := range certificates {
+		if cert.Host == selected.Host && cert.Trusted == selected.Trusted {
+			m.selectedIdx = i
+		}
+	}
+	if m.selectedIdx >= len(m.certificates) {
+		m.selectedIdx = len(m.certificates) - 1
+	}
+	if m.selectedIdx < 0 {
+		m.selectedIdx = 0
+	}
+	m.adjustScroll()
+}
+
 func (m *CertificateModal) Hide() {
 	m.visible = false
 }
@@ -125,12 +150,18 @@ func (m *CertificateModal) Update(msg tea.Msg) (*CertificateModal, tea.Cmd) {
 	return m, nil
 }
 
-func (m *CertificateModal) adjustScroll() {
-	// Calculate visible area (leave space for header and help text)
-	visibleHeight := m.height - 8
-	if visibleHeight < 1 {
-		visibleHeight = 1
+// visibleCount is how many certificates fit on screen, leaving space for
+// the header and help text
+func (m *CertificateModal) visibleCount() int {
+	count := (m.height - 12) / certificateLines
+	if count < 1 {
+		count = 1
 	}
+	return count
+}
+
+func (m *CertificateModal) adjustScroll() {
+	visibleHeight := m.visibleCount()
 
 	// Scroll down if selected item is below visible area
 	if m.selectedIdx >= m.scrollOffset+visibleHeight {
@@ -243,10 +274,7 @@ func (m *CertificateModal) View() string {
 		b.WriteString("\n")
 	} else {
 		// Calculate visible range
-		visibleHeight := modalHeight - 8
-		if visibleHeight < 1 {
-			visibleHeight = 1
-		}
+		visibleHeight := m.visibleCount()
 
 		endIdx := m.scrollOffset + visibleHeight
 		if endIdx > len(m.certificates) {
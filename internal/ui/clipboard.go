@@ -0,0 +1,51 @@
+package ui
+
+import (
+	"sync"
+
+	"github.com/atotto/clipboard"
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// clipboardMu serialises clipboard access, as reading the primary
+// selection flips the clipboard package's global Primary switch
+var clipboardMu sync.Mutex
+
+// PasteMsg carries text read from the clipboard or primary selection
+type PasteMsg struct {
+	Text string
+	Go   bool // Navigate to the text immediately instead of editing it
+	Err  error
+}
+
+// WriteClipboard copies text to the system clipboard
+func WriteClipboard(text string) error {
+	clipboardMu.Lock()
+	defer clipboardMu.Unlock()
+	return clipboard.WriteAll(text)
+}
+
+// readClipboard reads the system clipboard
+func readClipboard() (string, error) {
+	clipboardMu.Lock()
+	defer clipboardMu.Unlock()
+	return clipboard.ReadAll()
+}
+
+// ReadClipboard returns a command that reads the system clipboard. With
+// goNow set, the pasted text is meant to be navigated to straight away.
+func ReadClipboard(goNow bool) tea.Cmd {
+	return func() tea.Msg {
+		text, err := readClipboard()
+		return PasteMsg{Text: text, Go: goNow, Err: err}
+	}
+}
+
+// ReadPrimarySelection returns a command that reads the X11/Wayland primary
+// selection, falling back to the clipboard where there is none
+func ReadPrimarySelection() tea.Cmd {
+	return func() tea.Msg {
+		text, err := readPrimary()
+		return PasteMsg{Text: text, Err: err}
+	}
+}
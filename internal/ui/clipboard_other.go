@@ -0,0 +1,8 @@
+//go:build !(freebsd || linux || netbsd || openbsd || solaris || dragonfly)
+
+package ui
+
+// readPrimary reads the clipboard, as this platform has no primary selection
+func readPrimary() (string, error) {
+	return readClipboard()
+}
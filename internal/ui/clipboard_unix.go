@@ -0,0 +1,15 @@
+//go:build freebsd || linux || netbsd || openbsd || solaris || dragonfly
+
+package ui
+
+import "github.com/atotto/clipboard"
+
+// readPrimary reads the primary selection
+func readPrimary() (string, error) {
+	clipboardMu.Lock()
+	defer clipboardMu.Unlock()
+
+	clipboard.Primary = true
+	defer func() { clipboard.Primary = false }()
+	return clipboard.ReadAll()
+}
@@ -0,0 +1,157 @@
+package ui
+
+import (
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"starsearch/internal/textutil"
+)
+
+// ConfirmModal asks a yes/no question before a destructive or unusual
+// action. It is drawn over whatever lies beneath it. "No" is selected
+// initially, so a stray Enter changes nothing.
+type ConfirmModal struct {
+	visible bool
+	title   string
+	detail  string
+	yes     string  // Label of the confirming choice
+	confirm tea.Msg // Sent when the user confirms
+	onYes   bool    // Whether the confirming choice is selected
+	width   int
+	height  int
+}
+
+// NewConfirmModal creates a new confirmation dialog
+func NewConfirmModal() *ConfirmModal {
+	return &ConfirmModal{}
+}
+
+// SetSize sets the dimensions of the screen the dialog is centered on
+func (m *ConfirmModal) SetSize(width, height int) {
+	m.width = width
+	m.height = height
+}
+
+// Show asks title, with detail explaining what confirming does. yes labels
+// the confirming choice; confirm is sent if the user picks it.
+func (m *ConfirmModal) Show(title, detail, yes string, confirm tea.Msg) {
+	m.visible = true
+	m.title = textutil.Sanitize(title)
+	m.detail = textutil.SanitizeText(detail, false) // May quote a server's reply
+	m.yes = yes
+	m.confirm = confirm
+	m.onYes = false
+}
+
+// Hide closes the dialog without confirming
+func (m *ConfirmModal) Hide() {
+	m.visible = false
+	m.confirm = nil
+}
+
+// IsVisible returns whether the dialog is visible
+func (m *ConfirmModal) IsVisible() bool {
+	return m.visible
+}
+
+// Update handles the dialog's keys: y confirms, n and Esc decline, and
+// Enter takes the selected choice
+func (m *ConfirmModal) Update(msg tea.Msg) (*ConfirmModal, tea.Cmd) {
+	keyMsg, ok := msg.(tea.KeyMsg)
+	if !m.visible || !ok {
+		return m, nil
+	}
+
+	switch keyMsg.String() {
+	case "y", "Y":
+		return m, m.answer(true)
+	case "n", "N", "esc":
+		return m, m.answer(false)
+	case "enter":
+		return m, m.answer(m.onYes)
+	case "left", "right", "tab", "shift+tab", "h", "l":
+		m.onYes = !m.onYes
+	}
+	return m, nil
+}
+
+// answer closes the dialog, sending the confirmation if confirmed
+func (m *ConfirmModal) answer(confirmed bool) tea.Cmd {
+	confirm := m.confirm
+	m.Hide()
+	if !confirmed || confirm == nil {
+		return nil
+	}
+	return func() tea.Msg { return confirm }
+}
+
+// View renders the dialog over a blank screen
+func (m *ConfirmModal) View() string {
+	return m.Overlay("")
+}
+
+// Overlay renders the dialog centered over background
+func (m *ConfirmModal) Overlay(background string) string {
+	if !m.visible {
+		return background
+	}
+
+	modalWidth := min(m.width-4, 60)
+	if modalWidth < 30 {
+		modalWidth = 30
+	}
+
+	titleStyle := lipgloss.NewStyle().
+		Bold(true).
+		Foreground(lipgloss.Color("11")).
+		Width(modalWidth - 4).
+		Align(lipgloss.Center).
+		MarginBottom(1)
+
+	detailStyle := lipgloss.NewStyle().
+		Foreground(lipgloss.Color("7")).
+		Width(modalWidth - 4).
+		MarginBottom(1)
+
+	selectedStyle := lipgloss.NewStyle().
+		Background(lipgloss.Color("11")).
+		Foreground(lipgloss.Color("0")).
+		Bold(true).
+		Padding(0, 2)
+
+	normalStyle := lipgloss.NewStyle().
+		Foreground(lipgloss.Color("15")).
+		Padding(0, 2)
+
+	helpStyle := lipgloss.NewStyle().
+		Foreground(lipgloss.Color("8")).
+		Width(modalWidth - 4).
+		Align(lipgloss.Center).
+		MarginTop(1)
+
+	borderStyle := lipgloss.NewStyle().
+		Border(lipgloss.RoundedBorder()).
+		BorderForeground(lipgloss.Color("11")).
+		Padding(1, 2).
+		Width(modalWidth)
+
+	yes, no := normalStyle.Render(m.yes), selectedStyle.Render("Cancel")
+	if m.onYes {
+		yes, no = selectedStyle.Render(m.yes), normalStyle.Render("Cancel")
+	}
+	buttons := lipgloss.PlaceHorizontal(modalWidth-4, lipgloss.Center, yes+"   "+no)
+
+	var b strings.Builder
+	b.WriteString(titleStyle.Render(m.title))
+	b.WriteString("\n")
+	if m.detail != "" {
+		b.WriteString(detailStyle.Render(m.detail))
+		b.WriteString("\n")
+	}
+	b.WriteString(buttons)
+	b.WriteString("\n")
+	b.WriteString(helpStyle.Render("y: " + strings.ToLower(m.yes) + " • n/esc: cancel • ←/→: choose"))
+
+	return overlay(background, borderStyle.Render(b.String()), m.width, m.height)
+}
@@ -44,6 +44,11 @@ type DownloadCancelMsg struct {
 	ID string
 }
 
+// DownloadRetryMsg is sent to start a failed or cancelled download again
+type DownloadRetryMsg struct {
+	ID string
+}
+
 // DownloadCloseMsg is sent when download modal is closed
 type DownloadCloseMsg struct{}
 
@@ -71,6 +76,28 @@ func (m *DownloadModal) Show(downloads []types.Download) tea.Cmd {
 	return nil
 }
 
+// Refresh replaces the list while keeping the selection on the same
+// download if it is still listed
+func (m *DownloadModal) Refresh(downloads []types.Download) {
+	var selected string
+	if m.selectedIdx < len(m.downloads) {
+		selected = m.downloads[m.selectedIdx].ID
+	}
+	m.downloads = downloads
+	for i, download := range downloads {
+		if download.ID == selected {
+			m.selectedIdx = i
+		}
+	}
+	if m.selectedIdx >= len(m.downloads) {
+		m.selectedIdx = len(m.downloads) - 1
+	}
+	if m.selectedIdx < 0 {
+		m.selectedIdx = 0
+	}
+	m.adjustScroll()
+}
+
 func (m *DownloadModal) Hide() {
 	m.visible = false
 }
@@ -95,7 +122,7 @@ func (m *DownloadModal) Update(msg tea.Msg) (*DownloadModal, tea.Cmd) {
 	switch msg := msg.(type) {
 	case tea.KeyMsg:
 		switch {
-		case key.Matches(msg, key.NewBinding(key.WithKeys("esc", "q", "d"))):
+		case key.Matches(msg, key.NewBinding(key.WithKeys("esc", "q", "d", "D"))):
 			m.Hide()
 			return m, func() tea.Msg {
 				return DownloadCloseMsg{}
@@ -136,9 +163,10 @@ func (m *DownloadModal) Update(msg tea.Msg) (*DownloadModal, tea.Cmd) {
 		case key.Matches(msg, key.NewBinding(key.WithKeys("r"))):
 			if m.selectedIdx < len(m.downloads) {
 				download := m.downloads[m.selectedIdx]
-				if download.Status == types.DownloadFailed {
-					// TODO: Implement retry functionality
-					return m, nil
+				if download.Status == types.DownloadFailed || download.Status == types.DownloadCancelled {
+					return m, func() tea.Msg {
+						return DownloadRetryMsg{ID: download.ID}
+					}
 				}
 			}
 		}
@@ -171,7 +199,7 @@ func (m *DownloadModal) adjustScroll() {
 	}
 }
 
-func (m *DownloadModal) formatBytes(bytes int64) string {
+func (m *DownloadModal) FormatBytes(bytes int64) string {
 	const unit = 1024
 	if bytes < unit {
 		return fmt.Sprintf("%d B", bytes)
@@ -249,11 +277,17 @@ func (m *DownloadModal) View() string {
 		Width(modalWidth)
 
 	// Build content
-	b.WriteString(titleStyle.Render(fmt.Sprintf("Downloads (%d active)", len(m.downloads))))
+	active := 0
+	for _, download := range m.downloads {
+		if download.Status == types.Downloading || download.Status == types.DownloadPending {
+			active++
+		}
+	}
+	b.WriteString(titleStyle.Render(fmt.Sprintf("Downloads (%d active)", active)))
 	b.WriteString("\n")
 
 	if len(m.downloads) == 0 {
-		b.WriteString(statusStyle.Render("No active downloads"))
+		b.WriteString(statusStyle.Render("No downloads yet"))
 		b.WriteString("\n")
 	} else {
 		// Calculate visible range
@@ -296,13 +330,20 @@ func (m *DownloadModal) View() string {
 			percentage := float64(0)
 			if download.Size > 0 {
 				percentage = float64(download.Downloaded) / float64(download.Size) * 100
+			} else if download.Status == types.DownloadCompleted {
+				percentage = 100
 			}
 
-			// Format file info
-			fileInfo := fmt.Sprintf("%s (%s/%s)", 
+			// Format file info; Gemini and Gopher servers rarely give a size
+			fileInfo := fmt.Sprintf("%s (%s)",
 				download.Filename,
-				m.formatBytes(download.Downloaded),
-				m.formatBytes(download.Size))
+				m.FormatBytes(download.Downloaded))
+			if download.Size > 0 {
+				fileInfo = fmt.Sprintf("%s (%s/%s)",
+					download.Filename,
+					m.FormatBytes(download.Downloaded),
+					m.FormatBytes(download.Size))
+			}
 
 			// Calculate speed and ETA if downloading
 			speedText := ""
@@ -311,7 +352,7 @@ func (m *DownloadModal) View() string {
 				elapsed := time.Now().Unix() - download.StartTime
 				if elapsed > 0 {
 					speed := float64(download.Downloaded) / float64(elapsed)
-					speedText = fmt.Sprintf(" @ %s/s", m.formatBytes(int64(speed)))
+					speedText = fmt.Sprintf(" @ %s/s", m.FormatBytes(int64(speed)))
 					
 					if download.Size > 0 && download.Downloaded > 0 {
 						remaining := download.Size - download.Downloaded
@@ -346,7 +387,7 @@ func (m *DownloadModal) View() string {
 	}
 
 	// Help text
-	helpText := "j/k: move • c: cancel • r: retry • esc/q/d: close"
+	helpText := "j/k: move • c: cancel • r: retry • esc/q/D: close"
 	b.WriteString(helpStyle.Render(helpText))
 
 	// Wrap in border
@@ -0,0 +1,81 @@
+package ui
+
+import (
+	"strings"
+
+	"github.com/charmbracelet/x/ansi"
+)
+
+// asciiGlyphs maps the symbols the interface draws to plain ASCII for
+// terminals and serial consoles without Unicode fonts. Replacements are
+// padded to the width of the glyph so layouts stay aligned.
+var asciiGlyphs = map[string]string{
+	// Box drawing, as used by borders and tables
+	"─": "-", "━": "-", "═": "=",
+	"│": "|", "┃": "|", "║": "|",
+	"╭": "+", "╮": "+", "╰": "+", "╯": "+",
+	"┌": "+", "┐": "+", "└": "+", "┘": "+",
+	"├": "+", "┤": "+", "┬": "+", "┴": "+", "┼": "+",
+	"╔": "+", "╗": "+", "╚": "+", "╝": "+",
+
+	// Bullets, markers and arrows
+	"•": "*", "●": "*", "★": "*", "·": ".",
+	"▶": ">", "▸": ">", "▲": "^", "▼": "v",
+	"→": ">", "←": "<", "↑": "^", "↓": "v",
+	"…": "~", "♪": "~", "×": "x", "⚠": "!", "⟳": "@", "⛁": "#",
+
+	// Half and full blocks of inline images
+	"█": "#", "▀": "#",
+
+	// Footnote markers
+	"⁰": "0", "¹": "1", "²": "2", "³": "3", "⁴": "4",
+	"⁵": "5", "⁶": "6", "⁷": "7", "⁸": "8", "⁹": "9",
+
+	// Tab icons
+	"🌐": "o", "🌍": "*", "⏳": ".", "❌": "x",
+}
+
+// asciiReplacer applies asciiGlyphs
+var asciiReplacer = func() *strings.Replacer {
+	var pairs []string
+	for glyph, plain := range asciiGlyphs {
+		if pad := ansi.StringWidth(glyph) - len(plain); pad > 0 {
+			plain += strings.Repeat(" ", pad)
+		}
+		pairs = append(pairs, glyph, plain)
+	}
+	return strings.NewReplacer(pairs...)
+}()
+
+// PlainGlyphs replaces the borders, bullets, arrows, block characters and
+// icons in a rendered screen with ASCII, keeping its layout
+func PlainGlyphs(s string) string {
+	return asciiReplacer.Replace(s)
+}
+
+// UseASCII decides from the glyphs setting ("auto", "unicode" or "ascii")
+// whether to draw plain ASCII. Automatically that is when the locale,
+// read through getenv, names a character set other than UTF-8, or the
+// terminal is one known to lack Unicode.
+func UseASCII(setting string, getenv func(string) string) bool {
+	switch setting {
+	case "ascii":
+		return true
+	case "unicode":
+		return false
+	}
+
+	switch term := getenv("TERM"); {
+	case term == "dumb", strings.HasPrefix(term, "vt1"), strings.HasPrefix(term, "vt2"):
+		return true
+	}
+
+	// The first locale variable set decides the character set
+	for _, name := range []string{"LC_ALL", "LC_CTYPE", "LANG"} {
+		if locale := getenv(name); locale != "" {
+			locale = strings.ToLower(locale)
+			return !strings.Contains(locale, "utf-8") && !strings.Contains(locale, "utf8")
+		}
+	}
+	return false
+}
@@ -3,13 +3,15 @@ package ui
 import (
 	"strings"
 
+	tea "github.com/charmbracelet/bubbletea"
 	"github.com/charmbracelet/lipgloss"
 )
 
 // HelpModal displays keyboard shortcuts and commands
 type HelpModal struct {
-	width  int
-	height int
+	width   int
+	height  int
+	visible bool
 }
 
 // NewHelpModal creates a new help modal
@@ -23,6 +25,32 @@ func (h *HelpModal) SetSize(width, height int) {
 	h.height = height
 }
 
+// Show displays the help modal
+func (h *HelpModal) Show() {
+	h.visible = true
+}
+
+// Hide hides the help modal
+func (h *HelpModal) Hide() {
+	h.visible = false
+}
+
+// IsVisible returns whether the help modal is visible
+func (h *HelpModal) IsVisible() bool {
+	return h.visible
+}
+
+// Update closes the help modal on Esc or a second "?"
+func (h *HelpModal) Update(msg tea.Msg) (*HelpModal, tea.Cmd) {
+	if keyMsg, ok := msg.(tea.KeyMsg); ok {
+		switch keyMsg.String() {
+		case "esc", "?":
+			h.Hide()
+		}
+	}
+	return h, nil
+}
+
 // View renders the help modal
 func (h *HelpModal) View() string {
 	// Define styles
@@ -65,16 +93,44 @@ func (h *HelpModal) View() string {
 	// Navigation commands
 	content.WriteString(headerStyle.Render("Navigation"))
 	content.WriteString("\n")
-	content.WriteString(keyStyle.Render("Ctrl+L") + descStyle.Render("Focus address bar"))
+	content.WriteString(keyStyle.Render("Ctrl+L") + descStyle.Render("Focus address bar (URL selected)"))
+	content.WriteString("\n")
+	content.WriteString(keyStyle.Render("Ctrl+A/E Ctrl+W/K") + descStyle.Render("Start/end, delete word/to end (address bar)"))
+	content.WriteString("\n")
+	content.WriteString(keyStyle.Render("Alt+B / Alt+F") + descStyle.Render("Word back / forward (address bar)"))
+	content.WriteString("\n")
+	content.WriteString(keyStyle.Render("Ctrl+V / Middle") + descStyle.Render("Paste URL (address bar)"))
+	content.WriteString("\n")
+	content.WriteString(keyStyle.Render("Tab / Shift+Tab") + descStyle.Render("Complete the host (address bar)"))
+	content.WriteString("\n")
+	content.WriteString(keyStyle.Render("P / Ctrl+Shift+V") + descStyle.Render("Paste and go"))
 	content.WriteString("\n")
 	content.WriteString(keyStyle.Render("G") + descStyle.Render("Enter link number mode"))
 	content.WriteString("\n")
 	content.WriteString(keyStyle.Render("0-9") + descStyle.Render("Input link number (in link mode)"))
 	content.WriteString("\n")
+	content.WriteString(keyStyle.Render("O") + descStyle.Render("Link menu for number (in link mode)"))
+	content.WriteString("\n")
+	content.WriteString(keyStyle.Render("Right-click") + descStyle.Render("Link menu: tab, external, download, copy"))
+	content.WriteString("\n")
+	content.WriteString(keyStyle.Render("Double/triple-click") + descStyle.Render("Select and copy a word / line"))
+	content.WriteString("\n")
+	content.WriteString(keyStyle.Render("G U") + descStyle.Render("Go up one level"))
+	content.WriteString("\n")
+	content.WriteString(keyStyle.Render("G Shift+U / G R") + descStyle.Render("Go to capsule root"))
+	content.WriteString("\n")
+	content.WriteString(keyStyle.Render("G D / G ?") + descStyle.Render("Discover capsules / open a random one"))
+	content.WriteString("\n")
 	content.WriteString(keyStyle.Render("Enter") + descStyle.Render("Navigate to link/URL"))
 	content.WriteString("\n")
 	content.WriteString(keyStyle.Render("R") + descStyle.Render("Reload current page"))
 	content.WriteString("\n")
+	content.WriteString(keyStyle.Render("Shift+C") + descStyle.Render("Cache browser: open, invalidate, clear"))
+	content.WriteString("\n")
+	content.WriteString(keyStyle.Render("Shift+Z") + descStyle.Render("List the last downloaded archive; extract entries"))
+	content.WriteString("\n")
+	content.WriteString(keyStyle.Render("S") + descStyle.Render("Stop playing sound and clear the queue"))
+	content.WriteString("\n")
 	content.WriteString(keyStyle.Render("H / ← / Alt+←") + descStyle.Render("Go back in history"))
 	content.WriteString("\n")
 	content.WriteString(keyStyle.Render("L / → / Alt+→") + descStyle.Render("Go forward in history"))
@@ -90,6 +146,20 @@ func (h *HelpModal) View() string {
 	content.WriteString(keyStyle.Render("PgDown / Space") + descStyle.Render("Page down"))
 	content.WriteString("\n")
 	content.WriteString(keyStyle.Render("PgUp") + descStyle.Render("Page up"))
+	content.WriteString("\n")
+	content.WriteString(keyStyle.Render("W") + descStyle.Render("Toggle wrap / truncate long lines"))
+	content.WriteString("\n")
+	content.WriteString(keyStyle.Render("< / >") + descStyle.Render("Scroll left / right (truncate mode)"))
+	content.WriteString("\n")
+	content.WriteString(keyStyle.Render("T") + descStyle.Render("Toggle drawn tables / raw text"))
+	content.WriteString("\n")
+	content.WriteString(keyStyle.Render("F") + descStyle.Render("Toggle inline / footnote links"))
+	content.WriteString("\n")
+	content.WriteString(keyStyle.Render("Z A") + descStyle.Render("Fold / unfold section at the top of the view"))
+	content.WriteString("\n")
+	content.WriteString(keyStyle.Render("Z Shift+R / Shift+M") + descStyle.Render("Expand / fold all sections"))
+	content.WriteString("\n")
+	content.WriteString(keyStyle.Render("Enter") + descStyle.Render("Expand collapsed quote on screen"))
 	content.WriteString("\n\n")
 
 	// Tabs
@@ -104,6 +174,12 @@ func (h *HelpModal) View() string {
 	content.WriteString(keyStyle.Render("Ctrl+Shift+Tab") + descStyle.Render("Previous tab"))
 	content.WriteString("\n")
 	content.WriteString(keyStyle.Render("1-9") + descStyle.Render("Switch to tab by number"))
+	content.WriteString("\n")
+	content.WriteString(keyStyle.Render("Ctrl+O") + descStyle.Render("Link list: mark links, open in background tabs"))
+	content.WriteString("\n")
+	content.WriteString(keyStyle.Render("Shift+A") + descStyle.Render("Watch page: reload every 30s / 60s / 5m / off"))
+	content.WriteString("\n")
+	content.WriteString(keyStyle.Render("Shift+E / Shift+O") + descStyle.Render("Export the tabs to a gemtext file / import one"))
 	content.WriteString("\n\n")
 
 	// Other commands
@@ -113,14 +189,58 @@ func (h *HelpModal) View() string {
 	content.WriteString("\n")
 	content.WriteString(keyStyle.Render("B") + descStyle.Render("View bookmarks"))
 	content.WriteString("\n")
+	content.WriteString(keyStyle.Render("Ctrl+H") + descStyle.Render("History: search, go back to a visit, delete"))
+	content.WriteString("\n")
+	content.WriteString(keyStyle.Render("Shift+I") + descStyle.Render("Identities: create, scope and delete client certificates"))
+	content.WriteString("\n")
+	content.WriteString(keyStyle.Render("Shift+Y") + descStyle.Render("Rotate to the next identity on this host"))
+	content.WriteString("\n")
+	content.WriteString(keyStyle.Render("Shift+X") + descStyle.Render("Send the next request without a certificate"))
+	content.WriteString("\n")
+	content.WriteString(keyStyle.Render("Shift+K") + descStyle.Render("Capsule tokens: allow this capsule, revoke tokens"))
+	content.WriteString("\n")
+	content.WriteString(keyStyle.Render("Shift+U") + descStyle.Render("Upload the page, a file or edited text (Titan)"))
+	content.WriteString("\n")
+	content.WriteString(keyStyle.Render("Ctrl+S") + descStyle.Render("Recent status messages and errors"))
+	content.WriteString("\n")
+	content.WriteString(keyStyle.Render("Ctrl+P") + descStyle.Render("Privacy report: what each host can link to you"))
+	content.WriteString("\n")
+	content.WriteString(keyStyle.Render("c") + descStyle.Render("Certificates: inspect, trust or untrust hosts"))
+	content.WriteString("\n")
+	content.WriteString(keyStyle.Render("Shift+T") + descStyle.Render("Certificate expiry: re-verify or forget hosts"))
+	content.WriteString("\n")
+	content.WriteString(keyStyle.Render("Shift+D") + descStyle.Render("Downloads: progress, cancel, retry"))
+	content.WriteString("\n")
 	content.WriteString(keyStyle.Render("Ctrl+F") + descStyle.Render("Search in page"))
 	content.WriteString("\n")
+	content.WriteString(keyStyle.Render("* / #") + descStyle.Render("Next / previous occurrence of the selected word"))
+	content.WriteString("\n")
+	content.WriteString(keyStyle.Render("| / Shift+V") + descStyle.Render("Read the page / its source in $PAGER"))
+	content.WriteString("\n")
+	content.WriteString(keyStyle.Render("Shift+W") + descStyle.Render("View the page in another program"))
+	content.WriteString("\n")
+	content.WriteString(keyStyle.Render("I") + descStyle.Render("Page info and parse warnings"))
+	content.WriteString("\n")
+	content.WriteString(keyStyle.Render("Shift+S") + descStyle.Render("Toggle strict mode (flag protocol violations)"))
+	content.WriteString("\n")
+	content.WriteString(keyStyle.Render("+ / -") + descStyle.Render("Zoom: more spacious or more compact text"))
+	content.WriteString("\n")
+	content.WriteString(keyStyle.Render("+ / - on images") + descStyle.Render("Fit window, width, height or 1:1; Shift+arrows pan"))
+	content.WriteString("\n")
+	content.WriteString(keyStyle.Render("x / m on images") + descStyle.Render("256 colours with dithering / grayscale"))
+	content.WriteString("\n")
+	content.WriteString(keyStyle.Render("F11") + descStyle.Render("Hide or show all bars (distraction-free view)"))
+	content.WriteString("\n")
+	content.WriteString(keyStyle.Render("Alt+T/A/S") + descStyle.Render("Hide or show the tab, address or status bar"))
+	content.WriteString("\n")
 	content.WriteString(keyStyle.Render("?") + descStyle.Render("Show this help"))
 	content.WriteString("\n")
-	content.WriteString(keyStyle.Render("Esc") + descStyle.Render("Exit link mode / Close help"))
+	content.WriteString(keyStyle.Render("Esc") + descStyle.Render("Exit link mode / Cancel retry / Close dialog"))
 	content.WriteString("\n")
 	content.WriteString(keyStyle.Render("Q / Ctrl+C") + descStyle.Render("Quit"))
 	content.WriteString("\n")
+	content.WriteString(keyStyle.Render("Ctrl+Z") + descStyle.Render("Suspend to the shell"))
+	content.WriteString("\n")
 
 	content.WriteString(dismissStyle.Render("\nPress Esc or Q to close this help"))
 
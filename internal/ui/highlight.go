@@ -0,0 +1,258 @@
+package ui
+
+import (
+	"strings"
+	"unicode/utf8"
+
+	"github.com/charmbracelet/lipgloss"
+)
+
+// tokenKind classifies a span of source code for highlighting
+type tokenKind int
+
+const (
+	tokenText tokenKind = iota
+	tokenKeyword
+	tokenString
+	tokenComment
+	tokenNumber
+)
+
+// codeToken is a span of source code with a single kind
+type codeToken struct {
+	kind tokenKind
+	text string
+}
+
+// codeLanguage describes just enough of a language's lexical syntax to
+// color keywords, strings, comments and numbers
+type codeLanguage struct {
+	keywords     map[string]bool
+	lineComments []string
+	blockStart   string
+	blockEnd     string
+	quotes       string
+}
+
+// words builds a keyword set from a space-separated list
+func words(list string) map[string]bool {
+	set := make(map[string]bool)
+	for _, w := range strings.Fields(list) {
+		set[w] = true
+	}
+	return set
+}
+
+var (
+	cLikeComments = []string{"//"}
+	hashComments  = []string{"#"}
+
+	langGo = &codeLanguage{
+		keywords: words("break case chan const continue default defer else fallthrough for func go goto if import interface map package range return select struct switch type var " +
+			"nil true false iota bool byte rune string error int int8 int16 int32 int64 uint uint8 uint16 uint32 uint64 uintptr float32 float64 any"),
+		lineComments: cLikeComments, blockStart: "/*", blockEnd: "*/", quotes: "\"'`",
+	}
+	langPython = &codeLanguage{
+		keywords:     words("and as assert async await break class continue def del elif else except finally for from global if import in is lambda nonlocal not or pass raise return try while with yield None True False self"),
+		lineComments: hashComments, quotes: "\"'",
+	}
+	langJavaScript = &codeLanguage{
+		keywords: words("async await break case catch class const continue debugger default delete do else export extends finally for function if import in instanceof let new of return static super switch this throw try typeof var void while yield " +
+			"null undefined true false interface type enum implements"),
+		lineComments: cLikeComments, blockStart: "/*", blockEnd: "*/", quotes: "\"'`",
+	}
+	langC = &codeLanguage{
+		keywords: words("auto break case char const continue default do double else enum extern float for goto if inline int long register return short signed sizeof static struct switch typedef union unsigned void volatile while " +
+			"bool true false NULL nullptr class namespace template typename public private protected virtual new delete this using"),
+		lineComments: cLikeComments, blockStart: "/*", blockEnd: "*/", quotes: "\"'",
+	}
+	langRust = &codeLanguage{
+		keywords: words("as async await break const continue crate dyn else enum extern false fn for if impl in let loop match mod move mut pub ref return self Self static struct super trait true type unsafe use where while " +
+			"i8 i16 i32 i64 i128 isize u8 u16 u32 u64 u128 usize f32 f64 bool char str String Option Some None Result Ok Err"),
+		lineComments: cLikeComments, blockStart: "/*", blockEnd: "*/", quotes: "\"",
+	}
+	langShell = &codeLanguage{
+		keywords:     words("if then else elif fi case esac for while until do done in function return export local readonly echo exit set unset"),
+		lineComments: hashComments, quotes: "\"'",
+	}
+	langJSON = &codeLanguage{
+		keywords: words("true false null"),
+		quotes:   "\"",
+	}
+)
+
+// codeLanguages maps alt-text language names to their syntax
+var codeLanguages = map[string]*codeLanguage{
+	"go": langGo, "golang": langGo,
+	"python": langPython, "py": langPython,
+	"javascript": langJavaScript, "js": langJavaScript, "typescript": langJavaScript, "ts": langJavaScript,
+	"c": langC, "h": langC, "cpp": langC, "c++": langC, "cc": langC,
+	"rust": langRust, "rs": langRust,
+	"sh": langShell, "bash": langShell, "shell": langShell, "zsh": langShell,
+	"json": langJSON,
+}
+
+// languageForAltText returns the language named by the first word of a
+// preformatted block's alt text, or nil if it is not recognised
+func languageForAltText(alt string) *codeLanguage {
+	fields := strings.Fields(strings.ToLower(alt))
+	if len(fields) == 0 {
+		return nil
+	}
+	return codeLanguages[strings.TrimPrefix(fields[0], "```")]
+}
+
+// tokenize splits one line of code into tokens. inBlock carries whether a
+// block comment is open across lines.
+func (l *codeLanguage) tokenize(line string, inBlock *bool) []codeToken {
+	var tokens []codeToken
+	emit := func(kind tokenKind, text string) {
+		if text == "" {
+			return
+		}
+		if n := len(tokens); n > 0 && tokens[n-1].kind == kind {
+			tokens[n-1].text += text
+			return
+		}
+		tokens = append(tokens, codeToken{kind: kind, text: text})
+	}
+
+	i := 0
+	for i < len(line) {
+		rest := line[i:]
+
+		// Inside a block comment: consume up to and including its end
+		if *inBlock {
+			end := strings.Index(rest, l.blockEnd)
+			if end < 0 {
+				emit(tokenComment, rest)
+				return tokens
+			}
+			emit(tokenComment, rest[:end+len(l.blockEnd)])
+			i += end + len(l.blockEnd)
+			*inBlock = false
+			continue
+		}
+
+		if l.blockStart != "" && strings.HasPrefix(rest, l.blockStart) {
+			*inBlock = true
+			emit(tokenComment, l.blockStart)
+			i += len(l.blockStart)
+			continue
+		}
+
+		isComment := false
+		for _, marker := range l.lineComments {
+			if strings.HasPrefix(rest, marker) {
+				isComment = true
+				break
+			}
+		}
+		if isComment {
+			emit(tokenComment, rest)
+			return tokens
+		}
+
+		c := line[i]
+		switch {
+		case strings.IndexByte(l.quotes, c) >= 0:
+			// String literal up to the matching unescaped quote or end of line
+			j := i + 1
+			for j < len(line) && line[j] != c {
+				if line[j] == '\\' {
+					j++
+				}
+				j++
+			}
+			if j < len(line) {
+				j++
+			}
+			if j > len(line) {
+				j = len(line)
+			}
+			emit(tokenString, line[i:j])
+			i = j
+
+		case isDigit(c):
+			j := i
+			for j < len(line) && (isIdentByte(line[j]) || line[j] == '.') {
+				j++
+			}
+			emit(tokenNumber, line[i:j])
+			i = j
+
+		case isIdentByte(c):
+			j := i
+			for j < len(line) && isIdentByte(line[j]) {
+				j++
+			}
+			word := line[i:j]
+			if l.keywords[word] {
+				emit(tokenKeyword, word)
+			} else {
+				emit(tokenText, word)
+			}
+			i = j
+
+		default:
+			_, size := utf8.DecodeRuneInString(rest)
+			emit(tokenText, rest[:size])
+			i += size
+		}
+	}
+
+	return tokens
+}
+
+func isDigit(c byte) bool {
+	return c >= '0' && c <= '9'
+}
+
+func isIdentByte(c byte) bool {
+	return c == '_' || isDigit(c) || (c >= 'a' && c <= 'z') || (c >= 'A' && c <= 'Z')
+}
+
+// wrapTokens splits tokens into rows no wider than width runes, breaking
+// tokens where needed. A width of zero or less disables wrapping.
+func wrapTokens(tokens []codeToken, width int) [][]codeToken {
+	if width <= 0 {
+		return [][]codeToken{tokens}
+	}
+
+	rows := [][]codeToken{nil}
+	used := 0
+	for _, tok := range tokens {
+		text := tok.text
+		for text != "" {
+			if used == width {
+				rows = append(rows, nil)
+				used = 0
+			}
+			// Take as many runes as fit on the current row
+			n, taken := 0, 0
+			for taken < len(text) && used+n < width {
+				_, size := utf8.DecodeRuneInString(text[taken:])
+				taken += size
+				n++
+			}
+			last := len(rows) - 1
+			rows[last] = append(rows[last], codeToken{kind: tok.kind, text: text[:taken]})
+			used += n
+			text = text[taken:]
+		}
+	}
+	return rows
+}
+
+// renderTokens styles a row of tokens, using base for plain text
+func renderTokens(tokens []codeToken, base lipgloss.Style, styles map[tokenKind]lipgloss.Style) string {
+	var b strings.Builder
+	for _, tok := range tokens {
+		style, ok := styles[tok.kind]
+		if !ok {
+			style = base
+		}
+		b.WriteString(style.Render(tok.text))
+	}
+	return b.String()
+}
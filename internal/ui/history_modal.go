@@ -2,25 +2,65 @@ package ui
 
 import (
 	"fmt"
+	"net/url"
+	"sort"
 	"strings"
 	"time"
 
 	"github.com/charmbracelet/bubbles/key"
+	"github.com/charmbracelet/bubbles/textinput"
 	tea "github.com/charmbracelet/bubbletea"
 	"github.com/charmbracelet/lipgloss"
 	"starsearch/internal/types"
 )
 
+// HistoryGroupMode controls how the history modal groups entries
+type HistoryGroupMode int
+
+const (
+	HistoryGroupNone   HistoryGroupMode = iota // Flat list, newest first
+	HistoryGroupDay                            // Entries under date headers
+	HistoryGroupDomain                         // One row per domain with visit counts
+)
+
 // HistoryModal displays browsing history for viewing and navigation
 type HistoryModal struct {
 	visible      bool
 	history      []types.HistoryEntry
 	filtered     []types.HistoryEntry
+	rows         []historyRow // Display rows built from filtered
+	groupMode    HistoryGroupMode
 	searchQuery  string
-	selectedIdx  int
+	selectedIdx  int // Index into rows
 	width        int
 	height       int
-	scrollOffset int
+	scrollOffset int // Index of first visible row
+
+	// Inline title editing of the selected entry
+	editing    bool
+	editURL    string
+	titleInput textinput.Model
+}
+
+// historyRow is a single display row in the history modal
+type historyRow struct {
+	header string             // Group header text (header rows only)
+	entry  types.HistoryEntry // Most recent entry for this row
+	domain string             // Domain name (domain rows only)
+	visits int                // Visit count (domain rows only)
+}
+
+// isHeader returns whether the row is a non-selectable group header
+func (r historyRow) isHeader() bool {
+	return r.header != ""
+}
+
+// height returns the number of lines the row occupies when rendered
+func (r historyRow) height() int {
+	if r.isHeader() {
+		return 1
+	}
+	return 3
 }
 
 // HistorySelectedMsg is sent when a history entry is selected to navigate to
@@ -28,7 +68,26 @@ type HistorySelectedMsg struct {
 	URL string
 }
 
+// HistoryTitleMsg is sent when the user renames every visit to a URL
+type HistoryTitleMsg struct {
+	URL   string
+	Title string
+}
+
+// HistoryDeleteMsg is sent to delete every visit to the selected row's URL,
+// or to every URL of the selected domain
+type HistoryDeleteMsg struct {
+	URLs   []string
+	Label  string // The URL or domain, for display
+	Visits int
+}
+
 func NewHistoryModal() *HistoryModal {
+	titleInput := textinput.New()
+	titleInput.Placeholder = "Title"
+	titleInput.CharLimit = 1024
+	titleInput.Width = 50
+
 	return &HistoryModal{
 		visible:      false,
 		history:      []types.HistoryEntry{},
@@ -36,6 +95,7 @@ func NewHistoryModal() *HistoryModal {
 		searchQuery:  "",
 		selectedIdx:  0,
 		scrollOffset: 0,
+		titleInput:   titleInput,
 	}
 }
 
@@ -44,20 +104,115 @@ func (m *HistoryModal) Show(history []types.HistoryEntry) {
 	m.history = history
 	m.searchQuery = ""
 	m.filter()
-	m.selectedIdx = 0
-	m.scrollOffset = 0
+	m.resetSelection()
+}
+
+// Refresh replaces the history while keeping the filter and the selection
+// near its previous position
+func (m *HistoryModal) Refresh(history []types.HistoryEntry) {
+	selected := m.selectedIdx
+	m.history = history
+	m.filter()
+	m.selectedIdx = min(selected, len(m.rows)-1)
+	if m.selectedIdx < 0 {
+		m.selectedIdx = 0
+	}
+	m.moveSelection(0)
+}
+
+// deleteSelected asks for the visits of the selected row to be deleted
+func (m *HistoryModal) deleteSelected() tea.Cmd {
+	if m.selectedIdx >= len(m.rows) || m.rows[m.selectedIdx].isHeader() {
+		return nil
+	}
+	row := m.rows[m.selectedIdx]
+
+	label := row.entry.URL
+	if m.groupMode == HistoryGroupDomain {
+		label = row.domain
+	}
+	seen := make(map[string]bool)
+	var urls []string
+	visits := 0
+	for _, entry := range m.history {
+		if entry.URL != row.entry.URL && (m.groupMode != HistoryGroupDomain || entryDomain(entry.URL) != row.domain) {
+			continue
+		}
+		visits++
+		if !seen[entry.URL] {
+			seen[entry.URL] = true
+			urls = append(urls, entry.URL)
+		}
+	}
+	return func() tea.Msg {
+		return HistoryDeleteMsg{URLs: urls, Label: label, Visits: visits}
+	}
+}
+
+// startEditing opens the title editor for the selected entry
+func (m *HistoryModal) startEditing() tea.Cmd {
+	if m.groupMode == HistoryGroupDomain || m.selectedIdx >= len(m.rows) || m.rows[m.selectedIdx].isHeader() {
+		return nil
+	}
+	entry := m.rows[m.selectedIdx].entry
+	m.editing = true
+	m.editURL = entry.URL
+	m.titleInput.SetValue(entry.Title)
+	m.titleInput.CursorEnd()
+	return m.titleInput.Focus()
+}
+
+// stopEditing closes the title editor without saving
+func (m *HistoryModal) stopEditing() {
+	m.editing = false
+	m.editURL = ""
+	m.titleInput.Blur()
+}
+
+// updateEditing handles key input while the title editor is open
+func (m *HistoryModal) updateEditing(msg tea.KeyMsg) (*HistoryModal, tea.Cmd) {
+	switch msg.String() {
+	case "esc":
+		m.stopEditing()
+		return m, nil
+
+	case "enter":
+		title := strings.TrimSpace(m.titleInput.Value())
+		if title == "" {
+			return m, nil
+		}
+		titleMsg := HistoryTitleMsg{URL: m.editURL, Title: title}
+		m.stopEditing()
+		return m, func() tea.Msg { return titleMsg }
+	}
+
+	var cmd tea.Cmd
+	m.titleInput, cmd = m.titleInput.Update(msg)
+	return m, cmd
 }
 
 func (m *HistoryModal) Hide() {
+	m.stopEditing()
 	m.visible = false
 	m.searchQuery = ""
 	m.filtered = []types.HistoryEntry{}
+	m.rows = nil
 }
 
 func (m *HistoryModal) IsVisible() bool {
 	return m.visible
 }
 
+// TakesText reports that typing always filters the history
+func (m *HistoryModal) TakesText() bool {
+	return true
+}
+
+// HandlesMouse reports that the history modal handles the mouse
+func (m *HistoryModal) HandlesMouse() bool {
+	return true
+}
+
 func (m *HistoryModal) SetSize(width, height int) {
 	m.width = width
 	m.height = height
@@ -72,6 +227,7 @@ func (m *HistoryModal) filter() {
 		for i, j := 0, len(m.filtered)-1; i < j; i, j = i+1, j-1 {
 			m.filtered[i], m.filtered[j] = m.filtered[j], m.filtered[i]
 		}
+		m.buildRows()
 		return
 	}
 
@@ -87,6 +243,155 @@ func (m *HistoryModal) filter() {
 	for i, j := 0, len(m.filtered)-1; i < j; i, j = i+1, j-1 {
 		m.filtered[i], m.filtered[j] = m.filtered[j], m.filtered[i]
 	}
+	m.buildRows()
+}
+
+// buildRows groups the filtered entries into display rows for the current mode
+func (m *HistoryModal) buildRows() {
+	m.rows = []historyRow{}
+
+	switch m.groupMode {
+	case HistoryGroupDay:
+		lastDay := ""
+		for _, entry := range m.filtered {
+			day := dayLabel(time.Unix(entry.Timestamp, 0))
+			if day != lastDay {
+				m.rows = append(m.rows, historyRow{header: day})
+				lastDay = day
+			}
+			m.rows = append(m.rows, historyRow{entry: entry})
+		}
+
+	case HistoryGroupDomain:
+		// filtered is newest first, so the first entry seen per domain is the latest visit
+		index := make(map[string]int)
+		for _, entry := range m.filtered {
+			domain := entryDomain(entry.URL)
+			if i, ok := index[domain]; ok {
+				m.rows[i].visits++
+				continue
+			}
+			index[domain] = len(m.rows)
+			m.rows = append(m.rows, historyRow{entry: entry, domain: domain, visits: 1})
+		}
+		sort.SliceStable(m.rows, func(i, j int) bool {
+			return m.rows[i].visits > m.rows[j].visits
+		})
+
+	default:
+		for _, entry := range m.filtered {
+			m.rows = append(m.rows, historyRow{entry: entry})
+		}
+	}
+}
+
+// dayLabel returns a date header for a timestamp, using Today/Yesterday where possible
+func dayLabel(t time.Time) string {
+	now := time.Now()
+	y, mo, d := t.Date()
+	if ny, nmo, nd := now.Date(); y == ny && mo == nmo && d == nd {
+		return "Today"
+	}
+	if py, pmo, pd := now.AddDate(0, 0, -1).Date(); y == py && mo == pmo && d == pd {
+		return "Yesterday"
+	}
+	return t.Format("Monday, January 2, 2006")
+}
+
+// entryDomain returns the host part of a history URL
+func entryDomain(rawURL string) string {
+	parsed, err := url.Parse(rawURL)
+	if err != nil || parsed.Host == "" {
+		return rawURL
+	}
+	return parsed.Host
+}
+
+// resetSelection moves the selection to the first selectable row
+func (m *HistoryModal) resetSelection() {
+	m.selectedIdx = 0
+	m.scrollOffset = 0
+	m.moveSelection(0)
+}
+
+// moveSelection moves the selection by delta rows, skipping group headers.
+// A delta of zero snaps the selection onto the nearest selectable row.
+func (m *HistoryModal) moveSelection(delta int) {
+	if len(m.rows) == 0 {
+		m.selectedIdx = 0
+		return
+	}
+
+	if delta == 0 {
+		for i := m.selectedIdx; i < len(m.rows); i++ {
+			if !m.rows[i].isHeader() {
+				m.selectedIdx = i
+				m.adjustScroll()
+				return
+			}
+		}
+		delta = -1
+	}
+
+	step := 1
+	remaining := delta
+	if delta < 0 {
+		step = -1
+		remaining = -delta
+	}
+
+	idx := m.selectedIdx
+	for remaining > 0 {
+		next := idx + step
+		for next >= 0 && next < len(m.rows) && m.rows[next].isHeader() {
+			next += step
+		}
+		if next < 0 || next >= len(m.rows) {
+			break
+		}
+		idx = next
+		remaining--
+	}
+
+	m.selectedIdx = idx
+	m.adjustScroll()
+}
+
+// selectableCount returns the number of non-header rows
+func (m *HistoryModal) selectableCount() int {
+	count := 0
+	for _, row := range m.rows {
+		if !row.isHeader() {
+			count++
+		}
+	}
+	return count
+}
+
+// cycleGroupMode switches to the next grouping mode
+func (m *HistoryModal) cycleGroupMode() {
+	m.groupMode = (m.groupMode + 1) % 3
+	m.buildRows()
+	m.resetSelection()
+}
+
+// GroupMode returns the current grouping mode
+func (m *HistoryModal) GroupMode() HistoryGroupMode {
+	return m.groupMode
+}
+
+// visibleLines returns the number of content lines available for rows
+func (m *HistoryModal) visibleLines() int {
+	modalHeight := m.height - 6
+	if modalHeight < 10 {
+		modalHeight = 10
+	}
+	// Account for title (1 line), help text (1 line), and padding (4 lines total)
+	visibleHeight := modalHeight - 6
+	if visibleHeight < 3 {
+		visibleHeight = 3
+	}
+	return visibleHeight
 }
 
 func (m *HistoryModal) Update(msg tea.Msg) (*HistoryModal, tea.Cmd) {
@@ -96,40 +401,57 @@ func (m *HistoryModal) Update(msg tea.Msg) (*HistoryModal, tea.Cmd) {
 
 	switch msg := msg.(type) {
 	case tea.KeyMsg:
+		if m.editing {
+			return m.updateEditing(msg)
+		}
+
 		switch {
 		case key.Matches(msg, key.NewBinding(key.WithKeys("esc", "ctrl+c", "ctrl+h"))):
 			m.Hide()
 			return m, nil
 
 		case key.Matches(msg, key.NewBinding(key.WithKeys("j", "down"))):
-			if m.selectedIdx < len(m.filtered)-1 {
-				m.selectedIdx++
-				m.adjustScroll()
-			}
+			m.moveSelection(1)
 			return m, nil
 
 		case key.Matches(msg, key.NewBinding(key.WithKeys("k", "up"))):
-			if m.selectedIdx > 0 {
-				m.selectedIdx--
-				m.adjustScroll()
-			}
+			m.moveSelection(-1)
 			return m, nil
 
 		case key.Matches(msg, key.NewBinding(key.WithKeys("g"))):
-			m.selectedIdx = 0
-			m.scrollOffset = 0
+			m.resetSelection()
 			return m, nil
 
 		case key.Matches(msg, key.NewBinding(key.WithKeys("G"))):
-			if len(m.filtered) > 0 {
-				m.selectedIdx = len(m.filtered) - 1
-				m.adjustScroll()
+			if len(m.rows) > 0 {
+				m.selectedIdx = len(m.rows) - 1
+				m.moveSelection(0)
 			}
 			return m, nil
 
+		case key.Matches(msg, key.NewBinding(key.WithKeys("delete"))):
+			return m, m.deleteSelected()
+
+		case key.Matches(msg, key.NewBinding(key.WithKeys("ctrl+e"))):
+			return m, m.startEditing()
+
+		case key.Matches(msg, key.NewBinding(key.WithKeys("tab"))):
+			// Cycle grouping: flat -> by day -> by domain
+			m.cycleGroupMode()
+			return m, nil
+
 		case key.Matches(msg, key.NewBinding(key.WithKeys("enter"))):
-			if m.selectedIdx < len(m.filtered) {
-				url := m.filtered[m.selectedIdx].URL
+			if m.selectedIdx < len(m.rows) && !m.rows[m.selectedIdx].isHeader() {
+				row := m.rows[m.selectedIdx]
+				if m.groupMode == HistoryGroupDomain {
+					// Drill into the domain: show its visits as a flat list
+					m.groupMode = HistoryGroupNone
+					m.searchQuery = row.domain
+					m.filter()
+					m.resetSelection()
+					return m, nil
+				}
+				url := row.entry.URL
 				m.Hide()
 				return m, func() tea.Msg {
 					return HistorySelectedMsg{URL: url}
@@ -142,8 +464,7 @@ func (m *HistoryModal) Update(msg tea.Msg) (*HistoryModal, tea.Cmd) {
 			// In a full implementation, you'd want a search input field
 			m.searchQuery = ""
 			m.filter()
-			m.selectedIdx = 0
-			m.scrollOffset = 0
+			m.resetSelection()
 			return m, nil
 
 		case key.Matches(msg, key.NewBinding(key.WithKeys("backspace"))):
@@ -151,8 +472,7 @@ func (m *HistoryModal) Update(msg tea.Msg) (*HistoryModal, tea.Cmd) {
 			if len(m.searchQuery) > 0 {
 				m.searchQuery = m.searchQuery[:len(m.searchQuery)-1]
 				m.filter()
-				m.selectedIdx = 0
-				m.scrollOffset = 0
+				m.resetSelection()
 			}
 			return m, nil
 
@@ -161,8 +481,7 @@ func (m *HistoryModal) Update(msg tea.Msg) (*HistoryModal, tea.Cmd) {
 			if len(msg.Runes) > 0 {
 				m.searchQuery += string(msg.Runes)
 				m.filter()
-				m.selectedIdx = 0
-				m.scrollOffset = 0
+				m.resetSelection()
 				return m, nil
 			}
 		}
@@ -170,53 +489,17 @@ func (m *HistoryModal) Update(msg tea.Msg) (*HistoryModal, tea.Cmd) {
 	case tea.MouseMsg:
 		if msg.Type == tea.MouseWheelUp {
 			// Scroll up with mouse wheel
-			if m.scrollOffset > 0 {
-				m.scrollOffset--
-				if m.selectedIdx > 0 {
-					m.selectedIdx--
-				}
-			} else if m.selectedIdx > 0 {
-				m.selectedIdx--
-				m.adjustScroll()
-			}
+			m.moveSelection(-1)
 			return m, nil
 		}
 
 		if msg.Type == tea.MouseWheelDown {
 			// Scroll down with mouse wheel
-			modalHeight := m.height - 6
-			if modalHeight < 10 {
-				modalHeight = 10
-			}
-			visibleHeight := modalHeight - 6
-			if visibleHeight < 1 {
-				visibleHeight = 1
-			}
-
-			// Calculate visible entries (each entry is 3 lines)
-			visibleEntries := visibleHeight / 3
-			if visibleEntries < 1 {
-				visibleEntries = 1
-			}
-
-			maxScroll := len(m.filtered) - visibleEntries
-			if maxScroll < 0 {
-				maxScroll = 0
-			}
-
-			if m.scrollOffset < maxScroll {
-				m.scrollOffset++
-				if m.selectedIdx < len(m.filtered)-1 {
-					m.selectedIdx++
-				}
-			} else if m.selectedIdx < len(m.filtered)-1 {
-				m.selectedIdx++
-				m.adjustScroll()
-			}
+			m.moveSelection(1)
 			return m, nil
 		}
 
-		if msg.Type == tea.MouseLeft && len(m.filtered) > 0 {
+		if msg.Type == tea.MouseLeft && len(m.rows) > 0 && !m.editing {
 			// Similar mouse handling as bookmarks modal
 			modalWidth := m.width - 6
 			if modalWidth < 60 {
@@ -231,11 +514,6 @@ func (m *HistoryModal) Update(msg tea.Msg) (*HistoryModal, tea.Cmd) {
 				modalHeight = 10
 			}
 
-			visibleHeight := modalHeight - 6
-			if visibleHeight < 1 {
-				visibleHeight = 1
-			}
-
 			contentWidth := modalWidth + 6
 			leftPadding := (m.width - contentWidth) / 2
 			if leftPadding < 0 {
@@ -255,16 +533,12 @@ func (m *HistoryModal) Update(msg tea.Msg) (*HistoryModal, tea.Cmd) {
 			// Check if click is within modal bounds
 			if msg.X >= modalLeft && msg.X < modalLeft+modalWidth &&
 				msg.Y >= modalTop && msg.Y < modalTop+modalHeight {
-				// Click is in modal - calculate which entry was clicked
+				// Click is in modal - calculate which row was clicked
 				// Account for title (1 line) and help text (1 line) and padding
 				clickY := msg.Y - modalTop - 3
-				if clickY >= 0 {
-					// Each entry is 3 lines (title, URL, timestamp)
-					clickedIdx := m.scrollOffset + (clickY / 3)
-					if clickedIdx >= 0 && clickedIdx < len(m.filtered) {
-						m.selectedIdx = clickedIdx
-						m.adjustScroll()
-					}
+				if clickedIdx := m.rowAtLine(clickY); clickedIdx >= 0 && !m.rows[clickedIdx].isHeader() {
+					m.selectedIdx = clickedIdx
+					m.adjustScroll()
 				}
 			}
 		}
@@ -273,47 +547,48 @@ func (m *HistoryModal) Update(msg tea.Msg) (*HistoryModal, tea.Cmd) {
 	return m, nil
 }
 
-func (m *HistoryModal) adjustScroll() {
-	modalHeight := m.height - 6
-	if modalHeight < 10 {
-		modalHeight = 10
-	}
-	// Account for title (1 line), help text (1 line), and padding (4 lines total)
-	visibleHeight := modalHeight - 6
-	if visibleHeight < 1 {
-		visibleHeight = 1
+// rowAtLine returns the row index rendered at the given line of the list area, or -1
+func (m *HistoryModal) rowAtLine(line int) int {
+	if line < 0 {
+		return -1
 	}
-
-	// Each entry takes 3 lines (title, URL, timestamp)
-	// So we can show visibleHeight/3 entries
-	visibleEntries := visibleHeight / 3
-	if visibleEntries < 1 {
-		visibleEntries = 1
+	y := 0
+	for i := m.scrollOffset; i < len(m.rows); i++ {
+		y += m.rows[i].height()
+		if line < y {
+			return i
+		}
 	}
+	return -1
+}
 
-	// Scroll down if selected item is below visible area
-	if m.selectedIdx >= m.scrollOffset+visibleEntries {
-		m.scrollOffset = m.selectedIdx - visibleEntries + 1
-	}
+func (m *HistoryModal) adjustScroll() {
+	visibleHeight := m.visibleLines()
 
-	// Scroll up if selected item is above visible area
+	// Scroll up if selected item is above visible area, keeping its group header in view
 	if m.selectedIdx < m.scrollOffset {
 		m.scrollOffset = m.selectedIdx
 	}
+	if m.scrollOffset == m.selectedIdx && m.scrollOffset > 0 && m.rows[m.scrollOffset-1].isHeader() {
+		m.scrollOffset--
+	}
+
+	// Scroll down until the selected item fits in the visible area
+	for m.scrollOffset < m.selectedIdx {
+		lines := 0
+		for i := m.scrollOffset; i <= m.selectedIdx && i < len(m.rows); i++ {
+			lines += m.rows[i].height()
+		}
+		if lines <= visibleHeight {
+			break
+		}
+		m.scrollOffset++
+	}
 
 	// Ensure scroll offset doesn't go negative
 	if m.scrollOffset < 0 {
 		m.scrollOffset = 0
 	}
-
-	// Ensure we don't scroll past the end
-	maxScroll := len(m.filtered) - visibleEntries
-	if maxScroll < 0 {
-		maxScroll = 0
-	}
-	if m.scrollOffset > maxScroll {
-		m.scrollOffset = maxScroll
-	}
 }
 
 func (m *HistoryModal) View() string {
@@ -339,16 +614,7 @@ func (m *HistoryModal) View() string {
 		modalHeight = m.height - 6
 	}
 
-	visibleHeight := modalHeight - 6 // Less padding for more content
-	if visibleHeight < 1 {
-		visibleHeight = 1
-	}
-
-	// Calculate how many entries we can show (each entry is 3 lines)
-	visibleEntries := visibleHeight / 3
-	if visibleEntries < 1 {
-		visibleEntries = 1
-	}
+	visibleHeight := m.visibleLines()
 
 	// Title
 	titleStyle := lipgloss.NewStyle().
@@ -359,6 +625,12 @@ func (m *HistoryModal) View() string {
 		Width(modalWidth - 4)
 
 	title := "History"
+	switch m.groupMode {
+	case HistoryGroupDay:
+		title += " by day"
+	case HistoryGroupDomain:
+		title += fmt.Sprintf(" by domain (%d)", m.selectableCount())
+	}
 	if m.searchQuery != "" {
 		title += fmt.Sprintf(" (filter: %s)", m.searchQuery)
 	}
@@ -370,23 +642,41 @@ func (m *HistoryModal) View() string {
 		Padding(0, 1).
 		Width(modalWidth - 4)
 
-	helpText := helpStyle.Render("Enter: Navigate | Esc/Ctrl+C: Close | /: Search | Mouse: Scroll")
+	helpText := helpStyle.Render("Enter: Navigate | Tab: Group | Ctrl+E: Rename | Del: Delete | Esc/Ctrl+C: Close | /: Search | Mouse: Scroll")
+	if m.editing {
+		helpText = helpStyle.Render("Title: " + m.titleInput.View() + "  Enter: Save | Esc: Cancel")
+	}
 
-	// History entries - show entries starting from scrollOffset
+	headerStyle := lipgloss.NewStyle().
+		Bold(true).
+		Foreground(lipgloss.Color("11")).
+		Padding(0, 1).
+		Width(modalWidth - 4)
+
+	// History rows - show rows starting from scrollOffset until the area is full
 	var entries []string
-	startIdx := m.scrollOffset
-	endIdx := startIdx + visibleEntries
-	if endIdx > len(m.filtered) {
-		endIdx = len(m.filtered)
-	}
+	usedLines := 0
+	for i := m.scrollOffset; i < len(m.rows); i++ {
+		row := m.rows[i]
+		if usedLines+row.height() > visibleHeight {
+			break
+		}
+		usedLines += row.height()
+
+		if row.isHeader() {
+			entries = append(entries, headerStyle.Render(row.header))
+			continue
+		}
 
-	for i := startIdx; i < endIdx; i++ {
-		entry := m.filtered[i]
+		entry := row.entry
 		isSelected := i == m.selectedIdx
 
 		// Format timestamp
 		timestamp := time.Unix(entry.Timestamp, 0)
 		timeStr := timestamp.Format("2006-01-02 15:04")
+		if m.groupMode == HistoryGroupDay {
+			timeStr = timestamp.Format("15:04")
+		}
 
 		var style lipgloss.Style
 		if isSelected {
@@ -404,11 +694,20 @@ func (m *HistoryModal) View() string {
 
 		// Truncate title and URL if needed - use more width
 		title := entry.Title
+		url := entry.URL
+		if m.groupMode == HistoryGroupDomain {
+			title = row.domain
+			visits := "visits"
+			if row.visits == 1 {
+				visits = "visit"
+			}
+			url = fmt.Sprintf("%d %s • last: %s", row.visits, visits, entry.URL)
+			timeStr = "last visited " + timeStr
+		}
 		maxTitleLen := modalWidth - 10
 		if len(title) > maxTitleLen {
 			title = title[:maxTitleLen-3] + "..."
 		}
-		url := entry.URL
 		maxURLLen := modalWidth - 10
 		if len(url) > maxURLLen {
 			url = url[:maxURLLen-3] + "..."
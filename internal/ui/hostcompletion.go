@@ -0,0 +1,105 @@
+package ui
+
+import (
+	"net/url"
+	"strings"
+
+	"starsearch/internal/types"
+)
+
+// hostCompletion is a run of Tab presses completing the host in the
+// address bar
+type hostCompletion struct {
+	candidates []string // Origins matching what was typed, such as gemini://example.org
+	idx        int      // Candidate shown
+	value      string   // Address bar value after the last completion
+}
+
+// CompletionHosts lists the origins of visited and bookmarked pages for
+// host completion, most recently visited first, then bookmarks
+func CompletionHosts(history []types.HistoryEntry, bookmarks []types.Bookmark) []string {
+	seen := make(map[string]bool)
+	var origins []string
+	add := func(rawURL string) {
+		u, err := url.Parse(rawURL)
+		if err != nil || u.Host == "" {
+			return
+		}
+		origin := u.Scheme + "://" + strings.ToLower(u.Host)
+		if !seen[origin] {
+			seen[origin] = true
+			origins = append(origins, origin)
+		}
+	}
+	for i := len(history) - 1; i >= 0; i-- {
+		add(history[i].URL)
+	}
+	for _, bookmark := range bookmarks {
+		add(bookmark.URL)
+	}
+	return origins
+}
+
+// matchHosts returns the origins completing typed, which is a scheme and
+// the start of a host, or the start of a host alone. It returns nothing
+// once typed reaches past the host.
+func matchHosts(typed string, origins []string) []string {
+	typed = strings.ToLower(typed)
+	scheme, host, hasScheme := strings.Cut(typed, "://")
+	if !hasScheme {
+		host = typed
+	}
+	if host == "" || strings.Contains(host, "/") {
+		return nil
+	}
+
+	var matches []string
+	for _, origin := range origins {
+		originScheme, originHost, _ := strings.Cut(origin, "://")
+		if hasScheme && originScheme != scheme {
+			continue
+		}
+		if strings.HasPrefix(originHost, host) && originHost != host {
+			matches = append(matches, origin)
+		}
+	}
+	return matches
+}
+
+// SetHosts sets where host completion finds the hosts it offers
+func (a *AddressBar) SetHosts(hosts func() []string) {
+	a.hosts = hosts
+}
+
+// completeHost completes the host being typed, or on repeated presses
+// moves to the next (or previous) host that matched. It reports whether
+// there was anything to complete.
+func (a *AddressBar) completeHost(forward bool) bool {
+	value := a.input.Value()
+	if a.completion == nil || a.completion.value != value {
+		a.completion = nil
+		if a.hosts == nil || a.input.Position() != len([]rune(value)) {
+			return false
+		}
+		candidates := matchHosts(value, a.hosts())
+		if len(candidates) == 0 {
+			return false
+		}
+		a.completion = &hostCompletion{candidates: candidates}
+		if !forward {
+			a.completion.idx = len(candidates) - 1
+		}
+	} else {
+		n := len(a.completion.candidates)
+		if forward {
+			a.completion.idx = (a.completion.idx + 1) % n
+		} else {
+			a.completion.idx = (a.completion.idx + n - 1) % n
+		}
+	}
+
+	a.completion.value = a.completion.candidates[a.completion.idx] + "/"
+	a.input.SetValue(a.completion.value)
+	a.input.CursorEnd()
+	return true
+}
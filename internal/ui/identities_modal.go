@@ -0,0 +1,633 @@
+package ui
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/charmbracelet/bubbles/key"
+	"github.com/charmbracelet/bubbles/textinput"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"starsearch/internal/types"
+)
+
+// identityExpiryWarning is how long before it expires an identity is flagged
+const identityExpiryWarning = 30 * 24 * time.Hour
+
+// identityKeyTypes are the key types offered when creating an identity
+var identityKeyTypes = []string{"ed25519", "ecdsa"}
+
+// Create form field indices
+const (
+	identityFieldName = iota
+	identityFieldCommonName
+	identityFieldKeyType
+	identityFieldValidity
+	identityFieldCount
+)
+
+// IdentitiesModal lists client certificates and creates new ones
+type IdentitiesModal struct {
+	visible       bool
+	identities    []types.IdentityInfo
+	selectedIdx   int
+	width         int
+	height        int
+	scrollOffset  int
+	pendingDelete string // Name of the identity awaiting a second "d"
+	chooseURL     string // URL an identity is being chosen for; empty when managing
+	chooseReason  string
+	showingUsage  bool // Whether the URLs the selected identity was used on are shown
+
+	// Scope edit form state
+	editingScopes bool
+	scopeInput    textinput.Model
+
+	// Create form state
+	creating     bool
+	createInputs []textinput.Model // name, common name, (key type), validity
+	createFocus  int
+	keyTypeIdx   int
+	formError    string
+}
+
+// IdentityCreateMsg is sent when the user asks for a new identity
+type IdentityCreateMsg struct {
+	Name         string
+	CommonName   string
+	KeyType      string
+	ValidityDays int
+}
+
+// IdentityDeleteMsg is sent when an identity should be deleted
+type IdentityDeleteMsg struct {
+	Name string
+}
+
+// IdentityScopesMsg is sent when the user saves an identity's scopes
+type IdentityScopesMsg struct {
+	Name   string
+	Scopes []string
+}
+
+// IdentityChosenMsg is sent when an identity is picked for a URL
+type IdentityChosenMsg struct {
+	Name string
+	URL  string
+}
+
+func NewIdentitiesModal() *IdentitiesModal {
+	placeholders := []string{"my-identity", "Shown to capsules (defaults to the name)", "", "365"}
+	inputs := make([]textinput.Model, identityFieldCount)
+	for i, placeholder := range placeholders {
+		ti := textinput.New()
+		ti.Placeholder = placeholder
+		ti.CharLimit = 256
+		ti.Width = 50
+		inputs[i] = ti
+	}
+
+	scopeInput := textinput.New()
+	scopeInput.Placeholder = "gemini://host/path, gemini://other.host/"
+	scopeInput.CharLimit = 1024
+	scopeInput.Width = 50
+
+	return &IdentitiesModal{
+		identities:   []types.IdentityInfo{},
+		createInputs: inputs,
+		scopeInput:   scopeInput,
+	}
+}
+
+func (m *IdentitiesModal) Show(identities []types.IdentityInfo) {
+	m.visible = true
+	m.identities = identities
+	m.selectedIdx = 0
+	m.scrollOffset = 0
+	m.pendingDelete = ""
+	m.chooseURL = ""
+	m.showingUsage = false
+	m.stopCreating()
+	m.stopEditingScopes()
+}
+
+// ShowChooser opens the modal to pick the identity to present on urlStr,
+// saying why one is needed
+func (m *IdentitiesModal) ShowChooser(identities []types.IdentityInfo, urlStr, reason string) {
+	m.Show(identities)
+	m.chooseURL = urlStr
+	m.chooseReason = reason
+}
+
+// Refresh replaces the identity list while keeping the selection near its
+// previous position
+func (m *IdentitiesModal) Refresh(identities []types.IdentityInfo) {
+	m.identities = identities
+	m.pendingDelete = ""
+	if m.selectedIdx >= len(m.identities) {
+		m.selectedIdx = len(m.identities) - 1
+	}
+	if m.selectedIdx < 0 {
+		m.selectedIdx = 0
+	}
+	m.adjustScroll()
+}
+
+func (m *IdentitiesModal) Hide() {
+	m.visible = false
+	m.stopCreating()
+	m.stopEditingScopes()
+}
+
+func (m *IdentitiesModal) IsVisible() bool {
+	return m.visible
+}
+
+// TakesText reports whether one of the forms is taking typed text
+func (m *IdentitiesModal) TakesText() bool {
+	return m.creating || m.editingScopes
+}
+
+func (m *IdentitiesModal) SetSize(width, height int) {
+	m.width = width
+	m.height = height
+}
+
+// startCreating opens the form for a new identity
+func (m *IdentitiesModal) startCreating() tea.Cmd {
+	m.creating = true
+	m.formError = ""
+	m.keyTypeIdx = 0
+	for i := range m.createInputs {
+		m.createInputs[i].Reset()
+	}
+	m.createInputs[identityFieldValidity].SetValue("365")
+	m.createInputs[identityFieldValidity].CursorEnd()
+	return m.focusCreateField(identityFieldName)
+}
+
+// stopCreating closes the create form
+func (m *IdentitiesModal) stopCreating() {
+	m.creating = false
+	m.formError = ""
+	for i := range m.createInputs {
+		m.createInputs[i].Blur()
+	}
+}
+
+// focusCreateField moves focus to the given create form field
+func (m *IdentitiesModal) focusCreateField(field int) tea.Cmd {
+	m.createFocus = field
+	for i := range m.createInputs {
+		m.createInputs[i].Blur()
+	}
+	if field == identityFieldKeyType {
+		return nil
+	}
+	return m.createInputs[field].Focus()
+}
+
+// startEditingScopes opens the scope form for the selected identity
+func (m *IdentitiesModal) startEditingScopes() tea.Cmd {
+	if m.selectedIdx >= len(m.identities) {
+		return nil
+	}
+	m.editingScopes = true
+	m.scopeInput.SetValue(strings.Join(m.identities[m.selectedIdx].Scopes, ", "))
+	m.scopeInput.CursorEnd()
+	return m.scopeInput.Focus()
+}
+
+// stopEditingScopes closes the scope form
+func (m *IdentitiesModal) stopEditingScopes() {
+	m.editingScopes = false
+	m.scopeInput.Blur()
+}
+
+// updateEditingScopes handles key input while the scope form is open
+func (m *IdentitiesModal) updateEditingScopes(msg tea.KeyMsg) (*IdentitiesModal, tea.Cmd) {
+	switch msg.String() {
+	case "esc":
+		m.stopEditingScopes()
+		return m, nil
+
+	case "enter":
+		scopesMsg := IdentityScopesMsg{
+			Name:   m.identities[m.selectedIdx].Name,
+			Scopes: parseTags(m.scopeInput.Value()),
+		}
+		m.stopEditingScopes()
+		return m, func() tea.Msg { return scopesMsg }
+	}
+
+	var cmd tea.Cmd
+	m.scopeInput, cmd = m.scopeInput.Update(msg)
+	return m, cmd
+}
+
+// updateCreating handles key input while the create form is open
+func (m *IdentitiesModal) updateCreating(msg tea.KeyMsg) (*IdentitiesModal, tea.Cmd) {
+	switch msg.String() {
+	case "esc":
+		m.stopCreating()
+		return m, nil
+
+	case "tab", "down":
+		return m, m.focusCreateField((m.createFocus + 1) % identityFieldCount)
+
+	case "shift+tab", "up":
+		return m, m.focusCreateField((m.createFocus + identityFieldCount - 1) % identityFieldCount)
+
+	case "enter":
+		name := strings.TrimSpace(m.createInputs[identityFieldName].Value())
+		if name == "" {
+			m.formError = "A name is required"
+			return m, m.focusCreateField(identityFieldName)
+		}
+		days, err := strconv.Atoi(strings.TrimSpace(m.createInputs[identityFieldValidity].Value()))
+		if err != nil || days < 1 {
+			m.formError = "Validity must be a whole number of days"
+			return m, m.focusCreateField(identityFieldValidity)
+		}
+		createMsg := IdentityCreateMsg{
+			Name:         name,
+			CommonName:   strings.TrimSpace(m.createInputs[identityFieldCommonName].Value()),
+			KeyType:      identityKeyTypes[m.keyTypeIdx],
+			ValidityDays: days,
+		}
+		m.stopCreating()
+		return m, func() tea.Msg { return createMsg }
+	}
+
+	if m.createFocus == identityFieldKeyType {
+		switch msg.String() {
+		case "left", "right", " ", "h", "l":
+			m.keyTypeIdx = (m.keyTypeIdx + 1) % len(identityKeyTypes)
+		}
+		return m, nil
+	}
+
+	var cmd tea.Cmd
+	m.createInputs[m.createFocus], cmd = m.createInputs[m.createFocus].Update(msg)
+	return m, cmd
+}
+
+func (m *IdentitiesModal) Update(msg tea.Msg) (*IdentitiesModal, tea.Cmd) {
+	if !m.visible {
+		return m, nil
+	}
+
+	keyMsg, ok := msg.(tea.KeyMsg)
+	if !ok {
+		return m, nil
+	}
+	if m.creating {
+		return m.updateCreating(keyMsg)
+	}
+	if m.editingScopes {
+		return m.updateEditingScopes(keyMsg)
+	}
+	if m.showingUsage {
+		switch keyMsg.String() {
+		case "esc", "u":
+			m.showingUsage = false
+		}
+		return m, nil
+	}
+
+	// Any key other than a second "d" cancels a pending delete
+	pending := m.pendingDelete
+	m.pendingDelete = ""
+
+	switch {
+	case key.Matches(keyMsg, key.NewBinding(key.WithKeys("esc", "I"))):
+		m.Hide()
+
+	case key.Matches(keyMsg, key.NewBinding(key.WithKeys("j", "down"))):
+		if m.selectedIdx < len(m.identities)-1 {
+			m.selectedIdx++
+			m.adjustScroll()
+		}
+
+	case key.Matches(keyMsg, key.NewBinding(key.WithKeys("k", "up"))):
+		if m.selectedIdx > 0 {
+			m.selectedIdx--
+			m.adjustScroll()
+		}
+
+	case key.Matches(keyMsg, key.NewBinding(key.WithKeys("n"))):
+		return m, m.startCreating()
+
+	case key.Matches(keyMsg, key.NewBinding(key.WithKeys("s"))):
+		return m, m.startEditingScopes()
+
+	case key.Matches(keyMsg, key.NewBinding(key.WithKeys("u"))):
+		m.showingUsage = m.selectedIdx < len(m.identities)
+
+	case key.Matches(keyMsg, key.NewBinding(key.WithKeys("enter"))):
+		if m.chooseURL != "" && m.selectedIdx < len(m.identities) {
+			chosen := IdentityChosenMsg{Name: m.identities[m.selectedIdx].Name, URL: m.chooseURL}
+			m.Hide()
+			return m, func() tea.Msg { return chosen }
+		}
+
+	case key.Matches(keyMsg, key.NewBinding(key.WithKeys("d", "delete"))):
+		if m.selectedIdx < len(m.identities) {
+			name := m.identities[m.selectedIdx].Name
+			if pending != name {
+				// The key can't be recovered, so ask for confirmation
+				m.pendingDelete = name
+				return m, nil
+			}
+			return m, func() tea.Msg {
+				return IdentityDeleteMsg{Name: name}
+			}
+		}
+	}
+
+	return m, nil
+}
+
+func (m *IdentitiesModal) adjustScroll() {
+	// Each identity takes four lines
+	visibleItems := (m.height - 10) / 4
+	if visibleItems < 1 {
+		visibleItems = 1
+	}
+
+	if m.selectedIdx >= m.scrollOffset+visibleItems {
+		m.scrollOffset = m.selectedIdx - visibleItems + 1
+	}
+	if m.selectedIdx < m.scrollOffset {
+		m.scrollOffset = m.selectedIdx
+	}
+}
+
+// expiryText describes when an identity expires and whether to warn about it
+func expiryText(notAfter, now time.Time) (string, bool) {
+	remaining := notAfter.Sub(now)
+	switch {
+	case remaining <= 0:
+		return "EXPIRED " + notAfter.Format("2006-01-02"), true
+	case remaining < identityExpiryWarning:
+		days := int(remaining.Hours() / 24)
+		switch days {
+		case 0:
+			return "expires today", true
+		case 1:
+			return "expires tomorrow", true
+		}
+		return fmt.Sprintf("expires in %d days (%s)", days, notAfter.Format("2006-01-02")), true
+	default:
+		return "expires " + notAfter.Format("2006-01-02"), false
+	}
+}
+
+func (m *IdentitiesModal) View() string {
+	if !m.visible {
+		return ""
+	}
+
+	modalWidth := m.width - 4
+	if modalWidth < 40 {
+		modalWidth = 40
+	}
+	if modalWidth > 100 {
+		modalWidth = 100
+	}
+
+	titleStyle := lipgloss.NewStyle().
+		Bold(true).
+		Foreground(lipgloss.Color("12")).
+		Width(modalWidth).
+		Align(lipgloss.Center).
+		MarginBottom(1)
+
+	selectedStyle := lipgloss.NewStyle().
+		Background(lipgloss.Color("12")).
+		Foreground(lipgloss.Color("0")).
+		Bold(true).
+		Width(modalWidth - 4)
+
+	normalStyle := lipgloss.NewStyle().
+		Foreground(lipgloss.Color("15")).
+		Width(modalWidth - 4)
+
+	warningStyle := lipgloss.NewStyle().
+		Foreground(lipgloss.Color("11")).
+		Bold(true)
+
+	emptyStyle := lipgloss.NewStyle().
+		Foreground(lipgloss.Color("8")).
+		Italic(true).
+		Width(modalWidth).
+		Align(lipgloss.Center).
+		MarginTop(1).
+		MarginBottom(1)
+
+	helpStyle := lipgloss.NewStyle().
+		Foreground(lipgloss.Color("7")).
+		Width(modalWidth).
+		Align(lipgloss.Center).
+		MarginTop(1)
+
+	borderStyle := lipgloss.NewStyle().
+		Border(lipgloss.RoundedBorder()).
+		BorderForeground(lipgloss.Color("12")).
+		Padding(1, 2).
+		Width(modalWidth)
+
+	var b strings.Builder
+	if m.creating {
+		b.WriteString(m.createFormView(modalWidth, titleStyle, helpStyle, warningStyle))
+	} else if m.showingUsage {
+		b.WriteString(m.usageView(modalWidth, titleStyle, helpStyle, emptyStyle))
+	} else if m.editingScopes {
+		b.WriteString(titleStyle.Render("Scopes of " + m.identities[m.selectedIdx].Name))
+		b.WriteString("\n")
+		b.WriteString("Presented automatically on URLs starting with any of these (comma-separated):")
+		b.WriteString("\n")
+		m.scopeInput.Width = modalWidth - 12
+		b.WriteString(m.scopeInput.View())
+		b.WriteString("\n")
+		b.WriteString(helpStyle.Render("enter: save • esc: cancel"))
+	} else {
+		if m.chooseURL != "" {
+			b.WriteString(titleStyle.Render("Choose an identity"))
+			b.WriteString("\n")
+			b.WriteString(m.chooseReason)
+			b.WriteString("\n\n")
+		} else {
+			b.WriteString(titleStyle.Render(fmt.Sprintf("Identities (%d)", len(m.identities))))
+			b.WriteString("\n")
+		}
+
+		if len(m.identities) == 0 {
+			b.WriteString(emptyStyle.Render("No identities yet"))
+			b.WriteString("\n")
+			b.WriteString(emptyStyle.Render("Press 'n' to create a client certificate"))
+			b.WriteString("\n")
+		} else {
+			visibleItems := (m.height - 10) / 4
+			if visibleItems < 1 {
+				visibleItems = 1
+			}
+			endIdx := m.scrollOffset + visibleItems
+			if endIdx > len(m.identities) {
+				endIdx = len(m.identities)
+			}
+
+			now := time.Now()
+			for i := m.scrollOffset; i < endIdx; i++ {
+				identity := m.identities[i]
+				expiry, warn := expiryText(identity.NotAfter, now)
+				if warn && i != m.selectedIdx {
+					expiry = warningStyle.Render("⚠ " + expiry)
+				} else if warn {
+					expiry = "⚠ " + expiry
+				}
+
+				scopes := "not scoped: presented only when chosen"
+				if len(identity.Scopes) > 0 {
+					scopes = "scopes: " + strings.Join(identity.Scopes, ", ")
+				}
+
+				line := fmt.Sprintf("%s  (CN=%s, %s)\n  %s\n  %s\n  %s",
+					identity.Name,
+					identity.CommonName,
+					identity.KeyType,
+					expiry,
+					scopes,
+					shortFingerprint(identity.Fingerprint))
+				if i == m.selectedIdx {
+					b.WriteString(selectedStyle.Render(line))
+				} else {
+					b.WriteString(normalStyle.Render(line))
+				}
+				b.WriteString("\n")
+			}
+		}
+
+		helpText := "j/k: move • n: new • s: scopes • u: used on • d: delete • esc/q: close"
+		if m.chooseURL != "" {
+			helpText = "j/k: move • enter: use for this request • n: new • esc: cancel"
+		}
+		if m.pendingDelete != "" {
+			helpText = fmt.Sprintf("Press d again to delete %s permanently", m.pendingDelete)
+		}
+		b.WriteString(helpStyle.Render(helpText))
+	}
+
+	content := borderStyle.Render(b.String())
+
+	// Center the modal
+	contentHeight := strings.Count(content, "\n") + 1
+	contentWidth := modalWidth + 6 // Account for border and padding
+
+	topPadding := (m.height - contentHeight) / 2
+	if topPadding < 0 {
+		topPadding = 0
+	}
+
+	leftPadding := (m.width - contentWidth) / 2
+	if leftPadding < 0 {
+		leftPadding = 0
+	}
+
+	result := strings.Repeat("\n", topPadding)
+	for _, line := range strings.Split(content, "\n") {
+		result += strings.Repeat(" ", leftPadding) + line + "\n"
+	}
+
+	return result
+}
+
+// usageView lists the URLs the selected identity was presented on
+func (m *IdentitiesModal) usageView(modalWidth int, titleStyle, helpStyle, emptyStyle lipgloss.Style) string {
+	identity := m.identities[m.selectedIdx]
+
+	var b strings.Builder
+	b.WriteString(titleStyle.Render(fmt.Sprintf("%s was used on (%d)", identity.Name, len(identity.UsedOn))))
+	b.WriteString("\n")
+
+	if len(identity.UsedOn) == 0 {
+		b.WriteString(emptyStyle.Render("Not presented to any capsule yet"))
+		b.WriteString("\n")
+	}
+	maxLines := m.height - 12
+	if maxLines < 1 {
+		maxLines = 1
+	}
+	for i, used := range identity.UsedOn {
+		if i == maxLines {
+			b.WriteString(fmt.Sprintf("… and %d more", len(identity.UsedOn)-maxLines))
+			b.WriteString("\n")
+			break
+		}
+		if len(used) > modalWidth-6 {
+			used = used[:modalWidth-9] + "..."
+		}
+		b.WriteString(used)
+		b.WriteString("\n")
+	}
+
+	b.WriteString(helpStyle.Render("most recent first • esc/u: back"))
+	return b.String()
+}
+
+// createFormView renders the new identity form
+func (m *IdentitiesModal) createFormView(modalWidth int, titleStyle, helpStyle, errorStyle lipgloss.Style) string {
+	labelStyle := lipgloss.NewStyle().
+		Foreground(lipgloss.Color("8")).
+		Bold(true)
+
+	activeLabelStyle := lipgloss.NewStyle().
+		Foreground(lipgloss.Color("12")).
+		Bold(true)
+
+	var b strings.Builder
+	b.WriteString(titleStyle.Render("New Identity"))
+	b.WriteString("\n")
+
+	labels := []string{"Name", "Common name", "Key type", "Valid for (days)"}
+	for i, label := range labels {
+		if i == m.createFocus {
+			b.WriteString(activeLabelStyle.Render(label))
+		} else {
+			b.WriteString(labelStyle.Render(label))
+		}
+		b.WriteString("\n")
+		if i == identityFieldKeyType {
+			var options []string
+			for j, keyType := range identityKeyTypes {
+				if j == m.keyTypeIdx {
+					options = append(options, "("+keyType+")")
+				} else {
+					options = append(options, " "+keyType+" ")
+				}
+			}
+			b.WriteString(strings.Join(options, "  "))
+		} else {
+			m.createInputs[i].Width = modalWidth - 12
+			b.WriteString(m.createInputs[i].View())
+		}
+		b.WriteString("\n\n")
+	}
+
+	if m.formError != "" {
+		b.WriteString(errorStyle.Render(m.formError))
+		b.WriteString("\n")
+	}
+	b.WriteString(helpStyle.Render("tab: next field • ←/→: key type • enter: create • esc: cancel"))
+	return b.String()
+}
+
+// shortFingerprint shortens a SHA-256 fingerprint for display
+func shortFingerprint(fp string) string {
+	if len(fp) < 16 {
+		return fp
+	}
+	return "SHA256:" + fp[:16] + "…"
+}
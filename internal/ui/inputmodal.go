@@ -1,11 +1,13 @@
 package ui
 
 import (
+	"fmt"
 	"strings"
 
 	"github.com/charmbracelet/bubbles/textinput"
 	tea "github.com/charmbracelet/bubbletea"
 	"github.com/charmbracelet/lipgloss"
+	"starsearch/internal/textutil"
 )
 
 // InputSubmitMsg is sent when the user submits input
@@ -16,13 +18,26 @@ type InputSubmitMsg struct {
 // InputCancelMsg is sent when the user cancels input
 type InputCancelMsg struct{}
 
+// InputEditMsg asks for the answer to be written in an external editor,
+// starting from the text typed so far
+type InputEditMsg struct {
+	Text string
+}
+
 // InputModal displays a prompt and text input for user input
 type InputModal struct {
 	width     int
 	height    int
 	prompt    string
 	input     textinput.Model
-	sensitive bool // Whether this is sensitive input (masked)
+	sensitive bool     // Whether this is sensitive input (masked)
+	history   []string // Previous answers to this prompt, most recent first
+	recallIdx int      // Index into history being shown, or -1 for the draft
+	draft     string   // Text typed before recalling history
+	step      int      // Position of this prompt within an input session
+	host      string   // Host that sent the prompt
+	spelling  bool     // Whether likely misspellings are highlighted
+	visible   bool
 }
 
 // NewInputModal creates a new input modal
@@ -53,10 +68,15 @@ func (m *InputModal) SetSize(width, height int) {
 	m.input.Width = inputWidth
 }
 
-// Show displays the input modal with a prompt
-func (m *InputModal) Show(prompt string, sensitive bool) tea.Cmd {
-	m.prompt = prompt
+// Show displays the input modal with a prompt and any previous answers
+// that can be recalled with up/down
+func (m *InputModal) Show(prompt string, sensitive bool, history []string) tea.Cmd {
+	m.visible = true
+	m.prompt = textutil.Sanitize(prompt) // As the capsule sent it
 	m.sensitive = sensitive
+	m.history = history
+	m.recallIdx = -1
+	m.draft = ""
 	m.input.Reset()
 
 	if sensitive {
@@ -71,6 +91,22 @@ func (m *InputModal) Show(prompt string, sensitive bool) tea.Cmd {
 	return m.input.Focus()
 }
 
+// Hide hides the input modal
+func (m *InputModal) Hide() {
+	m.visible = false
+	m.input.Blur()
+}
+
+// IsVisible returns whether the input modal is visible
+func (m *InputModal) IsVisible() bool {
+	return m.visible
+}
+
+// TakesText reports that the input modal always takes typed text
+func (m *InputModal) TakesText() bool {
+	return true
+}
+
 // Update handles input events
 func (m *InputModal) Update(msg tea.Msg) (*InputModal, tea.Cmd) {
 	var cmd tea.Cmd
@@ -88,6 +124,41 @@ func (m *InputModal) Update(msg tea.Msg) (*InputModal, tea.Cmd) {
 			return m, func() tea.Msg {
 				return InputCancelMsg{}
 			}
+		case "ctrl+s":
+			m.spelling = !m.spelling
+			return m, nil
+		case "ctrl+e":
+			// Sensitive answers stay out of files on disk
+			if m.sensitive {
+				return m, nil
+			}
+			text := m.input.Value()
+			return m, func() tea.Msg {
+				return InputEditMsg{Text: text}
+			}
+		case "up":
+			// Recall an older answer
+			if m.recallIdx < len(m.history)-1 {
+				if m.recallIdx == -1 {
+					m.draft = m.input.Value()
+				}
+				m.recallIdx++
+				m.input.SetValue(m.history[m.recallIdx])
+				m.input.CursorEnd()
+			}
+			return m, nil
+		case "down":
+			// Recall a newer answer, ending at the draft
+			if m.recallIdx >= 0 {
+				m.recallIdx--
+				if m.recallIdx == -1 {
+					m.input.SetValue(m.draft)
+				} else {
+					m.input.SetValue(m.history[m.recallIdx])
+				}
+				m.input.CursorEnd()
+			}
+			return m, nil
 		}
 	}
 
@@ -122,6 +193,9 @@ func (m *InputModal) View() string {
 		Italic(true).
 		MarginTop(1)
 
+	stepStyle := lipgloss.NewStyle().
+		Foreground(lipgloss.Color("14"))
+
 	sensitiveWarningStyle := lipgloss.NewStyle().
 		Foreground(lipgloss.Color("9")).
 		Italic(true).
@@ -137,6 +211,12 @@ func (m *InputModal) View() string {
 	content.WriteString(titleStyle.Render(title))
 	content.WriteString("\n\n")
 
+	// Show where we are in a multi-step session
+	if m.step > 1 {
+		content.WriteString(stepStyle.Render(fmt.Sprintf("%s prompt from %s", ordinal(m.step), m.host)))
+		content.WriteString("\n")
+	}
+
 	// Show prompt
 	if m.prompt != "" {
 		content.WriteString(promptStyle.Render(m.prompt))
@@ -152,13 +232,100 @@ func (m *InputModal) View() string {
 	// Show input field
 	content.WriteString(m.input.View())
 	content.WriteString("\n")
+	if spelling := m.spellingView(); spelling != "" {
+		content.WriteString(spelling)
+		content.WriteString("\n")
+	}
 
 	// Show help text
-	content.WriteString(helpStyle.Render("Press Enter to submit • Esc to cancel"))
+	help := "Press Enter to submit • Esc to cancel"
+	if m.step > 1 {
+		help = "Press Enter to submit • Esc to cancel the whole session"
+	}
+	if len(m.history) > 0 {
+		help += fmt.Sprintf(" • ↑/↓ previous answers (%d)", len(m.history))
+	}
+	if !m.sensitive {
+		help += " • Ctrl+E edit in $EDITOR"
+		if m.spelling {
+			help += " • Ctrl+S spelling off"
+		} else {
+			help += " • Ctrl+S spelling on"
+		}
+	}
+	content.WriteString(helpStyle.Render(help))
 
 	return containerStyle.Render(content.String())
 }
 
+// spellingView shows the answer wrapped to the field's width with likely
+// misspellings highlighted, and what they might be instead. It is empty
+// when spelling is off, the input is sensitive, or nothing is flagged.
+func (m *InputModal) spellingView() string {
+	if !m.spelling || m.sensitive {
+		return ""
+	}
+	value := m.input.Value()
+	found := findMisspellings(value)
+	if len(found) == 0 {
+		return ""
+	}
+
+	flagStyle := lipgloss.NewStyle().
+		Foreground(lipgloss.Color("9")).
+		Underline(true)
+	suggestionStyle := lipgloss.NewStyle().
+		Foreground(lipgloss.Color("8"))
+
+	text := newStyledText(value, nil)
+	var suggestions []string
+	for _, word := range found {
+		text = text.overlay(word.start, word.end, flagStyle)
+		suggestions = append(suggestions, value[word.start:word.end]+" → "+word.suggestion)
+	}
+
+	var b strings.Builder
+	rows, _ := text.wrap(m.input.Width)
+	for _, row := range rows {
+		b.WriteString(row.render())
+		b.WriteString("\n")
+	}
+	b.WriteString(suggestionStyle.Render("Did you mean: " + strings.Join(suggestions, ", ")))
+	return b.String()
+}
+
+// SetSpelling sets whether likely misspellings in answers are highlighted
+func (m *InputModal) SetSpelling(enabled bool) {
+	m.spelling = enabled
+}
+
+// SetStep records which prompt of a multi-step input session is shown.
+// Steps after the first are called out above the prompt.
+func (m *InputModal) SetStep(step int, host string) {
+	m.step = step
+	m.host = host
+}
+
+// ordinal formats n as "1st", "2nd", "3rd", "4th", ...
+func ordinal(n int) string {
+	suffix := "th"
+	switch {
+	case n%100 >= 11 && n%100 <= 13:
+	case n%10 == 1:
+		suffix = "st"
+	case n%10 == 2:
+		suffix = "nd"
+	case n%10 == 3:
+		suffix = "rd"
+	}
+	return fmt.Sprintf("%d%s", n, suffix)
+}
+
+// IsSensitive returns whether the current prompt is for sensitive input
+func (m *InputModal) IsSensitive() bool {
+	return m.sensitive
+}
+
 // IsFocused returns whether the input modal is currently focused
 func (m *InputModal) IsFocused() bool {
 	return m.input.Focused()
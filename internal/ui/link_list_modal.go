@@ -0,0 +1,237 @@
+package ui
+
+import (
+	"fmt"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"starsearch/internal/types"
+)
+
+// LinkListOpenMsg is sent when marked links should be opened in background tabs
+type LinkListOpenMsg struct {
+	URLs []string
+}
+
+// LinkListModal lists the links on the current page and lets several of
+// them be marked and opened at once
+type LinkListModal struct {
+	visible      bool
+	links        []types.Line
+	marked       map[int]bool
+	selectedIdx  int
+	scrollOffset int
+	width        int
+	height       int
+}
+
+// NewLinkListModal creates a new link list modal
+func NewLinkListModal() *LinkListModal {
+	return &LinkListModal{
+		marked: make(map[int]bool),
+	}
+}
+
+// SetSize sets the dimensions of the link list modal
+func (m *LinkListModal) SetSize(width, height int) {
+	m.width = width
+	m.height = height
+}
+
+// Show opens the modal with the links of doc
+func (m *LinkListModal) Show(doc *types.Document) {
+	m.visible = true
+	m.links = doc.Links
+	m.marked = make(map[int]bool)
+	m.selectedIdx = 0
+	m.scrollOffset = 0
+}
+
+// Hide closes the modal
+func (m *LinkListModal) Hide() {
+	m.visible = false
+}
+
+// IsVisible returns whether the modal is shown
+func (m *LinkListModal) IsVisible() bool {
+	return m.visible
+}
+
+// Update handles key presses while the modal is shown
+func (m *LinkListModal) Update(msg tea.Msg) (*LinkListModal, tea.Cmd) {
+	keyMsg, ok := msg.(tea.KeyMsg)
+	if !ok || !m.visible {
+		return m, nil
+	}
+
+	switch keyMsg.String() {
+	case "esc":
+		m.visible = false
+
+	case "up", "k":
+		if m.selectedIdx > 0 {
+			m.selectedIdx--
+		}
+
+	case "down", "j":
+		if m.selectedIdx < len(m.links)-1 {
+			m.selectedIdx++
+		}
+
+	case " ", "x":
+		// Toggle the mark on the selected link and move on
+		if len(m.links) > 0 {
+			m.marked[m.selectedIdx] = !m.marked[m.selectedIdx]
+			if m.selectedIdx < len(m.links)-1 {
+				m.selectedIdx++
+			}
+		}
+
+	case "a":
+		// Mark everything, or clear the marks if everything is marked
+		allMarked := m.markedCount() == len(m.links)
+		for i := range m.links {
+			m.marked[i] = !allMarked
+		}
+
+	case "enter":
+		if len(m.links) == 0 {
+			return m, nil
+		}
+		m.visible = false
+
+		// Without marks, Enter simply follows the selected link
+		if m.markedCount() == 0 {
+			url := m.links[m.selectedIdx].URL
+			return m, func() tea.Msg { return NavigateMsg{URL: url} }
+		}
+
+		urls := make([]string, 0, m.markedCount())
+		for i, link := range m.links {
+			if m.marked[i] {
+				urls = append(urls, link.URL)
+			}
+		}
+		return m, func() tea.Msg { return LinkListOpenMsg{URLs: urls} }
+	}
+
+	return m, nil
+}
+
+// markedCount returns the number of marked links
+func (m *LinkListModal) markedCount() int {
+	count := 0
+	for _, marked := range m.marked {
+		if marked {
+			count++
+		}
+	}
+	return count
+}
+
+// View renders the link list modal
+func (m *LinkListModal) View() string {
+	if !m.visible {
+		return ""
+	}
+
+	modalWidth := min(m.width-4, 100)
+	if modalWidth < 40 {
+		modalWidth = 40
+	}
+
+	titleStyle := lipgloss.NewStyle().
+		Bold(true).
+		Foreground(lipgloss.Color("12")).
+		Width(modalWidth).
+		Align(lipgloss.Center).
+		MarginBottom(1)
+
+	selectedStyle := lipgloss.NewStyle().
+		Background(lipgloss.Color("12")).
+		Foreground(lipgloss.Color("0")).
+		Bold(true).
+		Width(modalWidth - 8)
+
+	normalStyle := lipgloss.NewStyle().
+		Foreground(lipgloss.Color("15")).
+		Width(modalWidth - 8)
+
+	urlStyle := lipgloss.NewStyle().
+		Foreground(lipgloss.Color("8"))
+
+	helpStyle := lipgloss.NewStyle().
+		Foreground(lipgloss.Color("7")).
+		Width(modalWidth).
+		Align(lipgloss.Center).
+		MarginTop(1)
+
+	borderStyle := lipgloss.NewStyle().
+		Border(lipgloss.RoundedBorder()).
+		BorderForeground(lipgloss.Color("12")).
+		Padding(1, 2).
+		Width(modalWidth)
+
+	var b strings.Builder
+
+	b.WriteString(titleStyle.Render(fmt.Sprintf("Links on this page (%d, %d marked)", len(m.links), m.markedCount())))
+	b.WriteString("\n")
+
+	if len(m.links) == 0 {
+		b.WriteString(normalStyle.Render("This page has no links"))
+		b.WriteString("\n")
+	}
+
+	// Keep the selection within the visible window
+	visible := m.height - 12
+	if visible < 3 {
+		visible = 3
+	}
+	if m.selectedIdx < m.scrollOffset {
+		m.scrollOffset = m.selectedIdx
+	}
+	if m.selectedIdx >= m.scrollOffset+visible {
+		m.scrollOffset = m.selectedIdx - visible + 1
+	}
+	end := min(m.scrollOffset+visible, len(m.links))
+
+	for i := m.scrollOffset; i < end; i++ {
+		link := m.links[i]
+		mark := "[ ]"
+		if m.marked[i] {
+			mark = "[x]"
+		}
+		label := link.Text
+		if label == "" {
+			label = link.URL
+		}
+		line := truncateRunes(fmt.Sprintf(" %s %d. %s", mark, link.LinkNum, label), modalWidth-8)
+		if room := modalWidth - 8 - lipgloss.Width(line) - 2; room > 3 && label != link.URL {
+			line += "  " + urlStyle.Render(truncateRunes(link.URL, room))
+		}
+
+		if i == m.selectedIdx {
+			b.WriteString(selectedStyle.Render(line))
+		} else {
+			b.WriteString(normalStyle.Render(line))
+		}
+		b.WriteString("\n")
+	}
+
+	b.WriteString(helpStyle.Render("Space: Mark • A: All • Enter: Open in background tabs • Esc: Close"))
+
+	return borderStyle.Render(b.String())
+}
+
+// truncateRunes shortens s to at most n runes, ending in "..." if cut
+func truncateRunes(s string, n int) string {
+	runes := []rune(s)
+	if len(runes) <= n {
+		return s
+	}
+	if n <= 3 {
+		return string(runes[:n])
+	}
+	return string(runes[:n-3]) + "..."
+}
@@ -0,0 +1,207 @@
+package ui
+
+import (
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+)
+
+// LinkAction is something that can be done with a link from the link menu
+type LinkAction int
+
+const (
+	LinkOpen LinkAction = iota
+	LinkOpenNewTab
+	LinkOpenExternal
+	LinkViewExternal
+	LinkDownload
+	LinkCopyURL
+	LinkBookmark
+)
+
+// linkMenuItem is one entry in the link menu
+type linkMenuItem struct {
+	action LinkAction
+	key    string
+	label  string
+}
+
+// linkMenuItems lists the menu entries in display order
+var linkMenuItems = []linkMenuItem{
+	{LinkOpen, "o", "Open"},
+	{LinkOpenNewTab, "t", "Open in new tab"},
+	{LinkOpenExternal, "x", "Open externally"},
+	{LinkViewExternal, "v", "View in another program"},
+	{LinkDownload, "d", "Download"},
+	{LinkCopyURL, "c", "Copy URL"},
+	{LinkBookmark, "b", "Add bookmark"},
+}
+
+// LinkMenuMsg is sent to open the link menu for a link
+type LinkMenuMsg struct {
+	URL   string
+	Label string
+}
+
+// LinkActionMsg is sent when an entry is chosen from the link menu
+type LinkActionMsg struct {
+	Action LinkAction
+	URL    string
+	Label  string
+}
+
+// LinkMenu offers the actions available for a single link
+type LinkMenu struct {
+	visible     bool
+	url         string
+	label       string
+	selectedIdx int
+	width       int
+	height      int
+}
+
+// NewLinkMenu creates a new link menu
+func NewLinkMenu() *LinkMenu {
+	return &LinkMenu{}
+}
+
+// SetSize sets the dimensions of the link menu
+func (m *LinkMenu) SetSize(width, height int) {
+	m.width = width
+	m.height = height
+}
+
+// Show opens the menu for the link to url with the given label
+func (m *LinkMenu) Show(url, label string) {
+	m.visible = true
+	m.url = url
+	m.label = label
+	m.selectedIdx = 0
+}
+
+// Hide closes the menu
+func (m *LinkMenu) Hide() {
+	m.visible = false
+}
+
+// IsVisible returns whether the menu is shown
+func (m *LinkMenu) IsVisible() bool {
+	return m.visible
+}
+
+// Update handles key presses while the menu is shown
+func (m *LinkMenu) Update(msg tea.Msg) (*LinkMenu, tea.Cmd) {
+	keyMsg, ok := msg.(tea.KeyMsg)
+	if !ok || !m.visible {
+		return m, nil
+	}
+
+	switch keyMsg.String() {
+	case "esc":
+		m.visible = false
+		return m, nil
+	case "up", "k":
+		if m.selectedIdx > 0 {
+			m.selectedIdx--
+		}
+		return m, nil
+	case "down", "j":
+		if m.selectedIdx < len(linkMenuItems)-1 {
+			m.selectedIdx++
+		}
+		return m, nil
+	case "enter":
+		return m, m.choose(linkMenuItems[m.selectedIdx].action)
+	}
+
+	// Each entry can also be picked by its shortcut key
+	for _, item := range linkMenuItems {
+		if keyMsg.String() == item.key {
+			return m, m.choose(item.action)
+		}
+	}
+	return m, nil
+}
+
+// choose closes the menu and reports the chosen action
+func (m *LinkMenu) choose(action LinkAction) tea.Cmd {
+	m.visible = false
+	url, label := m.url, m.label
+	return func() tea.Msg {
+		return LinkActionMsg{Action: action, URL: url, Label: label}
+	}
+}
+
+// View renders the link menu
+func (m *LinkMenu) View() string {
+	if !m.visible {
+		return ""
+	}
+
+	modalWidth := min(m.width-4, 80)
+	if modalWidth < 40 {
+		modalWidth = 40
+	}
+
+	titleStyle := lipgloss.NewStyle().
+		Bold(true).
+		Foreground(lipgloss.Color("12")).
+		Width(modalWidth).
+		Align(lipgloss.Center).
+		MarginBottom(1)
+
+	urlStyle := lipgloss.NewStyle().
+		Foreground(lipgloss.Color("8")).
+		Width(modalWidth - 4).
+		MarginBottom(1)
+
+	keyStyle := lipgloss.NewStyle().
+		Foreground(lipgloss.Color("10"))
+
+	selectedStyle := lipgloss.NewStyle().
+		Background(lipgloss.Color("12")).
+		Foreground(lipgloss.Color("0")).
+		Bold(true).
+		Width(modalWidth - 8)
+
+	normalStyle := lipgloss.NewStyle().
+		Foreground(lipgloss.Color("15")).
+		Width(modalWidth - 8)
+
+	helpStyle := lipgloss.NewStyle().
+		Foreground(lipgloss.Color("7")).
+		Width(modalWidth).
+		Align(lipgloss.Center).
+		MarginTop(1)
+
+	borderStyle := lipgloss.NewStyle().
+		Border(lipgloss.RoundedBorder()).
+		BorderForeground(lipgloss.Color("12")).
+		Padding(1, 2).
+		Width(modalWidth)
+
+	var b strings.Builder
+
+	title := m.label
+	if title == "" {
+		title = "Link"
+	}
+	b.WriteString(titleStyle.Render(title))
+	b.WriteString("\n")
+	b.WriteString(urlStyle.Render(m.url))
+	b.WriteString("\n")
+
+	for i, item := range linkMenuItems {
+		if i == m.selectedIdx {
+			b.WriteString(selectedStyle.Render(" " + item.key + "  " + item.label))
+		} else {
+			b.WriteString(normalStyle.Render(" " + keyStyle.Render(item.key) + "  " + item.label))
+		}
+		b.WriteString("\n")
+	}
+
+	b.WriteString(helpStyle.Render("↑/↓: Select • Enter or key: Choose • Esc: Cancel"))
+
+	return borderStyle.Render(b.String())
+}
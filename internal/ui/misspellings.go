@@ -0,0 +1,352 @@
+package ui
+
+// misspellings maps commonly misspelled English words, in lower case, to
+// their correct spelling. It is kept to mistakes that are never real
+// words, so flagging them is safe without a full dictionary.
+var misspellings = map[string]string{
+	"abscence":        "absence",
+	"acceptible":      "acceptable",
+	"accidently":      "accidentally",
+	"accomodate":      "accommodate",
+	"accomodation":    "accommodation",
+	"accross":         "across",
+	"acheive":         "achieve",
+	"acheived":        "achieved",
+	"acknowlege":      "acknowledge",
+	"acommodate":      "accommodate",
+	"acutally":        "actually",
+	"adn":             "and",
+	"adress":          "address",
+	"adressed":        "addressed",
+	"adresses":        "addresses",
+	"advertisment":    "advertisement",
+	"aggresive":       "aggressive",
+	"agressive":       "aggressive",
+	"allready":        "already",
+	"alltogether":     "altogether",
+	"alot":            "a lot",
+	"amatuer":         "amateur",
+	"anual":           "annual",
+	"anwser":          "answer",
+	"apparantly":      "apparently",
+	"appearence":      "appearance",
+	"aquaintance":     "acquaintance",
+	"aquire":          "acquire",
+	"arent":           "aren't",
+	"arguement":       "argument",
+	"assasination":    "assassination",
+	"assistent":       "assistant",
+	"athiest":         "atheist",
+	"attendence":      "attendance",
+	"availble":        "available",
+	"awfull":          "awful",
+	"basicly":         "basically",
+	"beautifull":      "beautiful",
+	"becasue":         "because",
+	"becuase":         "because",
+	"beggining":       "beginning",
+	"begining":        "beginning",
+	"beleive":         "believe",
+	"beleived":        "believed",
+	"belive":          "believe",
+	"benifit":         "benefit",
+	"bizzare":         "bizarre",
+	"brillant":        "brilliant",
+	"buisness":        "business",
+	"calandar":        "calendar",
+	"calender":        "calendar",
+	"camoflage":       "camouflage",
+	"carribean":       "Caribbean",
+	"catagories":      "categories",
+	"catagory":        "category",
+	"cemetary":        "cemetery",
+	"certian":         "certain",
+	"challange":       "challenge",
+	"changable":       "changeable",
+	"charactor":       "character",
+	"cheif":           "chief",
+	"collegue":        "colleague",
+	"comittee":        "committee",
+	"comming":         "coming",
+	"commited":        "committed",
+	"comparision":     "comparison",
+	"competetion":     "competition",
+	"completly":       "completely",
+	"concensus":       "consensus",
+	"concieve":        "conceive",
+	"concious":        "conscious",
+	"condemed":        "condemned",
+	"confortable":     "comfortable",
+	"congradulations": "congratulations",
+	"consciencious":   "conscientious",
+	"consistant":      "consistent",
+	"contraversy":     "controversy",
+	"convienient":     "convenient",
+	"copywrite":       "copyright",
+	"correspondance":  "correspondence",
+	"coudl":           "could",
+	"critisism":       "criticism",
+	"curiousity":      "curiosity",
+	"decieve":         "deceive",
+	"definately":      "definitely",
+	"definatly":       "definitely",
+	"definetly":       "definitely",
+	"definitly":       "definitely",
+	"dependant":       "dependent",
+	"desparate":       "desperate",
+	"developement":    "development",
+	"devided":         "divided",
+	"didnt":           "didn't",
+	"diffrence":       "difference",
+	"diffrent":        "different",
+	"dilema":          "dilemma",
+	"disapoint":       "disappoint",
+	"discription":     "description",
+	"dissapear":       "disappear",
+	"dissapoint":      "disappoint",
+	"doesnt":          "doesn't",
+	"dont":            "don't",
+	"eigth":           "eighth",
+	"eleminate":       "eliminate",
+	"embarass":        "embarrass",
+	"embarassing":     "embarrassing",
+	"embarrased":      "embarrassed",
+	"enviroment":      "environment",
+	"equiptment":      "equipment",
+	"exagerate":       "exaggerate",
+	"excelent":        "excellent",
+	"exellent":        "excellent",
+	"existance":       "existence",
+	"experiance":      "experience",
+	"explaination":    "explanation",
+	"facinating":      "fascinating",
+	"familar":         "familiar",
+	"febuary":         "February",
+	"finaly":          "finally",
+	"firey":           "fiery",
+	"foriegn":         "foreign",
+	"forseeable":      "foreseeable",
+	"fourty":          "forty",
+	"fowards":         "forwards",
+	"freind":          "friend",
+	"freinds":         "friends",
+	"freqently":       "frequently",
+	"fullfil":         "fulfil",
+	"garantee":        "guarantee",
+	"gaurd":           "guard",
+	"generaly":        "generally",
+	"genuinly":        "genuinely",
+	"glamourous":      "glamorous",
+	"goverment":       "government",
+	"grammer":         "grammar",
+	"gratefull":       "grateful",
+	"greatful":        "grateful",
+	"guidence":        "guidance",
+	"gurantee":        "guarantee",
+	"happend":         "happened",
+	"harrass":         "harass",
+	"heighth":         "height",
+	"heirarchy":       "hierarchy",
+	"hieght":          "height",
+	"hopefuly":        "hopefully",
+	"hte":             "the",
+	"humerous":        "humorous",
+	"hygene":          "hygiene",
+	"hypocracy":       "hypocrisy",
+	"ignorence":       "ignorance",
+	"im":              "I'm",
+	"imediately":      "immediately",
+	"immediatly":      "immediately",
+	"immitate":        "imitate",
+	"incidently":      "incidentally",
+	"independant":     "independent",
+	"indispensible":   "indispensable",
+	"innoculate":      "inoculate",
+	"inteligence":     "intelligence",
+	"interupt":        "interrupt",
+	"intresting":      "interesting",
+	"irrelevent":      "irrelevant",
+	"isnt":            "isn't",
+	"ive":             "I've",
+	"knowlegde":       "knowledge",
+	"knowlege":        "knowledge",
+	"langauge":        "language",
+	"lenght":          "length",
+	"liason":          "liaison",
+	"libary":          "library",
+	"lightening":      "lightning",
+	"maintainance":    "maintenance",
+	"managment":       "management",
+	"manuever":        "manoeuvre",
+	"mathmatics":      "mathematics",
+	"medeval":         "medieval",
+	"mesage":          "message",
+	"millenium":       "millennium",
+	"miniscule":       "minuscule",
+	"mischievious":    "mischievous",
+	"mispell":         "misspell",
+	"naturaly":        "naturally",
+	"neccessarily":    "necessarily",
+	"neccessary":      "necessary",
+	"necessery":       "necessary",
+	"negociate":       "negotiate",
+	"nieghbor":        "neighbor",
+	"ninty":           "ninety",
+	"noticable":       "noticeable",
+	"nuisence":        "nuisance",
+	"occassion":       "occasion",
+	"occassionally":   "occasionally",
+	"occurance":       "occurrence",
+	"occured":         "occurred",
+	"occurence":       "occurrence",
+	"occuring":        "occurring",
+	"ocurred":         "occurred",
+	"oficial":         "official",
+	"ommission":       "omission",
+	"opinon":          "opinion",
+	"oppurtunity":     "opportunity",
+	"orignal":         "original",
+	"outragous":       "outrageous",
+	"paralel":         "parallel",
+	"parliment":       "parliament",
+	"particulary":     "particularly",
+	"pasttime":        "pastime",
+	"peice":           "piece",
+	"percieve":        "perceive",
+	"perhapes":        "perhaps",
+	"perseverence":    "perseverance",
+	"persistant":      "persistent",
+	"personel":        "personnel",
+	"pharoah":         "pharaoh",
+	"physican":        "physician",
+	"playwrite":       "playwright",
+	"posession":       "possession",
+	"posible":         "possible",
+	"potatos":         "potatoes",
+	"practicle":       "practical",
+	"preceed":         "precede",
+	"prefered":        "preferred",
+	"pregnent":        "pregnant",
+	"presance":        "presence",
+	"privelege":       "privilege",
+	"priviledge":      "privilege",
+	"probaly":         "probably",
+	"probly":          "probably",
+	"proccess":        "process",
+	"proffesional":    "professional",
+	"programing":      "programming",
+	"promiss":         "promise",
+	"pronounciation":  "pronunciation",
+	"propoganda":      "propaganda",
+	"publically":      "publicly",
+	"quarentine":      "quarantine",
+	"questionaire":    "questionnaire",
+	"readible":        "readable",
+	"realy":           "really",
+	"reccomend":       "recommend",
+	"recieve":         "receive",
+	"recieved":        "received",
+	"recieves":        "receives",
+	"recieving":       "receiving",
+	"recogize":        "recognize",
+	"recomend":        "recommend",
+	"reconize":        "recognize",
+	"referance":       "reference",
+	"refered":         "referred",
+	"refering":        "referring",
+	"relevent":        "relevant",
+	"religous":        "religious",
+	"rember":          "remember",
+	"remeber":         "remember",
+	"repitition":      "repetition",
+	"resistence":      "resistance",
+	"responsability":  "responsibility",
+	"restaraunt":      "restaurant",
+	"resturant":       "restaurant",
+	"rythm":           "rhythm",
+	"sacreligious":    "sacrilegious",
+	"saftey":          "safety",
+	"sandwhich":       "sandwich",
+	"scedule":         "schedule",
+	"secratary":       "secretary",
+	"seige":           "siege",
+	"sentance":        "sentence",
+	"seperate":        "separate",
+	"seperately":      "separately",
+	"shedule":         "schedule",
+	"shoud":           "should",
+	"shoudl":          "should",
+	"sieze":           "seize",
+	"similarily":      "similarly",
+	"similiar":        "similar",
+	"sincerly":        "sincerely",
+	"sofware":         "software",
+	"somthing":        "something",
+	"speach":          "speech",
+	"strech":          "stretch",
+	"strenght":        "strength",
+	"studing":         "studying",
+	"substancial":     "substantial",
+	"succede":         "succeed",
+	"succesful":       "successful",
+	"successfull":     "successful",
+	"sucessful":       "successful",
+	"suggestted":      "suggested",
+	"supercede":       "supersede",
+	"supose":          "suppose",
+	"suprise":         "surprise",
+	"suprised":        "surprised",
+	"surley":          "surely",
+	"surprize":        "surprise",
+	"taht":            "that",
+	"techincal":       "technical",
+	"tecnology":       "technology",
+	"teh":             "the",
+	"temperture":      "temperature",
+	"tendancy":        "tendency",
+	"thankfull":       "thankful",
+	"thats":           "that's",
+	"theif":           "thief",
+	"therefor":        "therefore",
+	"theyre":          "they're",
+	"thier":           "their",
+	"threshhold":      "threshold",
+	"tomatos":         "tomatoes",
+	"tommorow":        "tomorrow",
+	"tommorrow":       "tomorrow",
+	"tought":          "thought",
+	"tounge":          "tongue",
+	"trafic":          "traffic",
+	"truely":          "truly",
+	"twelth":          "twelfth",
+	"tyrany":          "tyranny",
+	"underate":        "underrate",
+	"unforseen":       "unforeseen",
+	"unfortunatly":    "unfortunately",
+	"unneccessary":    "unnecessary",
+	"untill":          "until",
+	"upholstry":       "upholstery",
+	"usefull":         "useful",
+	"vaccum":          "vacuum",
+	"vaccuum":         "vacuum",
+	"varient":         "variant",
+	"vehical":         "vehicle",
+	"visable":         "visible",
+	"waht":            "what",
+	"wasnt":           "wasn't",
+	"wellcome":        "welcome",
+	"wether":          "whether",
+	"whats":           "what's",
+	"wheather":        "weather",
+	"whereever":       "wherever",
+	"wich":            "which",
+	"wierd":           "weird",
+	"withold":         "withhold",
+	"wonderfull":      "wonderful",
+	"woudl":           "would",
+	"wouldnt":         "wouldn't",
+	"writting":        "writing",
+	"yeild":           "yield",
+	"yesturday":       "yesterday",
+	"youre":           "you're",
+}
@@ -0,0 +1,196 @@
+package ui
+
+import (
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/charmbracelet/x/ansi"
+)
+
+// Modal is a dialog drawn over the page. The topmost open modal receives
+// all keyboard input.
+type Modal interface {
+	IsVisible() bool
+	View() string
+}
+
+// TextModal is implemented by modals that can take typed text, where q is
+// input rather than a way to close them
+type TextModal interface {
+	Modal
+	TakesText() bool
+}
+
+// MouseModal is implemented by modals that handle the mouse. The mouse is
+// ignored while any other modal is on top.
+type MouseModal interface {
+	Modal
+	HandlesMouse() bool
+}
+
+// Dialog is implemented by small modals drawn over what lies beneath them,
+// the page or another modal, instead of covering the screen
+type Dialog interface {
+	Modal
+	Overlay(background string) string
+}
+
+// ModalStack keeps the open modals in the order they were opened and routes
+// input to the topmost one. Esc backs out of the topmost modal; q and
+// Ctrl+C do the same unless it is taking typed text. A modal closes by
+// hiding itself, which drops it from the stack.
+type ModalStack struct {
+	layers []modalLayer
+}
+
+// modalLayer is an open modal with its Update method
+type modalLayer struct {
+	modal  Modal
+	update func(tea.Msg) tea.Cmd
+}
+
+// NewModalStack creates an empty modal stack
+func NewModalStack() *ModalStack {
+	return &ModalStack{}
+}
+
+// OpenModal puts an already shown modal on top of the stack, moving it
+// there if it was open below another
+func OpenModal[M interface {
+	Modal
+	Update(tea.Msg) (M, tea.Cmd)
+}](s *ModalStack, modal M) {
+	s.remove(modal)
+	s.layers = append(s.layers, modalLayer{
+		modal: modal,
+		update: func(msg tea.Msg) tea.Cmd {
+			_, cmd := modal.Update(msg)
+			return cmd
+		},
+	})
+}
+
+// Top returns the topmost open modal, or nil when none is open
+func (s *ModalStack) Top() Modal {
+	s.prune()
+	if len(s.layers) == 0 {
+		return nil
+	}
+	return s.layers[len(s.layers)-1].modal
+}
+
+// IsOpen returns whether modal is open, on top or below another
+func (s *ModalStack) IsOpen(modal Modal) bool {
+	s.prune()
+	for _, layer := range s.layers {
+		if layer.modal == modal {
+			return true
+		}
+	}
+	return false
+}
+
+// View renders the topmost modal. Dialogs are drawn over the modal below
+// them, or over the page rendered by page when there is none.
+func (s *ModalStack) View(page func() string) string {
+	s.prune()
+	return s.viewAt(len(s.layers)-1, page)
+}
+
+// viewAt renders the modal at index i with what lies beneath it
+func (s *ModalStack) viewAt(i int, page func() string) string {
+	if i < 0 {
+		return page()
+	}
+	modal := s.layers[i].modal
+	if dialog, ok := modal.(Dialog); ok {
+		return dialog.Overlay(s.viewAt(i-1, page))
+	}
+	return modal.View()
+}
+
+// TakesText returns whether the topmost modal is taking typed text
+func (s *ModalStack) TakesText() bool {
+	text, ok := s.Top().(TextModal)
+	return ok && text.TakesText()
+}
+
+// Update routes a key or mouse message to the topmost modal. Mouse
+// messages only reach modals that handle the mouse.
+func (s *ModalStack) Update(msg tea.Msg) tea.Cmd {
+	top := s.Top()
+	if top == nil {
+		return nil
+	}
+
+	switch keyMsg := msg.(type) {
+	case tea.KeyMsg:
+		switch keyMsg.String() {
+		case "q", "ctrl+c":
+			if !s.TakesText() {
+				msg = tea.KeyMsg{Type: tea.KeyEsc}
+			}
+		}
+	case tea.MouseMsg:
+		if mouse, ok := top.(MouseModal); !ok || !mouse.HandlesMouse() {
+			return nil
+		}
+	}
+
+	cmd := s.layers[len(s.layers)-1].update(msg)
+	s.prune()
+	return cmd
+}
+
+// prune drops modals that have hidden themselves
+func (s *ModalStack) prune() {
+	open := s.layers[:0]
+	for _, layer := range s.layers {
+		if layer.modal.IsVisible() {
+			open = append(open, layer)
+		}
+	}
+	s.layers = open
+}
+
+// remove drops modal from the stack
+func (s *ModalStack) remove(modal Modal) {
+	for i, layer := range s.layers {
+		if layer.modal == modal {
+			s.layers = append(s.layers[:i], s.layers[i+1:]...)
+			return
+		}
+	}
+}
+
+// overlay draws box centered over background, a screen of width by height
+// cells, keeping the background visible around it
+func overlay(background, box string, width, height int) string {
+	lines := strings.Count(strings.TrimSuffix(background, "\n"), "\n") + 1
+	boxHeight := strings.Count(box, "\n") + 1
+	top := max((max(lines, height)-boxHeight)/2, 0)
+	left := max((width-lipgloss.Width(box))/2, 0)
+	return overlayAt(background, box, left, top)
+}
+
+// overlayAt draws box over background with its top left corner at column
+// left of line top, padding the background where it is too short
+func overlayAt(background, box string, left, top int) string {
+	lines := strings.Split(strings.TrimSuffix(background, "\n"), "\n")
+	boxWidth := lipgloss.Width(box)
+
+	for i, boxLine := range strings.Split(box, "\n") {
+		row := top + i
+		for row >= len(lines) {
+			lines = append(lines, "")
+		}
+		line := lines[row]
+		prefix := ansi.Truncate(line, left, "")
+		if gap := left - ansi.StringWidth(prefix); gap > 0 {
+			prefix += strings.Repeat(" ", gap)
+		}
+		lines[row] = prefix + "\x1b[0m" + boxLine + "\x1b[0m" + ansi.TruncateLeft(line, left+boxWidth, "")
+	}
+	return strings.Join(lines, "\n")
+}
@@ -0,0 +1,160 @@
+package ui
+
+import (
+	"fmt"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"starsearch/internal/gemini"
+	"starsearch/internal/gopher"
+	"starsearch/internal/types"
+)
+
+// PageInfoModal displays details of the current page, including any
+// out-of-spec input the parser had to work around
+type PageInfoModal struct {
+	width   int
+	height  int
+	visible bool
+	doc     *types.Document
+	strict  bool // Whether to list protocol violations too
+}
+
+// NewPageInfoModal creates a new page info modal
+func NewPageInfoModal() *PageInfoModal {
+	return &PageInfoModal{}
+}
+
+// SetSize sets the dimensions of the page info modal
+func (p *PageInfoModal) SetSize(width, height int) {
+	p.width = width
+	p.height = height
+}
+
+// Show displays the details of doc. In strict mode the protocol violations
+// found while loading the page are listed too.
+func (p *PageInfoModal) Show(doc *types.Document, strict bool) {
+	p.visible = true
+	p.doc = doc
+	p.strict = strict
+}
+
+// Hide hides the page info modal
+func (p *PageInfoModal) Hide() {
+	p.visible = false
+	p.doc = nil
+}
+
+// IsVisible returns whether the page info modal is visible
+func (p *PageInfoModal) IsVisible() bool {
+	return p.visible
+}
+
+// Update closes the page info modal on Esc or a second "i"
+func (p *PageInfoModal) Update(msg tea.Msg) (*PageInfoModal, tea.Cmd) {
+	if keyMsg, ok := msg.(tea.KeyMsg); ok {
+		switch keyMsg.String() {
+		case "esc", "i":
+			p.Hide()
+		}
+	}
+	return p, nil
+}
+
+// View renders the page info modal
+func (p *PageInfoModal) View() string {
+	doc, strict := p.doc, p.strict
+	if doc == nil {
+		return ""
+	}
+	titleStyle := lipgloss.NewStyle().
+		Bold(true).
+		Foreground(lipgloss.Color("12")).
+		Background(lipgloss.Color("236")).
+		Padding(0, 2).
+		Width(p.width)
+
+	headerStyle := lipgloss.NewStyle().
+		Bold(true).
+		Foreground(lipgloss.Color("11")).
+		MarginTop(1)
+
+	keyStyle := lipgloss.NewStyle().
+		Foreground(lipgloss.Color("10")).
+		Width(14)
+
+	descStyle := lipgloss.NewStyle().
+		Foreground(lipgloss.Color("7"))
+
+	warningStyle := lipgloss.NewStyle().
+		Foreground(lipgloss.Color("11"))
+
+	containerStyle := lipgloss.NewStyle().
+		Border(lipgloss.RoundedBorder()).
+		BorderForeground(lipgloss.Color("12")).
+		Padding(1, 2).
+		Width(p.width - 4)
+
+	dismissStyle := lipgloss.NewStyle().
+		Foreground(lipgloss.Color("8")).
+		Italic(true).
+		MarginTop(1)
+
+	var content strings.Builder
+	content.WriteString(titleStyle.Render("PAGE INFO"))
+	content.WriteString("\n\n")
+
+	row := func(label, value string) {
+		content.WriteString(keyStyle.Render(label) + descStyle.Render(value))
+		content.WriteString("\n")
+	}
+	row("URL", doc.URL)
+	if doc.Metadata.Protocol != "" {
+		row("Protocol", doc.Metadata.Protocol)
+	}
+	if doc.Metadata.Protocol == "gemini" {
+		row("Status", fmt.Sprintf("%d %s", doc.Metadata.Status, gemini.GetStatusMessage(doc.Metadata.Status)))
+	}
+	row("Type", doc.MIMEType)
+	if doc.ItemType != "" {
+		row("Gopher item", fmt.Sprintf("%s (%s)", doc.ItemType, gopher.GetItemTypeDescription(doc.ItemType)))
+	}
+	row("Size", fmt.Sprintf("%d bytes", len(doc.RawBody)))
+	if doc.Metadata.LineEndings != "" {
+		row("Line endings", doc.Metadata.LineEndings)
+	}
+	if !doc.Metadata.Fetched.IsZero() {
+		row("Fetched", doc.Metadata.Fetched.Format("2006-01-02 15:04:05"))
+	}
+	row("Lines", fmt.Sprintf("%d", len(doc.Lines)))
+	row("Links", fmt.Sprintf("%d", len(doc.Links)))
+
+	content.WriteString(headerStyle.Render("Parse warnings"))
+	content.WriteString("\n")
+	if len(doc.Warnings) == 0 {
+		content.WriteString(descStyle.Render("None"))
+		content.WriteString("\n")
+	}
+	for _, warning := range doc.Warnings {
+		content.WriteString(warningStyle.Render(fmt.Sprintf("%5d× ", warning.Count)) + descStyle.Render(warning.Message))
+		content.WriteString("\n")
+	}
+
+	if strict {
+		content.WriteString(headerStyle.Render("Protocol violations"))
+		content.WriteString("\n")
+		if len(doc.Violations) == 0 {
+			content.WriteString(descStyle.Render("None"))
+			content.WriteString("\n")
+		}
+		for _, violation := range doc.Violations {
+			content.WriteString(warningStyle.Render("  • ") + descStyle.Render(violation))
+			content.WriteString("\n")
+		}
+	}
+
+	content.WriteString(dismissStyle.Render("\nPress Esc or Q to close"))
+
+	return containerStyle.Render(content.String())
+}
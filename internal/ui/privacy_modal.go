@@ -0,0 +1,281 @@
+package ui
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/charmbracelet/bubbles/key"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"starsearch/internal/types"
+)
+
+// PrivacyModal lists the hosts allowed to keep capsule tokens, lets the
+// user allow the current host and revokes tokens
+type PrivacyModal struct {
+	visible      bool
+	tokens       []types.CapsuleToken
+	host         string // Host of the current page
+	selectedIdx  int
+	width        int
+	height       int
+	scrollOffset int
+}
+
+// TokenConsentMsg is sent to allow or stop a host keeping a token
+type TokenConsentMsg struct {
+	Host  string
+	Allow bool
+}
+
+// TokenRevokeMsg is sent to forget a host's token and its permission
+type TokenRevokeMsg struct {
+	Host string
+}
+
+func NewPrivacyModal() *PrivacyModal {
+	return &PrivacyModal{}
+}
+
+// Show opens the modal with the stored tokens and the current page's host
+func (m *PrivacyModal) Show(tokens []types.CapsuleToken, host string) {
+	m.visible = true
+	m.host = host
+	m.selectedIdx = 0
+	m.scrollOffset = 0
+	m.Refresh(tokens)
+}
+
+// Refresh replaces the tokens while keeping the selection near its
+// previous position
+func (m *PrivacyModal) Refresh(tokens []types.CapsuleToken) {
+	m.tokens = tokens
+	if m.selectedIdx >= len(m.tokens) {
+		m.selectedIdx = len(m.tokens) - 1
+	}
+	if m.selectedIdx < 0 {
+		m.selectedIdx = 0
+	}
+	m.adjustScroll()
+}
+
+func (m *PrivacyModal) Hide() {
+	m.visible = false
+}
+
+func (m *PrivacyModal) IsVisible() bool {
+	return m.visible
+}
+
+func (m *PrivacyModal) SetSize(width, height int) {
+	m.width = width
+	m.height = height
+}
+
+// hostAllowed reports whether the current page's host may keep a token
+func (m *PrivacyModal) hostAllowed() bool {
+	for _, token := range m.tokens {
+		if token.Host == m.host {
+			return true
+		}
+	}
+	return false
+}
+
+func (m *PrivacyModal) Update(msg tea.Msg) (*PrivacyModal, tea.Cmd) {
+	keyMsg, ok := msg.(tea.KeyMsg)
+	if !m.visible || !ok {
+		return m, nil
+	}
+
+	switch {
+	case key.Matches(keyMsg, key.NewBinding(key.WithKeys("esc", "K"))):
+		m.Hide()
+
+	case key.Matches(keyMsg, key.NewBinding(key.WithKeys("j", "down"))):
+		if m.selectedIdx < len(m.tokens)-1 {
+			m.selectedIdx++
+			m.adjustScroll()
+		}
+
+	case key.Matches(keyMsg, key.NewBinding(key.WithKeys("k", "up"))):
+		if m.selectedIdx > 0 {
+			m.selectedIdx--
+			m.adjustScroll()
+		}
+
+	case key.Matches(keyMsg, key.NewBinding(key.WithKeys("a"))):
+		if m.host != "" {
+			host, allow := m.host, !m.hostAllowed()
+			return m, func() tea.Msg { return TokenConsentMsg{Host: host, Allow: allow} }
+		}
+
+	case key.Matches(keyMsg, key.NewBinding(key.WithKeys("d", "delete"))):
+		if m.selectedIdx < len(m.tokens) {
+			host := m.tokens[m.selectedIdx].Host
+			return m, func() tea.Msg { return TokenRevokeMsg{Host: host} }
+		}
+	}
+
+	return m, nil
+}
+
+func (m *PrivacyModal) adjustScroll() {
+	visibleHeight := m.height - 12
+	if visibleHeight < 1 {
+		visibleHeight = 1
+	}
+
+	if m.selectedIdx >= m.scrollOffset+visibleHeight {
+		m.scrollOffset = m.selectedIdx - visibleHeight + 1
+	}
+	if m.selectedIdx < m.scrollOffset {
+		m.scrollOffset = m.selectedIdx
+	}
+}
+
+func (m *PrivacyModal) View() string {
+	if !m.visible {
+		return ""
+	}
+
+	modalWidth := m.width - 4
+	if modalWidth < 50 {
+		modalWidth = 50
+	}
+	if modalWidth > 100 {
+		modalWidth = 100
+	}
+
+	titleStyle := lipgloss.NewStyle().
+		Bold(true).
+		Foreground(lipgloss.Color("12")).
+		Width(modalWidth).
+		Align(lipgloss.Center).
+		MarginBottom(1)
+
+	infoStyle := lipgloss.NewStyle().
+		Foreground(lipgloss.Color("7")).
+		Width(modalWidth - 4).
+		MarginBottom(1)
+
+	headerStyle := lipgloss.NewStyle().
+		Foreground(lipgloss.Color("8")).
+		Bold(true)
+
+	selectedStyle := lipgloss.NewStyle().
+		Background(lipgloss.Color("12")).
+		Foreground(lipgloss.Color("0")).
+		Bold(true).
+		Width(modalWidth - 4)
+
+	normalStyle := lipgloss.NewStyle().
+		Foreground(lipgloss.Color("15")).
+		Width(modalWidth - 4)
+
+	emptyStyle := lipgloss.NewStyle().
+		Foreground(lipgloss.Color("8")).
+		Italic(true).
+		Width(modalWidth).
+		Align(lipgloss.Center).
+		MarginTop(1).
+		MarginBottom(1)
+
+	helpStyle := lipgloss.NewStyle().
+		Foreground(lipgloss.Color("7")).
+		Width(modalWidth).
+		Align(lipgloss.Center).
+		MarginTop(1)
+
+	borderStyle := lipgloss.NewStyle().
+		Border(lipgloss.RoundedBorder()).
+		BorderForeground(lipgloss.Color("12")).
+		Padding(1, 2).
+		Width(modalWidth)
+
+	var b strings.Builder
+	b.WriteString(titleStyle.Render("Privacy: capsule tokens"))
+	b.WriteString("\n")
+
+	switch {
+	case m.host == "":
+		b.WriteString(infoStyle.Render("Open a capsule to allow it to keep a token."))
+	case m.hostAllowed():
+		b.WriteString(infoStyle.Render(fmt.Sprintf("%s may keep a token: the query it redirects to is sent again with later requests to it.", m.host)))
+	default:
+		b.WriteString(infoStyle.Render(fmt.Sprintf("%s keeps no token. Allow it only if the capsule needs a session token re-sent.", m.host)))
+	}
+	b.WriteString("\n")
+
+	// Token column takes what the host and age columns leave
+	hostWidth := 30
+	tokenWidth := modalWidth - 4 - hostWidth - 10
+	if len(m.tokens) == 0 {
+		b.WriteString(emptyStyle.Render("No capsule keeps a token"))
+		b.WriteString("\n")
+	} else {
+		b.WriteString(headerStyle.Render(fmt.Sprintf("%-*s%-*s%10s", hostWidth, "Host", tokenWidth, "Token", "Captured")))
+		b.WriteString("\n")
+
+		visibleHeight := m.height - 12
+		if visibleHeight < 1 {
+			visibleHeight = 1
+		}
+		endIdx := m.scrollOffset + visibleHeight
+		if endIdx > len(m.tokens) {
+			endIdx = len(m.tokens)
+		}
+
+		now := time.Now()
+		for i := m.scrollOffset; i < endIdx; i++ {
+			token := m.tokens[i]
+
+			host := token.Host
+			if len(host) > hostWidth-1 {
+				host = host[:hostWidth-4] + "..."
+			}
+			value, captured := "(none yet)", "-"
+			if token.Token != "" {
+				value = token.Token
+				captured = formatAge(now.Sub(time.Unix(token.Stored, 0)))
+			}
+			if len(value) > tokenWidth-1 {
+				value = value[:tokenWidth-4] + "..."
+			}
+			line := fmt.Sprintf("%-*s%-*s%10s", hostWidth, host, tokenWidth, value, captured)
+
+			if i == m.selectedIdx {
+				b.WriteString(selectedStyle.Render(line))
+			} else {
+				b.WriteString(normalStyle.Render(line))
+			}
+			b.WriteString("\n")
+		}
+	}
+
+	b.WriteString(helpStyle.Render("a: allow / stop this capsule • j/k: move • d: revoke • esc/q: close"))
+
+	content := borderStyle.Render(b.String())
+
+	// Center the modal
+	contentHeight := strings.Count(content, "\n") + 1
+	contentWidth := modalWidth + 6 // Account for border and padding
+
+	topPadding := (m.height - contentHeight) / 2
+	if topPadding < 0 {
+		topPadding = 0
+	}
+
+	leftPadding := (m.width - contentWidth) / 2
+	if leftPadding < 0 {
+		leftPadding = 0
+	}
+
+	result := strings.Repeat("\n", topPadding)
+	for _, line := range strings.Split(content, "\n") {
+		result += strings.Repeat(" ", leftPadding) + line + "\n"
+	}
+
+	return result
+}
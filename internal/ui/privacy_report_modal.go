@@ -0,0 +1,264 @@
+package ui
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/charmbracelet/bubbles/key"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"starsearch/internal/types"
+)
+
+// PrivacyReportModal lists, host by host, what starsearch sends to each
+// capsule or keeps about it, and forgets a host on request
+type PrivacyReportModal struct {
+	visible      bool
+	hosts        []types.HostPrivacy
+	selectedIdx  int
+	width        int
+	height       int
+	scrollOffset int
+}
+
+// PrivacyPurgeMsg is sent to forget everything kept about a host
+type PrivacyPurgeMsg struct {
+	Host string
+}
+
+func NewPrivacyReportModal() *PrivacyReportModal {
+	return &PrivacyReportModal{}
+}
+
+// Show opens the modal with the report
+func (m *PrivacyReportModal) Show(hosts []types.HostPrivacy) {
+	m.visible = true
+	m.selectedIdx = 0
+	m.scrollOffset = 0
+	m.Refresh(hosts)
+}
+
+// Refresh replaces the report while keeping the selection near its
+// previous position
+func (m *PrivacyReportModal) Refresh(hosts []types.HostPrivacy) {
+	m.hosts = hosts
+	if m.selectedIdx >= len(m.hosts) {
+		m.selectedIdx = len(m.hosts) - 1
+	}
+	if m.selectedIdx < 0 {
+		m.selectedIdx = 0
+	}
+	m.adjustScroll()
+}
+
+func (m *PrivacyReportModal) Hide() {
+	m.visible = false
+}
+
+func (m *PrivacyReportModal) IsVisible() bool {
+	return m.visible
+}
+
+func (m *PrivacyReportModal) SetSize(width, height int) {
+	m.width = width
+	m.height = height
+}
+
+func (m *PrivacyReportModal) Update(msg tea.Msg) (*PrivacyReportModal, tea.Cmd) {
+	keyMsg, ok := msg.(tea.KeyMsg)
+	if !m.visible || !ok {
+		return m, nil
+	}
+
+	switch {
+	case key.Matches(keyMsg, key.NewBinding(key.WithKeys("esc", "ctrl+p"))):
+		m.Hide()
+
+	case key.Matches(keyMsg, key.NewBinding(key.WithKeys("j", "down"))):
+		if m.selectedIdx < len(m.hosts)-1 {
+			m.selectedIdx++
+			m.adjustScroll()
+		}
+
+	case key.Matches(keyMsg, key.NewBinding(key.WithKeys("k", "up"))):
+		if m.selectedIdx > 0 {
+			m.selectedIdx--
+			m.adjustScroll()
+		}
+
+	case key.Matches(keyMsg, key.NewBinding(key.WithKeys("d", "delete"))):
+		if m.selectedIdx < len(m.hosts) {
+			host := m.hosts[m.selectedIdx].Host
+			return m, func() tea.Msg { return PrivacyPurgeMsg{Host: host} }
+		}
+	}
+
+	return m, nil
+}
+
+func (m *PrivacyReportModal) adjustScroll() {
+	visibleHeight := m.height - 12
+	if visibleHeight < 1 {
+		visibleHeight = 1
+	}
+
+	if m.selectedIdx >= m.scrollOffset+visibleHeight {
+		m.scrollOffset = m.selectedIdx - visibleHeight + 1
+	}
+	if m.selectedIdx < m.scrollOffset {
+		m.scrollOffset = m.selectedIdx
+	}
+}
+
+func (m *PrivacyReportModal) View() string {
+	if !m.visible {
+		return ""
+	}
+
+	modalWidth := m.width - 4
+	if modalWidth < 60 {
+		modalWidth = 60
+	}
+	if modalWidth > 110 {
+		modalWidth = 110
+	}
+
+	titleStyle := lipgloss.NewStyle().
+		Bold(true).
+		Foreground(lipgloss.Color("12")).
+		Width(modalWidth).
+		Align(lipgloss.Center).
+		MarginBottom(1)
+
+	infoStyle := lipgloss.NewStyle().
+		Foreground(lipgloss.Color("7")).
+		Width(modalWidth - 4).
+		MarginBottom(1)
+
+	headerStyle := lipgloss.NewStyle().
+		Foreground(lipgloss.Color("8")).
+		Bold(true)
+
+	selectedStyle := lipgloss.NewStyle().
+		Background(lipgloss.Color("12")).
+		Foreground(lipgloss.Color("0")).
+		Bold(true).
+		Width(modalWidth - 4)
+
+	normalStyle := lipgloss.NewStyle().
+		Foreground(lipgloss.Color("15")).
+		Width(modalWidth - 4)
+
+	emptyStyle := lipgloss.NewStyle().
+		Foreground(lipgloss.Color("8")).
+		Italic(true).
+		Width(modalWidth).
+		Align(lipgloss.Center).
+		MarginTop(1).
+		MarginBottom(1)
+
+	helpStyle := lipgloss.NewStyle().
+		Foreground(lipgloss.Color("7")).
+		Width(modalWidth).
+		Align(lipgloss.Center).
+		MarginTop(1)
+
+	borderStyle := lipgloss.NewStyle().
+		Border(lipgloss.RoundedBorder()).
+		BorderForeground(lipgloss.Color("12")).
+		Padding(1, 2).
+		Width(modalWidth)
+
+	var b strings.Builder
+	b.WriteString(titleStyle.Render("Privacy report"))
+	b.WriteString("\n")
+	b.WriteString(infoStyle.Render("Requests carry no browser name or version. What each capsule can link to you: client certificates presented, capsule tokens re-sent, input answers kept and when its certificate was first trusted."))
+	b.WriteString("\n")
+
+	// Certificates column takes what the fixed columns leave
+	hostWidth := 28
+	tokenWidth, inputsWidth, ageWidth := 9, 8, 9
+	certWidth := modalWidth - 4 - hostWidth - tokenWidth - inputsWidth - 2*ageWidth
+	if len(m.hosts) == 0 {
+		b.WriteString(emptyStyle.Render("Nothing is kept about any capsule"))
+		b.WriteString("\n")
+	} else {
+		b.WriteString(headerStyle.Render(fmt.Sprintf("%-*s%-*s%-*s%*s%*s%*s",
+			hostWidth, "Host", certWidth, "Certificates", tokenWidth, "Token",
+			inputsWidth, "Inputs", ageWidth, "Trusted", ageWidth, "Seen")))
+		b.WriteString("\n")
+
+		visibleHeight := m.height - 12
+		if visibleHeight < 1 {
+			visibleHeight = 1
+		}
+		endIdx := m.scrollOffset + visibleHeight
+		if endIdx > len(m.hosts) {
+			endIdx = len(m.hosts)
+		}
+
+		now := time.Now()
+		for i := m.scrollOffset; i < endIdx; i++ {
+			entry := m.hosts[i]
+
+			certs := "-"
+			if len(entry.Identities) > 0 {
+				certs = strings.Join(entry.Identities, ", ")
+			}
+			token := "-"
+			switch {
+			case entry.TokenSet:
+				token = "kept"
+			case entry.Token:
+				token = "allowed"
+			}
+			inputs := "-"
+			if entry.Inputs > 0 {
+				inputs = fmt.Sprintf("%d", entry.Inputs)
+			}
+			trusted, seen := "-", "-"
+			if !entry.FirstSeen.IsZero() {
+				trusted = formatAge(now.Sub(entry.FirstSeen))
+				seen = formatAge(now.Sub(entry.LastSeen))
+			}
+
+			line := fmt.Sprintf("%-*s%-*s%-*s%*s%*s%*s",
+				hostWidth, truncateRunes(entry.Host, hostWidth-1),
+				certWidth, truncateRunes(certs, certWidth-1),
+				tokenWidth, token, inputsWidth, inputs, ageWidth, trusted, ageWidth, seen)
+
+			if i == m.selectedIdx {
+				b.WriteString(selectedStyle.Render(line))
+			} else {
+				b.WriteString(normalStyle.Render(line))
+			}
+			b.WriteString("\n")
+		}
+	}
+
+	b.WriteString(helpStyle.Render("j/k: move • d: forget host • esc/q: close"))
+
+	content := borderStyle.Render(b.String())
+
+	// Center the modal
+	contentHeight := strings.Count(content, "\n") + 1
+	contentWidth := modalWidth + 6 // Account for border and padding
+
+	topPadding := (m.height - contentHeight) / 2
+	if topPadding < 0 {
+		topPadding = 0
+	}
+
+	leftPadding := (m.width - contentWidth) / 2
+	if leftPadding < 0 {
+		leftPadding = 0
+	}
+
+	result := strings.Repeat("\n", topPadding)
+	for _, line := range strings.Split(content, "\n") {
+		result += strings.Repeat(" ", leftPadding) + line + "\n"
+	}
+
+	return result
+}
@@ -0,0 +1,133 @@
+package ui
+
+import (
+	"strings"
+	"unicode/utf8"
+
+	"starsearch/internal/types"
+)
+
+// JumpToWord moves to the next occurrence of a word in the page, or the
+// previous one if forward is false, like vim's * and #. The word is the
+// one selected by a double click or else what was last searched for, from
+// the current match. Every
+// whole-word occurrence, in any case, is highlighted as a search. It
+// returns the word, the 1-based number of the occurrence moved to and how
+// many there are, or an empty word when nothing is selected.
+func (c *ContentViewport) JumpToWord(forward bool) (word string, current, total int) {
+	if c.document == nil {
+		return "", 0, 0
+	}
+
+	// Where to jump from: the selected word or the current match
+	var originLine, originStart int
+	switch {
+	case c.selection != nil && c.selection.word != "":
+		word = c.selection.word
+		originLine, originStart = c.selectionOrigin()
+	case c.currentResult >= 0 && c.currentResult < len(c.searchResults):
+		result := c.searchResults[c.currentResult]
+		word = c.currentSearch
+		originLine, originStart = result.Line, result.Start
+	default:
+		return "", 0, 0
+	}
+
+	var results []types.SearchResult
+	for lineIdx, line := range c.document.Lines {
+		for _, start := range wordMatches(line.Text, word) {
+			end := start + len(word)
+			results = append(results, types.SearchResult{
+				Line:  lineIdx,
+				Start: start,
+				End:   end,
+				Text:  line.Text[start:end],
+			})
+		}
+	}
+	if len(results) == 0 {
+		return word, 0, 0
+	}
+
+	// The first occurrence after the origin, or the last one before it,
+	// wrapping around the ends of the page
+	next := -1
+	if forward {
+		next = 0
+		for i, r := range results {
+			if r.Line > originLine || (r.Line == originLine && r.Start > originStart) {
+				next = i
+				break
+			}
+		}
+	} else {
+		next = len(results) - 1
+		for i := len(results) - 1; i >= 0; i-- {
+			r := results[i]
+			if r.Line < originLine || (r.Line == originLine && r.Start < originStart) {
+				next = i
+				break
+			}
+		}
+	}
+
+	c.selection = nil
+	c.SetSearch(word, results, false)
+	c.GoToSearchResult(&results[next])
+	return word, next + 1, len(results)
+}
+
+// selectionOrigin returns the document line and byte offset of the
+// selected word, counting the occurrences before it in its rendered line
+func (c *ContentViewport) selectionOrigin() (line, start int) {
+	line, ok := c.lineMapping[c.selection.line]
+	if !ok || line < 0 || line >= len(c.document.Lines) {
+		return -1, -1
+	}
+	segment := c.lineOffsets[c.selection.line]
+	seen := 0
+	for _, at := range wordMatches(c.document.Lines[line].Text, c.selection.word) {
+		if at < segment {
+			continue
+		}
+		if seen == c.selection.before {
+			return line, at
+		}
+		seen++
+	}
+	return line, segment
+}
+
+// wordMatches returns the byte offsets of whole-word occurrences of word
+// in text, ignoring case
+func wordMatches(text, word string) []int {
+	if word == "" {
+		return nil
+	}
+	lowerText, lowerWord := strings.ToLower(text), strings.ToLower(word)
+	if len(lowerText) != len(text) || len(lowerWord) != len(word) {
+		// Lowercasing changed the byte length; offsets would not line up
+		lowerText, lowerWord = text, word
+	}
+
+	var matches []int
+	for from := 0; from < len(lowerText); {
+		idx := strings.Index(lowerText[from:], lowerWord)
+		if idx < 0 {
+			break
+		}
+		start, end := from+idx, from+idx+len(lowerWord)
+		before, _ := utf8.DecodeLastRuneInString(text[:start])
+		after, _ := utf8.DecodeRuneInString(text[end:])
+		if (start == 0 || !isWordRune(before)) && (end == len(text) || !isWordRune(after)) {
+			matches = append(matches, start)
+		}
+		from = start + 1
+	}
+	return matches
+}
+
+// HasSearch reports whether search matches are highlighted
+func (c *ContentViewport) HasSearch() bool {
+	return c.searchHighlight
+}
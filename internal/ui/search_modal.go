@@ -74,6 +74,16 @@ func (m *SearchModal) IsVisible() bool {
 	return m.visible
 }
 
+// TakesText reports that the search modal always takes typed text
+func (m *SearchModal) TakesText() bool {
+	return true
+}
+
+// HandlesMouse reports that the search modal handles the mouse
+func (m *SearchModal) HandlesMouse() bool {
+	return true
+}
+
 func (m *SearchModal) SetSize(width, height int) {
 	m.width = width
 	m.height = height
@@ -0,0 +1,136 @@
+package ui
+
+import (
+	"strings"
+	"time"
+	"unicode"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/x/ansi"
+)
+
+// multiClickInterval is how soon a click must follow the previous one, on
+// the same spot, to count as a double or triple click
+const multiClickInterval = 400 * time.Millisecond
+
+// selectionSGR shows selected text in reverse video
+const selectionSGR = "\x1b[7m"
+
+// CopySelectionMsg is sent when a double or triple click selects text in
+// the content, to be copied to the clipboard
+type CopySelectionMsg struct {
+	Text string
+	Line bool // A whole line was selected rather than a word
+}
+
+// textSelection is a selected span of a rendered line, in display columns
+// of the full line (not shifted by horizontal scrolling)
+type textSelection struct {
+	line   int
+	start  int
+	end    int
+	word   string // The selected word; empty when a whole line is selected
+	before int    // Occurrences of word earlier in the visible line
+}
+
+// registerClick counts consecutive clicks on the same spot, returning 1 for
+// a single click, 2 for a double click and 3 for a triple click
+func (c *ContentViewport) registerClick(x, y int) int {
+	now := time.Now()
+	sameSpot := y == c.lastClickY && x >= c.lastClickX-1 && x <= c.lastClickX+1
+	if sameSpot && now.Sub(c.lastClick) <= multiClickInterval && c.clickCount < 3 {
+		c.clickCount++
+	} else {
+		c.clickCount = 1
+	}
+	c.lastClick = now
+	c.lastClickX = x
+	c.lastClickY = y
+	return c.clickCount
+}
+
+// selectAt selects the word at screen position x, y, or the whole line
+// there, and returns a command asking for it to be copied. Clicks on
+// whitespace select nothing.
+func (c *ContentViewport) selectAt(x, y int, line bool) tea.Cmd {
+	viewportY := y - c.yPosition
+	rows := strings.Split(c.viewport.View(), "\n")
+	if viewportY < 0 || viewportY >= len(rows) {
+		return nil
+	}
+
+	// Display column where each rune of the visible line starts
+	runes := []rune(ansi.Strip(rows[viewportY]))
+	cols := make([]int, len(runes)+1)
+	for i, r := range runes {
+		cols[i+1] = cols[i] + ansi.StringWidth(string(r))
+	}
+
+	start, end := 0, len(runes)
+	if line {
+		for start < end && unicode.IsSpace(runes[start]) {
+			start++
+		}
+		for end > start && unicode.IsSpace(runes[end-1]) {
+			end--
+		}
+	} else {
+		at := -1
+		for i := range runes {
+			if x >= cols[i] && x < cols[i+1] {
+				at = i
+				break
+			}
+		}
+		if at < 0 || !isWordRune(runes[at]) {
+			return nil
+		}
+		start, end = at, at+1
+		for start > 0 && isWordRune(runes[start-1]) {
+			start--
+		}
+		for end < len(runes) && isWordRune(runes[end]) {
+			end++
+		}
+	}
+	if start >= end {
+		return nil
+	}
+
+	c.selection = &textSelection{
+		line:  c.viewport.YOffset + viewportY,
+		start: cols[start] + c.xOffset,
+		end:   cols[end] + c.xOffset,
+	}
+	text := string(runes[start:end])
+	if !line {
+		c.selection.word = text
+		c.selection.before = len(wordMatches(string(runes[:start]), text))
+	}
+	return func() tea.Msg { return CopySelectionMsg{Text: text, Line: line} }
+}
+
+// isWordRune reports whether r belongs to a word for double-click selection
+func isWordRune(r rune) bool {
+	return unicode.IsLetter(r) || unicode.IsDigit(r) || r == '_' || r == '-' || r == '\''
+}
+
+// selectLine shows the selected span of a visible rendered line in reverse
+// video
+func (c *ContentViewport) selectLine(line string) string {
+	start := c.selection.start - c.xOffset
+	end := c.selection.end - c.xOffset
+	if start < 0 {
+		start = 0
+	}
+	if end <= start {
+		return line
+	}
+	selected := ansi.Strip(ansi.Cut(line, start, end))
+	return ansi.Truncate(line, start, "") + "\x1b[0m" + selectionSGR + selected + "\x1b[0m" + ansi.TruncateLeft(line, end, "")
+}
+
+// ClearSelection drops the text selected by a double or triple click
+func (c *ContentViewport) ClearSelection() {
+	c.selection = nil
+}
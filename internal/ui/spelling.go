@@ -0,0 +1,56 @@
+package ui
+
+import (
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// misspelling is a word in some text that is likely misspelled
+type misspelling struct {
+	start, end int    // Byte range of the word
+	suggestion string // Correct spelling, in the word's case
+}
+
+// findMisspellings returns the words in text found in the bundled list of
+// common misspellings, in order
+func findMisspellings(text string) []misspelling {
+	var found []misspelling
+	start := -1
+	for i := 0; i <= len(text); {
+		r, size := utf8.DecodeRuneInString(text[i:])
+		if i < len(text) && (unicode.IsLetter(r) || (r == '\'' && start >= 0)) {
+			if start < 0 {
+				start = i
+			}
+			i += size
+			continue
+		}
+		if start >= 0 {
+			word := strings.TrimRight(text[start:i], "'")
+			if suggestion, ok := misspellings[strings.ToLower(word)]; ok {
+				found = append(found, misspelling{
+					start:      start,
+					end:        start + len(word),
+					suggestion: matchCase(word, suggestion),
+				})
+			}
+			start = -1
+		}
+		if i == len(text) {
+			break
+		}
+		i += size
+	}
+	return found
+}
+
+// matchCase capitalizes suggestion when word starts with a capital
+func matchCase(word, suggestion string) string {
+	first, _ := utf8.DecodeRuneInString(word)
+	if !unicode.IsUpper(first) {
+		return suggestion
+	}
+	r, size := utf8.DecodeRuneInString(suggestion)
+	return string(unicode.ToUpper(r)) + suggestion[size:]
+}
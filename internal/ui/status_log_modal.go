@@ -0,0 +1,226 @@
+package ui
+
+import (
+	"strings"
+
+	"github.com/charmbracelet/bubbles/key"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+)
+
+// StatusLogModal shows the recent status bar messages, most recent first,
+// so a message that was replaced before it could be read can be found
+// again
+type StatusLogModal struct {
+	visible      bool
+	entries      []StatusEntry
+	selectedIdx  int
+	width        int
+	height       int
+	scrollOffset int
+}
+
+// StatusCopyMsg is sent to copy a logged message to the clipboard
+type StatusCopyMsg struct {
+	Text string
+}
+
+func NewStatusLogModal() *StatusLogModal {
+	return &StatusLogModal{}
+}
+
+// Show opens the modal with the logged messages, most recent first
+func (m *StatusLogModal) Show(entries []StatusEntry) {
+	m.visible = true
+	m.entries = entries
+	m.selectedIdx = 0
+	m.scrollOffset = 0
+}
+
+func (m *StatusLogModal) Hide() {
+	m.visible = false
+}
+
+func (m *StatusLogModal) IsVisible() bool {
+	return m.visible
+}
+
+func (m *StatusLogModal) SetSize(width, height int) {
+	m.width = width
+	m.height = height
+}
+
+func (m *StatusLogModal) Update(msg tea.Msg) (*StatusLogModal, tea.Cmd) {
+	keyMsg, ok := msg.(tea.KeyMsg)
+	if !m.visible || !ok {
+		return m, nil
+	}
+
+	switch {
+	case key.Matches(keyMsg, key.NewBinding(key.WithKeys("esc", "ctrl+s"))):
+		m.Hide()
+
+	case key.Matches(keyMsg, key.NewBinding(key.WithKeys("j", "down"))):
+		if m.selectedIdx < len(m.entries)-1 {
+			m.selectedIdx++
+			m.adjustScroll()
+		}
+
+	case key.Matches(keyMsg, key.NewBinding(key.WithKeys("k", "up"))):
+		if m.selectedIdx > 0 {
+			m.selectedIdx--
+			m.adjustScroll()
+		}
+
+	case key.Matches(keyMsg, key.NewBinding(key.WithKeys("y", "enter"))):
+		if m.selectedIdx < len(m.entries) {
+			text := m.entries[m.selectedIdx].Text
+			return m, func() tea.Msg { return StatusCopyMsg{Text: text} }
+		}
+	}
+
+	return m, nil
+}
+
+// visibleRows is how many messages fit, leaving room for the selected
+// message in full
+func (m *StatusLogModal) visibleRows() int {
+	rows := m.height - 16
+	if rows < 1 {
+		rows = 1
+	}
+	return rows
+}
+
+func (m *StatusLogModal) adjustScroll() {
+	visibleHeight := m.visibleRows()
+	if m.selectedIdx >= m.scrollOffset+visibleHeight {
+		m.scrollOffset = m.selectedIdx - visibleHeight + 1
+	}
+	if m.selectedIdx < m.scrollOffset {
+		m.scrollOffset = m.selectedIdx
+	}
+}
+
+func (m *StatusLogModal) View() string {
+	if !m.visible {
+		return ""
+	}
+
+	modalWidth := m.width - 4
+	if modalWidth < 50 {
+		modalWidth = 50
+	}
+	if modalWidth > 100 {
+		modalWidth = 100
+	}
+
+	titleStyle := lipgloss.NewStyle().
+		Bold(true).
+		Foreground(lipgloss.Color("12")).
+		Width(modalWidth).
+		Align(lipgloss.Center).
+		MarginBottom(1)
+
+	timeStyle := lipgloss.NewStyle().
+		Foreground(lipgloss.Color("8"))
+
+	selectedStyle := lipgloss.NewStyle().
+		Background(lipgloss.Color("12")).
+		Foreground(lipgloss.Color("0")).
+		Bold(true).
+		Width(modalWidth - 4)
+
+	normalStyle := lipgloss.NewStyle().
+		Foreground(lipgloss.Color("15")).
+		Width(modalWidth - 4)
+
+	errorStyle := lipgloss.NewStyle().
+		Foreground(lipgloss.Color("9")).
+		Width(modalWidth - 4)
+
+	detailStyle := lipgloss.NewStyle().
+		Foreground(lipgloss.Color("7")).
+		Width(modalWidth - 4).
+		MarginTop(1)
+
+	emptyStyle := lipgloss.NewStyle().
+		Foreground(lipgloss.Color("8")).
+		Italic(true).
+		Width(modalWidth).
+		Align(lipgloss.Center).
+		MarginTop(1).
+		MarginBottom(1)
+
+	helpStyle := lipgloss.NewStyle().
+		Foreground(lipgloss.Color("7")).
+		Width(modalWidth).
+		Align(lipgloss.Center).
+		MarginTop(1)
+
+	borderStyle := lipgloss.NewStyle().
+		Border(lipgloss.RoundedBorder()).
+		BorderForeground(lipgloss.Color("12")).
+		Padding(1, 2).
+		Width(modalWidth)
+
+	var b strings.Builder
+	b.WriteString(titleStyle.Render("Status messages"))
+	b.WriteString("\n")
+
+	if len(m.entries) == 0 {
+		b.WriteString(emptyStyle.Render("No messages yet"))
+		b.WriteString("\n")
+	} else {
+		endIdx := m.scrollOffset + m.visibleRows()
+		if endIdx > len(m.entries) {
+			endIdx = len(m.entries)
+		}
+
+		textWidth := modalWidth - 4 - 9 // Time column and its gap
+		for i := m.scrollOffset; i < endIdx; i++ {
+			entry := m.entries[i]
+			stamp := entry.Time.Format("15:04:05")
+			text := truncateRunes(strings.Join(strings.Fields(entry.Text), " "), textWidth)
+
+			switch {
+			case i == m.selectedIdx:
+				b.WriteString(selectedStyle.Render(stamp + " " + text))
+			case entry.Error:
+				b.WriteString(errorStyle.Render(timeStyle.Render(stamp) + " " + text))
+			default:
+				b.WriteString(normalStyle.Render(timeStyle.Render(stamp) + " " + text))
+			}
+			b.WriteString("\n")
+		}
+
+		// The selected message in full, however long
+		b.WriteString(detailStyle.Render(m.entries[m.selectedIdx].Text))
+		b.WriteString("\n")
+	}
+
+	b.WriteString(helpStyle.Render("j/k: move • y/enter: copy • esc/q: close"))
+
+	content := borderStyle.Render(b.String())
+
+	// Center the modal
+	contentHeight := strings.Count(content, "\n") + 1
+	contentWidth := modalWidth + 6 // Account for border and padding
+
+	topPadding := (m.height - contentHeight) / 2
+	if topPadding < 0 {
+		topPadding = 0
+	}
+
+	leftPadding := (m.width - contentWidth) / 2
+	if leftPadding < 0 {
+		leftPadding = 0
+	}
+
+	result := strings.Repeat("\n", topPadding)
+	for _, line := range strings.Split(content, "\n") {
+		result += strings.Repeat(" ", leftPadding) + line + "\n"
+	}
+
+	return result
+}
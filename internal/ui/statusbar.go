@@ -2,19 +2,67 @@ package ui
 
 import (
 	"fmt"
+	"time"
 
+	tea "github.com/charmbracelet/bubbletea"
 	"github.com/charmbracelet/lipgloss"
+	"starsearch/internal/textutil"
 )
 
+// StatusZone identifies a clickable segment of the status bar
+type StatusZone int
+
+const (
+	StatusZoneLoading StatusZone = iota // Loading indicator; click to cancel the fetch
+	StatusZoneURL                       // Current URL; click to copy
+	StatusZoneScroll                    // Scroll percentage; click to jump to top/bottom
+	StatusZoneCache                     // Cache usage; click to open the cache browser
+	StatusZoneIdentity                  // Identity presented; click to pick another
+)
+
+// StatusClickMsg is sent when a status bar segment is clicked
+type StatusClickMsg struct {
+	Zone StatusZone
+}
+
+// statusZoneBound is the horizontal extent of a clickable segment
+type statusZoneBound struct {
+	startX int
+	endX   int
+	zone   StatusZone
+}
+
 // StatusBar displays status information at the bottom
 type StatusBar struct {
 	message      string
 	url          string
+	hoverURL     string // Link under the mouse pointer, shown instead of the URL
 	scrollPercent float64
 	width        int
 	isLoading    bool
 	errorMsg     string
 	version      string
+	cacheUsed    int64 // Page cache size in bytes
+	cacheMax     int64 // Page cache cap in bytes; 0 hides the cache segment
+	onion        bool  // The page is an onion service reached through the proxy
+	isolated     bool  // The tab has its own Tor circuits
+	identity     string // Identity presented on the page; empty hides the segment
+	nowPlaying   string // Sound file being played; empty hides the segment
+	queued       int    // Sound files waiting to play after it
+	zones        []statusZoneBound // Clickable segments from the last render
+	log          [statusLogSize]StatusEntry // Recent messages, a ring buffer
+	logNext      int                        // Slot the next message goes in
+	logLen       int                        // Messages in the log
+}
+
+// statusLogSize is how many messages the status log keeps
+const statusLogSize = 100
+
+// StatusEntry is a message shown in the status bar, kept in the status log
+type StatusEntry struct {
+	Time  time.Time
+	Text  string
+	Error bool
 }
 
 // NewStatusBar creates a new status bar
@@ -28,14 +76,54 @@ func NewStatusBar(width int, version string) *StatusBar {
 
 // SetMessage sets the status message
 func (s *StatusBar) SetMessage(msg string) {
+	msg = textutil.Sanitize(msg) // Messages often quote what servers sent
 	s.message = msg
 	s.errorMsg = ""
+	s.Log(msg, false)
 }
 
 // SetError sets an error message
 func (s *StatusBar) SetError(err string) {
+	err = textutil.Sanitize(err)
 	s.errorMsg = err
 	s.message = ""
+	s.Log(err, true)
+}
+
+// SetProgress sets a status message that changes too often to be worth
+// keeping in the log
+func (s *StatusBar) SetProgress(msg string) {
+	s.message = msg
+	s.errorMsg = ""
+}
+
+// Log records a message in the status log without showing it, for
+// messages shown elsewhere. A message repeating the last one is recorded
+// once.
+func (s *StatusBar) Log(text string, isError bool) {
+	if text == "" {
+		return
+	}
+	if s.logLen > 0 {
+		last := s.log[(s.logNext+statusLogSize-1)%statusLogSize]
+		if last.Text == text && last.Error == isError {
+			return
+		}
+	}
+	s.log[s.logNext] = StatusEntry{Time: time.Now(), Text: text, Error: isError}
+	s.logNext = (s.logNext + 1) % statusLogSize
+	if s.logLen < statusLogSize {
+		s.logLen++
+	}
+}
+
+// History returns the messages in the status log, most recent first
+func (s *StatusBar) History() []StatusEntry {
+	entries := make([]StatusEntry, 0, s.logLen)
+	for i := 1; i <= s.logLen; i++ {
+		entries = append(entries, s.log[(s.logNext+statusLogSize-i)%statusLogSize])
+	}
+	return entries
 }
 
 // SetURL sets the current URL
@@ -43,11 +131,44 @@ func (s *StatusBar) SetURL(url string) {
 	s.url = url
 }
 
+// SetHoverURL shows where the link under the mouse pointer leads in place
+// of the current URL; an empty url shows the current URL again
+func (s *StatusBar) SetHoverURL(url string) {
+	s.hoverURL = url
+}
+
 // SetScrollPercent sets the scroll percentage
 func (s *StatusBar) SetScrollPercent(percent float64) {
 	s.scrollPercent = percent
 }
 
+// SetCacheUsage sets the page cache size and cap shown; a zero cap hides
+// the segment
+func (s *StatusBar) SetCacheUsage(used, max int64) {
+	s.cacheUsed = used
+	s.cacheMax = max
+}
+
+// SetOnion marks the page as an onion service reached through the proxy,
+// on circuits of the tab's own if isolated
+func (s *StatusBar) SetOnion(onion, isolated bool) {
+	s.onion = onion
+	s.isolated = isolated
+}
+
+// SetIdentity shows the name of the identity presented on the page; an
+// empty name hides the segment
+func (s *StatusBar) SetIdentity(name string) {
+	s.identity = name
+}
+
+// SetNowPlaying shows the sound file being played and how many are queued
+// after it; an empty name hides the segment
+func (s *StatusBar) SetNowPlaying(name string, queued int) {
+	s.nowPlaying = name
+	s.queued = queued
+}
+
 // SetLoading sets the loading state
 func (s *StatusBar) SetLoading(loading bool) {
 	s.isLoading = loading
@@ -58,6 +179,20 @@ func (s *StatusBar) SetWidth(width int) {
 	s.width = width
 }
 
+// Update handles clicks on the status bar's segments
+func (s *StatusBar) Update(msg tea.Msg) (*StatusBar, tea.Cmd) {
+	if mouseMsg, ok := msg.(tea.MouseMsg); ok &&
+		mouseMsg.Button == tea.MouseButtonLeft && mouseMsg.Action == tea.MouseActionPress {
+		for _, zone := range s.zones {
+			if mouseMsg.X >= zone.startX && mouseMsg.X < zone.endX {
+				clicked := zone.zone
+				return s, func() tea.Msg { return StatusClickMsg{Zone: clicked} }
+			}
+		}
+	}
+	return s, nil
+}
+
 // View renders the status bar
 func (s *StatusBar) View() string {
 	// Define styles
@@ -91,16 +226,20 @@ func (s *StatusBar) View() string {
 		leftSection = normalStyle.Render(" " + s.message + " ")
 	}
 
-	// Middle section: URL (if available)
+	// Middle section: URL (if available), or where a hovered link leads
 	middleSection := ""
-	if s.url != "" {
+	shown := s.url
+	if s.hoverURL != "" {
+		shown = "→ " + s.hoverURL
+	}
+	if shown != "" {
 		// Truncate URL if too long
 		maxURLLen := s.width - lipgloss.Width(leftSection) - 20
 		if maxURLLen < 20 {
 			maxURLLen = 20
 		}
 
-		displayURL := s.url
+		displayURL := shown
 		if len(displayURL) > maxURLLen {
 			displayURL = displayURL[:maxURLLen-3] + "..."
 		}
@@ -108,6 +247,41 @@ func (s *StatusBar) View() string {
 		middleSection = urlStyle.Render(" " + displayURL + " ")
 	}
 
+	// Onion indicator, right after the URL
+	onionSection := ""
+	if s.onion {
+		label := " onion "
+		if s.isolated {
+			label = " onion · isolated "
+		}
+		onionSection = lipgloss.NewStyle().
+			Foreground(lipgloss.Color("15")).
+			Background(lipgloss.Color("5")).
+			Render(label)
+	}
+
+	// Identity presented, after the onion indicator
+	identitySection := ""
+	if s.identity != "" {
+		identitySection = lipgloss.NewStyle().
+			Foreground(lipgloss.Color("0")).
+			Background(lipgloss.Color("14")).
+			Render(" ⚷ " + textutil.Sanitize(s.identity) + " ")
+	}
+
+	// Now playing, after the identity
+	playingSection := ""
+	if s.nowPlaying != "" {
+		label := " ♪ " + s.nowPlaying
+		if s.queued > 0 {
+			label += fmt.Sprintf(" (+%d)", s.queued)
+		}
+		playingSection = lipgloss.NewStyle().
+			Foreground(lipgloss.Color("0")).
+			Background(lipgloss.Color("10")).
+			Render(label + " ")
+	}
+
 	// Right section: Scroll position and version
 	scrollText := fmt.Sprintf("%.0f%%", s.scrollPercent*100)
 	versionText := ""
@@ -116,8 +290,28 @@ func (s *StatusBar) View() string {
 	}
 	rightSection := scrollStyle.Render(" " + scrollText + versionText + " ")
 
+	// Cache usage, just left of the scroll position
+	cacheSection := ""
+	if s.cacheMax > 0 {
+		cacheSection = scrollStyle.Render(" ⛁ " + FormatBytes(s.cacheUsed) + "/" + FormatBytes(s.cacheMax) + " ")
+	}
+
+	// Record clickable segments
+	s.zones = s.zones[:0]
+	leftWidth := lipgloss.Width(leftSection)
+	if s.isLoading && s.errorMsg == "" {
+		s.zones = append(s.zones, statusZoneBound{startX: 0, endX: leftWidth, zone: StatusZoneLoading})
+	}
+	if middleSection != "" {
+		s.zones = append(s.zones, statusZoneBound{startX: leftWidth, endX: leftWidth + lipgloss.Width(middleSection), zone: StatusZoneURL})
+	}
+	if identitySection != "" {
+		identityStart := leftWidth + lipgloss.Width(middleSection) + lipgloss.Width(onionSection)
+		s.zones = append(s.zones, statusZoneBound{startX: identityStart, endX: identityStart + lipgloss.Width(identitySection), zone: StatusZoneIdentity})
+	}
+
 	// Calculate spacing
-	usedWidth := lipgloss.Width(leftSection) + lipgloss.Width(middleSection) + lipgloss.Width(rightSection)
+	usedWidth := lipgloss.Width(leftSection) + lipgloss.Width(middleSection) + lipgloss.Width(onionSection) + lipgloss.Width(identitySection) + lipgloss.Width(playingSection) + lipgloss.Width(cacheSection) + lipgloss.Width(rightSection)
 	spacing := s.width - usedWidth
 
 	if spacing < 0 {
@@ -129,12 +323,23 @@ func (s *StatusBar) View() string {
 		Width(spacing).
 		Render("")
 
+	scrollStart := usedWidth - lipgloss.Width(rightSection) + spacing
+	if cacheSection != "" {
+		cacheStart := scrollStart - lipgloss.Width(cacheSection)
+		s.zones = append(s.zones, statusZoneBound{startX: cacheStart, endX: scrollStart, zone: StatusZoneCache})
+	}
+	s.zones = append(s.zones, statusZoneBound{startX: scrollStart, endX: scrollStart + len(scrollText) + 1, zone: StatusZoneScroll})
+
 	// Combine sections
 	statusLine := lipgloss.JoinHorizontal(
 		lipgloss.Top,
 		leftSection,
 		middleSection,
+		onionSection,
+		identitySection,
+		playingSection,
 		spacer,
+		cacheSection,
 		rightSection,
 	)
 
@@ -0,0 +1,179 @@
+package ui
+
+import (
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/charmbracelet/lipgloss"
+)
+
+// textSpan is a run of text drawn with a single style. A nil style draws
+// the text unstyled.
+type textSpan struct {
+	text  string
+	style *lipgloss.Style
+}
+
+// styledText is a line of plain text split into styled spans. Styles are
+// only turned into escape sequences by render, so byte offsets into the
+// plain text stay valid while styles are layered and the text is wrapped.
+type styledText []textSpan
+
+// newStyledText returns text drawn entirely with style
+func newStyledText(text string, style *lipgloss.Style) styledText {
+	if text == "" {
+		return nil
+	}
+	return styledText{{text: text, style: style}}
+}
+
+// String returns the plain text without styling
+func (t styledText) String() string {
+	var b strings.Builder
+	for _, span := range t {
+		b.WriteString(span.text)
+	}
+	return b.String()
+}
+
+// render draws the text with each span's style
+func (t styledText) render() string {
+	var b strings.Builder
+	for _, span := range t {
+		if span.style == nil {
+			b.WriteString(span.text)
+		} else {
+			b.WriteString(span.style.Render(span.text))
+		}
+	}
+	return b.String()
+}
+
+// add appends text, merging it into the last span if the style is the same
+func (t styledText) add(text string, style *lipgloss.Style) styledText {
+	if text == "" {
+		return t
+	}
+	if last := len(t) - 1; last >= 0 && t[last].style == style {
+		t[last].text += text
+		return t
+	}
+	return append(t, textSpan{text: text, style: style})
+}
+
+// overlay layers style over the bytes [start, end) of the plain text.
+// Attributes style does not set, such as a link's underline, are kept from
+// the style underneath.
+func (t styledText) overlay(start, end int, style lipgloss.Style) styledText {
+	var out styledText
+	pos := 0
+	for _, span := range t {
+		spanStart, spanEnd := pos, pos+len(span.text)
+		pos = spanEnd
+
+		from := min(max(start, spanStart), spanEnd) - spanStart
+		to := min(max(end, spanStart), spanEnd) - spanStart
+		if from >= to {
+			out = out.add(span.text, span.style)
+			continue
+		}
+
+		merged := style
+		if span.style != nil {
+			merged = style.Inherit(*span.style)
+		}
+		out = out.add(span.text[:from], span.style)
+		out = out.add(span.text[from:to], &merged)
+		out = out.add(span.text[to:], span.style)
+	}
+	return out
+}
+
+// slice returns the spans covering the bytes [start, end) of the plain text
+func (t styledText) slice(start, end int) styledText {
+	var out styledText
+	pos := 0
+	for _, span := range t {
+		spanStart, spanEnd := pos, pos+len(span.text)
+		pos = spanEnd
+		if spanEnd <= start || spanStart >= end {
+			continue
+		}
+		out = out.add(span.text[max(start, spanStart)-spanStart:min(end, spanEnd)-spanStart], span.style)
+	}
+	return out
+}
+
+// styleAt returns the style of the byte at pos
+func (t styledText) styleAt(pos int) *lipgloss.Style {
+	for _, span := range t {
+		if pos < len(span.text) {
+			return span.style
+		}
+		pos -= len(span.text)
+	}
+	return nil
+}
+
+// wrap word-wraps the text to width exactly like wordWrap, keeping each
+// word's styles. Runs of whitespace collapse to a single space drawn in the
+// style of the gap it replaces. starts holds the byte offset in the plain
+// text where each row begins.
+func (t styledText) wrap(width int) (rows []styledText, starts []int) {
+	if width <= 0 {
+		width = 80
+	}
+
+	words := wordRanges(t.String())
+	if len(words) == 0 {
+		return []styledText{t}, []int{0}
+	}
+
+	var row styledText
+	rowLen, prevEnd := 0, 0
+	for _, word := range words {
+		wordLen := word[1] - word[0]
+		if rowLen == 0 {
+			row = t.slice(word[0], word[1])
+			rowLen = wordLen
+			starts = append(starts, word[0])
+		} else if rowLen+1+wordLen <= width {
+			row = row.add(" ", t.styleAt(prevEnd))
+			for _, span := range t.slice(word[0], word[1]) {
+				row = row.add(span.text, span.style)
+			}
+			rowLen += 1 + wordLen
+		} else {
+			rows = append(rows, row)
+			row = t.slice(word[0], word[1])
+			rowLen = wordLen
+			starts = append(starts, word[0])
+		}
+		prevEnd = word[1]
+	}
+	return append(rows, row), starts
+}
+
+// wordRanges returns the byte ranges of the whitespace-separated words in
+// text, splitting the same way as strings.Fields
+func wordRanges(text string) [][2]int {
+	var ranges [][2]int
+	start := -1
+	for i := 0; i < len(text); {
+		r, size := utf8.DecodeRuneInString(text[i:])
+		if unicode.IsSpace(r) {
+			if start >= 0 {
+				ranges = append(ranges, [2]int{start, i})
+				start = -1
+			}
+		} else if start < 0 {
+			start = i
+		}
+		i += size
+	}
+	if start >= 0 {
+		ranges = append(ranges, [2]int{start, len(text)})
+	}
+	return ranges
+}
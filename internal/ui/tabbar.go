@@ -17,6 +17,8 @@ type TabBar struct {
 	width       int
 	height      int
 	scrollOffset int
+	nextID      int // ID given to the next tab added
+	useSeq      int // Incremented each time a tab is shown, for LastUsed
 }
 
 // TabSwitchMsg is sent when user switches tabs
@@ -41,17 +43,87 @@ func NewTabBar() *TabBar {
 }
 
 func (t *TabBar) AddTab(url, title string) {
+	t.appendTab(url, title)
+	t.SwitchTab(len(t.tabs) - 1)
+}
+
+// AddBackgroundTab adds a tab without switching to it and returns its ID
+func (t *TabBar) AddBackgroundTab(url, title string) int {
+	return t.appendTab(url, title)
+}
+
+// appendTab adds a tab at the end and returns its ID
+func (t *TabBar) appendTab(url, title string) int {
 	tab := types.Tab{
-		ID:       len(t.tabs),
+		ID:       t.nextID,
 		Title:    title,
 		URL:      url,
 		Document:  nil,
 		Scroll:   0,
 	}
+	t.nextID++
+	t.useSeq++
+	tab.LastUsed = t.useSeq
 
 	t.tabs = append(t.tabs, tab)
-	t.activeIdx = len(t.tabs) - 1
-	t.adjustScroll()
+	return tab.ID
+}
+
+// IndexOf returns the index of the tab with the given ID, or -1 if it has
+// been closed
+func (t *TabBar) IndexOf(id int) int {
+	for i, tab := range t.tabs {
+		if tab.ID == id {
+			return i
+		}
+	}
+	return -1
+}
+
+// SetTabStatus records whether a tab is loading in the background or
+// failed to load
+func (t *TabBar) SetTabStatus(index int, loading, failed bool) {
+	if index >= 0 && index < len(t.tabs) {
+		t.tabs[index].Loading = loading
+		t.tabs[index].Failed = failed
+	}
+}
+
+// SetWatch reloads a tab automatically every interval seconds, starting
+// the countdown over; an interval of 0 stops watching it
+func (t *TabBar) SetWatch(index, interval int) {
+	if index >= 0 && index < len(t.tabs) {
+		t.tabs[index].WatchInterval = interval
+		t.tabs[index].WatchRemaining = interval
+	}
+}
+
+// CountDownWatches advances the countdown of every watched tab by a second
+// and returns the IDs of tabs due for a reload, whose countdowns restart
+func (t *TabBar) CountDownWatches() []int {
+	var due []int
+	for i := range t.tabs {
+		tab := &t.tabs[i]
+		if tab.WatchInterval == 0 {
+			continue
+		}
+		tab.WatchRemaining--
+		if tab.WatchRemaining <= 0 {
+			tab.WatchRemaining = tab.WatchInterval
+			due = append(due, tab.ID)
+		}
+	}
+	return due
+}
+
+// HasWatches reports whether any tab is watched
+func (t *TabBar) HasWatches() bool {
+	for _, tab := range t.tabs {
+		if tab.WatchInterval > 0 {
+			return true
+		}
+	}
+	return false
 }
 
 func (t *TabBar) CloseTab(index int) {
@@ -70,21 +142,27 @@ func (t *TabBar) CloseTab(index int) {
 		t.activeIdx = 0
 	}
 
-	// Adjust tab IDs
-	for i := range t.tabs {
-		t.tabs[i].ID = i
-	}
-
 	t.adjustScroll()
 }
 
 func (t *TabBar) SwitchTab(index int) {
 	if index >= 0 && index < len(t.tabs) {
 		t.activeIdx = index
+		t.useSeq++
+		t.tabs[index].LastUsed = t.useSeq
 		t.adjustScroll()
 	}
 }
 
+// EvictDocument drops a tab's document to save memory, keeping its URL,
+// title and scroll position so it can be reloaded
+func (t *TabBar) EvictDocument(index int) {
+	if index >= 0 && index < len(t.tabs) && t.tabs[index].Document != nil {
+		t.tabs[index].Document = nil
+		t.tabs[index].Evicted = true
+	}
+}
+
 func (t *TabBar) GetActiveTab() *types.Tab {
 	if t.activeIdx >= 0 && t.activeIdx < len(t.tabs) {
 		return &t.tabs[t.activeIdx]
@@ -106,6 +184,9 @@ func (t *TabBar) UpdateTab(index int, url, title string, document *types.Documen
 		t.tabs[index].Title = title
 		t.tabs[index].Document = document
 		t.tabs[index].Scroll = scroll
+		if document != nil {
+			t.tabs[index].Evicted = false
+		}
 	}
 }
 
@@ -158,6 +239,7 @@ func (t *TabBar) calculateTabWidth(tab types.Tab) int {
 	
 	// Add icon and padding
 	width := len(title) + 4 // 2 for icon, 2 for padding
+	width += len([]rune(watchIndicator(tab)))
 
 	if width < minWidth {
 		width = minWidth
@@ -166,6 +248,19 @@ func (t *TabBar) calculateTabWidth(tab types.Tab) int {
 	return width
 }
 
+// watchIndicator returns the countdown to a watched tab's next reload,
+// e.g. " ⟳42s", or an empty string for other tabs
+func watchIndicator(tab types.Tab) string {
+	switch {
+	case tab.WatchInterval == 0:
+		return ""
+	case tab.WatchRemaining >= 60:
+		return fmt.Sprintf(" ⟳%dm", (tab.WatchRemaining+59)/60)
+	default:
+		return fmt.Sprintf(" ⟳%ds", tab.WatchRemaining)
+	}
+}
+
 func (t *TabBar) Update(msg tea.Msg) (*TabBar, tea.Cmd) {
 	switch msg := msg.(type) {
 	case tea.KeyMsg:
@@ -341,13 +436,18 @@ func (t *TabBar) View() string {
 				title = title[:maxTitleLen-3] + "..."
 			}
 
-			// Add icon
+			// Add icon; background tabs show their loading progress
 			icon := "🌐"
-			if i == t.activeIdx {
+			switch {
+			case tab.Loading:
+				icon = "⏳"
+			case tab.Failed:
+				icon = "❌"
+			case i == t.activeIdx:
 				icon = "🌍"
 			}
 
-			tabText := fmt.Sprintf(" %s %s ", icon, title)
+			tabText := fmt.Sprintf(" %s %s%s ", icon, title, watchIndicator(tab))
 
 			if i == t.activeIdx {
 				b.WriteString(activeStyle.Render(tabText))
@@ -0,0 +1,161 @@
+package ui
+
+import (
+	"strings"
+
+	"github.com/charmbracelet/lipgloss"
+	"starsearch/internal/types"
+)
+
+// tableBlock is a run of document lines that looks like a delimited table
+type tableBlock struct {
+	end       int        // Index of the last document line in the table
+	rows      [][]string // Cell text, separator rows removed
+	rowLines  []int      // Document line index of each row
+	hasHeader bool       // Whether the first row was followed by a separator row
+}
+
+// detectTables finds pipe- or tab-delimited tables inside preformatted
+// blocks and runs of plain text, keyed by the index of their first line
+func detectTables(lines []types.Line) map[int]tableBlock {
+	tables := make(map[int]tableBlock)
+
+	for i := 0; i < len(lines); {
+		lineType := lines[i].Type
+		if lineType != types.LinePreformatText && lineType != types.LineText {
+			i++
+			continue
+		}
+
+		// Collect consecutive lines of the same type that contain a delimiter
+		start := i
+		for i < len(lines) && lines[i].Type == lineType && isDelimitedRow(lines[i].Text) {
+			i++
+		}
+		if i == start {
+			i++
+			continue
+		}
+
+		texts := make([]string, 0, i-start)
+		for _, line := range lines[start:i] {
+			texts = append(texts, line.Text)
+		}
+		if rows, rowLines, hasHeader, ok := parseTable(texts); ok {
+			for r := range rowLines {
+				rowLines[r] += start
+			}
+			tables[start] = tableBlock{end: i - 1, rows: rows, rowLines: rowLines, hasHeader: hasHeader}
+		}
+	}
+
+	return tables
+}
+
+// isDelimitedRow reports whether a line could be a table row
+func isDelimitedRow(text string) bool {
+	return strings.Contains(text, "|") || strings.Contains(text, "\t")
+}
+
+// parseTable splits lines into cells. It succeeds only when there are at
+// least two rows and every row has the same number of cells (at least two).
+// rowLines holds the index into lines of each returned row.
+func parseTable(lines []string) (rows [][]string, rowLines []int, hasHeader bool, ok bool) {
+	if len(lines) < 2 {
+		return nil, nil, false, false
+	}
+
+	columns := 0
+	for i, line := range lines {
+		cells := splitRow(line)
+		if isSeparatorRow(cells) {
+			if i == 1 {
+				hasHeader = true
+			}
+			continue
+		}
+		if len(cells) < 2 || (columns != 0 && len(cells) != columns) {
+			return nil, nil, false, false
+		}
+		columns = len(cells)
+		rows = append(rows, cells)
+		rowLines = append(rowLines, i)
+	}
+
+	if len(rows) < 2 {
+		return nil, nil, false, false
+	}
+	return rows, rowLines, hasHeader, true
+}
+
+// splitRow splits a row on pipes (ignoring leading and trailing pipes) or,
+// failing that, on tabs
+func splitRow(line string) []string {
+	var cells []string
+	if strings.Contains(line, "|") {
+		line = strings.TrimSpace(line)
+		line = strings.TrimPrefix(line, "|")
+		line = strings.TrimSuffix(line, "|")
+		cells = strings.Split(line, "|")
+	} else {
+		cells = strings.Split(line, "\t")
+	}
+
+	for i, cell := range cells {
+		cells[i] = strings.TrimSpace(cell)
+	}
+	return cells
+}
+
+// isSeparatorRow reports whether cells form a Markdown-style "---|:--:" rule
+func isSeparatorRow(cells []string) bool {
+	if len(cells) == 0 {
+		return false
+	}
+	for _, cell := range cells {
+		if cell == "" || strings.Trim(cell, "-:=+ ") != "" {
+			return false
+		}
+	}
+	return true
+}
+
+// renderTable draws rows with aligned columns and light box borders.
+// lineIdx holds the document line each rendered line belongs to.
+func renderTable(block tableBlock) (lines []string, lineIdx []int) {
+	widths := make([]int, len(block.rows[0]))
+	for _, row := range block.rows {
+		for col, cell := range row {
+			if w := lipgloss.Width(cell); w > widths[col] {
+				widths[col] = w
+			}
+		}
+	}
+
+	border := func(left, mid, right string) string {
+		parts := make([]string, len(widths))
+		for col, w := range widths {
+			parts[col] = strings.Repeat("─", w+2)
+		}
+		return left + strings.Join(parts, mid) + right
+	}
+
+	lines = []string{border("┌", "┬", "┐")}
+	lineIdx = []int{block.rowLines[0]}
+	for i, row := range block.rows {
+		parts := make([]string, len(row))
+		for col, cell := range row {
+			parts[col] = " " + cell + strings.Repeat(" ", widths[col]-lipgloss.Width(cell)) + " "
+		}
+		lines = append(lines, "│"+strings.Join(parts, "│")+"│")
+		lineIdx = append(lineIdx, block.rowLines[i])
+		if i == 0 && block.hasHeader {
+			lines = append(lines, border("├", "┼", "┤"))
+			lineIdx = append(lineIdx, block.rowLines[i])
+		}
+	}
+	lines = append(lines, border("└", "┴", "┘"))
+	lineIdx = append(lineIdx, block.end)
+
+	return lines, lineIdx
+}
@@ -0,0 +1,280 @@
+package ui
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/charmbracelet/bubbles/textinput"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+)
+
+// Upload form field indices
+const (
+	titanFieldURL = iota
+	titanFieldFile
+	titanFieldMIME
+	titanFieldToken
+	titanFieldCount
+)
+
+// TitanModal is the form for publishing the current page, a local file or
+// text written in an editor to a Titan-enabled capsule
+type TitanModal struct {
+	visible   bool
+	width     int
+	height    int
+	inputs    []textinput.Model // URL, file, media type, token
+	focus     int
+	edited    int // Size of the text written in the editor, -1 if none
+	formError string
+	uploading bool
+}
+
+// TitanUploadMsg is sent when the user asks for the upload to be sent
+type TitanUploadMsg struct {
+	URL    string
+	File   string // Local file to upload; empty for the current page
+	MIME   string
+	Token  string
+	Edited bool // Upload the text written in the editor instead of File
+}
+
+// TitanEditMsg asks for the content to be written in an external editor
+// before it is uploaded, starting from File or the current page
+type TitanEditMsg struct {
+	File   string
+	Edited bool // Continue with the text written in the editor before
+}
+
+func NewTitanModal() *TitanModal {
+	placeholders := []string{"titan://host/path", "Local file (empty for the current page)", "text/gemini", "Only if the capsule asks for one"}
+	inputs := make([]textinput.Model, titanFieldCount)
+	for i, placeholder := range placeholders {
+		ti := textinput.New()
+		ti.Placeholder = placeholder
+		ti.CharLimit = 1024
+		ti.Width = 50
+		inputs[i] = ti
+	}
+	inputs[titanFieldToken].EchoMode = textinput.EchoPassword
+	inputs[titanFieldToken].EchoCharacter = '•'
+
+	return &TitanModal{inputs: inputs, edited: -1}
+}
+
+// Show opens the form to upload to target with the given media type
+func (m *TitanModal) Show(target, mime string) tea.Cmd {
+	m.visible = true
+	m.edited = -1
+	m.formError = ""
+	m.uploading = false
+	for i := range m.inputs {
+		m.inputs[i].Reset()
+	}
+	m.inputs[titanFieldURL].SetValue(target)
+	m.inputs[titanFieldMIME].SetValue(mime)
+	for i := range m.inputs {
+		m.inputs[i].CursorEnd()
+	}
+	return m.focusField(titanFieldURL)
+}
+
+func (m *TitanModal) Hide() {
+	m.visible = false
+	for i := range m.inputs {
+		m.inputs[i].Blur()
+	}
+}
+
+func (m *TitanModal) IsVisible() bool {
+	return m.visible
+}
+
+// TakesText reports that the form always takes typed text
+func (m *TitanModal) TakesText() bool {
+	return true
+}
+
+func (m *TitanModal) SetSize(width, height int) {
+	m.width = width
+	m.height = height
+}
+
+// SetEdited records that the text to upload was written in the editor
+func (m *TitanModal) SetEdited(size int) {
+	m.edited = size
+}
+
+// SetError shows why the upload failed, keeping the form open to try again
+func (m *TitanModal) SetError(err string) {
+	m.formError = err
+	m.uploading = false
+}
+
+// focusField moves focus to the given field
+func (m *TitanModal) focusField(field int) tea.Cmd {
+	m.focus = field
+	for i := range m.inputs {
+		m.inputs[i].Blur()
+	}
+	return m.inputs[field].Focus()
+}
+
+func (m *TitanModal) Update(msg tea.Msg) (*TitanModal, tea.Cmd) {
+	keyMsg, ok := msg.(tea.KeyMsg)
+	if !m.visible || !ok {
+		return m, nil
+	}
+
+	switch keyMsg.String() {
+	case "esc":
+		m.Hide()
+		return m, nil
+
+	case "tab", "down":
+		return m, m.focusField((m.focus + 1) % titanFieldCount)
+
+	case "shift+tab", "up":
+		return m, m.focusField((m.focus + titanFieldCount - 1) % titanFieldCount)
+
+	case "ctrl+e":
+		editMsg := TitanEditMsg{
+			File:   strings.TrimSpace(m.inputs[titanFieldFile].Value()),
+			Edited: m.edited >= 0,
+		}
+		return m, func() tea.Msg { return editMsg }
+
+	case "enter":
+		if m.uploading {
+			return m, nil
+		}
+		target := strings.TrimSpace(m.inputs[titanFieldURL].Value())
+		if !strings.HasPrefix(target, "titan://") {
+			m.formError = "The URL must start with titan://"
+			return m, m.focusField(titanFieldURL)
+		}
+		m.formError = ""
+		m.uploading = true
+		uploadMsg := TitanUploadMsg{
+			URL:    target,
+			File:   strings.TrimSpace(m.inputs[titanFieldFile].Value()),
+			MIME:   strings.TrimSpace(m.inputs[titanFieldMIME].Value()),
+			Token:  m.inputs[titanFieldToken].Value(),
+			Edited: m.edited >= 0,
+		}
+		return m, func() tea.Msg { return uploadMsg }
+	}
+
+	// A file chosen after editing replaces the edited text
+	before := m.inputs[titanFieldFile].Value()
+	var cmd tea.Cmd
+	m.inputs[m.focus], cmd = m.inputs[m.focus].Update(msg)
+	if m.inputs[titanFieldFile].Value() != before {
+		m.edited = -1
+	}
+	return m, cmd
+}
+
+func (m *TitanModal) View() string {
+	if !m.visible {
+		return ""
+	}
+
+	modalWidth := m.width - 4
+	if modalWidth < 50 {
+		modalWidth = 50
+	}
+	if modalWidth > 90 {
+		modalWidth = 90
+	}
+
+	titleStyle := lipgloss.NewStyle().
+		Bold(true).
+		Foreground(lipgloss.Color("12")).
+		Width(modalWidth).
+		Align(lipgloss.Center).
+		MarginBottom(1)
+
+	labelStyle := lipgloss.NewStyle().
+		Foreground(lipgloss.Color("8")).
+		Bold(true)
+
+	activeLabelStyle := lipgloss.NewStyle().
+		Foreground(lipgloss.Color("12")).
+		Bold(true)
+
+	noteStyle := lipgloss.NewStyle().
+		Foreground(lipgloss.Color("14"))
+
+	errorStyle := lipgloss.NewStyle().
+		Foreground(lipgloss.Color("9")).
+		Width(modalWidth - 4)
+
+	helpStyle := lipgloss.NewStyle().
+		Foreground(lipgloss.Color("7")).
+		Width(modalWidth).
+		Align(lipgloss.Center).
+		MarginTop(1)
+
+	borderStyle := lipgloss.NewStyle().
+		Border(lipgloss.RoundedBorder()).
+		BorderForeground(lipgloss.Color("12")).
+		Padding(1, 2).
+		Width(modalWidth)
+
+	var b strings.Builder
+	b.WriteString(titleStyle.Render("Upload (Titan)"))
+	b.WriteString("\n")
+
+	labels := []string{"URL", "File", "Media type", "Token"}
+	for i, label := range labels {
+		if i == m.focus {
+			b.WriteString(activeLabelStyle.Render(label))
+		} else {
+			b.WriteString(labelStyle.Render(label))
+		}
+		b.WriteString("\n")
+		m.inputs[i].Width = modalWidth - 12
+		b.WriteString(m.inputs[i].View())
+		b.WriteString("\n")
+		if i == titanFieldFile && m.edited >= 0 {
+			b.WriteString(noteStyle.Render(fmt.Sprintf("Uploading the text written in the editor (%s)", FormatBytes(int64(m.edited)))))
+			b.WriteString("\n")
+		}
+		b.WriteString("\n")
+	}
+
+	switch {
+	case m.uploading:
+		b.WriteString(noteStyle.Render("Uploading..."))
+		b.WriteString("\n")
+	case m.formError != "":
+		b.WriteString(errorStyle.Render(m.formError))
+		b.WriteString("\n")
+	}
+	b.WriteString(helpStyle.Render("tab: next field • ctrl+e: edit in $EDITOR • enter: upload • esc: cancel"))
+
+	content := borderStyle.Render(b.String())
+
+	// Center the modal
+	contentHeight := strings.Count(content, "\n") + 1
+	contentWidth := modalWidth + 6 // Account for border and padding
+
+	topPadding := (m.height - contentHeight) / 2
+	if topPadding < 0 {
+		topPadding = 0
+	}
+
+	leftPadding := (m.width - contentWidth) / 2
+	if leftPadding < 0 {
+		leftPadding = 0
+	}
+
+	result := strings.Repeat("\n", topPadding)
+	for _, line := range strings.Split(content, "\n") {
+		result += strings.Repeat(" ", leftPadding) + line + "\n"
+	}
+
+	return result
+}
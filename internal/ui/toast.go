@@ -0,0 +1,89 @@
+package ui
+
+import (
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/charmbracelet/x/ansi"
+	"starsearch/internal/textutil"
+)
+
+// ToastExpiredMsg is sent when a toast has been shown for its duration
+type ToastExpiredMsg struct {
+	id int
+}
+
+// Toast briefly shows a notification in the bottom right corner, above the
+// status bar, so it is not overwritten by status updates
+type Toast struct {
+	text    string
+	id      int // Distinguishes a toast from the one it replaced
+	visible bool
+	width   int
+	height  int
+}
+
+// NewToast creates a new toast
+func NewToast() *Toast {
+	return &Toast{}
+}
+
+// SetSize sets the width of the screen the toast is drawn on and the
+// height of the part above the status bar
+func (t *Toast) SetSize(width, height int) {
+	t.width = width
+	t.height = height
+}
+
+// Show displays text, replacing any toast still showing, and returns a
+// command that hides it after duration
+func (t *Toast) Show(text string, duration time.Duration) tea.Cmd {
+	t.id++
+	t.text = textutil.Sanitize(text)
+	t.visible = true
+	id := t.id
+	return tea.Tick(duration, func(time.Time) tea.Msg { return ToastExpiredMsg{id: id} })
+}
+
+// Update hides the toast when its time is up. Expiries of toasts it
+// replaced are ignored.
+func (t *Toast) Update(msg ToastExpiredMsg) {
+	if msg.id == t.id {
+		t.visible = false
+	}
+}
+
+// IsVisible returns whether a toast is showing
+func (t *Toast) IsVisible() bool {
+	return t.visible
+}
+
+// Text returns the text of the toast showing, or an empty string
+func (t *Toast) Text() string {
+	if !t.visible {
+		return ""
+	}
+	return t.text
+}
+
+// Overlay draws the toast over the bottom right of background
+func (t *Toast) Overlay(background string) string {
+	if !t.visible || t.width < 10 || t.height < 4 {
+		return background
+	}
+
+	style := lipgloss.NewStyle().
+		Border(lipgloss.RoundedBorder()).
+		BorderForeground(lipgloss.Color("10")).
+		Foreground(lipgloss.Color("15")).
+		Padding(0, 1)
+
+	text := ansi.Truncate(t.text, min(t.width-6, 60), "…")
+	box := style.Render(text)
+
+	// Just above the status bar, one column in from the edge
+	left := max(t.width-lipgloss.Width(box)-1, 0)
+	top := max(t.height-lipgloss.Height(box), 0)
+	return overlayAt(background, box, left, top)
+}
@@ -0,0 +1,76 @@
+package ui
+
+import "starsearch/internal/types"
+
+// Typography holds the spacing choices pages are rendered with
+type Typography struct {
+	HeadingMargins [3][2]int // Blank lines above and below headings, by level
+	MaxBlankLines  int       // Longest run of blank lines shown; 0 or less shows all
+	BodyIndent     int       // Columns text under a heading is indented by
+}
+
+// DefaultTypography is the spacing pages get unless configured otherwise
+func DefaultTypography() Typography {
+	return Typography{
+		HeadingMargins: [3][2]int{{1, 1}, {1, 0}, {0, 0}},
+	}
+}
+
+// SetTypography sets the spacing pages are rendered with and re-renders
+// the page
+func (c *ContentViewport) SetTypography(typography Typography) {
+	c.typography = typography
+	if c.document != nil {
+		docLine, offset := c.topPosition()
+		c.rerender()
+		if line := c.renderedLineAt(docLine, offset); line >= 0 {
+			c.viewport.SetYOffset(line)
+		}
+	}
+}
+
+// headingMargins returns the blank lines above and below headings of the
+// given level (1-3). Zooming out drops them; zooming in adds space above
+// every heading.
+func (c *ContentViewport) headingMargins(level int) (top, bottom int) {
+	if c.zoom < 0 {
+		return 0, 0
+	}
+	margins := c.typography.HeadingMargins[level-1]
+	top, bottom = margins[0], margins[1]
+	if c.zoom > 0 {
+		top++
+	}
+	return top, bottom
+}
+
+// skipBlank reports whether the blank document line at idx is left out:
+// runs of blank lines are cut to MaxBlankLines, zooming out once cuts
+// them to one and twice drops them all
+func (c *ContentViewport) skipBlank(idx int) bool {
+	limit := c.typography.MaxBlankLines
+	switch {
+	case c.zoom <= -2:
+		return true
+	case c.zoom == -1:
+		limit = 1
+	}
+	if limit <= 0 {
+		return false
+	}
+
+	// Count the blank lines in the run before this one
+	run := 0
+	for i := idx - 1; i >= 0; i-- {
+		if line := c.document.Lines[i]; line.Type != types.LineText || line.Text != "" {
+			break
+		}
+		run++
+	}
+	return run >= limit
+}
+
+// isHeading reports whether t is a heading of any level
+func isHeading(t types.LineType) bool {
+	return t == types.LineHeading1 || t == types.LineHeading2 || t == types.LineHeading3
+}
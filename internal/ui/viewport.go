@@ -2,12 +2,14 @@ package ui
 
 import (
 	"fmt"
-	"regexp"
+	"sort"
 	"strings"
+	"time"
 
 	"github.com/charmbracelet/bubbles/viewport"
 	tea "github.com/charmbracelet/bubbletea"
 	"github.com/charmbracelet/lipgloss"
+	"starsearch/internal/gopher"
 	"starsearch/internal/types"
 )
 
@@ -20,14 +22,48 @@ type ContentViewport struct {
 	yPosition      int // Y position of viewport in screen layout
 	selectedLink   int // Currently selected link for keyboard navigation
 	lineMapping    map[int]int // Maps rendered line number to document line index
+	lineOffsets    map[int]int // Maps wrapped rendered lines to the byte offset in their document line's text where they start
 	linkBounds     map[int][]linkBound // Maps rendered line to clickable link regions
 	searchResults  []types.SearchResult
+	searchByLine   map[int][]int // Indices into searchResults for each document line, by start
+	currentResult  int           // Index of the current match in searchResults, or -1
 	currentSearch  string
 	searchHighlight bool
 	caseSensitive  bool
 	colors         *types.ColorConfig // Color configuration
+	truncate       bool               // Whether long text lines are truncated instead of wrapped
+	truncatePages  map[string]bool    // Pages the user switched to truncate mode, by URL
+	xOffset        int                // Horizontal scroll offset in truncate mode
+	contentWidth   int                // Width of the longest rendered line
+	renderTables   bool               // Whether delimited tables are drawn with borders
+	highlightCode  bool               // Whether code blocks with a known language are highlighted
+	footnoteLinks  bool               // Whether links render as footnotes with a References section
+	folded         map[int]bool       // Document line indices of collapsed headings
+	quoteThreshold int                // Quote runs longer than this start collapsed; 0 or less disables
+	expandedQuotes map[int]bool       // Start indices of long quote runs the user expanded
+	quoteToggles   map[int]int        // Maps rendered placeholder line to its quote run start
+	dragging       bool               // Whether the left button is held on the content
+	dragStartY     int                // Screen row where the drag started
+	dragLastY      int                // Screen row of the last drag event
+	dragMoved      bool               // Whether the pointer moved since the press
+	hoverURL       string             // URL of the link under the mouse pointer, if any
+	lastClick      time.Time          // When the content was last clicked
+	lastClickX     int                // Screen position of the last click
+	lastClickY     int
+	clickCount     int                // Consecutive clicks on the same spot
+	selection      *textSelection     // Text selected by a double or triple click
+	zoom           int                // Content density, MinZoom to MaxZoom
+	typography     Typography         // Spacing around headings and blank lines
+	shapeBidi      bool               // Whether right-to-left text is reordered and right-aligned
+	slowLink       func(url string) bool // Whether a link leads to a host known to be slow; nil marks none
 }
 
+// horizontalScrollStep is how many columns one horizontal scroll moves
+const horizontalScrollStep = 8
+
+// hoverSGR underlines and brightens the line of the link under the pointer
+const hoverSGR = "\x1b[1;4m"
+
 // linkBound represents the clickable region of a link on a rendered line
 type linkBound struct {
 	startX int
@@ -49,6 +85,11 @@ func NewContentViewport(width, height int) *ContentViewport {
 		searchHighlight: false,
 		caseSensitive:  false,
 		colors:         nil, // Will be set via SetColors
+		truncatePages:  make(map[string]bool),
+		folded:         make(map[int]bool),
+		expandedQuotes: make(map[int]bool),
+		typography:     DefaultTypography(),
+		shapeBidi:      true,
 	}
 }
 
@@ -63,8 +104,62 @@ func (c *ContentViewport) Update(msg tea.Msg) (*ContentViewport, tea.Cmd) {
 
 	switch msg := msg.(type) {
 	case tea.MouseMsg:
+		// Shift+wheel and horizontal wheels scroll sideways in truncate mode
+		switch {
+		case msg.Button == tea.MouseButtonWheelLeft || (msg.Shift && msg.Button == tea.MouseButtonWheelUp):
+			c.ScrollLeft()
+			return c, nil
+		case msg.Button == tea.MouseButtonWheelRight || (msg.Shift && msg.Button == tea.MouseButtonWheelDown):
+			c.ScrollRight()
+			return c, nil
+		}
+
+		// Right-clicking a link opens the link menu
+		if msg.Button == tea.MouseButtonRight && msg.Action == tea.MouseActionPress {
+			if url, ok := c.linkAt(msg.X, msg.Y); ok {
+				label := c.linkLabel(url)
+				return c, func() tea.Msg { return LinkMenuMsg{URL: url, Label: label} }
+			}
+			return c, nil
+		}
+
+		// Click-drag scrolls the content; a click is only acted on when
+		// the button is released without the pointer moving
+		clicked := false
+		switch {
+		case msg.Button == tea.MouseButtonLeft && msg.Action == tea.MouseActionPress:
+			c.dragging = true
+			c.dragStartY = msg.Y
+			c.dragLastY = msg.Y
+			c.dragMoved = false
+			return c, nil
+
+		case msg.Action == tea.MouseActionMotion && c.dragging:
+			if delta := c.dragLastY - msg.Y; delta > 0 {
+				c.viewport.ScrollDown(delta)
+			} else if delta < 0 {
+				c.viewport.ScrollUp(-delta)
+			}
+			c.dragLastY = msg.Y
+			if msg.Y != c.dragStartY {
+				c.dragMoved = true
+			}
+			return c, nil
+
+		case msg.Action == tea.MouseActionMotion && msg.Button == tea.MouseButtonNone:
+			c.hoverURL, _ = c.linkAt(msg.X, msg.Y)
+			return c, nil
+
+		case msg.Action == tea.MouseActionRelease && c.dragging:
+			c.dragging = false
+			if c.dragMoved {
+				return c, nil
+			}
+			clicked = true
+		}
+
 		// Handle mouse clicks on links
-		if msg.Button == tea.MouseButtonLeft && msg.Action == tea.MouseActionPress && c.document != nil {
+		if clicked && c.document != nil {
 			// Calculate which line was clicked
 			// Subtract viewport's Y position to convert from screen coordinates to viewport coordinates
 			viewportY := msg.Y - c.yPosition
@@ -72,15 +167,24 @@ func (c *ContentViewport) Update(msg tea.Msg) (*ContentViewport, tea.Cmd) {
 				// Calculate the rendered line number (accounting for scroll offset)
 				renderedLineNum := c.viewport.YOffset + viewportY
 
+				// Clicking a collapsed quote placeholder expands it
+				if start, ok := c.quoteToggles[renderedLineNum]; ok {
+					c.expandQuote(start)
+					return c, nil
+				}
+
 				// Check if there are link bounds for this rendered line
-				if bounds, ok := c.linkBounds[renderedLineNum]; ok {
-					// Check if click X position is within any link bound
-					for _, bound := range bounds {
-						if msg.X >= bound.startX && msg.X < bound.endX {
-							return c, func() tea.Msg { return NavigateMsg{URL: bound.url} }
-						}
-					}
+				if url, ok := c.linkAt(msg.X, msg.Y); ok {
+					return c, func() tea.Msg { return NavigateMsg{URL: url} }
 				}
+
+				// Double-clicking selects a word and triple-clicking the
+				// whole line; a single click drops the selection
+				if count := c.registerClick(msg.X, msg.Y); count > 1 {
+					return c, c.selectAt(msg.X, msg.Y, count == 3)
+				}
+				c.selection = nil
+				return c, nil
 			}
 		}
 	}
@@ -89,9 +193,84 @@ func (c *ContentViewport) Update(msg tea.Msg) (*ContentViewport, tea.Cmd) {
 	return c, cmd
 }
 
-// View renders the viewport
+// linkAt returns the URL of the link at screen position x, y, if any
+func (c *ContentViewport) linkAt(x, y int) (string, bool) {
+	viewportY := y - c.yPosition
+	if c.document == nil || viewportY < 0 || viewportY >= c.viewport.Height {
+		return "", false
+	}
+
+	renderedLineNum := c.viewport.YOffset + viewportY
+	clickX := x + c.xOffset
+	for _, bound := range c.linkBounds[renderedLineNum] {
+		if clickX >= bound.startX && clickX < bound.endX {
+			return bound.url, true
+		}
+	}
+	return "", false
+}
+
+// linkLabel returns the text of the first link in the document to url
+func (c *ContentViewport) linkLabel(url string) string {
+	for _, link := range c.document.Links {
+		if link.URL == url {
+			return link.Text
+		}
+	}
+	return ""
+}
+
+// View renders the viewport, with the lines of the link under the mouse
+// pointer underlined and brightened and any selected text highlighted
 func (c *ContentViewport) View() string {
-	return c.viewport.View()
+	view := c.viewport.View()
+	if c.hoverURL == "" && c.selection == nil {
+		return view
+	}
+
+	lines := strings.Split(view, "\n")
+	for row := range lines {
+		if c.selection != nil && c.selection.line == c.viewport.YOffset+row {
+			lines[row] = c.selectLine(lines[row])
+		}
+		if c.hoverURL == "" {
+			continue
+		}
+		for _, bound := range c.linkBounds[c.viewport.YOffset+row] {
+			if bound.url == c.hoverURL {
+				lines[row] = hoverLine(lines[row])
+				break
+			}
+		}
+	}
+	return strings.Join(lines, "\n")
+}
+
+// Rendered returns the whole page as drawn, with trailing padding removed,
+// for showing it elsewhere
+func (c *ContentViewport) Rendered() string {
+	lines := strings.Split(c.renderDocument(), "\n")
+	for i, line := range lines {
+		lines[i] = strings.TrimRight(line, " ")
+	}
+	return strings.Join(lines, "\n") + "\n"
+}
+
+// hoverLine underlines and brightens a rendered line while keeping its
+// colors: the attributes are set again after every reset in the line.
+// Trailing padding is left plain.
+func hoverLine(line string) string {
+	text := strings.TrimRight(line, " ")
+	padding := line[len(text):]
+	text = strings.ReplaceAll(text, "\x1b[0m", "\x1b[0m"+hoverSGR)
+	text = strings.ReplaceAll(text, "\x1b[m", "\x1b[m"+hoverSGR)
+	return hoverSGR + text + "\x1b[0m" + padding
+}
+
+// HoveredURL returns the URL of the link under the mouse pointer, or an
+// empty string
+func (c *ContentViewport) HoveredURL() string {
+	return c.hoverURL
 }
 
 // SetDocument sets the document to display
@@ -99,17 +278,47 @@ func (c *ContentViewport) SetDocument(doc *types.Document) {
 	c.document = doc
 	c.selectedLink = -1
 	c.searchResults = []types.SearchResult{}
+	c.searchByLine = nil
+	c.currentResult = -1
 	c.currentSearch = ""
 	c.searchHighlight = false
 	c.viewport.YOffset = 0 // Reset scroll to top
+	c.hoverURL = ""
+	c.selection = nil
+	c.clickCount = 0
+	c.truncate = doc != nil && c.truncatePages[doc.URL]
+	c.folded = make(map[int]bool)
+	c.expandedQuotes = make(map[int]bool)
 
 	// Render the document
 	content := c.renderDocument()
 	c.viewport.SetContent(content)
+	c.setXOffset(0)
 }
 
-// SetSize sets the viewport size
+// GrowDocument shows a longer version of the current page, such as one
+// still streaming in. Scroll position, folds and search are kept, and a
+// view scrolled to the bottom follows the new lines. Another page is shown
+// as SetDocument would.
+func (c *ContentViewport) GrowDocument(doc *types.Document) {
+	if c.document == nil || doc == nil || c.document.URL != doc.URL {
+		c.SetDocument(doc)
+		return
+	}
+
+	atBottom := c.viewport.AtBottom()
+	c.document = doc
+	c.rerender()
+	if atBottom {
+		c.viewport.GotoBottom()
+	}
+}
+
+// SetSize sets the viewport size. The text at the top of the view stays
+// there as the document rewraps, and search highlights are kept.
 func (c *ContentViewport) SetSize(width, height int) {
+	docLine, offset := c.topPosition()
+
 	c.width = width
 	c.height = height
 	c.viewport.Width = width
@@ -117,8 +326,17 @@ func (c *ContentViewport) SetSize(width, height int) {
 
 	// Re-render document if present
 	if c.document != nil {
-		content := c.renderDocument()
-		c.viewport.SetContent(content)
+		c.rerender()
+		if line := c.renderedLineAt(docLine, offset); line >= 0 {
+			c.viewport.SetYOffset(line)
+		}
+	}
+}
+
+// SetScrollSpeed sets how many lines one mouse wheel step scrolls
+func (c *ContentViewport) SetScrollSpeed(lines int) {
+	if lines > 0 {
+		c.viewport.MouseWheelDelta = lines
 	}
 }
 
@@ -145,6 +363,21 @@ func (c *ContentViewport) SetSearch(query string, results []types.SearchResult,
 	c.searchHighlight = len(results) > 0
 	c.caseSensitive = caseSensitive
 
+	// Bucket matches by line once so rendering each line is a map lookup
+	c.searchByLine = make(map[int][]int)
+	for i, result := range results {
+		c.searchByLine[result.Line] = append(c.searchByLine[result.Line], i)
+	}
+	for _, indices := range c.searchByLine {
+		sort.Slice(indices, func(a, b int) bool {
+			return results[indices[a]].Start < results[indices[b]].Start
+		})
+	}
+	c.currentResult = -1
+	if len(results) > 0 {
+		c.currentResult = 0
+	}
+
 	// Re-render document with highlights
 	content := c.renderDocument()
 	c.viewport.SetContent(content)
@@ -154,6 +387,8 @@ func (c *ContentViewport) SetSearch(query string, results []types.SearchResult,
 func (c *ContentViewport) ClearSearch() {
 	c.currentSearch = ""
 	c.searchResults = []types.SearchResult{}
+	c.searchByLine = nil
+	c.currentResult = -1
 	c.searchHighlight = false
 
 	// Re-render document without highlights
@@ -167,19 +402,295 @@ func (c *ContentViewport) GoToSearchResult(result *types.SearchResult) {
 		return
 	}
 
-	// Find rendered line number for this document line
-	targetLine := -1
+	// Mark the new current match
+	for _, i := range c.searchByLine[result.Line] {
+		if c.searchResults[i].Start == result.Start && c.searchResults[i].End == result.End {
+			if i != c.currentResult {
+				c.currentResult = i
+				c.rerender()
+			}
+			break
+		}
+	}
+
+	if targetLine := c.renderedLineAt(result.Line, result.Start); targetLine >= 0 {
+		// Center the match vertically; SetYOffset clamps at the edges
+		c.viewport.SetYOffset(targetLine - c.viewport.Height/2)
+	}
+}
+
+// ScrollToLine scrolls so document line idx is at the top of the view
+func (c *ContentViewport) ScrollToLine(idx int) {
+	if target := c.renderedLineFor(idx); target >= 0 {
+		c.viewport.SetYOffset(target)
+	}
+}
+
+// ToggleTruncate switches the current page between soft-wrapping long text
+// lines and truncating them with horizontal scrolling. The choice is
+// remembered for the page's URL.
+func (c *ContentViewport) ToggleTruncate() bool {
+	if c.document == nil {
+		return false
+	}
+
+	c.truncate = !c.truncate
+	if c.truncate {
+		c.truncatePages[c.document.URL] = true
+	} else {
+		delete(c.truncatePages, c.document.URL)
+	}
+
+	c.viewport.SetContent(c.renderDocument())
+	c.setXOffset(0)
+	return c.truncate
+}
+
+// SetRenderTables sets whether delimited tables are drawn with borders
+func (c *ContentViewport) SetRenderTables(enabled bool) {
+	c.renderTables = enabled
+	if c.document != nil {
+		c.viewport.SetContent(c.renderDocument())
+		c.setXOffset(c.xOffset)
+	}
+}
+
+// SetSyntaxHighlight sets whether code blocks whose alt text names a
+// known language are syntax highlighted
+func (c *ContentViewport) SetSyntaxHighlight(enabled bool) {
+	c.highlightCode = enabled
+	if c.document != nil {
+		c.viewport.SetContent(c.renderDocument())
+		c.setXOffset(c.xOffset)
+	}
+}
+
+// SetSlowLinks sets how links to hosts known to be slow are recognized;
+// they are marked when the page is next rendered
+func (c *ContentViewport) SetSlowLinks(slow func(url string) bool) {
+	c.slowLink = slow
+}
+
+// SetFootnoteLinks sets whether links are rendered footnote-style, with
+// their URLs collected in a References section at the end
+func (c *ContentViewport) SetFootnoteLinks(enabled bool) {
+	c.footnoteLinks = enabled
+	if c.document != nil {
+		c.viewport.SetContent(c.renderDocument())
+		c.setXOffset(c.xOffset)
+	}
+}
+
+// FootnoteLinks returns whether links are rendered footnote-style
+func (c *ContentViewport) FootnoteLinks() bool {
+	return c.footnoteLinks
+}
+
+// headingLevel returns 1-3 for heading lines and 0 otherwise
+func headingLevel(lineType types.LineType) int {
+	switch lineType {
+	case types.LineHeading1:
+		return 1
+	case types.LineHeading2:
+		return 2
+	case types.LineHeading3:
+		return 3
+	}
+	return 0
+}
+
+// sectionEnd returns the index of the last line in the section started by
+// the heading at idx, i.e. the line before the next heading of the same or
+// a higher level
+func (c *ContentViewport) sectionEnd(idx int) int {
+	level := headingLevel(c.document.Lines[idx].Type)
+	for j := idx + 1; j < len(c.document.Lines); j++ {
+		if l := headingLevel(c.document.Lines[j].Type); l > 0 && l <= level {
+			return j - 1
+		}
+	}
+	return len(c.document.Lines) - 1
+}
+
+// topDocLine returns the document line shown at the top of the viewport
+func (c *ContentViewport) topDocLine() int {
+	docLine, _ := c.topPosition()
+	return docLine
+}
+
+// topPosition returns the document line shown at the top of the viewport
+// and the byte offset in its text where the top rendered line starts
+func (c *ContentViewport) topPosition() (docLine, offset int) {
+	for line := c.viewport.YOffset; line >= 0; line-- {
+		if docLine, ok := c.lineMapping[line]; ok && docLine >= 0 {
+			return docLine, c.lineOffsets[line]
+		}
+	}
+	return 0, 0
+}
+
+// renderedLineAt returns the rendered line showing byte offset offset of
+// document line idx: the wrapped segment with the last start at or before
+// it. It returns -1 if the line is not rendered.
+func (c *ContentViewport) renderedLineAt(idx, offset int) int {
+	target, targetStart := -1, -1
+	for renderedLine, docLine := range c.lineMapping {
+		if docLine != idx {
+			continue
+		}
+		start := c.lineOffsets[renderedLine]
+		if start > offset {
+			continue
+		}
+		if start > targetStart || (start == targetStart && renderedLine < target) {
+			target, targetStart = renderedLine, start
+		}
+	}
+	return target
+}
+
+// renderedLineFor returns the first rendered line showing document line idx
+func (c *ContentViewport) renderedLineFor(idx int) int {
+	first := -1
 	for renderedLine, docLine := range c.lineMapping {
-		if docLine == result.Line {
-			targetLine = renderedLine
+		if docLine == idx && (first < 0 || renderedLine < first) {
+			first = renderedLine
+		}
+	}
+	return first
+}
+
+// rerender re-renders the document, keeping the horizontal offset valid
+func (c *ContentViewport) rerender() {
+	c.viewport.SetContent(c.renderDocument())
+	c.setXOffset(c.xOffset)
+}
+
+// ToggleFold collapses or expands the section containing the top visible
+// line and returns the heading text, or "" if that line is not in a section
+func (c *ContentViewport) ToggleFold() string {
+	if c.document == nil {
+		return ""
+	}
+
+	heading := -1
+	for idx := c.topDocLine(); idx >= 0; idx-- {
+		if headingLevel(c.document.Lines[idx].Type) > 0 {
+			heading = idx
 			break
 		}
 	}
+	if heading < 0 {
+		return ""
+	}
+
+	if c.folded[heading] {
+		delete(c.folded, heading)
+	} else {
+		c.folded[heading] = true
+	}
+	c.rerender()
+
+	// Keep the heading in view
+	if line := c.renderedLineFor(heading); line >= 0 {
+		c.viewport.SetYOffset(line)
+	}
+	return c.document.Lines[heading].Text
+}
+
+// FoldAll collapses every heading's section
+func (c *ContentViewport) FoldAll() {
+	if c.document == nil {
+		return
+	}
+	for idx, line := range c.document.Lines {
+		if headingLevel(line.Type) > 0 {
+			c.folded[idx] = true
+		}
+	}
+	c.rerender()
+	c.viewport.SetYOffset(0)
+}
+
+// UnfoldAll expands every folded section
+func (c *ContentViewport) UnfoldAll() {
+	if c.document == nil {
+		return
+	}
+	c.folded = make(map[int]bool)
+	c.rerender()
+}
 
-	if targetLine >= 0 {
-		// Scroll to make line visible
-		c.viewport.YOffset = targetLine
+// SetQuoteThreshold sets how many consecutive quote lines are shown before
+// the run is collapsed; 0 or less never collapses quotes
+func (c *ContentViewport) SetQuoteThreshold(threshold int) {
+	c.quoteThreshold = threshold
+	if c.document != nil {
+		c.rerender()
+	}
+}
+
+// ExpandVisibleQuote expands the first collapsed quote run on screen and
+// reports whether there was one
+func (c *ContentViewport) ExpandVisibleQuote() bool {
+	start := -1
+	first := -1
+	for renderedLine, runStart := range c.quoteToggles {
+		visible := renderedLine >= c.viewport.YOffset && renderedLine < c.viewport.YOffset+c.height
+		if visible && (first < 0 || renderedLine < first) {
+			first = renderedLine
+			start = runStart
+		}
+	}
+	if start < 0 {
+		return false
 	}
+	c.expandQuote(start)
+	return true
+}
+
+// expandQuote shows every line of the quote run starting at start
+func (c *ContentViewport) expandQuote(start int) {
+	c.expandedQuotes[start] = true
+	yOffset := c.viewport.YOffset
+	c.rerender()
+	c.viewport.SetYOffset(yOffset)
+}
+
+// RenderTables returns whether delimited tables are drawn with borders
+func (c *ContentViewport) RenderTables() bool {
+	return c.renderTables
+}
+
+// IsTruncated returns whether long text lines are truncated on this page
+func (c *ContentViewport) IsTruncated() bool {
+	return c.truncate
+}
+
+// ScrollLeft scrolls left in truncate mode
+func (c *ContentViewport) ScrollLeft() {
+	c.setXOffset(c.xOffset - horizontalScrollStep)
+}
+
+// ScrollRight scrolls right in truncate mode
+func (c *ContentViewport) ScrollRight() {
+	c.setXOffset(c.xOffset + horizontalScrollStep)
+}
+
+// setXOffset clamps and applies a horizontal scroll offset
+func (c *ContentViewport) setXOffset(offset int) {
+	maxOffset := c.contentWidth - c.width
+	if !c.truncate || maxOffset < 0 {
+		maxOffset = 0
+	}
+	if offset > maxOffset {
+		offset = maxOffset
+	}
+	if offset < 0 {
+		offset = 0
+	}
+	c.xOffset = offset
+	c.viewport.SetXOffset(offset)
 }
 
 // SetYPosition sets the viewport's Y position in the screen layout
@@ -195,11 +706,28 @@ func (c *ContentViewport) renderDocument() string {
 
 	var builder strings.Builder
 	c.lineMapping = make(map[int]int) // Initialize line mapping
+	c.lineOffsets = make(map[int]int)
 	c.linkBounds = make(map[int][]linkBound) // Initialize link bounds
+	c.quoteToggles = make(map[int]int)
 	renderedLineNum := 0 // Track which rendered line we're on
 
+	c.contentWidth = 0
+	width := c.textWidth()
+	indent := "" // Put before lines of text under a heading
+
 	// Helper function to add content and track line mapping
 	addLine := func(content string, docLineIdx int) {
+		if indent != "" {
+			content = indent + content
+			bounds := c.linkBounds[renderedLineNum]
+			for n := range bounds {
+				bounds[n].startX += len(indent)
+				bounds[n].endX += len(indent)
+			}
+		}
+		if w := lipgloss.Width(content); w > c.contentWidth {
+			c.contentWidth = w
+		}
 		builder.WriteString(content)
 		builder.WriteString("\n")
 		// Map this rendered line to the document line
@@ -211,6 +739,12 @@ func (c *ContentViewport) renderDocument() string {
 	addMultilineContent := func(content string, docLineIdx int) {
 		lines := strings.Split(content, "\n")
 		for _, line := range lines {
+			if line != "" {
+				line = indent + line
+			}
+			if w := lipgloss.Width(line); w > c.contentWidth {
+				c.contentWidth = w
+			}
 			builder.WriteString(line)
 			builder.WriteString("\n")
 			c.lineMapping[renderedLineNum] = docLineIdx
@@ -251,22 +785,24 @@ func (c *ContentViewport) renderDocument() string {
 		}
 	}
 
-	// Define styles
+	// Define styles; the space around headings depends on the zoom level
 	heading1Style := lipgloss.NewStyle().
 		Bold(true).
-		Foreground(lipgloss.Color(heading1Color)).
-		MarginTop(1).
-		MarginBottom(1)
+		Foreground(lipgloss.Color(heading1Color))
 
 	heading2Style := lipgloss.NewStyle().
 		Bold(true).
-		Foreground(lipgloss.Color(heading2Color)).
-		MarginTop(1)
+		Foreground(lipgloss.Color(heading2Color))
 
 	heading3Style := lipgloss.NewStyle().
 		Bold(true).
 		Foreground(lipgloss.Color(heading3Color))
 
+	for level, style := range []*lipgloss.Style{&heading1Style, &heading2Style, &heading3Style} {
+		top, bottom := c.headingMargins(level + 1)
+		*style = style.MarginTop(top).MarginBottom(bottom)
+	}
+
 	linkStyle := lipgloss.NewStyle().
 		Foreground(lipgloss.Color(linkColor)).
 		Underline(true)
@@ -275,6 +811,13 @@ func (c *ContentViewport) renderDocument() string {
 		Foreground(lipgloss.Color(quoteColor)).
 		Bold(true)
 
+	itemTagStyle := lipgloss.NewStyle().
+		Foreground(lipgloss.Color(heading3Color))
+
+	sessionNoteStyle := lipgloss.NewStyle().
+		Foreground(lipgloss.Color(quoteColor)).
+		Italic(true)
+
 	listStyle := lipgloss.NewStyle().
 		Foreground(lipgloss.Color(preformatColor))
 
@@ -287,25 +830,93 @@ func (c *ContentViewport) renderDocument() string {
 		Foreground(lipgloss.Color(preformatColor)).
 		Background(lipgloss.Color(backgroundColor))
 
+	tableStyle := lipgloss.NewStyle().
+		Foreground(lipgloss.Color(preformatColor))
+
+	codeStyles := map[tokenKind]lipgloss.Style{
+		tokenKeyword: preformatStyle.Foreground(lipgloss.Color(heading2Color)).Bold(true),
+		tokenString:  preformatStyle.Foreground(lipgloss.Color(heading3Color)),
+		tokenComment: preformatStyle.Foreground(lipgloss.Color(quoteColor)).Italic(true),
+		tokenNumber:  preformatStyle.Foreground(lipgloss.Color(linkColor)),
+	}
+	var codeLang *codeLanguage // Language of the current preformatted block, if highlighted
+	inBlockComment := false
+
+	// Gopher menus get a gutter holding each item's type tag and link
+	// number, so link text and info lines start in the same column
+	gutterWidth := 0
+	if !c.footnoteLinks {
+		gutterWidth = gopherGutterWidth(c.document)
+	}
+	gutter := func(itemType, num string) string {
+		tag := fmt.Sprintf("%-*s", gopher.ItemTypeTagWidth+1, gopher.ItemTypeTag(itemType))
+		num = fmt.Sprintf("%*s ", gutterWidth-len(tag)-1, num)
+		return itemTagStyle.Render(tag) + linkNumStyle.Render(num)
+	}
+
+	var tables map[int]tableBlock
+	if c.renderTables {
+		tables = detectTables(c.document.Lines)
+	}
+	skipUntil := 0
+	underHeading := false
+
 	for i, line := range c.document.Lines {
+		if i < skipUntil {
+			continue
+		}
+
+		// Indent text under a heading, wrapping it narrower to make room
+		width, indent = c.textWidth(), ""
+		if isHeading(line.Type) {
+			underHeading = true
+		} else if underHeading && c.typography.BodyIndent > 0 {
+			indent = strings.Repeat(" ", c.typography.BodyIndent)
+			width -= c.typography.BodyIndent
+		}
+
+		// Collapse the section under a folded heading
+		foldNote := ""
+		if c.folded[i] {
+			end := c.sectionEnd(i)
+			foldNote = fmt.Sprintf(" ▸ (%d lines folded)", end-i)
+			skipUntil = end + 1
+		}
+
+		// Draw detected tables in place of their raw lines, unless they
+		// would have to be wrapped
+		if block, ok := tables[i]; ok {
+			tableLines, lineIdx := renderTable(block)
+			if c.truncate || lipgloss.Width(tableLines[0]) <= width {
+				for n, tableLine := range tableLines {
+					addLine(tableStyle.Render(tableLine), lineIdx[n])
+				}
+				skipUntil = block.end + 1
+				continue
+			}
+		}
+
 		switch line.Type {
 		case types.LineHeading1:
 			// Wrap heading text before styling
-			wrapped := wordWrap("# "+line.Text, c.width)
+			wrapped := wordWrap("# "+line.Text+foldNote, width)
+			wrapped = c.shapeBidiText(wrapped, rightToLeft(line.Text), width)
 			rendered := heading1Style.Render(wrapped)
 			// Styles with margins produce multiple lines
 			addMultilineContent(rendered, i)
 
 		case types.LineHeading2:
 			// Wrap heading text before styling
-			wrapped := wordWrap("## "+line.Text, c.width)
+			wrapped := wordWrap("## "+line.Text+foldNote, width)
+			wrapped = c.shapeBidiText(wrapped, rightToLeft(line.Text), width)
 			rendered := heading2Style.Render(wrapped)
 			// Styles with margins produce multiple lines
 			addMultilineContent(rendered, i)
 
 		case types.LineHeading3:
 			// Wrap heading text before styling
-			wrapped := wordWrap("### "+line.Text, c.width)
+			wrapped := wordWrap("### "+line.Text+foldNote, width)
+			wrapped = c.shapeBidiText(wrapped, rightToLeft(line.Text), width)
 			rendered := heading3Style.Render(wrapped)
 			addMultilineContent(rendered, i)
 
@@ -315,47 +926,80 @@ func (c *ContentViewport) renderDocument() string {
 				linkText = line.URL
 			}
 
-			// Apply search highlighting if enabled
-			if c.searchHighlight && c.currentSearch != "" {
-				linkText = c.highlightSearchText(linkText, i)
+			// Style the link text and layer search highlighting over it
+			styledLink := c.highlightSearch(newStyledText(linkText, &linkStyle), i)
+			rtl := rightToLeft(linkText)
+
+			// Telnet and phone book items open no page; say where they lead
+			if line.ItemType != "" {
+				if session, ok := gopher.SessionFor(line.URL); ok {
+					styledLink = styledLink.add(" ("+session.Description()+")", &sessionNoteStyle)
+				}
+			}
+			if c.slowLink != nil && c.slowLink(line.URL) {
+				styledLink = styledLink.add(" (slow host)", &sessionNoteStyle)
+			}
+
+			if c.footnoteLinks {
+				// Show only the text with a superscript number; the URL is
+				// listed under References at the end
+				marker := superscript(line.LinkNum)
+				wrappedLines, starts := styledLink.wrap(width - len([]rune(marker)) - 1)
+				for lineIdx, wrappedLine := range wrappedLines {
+					c.lineOffsets[renderedLineNum] = starts[lineIdx]
+					displayLine := c.bidiRow(wrappedLine, rtl).render()
+					if lineIdx == len(wrappedLines)-1 {
+						displayLine += " " + linkNumStyle.Render(marker)
+					}
+					c.linkBounds[renderedLineNum] = []linkBound{
+						{startX: 0, endX: len(wrappedLine.String()), url: line.URL},
+					}
+					addLine(displayLine, i)
+				}
+				continue
 			}
 
 			// Add link number for keyboard navigation
 			numStrPlain := fmt.Sprintf("[%d] ", line.LinkNum)
 			linkPrefix := len(numStrPlain)
+			inGutter := gutterWidth > 0 && line.ItemType != ""
+			if inGutter {
+				linkPrefix = gutterWidth
+			}
 
 			// Wrap link text to fit viewport width (accounting for the link number prefix)
-			availableWidth := c.width - linkPrefix
+			availableWidth := width - linkPrefix
 			if availableWidth < 20 {
 				availableWidth = 20 // Minimum width for readability
 			}
-			wrappedLink := wordWrap(linkText, availableWidth)
-			wrappedLines := strings.Split(wrappedLink, "\n")
+			wrappedLines, starts := styledLink.wrap(availableWidth)
 
 			// Render each wrapped line
 			for lineIdx, wrappedLine := range wrappedLines {
+				c.lineOffsets[renderedLineNum] = starts[lineIdx]
 				var displayLine string
 				if lineIdx == 0 {
 					// First line includes the link number
-					numStr := linkNumStyle.Render(fmt.Sprintf("[%d]", line.LinkNum))
-					linkStr := linkStyle.Render(wrappedLine)
-					displayLine = numStr + " " + linkStr
+					numStr := linkNumStyle.Render(fmt.Sprintf("[%d]", line.LinkNum)) + " "
+					if inGutter {
+						numStr = gutter(line.ItemType, fmt.Sprintf("[%d]", line.LinkNum))
+					}
+					displayLine = numStr + c.bidiRow(wrappedLine, rtl).render()
 
 					// Calculate clickable bounds for first line
 					startX := linkPrefix
-					endX := startX + len(stripANSI(wrappedLine))
+					endX := startX + len(wrappedLine.String())
 					c.linkBounds[renderedLineNum] = []linkBound{
 						{startX: startX, endX: endX, url: line.URL},
 					}
 				} else {
 					// Continuation lines are indented to align with first line
 					indent := strings.Repeat(" ", linkPrefix)
-					linkStr := linkStyle.Render(wrappedLine)
-					displayLine = indent + linkStr
+					displayLine = indent + c.bidiRow(wrappedLine, rtl).render()
 
 					// Calculate clickable bounds for continuation line
 					startX := linkPrefix
-					endX := startX + len(stripANSI(wrappedLine))
+					endX := startX + len(wrappedLine.String())
 					c.linkBounds[renderedLineNum] = []linkBound{
 						{startX: startX, endX: endX, url: line.URL},
 					}
@@ -369,13 +1013,26 @@ func (c *ContentViewport) renderDocument() string {
 		case types.LineList:
 			// Wrap list text (accounting for bullet point)
 			listPrefix := "  • "
-			availableWidth := c.width - len(listPrefix)
+			availableWidth := width - len(listPrefix)
 			if availableWidth < 20 {
 				availableWidth = 20
 			}
 			wrapped := wordWrap(line.Text, availableWidth)
 			wrappedLines := strings.Split(wrapped, "\n")
 
+			// Right-to-left items read from a bullet on the right
+			if c.shapeBidi && rightToLeft(line.Text) {
+				for lineIdx, wrappedLine := range wrappedLines {
+					suffix := strings.Repeat(" ", len(listPrefix))
+					if lineIdx == 0 {
+						suffix = " •  "
+					}
+					row := newStyledText(wrappedLine, nil).visual(true).String()
+					addLine(listStyle.Render(alignRight(row+suffix, width)), i)
+				}
+				continue
+			}
+
 			for lineIdx, wrappedLine := range wrappedLines {
 				if lineIdx == 0 {
 					// First line with bullet
@@ -389,83 +1046,171 @@ func (c *ContentViewport) renderDocument() string {
 			continue
 
 		case types.LineQuote:
+			// Collapse long runs of quoted lines behind a placeholder
+			if c.quoteThreshold > 0 && (i == 0 || c.document.Lines[i-1].Type != types.LineQuote) && !c.expandedQuotes[i] {
+				end := i
+				for end+1 < len(c.document.Lines) && c.document.Lines[end+1].Type == types.LineQuote {
+					end++
+				}
+				if count := end - i + 1; count > c.quoteThreshold {
+					c.quoteToggles[renderedLineNum] = i
+					addLine(quoteStyle.Render(fmt.Sprintf("(%d quoted lines, press enter to expand)", count)), i)
+					skipUntil = end + 1
+					continue
+				}
+			}
+
 			// Wrap quote text (accounting for padding)
 			quotePadding := 2 // PaddingLeft(2) from quoteStyle
-			availableWidth := c.width - quotePadding
+			availableWidth := width - quotePadding
 			if availableWidth < 20 {
 				availableWidth = 20
 			}
 			wrapped := wordWrap(line.Text, availableWidth)
+			wrapped = c.shapeBidiText(wrapped, rightToLeft(line.Text), availableWidth)
 			rendered := quoteStyle.Render(wrapped)
 			addMultilineContent(rendered, i)
 
 		case types.LinePreformatStart:
+			codeLang = nil
+			if c.highlightCode {
+				codeLang = languageForAltText(line.Text)
+			}
+			inBlockComment = false
+
 			// Optionally show alt text, hard-wrap if needed
 			if line.Text != "" {
-				wrapped := hardWrap("``` "+line.Text, c.width)
+				wrapped := hardWrap("``` "+line.Text, width)
 				addMultilineContent(preformatStyle.Render(wrapped), i)
 			}
 			// Note: If text is empty, we don't render anything but the mapping continues
 
 		case types.LinePreformatText:
-			// Hard-wrap preformatted text to prevent overflow
-			wrapped := hardWrap(line.Text, c.width)
-			addMultilineContent(preformatStyle.Render(wrapped), i)
+			// Highlight code in a recognised language; unknown languages
+			// fall through to plain preformatted text
+			if codeLang != nil {
+				wrapWidth := width
+				if c.truncate {
+					wrapWidth = 0
+				}
+				tokens := codeLang.tokenize(line.Text, &inBlockComment)
+				for _, row := range wrapTokens(tokens, wrapWidth) {
+					addLine(renderTokens(row, preformatStyle, codeStyles), i)
+				}
+				continue
+			}
+
+			// Hard-wrap preformatted text to prevent overflow, unless the
+			// page is in truncate mode
+			if c.truncate {
+				addLine(preformatStyle.Render(line.Text), i)
+			} else {
+				wrapped := hardWrap(line.Text, width)
+				addMultilineContent(preformatStyle.Render(wrapped), i)
+			}
 
 		case types.LinePreformatEnd:
+			codeLang = nil
 			addLine(preformatStyle.Render("```"), i)
 
 		case types.LineText:
 			// Word wrap for long lines
 			if len(line.Text) == 0 {
-				addLine("", i)
+				if !c.skipBlank(i) {
+					addLine("", i)
+				}
 			} else {
-				text := line.Text
+				// Gopher info and error lines line up with the menu's links
+				prefix, indent := "", ""
+				if gutterWidth > 0 && line.ItemType != "" {
+					prefix = gutter(line.ItemType, "")
+					indent = strings.Repeat(" ", gutterWidth)
+				}
+
 				// Apply search highlighting if enabled
-				if c.searchHighlight && c.currentSearch != "" {
-					text = c.highlightSearchText(text, i)
+				text := c.highlightSearch(newStyledText(line.Text, nil), i)
+				rtl := rightToLeft(line.Text)
+				if c.truncate {
+					// Keep the line intact; the viewport scrolls horizontally
+					addLine(prefix+c.bidiRow(text, rtl).render(), i)
+				} else {
+					// Wrapping may produce multiple lines
+					wrappedLines, starts := text.wrap(width - len(indent))
+					for n, wrapped := range wrappedLines {
+						c.lineOffsets[renderedLineNum] = starts[n]
+						if n > 0 {
+							prefix = indent
+						}
+						row := c.bidiRow(wrapped, rtl).render()
+						// Right-to-left paragraphs end at the right margin
+						if rtl && c.shapeBidi && indent == "" {
+							row = alignRight(row, width)
+						}
+						addLine(prefix+row, i)
+					}
 				}
-				wrapped := wordWrap(text, c.width)
-				// wordWrap may produce multiple lines
-				addMultilineContent(wrapped, i)
 			}
 		}
 	}
 
+	// List link targets at the end of the document in footnote mode.
+	// These lines map to no document line so search never lands on them.
+	indent = ""
+	if c.footnoteLinks && len(c.document.Links) > 0 {
+		addMultilineContent(heading2Style.Render("References"), -1)
+		for _, link := range c.document.Links {
+			numStr := fmt.Sprintf("[%d] ", link.LinkNum)
+			c.linkBounds[renderedLineNum] = []linkBound{
+				{startX: len(numStr), endX: len(numStr) + len(link.URL), url: link.URL},
+			}
+			addLine(linkNumStyle.Render(strings.TrimSpace(numStr))+" "+linkStyle.Render(link.URL), -1)
+		}
+	}
+
 	return builder.String()
 }
 
-// highlightSearchText applies highlighting to search matches in text
-func (c *ContentViewport) highlightSearchText(text string, lineIdx int) string {
-	if !c.searchHighlight || c.currentSearch == "" {
-		return text
+// gopherGutterWidth returns the width of the gutter beside Gopher menu
+// items: a type tag, then the widest link number. It is 0 for documents
+// that are not Gopher menus.
+func gopherGutterWidth(doc *types.Document) int {
+	for _, line := range doc.Lines {
+		if line.ItemType != "" {
+			return gopher.ItemTypeTagWidth + 1 + len(fmt.Sprintf("[%d] ", len(doc.Links)))
+		}
 	}
+	return 0
+}
+
+// superscriptDigits maps '0'-'9' to their superscript forms
+var superscriptDigits = []rune("⁰¹²³⁴⁵⁶⁷⁸⁹")
 
-	// Find all search results for this line
-	var lineResults []types.SearchResult
-	for _, result := range c.searchResults {
-		if result.Line == lineIdx {
-			lineResults = append(lineResults, result)
+// superscript formats n with superscript digits, e.g. 12 -> "¹²"
+func superscript(n int) string {
+	var b strings.Builder
+	for _, d := range fmt.Sprint(n) {
+		if d >= '0' && d <= '9' {
+			b.WriteRune(superscriptDigits[d-'0'])
+		} else {
+			b.WriteRune(d)
 		}
 	}
+	return b.String()
+}
 
-	if len(lineResults) == 0 {
+// highlightSearch layers highlighting for the search matches on document
+// line lineIdx over text, keeping the text's own styles
+func (c *ContentViewport) highlightSearch(text styledText, lineIdx int) styledText {
+	if !c.searchHighlight || c.currentSearch == "" {
 		return text
 	}
 
-	// Sort results by start position
-	for i := 0; i < len(lineResults)-1; i++ {
-		for j := i + 1; j < len(lineResults); j++ {
-			if lineResults[i].Start > lineResults[j].Start {
-				lineResults[i], lineResults[j] = lineResults[j], lineResults[i]
-			}
-		}
+	// Search results for this line, sorted by start position
+	lineResults := c.searchByLine[lineIdx]
+	if len(lineResults) == 0 {
+		return text
 	}
 
-	// Apply highlighting
-	result := ""
-	lastEnd := 0
-	
 	searchHighlightStyle := lipgloss.NewStyle().
 		Background(lipgloss.Color("11")).
 		Bold(true)
@@ -474,37 +1219,24 @@ func (c *ContentViewport) highlightSearchText(text string, lineIdx int) string {
 		Background(lipgloss.Color("3")).
 		Bold(true)
 
-	for _, searchResult := range lineResults {
-		// Add text before match
-		result += text[lastEnd:searchResult.Start]
-		
-		// Add highlighted match
-		matchText := text[searchResult.Start:searchResult.End]
-		
-		// Check if this is the current match
-		isCurrent := false
-		for _, currentResult := range c.searchResults {
-			if currentResult.Line == lineIdx && 
-			   currentResult.Start == searchResult.Start && 
-			   currentResult.End == searchResult.End {
-				isCurrent = true
-				break
-			}
+	textLen := len(text.String())
+	lastEnd := 0
+	for _, i := range lineResults {
+		searchResult := c.searchResults[i]
+		if searchResult.Start < lastEnd || searchResult.End > textLen {
+			continue // Overlapping or stale match
 		}
-		
-		if isCurrent {
-			result += searchCurrentStyle.Render(matchText)
+
+		// Distinguish the current match
+		if i == c.currentResult {
+			text = text.overlay(searchResult.Start, searchResult.End, searchCurrentStyle)
 		} else {
-			result += searchHighlightStyle.Render(matchText)
+			text = text.overlay(searchResult.Start, searchResult.End, searchHighlightStyle)
 		}
-		
 		lastEnd = searchResult.End
 	}
-	
-	// Add remaining text
-	result += text[lastEnd:]
-	
-	return result
+
+	return text
 }
 
 // wordWrap wraps text to a specified width
@@ -576,6 +1308,16 @@ func (c *ContentViewport) ScrollDown() {
 	c.viewport.LineDown(1)
 }
 
+// GotoTop scrolls to the start of the document
+func (c *ContentViewport) GotoTop() {
+	c.viewport.GotoTop()
+}
+
+// GotoBottom scrolls to the end of the document
+func (c *ContentViewport) GotoBottom() {
+	c.viewport.GotoBottom()
+}
+
 // PageUp scrolls up one page
 func (c *ContentViewport) PageUp() {
 	c.viewport.ViewUp()
@@ -641,6 +1383,19 @@ func (c *ContentViewport) SelectLinkByNumber(num int) tea.Cmd {
 	return nil
 }
 
+// LinkByNumber returns the link with the given number, if it exists
+func (c *ContentViewport) LinkByNumber(num int) (types.Line, bool) {
+	if c.document == nil {
+		return types.Line{}, false
+	}
+	for _, link := range c.document.Links {
+		if link.LinkNum == num {
+			return link, true
+		}
+	}
+	return types.Line{}, false
+}
+
 // GetScrollOffset returns the current scroll offset
 func (c *ContentViewport) GetScrollOffset() int {
 	return c.viewport.YOffset
@@ -650,10 +1405,3 @@ func (c *ContentViewport) GetScrollOffset() int {
 func (c *ContentViewport) SetScrollOffset(offset int) {
 	c.viewport.YOffset = offset
 }
-
-// stripANSI removes ANSI escape codes from a string
-func stripANSI(str string) string {
-	// Regex to match ANSI escape sequences
-	ansiRegex := regexp.MustCompile(`\x1b\[[0-9;]*[a-zA-Z]`)
-	return ansiRegex.ReplaceAllString(str, "")
-}
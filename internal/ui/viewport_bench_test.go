@@ -0,0 +1,101 @@
+package ui
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	"starsearch/internal/types"
+)
+
+// benchParagraph is a line of prose long enough to wrap several times
+const benchParagraph = "Gemini is a new internet protocol which is heavier than gopher, " +
+	"lighter than the web, will not replace either, and strives for maximum power to weight ratio."
+
+// benchDocument builds a synthetic document of roughly n lines mixing every
+// line type, the way a long gemlog or directory listing would
+func benchDocument(n int) *types.Document {
+	doc := &types.Document{URL: "gemini://bench.example/"}
+	linkNum := 0
+	for i := 0; len(doc.Lines) < n; i++ {
+		switch i % 8 {
+		case 0:
+			doc.Lines = append(doc.Lines, types.Line{Type: types.LineHeading2, Text: fmt.Sprintf("Section %d", i)})
+		case 1, 2, 3:
+			doc.Lines = append(doc.Lines, types.Line{Type: types.LineText, Text: benchParagraph})
+		case 4:
+			linkNum++
+			link := types.Line{Type: types.LineLink, Text: fmt.Sprintf("Entry %d", linkNum), URL: fmt.Sprintf("gemini://bench.example/%d.gmi", linkNum), LinkNum: linkNum}
+			doc.Lines = append(doc.Lines, link)
+			doc.Links = append(doc.Links, link)
+		case 5:
+			doc.Lines = append(doc.Lines, types.Line{Type: types.LineQuote, Text: benchParagraph})
+		case 6:
+			doc.Lines = append(doc.Lines, types.Line{Type: types.LineList, Text: benchParagraph})
+		case 7:
+			doc.Lines = append(doc.Lines,
+				types.Line{Type: types.LinePreformatStart, Text: "go"},
+				types.Line{Type: types.LinePreformatText, Text: `	fmt.Println("hello", 42) // greet`},
+				types.Line{Type: types.LinePreformatEnd},
+			)
+		}
+	}
+	return doc
+}
+
+// benchSearch finds every occurrence of query in doc the way the search
+// modal does
+func benchSearch(doc *types.Document, query string) []types.SearchResult {
+	var results []types.SearchResult
+	for i, line := range doc.Lines {
+		text := line.Text
+		for offset := 0; ; {
+			idx := strings.Index(text[offset:], query)
+			if idx < 0 {
+				break
+			}
+			start := offset + idx
+			results = append(results, types.SearchResult{Line: i, Start: start, End: start + len(query), Text: text})
+			offset = start + len(query)
+		}
+	}
+	return results
+}
+
+func BenchmarkRenderDocument(b *testing.B) {
+	for _, n := range []int{1000, 10000} {
+		b.Run(fmt.Sprintf("lines=%d", n), func(b *testing.B) {
+			c := NewContentViewport(100, 40)
+			c.SetDocument(benchDocument(n))
+			b.ResetTimer()
+			for i := 0; i < b.N; i++ {
+				c.renderDocument()
+			}
+		})
+	}
+}
+
+func BenchmarkWordWrap(b *testing.B) {
+	text := strings.Repeat(benchParagraph+" ", 50)
+	b.SetBytes(int64(len(text)))
+	for i := 0; i < b.N; i++ {
+		wordWrap(text, 80)
+	}
+}
+
+func BenchmarkHighlightSearch(b *testing.B) {
+	for _, n := range []int{1000, 10000} {
+		b.Run(fmt.Sprintf("lines=%d", n), func(b *testing.B) {
+			doc := benchDocument(n)
+			c := NewContentViewport(100, 40)
+			c.SetDocument(doc)
+			c.SetSearch("e", benchSearch(doc, "e"), true)
+			b.ResetTimer()
+			for i := 0; i < b.N; i++ {
+				for lineIdx, line := range doc.Lines {
+					c.highlightSearch(newStyledText(line.Text, nil), lineIdx).render()
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,137 @@
+package ui
+
+import (
+	"flag"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/charmbracelet/lipgloss"
+	"github.com/muesli/termenv"
+	"starsearch/internal/gemini"
+	"starsearch/internal/gopher"
+	"starsearch/internal/types"
+)
+
+var update = flag.Bool("update", false, "rewrite golden files with the current output")
+
+// goldenWidth is the viewport width every golden file is rendered at
+const goldenWidth = 40
+
+// renderGolden parses gemtext, renders it at goldenWidth and compares the
+// output, escape sequences included, with testdata/<name>.golden. Run
+// `go test ./internal/ui -update` to accept changed output.
+func renderGolden(t *testing.T, name, gemtext string, setup func(c *ContentViewport, doc *types.Document)) {
+	t.Helper()
+	doc, err := gemini.NewParser("gemini://golden.example/").Parse(&types.Response{
+		Status: 20,
+		Meta:   "text/gemini",
+		Body:   []byte(gemtext),
+		URL:    "gemini://golden.example/",
+	})
+	if err != nil {
+		t.Fatalf("parse: %v", err)
+	}
+	renderGoldenDocument(t, name, doc, setup)
+}
+
+// renderGoldenDocument is renderGolden for an already parsed document
+func renderGoldenDocument(t *testing.T, name string, doc *types.Document, setup func(c *ContentViewport, doc *types.Document)) {
+	t.Helper()
+	lipgloss.SetColorProfile(termenv.ANSI256)
+
+	c := NewContentViewport(goldenWidth, 20)
+	c.SetDocument(doc)
+	if setup != nil {
+		setup(c, doc)
+	}
+	got := c.renderDocument()
+
+	path := filepath.Join("testdata", name+".golden")
+	if *update {
+		if err := os.MkdirAll("testdata", 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(got), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	want, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("read golden file (run with -update to create it): %v", err)
+	}
+	if got != string(want) {
+		t.Errorf("%s differs from golden file\ngot:\n%s\nwant:\n%s", name, got, want)
+	}
+}
+
+// search returns every occurrence of query in doc, as the search modal does
+func search(doc *types.Document, query string) []types.SearchResult {
+	var results []types.SearchResult
+	for i, line := range doc.Lines {
+		for offset := 0; ; {
+			idx := strings.Index(line.Text[offset:], query)
+			if idx < 0 {
+				break
+			}
+			start := offset + idx
+			results = append(results, types.SearchResult{Line: i, Start: start, End: start + len(query), Text: query})
+			offset = start + 1
+		}
+	}
+	return results
+}
+
+func TestRenderHeadings(t *testing.T) {
+	renderGolden(t, "headings", "# Top level\n## Second level\n### Third level\nBody text\n", nil)
+}
+
+func TestRenderLinks(t *testing.T) {
+	renderGolden(t, "links", "=> gemini://golden.example/a.gmi Short link\n"+
+		"=> /relative.gmi A link label long enough to wrap onto a second line\n"+
+		"=> gemini://golden.example/bare\n", nil)
+}
+
+func TestRenderWrapping(t *testing.T) {
+	renderGolden(t, "wrapping", "A paragraph of prose that is much wider than the forty column viewport and has to wrap.\n"+
+		"* A list item that also needs more than one line to fit\n"+
+		"> A quoted line that wraps inside its padding as well\n", nil)
+}
+
+func TestRenderPreformat(t *testing.T) {
+	renderGolden(t, "preformat", "```\n"+
+		"+------+------------------------------------------+\n"+
+		"| box  | wider than the viewport, so hard-wrapped |\n"+
+		"+------+------------------------------------------+\n"+
+		"```\n", nil)
+}
+
+func TestRenderSearchHighlight(t *testing.T) {
+	renderGolden(t, "search_highlight", "Find the needle in this haystack of words, then find another needle.\n"+
+		"=> /needle.gmi A link with a needle in its label\n",
+		func(c *ContentViewport, doc *types.Document) {
+			results := search(doc, "needle")
+			c.SetSearch("needle", results, true)
+			c.GoToSearchResult(&results[1])
+		})
+}
+
+func TestRenderGopherMenu(t *testing.T) {
+	menu := "iWelcome to the hole\t\tgolden.example\t70\r\n" +
+		"1Phlog\t/phlog\tgolden.example\t70\r\n" +
+		"0About this server, with a description long enough to wrap\t/about.txt\tgolden.example\t70\r\n" +
+		"7Search\t/search\tgolden.example\t70\r\n" +
+		"3Something went wrong\t\tgolden.example\t70\r\n" +
+		".\r\n"
+	doc, err := gopher.NewParser("gopher://golden.example/").Parse(&types.Response{
+		Status: 20,
+		Meta:   gopher.GetMIMEType("1"),
+		Body:   []byte(menu),
+		URL:    "gopher://golden.example/",
+	})
+	if err != nil {
+		t.Fatalf("parse: %v", err)
+	}
+	renderGoldenDocument(t, "gopher_menu", doc, nil)
+}
@@ -0,0 +1,43 @@
+package ui
+
+// Zoom levels trade how much of a page fits on screen against how easy it
+// is to read. Level 0 is the normal layout.
+const (
+	MinZoom = -2
+	MaxZoom = 2
+)
+
+// zoomWidths caps the width text is wrapped to at each level above 0, so
+// lines stay a comfortable length on wide terminals
+var zoomWidths = map[int]int{1: 100, 2: 72}
+
+// SetZoom sets the zoom level, clamped to MinZoom..MaxZoom, and re-renders
+// the page keeping the text at the top of the view in place
+func (c *ContentViewport) SetZoom(level int) {
+	level = max(MinZoom, min(level, MaxZoom))
+	if level == c.zoom {
+		return
+	}
+	c.zoom = level
+
+	if c.document != nil {
+		docLine, offset := c.topPosition()
+		c.rerender()
+		if line := c.renderedLineAt(docLine, offset); line >= 0 {
+			c.viewport.SetYOffset(line)
+		}
+	}
+}
+
+// Zoom returns the zoom level
+func (c *ContentViewport) Zoom() int {
+	return c.zoom
+}
+
+// textWidth is the width text is wrapped to at the current zoom level
+func (c *ContentViewport) textWidth() int {
+	if limit, ok := zoomWidths[c.zoom]; ok && limit < c.width {
+		return limit
+	}
+	return c.width
+}
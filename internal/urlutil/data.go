@@ -0,0 +1,59 @@
+package urlutil
+
+import (
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"net/url"
+	"strings"
+)
+
+// ErrNotDataURL is returned by ParseDataURL for addresses that are not
+// data: URLs
+var ErrNotDataURL = errors.New("not a data: URL")
+
+// ParseDataURL decodes a data: URL (RFC 2397) into its media type and
+// contents. The media type defaults to text/plain, as the RFC says.
+func ParseDataURL(rawURL string) (mediaType string, data []byte, err error) {
+	if len(rawURL) < 5 || !strings.EqualFold(rawURL[:5], "data:") {
+		return "", nil, ErrNotDataURL
+	}
+	header, payload, ok := strings.Cut(rawURL[5:], ",")
+	if !ok {
+		return "", nil, errors.New("data: URL has no comma before its contents")
+	}
+
+	isBase64 := false
+	if strings.HasSuffix(strings.ToLower(header), ";base64") {
+		isBase64 = true
+		header = header[:len(header)-len(";base64")]
+	}
+	mediaType = strings.TrimSpace(header)
+	if mediaType == "" || strings.HasPrefix(mediaType, ";") {
+		mediaType = "text/plain" + mediaType
+	}
+
+	decoded, err := url.PathUnescape(payload)
+	if err != nil {
+		return "", nil, fmt.Errorf("invalid data: URL: %w", err)
+	}
+	if !isBase64 {
+		return mediaType, []byte(decoded), nil
+	}
+
+	// Padding and line breaks are often left out or left in
+	decoded = strings.Map(func(r rune) rune {
+		if r == ' ' || r == '\n' || r == '\r' || r == '\t' {
+			return -1
+		}
+		return r
+	}, decoded)
+	data, err = base64.RawStdEncoding.DecodeString(strings.TrimRight(decoded, "="))
+	if err != nil {
+		data, err = base64.RawURLEncoding.DecodeString(strings.TrimRight(decoded, "="))
+	}
+	if err != nil {
+		return "", nil, fmt.Errorf("invalid base64 in data: URL: %w", err)
+	}
+	return mediaType, data, nil
+}
@@ -0,0 +1,154 @@
+package urlutil
+
+import (
+	"fmt"
+	"net/url"
+	"strings"
+)
+
+// ParentURL returns the URL one path segment above rawURL. The query and
+// fragment are dropped. A root URL is returned unchanged.
+func ParentURL(rawURL string) (string, error) {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return "", fmt.Errorf("invalid URL: %w", err)
+	}
+	if u.Host == "" {
+		return "", fmt.Errorf("URL has no host: %s", rawURL)
+	}
+
+	u.RawQuery = ""
+	u.Fragment = ""
+	u.RawPath = ""
+
+	if u.Scheme == "gopher" {
+		u.Path = gopherParentPath(u.Path)
+	} else {
+		u.Path = parentPath(u.Path)
+	}
+
+	return u.String(), nil
+}
+
+// RootURL returns the root of the capsule or gopher hole hosting rawURL
+func RootURL(rawURL string) (string, error) {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return "", fmt.Errorf("invalid URL: %w", err)
+	}
+	if u.Host == "" {
+		return "", fmt.Errorf("URL has no host: %s", rawURL)
+	}
+
+	root := url.URL{Scheme: u.Scheme, Host: u.Host, Path: "/"}
+	return root.String(), nil
+}
+
+// Host returns the hostname of rawURL, or an empty string if it has none
+func Host(rawURL string) string {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return ""
+	}
+	return u.Hostname()
+}
+
+// IsOnion reports whether rawURL is on a Tor onion service
+func IsOnion(rawURL string) bool {
+	return strings.HasSuffix(strings.ToLower(Host(rawURL)), ".onion")
+}
+
+// CleanPasted extracts an address from pasted text: the first non-blank
+// line, trimmed of whitespace and any surrounding angle brackets
+func CleanPasted(text string) string {
+	for _, line := range strings.Split(text, "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" {
+			continue
+		}
+		if strings.HasPrefix(line, "<") && strings.HasSuffix(line, ">") {
+			line = strings.TrimSpace(line[1 : len(line)-1])
+		}
+		return line
+	}
+	return ""
+}
+
+// parentPath returns the parent directory of a slash-separated path,
+// always ending in a slash
+func parentPath(path string) string {
+	trimmed := strings.TrimSuffix(path, "/")
+	idx := strings.LastIndex(trimmed, "/")
+	if idx < 0 {
+		return "/"
+	}
+	return trimmed[:idx+1]
+}
+
+// gopherParentPath returns the parent menu of a gopher path of the form
+// /<type><selector>. Parents are always directories (type 1).
+func gopherParentPath(path string) string {
+	if len(path) < 2 {
+		return "/"
+	}
+
+	selector := strings.TrimSuffix(path[2:], "/")
+	idx := strings.LastIndex(selector, "/")
+	if idx <= 0 {
+		return "/"
+	}
+	return "/1" + selector[:idx]
+}
+
+// Crumb is one navigable prefix of a URL: the host or a path segment
+type Crumb struct {
+	Label string
+	URL   string
+}
+
+// Crumbs splits rawURL into its host and path segments, each paired with the
+// URL of that prefix. Gopher item types are skipped so segments map onto menus.
+func Crumbs(rawURL string) ([]Crumb, error) {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return nil, fmt.Errorf("invalid URL: %w", err)
+	}
+	if u.Host == "" {
+		return nil, fmt.Errorf("URL has no host: %s", rawURL)
+	}
+
+	root := url.URL{Scheme: u.Scheme, Host: u.Host, Path: "/"}
+	crumbs := []Crumb{{Label: u.Host, URL: root.String()}}
+
+	path := u.Path
+	prefix := "/"
+	if u.Scheme == "gopher" {
+		if len(path) < 2 {
+			return crumbs, nil
+		}
+		path = path[2:]
+		prefix = "/1/"
+	}
+
+	segments := strings.Split(strings.Trim(path, "/"), "/")
+	for i, segment := range segments {
+		if segment == "" {
+			continue
+		}
+
+		last := i == len(segments)-1
+		prefix += segment
+		target := url.URL{Scheme: u.Scheme, Host: u.Host, Path: prefix}
+		if last {
+			// The final segment is the current page itself
+			target = *u
+			target.Fragment = ""
+		} else if u.Scheme != "gopher" {
+			target.Path += "/"
+		}
+		crumbs = append(crumbs, Crumb{Label: segment, URL: target.String()})
+		prefix += "/"
+	}
+
+	return crumbs, nil
+}